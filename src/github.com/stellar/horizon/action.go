@@ -1,9 +1,16 @@
 package horizon
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
+	"net/url"
+	"reflect"
 
 	"github.com/stellar/horizon/actions"
+	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/render/problem"
+	"github.com/stellar/horizon/render/sse"
 	"github.com/zenazn/goji/web"
 )
 
@@ -16,4 +23,185 @@ func (action *Action) Prepare(c web.C, w http.ResponseWriter, r *http.Request) {
 	base := &action.Base
 	base.Prepare(c, w, r)
 	action.App = action.GojiCtx.Env["app"].(*App)
+	action.Heartbeat = action.App.currentConfig().SSEHeartbeat
+	action.Streams = action.App.streams
+	action.Compress = action.App.config.SSECompression
+	action.JSONP = action.App.config.JSONP
+	action.Replay = action.App.streamReplay
+	action.StatusInterval = action.App.config.SSEStatusInterval
+	action.Buffer = sse.BufferConfig{Size: action.App.config.SSEBufferSize}
+	action.Cursors = action.App.cursors
+	action.Status = func() (interface{}, error) {
+		return action.App.StreamStatus(action.Ctx)
+	}
+
+	// the actions of a network served under a path of its parent link to the
+	// resources under that path, and stream by the pump of the network
+	if action.App.network != "" {
+		action.LinkPrefix = "/" + action.App.network
+	}
+	if action.App.streamPump != nil {
+		action.Pumped = action.App.streamPump.Pumped
+	}
+
+	if max := action.App.config.SSEMaxRetry; max > 0 && action.Retry.Pressure == nil {
+		action.Retry.Pressure = action.App.streams.Pressure
+		action.Retry.Max = max
+	}
+}
+
+// Select behaves like db.Select.  While streaming, however, identical queries
+// made by other streams since the last pump share a single trip to the
+// database through the app's stream hub.  dest is populated with the shared
+// result, and must not be modified.
+func (action *Action) Select(q db.Query, dest interface{}) error {
+	if !action.Streaming {
+		return db.Select(action.Ctx, q, dest)
+	}
+
+	key, err := querySignature(q, dest)
+	if err != nil {
+		return db.Select(action.Ctx, q, dest)
+	}
+
+	dv := reflect.ValueOf(dest).Elem()
+	shared, err := action.App.streamHub.Fetch(key, func() (interface{}, error) {
+		records := reflect.New(dv.Type())
+		err := db.Select(action.Ctx, q, records.Interface())
+		return records.Elem().Interface(), err
+	})
+
+	if err != nil {
+		return err
+	}
+
+	dv.Set(reflect.ValueOf(shared))
+	return nil
+}
+
+// Immutable returns q, loading its results through the app's query cache.  Use
+// it for queries of resources that never change once found, such as closed
+// ledgers.
+func (action *Action) Immutable(q db.Query) db.Query {
+	return action.App.cache.Immutable(q)
+}
+
+// Mutable returns q, loading its results through the app's query cache, which
+// forgets them as the next ledger is ingested.  Streams, which query as each
+// ledger is pumped, perhaps before the cache has forgotten the last one, load
+// them from the database.
+func (action *Action) Mutable(q db.Query) db.Query {
+	if action.Streaming {
+		return q
+	}
+
+	return action.App.cache.Mutable(q)
+}
+
+// EnsureHistoryFreshness populates err with problem.StaleHistory when history
+// lags further behind stellar-core than Config.HistoryStaleThreshold allows.
+func (action *Action) EnsureHistoryFreshness() {
+	threshold := int32(action.App.config.HistoryStaleThreshold)
+	if action.Err != nil || threshold == 0 {
+		return
+	}
+
+	ls, err := action.App.LedgerState(action.Ctx)
+	if err != nil {
+		action.Err = err
+		return
+	}
+
+	if ls.StellarCoreSequence-ls.HorizonSequence <= threshold {
+		return
+	}
+
+	action.Err = problem.StaleHistory.With(map[string]interface{}{
+		"history_latest_ledger": ls.HorizonSequence,
+		"core_latest_ledger":    ls.StellarCoreSequence,
+	})
+}
+
+// ValidateLedgerWithinHistory populates err with problem.BeforeHistory when
+// ledger is older than the oldest ledger history holds, as once the reaper has
+// removed it.
+func (action *Action) ValidateLedgerWithinHistory(ledger int32) {
+	if action.Err != nil {
+		return
+	}
+
+	ls, err := action.App.LedgerState(action.Ctx)
+	if err != nil {
+		action.Err = err
+		return
+	}
+
+	if ledger >= ls.HorizonElderSequence {
+		return
+	}
+
+	action.Err = problem.BeforeHistory.With(map[string]interface{}{
+		"history_elder_ledger": ls.HorizonElderSequence,
+	})
+}
+
+// ValidateCursorWithinHistory behaves like ValidateLedgerWithinHistory for the
+// ledger of the cursor of page when it descends, finding records older than
+// the cursor which history no longer holds.
+func (action *Action) ValidateCursorWithinHistory(page db.PageQuery) {
+	if action.Err != nil || page.Order != db.OrderDescending {
+		return
+	}
+
+	cursor, err := page.CursorInt64()
+	if err != nil {
+		return
+	}
+
+	action.ValidateLedgerWithinHistory(db.ParseTotalOrderId(cursor).LedgerSequence)
+}
+
+// GetCallbackURL returns the callback_url param, the https url of a public
+// host to post the outcome of a transaction submission to, or an empty string
+// when none is given.  Populates err when the app's webhooks would not deliver
+// to the url, or the app sends no callbacks.
+func (action *Action) GetCallbackURL() string {
+	raw := action.GetString("callback_url")
+	if action.Err != nil || raw == "" {
+		return ""
+	}
+
+	if action.App.webhooks == nil {
+		action.Err = &problem.P{
+			Type:   "callbacks_disabled",
+			Title:  "Callbacks Disabled",
+			Status: http.StatusBadRequest,
+			Detail: "This server does not post callbacks, as it has no secret to sign them with.  Submit the transaction without a callback_url.",
+		}
+		return ""
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || action.App.webhooks.ValidateURL(u) != nil {
+		action.Err = &problem.P{
+			Type:   "invalid_callback_url",
+			Title:  "Invalid Callback URL",
+			Status: http.StatusBadRequest,
+			Detail: "The callback_url param must be an https url of a host on the public internet, such as https://example.com/callbacks.",
+		}
+		return ""
+	}
+
+	return u.String()
+}
+
+// querySignature returns a key that is identical for queries of the same type
+// and parameters, loading the same type of records.
+func querySignature(q db.Query, dest interface{}) (string, error) {
+	params, err := json.Marshal(q)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%T:%T:%s", q, dest, params), nil
 }
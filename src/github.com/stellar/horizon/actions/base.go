@@ -1,17 +1,36 @@
 package actions
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	gctx "github.com/goji/context"
 
+	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/httpx"
+	"github.com/stellar/horizon/log"
 	"github.com/stellar/horizon/render"
+	"github.com/stellar/horizon/render/hal"
+	"github.com/stellar/horizon/render/jsonapi"
+	"github.com/stellar/horizon/render/longpoll"
+	"github.com/stellar/horizon/render/ndjson"
 	"github.com/stellar/horizon/render/problem"
 	"github.com/stellar/horizon/render/sse"
+	"github.com/stellar/horizon/render/ws"
 	"github.com/zenazn/goji/web"
 	"golang.org/x/net/context"
 )
 
+const (
+	// maxBatchSize is the largest batch of events a client may ask for.
+	maxBatchSize = 200
+	// maxBatchWindow is the longest a client may ask events to be held back
+	// while a batch fills.
+	maxBatchWindow = 10 * time.Second
+)
+
 // Base is a helper struct you can use as part of a custom action via
 // composition.
 //
@@ -22,6 +41,58 @@ type Base struct {
 	W       http.ResponseWriter
 	R       *http.Request
 	Err     error
+
+	// Heartbeat is the idle interval after which streaming responses send a
+	// keepalive to the client.  Zero disables keepalives.
+	Heartbeat time.Duration
+
+	// Streams, when set, tracks and limits the streaming responses open across
+	// the server.
+	Streams *sse.ConnectionRegistry
+
+	// Compress enables compression of event streams for clients that accept it.
+	Compress bool
+
+	// Buffer, when its Size is not zero, queues the events of event streams
+	// for sending through an sse.BufferedWriter, ending the streams of clients
+	// that fall more than Size events behind.
+	Buffer sse.BufferConfig
+
+	// JSONP enables the callback param, through which clients may ask for a
+	// json response as a script that calls the named function with the
+	// response's document.  Problems are never rendered as scripts.
+	JSONP bool
+
+	// Streaming is true while the action is serving a stream, rather than a
+	// single response.
+	Streaming bool
+
+	// Retry controls the retry hints sent to streaming clients.  As actions are
+	// copied for every request, an endpoint may choose its own policy by
+	// setting Retry on the action it registers with the router.
+	Retry sse.RetryPolicy
+
+	// Replay, when set, keeps the events recently sent on streams so that
+	// reconnecting clients can be sent the events they missed from memory.
+	Replay *sse.ReplayBuffer
+
+	// Status, when set, provides the data of the status events sent to
+	// streaming clients every StatusInterval.
+	Status         func() (interface{}, error)
+	StatusInterval time.Duration
+
+	// Pumped, when set, returns the channel streams wait on for their next
+	// pump, in place of sse.Pumped.
+	Pumped func() <-chan struct{}
+
+	// LinkPrefix prefixes the links of the documents and events the action
+	// renders, such as "/testnet" when it is served under that path.
+	LinkPrefix string
+
+	// Cursors, when set, encodes the paging tokens of the documents and events
+	// the action renders as opaque cursors, issued for the path of its
+	// endpoint, and decodes the cursors its clients page by.
+	Cursors *db.CursorCodec
 }
 
 // Prepare established the common attributes that get used in nearly every
@@ -37,57 +108,440 @@ func (base *Base) Prepare(c web.C, w http.ResponseWriter, r *http.Request) {
 // Execute trigger content negottion and the actual execution of one of the
 // action's handlers.
 func (base *Base) Execute(action interface{}) {
-	contentType := render.Negotiate(base.Ctx, base.R)
+	// websocket clients are served by the action's SSE handler, regardless of
+	// the content type they negotiated.
+	if ws.IsUpgrade(base.R) {
+		action, ok := action.(SSE)
+		if !ok {
+			problem.Render(base.Ctx, base.W, problem.NotAcceptable)
+			return
+		}
+
+		base.serveWebsocket(action)
+		return
+	}
 
-	switch contentType {
-	case render.MimeHal, render.MimeJSON:
-		action, ok := action.(JSON)
+	var renderer render.Renderer
 
-		if !ok {
-			goto NotAcceptable
+	if action, ok := action.(JSON); ok {
+		renderer.HAL = func() {
+			w, ok := base.formatWriter()
+			if !ok {
+				return
+			}
+
+			base.W = hal.WithFields(
+				base.withCursors(hal.WithLinkPrefix(hal.WithDecodedXDR(w, base.DecodeXDR()), base.LinkPrefix)),
+				base.R.URL.Query().Get(ParamFields),
+			)
+			action.JSON()
+			base.renderErr()
 		}
 
-		action.JSON()
+		// JSON:API documents are converted from the HAL the action renders
+		renderer.JSONAPI = func() {
+			jw := jsonapi.NewWriter(base.W, base.R.URL.Path)
+			base.W = hal.WithFields(
+				base.withCursors(hal.WithLinkPrefix(hal.WithDecodedXDR(jw, base.DecodeXDR()), base.LinkPrefix)),
+				base.R.URL.Query().Get(ParamFields),
+			)
+			action.JSON()
+			base.renderErr()
+		}
+	}
 
-		if base.Err != nil {
-			problem.Render(base.Ctx, base.W, base.Err)
+	if action, ok := action.(SSE); ok {
+		renderer.EventStream = func() {
+			base.serveEventStream(action)
+		}
+
+		// long-polling clients ask for json, but are served by the action's SSE
+		// handler.
+		if base.R.URL.Query().Get(ParamWait) != "" {
+			renderer.HAL = func() {
+				base.longPoll(action)
+			}
+		}
+	}
+
+	if action, ok := action.(XDR); ok {
+		renderer.XDR = func() {
+			action.XDR()
+			base.renderErr()
+		}
+	}
+
+	if action, ok := action.(CSV); ok {
+		renderer.CSV = func() {
+			action.CSV()
+			base.renderErr()
+		}
+	}
+
+	if action, ok := action.(NDJSON); ok {
+		renderer.NDJSON = func() {
+			base.export(action)
+		}
+	}
+
+	renderer.Render(base.Ctx, base.W, base.R)
+}
+
+// renderErr renders the action's error, if any, as a problem.
+func (base *Base) renderErr() {
+	if base.Err != nil {
+		problem.Render(base.Ctx, base.W, base.Err)
+	}
+}
+
+// formatWriter wraps base.W so that the json rendered to it is formatted as the
+// pretty and, when JSONP is enabled, callback params ask.  Renders a problem and
+// returns false if the callback is not a valid function name.
+func (base *Base) formatWriter() (http.ResponseWriter, bool) {
+	var callback string
+	if base.JSONP {
+		callback = base.R.URL.Query().Get(ParamCallback)
+	}
+
+	if callback != "" && !hal.ValidCallback(callback) {
+		problem.Render(base.Ctx, base.W, &problem.P{
+			Type:   "invalid_callback",
+			Title:  "Invalid Callback",
+			Status: http.StatusBadRequest,
+			Detail: "The callback param must be the name of a javascript function, " +
+				"e.g. handleResponse.",
+		})
+		return nil, false
+	}
+
+	return hal.WithFormat(base.W, base.Pretty(), callback), true
+}
+
+// export writes the records the action exports as newline-delimited json,
+// ending the export with the action's error, if any.
+func (base *Base) export(action NDJSON) {
+	stream := ndjson.NewStream(base.Ctx, base.W)
+	if base.Cursors != nil {
+		stream.Transform(base.EncodeCursors)
+	}
+	action.NDJSON(stream)
+
+	if base.Err != nil {
+		stream.Err(base.Err)
+	}
+}
+
+// liftTimeout lifts the timeout of the request, which streams are not held to:
+// their heartbeats keep them open for as long as their clients stay, and long
+// polls wait as long as their clients ask.
+func (base *Base) liftTimeout() {
+	base.Ctx = httpx.WithoutRequestTimeout(base.Ctx)
+}
+
+// serveWebsocket upgrades the request to a websocket, over which it streams
+// the action's events.
+func (base *Base) serveWebsocket(action SSE) {
+	base.liftTimeout()
+
+	opts, ok := base.streamOptions()
+	if !ok {
+		return
+	}
+
+	release, ok := base.acquireStream()
+	if !ok {
+		return
+	}
+	defer release()
+
+	stream, ok := ws.NewStream(base.Ctx, base.W, base.R)
+	if !ok {
+		return
+	}
+
+	base.stream(action, stream, opts)
+}
+
+// serveEventStream streams the action's events as a text/event-stream
+// response.
+func (base *Base) serveEventStream(action SSE) {
+	base.liftTimeout()
+
+	opts, ok := base.streamOptions()
+	if !ok {
+		return
+	}
+
+	release, ok := base.acquireStream()
+	if !ok {
+		return
+	}
+	defer release()
+
+	w := base.W
+	if base.Compress {
+		var done func()
+		w, done = sse.Compress(base.W, base.R)
+		defer done()
+	}
+
+	serializer := sse.JSON
+	if base.Pretty() {
+		serializer = sse.PrettyJSON
+	}
+
+	stream, done, ok := sse.NewBufferedStream(base.Ctx, w, base.R, base.Retry, serializer, base.Buffer)
+	if !ok {
+		return
+	}
+	defer done()
+
+	base.stream(action, stream, opts)
+}
+
+// streamOptions are the params that alter how a stream's events are sent.
+type streamOptions struct {
+	// filter restricts the events sent to those matching it.
+	filter *sse.Filter
+	// batch coalesces events into batches.
+	batch sse.BatchConfig
+}
+
+// streamOptions parses the filter, batch and batch_window params.  Renders a
+// problem and returns false if any is invalid.
+func (base *Base) streamOptions() (streamOptions, bool) {
+	var opts streamOptions
+	var err error
+	query := base.R.URL.Query()
+
+	opts.filter, err = sse.ParseFilter(query.Get(ParamFilter))
+	if err != nil {
+		problem.Render(base.Ctx, base.W, &problem.P{
+			Type:   "invalid_filter",
+			Title:  "Invalid Filter",
+			Status: http.StatusBadRequest,
+			Detail: "The filter param could not be parsed: " + err.Error() + ".  " +
+				"Filters are a list of field=value or field!=value terms separated " +
+				"by '&', e.g. type=payment&asset_code=USD.",
+		})
+		return opts, false
+	}
+
+	invalidBatch := &problem.P{
+		Type:   "invalid_batch",
+		Title:  "Invalid Batch",
+		Status: http.StatusBadRequest,
+		Detail: fmt.Sprintf("The batch param must be a number of events from 1 to "+
+			"%d, and the batch_window param a duration of at most %s, e.g. 500ms.",
+			maxBatchSize, maxBatchWindow),
+	}
+
+	if size := query.Get(ParamBatch); size != "" {
+		opts.batch.Size, err = strconv.Atoi(size)
+		if err != nil || opts.batch.Size < 1 || opts.batch.Size > maxBatchSize {
+			problem.Render(base.Ctx, base.W, invalidBatch)
+			return opts, false
+		}
+	}
+
+	if window := query.Get(ParamBatchWindow); window != "" {
+		opts.batch.Window, err = time.ParseDuration(window)
+		if err != nil || opts.batch.Window < 0 || opts.batch.Window > maxBatchWindow {
+			problem.Render(base.Ctx, base.W, invalidBatch)
+			return opts, false
+		}
+	}
+
+	return opts, true
+}
+
+// acquireStream registers a new stream with base.Streams, rendering a problem
+// and returning false when the server's streaming limits have been reached.
+func (base *Base) acquireStream() (func(), bool) {
+	release, err := base.Streams.Acquire(base.R)
+	switch err {
+	case nil:
+	case sse.ErrShuttingDown:
+		problem.Render(base.Ctx, base.W, problem.ShuttingDown)
+		return nil, false
+	default:
+		problem.Render(base.Ctx, base.W, problem.TooManyStreams)
+		return nil, false
+	}
+
+	return release, true
+}
+
+// longPoll serves the action's events as a single json response, holding the
+// request open for up to the duration of the wait param until there are events
+// to deliver.
+func (base *Base) longPoll(action SSE) {
+	base.liftTimeout()
+
+	wait, err := longpoll.ParseWait(base.R.URL.Query().Get(ParamWait))
+	if err != nil {
+		problem.Render(base.Ctx, base.W, &problem.P{
+			Type:   "invalid_wait",
+			Title:  "Invalid Wait",
+			Status: http.StatusBadRequest,
+			Detail: "The wait param must be a duration, e.g. 30s, or a number of " +
+				"seconds.",
+		})
+		return
+	}
+
+	opts, ok := base.streamOptions()
+	if !ok {
+		return
+	}
+
+	release, ok := base.acquireStream()
+	if !ok {
+		return
+	}
+	defer release()
+
+	stream := longpoll.NewStream(base.Ctx, base.R)
+
+	// the stream ends, delivering nothing, if the wait expires
+	parent := base.Ctx
+	ctx, cancel := context.WithTimeout(parent, wait)
+	defer cancel()
+	base.Ctx = ctx
+	opts.batch = sse.BatchConfig{} // events are delivered together regardless
+	base.stream(action, stream, opts)
+	base.Ctx = parent
+
+	stream.Render(base.W)
+}
+
+// stream repeatedly runs the action's SSE handler against the provided stream,
+// once per pump, until the stream is done or the request is cancelled.
+//
+// When the events missed by a reconnecting client can be replayed from
+// base.Replay, the stream continues from the last event replayed and the
+// handler is not run until the next pump.
+//
+// The queries of the handler are db.StreamQueries, under their own timeout.
+func (base *Base) stream(action SSE, stream sse.Stream, opts streamOptions) {
+	base.Streaming = true
+
+	parent := base.Ctx
+	base.Ctx = db.WithQueryClass(parent, db.StreamQueries)
+	defer func() { base.Ctx = parent }()
+
+	heartbeat := sse.NewHeartbeat(base.Heartbeat)
+	defer heartbeat.Stop()
+
+	statusInterval := base.StatusInterval
+	if base.Status == nil {
+		statusInterval = 0
+	}
+	status := sse.NewHeartbeat(statusInterval)
+	defer status.Stop()
+
+	// batch is nil when batching is disabled
+	batched := sse.NewBatchedStream(stream, opts.batch)
+	batch, _ := batched.(*sse.BatchedStream)
+
+	stream, replayed := sse.NewReplayStream(
+		sse.NewFilteredStream(batched, opts.filter),
+		base.Replay,
+		base.replayTopic(),
+	)
+	stream = sse.NewLinkedStream(stream, base.LinkPrefix)
+	if enc := base.cursorEncoder(); enc != nil {
+		stream = sse.NewCursorStream(stream, enc, base.R.URL.Path)
+	}
+	if replayed != "" {
+		base.R.Header.Set("Last-Event-ID", replayed)
+	}
+
+	for {
+		sent := stream.SentCount()
+		if replayed == "" {
+			action.SSE(stream)
+		}
+		replayed = ""
+
+		// without a window, batches never outlive the handler run that queued
+		// their events
+		if batch != nil && opts.batch.Window == 0 {
+			batch.Flush()
+		}
+
+		if stream.IsDone() {
 			return
 		}
 
-	case render.MimeEventStream:
-		action, ok := action.(SSE)
-		if !ok {
-			goto NotAcceptable
+		if stream.SentCount() > sent {
+			heartbeat.Reset()
 		}
 
-		stream, ok := sse.NewStream(base.Ctx, base.W, base.R)
-		if !ok {
+		if !base.waitForPump(stream, heartbeat, status, batch) {
 			return
 		}
+	}
+}
 
-		for {
-			action.SSE(stream)
+// replayTopic returns the topic under which the events of the current stream
+// are kept in base.Replay: the request's path and params, less those that only
+// alter where in the sequence of events a client starts or which it receives.
+func (base *Base) replayTopic() string {
+	query := base.R.URL.Query()
+	for _, param := range []string{ParamCursor, ParamLimit, ParamFilter, ParamWait, ParamBatch, ParamBatchWindow, ParamPretty, "last_event_id"} {
+		query.Del(param)
+	}
 
-			if stream.IsDone() {
-				return
-			}
+	return base.R.URL.Path + "?" + query.Encode()
+}
 
-			select {
-			case <-base.Ctx.Done():
-				return
-			case <-sse.Pumped():
-				//no-op, continue onto the next iteration
-			}
+// waitForPump blocks until the next pump, sending keepalives and status events
+// to the client while it waits and flushing batch, if any, as its window
+// passes.  Returns false if the request was cancelled, or the server began
+// shutting down, while waiting.
+func (base *Base) waitForPump(stream sse.Stream, heartbeat, status *sse.Heartbeat, batch *sse.BatchedStream) bool {
+	pumped := sse.Pumped()
+	if base.Pumped != nil {
+		pumped = base.Pumped()
+	}
+
+	for {
+		select {
+		case <-status.C():
+			base.sendStatus(stream)
+			status.Reset()
+		case <-batch.Due():
+			batch.Flush()
+			heartbeat.Reset()
+		case <-base.Ctx.Done():
+			return false
+		case <-base.Streams.Closing():
+			stream.Shutdown()
+			return false
+		case <-pumped:
+			return true
+		case <-heartbeat.C():
+			stream.Keepalive()
+			heartbeat.Reset()
 
+			if stream.IsDone() {
+				return false
+			}
 		}
-	default:
-		goto NotAcceptable
 	}
-	return
+}
+
+// sendStatus sends a status event to the client.  Failures to load the status
+// are logged and the event skipped, as the stream itself is unaffected.
+func (base *Base) sendStatus(stream sse.Stream) {
+	data, err := base.Status()
+	if err != nil {
+		log.Warnf(base.Ctx, "failed to load stream status: %s", err)
+		return
+	}
 
-NotAcceptable:
-	problem.Render(base.Ctx, base.W, problem.NotAcceptable)
-	return
+	stream.Meta(sse.Event{Event: "status", Data: data})
 }
 
 // Do executes the provided func iff there is no current error for the action. Provides
@@ -0,0 +1,93 @@
+package actions
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	gctx "github.com/goji/context"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/render/sse"
+	"github.com/stellar/horizon/test"
+	"github.com/zenazn/goji/web"
+)
+
+// streamTestAction streams count events, ending the stream after them when
+// done is set.
+type streamTestAction struct {
+	Base
+	count int
+	done  bool
+}
+
+func (action *streamTestAction) SSE(stream sse.Stream) {
+	for i := 1; i <= action.count; i++ {
+		stream.Send(sse.Event{ID: strconv.Itoa(i), Data: i})
+	}
+
+	if action.done {
+		stream.Done()
+	}
+}
+
+// stalledRecorder is a response recorder whose flushes, after the first,
+// block until released, as for a client that stopped reading.
+type stalledRecorder struct {
+	*httptest.ResponseRecorder
+	lock     sync.Mutex
+	flushes  int
+	released chan struct{}
+}
+
+func (w *stalledRecorder) Flush() {
+	w.lock.Lock()
+	w.flushes++
+	first := w.flushes == 1
+	w.lock.Unlock()
+
+	if !first {
+		<-w.released
+	}
+	w.ResponseRecorder.Flush()
+}
+
+func TestServeEventStream(t *testing.T) {
+	serve := func(action *streamTestAction, w http.ResponseWriter) {
+		c := web.C{Env: map[interface{}]interface{}{}}
+		gctx.Set(&c, test.Context())
+		r, _ := http.NewRequest("GET", "/stream", nil)
+		r.Header.Set("Accept", "text/event-stream")
+
+		action.Prepare(c, w, r)
+		action.Execute(action)
+	}
+
+	Convey("serveEventStream", t, func() {
+		Convey("sends the events of a buffered stream", func() {
+			w := httptest.NewRecorder()
+			action := &streamTestAction{count: 3, done: true}
+			action.Buffer = sse.BufferConfig{Size: 10}
+			serve(action, w)
+
+			So(w.Code, ShouldEqual, 200)
+			for i := 1; i <= 3; i++ {
+				So(w.Body.String(), ShouldContainSubstring, "id: "+strconv.Itoa(i)+"\n")
+			}
+		})
+
+		Convey("ends the buffered streams of clients that fall behind", func() {
+			w := &stalledRecorder{ResponseRecorder: httptest.NewRecorder(), released: make(chan struct{})}
+			time.AfterFunc(50*time.Millisecond, func() { close(w.released) })
+
+			action := &streamTestAction{count: 5}
+			action.Buffer = sse.BufferConfig{Size: 1}
+			serve(action, w)
+
+			So(w.Code, ShouldEqual, 200)
+			So(w.Body.String(), ShouldNotContainSubstring, "id: 5\n")
+		})
+	})
+}
@@ -1,4 +1,5 @@
 // Package actions provides the infrastructure for defining and executing
 // actions (code that is triggered in response to an client request) on horizon.
-// At present it allows for defining actions that can respond using JSON or SSE.
+// At present it allows for defining actions that can respond using JSON, SSE
+// or XDR.
 package actions
@@ -1,14 +1,21 @@
 package actions
 
 import (
+	"crypto/sha1"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
-	"github.com/go-errors/errors"
 	"github.com/stellar/go-stellar-base/xdr"
 	"github.com/stellar/horizon/assets"
 	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/paths"
+	"github.com/stellar/horizon/render/hal"
 	"github.com/stellar/horizon/render/problem"
+	"github.com/stellar/horizon/render/sse"
+	"github.com/stellar/horizon/strkeys"
 )
 
 const (
@@ -18,6 +25,32 @@ const (
 	ParamOrder = "order"
 	// ParamLimit is a query string param name
 	ParamLimit = "limit"
+	// ParamFilter is a query string param name
+	ParamFilter = "filter"
+	// ParamWait is a query string param name
+	ParamWait = "wait"
+	// ParamBatch is a query string param name
+	ParamBatch = "batch"
+	// ParamBatchWindow is a query string param name
+	ParamBatchWindow = "batch_window"
+	// ParamFields is a query string param name
+	ParamFields = "fields"
+	// ParamIncludeXDR is a query string param name
+	ParamIncludeXDR = "include_xdr"
+	// ParamDecodeXDR is a query string param name
+	ParamDecodeXDR = "decode_xdr"
+	// ParamIncludeFailed is a query string param name
+	ParamIncludeFailed = "include_failed"
+	// ParamEmbed is a query string param name
+	ParamEmbed = "embed"
+	// ParamPretty is a query string param name
+	ParamPretty = "pretty"
+	// ParamCallback is a query string param name
+	ParamCallback = "callback"
+	// ParamStartTime is a query string param name
+	ParamStartTime = "start_time"
+	// ParamEndTime is a query string param name
+	ParamEndTime = "end_time"
 )
 
 // OrderBookParams is a helper struct that encapsulates the specification for
@@ -54,7 +87,8 @@ func (base *Base) GetString(name string) string {
 }
 
 // GetInt64 retrieves an int64 from the action parameter of the given name.
-// Populates err if the value is not a valid int64
+// Populates err with a problem naming the param if the value is not a valid
+// int64
 func (base *Base) GetInt64(name string) int64 {
 	if base.Err != nil {
 		return 0
@@ -69,7 +103,7 @@ func (base *Base) GetInt64(name string) int64 {
 	asI64, err := strconv.ParseInt(asStr, 10, 64)
 
 	if err != nil {
-		base.Err = errors.Wrap(err, 1)
+		base.Err = problem.InvalidParam(name, "must be an integer")
 		return 0
 	}
 
@@ -81,8 +115,147 @@ func (base *Base) ValidateInt64(name string) {
 	_ = base.GetInt64(name)
 }
 
+// ValidateCursor populates err if the cursor param, once decoded, is not a
+// valid int64
+func (base *Base) ValidateCursor() {
+	if base.Err != nil {
+		return
+	}
+
+	query := db.PageQuery{
+		Cursor: base.DecodeCursor(base.GetString(ParamCursor)),
+		Order:  db.OrderAscending,
+	}
+	if base.Err != nil {
+		return
+	}
+
+	if _, err := query.CursorInt64(); err != nil {
+		base.Err = problem.InvalidParam(ParamCursor, "must be the paging_token of a record")
+	}
+}
+
+// IncludeXDR returns false if the client asked, through the include_xdr param,
+// for resources to be rendered without their xdr fields.  Populates err if the
+// value is not a valid bool
+func (base *Base) IncludeXDR() bool {
+	if base.Err != nil {
+		return true
+	}
+
+	asStr := base.GetString(ParamIncludeXDR)
+
+	if asStr == "" {
+		return true
+	}
+
+	include, err := strconv.ParseBool(asStr)
+
+	if err != nil {
+		base.Err = problem.InvalidParam(ParamIncludeXDR, "must be true or false")
+		return true
+	}
+
+	return include
+}
+
+// DecodeXDR returns true if the client asked, through the decode_xdr param,
+// for the xdr fields of resources to be rendered decoded as well, for people
+// to read.  Populates err if the value is not a valid bool
+func (base *Base) DecodeXDR() bool {
+	if base.Err != nil {
+		return false
+	}
+
+	asStr := base.GetString(ParamDecodeXDR)
+
+	if asStr == "" {
+		return false
+	}
+
+	decode, err := strconv.ParseBool(asStr)
+
+	if err != nil {
+		base.Err = problem.InvalidParam(ParamDecodeXDR, "must be true or false")
+		return false
+	}
+
+	return decode
+}
+
+// IncludeFailed returns true if the client asked, through the include_failed
+// param, for failed transactions to be included alongside successful ones.
+// Populates err if the value is not a valid bool
+func (base *Base) IncludeFailed() bool {
+	if base.Err != nil {
+		return false
+	}
+
+	asStr := base.GetString(ParamIncludeFailed)
+
+	if asStr == "" {
+		return false
+	}
+
+	include, err := strconv.ParseBool(asStr)
+
+	if err != nil {
+		base.Err = problem.InvalidParam(ParamIncludeFailed, "must be true or false")
+		return false
+	}
+
+	return include
+}
+
+// Pretty returns true if the client asked, through the pretty param, for json
+// to be indented for human readers.  Values that are not valid bools are
+// treated as false.
+func (base *Base) Pretty() bool {
+	pretty, err := strconv.ParseBool(base.R.URL.Query().Get(ParamPretty))
+	return err == nil && pretty
+}
+
+// GetEmbeds returns the names of the related collections the client asked,
+// through the embed param, to have embedded in the response.  Populates err if
+// any of them is not one of allowed.
+func (base *Base) GetEmbeds(allowed ...string) (result []string) {
+	if base.Err != nil {
+		return
+	}
+
+	for _, name := range strings.Split(base.GetString(ParamEmbed), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		ok := false
+		for _, a := range allowed {
+			ok = ok || a == name
+		}
+
+		if !ok {
+			base.Err = &problem.P{
+				Type:   "invalid_embed",
+				Title:  "Invalid Embed",
+				Status: http.StatusBadRequest,
+				Detail: fmt.Sprintf(
+					"%q cannot be embedded in this resource.  The embed param may name: %s.",
+					name, strings.Join(allowed, ", "),
+				),
+			}
+			return nil
+		}
+
+		result = append(result, name)
+	}
+
+	return
+}
+
 // GetInt32 retrieves an int32 from the action parameter of the given name.
-// Populates err if the value is not a valid int32
+// Populates err with a problem naming the param if the value is not a valid
+// int32
 func (base *Base) GetInt32(name string) int32 {
 	if base.Err != nil {
 		return 0
@@ -97,15 +270,168 @@ func (base *Base) GetInt32(name string) int32 {
 	asI64, err := strconv.ParseInt(asStr, 10, 32)
 
 	if err != nil {
-		base.Err = errors.Wrap(err, 1)
+		base.Err = problem.InvalidParam(name, "must be a 32-bit integer")
 		return 0
 	}
 
 	return int32(asI64)
 }
 
+// GetTime retrieves a time, formatted according to RFC3339, from the action
+// parameter of the given name.  Populates err if the value is not a valid
+// time.
+func (base *Base) GetTime(name string) time.Time {
+	if base.Err != nil {
+		return time.Time{}
+	}
+
+	asStr := base.GetString(name)
+
+	if asStr == "" {
+		return time.Time{}
+	}
+
+	t, err := time.Parse(time.RFC3339, asStr)
+
+	if err != nil {
+		base.Err = problem.InvalidParam(name, "must be a time formatted according to RFC3339, such as 2015-10-07T23:07:27Z")
+		return time.Time{}
+	}
+
+	return t
+}
+
+// GetTimeRange returns the range of close times, given by the start_time and
+// end_time params, that a page of history is restricted to.  Populates err if
+// the range ends before it starts.
+func (base *Base) GetTimeRange() db.TimeRange {
+	r := db.TimeRange{
+		Start: base.GetTime(ParamStartTime),
+		End:   base.GetTime(ParamEndTime),
+	}
+
+	if base.Err != nil {
+		return db.TimeRange{}
+	}
+
+	if !r.Start.IsZero() && !r.End.IsZero() && r.End.Before(r.Start) {
+		base.Err = problem.InvalidParam(ParamEndTime, "must not be before start_time")
+		return db.TimeRange{}
+	}
+
+	return r
+}
+
+// GetOrder returns the order, asc or desc, given by the order param, asc when
+// it is missing.  Populates err if it is neither.
+func (base *Base) GetOrder() string {
+	if base.Err != nil {
+		return ""
+	}
+
+	switch order := base.GetString(ParamOrder); order {
+	case "":
+		return db.OrderAscending
+	case db.OrderAscending, db.OrderDescending:
+		return order
+	default:
+		base.Err = problem.InvalidParam(ParamOrder, "must be asc or desc")
+		return ""
+	}
+}
+
+// GetLimit returns the number of records, given by the limit param, that a
+// page holds: def when it is missing.  Populates err if it is not a number
+// from 1 to max.
+func (base *Base) GetLimit(def int32, max int32) int32 {
+	if base.Err != nil {
+		return 0
+	}
+
+	if base.GetString(ParamLimit) == "" {
+		return def
+	}
+
+	limit := base.GetInt32(ParamLimit)
+	if base.Err == nil && (limit < 1 || limit > max) {
+		base.Err = problem.InvalidParam(ParamLimit, fmt.Sprintf("must be a number from 1 to %d", max))
+	}
+
+	return limit
+}
+
+// GetAsset returns the asset, in the form CODE:ISSUER or native, given by the
+// param of the given name.  Populates err if the value is not an asset, or is
+// native and native is false, or its issuer is not an account id.  A missing
+// param returns the zero asset.
+func (base *Base) GetAsset(name string, native bool) paths.Asset {
+	if base.Err != nil {
+		return paths.Asset{}
+	}
+
+	asStr := base.GetString(name)
+	if asStr == "" {
+		return paths.Asset{}
+	}
+
+	reason := "must be an asset of the form CODE:ISSUER"
+	if native {
+		reason += ", or native"
+	}
+
+	asset, err := paths.ParseAsset(asStr)
+	if err != nil || (asset == paths.Native && !native) {
+		base.Err = problem.InvalidParam(name, reason)
+		return paths.Asset{}
+	}
+
+	if asset != paths.Native {
+		if err := strkeys.ValidateAccountID(asset.Issuer); err != nil {
+			base.Err = AccountIDProblem(name, err)
+			return paths.Asset{}
+		}
+	}
+
+	return asset
+}
+
+// GetAccountID returns the account id, such as GABC..., given by the param of
+// the given name.  Populates err with the problem of AccountIDProblem if the
+// value is not an account id.  A missing param returns "".
+func (base *Base) GetAccountID(name string) string {
+	if base.Err != nil {
+		return ""
+	}
+
+	asStr := base.GetString(name)
+	if asStr == "" {
+		return ""
+	}
+
+	if err := strkeys.ValidateAccountID(asStr); err != nil {
+		base.Err = AccountIDProblem(name, err)
+		return ""
+	}
+
+	return asStr
+}
+
+// AccountIDProblem returns the problem of the param name, whose value was
+// refused by strkeys.ValidateAccountID with err: SecretSeedGiven for seeds,
+// and InvalidAccountID otherwise.
+func AccountIDProblem(name string, err error) *problem.P {
+	p := problem.InvalidAccountID
+	if err == strkeys.ErrSeed {
+		p = problem.SecretSeedGiven
+	}
+
+	return p.With(map[string]interface{}{"invalid_field": name})
+}
+
 // GetPagingParams returns the cursor/order/limit triplet that is the
-// standard way of communicating paging data to a horizon endpoint.
+// standard way of communicating paging data to a horizon endpoint.  A
+// streaming client's last event id, if present, takes precedence over the
+// cursor param so that reconnecting clients resume where they left off.
 func (base *Base) GetPagingParams() (cursor string, order string, limit int32) {
 	if base.Err != nil {
 		return
@@ -115,21 +441,76 @@ func (base *Base) GetPagingParams() (cursor string, order string, limit int32) {
 	order = base.GetString(ParamOrder)
 	limit = base.GetInt32(ParamLimit)
 
-	if lei := base.R.Header.Get("Last-Event-ID"); lei != "" {
+	if lei := sse.LastEventID(base.R); lei != "" {
 		cursor = lei
 	}
 
+	cursor = base.DecodeCursor(cursor)
 	return
 }
 
+// DecodeCursor returns the paging token of cursor, once decoded by
+// base.Cursors as a cursor issued for the action's endpoint.  Populates err if
+// it was not.
+func (base *Base) DecodeCursor(cursor string) string {
+	if base.Err != nil {
+		return ""
+	}
+
+	token, err := base.Cursors.Decode(base.R.URL.Path, cursor)
+	if err != nil {
+		base.Err = err
+		return ""
+	}
+
+	return token
+}
+
+// EncodeCursors returns data with its paging tokens encoded by base.Cursors,
+// as the documents the action renders are, for renderers other than HAL.  data
+// is returned unchanged when base.Cursors is nil.
+func (base *Base) EncodeCursors(data interface{}) (interface{}, error) {
+	enc := base.cursorEncoder()
+	if enc == nil {
+		return data, nil
+	}
+
+	return hal.EncodeCursors(data, enc, base.R.URL.Path)
+}
+
+// withCursors returns w, through which hal.Render encodes paging tokens with
+// base.Cursors, if set.
+func (base *Base) withCursors(w http.ResponseWriter) http.ResponseWriter {
+	return hal.WithCursors(w, base.cursorEncoder(), base.R.URL.Path)
+}
+
+// cursorEncoder returns base.Cursors as a hal.CursorEncoder, or nil when it is
+// nil.
+func (base *Base) cursorEncoder() hal.CursorEncoder {
+	if base.Cursors == nil {
+		return nil
+	}
+
+	return base.Cursors
+}
+
 // GetPageQuery is a helper that returns a new db.PageQuery struct initialized
-// using the results from a call to GetPagingParams()
+// using the results from a call to GetPagingParams(), with the order and
+// limit validated by GetOrder and GetLimit.
 func (base *Base) GetPageQuery() db.PageQuery {
 	if base.Err != nil {
 		return db.PageQuery{}
 	}
 
-	r, err := db.NewPageQuery(base.GetPagingParams())
+	cursor, _, _ := base.GetPagingParams()
+	order := base.GetOrder()
+	limit := base.GetLimit(db.DefaultPageSize, db.MaxPageSize)
+
+	if base.Err != nil {
+		return db.PageQuery{}
+	}
+
+	r, err := db.NewPageQuery(cursor, order, limit)
 
 	if err != nil {
 		base.Err = err
@@ -138,6 +519,32 @@ func (base *Base) GetPageQuery() db.PageQuery {
 	return r
 }
 
+// NotModified sets the response's ETag, derived from the request's path,
+// params and accepted formats and from parts, which must identify the version
+// of the resource being rendered.  Returns true, having responded 304 Not
+// Modified, if the client's If-None-Match header holds the same ETag, in which
+// case the action should render nothing further.
+func (base *Base) NotModified(parts ...interface{}) bool {
+	h := sha1.New()
+	fmt.Fprintf(h, "%s?%s|%s", base.R.URL.Path, base.R.URL.RawQuery, base.R.Header.Get("Accept"))
+	for _, part := range parts {
+		fmt.Fprintf(h, "|%v", part)
+	}
+
+	etag := fmt.Sprintf(`"%x"`, h.Sum(nil))
+	base.W.Header().Set("ETag", etag)
+
+	for _, candidate := range strings.Split(base.R.Header.Get("If-None-Match"), ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			base.W.WriteHeader(http.StatusNotModified)
+			return true
+		}
+	}
+
+	return false
+}
+
 // GetAssetType is a helper that returns a xdr.AssetType by reading a string
 func (base *Base) GetAssetType(name string) xdr.AssetType {
 	if base.Err != nil {
@@ -4,10 +4,15 @@ import (
 	"fmt"
 	"math"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"testing"
+	"time"
 
 	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/paths"
+	"github.com/stellar/horizon/render/problem"
+	"github.com/stellar/horizon/strkeys"
 	"github.com/stellar/horizon/test"
 	"github.com/stellar/go-stellar-base/xdr"
 	"github.com/zenazn/goji/web"
@@ -99,6 +104,116 @@ func TestHelpers(t *testing.T) {
 			So(order, ShouldEqual, "")
 		})
 
+		Convey("GetInt32 names the invalid param", func() {
+			action.GetInt32("native_type")
+			So(action.Err, ShouldHaveSameTypeAs, &problem.P{})
+			p := action.Err.(*problem.P)
+			So(p.Type, ShouldEqual, "bad_request")
+			So(p.Extras["invalid_field"], ShouldEqual, "native_type")
+		})
+
+		Convey("GetLimit", func() {
+			So(action.GetLimit(10, 200), ShouldEqual, 2)
+			So(action.Err, ShouldBeNil)
+
+			for _, limit := range []string{"0", "-1", "201", "foo"} {
+				action.Err = nil
+				r, _ := http.NewRequest("GET", "/?limit="+limit, nil)
+				action.R = r
+				action.GetLimit(10, 200)
+				So(action.Err, ShouldNotBeNil)
+				So(action.Err.(*problem.P).Extras["invalid_field"], ShouldEqual, "limit")
+			}
+
+			action.Err = nil
+			r, _ := http.NewRequest("GET", "/", nil)
+			action.R = r
+			So(action.GetLimit(10, 200), ShouldEqual, 10)
+			So(action.Err, ShouldBeNil)
+		})
+
+		Convey("GetOrder", func() {
+			So(action.GetOrder(), ShouldEqual, "asc")
+
+			r, _ := http.NewRequest("GET", "/?order=desc", nil)
+			action.R = r
+			So(action.GetOrder(), ShouldEqual, "desc")
+			So(action.Err, ShouldBeNil)
+
+			r, _ = http.NewRequest("GET", "/?order=up", nil)
+			action.R = r
+			action.GetOrder()
+			So(action.Err.(*problem.P).Extras["invalid_field"], ShouldEqual, "order")
+		})
+
+		Convey("GetAsset", func() {
+			issuer := "GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4"
+			r, _ := http.NewRequest("GET", "/?asset=USD:"+issuer+"&native=native&bad=USD:GABC", nil)
+			action.R = r
+
+			asset := action.GetAsset("asset", false)
+			So(action.Err, ShouldBeNil)
+			So(asset.Code, ShouldEqual, "USD")
+			So(asset.Issuer, ShouldEqual, issuer)
+
+			So(action.GetAsset("native", true), ShouldResemble, paths.Native)
+			So(action.Err, ShouldBeNil)
+			So(action.GetAsset("missing", false), ShouldResemble, paths.Asset{})
+			So(action.Err, ShouldBeNil)
+
+			action.GetAsset("native", false)
+			So(action.Err.(*problem.P).Extras["invalid_field"], ShouldEqual, "native")
+
+			action.Err = nil
+			action.GetAsset("bad", true)
+			So(action.Err.(*problem.P).Type, ShouldEqual, "invalid_account_id")
+			So(action.Err.(*problem.P).Extras["invalid_field"], ShouldEqual, "bad")
+		})
+
+		Convey("GetAccountID", func() {
+			address := "GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4"
+			r, _ := http.NewRequest("GET", "/?good="+address+"&bad="+address[:55]+"A", nil)
+			action.R = r
+
+			So(action.GetAccountID("good"), ShouldEqual, address)
+			So(action.GetAccountID("missing"), ShouldEqual, "")
+			So(action.Err, ShouldBeNil)
+
+			So(action.GetAccountID("bad"), ShouldEqual, "")
+			So(action.Err.(*problem.P).Type, ShouldEqual, "invalid_account_id")
+			So(action.Err.(*problem.P).Extras["invalid_field"], ShouldEqual, "bad")
+		})
+
+		Convey("GetAccountID refuses seeds with a problem of their own", func() {
+			seed, err := strkeys.Encode(strkeys.Seed, make([]byte, 32))
+			So(err, ShouldBeNil)
+			r, _ := http.NewRequest("GET", "/?signer="+seed, nil)
+			action.R = r
+
+			So(action.GetAccountID("signer"), ShouldEqual, "")
+			So(action.Err.(*problem.P).Type, ShouldEqual, "secret_seed_given")
+			So(action.Err.(*problem.P).Extras["invalid_field"], ShouldEqual, "signer")
+		})
+
+		Convey("GetTimeRange", func() {
+			r, _ := http.NewRequest("GET", "/?start_time=2015-10-07T23:07:27Z&end_time=2015-10-07T23:07:28Z", nil)
+			action.R = r
+			tr := action.GetTimeRange()
+			So(action.Err, ShouldBeNil)
+			So(tr.End.Sub(tr.Start), ShouldEqual, time.Second)
+
+			r, _ = http.NewRequest("GET", "/?start_time=2015-10-07T23:07:28Z&end_time=2015-10-07T23:07:27Z", nil)
+			action.R = r
+			action.GetTimeRange()
+			So(action.Err.(*problem.P).Extras["invalid_field"], ShouldEqual, "end_time")
+
+			action.Err = nil
+			r, _ = http.NewRequest("GET", "/?start_time=yesterday", nil)
+			action.R = r
+			action.GetTimeRange()
+			So(action.Err.(*problem.P).Extras["invalid_field"], ShouldEqual, "start_time")
+		})
+
 		Convey("GetAssetType", func() {
 			t := action.GetAssetType("native_type")
 			So(t, ShouldEqual, xdr.AssetTypeAssetTypeNative)
@@ -120,6 +235,13 @@ func TestHelpers(t *testing.T) {
 			So(cursor, ShouldEqual, "from_header")
 		})
 
+		Convey("last_event_id param overrides cursor", func() {
+			r, _ := http.NewRequest("GET", "/?cursor=hello&last_event_id=from_param", nil)
+			action.R = r
+			cursor, _, _ := action.GetPagingParams()
+			So(cursor, ShouldEqual, "from_param")
+		})
+
 		Convey("Form values override query values", func() {
 			So(action.GetString("cursor"), ShouldEqual, "hello")
 
@@ -139,6 +261,25 @@ func TestHelpers(t *testing.T) {
 			So(action.Err, ShouldNotBeNil)
 		})
 
+		Convey("NotModified", func() {
+			w := httptest.NewRecorder()
+			action.W = w
+			So(action.NotModified(1, "abc"), ShouldBeFalse)
+			etag := w.Header().Get("ETag")
+			So(etag, ShouldStartWith, `"`)
+
+			w = httptest.NewRecorder()
+			action.W = w
+			r.Header.Set("If-None-Match", "W/"+etag)
+			So(action.NotModified(1, "abc"), ShouldBeTrue)
+			So(w.Code, ShouldEqual, http.StatusNotModified)
+
+			w = httptest.NewRecorder()
+			action.W = w
+			So(action.NotModified(2, "abc"), ShouldBeFalse)
+			So(w.Header().Get("ETag"), ShouldNotEqual, etag)
+		})
+
 		Convey("Path() return the action's http path", func() {
 			r, _ := http.NewRequest("GET", "/foo-bar/blah?limit=foo", nil)
 			action.R = r
@@ -1,6 +1,9 @@
 package actions
 
-import "github.com/stellar/horizon/render/sse"
+import (
+	"github.com/stellar/horizon/render/ndjson"
+	"github.com/stellar/horizon/render/sse"
+)
 
 // JSON implementors can respond to a request whose response type was negotiated
 // to be MimeHal or MimeJSON.
@@ -8,6 +11,25 @@ type JSON interface {
 	JSON()
 }
 
+// XDR implementors can respond to a request whose response type was negotiated
+// to be MimeXDR.
+type XDR interface {
+	XDR()
+}
+
+// CSV implementors can respond to a request whose response type was negotiated
+// to be MimeCSV.
+type CSV interface {
+	CSV()
+}
+
+// NDJSON implementors can respond to a request whose response type was
+// negotiated to be MimeNDJSON, by writing every record of the collection they
+// export to the stream.
+type NDJSON interface {
+	NDJSON(*ndjson.Stream)
+}
+
 // SSE implementors can respond to a request whose response type was negotiated
 // to be MimeEventStream.
 type SSE interface {
@@ -1,43 +1,90 @@
 package horizon
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
 	"github.com/stellar/horizon/actions"
 	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/paths"
 	"github.com/stellar/horizon/render/hal"
+	"github.com/stellar/horizon/render/problem"
 	"github.com/stellar/horizon/render/sse"
+	"github.com/stellar/horizon/strkeys"
 )
 
 // This file contains the actions:
 //
-// AccountIndexAction: pages of account's addresses in order of creation
+// AccountIndexAction: pages of account's addresses in order of creation, or
+//                     of accounts filtered by signer, asset or home domain
 // AccountShowAction: details for single account (including stellar-core state)
+// AccountBatchAction: details for many accounts at once
+// AccountBalancesAction: an account's balances as of a past ledger
 
 // AccountIndexAction renders a page of account resources, identified by
 // a normal page query, ordered by the operation id that created them.
+//
+// Given any of the signer, asset (as CODE:ISSUER) or home_domain params, the
+// page is instead one of full account summaries from stellar-core, restricted
+// to the accounts matching every filter and ordered by address.
 type AccountIndexAction struct {
 	Action
-	Query   db.HistoryAccountPageQuery
-	Records []db.HistoryAccountRecord
-	Page    hal.Page
+	Query         db.HistoryAccountPageQuery
+	Records       []db.HistoryAccountRecord
+	Filtered      bool
+	FilterQuery   db.CoreAccountPageQuery
+	FilterRecords []db.AccountRecord
+	Page          hal.Page
 }
 
-// LoadQuery sets action.Query from the request params
+// LoadQuery sets action.Query, or action.FilterQuery when filtering, from
+// the request params
 func (action *AccountIndexAction) LoadQuery() {
-	action.ValidateInt64(actions.ParamCursor)
-	action.Query = db.HistoryAccountPageQuery{
-		SqlQuery:  action.App.HistoryQuery(),
-		PageQuery: action.GetPageQuery(),
+	signer := action.GetAccountID("signer")
+	asset := action.GetAsset("asset", false)
+	domain := action.GetString("home_domain")
+	if action.Err != nil {
+		return
+	}
+
+	action.Filtered = signer != "" || asset != (paths.Asset{}) || domain != ""
+	if !action.Filtered {
+		action.ValidateCursor()
+		action.Query = db.HistoryAccountPageQuery{
+			SqlQuery:  action.App.HistoryQuery(),
+			PageQuery: action.GetPageQuery(),
+		}
+		action.ValidateCursorWithinHistory(action.Query.PageQuery)
+		action.EnsureHistoryFreshness()
+		return
+	}
+
+	action.FilterQuery = db.CoreAccountPageQuery{
+		SqlQuery:    action.App.CoreQuery(),
+		PageQuery:   action.GetPageQuery(),
+		Signer:      signer,
+		AssetCode:   asset.Code,
+		AssetIssuer: asset.Issuer,
+		HomeDomain:  domain,
 	}
 }
 
-// LoadRecords populates action.Records
+// LoadRecords populates action.Records, or action.FilterRecords when
+// filtering
 func (action *AccountIndexAction) LoadRecords() {
 	action.LoadQuery()
 	if action.Err != nil {
 		return
 	}
 
-	action.Err = db.Select(action.Ctx, action.Query, &action.Records)
+	if action.Filtered {
+		action.Err = action.Select(action.FilterQuery, &action.FilterRecords)
+		return
+	}
+
+	action.Err = action.Select(action.Query, &action.Records)
 }
 
 // LoadPage populates action.Page
@@ -47,6 +94,11 @@ func (action *AccountIndexAction) LoadPage() {
 		return
 	}
 
+	if action.Filtered {
+		action.Page = NewAccountResourcePage(action.FilterRecords, action.FilterQuery)
+		return
+	}
+
 	action.Page, action.Err = NewHistoryAccountResourcePage(action.Records, action.Query.PageQuery)
 }
 
@@ -67,6 +119,21 @@ func (action *AccountIndexAction) SSE(stream sse.Stream) {
 		return
 	}
 
+	if action.Filtered {
+		for _, record := range action.FilterRecords[stream.SentCount():] {
+			r := NewFilteredAccountResource(record)
+			stream.Send(sse.Event{
+				ID:   r.PagingToken,
+				Data: r,
+			})
+		}
+
+		if stream.SentCount() >= int(action.FilterQuery.Limit) {
+			stream.Done()
+		}
+		return
+	}
+
 	for _, record := range action.Records[stream.SentCount():] {
 		stream.Send(sse.Event{
 			ID:   record.PagingToken(),
@@ -79,6 +146,61 @@ func (action *AccountIndexAction) SSE(stream sse.Stream) {
 	}
 }
 
+// AccountBalancesAction renders the balances of an account as of the close of
+// a past ledger, given by the at_ledger param or as the latest ledger closed
+// at or before the at_time param, reconstructed from history.  Without either,
+// the balances are those of the latest ledger in history.
+type AccountBalancesAction struct {
+	Action
+	Query    db.HistoricalBalancesQuery
+	Record   db.HistoricalBalancesRecord
+	Resource HistoricalBalancesResource
+}
+
+// LoadQuery sets action.Query from the request params
+func (action *AccountBalancesAction) LoadQuery() {
+	action.Query = db.HistoricalBalancesQuery{
+		SqlQuery: action.App.HistoryQuery(),
+		Address:  action.GetAccountID("account_id"),
+		Ledger:   action.GetInt32("at_ledger"),
+		At:       action.GetTime("at_time"),
+	}
+	if action.Err != nil {
+		return
+	}
+
+	if action.Query.Ledger < 0 || (action.Query.Ledger > 0 && !action.Query.At.IsZero()) {
+		action.Err = &problem.P{
+			Type:   "invalid_balances_ledger",
+			Title:  "Invalid Balances Ledger",
+			Status: http.StatusBadRequest,
+			Detail: "The at_ledger param must be a ledger sequence, and cannot be given along with at_time.",
+		}
+	}
+}
+
+// LoadResource populates action.Record and action.Resource
+func (action *AccountBalancesAction) LoadResource() {
+	action.LoadQuery()
+	if action.Err != nil {
+		return
+	}
+
+	action.Err = db.Get(action.Ctx, action.Query, &action.Record)
+	if action.Err != nil {
+		return
+	}
+
+	action.Resource, action.Err = NewHistoricalBalancesResource(action.Record)
+}
+
+// JSON is a method for actions.JSON
+func (action *AccountBalancesAction) JSON() {
+	action.Do(action.LoadResource, func() {
+		hal.Render(action.W, action.Resource)
+	})
+}
+
 // AccountShowAction renders a account summary found by its address.
 type AccountShowAction struct {
 	Action
@@ -91,7 +213,7 @@ func (action *AccountShowAction) LoadQuery() {
 	action.Query = db.AccountByAddressQuery{
 		Core:    action.App.CoreQuery(),
 		History: action.App.HistoryQuery(),
-		Address: action.GetString("id"),
+		Address: action.GetAccountID("id"),
 	}
 }
 
@@ -102,7 +224,7 @@ func (action *AccountShowAction) LoadRecord() {
 		return
 	}
 
-	action.Err = db.Get(action.Ctx, action.Query, &action.Record)
+	action.Err = db.Get(action.Ctx, action.Mutable(action.Query), &action.Record)
 }
 
 // JSON is a method for actions.JSON
@@ -112,6 +234,17 @@ func (action *AccountShowAction) JSON() {
 		return
 	}
 
+	// an account only changes as ledgers close
+	ls, err := action.App.LedgerState(action.Ctx)
+	if err != nil {
+		action.Err = err
+		return
+	}
+
+	if action.NotModified(action.Record.Address, ls.HorizonSequence, ls.StellarCoreSequence) {
+		return
+	}
+
 	hal.Render(action.W, NewAccountResource(action.Record))
 }
 
@@ -131,3 +264,97 @@ func (action *AccountShowAction) SSE(stream sse.Stream) {
 		stream.Done()
 	}
 }
+
+// MaxAccountBatchSize is the most accounts a single AccountBatchAction may
+// look up.
+const MaxAccountBatchSize = 200
+
+// AccountBatchAction renders the account summaries of many addresses at once,
+// as an AccountBatchResource.  The addresses are read from a json body of the
+// form `{"account_ids": [...]}` or, failing that, from the comma separated
+// account_ids param.  Every account is loaded in the same few queries, however
+// many are asked for.
+type AccountBatchAction struct {
+	Action
+	Addresses []string
+	Records   []db.AccountRecord
+	Resource  AccountBatchResource
+}
+
+// LoadAddresses populates action.Addresses from the request
+func (action *AccountBatchAction) LoadAddresses() {
+	isJSON := strings.HasPrefix(action.R.Header.Get("Content-Type"), "application/json")
+	if isJSON {
+		var req struct {
+			AccountIDs []string `json:"account_ids"`
+		}
+
+		err := json.NewDecoder(action.R.Body).Decode(&req)
+		if err != nil {
+			action.Err = invalidAccountBatch("The request body is not valid json.")
+			return
+		}
+		action.Addresses = req.AccountIDs
+	} else {
+		for _, address := range strings.Split(action.GetString("account_ids"), ",") {
+			address = strings.TrimSpace(address)
+			if address != "" {
+				action.Addresses = append(action.Addresses, address)
+			}
+		}
+	}
+
+	for _, address := range action.Addresses {
+		if err := strkeys.ValidateAccountID(address); err != nil {
+			action.Err = actions.AccountIDProblem("account_ids", err)
+			return
+		}
+	}
+
+	switch {
+	case len(action.Addresses) == 0:
+		action.Err = invalidAccountBatch("No account ids were given.")
+	case len(action.Addresses) > MaxAccountBatchSize:
+		action.Err = invalidAccountBatch(fmt.Sprintf(
+			"%d account ids were given, but at most %d may be looked up at once.",
+			len(action.Addresses), MaxAccountBatchSize,
+		))
+	}
+}
+
+// LoadRecords populates action.Records
+func (action *AccountBatchAction) LoadRecords() {
+	action.Err = db.Select(action.Ctx, db.AccountsByAddressQuery{
+		Core:      action.App.CoreQuery(),
+		History:   action.App.HistoryQuery(),
+		Addresses: action.Addresses,
+	}, &action.Records)
+}
+
+// LoadResource populates action.Resource
+func (action *AccountBatchAction) LoadResource() {
+	action.Resource = NewAccountBatchResource(action.Ctx, action.Addresses, action.Records)
+}
+
+// JSON is a method for actions.JSON
+func (action *AccountBatchAction) JSON() {
+	action.Do(action.LoadAddresses, action.LoadRecords, action.LoadResource)
+	if action.Err != nil {
+		return
+	}
+
+	hal.Render(action.W, action.Resource)
+}
+
+func invalidAccountBatch(detail string) *problem.P {
+	return &problem.P{
+		Type:   "invalid_account_batch",
+		Title:  "Invalid Account Batch",
+		Status: http.StatusBadRequest,
+		Detail: fmt.Sprintf(
+			"%s  Give up to %d account ids, either as a json body of the form "+
+				"{\"account_ids\": [...]} or as the comma separated account_ids param.",
+			detail, MaxAccountBatchSize,
+		),
+	}
+}
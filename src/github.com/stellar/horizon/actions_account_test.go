@@ -2,6 +2,10 @@ package horizon
 
 import (
 	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
 	"testing"
 
 	. "github.com/smartystreets/goconvey/convey"
@@ -27,6 +31,88 @@ func TestAccountActions(t *testing.T) {
 			So(result.Sequence, ShouldEqual, 3)
 		})
 
+		Convey("POST /accounts/batch", func() {
+			form := url.Values{"account_ids": []string{
+				"GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H,GDNOTREAL,GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU",
+			}}
+			w := rh.Post("/accounts/batch", form, test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+
+			var result AccountBatchResource
+			err := json.Unmarshal(w.Body.Bytes(), &result)
+			So(err, ShouldBeNil)
+			So(len(result.Records), ShouldEqual, 3)
+
+			So(result.Records[0].ID, ShouldEqual, "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H")
+			So(result.Records[0].Account.Sequence, ShouldEqual, 3)
+			So(result.Records[0].Error, ShouldBeNil)
+
+			So(result.Records[1].ID, ShouldEqual, "GDNOTREAL")
+			So(result.Records[1].Account, ShouldBeNil)
+			So(result.Records[1].Error.Status, ShouldEqual, 404)
+
+			So(result.Records[2].Account.ID, ShouldEqual, "GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU")
+		})
+
+		Convey("POST /accounts/batch with a json body", func() {
+			w := rh.Post("/accounts/batch", nil, func(r *http.Request) {
+				r.Header.Set("Content-Type", "application/json")
+				r.Body = ioutil.NopCloser(strings.NewReader(`{"account_ids": ["GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H"]}`))
+			})
+			So(w.Code, ShouldEqual, 200)
+
+			var result AccountBatchResource
+			err := json.Unmarshal(w.Body.Bytes(), &result)
+			So(err, ShouldBeNil)
+			So(len(result.Records), ShouldEqual, 1)
+			So(result.Records[0].Account, ShouldNotBeNil)
+
+			w = rh.Post("/accounts/batch", nil, func(r *http.Request) {
+				r.Header.Set("Content-Type", "application/json")
+				r.Body = ioutil.NopCloser(strings.NewReader(`{"account_ids": `))
+			})
+			So(w.Code, ShouldEqual, 400)
+		})
+
+		Convey("POST /accounts/batch with too many or no account ids", func() {
+			w := rh.Post("/accounts/batch", url.Values{}, test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 400)
+
+			ids := make([]string, MaxAccountBatchSize+1)
+			for i := range ids {
+				ids[i] = "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H"
+			}
+			w = rh.Post("/accounts/batch", url.Values{"account_ids": []string{strings.Join(ids, ",")}}, test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 400)
+		})
+
+		Convey("GET /accounts/GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H?fields=balances,sequence", func() {
+			w := rh.Get("/accounts/GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H?fields=balances,sequence", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+
+			var result map[string]interface{}
+			err := json.Unmarshal(w.Body.Bytes(), &result)
+			So(err, ShouldBeNil)
+			So(result["_links"], ShouldNotBeNil)
+			So(result["balances"], ShouldNotBeNil)
+			So(result["sequence"], ShouldEqual, 3)
+			So(result["signers"], ShouldBeNil)
+		})
+
+		Convey("GET /accounts/:id with If-None-Match", func() {
+			w := rh.Get("/accounts/GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H", test.RequestHelperNoop)
+			etag := w.Header().Get("ETag")
+			So(etag, ShouldNotBeBlank)
+
+			w = rh.Get("/accounts/GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H", func(r *http.Request) {
+				r.Header.Set("If-None-Match", `"stale", `+etag)
+			})
+			So(w.Code, ShouldEqual, 304)
+
+			w = rh.Get("/accounts/100", test.RequestHelperNoop)
+			So(w.Header().Get("ETag"), ShouldBeBlank)
+		})
+
 		Convey("GET /accounts/100", func() {
 			w := rh.Get("/accounts/100", test.RequestHelperNoop)
 			So(w.Code, ShouldEqual, 404)
@@ -41,5 +127,62 @@ func TestAccountActions(t *testing.T) {
 			So(w.Code, ShouldEqual, 200)
 			So(w.Body, ShouldBePageOf, 1)
 		})
+
+		Convey("GET /accounts?signer=", func() {
+			w := rh.Get("/accounts?signer=GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Body, ShouldBePageOf, 1)
+
+			w = rh.Get("/accounts?asset=native", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 400)
+
+			w = rh.Get("/accounts?signer=SBUG64TJPJXW4IDUMVZXIIDTMVSWILBANZSXMZLSEBTHK3TEMVSCCMHU", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 400)
+			So(w.Body.String(), ShouldContainSubstring, "secret_seed_given")
+		})
+
+		Convey("GET /accounts?home_domain=", func() {
+			test.LoadScenario("set_options")
+			w := rh.Get("/accounts?home_domain=nullstyle.com", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Body, ShouldBePageOf, 1)
+		})
+
+		Convey("GET /accounts?asset=", func() {
+			test.LoadScenario("non_native_payment")
+			w := rh.Get("/accounts?asset=USD:GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4&limit=1", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Body, ShouldBePageOf, 1)
+
+			var page map[string]interface{}
+			err := json.Unmarshal(w.Body.Bytes(), &page)
+			So(err, ShouldBeNil)
+			links := page["_links"].(map[string]interface{})
+			next := links["next"].(map[string]interface{})["href"].(string)
+			So(next, ShouldContainSubstring, "asset=USD:GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4")
+
+			w = rh.Get(next, test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Body, ShouldBePageOf, 1)
+		})
+
+		Convey("GET /accounts/:account_id/balances", func() {
+			w := rh.Get("/accounts/GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON/balances?at_ledger=2", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+
+			var result HistoricalBalancesResource
+			err := json.Unmarshal(w.Body.Bytes(), &result)
+			So(err, ShouldBeNil)
+			So(result.Ledger, ShouldEqual, 2)
+			So(len(result.Balances), ShouldEqual, 1)
+			So(result.Balances[0].Type, ShouldEqual, "native")
+			So(result.Balances[0].Balance, ShouldEqual, "100.0000000")
+
+			w = rh.Get("/accounts/GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON/balances?at_ledger=1", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 404)
+
+			w = rh.Get("/accounts/GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON/balances?at_ledger=2&at_time=2015-10-07T23:07:29Z", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 400)
+		})
 	})
 }
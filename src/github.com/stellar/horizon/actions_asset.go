@@ -0,0 +1,136 @@
+package horizon
+
+import (
+	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/render/hal"
+)
+
+// This file contains the actions:
+//
+// AssetsIndexAction: pages of asset statistics
+// AssetHoldersIndexAction: pages of the accounts holding an asset
+
+// AssetsIndexAction renders a page of asset resources, optionally restricted
+// to the assets with a given code or from a given issuer.  The statistics are
+// those of the latest ledger known to stellar-core, shared by the requests
+// served until the next pump.  The assets are annotated
+// with the anchors the app has fetched of them, if it fetches anchors.
+type AssetsIndexAction struct {
+	Action
+	Query   db.CoreAssetStatPageQuery
+	Records []db.CoreAssetStatRecord
+	Page    hal.Page
+}
+
+// LoadQuery sets action.Query from the request params
+func (action *AssetsIndexAction) LoadQuery() {
+	action.Query = db.CoreAssetStatPageQuery{
+		PageQuery: action.GetPageQuery(),
+		Code:      action.GetString("asset_code"),
+		Issuer:    action.GetString("asset_issuer"),
+	}
+	if action.Err != nil {
+		return
+	}
+
+	action.Query.Stats, action.Err = action.App.AssetStats(action.Ctx)
+}
+
+// LoadRecords populates action.Records
+func (action *AssetsIndexAction) LoadRecords() {
+	action.LoadQuery()
+	if action.Err != nil {
+		return
+	}
+
+	action.Err = action.Select(action.Query, &action.Records)
+}
+
+// LoadPage populates action.Page
+func (action *AssetsIndexAction) LoadPage() {
+	action.LoadRecords()
+	if action.Err != nil {
+		return
+	}
+
+	action.Page, action.Err = NewAssetStatResourcePage(action.Records, action.Query)
+	if action.Err != nil || action.App.anchors == nil {
+		return
+	}
+
+	for i, record := range action.Records {
+		a, ok := action.App.anchors.Lookup(record.Assetcode, record.Issuer)
+		if !ok {
+			continue
+		}
+
+		r := action.Page.Records[i].(AssetStatResource)
+		r.Anchor = NewAnchorResource(a)
+		action.Page.Records[i] = r
+	}
+}
+
+// JSON is a method for actions.JSON
+func (action *AssetsIndexAction) JSON() {
+	action.LoadPage()
+	if action.Err != nil {
+		return
+	}
+
+	hal.Render(action.W, action.Page)
+}
+
+// AssetHoldersIndexAction renders a page of the accounts holding the asset
+// given as CODE:ISSUER, ordered by balance.  The holders are those of the
+// trustlines that ingestion keeps current, as of the latest ledger ingested.
+type AssetHoldersIndexAction struct {
+	Action
+	Query   db.AssetHoldersPageQuery
+	Records []db.TrustlineRecord
+	Page    hal.Page
+}
+
+// LoadQuery sets action.Query from the request params
+func (action *AssetHoldersIndexAction) LoadQuery() {
+	asset := action.GetAsset("asset", false)
+	if action.Err != nil {
+		return
+	}
+
+	action.Query = db.AssetHoldersPageQuery{
+		SqlQuery:  action.App.HistoryQuery(),
+		PageQuery: action.GetPageQuery(),
+		Code:      asset.Code,
+		Issuer:    asset.Issuer,
+	}
+}
+
+// LoadRecords populates action.Records
+func (action *AssetHoldersIndexAction) LoadRecords() {
+	action.LoadQuery()
+	if action.Err != nil {
+		return
+	}
+
+	action.Err = action.Select(action.Query, &action.Records)
+}
+
+// LoadPage populates action.Page
+func (action *AssetHoldersIndexAction) LoadPage() {
+	action.LoadRecords()
+	if action.Err != nil {
+		return
+	}
+
+	action.Page, action.Err = NewAssetHolderResourcePage(action.Records, action.Query)
+}
+
+// JSON is a method for actions.JSON
+func (action *AssetHoldersIndexAction) JSON() {
+	action.LoadPage()
+	if action.Err != nil {
+		return
+	}
+
+	hal.Render(action.W, action.Page)
+}
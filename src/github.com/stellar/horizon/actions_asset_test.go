@@ -0,0 +1,131 @@
+package horizon
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/anchors"
+	"github.com/stellar/horizon/test"
+	"golang.org/x/net/context"
+)
+
+func TestAssetActions(t *testing.T) {
+	test.LoadScenario("trades")
+	app := NewTestApp()
+	defer app.Close()
+	rh := NewRequestHelper(app)
+
+	Convey("Asset Actions:", t, func() {
+
+		Convey("GET /assets", func() {
+			w := rh.Get("/assets", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Body, ShouldBePageOf, 2)
+		})
+
+		Convey("GET /assets?asset_code=USD", func() {
+			w := rh.Get("/assets?asset_code=USD", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+
+			var result struct {
+				Embedded struct {
+					Records []AssetStatResource
+				} `json:"_embedded"`
+			}
+			err := json.Unmarshal(w.Body.Bytes(), &result)
+			So(err, ShouldBeNil)
+			So(len(result.Embedded.Records), ShouldEqual, 1)
+
+			asset := result.Embedded.Records[0]
+			So(asset.Type, ShouldEqual, "credit_alphanum4")
+			So(asset.Issuer, ShouldEqual, "GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4")
+			So(asset.Amount, ShouldEqual, "500.0000000")
+			So(asset.NumAccounts, ShouldEqual, 2)
+		})
+
+		Convey("GET /assets annotated with anchors", func() {
+			const issuer = "GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4"
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintf(w, "[DOCUMENTATION]\nORG_NAME=\"Example\"\n\n[[CURRENCIES]]\ncode=\"USD\"\nissuer=\"%s\"\n", issuer)
+			}))
+			defer server.Close()
+
+			app.anchors = &anchors.Directory{
+				Insecure: true,
+				HomeDomain: func(ctx context.Context, address string) (string, error) {
+					return strings.TrimPrefix(server.URL, "http://"), nil
+				},
+			}
+			Reset(func() { app.anchors = nil })
+
+			_, err := app.anchors.Fetch(app.ctx, "USD", issuer)
+			So(err, ShouldBeNil)
+
+			w := rh.Get("/assets?asset_code=USD", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+
+			var result struct {
+				Embedded struct {
+					Records []AssetStatResource
+				} `json:"_embedded"`
+			}
+			err = json.Unmarshal(w.Body.Bytes(), &result)
+			So(err, ShouldBeNil)
+			So(len(result.Embedded.Records), ShouldEqual, 1)
+			So(result.Embedded.Records[0].Anchor, ShouldNotBeNil)
+			So(result.Embedded.Records[0].Anchor.Name, ShouldEqual, "Example")
+			So(result.Embedded.Records[0].Anchor.Verified, ShouldBeTrue)
+		})
+
+		Convey("GET /assets?cursor=bad", func() {
+			w := rh.Get("/assets?cursor=bad", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 400)
+		})
+
+		Convey("GET /assets/:asset/holders", func() {
+			const usd = "USD:GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4"
+			Reset(func() { app.historyDb.MustExec("DELETE FROM current_trustlines") })
+
+			for i, account := range []string{
+				"GA5WBPYA5Y4WAEHXWR2UKO2UO4BUGHUQ74EUPKON2QHV4WRHOIRNKKH2",
+				"GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU",
+			} {
+				app.historyDb.MustExec(`
+					INSERT INTO current_trustlines
+					(accountid, assettype, issuer, assetcode, tlimit, balance, flags, lastmodified)
+					VALUES ($1, 1, 'GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4', 'USD', 10000000000, $2, 1, 6)`,
+					account, (i+1)*100000000)
+			}
+
+			w := rh.Get("/assets/"+usd+"/holders?order=desc", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+
+			var result struct {
+				Embedded struct {
+					Records []AssetHolderResource
+				} `json:"_embedded"`
+			}
+			err := json.Unmarshal(w.Body.Bytes(), &result)
+			So(err, ShouldBeNil)
+			So(len(result.Embedded.Records), ShouldEqual, 2)
+
+			holder := result.Embedded.Records[0]
+			So(holder.Account, ShouldEqual, "GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU")
+			So(holder.Balance, ShouldEqual, "20.0000000")
+			So(holder.Limit, ShouldEqual, "1000.0000000")
+			So(holder.Authorized, ShouldBeTrue)
+
+			w = rh.Get("/assets/"+usd+"/holders?order=desc&cursor="+holder.PagingToken, test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Body, ShouldBePageOf, 1)
+
+			w = rh.Get("/assets/native/holders", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 400)
+		})
+	})
+}
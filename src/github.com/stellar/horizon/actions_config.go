@@ -0,0 +1,60 @@
+package horizon
+
+import (
+	"net/http"
+
+	"github.com/stellar/horizon/render/hal"
+	"github.com/stellar/horizon/render/problem"
+)
+
+// This file contains the actions:
+//
+// ConfigAction: the config horizon runs with, its secrets redacted
+// ConfigReloadAction: reloads the config horizon runs with
+
+// ConfigAction renders the config of the app, as a ConfigResource.  It is
+// served by the admin server only.
+type ConfigAction struct {
+	Action
+	Resource ConfigResource
+}
+
+// JSON is a method for actions.JSON
+func (action *ConfigAction) JSON() {
+	action.Resource = NewConfigResource(action.App.currentConfig())
+	hal.Render(action.W, action.Resource)
+}
+
+// ConfigReloadAction reloads the config of the app from its source, as
+// sending horizon SIGHUP does, then renders it as ConfigAction does.  It is
+// not found when horizon was started without a config file.  It is served by
+// the admin server only.
+type ConfigReloadAction struct {
+	Action
+	Resource ConfigResource
+}
+
+// JSON is a method for actions.JSON
+func (action *ConfigReloadAction) JSON() {
+	action.Do(
+		func() {
+			err := action.App.ReloadConfig()
+			switch err {
+			case nil:
+			case ErrNoConfigSource:
+				action.Err = &problem.NotFound
+			default:
+				action.Err = &problem.P{
+					Type:   "config_reload_failed",
+					Title:  "Config Reload Failed",
+					Status: http.StatusUnprocessableEntity,
+					Detail: "The config was left as it was, as it could not be reloaded: " + err.Error(),
+				}
+			}
+		},
+		func() {
+			action.Resource = NewConfigResource(action.App.currentConfig())
+			hal.Render(action.W, action.Resource)
+		},
+	)
+}
@@ -3,6 +3,7 @@ package horizon
 import (
 	"github.com/stellar/horizon/db"
 	"github.com/stellar/horizon/render/hal"
+	"github.com/stellar/horizon/render/ndjson"
 	"github.com/stellar/horizon/render/sse"
 )
 
@@ -22,7 +23,7 @@ type EffectIndexAction struct {
 
 // JSON is a method for actions.JSON
 func (action *EffectIndexAction) JSON() {
-	action.Do(action.LoadQuery, action.LoadRecords, action.LoadPage)
+	action.Do(action.LoadQuery, action.LoadScope, action.LoadRecords, action.LoadPage)
 
 	action.Do(func() {
 		hal.Render(action.W, action.Page)
@@ -58,6 +59,32 @@ func (action *EffectIndexAction) SSE(stream sse.Stream) {
 	}
 }
 
+// NDJSON is a method for actions.NDJSON
+func (action *EffectIndexAction) NDJSON(export *ndjson.Stream) {
+	action.LoadQuery()
+	action.Query.Limit = db.MaxPageSize
+
+	for action.Err == nil && export.Continue() {
+		var records []db.EffectRecord
+		action.Err = action.Select(action.Query, &records)
+		if action.Err != nil || len(records) == 0 {
+			return
+		}
+
+		for _, record := range records {
+			r, err := NewEffectResource(record)
+			if err != nil {
+				action.Err = err
+				return
+			}
+
+			export.Write(r)
+		}
+
+		action.Query.Cursor = records[len(records)-1].PagingToken()
+	}
+}
+
 // LoadQuery sets action.Query from the request params
 func (action *EffectIndexAction) LoadQuery() {
 	action.Query = db.EffectPageQuery{
@@ -65,13 +92,13 @@ func (action *EffectIndexAction) LoadQuery() {
 		PageQuery: action.GetPageQuery(),
 	}
 
-	if address := action.GetString("account_id"); address != "" {
+	if address := action.GetAccountID("account_id"); address != "" {
 		action.Query.Filter = &db.EffectAccountFilter{action.Query.SqlQuery, address}
 		return
 	}
 
-	if seq := action.GetInt32("ledger_id"); seq != 0 {
-		action.Query.Filter = &db.EffectLedgerFilter{seq}
+	if action.GetString("ledger_id") != "" {
+		action.Query.Filter = &db.EffectLedgerFilter{action.GetInt32("ledger_id")}
 		return
 	}
 
@@ -80,15 +107,36 @@ func (action *EffectIndexAction) LoadQuery() {
 		return
 	}
 
-	if op := action.GetInt64("op_id"); op != 0 {
-		action.Query.Filter = &db.EffectOperationFilter{op}
+	if action.GetString("op_id") != "" {
+		action.Query.Filter = &db.EffectOperationFilter{action.GetInt64("op_id")}
 		return
 	}
 }
 
+// LoadScope ensures the ledger or operation whose effects are requested
+// exists, so that a page of its effects is not confused with a page of the
+// effects of something unknown.  Streams skip this check, to allow waiting on
+// a ledger yet to close.
+func (action *EffectIndexAction) LoadScope() {
+	switch filter := action.Query.Filter.(type) {
+	case *db.EffectLedgerFilter:
+		var ledger db.LedgerRecord
+		action.Err = db.Get(action.Ctx, db.LedgerBySequenceQuery{
+			SqlQuery: action.Query.SqlQuery,
+			Sequence: filter.LedgerSequence,
+		}, &ledger)
+	case *db.EffectOperationFilter:
+		var op db.OperationRecord
+		action.Err = db.Get(action.Ctx, db.OperationByIdQuery{
+			SqlQuery: action.Query.SqlQuery,
+			Id:       filter.OperationID,
+		}, &op)
+	}
+}
+
 // LoadRecords populates action.Records
 func (action *EffectIndexAction) LoadRecords() {
-	action.Err = db.Select(action.Ctx, action.Query, &action.Records)
+	action.Err = action.Select(action.Query, &action.Records)
 }
 
 // LoadPage populates action.Page
@@ -41,6 +41,13 @@ func TestEffectActions(t *testing.T) {
 			w = rh.Get("/ledgers/3/effects", test.RequestHelperNoop)
 			So(w.Code, ShouldEqual, 200)
 			So(w.Body, ShouldBePageOf, 2)
+
+			// unknown ledgers are not found, rather than empty
+			w = rh.Get("/ledgers/100/effects", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 404)
+
+			w = rh.Get("/ledgers/0/effects", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 404)
 		})
 
 		Convey("GET /accounts/:account_id/effects", func() {
@@ -67,6 +74,9 @@ func TestEffectActions(t *testing.T) {
 			w := rh.Get("/operations/8589938689/effects", test.RequestHelperNoop)
 			So(w.Code, ShouldEqual, 200)
 			So(w.Body, ShouldBePageOf, 3)
+
+			w = rh.Get("/operations/1/effects", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 404)
 		})
 	})
 }
@@ -0,0 +1,34 @@
+package horizon
+
+import (
+	"github.com/stellar/horizon/render/hal"
+)
+
+// This file contains the actions:
+//
+// FeeStatsAction: fee percentiles and capacity usage of the latest ledgers
+
+// FeeStatsAction renders the fees accepted into the latest ledgers, over the
+// number of ledgers set by Config.FeeStatsLedgers.
+type FeeStatsAction struct {
+	Action
+	Resource FeeStatsResource
+}
+
+// LoadResource populates action.Resource
+func (action *FeeStatsAction) LoadResource() {
+	stats, err := action.App.FeeStats(action.Ctx)
+	if err != nil {
+		action.Err = err
+		return
+	}
+
+	action.Resource = NewFeeStatsResource(stats)
+}
+
+// JSON is a method for actions.JSON
+func (action *FeeStatsAction) JSON() {
+	action.Do(action.LoadResource, func() {
+		hal.Render(action.W, action.Resource)
+	})
+}
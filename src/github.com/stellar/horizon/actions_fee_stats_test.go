@@ -0,0 +1,31 @@
+package horizon
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/test"
+)
+
+func TestFeeStatsActions(t *testing.T) {
+	test.LoadScenario("base")
+	app := NewTestApp()
+	defer app.Close()
+	rh := NewRequestHelper(app)
+
+	Convey("Fee Stats Actions:", t, func() {
+
+		Convey("GET /fee_stats", func() {
+			w := rh.Get("/fee_stats", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+
+			var result FeeStatsResource
+			err := json.Unmarshal(w.Body.Bytes(), &result)
+			So(err, ShouldBeNil)
+			So(result.LastLedger, ShouldEqual, 3)
+			So(result.Ledgers, ShouldEqual, 3)
+			So(result.P99AcceptedFee, ShouldBeGreaterThanOrEqualTo, result.P10AcceptedFee)
+		})
+	})
+}
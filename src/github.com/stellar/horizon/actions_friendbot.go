@@ -0,0 +1,82 @@
+package horizon
+
+import (
+	"net/http"
+
+	"github.com/stellar/horizon/actions"
+	"github.com/stellar/horizon/render/hal"
+	"github.com/stellar/horizon/render/problem"
+	"github.com/stellar/horizon/strkeys"
+	"golang.org/x/net/context"
+)
+
+// friendbotAccountRateLimitExceeded is the problem of requests to fund an
+// account that friendbot was asked to fund as often as it may be
+var friendbotAccountRateLimitExceeded = problem.P{
+	Type:   "account_rate_limit_exceeded",
+	Title:  "Account rate limit exceeded",
+	Status: http.StatusTooManyRequests,
+	Detail: "Friendbot was asked to fund this account as many times as it may " +
+		"be in a period.  The allowed limit and requests left per time period " +
+		"are communicated to clients via the http response headers " +
+		"'X-RateLimit-*' headers.",
+}
+
+// FriendbotAction funds the account of the addr param, creating it with the
+// lumens of Config.FriendbotAmount.  When the app has a captcha verifier, the
+// token of the captcha solved by the client must be given as the captcha
+// param.  The transaction funding the account is rendered as by
+// TransactionCreateAction.
+type FriendbotAction struct {
+	Action
+	Address string
+}
+
+// JSON is a method for actions.JSON
+func (action *FriendbotAction) JSON() {
+	action.Do(action.loadParams, action.verifyCaptcha, action.fund)
+}
+
+func (action *FriendbotAction) loadParams() {
+	action.Address = action.GetString("addr")
+
+	// friendbot refuses seeds as any other invalid address, but the client
+	// sending one must be warned its secret is exposed
+	if strkeys.ValidateAccountID(action.Address) == strkeys.ErrSeed {
+		action.Err = actions.AccountIDProblem("addr", strkeys.ErrSeed)
+	}
+}
+
+// verifyCaptcha checks the captcha solved by the client, if the app asks for
+// one
+func (action *FriendbotAction) verifyCaptcha() {
+	if action.App.captcha == nil {
+		return
+	}
+
+	action.Err = action.App.captcha.Verify(action.Ctx, action.GetString("captcha"), remoteAddrIP(action.R))
+}
+
+func (action *FriendbotAction) fund() {
+	l, err := action.App.friendbot.Fund(action.Ctx, action.Address)
+	if err != nil {
+		action.Err = err
+		return
+	}
+
+	select {
+	case result := <-l:
+		resource := &ResultResource{result}
+
+		if resource.IsSuccess() {
+			hal.Render(action.W, resource.Success())
+		} else {
+			problem.Render(action.Ctx, action.W, resource.Error())
+		}
+	case <-action.Ctx.Done():
+		// the client is gone, unless the request timed out
+		if action.Ctx.Err() == context.DeadlineExceeded {
+			problem.Render(action.Ctx, action.W, problem.Timeout)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package horizon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/PuerkitoBio/throttled"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/test"
+)
+
+func TestFriendbotActions(t *testing.T) {
+	// the root account of the base scenario, which exists already
+	root := "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H"
+
+	Convey("friendbot is not implemented without a secret", t, func() {
+		app := NewTestApp()
+		defer app.Close()
+		So(app.friendbot, ShouldBeNil)
+
+		rh := NewRequestHelper(app)
+		w := rh.Post("/friendbot", url.Values{"addr": []string{root}}, test.RequestHelperNoop)
+		So(w.Body.String(), ShouldContainSubstring, "not_implemented")
+	})
+
+	Convey("POST /friendbot", t, func() {
+		test.LoadScenario("base")
+
+		captcha := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.FormValue("response") == "solved" {
+				w.Write([]byte(`{"success": true}`))
+				return
+			}
+			w.Write([]byte(`{"success": false}`))
+		}))
+		defer captcha.Close()
+
+		c := NewTestConfig()
+		c.FriendbotSecret = "SDHOAMBNLGCE2MV5ZKIVZAQD3VCLGP53P3OBSBI6UN5L5XZI5TKHFQL4"
+		c.FriendbotAmount = "10000"
+		c.FriendbotAccountLimit = throttled.PerHour(1)
+		c.FriendbotCaptchaURL = captcha.URL
+		c.FriendbotCaptchaSecret = "shh"
+		app, err := NewApp(c)
+		So(err, ShouldBeNil)
+		defer app.Close()
+		So(app.friendbot.Address(), ShouldEqual, root)
+
+		rh := NewRequestHelper(app)
+
+		Convey("asks for a solved captcha", func() {
+			w := rh.Post("/friendbot", url.Values{
+				"addr":    []string{"GA5WBPYA5Y4WAEHXWR2UKO2UO4BUGHUQ74EUPKON2QHV4WRHOIRNKKH2"},
+				"captcha": []string{"guessed"},
+			}, test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 403)
+			So(w.Body.String(), ShouldContainSubstring, "captcha_failed")
+		})
+
+		Convey("refuses invalid addresses", func() {
+			w := rh.Post("/friendbot", url.Values{
+				"addr":    []string{"not_an_address"},
+				"captcha": []string{"solved"},
+			}, test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 400)
+			So(w.Body.String(), ShouldContainSubstring, "invalid_address")
+		})
+
+		Convey("refuses accounts that exist, and limits requests by account", func() {
+			params := url.Values{"addr": []string{root}, "captcha": []string{"solved"}}
+
+			w := rh.Post("/friendbot", params, test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 400)
+			So(w.Body.String(), ShouldContainSubstring, "account_exists")
+
+			w = rh.Post("/friendbot", params, test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 429)
+			So(w.Body.String(), ShouldContainSubstring, "account_rate_limit_exceeded")
+		})
+	})
+}
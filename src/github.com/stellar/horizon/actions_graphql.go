@@ -0,0 +1,333 @@
+package horizon
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/graphql"
+	"github.com/stellar/horizon/render/problem"
+	"github.com/stellar/horizon/render/sse"
+)
+
+// This file contains the actions:
+//
+// GraphQLAction: graphql queries and subscriptions
+
+// GraphQLAction answers graphql queries, and streams graphql subscriptions to
+// clients that request an event stream.  The request is read from the `query`
+// and `variables` params or, for POST requests, from a json body of the same
+// form.
+//
+// Queries may select the following root fields:
+//
+//	account(id)
+//	transaction(id)
+//	ledgers(cursor, order, limit)
+//	transactions, operations, payments, effects(account_id, cursor, order, limit)
+//
+// and subscriptions any one of the list fields, less the order and limit
+// arguments.  The fields selected below the root fields are those of the json
+// form of the corresponding resources.
+type GraphQLAction struct {
+	Action
+	Document *graphql.Document
+	Data     graphql.Object
+
+	// subscription state, maintained between pumps
+	Field  *graphql.Field
+	Cursor string
+	Load   func(db.PageQuery) ([]sse.Event, error)
+}
+
+// JSON is a method for actions.JSON
+func (action *GraphQLAction) JSON() {
+	action.Do(action.LoadDocument, action.LoadData)
+
+	// errors are delivered using graphql's response format, not as problems
+	graphql.Render(action.Ctx, action.W, action.Data, action.Err)
+	action.Err = nil
+}
+
+// SSE is a method for actions.SSE
+func (action *GraphQLAction) SSE(stream sse.Stream) {
+	if action.Load == nil {
+		action.Do(action.LoadDocument, action.LoadSubscription)
+	}
+
+	if action.Err != nil {
+		stream.Err(action.Err)
+		return
+	}
+
+	query, err := db.NewPageQuery(action.Cursor, db.OrderAscending, 0)
+	if err != nil {
+		stream.Err(err)
+		return
+	}
+
+	events, err := action.Load(query)
+	if err != nil {
+		stream.Err(err)
+		return
+	}
+
+	for _, e := range events {
+		data, err := graphql.Select(e.Data, action.Field.Selections)
+		if err != nil {
+			stream.Err(invalidGraphQL(err.Error()))
+			return
+		}
+
+		action.Cursor = e.ID
+		stream.Send(sse.Event{
+			ID:    e.ID,
+			Event: action.Field.Key(),
+			Data: graphql.Response{
+				Data: graphql.Object{{Key: action.Field.Key(), Value: data}},
+			},
+		})
+	}
+}
+
+// LoadDocument parses the request's graphql document into action.Document.
+func (action *GraphQLAction) LoadDocument() {
+	var req struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables"`
+	}
+
+	isJSON := strings.HasPrefix(action.R.Header.Get("Content-Type"), "application/json")
+	if action.R.Method == "POST" && isJSON {
+		err := json.NewDecoder(action.R.Body).Decode(&req)
+		if err != nil {
+			action.Err = invalidGraphQL("The request body is not valid json.")
+			return
+		}
+	} else {
+		req.Query = action.GetString("query")
+
+		if vars := action.GetString("variables"); vars != "" {
+			err := json.Unmarshal([]byte(vars), &req.Variables)
+			if err != nil {
+				action.Err = invalidGraphQL("The variables param is not a valid json object.")
+				return
+			}
+		}
+	}
+
+	if strings.TrimSpace(req.Query) == "" {
+		action.Err = invalidGraphQL("No query was provided.")
+		return
+	}
+
+	doc, err := graphql.Parse(req.Query, req.Variables)
+	if err != nil {
+		action.Err = invalidGraphQL(err.Error())
+		return
+	}
+
+	action.Document = doc
+}
+
+// LoadData resolves every root field of a query into action.Data.
+func (action *GraphQLAction) LoadData() {
+	if action.Document.Operation != graphql.OperationQuery {
+		action.Err = invalidGraphQL("Subscriptions must be requested as an event stream.")
+		return
+	}
+
+	for _, field := range action.Document.Fields {
+		value, err := action.resolve(field)
+		if err != nil {
+			action.Err = err
+			return
+		}
+
+		value, err = action.EncodeCursors(value)
+		if err != nil {
+			action.Err = err
+			return
+		}
+
+		data, err := graphql.Select(value, field.Selections)
+		if err != nil {
+			action.Err = invalidGraphQL(err.Error())
+			return
+		}
+
+		action.Data = append(action.Data, graphql.Member{Key: field.Key(), Value: data})
+	}
+}
+
+// LoadSubscription prepares the loader for a subscription's root field.  A
+// reconnecting client's last event id takes precedence over the field's
+// cursor argument.
+func (action *GraphQLAction) LoadSubscription() {
+	doc := action.Document
+	if doc.Operation != graphql.OperationSubscription || len(doc.Fields) != 1 {
+		action.Err = invalidGraphQL("Streams require a subscription with a single root field.")
+		return
+	}
+
+	field := doc.Fields[0]
+	address, err := stringArg(field, "account_id")
+	if err != nil {
+		action.Err = err
+		return
+	}
+
+	action.Load = action.collectionLoader(field.Name, address)
+	if action.Load == nil {
+		action.Err = invalidGraphQL(fmt.Sprintf("Cannot subscribe to %q.", field.Name))
+		return
+	}
+
+	if action.Cursor, action.Err = stringArg(field, "cursor"); action.Err != nil {
+		return
+	}
+
+	if lei := sse.LastEventID(action.R); lei != "" {
+		action.Cursor = lei
+	}
+	action.Cursor = action.DecodeCursor(action.Cursor)
+
+	action.Field = field
+}
+
+// resolve returns the value of a query's root field.
+func (action *GraphQLAction) resolve(field *graphql.Field) (interface{}, error) {
+	switch field.Name {
+	case "account":
+		id, err := requiredStringArg(field, "id")
+		if err != nil {
+			return nil, err
+		}
+
+		var record db.AccountRecord
+		err = db.Get(action.Ctx, db.AccountByAddressQuery{
+			Core:    action.App.CoreQuery(),
+			History: action.App.HistoryQuery(),
+			Address: id,
+		}, &record)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewAccountResource(record), nil
+	case "transaction":
+		id, err := requiredStringArg(field, "id")
+		if err != nil {
+			return nil, err
+		}
+
+		var record db.TransactionRecord
+		err = db.Get(action.Ctx, db.TransactionByHashQuery{
+			SqlQuery: action.App.HistoryQuery(),
+			Hash:     id,
+		}, &record)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewTransactionResource(record), nil
+	}
+
+	address, err := stringArg(field, "account_id")
+	if err != nil {
+		return nil, err
+	}
+
+	load := action.collectionLoader(field.Name, address)
+	if load == nil {
+		return nil, invalidGraphQL(fmt.Sprintf("Unknown field %q.", field.Name))
+	}
+
+	cursor, err := stringArg(field, "cursor")
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err = action.Cursors.Decode(action.Path(), cursor)
+	if err != nil {
+		return nil, invalidGraphQL(fmt.Sprintf("Invalid cursor of %q.", field.Name))
+	}
+
+	order, err := stringArg(field, "order")
+	if err != nil {
+		return nil, err
+	}
+
+	limit, err := intArg(field, "limit")
+	if err != nil {
+		return nil, err
+	}
+
+	query, err := db.NewPageQuery(cursor, order, limit)
+	if err != nil {
+		return nil, invalidGraphQL(fmt.Sprintf("Invalid paging arguments of %q: %s.", field.Name, err))
+	}
+
+	events, err := load(query)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]interface{}, len(events))
+	for i, e := range events {
+		records[i] = e.Data
+	}
+	return records, nil
+}
+
+// stringArg returns the named string argument of field, or "" if absent.
+func stringArg(field *graphql.Field, name string) (string, error) {
+	switch v := field.Args[name].(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	default:
+		return "", invalidGraphQL(fmt.Sprintf("Argument %q of %q must be a string.", name, field.Name))
+	}
+}
+
+// requiredStringArg is like stringArg, but the argument must be present.
+func requiredStringArg(field *graphql.Field, name string) (string, error) {
+	v, err := stringArg(field, name)
+	if err == nil && v == "" {
+		err = invalidGraphQL(fmt.Sprintf("Argument %q of %q is required.", name, field.Name))
+	}
+
+	return v, err
+}
+
+// intArg returns the named int argument of field, or 0 if absent.
+func intArg(field *graphql.Field, name string) (int32, error) {
+	switch v := field.Args[name].(type) {
+	case nil:
+		return 0, nil
+	case int64:
+		return int32(v), nil
+	case float64:
+		// json variables are decoded as floats
+		if v == float64(int32(v)) {
+			return int32(v), nil
+		}
+	}
+
+	return 0, invalidGraphQL(fmt.Sprintf("Argument %q of %q must be an integer.", name, field.Name))
+}
+
+// invalidGraphQL returns the problem for a request that is not a valid, or not
+// a supported, graphql request.
+func invalidGraphQL(detail string) *problem.P {
+	return &problem.P{
+		Type:   "invalid_graphql",
+		Title:  "Invalid GraphQL Request",
+		Status: http.StatusBadRequest,
+		Detail: detail,
+	}
+}
@@ -0,0 +1,48 @@
+package horizon
+
+import (
+	"net/url"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/test"
+)
+
+func TestGraphQLActions(t *testing.T) {
+	test.LoadScenario("base")
+	app := NewTestApp()
+	defer app.Close()
+	rh := NewRequestHelper(app)
+
+	graphql := func(query string) string {
+		return "/graphql?query=" + url.QueryEscape(query)
+	}
+
+	Convey("GraphQL Actions:", t, func() {
+
+		Convey("GET /graphql selects fields of the requested resources", func() {
+			w := rh.Get(graphql(`{ ledgers(limit: 2) { sequence } }`), test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Body.String(), ShouldContainSubstring, `"sequence": 1`)
+			So(w.Body.String(), ShouldNotContainSubstring, `"hash"`)
+		})
+
+		Convey("GET /graphql without a query", func() {
+			w := rh.Get("/graphql", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 400)
+			So(w.Body.String(), ShouldContainSubstring, `"errors"`)
+		})
+
+		Convey("GET /graphql with an unknown field", func() {
+			w := rh.Get(graphql(`{ things { id } }`), test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 400)
+			So(w.Body.String(), ShouldContainSubstring, `Unknown field \"things\"`)
+		})
+
+		Convey("GET /graphql streams subscriptions", func() {
+			w := rh.Get(graphql(`subscription { ledgers { sequence } }`), test.RequestHelperStreaming)
+			So(w.Body.String(), ShouldContainSubstring, "event: ledgers\n")
+			So(w.Body.String(), ShouldNotContainSubstring, `"hash"`)
+		})
+	})
+}
@@ -0,0 +1,51 @@
+package horizon
+
+import (
+	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/render/hal"
+)
+
+// This file contains the actions:
+//
+// InflationDestinationsAction: the votes for each inflation destination
+
+// InflationDestinationsAction renders the latest tally of the votes for each
+// inflation destination, the most voted for first, up to the limit param.
+// Given the destination param, only the votes for that account are rendered.
+type InflationDestinationsAction struct {
+	Action
+	Destination string
+	Limit       int32
+	Resource    InflationDestinationsResource
+}
+
+// LoadResource populates action.Resource
+func (action *InflationDestinationsAction) LoadResource() {
+	action.Destination = action.GetAccountID("destination")
+	action.Limit = action.GetLimit(db.DefaultPageSize, db.MaxPageSize)
+	if action.Err != nil {
+		return
+	}
+
+	tally := action.App.InflationDestinations()
+
+	var records []db.CoreInflationDestinationRecord
+	for _, record := range tally.Records {
+		if int32(len(records)) >= action.Limit {
+			break
+		}
+		if action.Destination != "" && record.Destination != action.Destination {
+			continue
+		}
+		records = append(records, record)
+	}
+
+	action.Resource = NewInflationDestinationsResource(action.R.URL.RequestURI(), tally.Ledger, records)
+}
+
+// JSON is a method for actions.JSON
+func (action *InflationDestinationsAction) JSON() {
+	action.Do(action.LoadResource, func() {
+		hal.Render(action.W, action.Resource)
+	})
+}
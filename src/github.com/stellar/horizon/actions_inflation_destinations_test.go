@@ -0,0 +1,48 @@
+package horizon
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/test"
+)
+
+func TestInflationDestinationsActions(t *testing.T) {
+	test.LoadScenario("inflation")
+	app := NewTestApp()
+	defer app.Close()
+	rh := NewRequestHelper(app)
+
+	Convey("Inflation Destinations Actions:", t, func() {
+		app.UpdateInflationDestinations(app.ctx)
+
+		Convey("GET /inflation_destinations", func() {
+			w := rh.Get("/inflation_destinations", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+
+			var result InflationDestinationsResource
+			err := json.Unmarshal(w.Body.Bytes(), &result)
+			So(err, ShouldBeNil)
+			So(result.Ledger, ShouldBeGreaterThan, 0)
+			So(len(result.Destinations), ShouldEqual, 2)
+			So(result.Destinations[0].Destination, ShouldStartWith, "GBRPYHIL")
+			So(result.Destinations[1].Votes, ShouldEqual, "2000381441.9999907")
+		})
+
+		Convey("GET /inflation_destinations?limit=1", func() {
+			w := rh.Get("/inflation_destinations?limit=1", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+
+			var result InflationDestinationsResource
+			err := json.Unmarshal(w.Body.Bytes(), &result)
+			So(err, ShouldBeNil)
+			So(len(result.Destinations), ShouldEqual, 1)
+		})
+
+		Convey("GET /inflation_destinations?destination=", func() {
+			w := rh.Get("/inflation_destinations?destination=notanaccount", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 400)
+		})
+	})
+}
@@ -0,0 +1,101 @@
+package horizon
+
+import (
+	"reflect"
+
+	"github.com/stellar/horizon/render/hal"
+	"github.com/stellar/horizon/render/problem"
+	"github.com/stellar/horizon/render/sse"
+)
+
+// This file contains the actions:
+//
+// IngestionStatusAction: the progress of ingestion and of its backfilling
+// IngestionControlAction: pauses or resumes ingestion
+
+// IngestionStatusAction renders the latest ledger ingested, its lag behind
+// stellar-core, the rate rows are written at and the gaps in history left to
+// backfill.  Streaming clients are sent the status each time it changes.  It
+// is not found when ingestion is disabled.
+type IngestionStatusAction struct {
+	Action
+	Resource IngestionStatusResource
+}
+
+// LoadResource populates action.Resource
+func (action *IngestionStatusAction) LoadResource() {
+	action.Resource, action.Err = action.ingestionStatus()
+}
+
+// JSON is a method for actions.JSON
+func (action *IngestionStatusAction) JSON() {
+	action.Do(action.LoadResource, func() {
+		hal.Render(action.W, action.Resource)
+	})
+}
+
+// SSE is a method for actions.SSE, sending the status as an "ingestion" event
+// when it differs from the one last sent
+func (action *IngestionStatusAction) SSE(stream sse.Stream) {
+	last := action.Resource
+
+	action.LoadResource()
+	if action.Err != nil {
+		stream.Err(action.Err)
+		return
+	}
+
+	if stream.SentCount() > 0 && reflect.DeepEqual(last, action.Resource) {
+		return
+	}
+
+	stream.Send(sse.Event{Event: "ingestion", Data: action.Resource})
+}
+
+// IngestionControlAction pauses or resumes ingestion, as its Pause field says,
+// rendering the status of ingestion afterwards.  It is served by the admin
+// server only.
+type IngestionControlAction struct {
+	Action
+	Pause    bool
+	Resource IngestionStatusResource
+}
+
+// JSON is a method for actions.JSON
+func (action *IngestionControlAction) JSON() {
+	action.Do(
+		func() {
+			if action.App.ingester == nil {
+				action.Err = &problem.NotFound
+				return
+			}
+
+			if action.Pause {
+				action.App.ingester.Pause()
+			} else {
+				action.App.ingester.Resume()
+			}
+		},
+		func() {
+			action.Resource, action.Err = action.ingestionStatus()
+		},
+		func() {
+			hal.Render(action.W, action.Resource)
+		},
+	)
+}
+
+// ingestionStatus returns the status of ingestion, not found when it is
+// disabled
+func (action *Action) ingestionStatus() (IngestionStatusResource, error) {
+	if action.App.ingester == nil {
+		return IngestionStatusResource{}, &problem.NotFound
+	}
+
+	status, err := action.App.ingester.Status(action.Ctx)
+	if err != nil {
+		return IngestionStatusResource{}, err
+	}
+
+	return NewIngestionStatusResource(status, action.App.ingester.Progress()), nil
+}
@@ -0,0 +1,42 @@
+package horizon
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/ingest"
+	"github.com/stellar/horizon/test"
+)
+
+func TestIngestionActions(t *testing.T) {
+	test.LoadScenario("base")
+	app := NewTestApp()
+	defer app.Close()
+	rh := NewRequestHelper(app)
+
+	Convey("Ingestion Actions:", t, func() {
+
+		Convey("GET /admin/ingestion", func() {
+			w := rh.Get("/admin/ingestion", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 404)
+
+			Convey("when ingesting", func() {
+				app.ingester = &ingest.System{HorizonDB: app.historyDb, CoreDB: app.coreDb}
+				defer func() { app.ingester = nil }()
+
+				w := rh.Get("/admin/ingestion", test.RequestHelperNoop)
+				So(w.Code, ShouldEqual, 200)
+
+				var result IngestionStatusResource
+				err := json.Unmarshal(w.Body.Bytes(), &result)
+				So(err, ShouldBeNil)
+				So(result.LatestLedger, ShouldEqual, 3)
+				So(result.CoreLatestLedger, ShouldEqual, 3)
+				So(result.LagLedgers, ShouldEqual, 0)
+				So(result.MissingLedgers, ShouldEqual, 0)
+				So(result.GapsDetectedAt, ShouldBeNil)
+			})
+		})
+	})
+}
@@ -1,7 +1,6 @@
 package horizon
 
 import (
-	"github.com/stellar/horizon/actions"
 	"github.com/stellar/horizon/db"
 	"github.com/stellar/horizon/render/hal"
 	"github.com/stellar/horizon/render/sse"
@@ -11,6 +10,7 @@ import (
 //
 // LedgerIndexAction: pages of ledgers
 // LedgerShowAction: single ledger by sequence
+// LedgerStatsAction: the operations of a single ledger by type
 
 // LedgerIndexAction renders a page of ledger resources, identified by
 // a normal page query.
@@ -18,16 +18,20 @@ type LedgerIndexAction struct {
 	Action
 	Query   db.LedgerPageQuery
 	Records []db.LedgerRecord
+	Headers map[int32]*LedgerHeaderResource
 	Page    hal.Page
 }
 
 // LoadQuery sets action.Query from the request params
 func (action *LedgerIndexAction) LoadQuery() {
-	action.ValidateInt64(actions.ParamCursor)
+	action.ValidateCursor()
 	action.Query = db.LedgerPageQuery{
 		SqlQuery:  action.App.HistoryQuery(),
 		PageQuery: action.GetPageQuery(),
+		TimeRange: action.GetTimeRange(),
 	}
+	action.ValidateCursorWithinHistory(action.Query.PageQuery)
+	action.EnsureHistoryFreshness()
 }
 
 // LoadRecords populates action.Records
@@ -37,7 +41,17 @@ func (action *LedgerIndexAction) LoadRecords() {
 		return
 	}
 
-	action.Err = db.Select(action.Ctx, action.Query, &action.Records)
+	action.Err = action.Select(action.Query, &action.Records)
+	if action.Err != nil {
+		return
+	}
+
+	sequences := make([]int32, len(action.Records))
+	for i, record := range action.Records {
+		sequences[i] = record.Sequence
+	}
+
+	action.Headers, action.Err = action.loadHeaders(sequences...)
 }
 
 // LoadPage populates action.Page
@@ -47,7 +61,16 @@ func (action *LedgerIndexAction) LoadPage() {
 		return
 	}
 
-	action.Page, action.Err = NewLedgerResourcePage(action.Records, action.Query.PageQuery)
+	action.Page, action.Err = NewLedgerResourcePage(action.Records, action.Query)
+	if action.Err != nil {
+		return
+	}
+
+	for i, record := range action.Records {
+		r := action.Page.Records[i].(LedgerResource)
+		r.LedgerHeaderResource = action.Headers[record.Sequence]
+		action.Page.Records[i] = r
+	}
 }
 
 // JSON is a method for actions.JSON
@@ -71,9 +94,12 @@ func (action *LedgerIndexAction) SSE(stream sse.Stream) {
 	records := action.Records[stream.SentCount():]
 
 	for _, record := range records {
+		r := NewLedgerResource(record)
+		r.LedgerHeaderResource = action.Headers[record.Sequence]
+
 		stream.Send(sse.Event{
 			ID:   record.PagingToken(),
-			Data: NewLedgerResource(record),
+			Data: r,
 		})
 	}
 
@@ -99,16 +125,102 @@ func (action *LedgerShowAction) Query() db.LedgerBySequenceQuery {
 // JSON is a method for actions.JSON
 func (action *LedgerShowAction) JSON() {
 	query := action.Query()
+	action.ValidateLedgerWithinHistory(query.Sequence)
+
+	if action.Err != nil {
+		return
+	}
+
+	action.Err = db.Get(action.Ctx, action.Immutable(query), &action.Record)
+
+	if action.Err != nil {
+		return
+	}
+
+	// closed ledgers never change
+	if action.NotModified(action.Record.Sequence) {
+		return
+	}
+
+	headers, err := action.loadHeaders(action.Record.Sequence)
+	if err != nil {
+		action.Err = err
+		return
+	}
+
+	r := NewLedgerResource(action.Record)
+	r.LedgerHeaderResource = headers[action.Record.Sequence]
+	hal.Render(action.W, r)
+}
+
+// LedgerStatsAction renders the counts of the operations of a ledger, found by
+// its sequence number, by type.
+type LedgerStatsAction struct {
+	Action
+	Record db.LedgerStatsRecord
+}
+
+// Query returns a database query to find the stats of a ledger by sequence
+func (action *LedgerStatsAction) Query() db.LedgerStatsQuery {
+	return db.LedgerStatsQuery{
+		SqlQuery: action.App.HistoryQuery(),
+		Sequence: action.GetInt32("ledger_id"),
+	}
+}
+
+// JSON is a method for actions.JSON
+func (action *LedgerStatsAction) JSON() {
+	query := action.Query()
+	action.ValidateLedgerWithinHistory(query.Sequence)
 
 	if action.Err != nil {
 		return
 	}
 
-	action.Err = db.Get(action.Ctx, query, &action.Record)
+	action.Err = db.Get(action.Ctx, action.Immutable(query), &action.Record)
 
 	if action.Err != nil {
 		return
 	}
 
-	hal.Render(action.W, NewLedgerResource(action.Record))
+	// closed ledgers never change
+	if action.NotModified(action.Record.Ledger.Sequence) {
+		return
+	}
+
+	hal.Render(action.W, NewLedgerStatsResource(action.Record))
+}
+
+// loadHeaders loads, by sequence, the headers stellar-core has kept of the
+// ledgers of the given sequences, less their xdr unless the client asked for
+// it.
+func (action *Action) loadHeaders(sequences ...int32) (map[int32]*LedgerHeaderResource, error) {
+	result := map[int32]*LedgerHeaderResource{}
+	if len(sequences) == 0 {
+		return result, nil
+	}
+
+	withXDR := action.IncludeXDR()
+	if action.Err != nil {
+		return nil, action.Err
+	}
+
+	var records []db.CoreLedgerHeaderRecord
+	err := db.Select(action.Ctx, db.CoreLedgerHeadersBySequenceQuery{
+		SqlQuery:  action.App.CoreQuery(),
+		Sequences: sequences,
+	}, &records)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, record := range records {
+		r, err := NewLedgerHeaderResource(record, withXDR)
+		if err != nil {
+			return nil, err
+		}
+		result[record.Sequence] = r
+	}
+
+	return result, nil
 }
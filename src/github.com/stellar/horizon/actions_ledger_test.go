@@ -2,9 +2,13 @@ package horizon
 
 import (
 	"encoding/json"
+	"net/http"
+	"strconv"
 	"testing"
 
 	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/render/problem"
 	"github.com/stellar/horizon/test"
 )
 
@@ -27,12 +31,128 @@ func TestLedgerActions(t *testing.T) {
 			So(result.Sequence, ShouldEqual, 1)
 		})
 
+		Convey("GET /ledgers/2", func() {
+			w := rh.Get("/ledgers/2", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+
+			var result LedgerResource
+			err := json.Unmarshal(w.Body.Bytes(), &result)
+			So(err, ShouldBeNil)
+			So(result.LedgerHeaderResource, ShouldNotBeNil)
+			So(result.HeaderXDR, ShouldNotBeBlank)
+			So(result.ProtocolVersion, ShouldEqual, 1)
+			So(result.BaseFee, ShouldEqual, 100)
+			So(result.BaseReserve, ShouldEqual, 100000000)
+			So(result.MaxTxSetSize, ShouldEqual, 500)
+			So(result.Upgrades, ShouldResemble, []LedgerUpgradeResource{
+				{Type: "protocol_version", Value: 1},
+				{Type: "max_tx_set_size", Value: 500},
+			})
+
+			w = rh.Get("/ledgers/2?include_xdr=false", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+
+			result = LedgerResource{}
+			err = json.Unmarshal(w.Body.Bytes(), &result)
+			So(err, ShouldBeNil)
+			So(result.HeaderXDR, ShouldBeBlank)
+			So(result.ProtocolVersion, ShouldEqual, 1)
+		})
+
+		Convey("GET /ledgers/1 with If-None-Match", func() {
+			w := rh.Get("/ledgers/1", test.RequestHelperNoop)
+			etag := w.Header().Get("ETag")
+			So(etag, ShouldNotBeBlank)
+
+			w = rh.Get("/ledgers/1", func(r *http.Request) {
+				r.Header.Set("If-None-Match", etag)
+			})
+			So(w.Code, ShouldEqual, 304)
+			So(w.Body.Len(), ShouldEqual, 0)
+
+			w = rh.Get("/ledgers/1?fields=hash", func(r *http.Request) {
+				r.Header.Set("If-None-Match", etag)
+			})
+			So(w.Code, ShouldEqual, 200)
+			So(w.Header().Get("ETag"), ShouldNotEqual, etag)
+		})
+
+		Convey("GET /ledgers/1 as json:api", func() {
+			w := rh.Get("/ledgers/1", func(r *http.Request) {
+				r.Header.Set("Accept", "application/vnd.api+json")
+			})
+			So(w.Code, ShouldEqual, 200)
+			So(w.Header().Get("Content-Type"), ShouldEqual, "application/vnd.api+json")
+
+			var doc struct {
+				Data struct {
+					Type       string
+					Attributes LedgerResource
+				}
+			}
+			err := json.Unmarshal(w.Body.Bytes(), &doc)
+			So(err, ShouldBeNil)
+			So(doc.Data.Type, ShouldEqual, "ledgers")
+			So(doc.Data.Attributes.Sequence, ShouldEqual, 1)
+		})
+
+		Convey("GET /ledgers/1?pretty=true", func() {
+			w := rh.Get("/ledgers/1", test.RequestHelperNoop)
+			So(w.Body.String(), ShouldStartWith, `{"_links"`)
+
+			w = rh.Get("/ledgers/1?pretty=true", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Body.String(), ShouldStartWith, "{\n  \"_links\"")
+		})
+
+		Convey("GET /ledgers/1?callback=cb", func() {
+			w := rh.Get("/ledgers/1?callback=cb", test.RequestHelperNoop)
+			So(w.Header().Get("Content-Type"), ShouldEqual, "application/hal+json")
+
+			app.config.JSONP = true
+			defer func() { app.config.JSONP = false }()
+
+			w = rh.Get("/ledgers/1?callback=cb", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Header().Get("Content-Type"), ShouldEqual, "application/javascript; charset=utf-8")
+			So(w.Body.String(), ShouldStartWith, "/**/cb({")
+
+			w = rh.Get("/ledgers/1?callback=alert(1)", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 400)
+		})
+
 		Convey("GET /ledgers/100", func() {
 			w := rh.Get("/ledgers/100", test.RequestHelperNoop)
 
 			So(w.Code, ShouldEqual, 404)
 		})
 
+		Convey("GET /ledgers/:ledger_id/stats", func() {
+			w := rh.Get("/ledgers/2/stats", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+
+			var result LedgerStatsResource
+			err := json.Unmarshal(w.Body.Bytes(), &result)
+			So(err, ShouldBeNil)
+			So(result.Sequence, ShouldEqual, 2)
+			So(result.OperationCount, ShouldEqual, 3)
+			So(result.OperationTypeCounts["create_account"], ShouldEqual, 3)
+			So(result.OperationTypeCounts["payment"], ShouldEqual, 0)
+
+			w = rh.Get("/ledgers/100/stats", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 404)
+		})
+
+		Convey("GET /ledgers?start_time", func() {
+			w := rh.Get("/ledgers?start_time=2015-10-07T23:07:27Z", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Body, ShouldBePageOf, 2)
+
+			w = rh.Get("/ledgers?start_time=2015-10-07T23:07:27Z&end_time=2015-10-07T23:07:28Z", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Body, ShouldBePageOf, 1)
+		})
+
 		Convey("GET /ledgers", func() {
 
 			Convey("With Default Params", func() {
@@ -68,3 +188,46 @@ func TestLedgerActions(t *testing.T) {
 		})
 	})
 }
+
+func TestHistoryBounds(t *testing.T) {
+	Convey("History bounds:", t, func() {
+		test.LoadScenario("base")
+		app := NewTestApp()
+		defer app.Close()
+		rh := NewRequestHelper(app)
+
+		problemType := func(w interface{ Bytes() []byte }) string {
+			var result problem.P
+			So(json.Unmarshal(w.Bytes(), &result), ShouldBeNil)
+			return result.Type
+		}
+
+		Convey("ledgers reaped from history are gone", func() {
+			So(db.DeleteLedgerRange(app.historyDb, 1, 1), ShouldBeNil)
+
+			w := rh.Get("/ledgers/1", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, http.StatusGone)
+			So(problemType(w.Body), ShouldEqual, problem.TypeURI("before_history"))
+			So(w.Body.String(), ShouldContainSubstring, `"history_elder_ledger": 2`)
+
+			cursor := strconv.FormatInt(db.TotalOrderId{LedgerSequence: 1}.ToInt64(), 10)
+			w = rh.Get("/transactions?order=desc&cursor="+cursor, test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, http.StatusGone)
+
+			w = rh.Get("/transactions?cursor="+cursor, test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, http.StatusOK)
+		})
+
+		Convey("history lagging behind stellar-core is stale", func() {
+			app.config.HistoryStaleThreshold = 1
+			w := rh.Get("/transactions", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, http.StatusOK)
+
+			So(db.DeleteLedgerRange(app.historyDb, 2, 3), ShouldBeNil)
+			w = rh.Get("/transactions", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, http.StatusServiceUnavailable)
+			So(problemType(w.Body), ShouldEqual, problem.TypeURI("stale_history"))
+			So(w.Body.String(), ShouldContainSubstring, `"core_latest_ledger": 3`)
+		})
+	})
+}
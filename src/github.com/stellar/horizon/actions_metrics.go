@@ -1,9 +1,15 @@
 package horizon
 
 import (
+	"net/http"
+
+	gctx "github.com/goji/context"
 	"github.com/jagregory/halgo"
 	"github.com/rcrowley/go-metrics"
+	"github.com/stellar/horizon/log"
+	"github.com/stellar/horizon/prometheus"
 	"github.com/stellar/horizon/render/hal"
+	"github.com/zenazn/goji/web"
 )
 
 // MetricsAction collects and renders a snapshot from the metrics system that
@@ -89,3 +95,23 @@ func (action *MetricsAction) LoadSnapshot() {
 	})
 
 }
+
+// prometheusMetricsHandler renders the metrics of the app's registry, along
+// with the latency and slow queries of requests by route, in the text
+// exposition format scraped by prometheus.
+func prometheusMetricsHandler(c web.C, w http.ResponseWriter, r *http.Request) {
+	app := c.Env["app"].(*App)
+	ctx := gctx.FromC(c)
+
+	app.UpdateMetrics(ctx)
+
+	w.Header().Set("Content-Type", prometheus.ContentType)
+	err := prometheus.Write(w,
+		&prometheus.Registry{Namespace: "horizon", Registry: app.metrics},
+		app.web.routeHistogram,
+		app.web.slowQueryRoutes,
+	)
+	if err != nil {
+		log.WithStack(ctx, err).Error(err)
+	}
+}
@@ -0,0 +1,39 @@
+package horizon
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/prometheus"
+	"github.com/stellar/horizon/test"
+)
+
+func TestMetricsActions(t *testing.T) {
+	test.LoadScenario("base")
+	app := NewTestApp()
+	defer app.Close()
+	rh := NewRequestHelper(app)
+
+	Convey("Metrics Actions:", t, func() {
+
+		Convey("GET /metrics/prometheus", func() {
+			w := rh.Get("/ledgers/1", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			w = rh.Get("/nope", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 404)
+
+			w = rh.Get("/metrics/prometheus", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Header().Get("Content-Type"), ShouldEqual, prometheus.ContentType)
+
+			body := w.Body.String()
+			So(body, ShouldContainSubstring, "horizon_history_latest_ledger 3\n")
+			So(body, ShouldContainSubstring, "horizon_history_lag_ledgers 0\n")
+			So(body, ShouldContainSubstring, "horizon_sse_open_connections ")
+			So(body, ShouldContainSubstring, "horizon_txsub_succeeded_total ")
+			So(body, ShouldContainSubstring, "horizon_history_connections_in_use ")
+			So(body, ShouldContainSubstring, `horizon_http_request_duration_seconds_count{route="/ledgers/:id",method="GET"} 1`)
+			So(body, ShouldContainSubstring, `horizon_http_request_duration_seconds_count{route="not_found",method="GET"} 1`)
+		})
+	})
+}
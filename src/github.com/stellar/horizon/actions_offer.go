@@ -2,6 +2,7 @@ package horizon
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/stellar/horizon/db"
 	"github.com/stellar/horizon/render/hal"
@@ -20,6 +21,9 @@ type OffersByAccountAction struct {
 	Query   db.CoreOfferPageByAddressQuery
 	Records []db.CoreOfferRecord
 	Page    hal.Page
+
+	// Sent holds, by offer id, the state of each offer last streamed.
+	Sent map[int64]db.CoreOfferRecord
 }
 
 // LoadQuery sets action.Query from the request params
@@ -27,7 +31,7 @@ func (action *OffersByAccountAction) LoadQuery() {
 	action.Query = db.CoreOfferPageByAddressQuery{
 		SqlQuery:  action.App.CoreQuery(),
 		PageQuery: action.GetPageQuery(),
-		Address:   action.GetString("account_id"),
+		Address:   action.GetAccountID("account_id"),
 	}
 
 }
@@ -39,7 +43,7 @@ func (action *OffersByAccountAction) LoadRecords() {
 		return
 	}
 
-	action.Err = db.Select(action.Ctx, action.Query, &action.Records)
+	action.Err = action.Select(action.Query, &action.Records)
 }
 
 // LoadPage populates action.Page
@@ -62,7 +66,10 @@ func (action *OffersByAccountAction) JSON() {
 	hal.Render(action.W, action.Page)
 }
 
-// SSE is a method for actions.SSE
+// SSE is a method for actions.SSE.  The account's offers are sent when the
+// stream opens and then, on each pump, only those that were created, modified
+// or removed since.  A removed offer, whether filled or cancelled, is sent with
+// an amount of zero.
 func (action *OffersByAccountAction) SSE(stream sse.Stream) {
 	action.LoadRecords()
 	if action.Err != nil {
@@ -70,7 +77,11 @@ func (action *OffersByAccountAction) SSE(stream sse.Stream) {
 		return
 	}
 
-	for _, record := range action.Records[stream.SentCount():] {
+	if action.Sent == nil {
+		action.Sent = map[int64]db.CoreOfferRecord{}
+	}
+
+	for _, record := range offerChanges(action.Sent, action.Records, action.Query.PageQuery) {
 		stream.Send(sse.Event{
 			ID:   record.PagingToken(),
 			Data: NewOfferResource(record),
@@ -81,3 +92,53 @@ func (action *OffersByAccountAction) SSE(stream sse.Stream) {
 		stream.Done()
 	}
 }
+
+// offerChanges returns the records that differ from the state of the offers in
+// sent, updating sent to match.  Offers of sent missing from records, within
+// the range of offers the page covers, are returned as removed: with an amount
+// of zero.
+func offerChanges(sent map[int64]db.CoreOfferRecord, records []db.CoreOfferRecord, page db.PageQuery) (result []db.CoreOfferRecord) {
+	current := make(map[int64]bool, len(records))
+
+	for _, record := range records {
+		current[record.OfferID] = true
+
+		if prev, ok := sent[record.OfferID]; ok && prev == record {
+			continue
+		}
+
+		sent[record.OfferID] = record
+		result = append(result, record)
+	}
+
+	// a full page may not reach the offers beyond its last
+	full := len(records) > 0 && len(records) >= int(page.Limit)
+	last := int64(0)
+	if full {
+		last = records[len(records)-1].OfferID
+	}
+
+	var removed []db.CoreOfferRecord
+	for id, prev := range sent {
+		if current[id] {
+			continue
+		}
+
+		if full && (page.Order == db.OrderDescending && id < last || page.Order != db.OrderDescending && id > last) {
+			continue
+		}
+
+		delete(sent, id)
+		prev.Amount = 0
+		removed = append(removed, prev)
+	}
+
+	sort.Sort(byOfferID(removed))
+	return append(result, removed...)
+}
+
+type byOfferID []db.CoreOfferRecord
+
+func (s byOfferID) Len() int           { return len(s) }
+func (s byOfferID) Less(i, j int) bool { return s[i].OfferID < s[j].OfferID }
+func (s byOfferID) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
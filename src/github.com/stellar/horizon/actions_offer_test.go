@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/db"
 	"github.com/stellar/horizon/test"
 )
 
@@ -24,3 +25,47 @@ func TestOfferActions(t *testing.T) {
 
 	})
 }
+
+func TestOfferChanges(t *testing.T) {
+	Convey("offerChanges", t, func() {
+		sent := map[int64]db.CoreOfferRecord{}
+		page := db.PageQuery{Order: db.OrderAscending, Limit: 2}
+		offers := []db.CoreOfferRecord{
+			{OfferID: 1, Amount: 10, Lastmodified: 3},
+			{OfferID: 2, Amount: 20, Lastmodified: 3},
+		}
+
+		changes := offerChanges(sent, offers, page)
+		So(len(changes), ShouldEqual, 2)
+
+		Convey("sends nothing when no offer changes", func() {
+			changes = offerChanges(sent, offers, page)
+			So(changes, ShouldBeEmpty)
+		})
+
+		Convey("sends modified offers", func() {
+			changes = offerChanges(sent, []db.CoreOfferRecord{
+				offers[0],
+				{OfferID: 2, Amount: 5, Lastmodified: 4},
+			}, page)
+			So(len(changes), ShouldEqual, 1)
+			So(changes[0].Amount, ShouldEqual, 5)
+		})
+
+		Convey("sends removed offers with an amount of zero", func() {
+			changes = offerChanges(sent, offers[1:], page)
+			So(len(changes), ShouldEqual, 1)
+			So(changes[0].OfferID, ShouldEqual, 1)
+			So(changes[0].Amount, ShouldEqual, 0)
+
+			changes = offerChanges(sent, offers[1:], page)
+			So(changes, ShouldBeEmpty)
+		})
+
+		Convey("ignores offers beyond a full page", func() {
+			sent[3] = db.CoreOfferRecord{OfferID: 3, Amount: 30}
+			changes = offerChanges(sent, offers, page)
+			So(changes, ShouldBeEmpty)
+		})
+	})
+}
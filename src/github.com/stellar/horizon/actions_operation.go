@@ -1,9 +1,14 @@
 package horizon
 
 import (
-	"github.com/stellar/horizon/actions"
+	"fmt"
+	"net/http"
+	"strings"
+
 	"github.com/stellar/horizon/db"
 	"github.com/stellar/horizon/render/hal"
+	"github.com/stellar/horizon/render/ndjson"
+	"github.com/stellar/horizon/render/problem"
 	"github.com/stellar/horizon/render/sse"
 )
 
@@ -14,7 +19,7 @@ import (
 
 // OperationIndexAction renders a page of operations resources, identified by
 // a normal page query and optionally filtered by an account, ledger, or
-// transaction.
+// transaction, and by the comma separated operation types of the type param.
 type OperationIndexAction struct {
 	Action
 	Query   db.OperationPageQuery
@@ -24,13 +29,50 @@ type OperationIndexAction struct {
 
 // LoadQuery sets action.Query from the request params
 func (action *OperationIndexAction) LoadQuery() {
-	action.ValidateInt64(actions.ParamCursor)
+	action.ValidateCursor()
 	action.Query = db.OperationPageQuery{
 		SqlQuery:        action.App.HistoryQuery(),
 		PageQuery:       action.GetPageQuery(),
-		AccountAddress:  action.GetString("account_id"),
+		AccountAddress:  action.GetAccountID("account_id"),
 		LedgerSequence:  action.GetInt32("ledger_id"),
 		TransactionHash: action.GetString("tx_id"),
+		TimeRange:       action.GetTimeRange(),
+		IncludeFailed:   action.IncludeFailed(),
+		Core:            action.App.CoreQuery(),
+	}
+	action.ValidateCursorWithinHistory(action.Query.PageQuery)
+	action.EnsureHistoryFreshness()
+	action.loadTypes()
+}
+
+// loadTypes sets action.Query.Types from the type param, a comma separated
+// list of operation type names such as `payment,create_account`.
+func (action *OperationIndexAction) loadTypes() {
+	if action.Err != nil {
+		return
+	}
+
+	for _, name := range strings.Split(action.GetString("type"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		t, ok := operationTypeNamed(name)
+		if !ok {
+			action.Err = &problem.P{
+				Type:   "invalid_type_filter",
+				Title:  "Invalid Type Filter",
+				Status: http.StatusBadRequest,
+				Detail: fmt.Sprintf(
+					"%q is not an operation type.  The type param may list: %s.",
+					name, strings.Join(operationTypeNames(), ", "),
+				),
+			}
+			return
+		}
+
+		action.Query.Types = append(action.Query.Types, t)
 	}
 }
 
@@ -41,7 +83,7 @@ func (action *OperationIndexAction) LoadRecords() {
 		return
 	}
 
-	action.Err = db.Select(action.Ctx, action.Query, &action.Records)
+	action.Err = action.Select(action.Query, &action.Records)
 }
 
 // LoadPage populates action.Page
@@ -51,7 +93,7 @@ func (action *OperationIndexAction) LoadPage() {
 		return
 	}
 
-	action.Page, action.Err = NewOperationResourcePage(action.Records, action.Query.PageQuery, action.Path())
+	action.Page, action.Err = NewOperationResourcePage(action.Records, action.Query, action.Path())
 }
 
 // JSON is a method for actions.JSON
@@ -93,6 +135,32 @@ func (action *OperationIndexAction) SSE(stream sse.Stream) {
 	}
 }
 
+// NDJSON is a method for actions.NDJSON
+func (action *OperationIndexAction) NDJSON(export *ndjson.Stream) {
+	action.LoadQuery()
+	action.Query.Limit = db.MaxPageSize
+
+	for action.Err == nil && export.Continue() {
+		var records []db.OperationRecord
+		action.Err = action.Select(action.Query, &records)
+		if action.Err != nil || len(records) == 0 {
+			return
+		}
+
+		for _, record := range records {
+			r, err := NewOperationResource(record)
+			if err != nil {
+				action.Err = err
+				return
+			}
+
+			export.Write(r)
+		}
+
+		action.Query.Cursor = records[len(records)-1].PagingToken()
+	}
+}
+
 // OperationShowAction renders a ledger found by its sequence number.
 type OperationShowAction struct {
 	Action
@@ -114,7 +182,7 @@ func (action *OperationShowAction) JSON() {
 		return
 	}
 
-	action.Err = db.Get(action.Ctx, query, &action.Record)
+	action.Err = db.Get(action.Ctx, action.Immutable(query), &action.Record)
 	if action.Err != nil {
 		return
 	}
@@ -60,6 +60,45 @@ func TestOperationActions(t *testing.T) {
 			So(w.Body, ShouldBePageOf, 1)
 		})
 
+		Convey("GET /operations?type", func() {
+			w := rh.Get("/operations?type=payment", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Body, ShouldBePageOf, 1)
+
+			w = rh.Get("/operations?type=payment,create_account", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Body, ShouldBePageOf, 4)
+
+			var page map[string]interface{}
+			err := json.Unmarshal(w.Body.Bytes(), &page)
+			So(err, ShouldBeNil)
+			next := page["_links"].(map[string]interface{})["next"].(map[string]interface{})
+			So(next["href"], ShouldStartWith, "/operations?type=payment,create_account&")
+
+			w = rh.Get("/accounts/GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU/operations?type=create_account", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Body, ShouldBePageOf, 1)
+
+			w = rh.Get("/operations?type=inflation", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Body, ShouldBePageOf, 0)
+
+			w = rh.Get("/operations?type=payment,bogus", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 400)
+		})
+
+		Convey("GET /operations?start_time&end_time", func() {
+			w := rh.Get("/operations?start_time=2015-10-07T23:07:27Z&end_time=2015-10-07T23:07:28Z", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Body, ShouldBePageOf, 3)
+
+			var page map[string]interface{}
+			err := json.Unmarshal(w.Body.Bytes(), &page)
+			So(err, ShouldBeNil)
+			next := page["_links"].(map[string]interface{})["next"].(map[string]interface{})
+			So(next["href"], ShouldStartWith, "/operations?start_time=2015-10-07T23:07:27Z&end_time=2015-10-07T23:07:28Z&")
+		})
+
 		Convey("GET /operations/:id", func() {
 			w := rh.Get("/operations/8589938689", test.RequestHelperNoop)
 			So(w.Code, ShouldEqual, 200)
@@ -68,11 +107,49 @@ func TestOperationActions(t *testing.T) {
 			err := json.Unmarshal(w.Body.Bytes(), &result)
 			So(err, ShouldBeNil)
 			So(result["paging_token"], ShouldEqual, "8589938689")
+			So(result["transaction_successful"], ShouldEqual, true)
 
 			w = rh.Get("/operations/10", test.RequestHelperNoop)
 			So(w.Code, ShouldEqual, 404)
 		})
 
+		Convey("GET /operations?include_failed=true", func() {
+			// the scenario's transactions all succeeded
+			w := rh.Get("/operations?include_failed=true", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Body, ShouldBePageOf, 4)
+
+			w = rh.Get("/operations?include_failed=true&order=desc&limit=2", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Body, ShouldBePageOf, 2)
+
+			w = rh.Get("/transactions/2374e99349b9ef7dba9a5db3339b78fda8f34777b1af33ba468ad5c0df946d4d/operations?include_failed=true", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Body, ShouldBePageOf, 1)
+
+			w = rh.Get("/transactions/0000000000000000000000000000000000000000000000000000000000000000/operations?include_failed=true", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 404)
+
+			w = rh.Get("/operations?include_failed=maybe", test.RequestHelperNoop)
+			So(w.Code, ShouldNotEqual, 200)
+		})
+
+		Convey("GET /operations?include_failed=true follows next", func() {
+			w := rh.Get("/operations?include_failed=true&limit=3", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Body, ShouldBePageOf, 3)
+
+			var page map[string]interface{}
+			err := json.Unmarshal(w.Body.Bytes(), &page)
+			So(err, ShouldBeNil)
+			next := page["_links"].(map[string]interface{})["next"].(map[string]interface{})["href"].(string)
+			So(next, ShouldStartWith, "/operations?include_failed=true&")
+
+			w = rh.Get(next, test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Body, ShouldBePageOf, 1)
+		})
+
 		Convey("GET /ledgers/100/operations", func() {
 			w := rh.Get("/ledgers/100/operations", test.RequestHelperNoop)
 
@@ -12,6 +12,9 @@ type OrderBookShowAction struct {
 	Query    *db.OrderBookSummaryQuery
 	Record   db.OrderBookSummaryRecord
 	Resource OrderBookSummaryResource
+
+	// Version is the version of the book whose summary was last streamed.
+	Version db.OrderBookVersion
 }
 
 // LoadQuery sets action.Query from the request params
@@ -31,9 +34,23 @@ func (action *OrderBookShowAction) LoadQuery() {
 	return
 }
 
+// VersionQuery returns a database query to find the version of the book
+// selected by action.Query
+func (action *OrderBookShowAction) VersionQuery() db.OrderBookVersionQuery {
+	return db.OrderBookVersionQuery{
+		SqlQuery:      action.Query.SqlQuery,
+		SellingType:   action.Query.SellingType,
+		SellingIssuer: action.Query.SellingIssuer,
+		SellingCode:   action.Query.SellingCode,
+		BuyingType:    action.Query.BuyingType,
+		BuyingIssuer:  action.Query.BuyingIssuer,
+		BuyingCode:    action.Query.BuyingCode,
+	}
+}
+
 // LoadRecord populates action.Record
 func (action *OrderBookShowAction) LoadRecord() {
-	action.Err = db.Select(action.Ctx, action.Query, &action.Record)
+	action.Err = action.Select(action.Mutable(action.Query), &action.Record)
 }
 
 // LoadResource populates action.Record
@@ -50,14 +67,31 @@ func (action *OrderBookShowAction) JSON() {
 	})
 }
 
-// SSE is a method for actions.SSE
+// SSE is a method for actions.SSE.  A summary of the book is sent when the
+// stream opens and then, on each pump, only if an offer of the book has
+// changed since the last summary sent.
 func (action *OrderBookShowAction) SSE(stream sse.Stream) {
-	action.Do(action.LoadQuery, action.LoadRecord, action.LoadResource)
+	var version db.OrderBookVersion
+
+	action.Do(action.LoadQuery, func() {
+		action.Err = action.Select(action.VersionQuery(), &version)
+	})
+	if action.Err != nil {
+		stream.Err(action.Err)
+		return
+	}
+
+	if stream.SentCount() > 0 && version == action.Version {
+		return
+	}
+
+	action.Do(action.LoadRecord, action.LoadResource)
 	if action.Err != nil {
 		stream.Err(action.Err)
 		return
 	}
 
+	action.Version = version
 	stream.Send(sse.Event{
 		Data: action.Resource,
 	})
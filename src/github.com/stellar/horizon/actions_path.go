@@ -0,0 +1,308 @@
+package horizon
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/stellar/go-stellar-base/amount"
+	"github.com/stellar/go-stellar-base/xdr"
+	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/paths"
+	"github.com/stellar/horizon/render/hal"
+	"github.com/stellar/horizon/render/problem"
+	"golang.org/x/net/context"
+)
+
+// This file contains the actions:
+//
+// PathStrictReceiveAction: paths delivering an exact amount, cheapest first
+// PathStrictSendAction: paths sending an exact amount, most received first
+
+// PathStrictReceiveAction renders the paths by which an exact amount of the
+// destination asset can be received, from the assets listed in the
+// source_assets param or, given a source_account, from the assets that account
+// holds.  The paths found from an account are limited to those it can afford.
+type PathStrictReceiveAction struct {
+	Action
+	Destination       paths.Asset
+	DestinationAmount int64
+	Sources           []paths.Asset
+	Balances          map[paths.Asset]int64
+	Options           paths.Options
+	Records           []paths.Path
+	Page              hal.Page
+}
+
+// LoadQuery sets the search from the request params
+func (action *PathStrictReceiveAction) LoadQuery() {
+	action.Destination = action.getPathAsset("destination")
+	action.DestinationAmount = action.getPathAmount("destination_amount")
+	action.Options = action.getPathOptions()
+
+	account := action.GetString("source_account")
+	list := action.GetString("source_assets")
+	if action.Err != nil {
+		return
+	}
+
+	switch {
+	case account != "" && list != "":
+		action.Err = invalidPathRequest("Provide either source_account or source_assets, not both.")
+	case account != "":
+		action.Sources, action.Balances, action.Err = action.accountAssets(account, true)
+	case list != "":
+		action.Sources, action.Err = action.parsePathAssets("source_assets", list)
+	default:
+		action.Err = invalidPathRequest("Either source_account or source_assets is required.")
+	}
+}
+
+// LoadRecords populates action.Records
+func (action *PathStrictReceiveAction) LoadRecords() {
+	action.LoadQuery()
+	if action.Err != nil {
+		return
+	}
+
+	found := action.App.paths.Graph().StrictReceive(
+		action.Sources,
+		action.Destination,
+		action.DestinationAmount,
+		action.Options,
+	)
+
+	if action.Ctx.Err() == context.DeadlineExceeded {
+		action.Err = action.Ctx.Err()
+		return
+	}
+
+	action.Records = []paths.Path{}
+	for _, p := range found {
+		if action.Balances != nil && p.SourceAmount > action.Balances[p.Source] {
+			continue
+		}
+		action.Records = append(action.Records, p)
+	}
+}
+
+// JSON is a method for actions.JSON
+func (action *PathStrictReceiveAction) JSON() {
+	action.LoadRecords()
+	if action.Err != nil {
+		return
+	}
+
+	action.Page = NewPathResourcePage(action.Records, action.R.URL.RequestURI())
+	hal.Render(action.W, action.Page)
+}
+
+// PathStrictSendAction renders the paths by which an exact amount of the
+// source asset can be sent, to the assets listed in the destination_assets
+// param or, given a destination_account, to the assets that account can
+// receive.
+type PathStrictSendAction struct {
+	Action
+	Source       paths.Asset
+	SourceAmount int64
+	Destinations []paths.Asset
+	Options      paths.Options
+	Records      []paths.Path
+	Page         hal.Page
+}
+
+// LoadQuery sets the search from the request params
+func (action *PathStrictSendAction) LoadQuery() {
+	action.Source = action.getPathAsset("source")
+	action.SourceAmount = action.getPathAmount("source_amount")
+	action.Options = action.getPathOptions()
+
+	account := action.GetString("destination_account")
+	list := action.GetString("destination_assets")
+	if action.Err != nil {
+		return
+	}
+
+	switch {
+	case account != "" && list != "":
+		action.Err = invalidPathRequest("Provide either destination_account or destination_assets, not both.")
+	case account != "":
+		action.Destinations, _, action.Err = action.accountAssets(account, false)
+	case list != "":
+		action.Destinations, action.Err = action.parsePathAssets("destination_assets", list)
+	default:
+		action.Err = invalidPathRequest("Either destination_account or destination_assets is required.")
+	}
+}
+
+// LoadRecords populates action.Records
+func (action *PathStrictSendAction) LoadRecords() {
+	action.LoadQuery()
+	if action.Err != nil {
+		return
+	}
+
+	action.Records = action.App.paths.Graph().StrictSend(
+		action.Source,
+		action.SourceAmount,
+		action.Destinations,
+		action.Options,
+	)
+
+	if action.Ctx.Err() == context.DeadlineExceeded {
+		action.Err = action.Ctx.Err()
+	}
+}
+
+// JSON is a method for actions.JSON
+func (action *PathStrictSendAction) JSON() {
+	action.LoadRecords()
+	if action.Err != nil {
+		return
+	}
+
+	action.Page = NewPathResourcePage(action.Records, action.R.URL.RequestURI())
+	hal.Render(action.W, action.Page)
+}
+
+// getPathAsset reads the asset whose params are named with prefix, such as
+// destination_asset_type, destination_asset_code and destination_asset_issuer.
+func (action *Action) getPathAsset(prefix string) paths.Asset {
+	if action.Err != nil {
+		return paths.Asset{}
+	}
+
+	t := action.GetAssetType(prefix + "_asset_type")
+	code := action.GetString(prefix + "_asset_code")
+	issuer := action.GetString(prefix + "_asset_issuer")
+	if action.Err != nil {
+		action.Err = invalidPathRequest(fmt.Sprintf(
+			"The %s_asset_type param must be one of native, credit_alphanum4 or credit_alphanum12.", prefix))
+		return paths.Asset{}
+	}
+
+	if t == xdr.AssetTypeAssetTypeNative {
+		return paths.Native
+	}
+
+	if code == "" || issuer == "" {
+		action.Err = invalidPathRequest(fmt.Sprintf(
+			"The %s_asset_code and %s_asset_issuer params are required unless %s_asset_type is native.",
+			prefix, prefix, prefix))
+		return paths.Asset{}
+	}
+
+	return paths.Asset{Type: t, Code: code, Issuer: issuer}
+}
+
+// getPathAmount reads a positive amount, such as 10.5, from the named param.
+func (action *Action) getPathAmount(name string) int64 {
+	raw := action.GetString(name)
+	if action.Err != nil {
+		return 0
+	}
+
+	parsed, err := amount.Parse(raw)
+	if err != nil || parsed <= 0 {
+		action.Err = invalidPathRequest(fmt.Sprintf("The %s param must be a positive amount.", name))
+		return 0
+	}
+
+	return int64(parsed)
+}
+
+// getPathOptions reads the max_path_length and exclude_assets params.  Paths
+// may be no longer than the server's configured maximum, which is also the
+// length searched when max_path_length is omitted.
+func (action *Action) getPathOptions() (opts paths.Options) {
+	if action.Err != nil {
+		return
+	}
+
+	max := action.App.config.PathMaxLength
+	if max <= 0 {
+		max = paths.DefaultMaxLength
+	}
+
+	length := action.GetInt32("max_path_length")
+	exclude := action.GetString("exclude_assets")
+	if action.Err != nil {
+		action.Err = invalidPathRequest("The max_path_length param must be an integer.")
+		return
+	}
+
+	if length < 0 || int(length) > max {
+		action.Err = invalidPathRequest(fmt.Sprintf(
+			"The max_path_length param must be between 0 and %d.", max))
+		return
+	}
+
+	opts.MaxLength = max
+	if length > 0 {
+		opts.MaxLength = int(length)
+	}
+	opts.Done = action.Ctx.Done()
+
+	opts.Exclude, action.Err = action.parsePathAssets("exclude_assets", exclude)
+	return
+}
+
+// parsePathAssets parses the comma separated list of assets read from the
+// named param.
+func (action *Action) parsePathAssets(name, list string) ([]paths.Asset, error) {
+	result, err := paths.ParseAssets(list)
+	if err != nil {
+		return nil, invalidPathRequest(fmt.Sprintf(
+			"The %s param must be a comma separated list of assets, each of "+
+				"which is native or of the form CODE:ISSUER.", name))
+	}
+
+	return result, nil
+}
+
+// accountAssets returns the native asset and the assets address trusts, along
+// with the balance of each.  When held is true, only the assets address has a
+// balance of are returned.
+func (action *Action) accountAssets(address string, held bool) ([]paths.Asset, map[paths.Asset]int64, error) {
+	var account db.CoreAccountRecord
+	err := db.Get(action.Ctx, db.CoreAccountByAddressQuery{
+		SqlQuery: action.App.CoreQuery(),
+		Address:  address,
+	}, &account)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var trustlines []db.CoreTrustlineRecord
+	err = db.Select(action.Ctx, db.CoreTrustlinesByAddressQuery{
+		SqlQuery: action.App.CoreQuery(),
+		Address:  address,
+	}, &trustlines)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := []paths.Asset{paths.Native}
+	balances := map[paths.Asset]int64{paths.Native: account.Balance}
+	for _, tl := range trustlines {
+		if held && tl.Balance <= 0 {
+			continue
+		}
+
+		a := pathAsset(tl.Assettype, tl.Assetcode, tl.Issuer)
+		result = append(result, a)
+		balances[a] = tl.Balance
+	}
+
+	return result, balances, nil
+}
+
+// invalidPathRequest returns the problem rendered when the params of a path
+// search are invalid.
+func invalidPathRequest(detail string) *problem.P {
+	return &problem.P{
+		Type:   "invalid_path_request",
+		Title:  "Invalid Path Request",
+		Status: http.StatusBadRequest,
+		Detail: detail,
+	}
+}
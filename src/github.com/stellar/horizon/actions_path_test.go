@@ -0,0 +1,78 @@
+package horizon
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/test"
+)
+
+func TestPathActions(t *testing.T) {
+	test.LoadScenario("paths")
+	app := NewTestApp()
+	defer app.Close()
+	rh := NewRequestHelper(app)
+
+	issuer := "GAVZL2RKWZNVYNB6Q6SMJL34FAJ24HQEVD67UHHMNH7C7HM4XWAG3OXQ"
+	eur := "destination_asset_type=credit_alphanum4&destination_asset_code=EUR&destination_asset_issuer=" + issuer
+
+	Convey("Path Actions:", t, func() {
+
+		Convey("GET /paths/strict-receive", func() {
+			w := rh.Get("/paths/strict-receive?"+eur+"&destination_amount=10&source_assets=USD:"+issuer, test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+
+			var result struct {
+				Embedded struct {
+					Records []PathResource
+				} `json:"_embedded"`
+			}
+			err := json.Unmarshal(w.Body.Bytes(), &result)
+			So(err, ShouldBeNil)
+			So(len(result.Embedded.Records), ShouldEqual, 1)
+
+			path := result.Embedded.Records[0]
+			So(path.SourceAssetCode, ShouldEqual, "USD")
+			So(path.SourceAmount, ShouldEqual, "10.0000000")
+			So(path.DestinationAssetCode, ShouldEqual, "EUR")
+			So(path.DestinationAmount, ShouldEqual, "10.0000000")
+			So(path.Path, ShouldBeEmpty)
+		})
+
+		Convey("GET /paths/strict-receive?source_account", func() {
+			w := rh.Get("/paths/strict-receive?"+eur+"&destination_amount=10&source_account=GBU347QDBQUWYDYNLXU7MU6EPMUIQP6XXT5JNR7UBQLK4BJAXMO34TLN", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Body, ShouldBePageOf, 1)
+
+			// more than the account holds
+			w = rh.Get("/paths/strict-receive?"+eur+"&destination_amount=10000&source_account=GBU347QDBQUWYDYNLXU7MU6EPMUIQP6XXT5JNR7UBQLK4BJAXMO34TLN", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Body, ShouldBePageOf, 0)
+		})
+
+		Convey("GET /paths/strict-send", func() {
+			w := rh.Get("/paths/strict-send?source_asset_type=credit_alphanum4&source_asset_code=USD&source_asset_issuer="+issuer+"&source_amount=10&destination_assets=EUR:"+issuer, test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Body, ShouldBePageOf, 1)
+		})
+
+		Convey("invalid requests", func() {
+			// missing sources
+			w := rh.Get("/paths/strict-receive?"+eur+"&destination_amount=10", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 400)
+
+			// longer than configured
+			w = rh.Get("/paths/strict-receive?"+eur+"&destination_amount=10&source_assets=native&max_path_length=4", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 400)
+
+			// bad amount
+			w = rh.Get("/paths/strict-receive?"+eur+"&destination_amount=-1&source_assets=native", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 400)
+
+			// bad exclusions
+			w = rh.Get("/paths/strict-send?source_asset_type=native&source_amount=10&destination_assets=native&exclude_assets=USD", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 400)
+		})
+	})
+}
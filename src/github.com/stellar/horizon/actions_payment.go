@@ -1,12 +1,44 @@
 package horizon
 
 import (
-	"github.com/stellar/horizon/actions"
 	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/render/csv"
 	"github.com/stellar/horizon/render/hal"
 	"github.com/stellar/horizon/render/sse"
 )
 
+// paymentColumns are the columns of payments rendered as csv.  Payments of
+// each type fill the columns of their own details, leaving the others empty.
+var paymentColumns = []string{
+	"id",
+	"paging_token",
+	"type",
+	"source_account",
+	"from",
+	"to",
+	"asset_type",
+	"asset_code",
+	"asset_issuer",
+	"amount",
+	"source_max",
+	"source_asset_type",
+	"source_asset_code",
+	"source_asset_issuer",
+	"funder",
+	"account",
+	"starting_balance",
+	"into",
+	"received_by",
+	"amount_received",
+	"asset_received_type",
+	"asset_received_code",
+	"asset_received_issuer",
+}
+
+// PaymentsIndexAction renders a page of payments: the operations that send
+// value from one account to another, being create_account, payment,
+// path_payment and account_merge.  Each carries, whatever its type, the
+// fields describing what its recipient received.
 type PaymentsIndexAction struct {
 	Action
 	Query   db.OperationPageQuery
@@ -16,15 +48,17 @@ type PaymentsIndexAction struct {
 
 // LoadQuery sets action.Query from the request params
 func (action *PaymentsIndexAction) LoadQuery() {
-	action.ValidateInt64(actions.ParamCursor)
+	action.ValidateCursor()
 	action.Query = db.OperationPageQuery{
 		SqlQuery:        action.App.HistoryQuery(),
 		PageQuery:       action.GetPageQuery(),
-		AccountAddress:  action.GetString("account_id"),
+		AccountAddress:  action.GetAccountID("account_id"),
 		LedgerSequence:  action.GetInt32("ledger_id"),
 		TransactionHash: action.GetString("tx_id"),
 		TypeFilter:      db.PaymentTypeFilter,
 	}
+	action.ValidateCursorWithinHistory(action.Query.PageQuery)
+	action.EnsureHistoryFreshness()
 }
 
 // LoadRecords populates action.Records
@@ -34,7 +68,7 @@ func (action *PaymentsIndexAction) LoadRecords() {
 		return
 	}
 
-	action.Err = db.Select(action.Ctx, action.Query, &action.Records)
+	action.Err = action.Select(action.Query, &action.Records)
 }
 
 // LoadPage populates action.Page
@@ -44,7 +78,7 @@ func (action *PaymentsIndexAction) LoadPage() {
 		return
 	}
 
-	action.Page, action.Err = NewOperationResourcePage(action.Records, action.Query.PageQuery, action.Path())
+	action.Page, action.Err = NewPaymentResourcePage(action.Ctx, action.Query.SqlQuery, action.Records, action.Query.PageQuery, action.Path())
 }
 
 // JSON is a method for actions.JSON
@@ -56,6 +90,23 @@ func (action *PaymentsIndexAction) JSON() {
 	hal.Render(action.W, action.Page)
 }
 
+// CSV is a method for actions.CSV
+func (action *PaymentsIndexAction) CSV() {
+	action.LoadPage()
+	if action.Err != nil {
+		return
+	}
+
+	encoded, err := action.EncodeCursors(action.Page.Records)
+	if err != nil {
+		action.Err = err
+		return
+	}
+
+	records, _ := encoded.([]interface{})
+	action.Err = csv.Render(action.W, paymentColumns, records)
+}
+
 // SSE is a method for actions.SSE
 func (action *PaymentsIndexAction) SSE(stream sse.Stream) {
 	action.LoadRecords()
@@ -66,18 +117,16 @@ func (action *PaymentsIndexAction) SSE(stream sse.Stream) {
 	}
 
 	records := action.Records[stream.SentCount():]
+	resources, err := NewPaymentResources(action.Ctx, action.Query.SqlQuery, records)
+	if err != nil {
+		stream.Err(err)
+		return
+	}
 
-	for _, record := range records {
-		r, err := NewOperationResource(record)
-
-		if err != nil {
-			stream.Err(action.Err)
-			return
-		}
-
+	for i, record := range records {
 		stream.Send(sse.Event{
 			ID:   record.PagingToken(),
-			Data: r,
+			Data: resources[i],
 		})
 	}
 
@@ -85,3 +134,38 @@ func (action *PaymentsIndexAction) SSE(stream sse.Stream) {
 		stream.Done()
 	}
 }
+
+// PaymentSummaryAction renders the totals of the payments an account received
+// and sent per asset, optionally within the close times given by the
+// start_time and end_time params, so that they need not be paged through.
+type PaymentSummaryAction struct {
+	Action
+	Query   db.PaymentSummaryQuery
+	Records []db.PaymentSummaryRecord
+}
+
+// LoadQuery sets action.Query from the request params
+func (action *PaymentSummaryAction) LoadQuery() {
+	action.Query = db.PaymentSummaryQuery{
+		SqlQuery:  action.App.HistoryQuery(),
+		Address:   action.GetAccountID("account_id"),
+		TimeRange: action.GetTimeRange(),
+	}
+}
+
+// LoadRecords populates action.Records
+func (action *PaymentSummaryAction) LoadRecords() {
+	action.LoadQuery()
+	if action.Err != nil {
+		return
+	}
+
+	action.Err = action.Select(action.Query, &action.Records)
+}
+
+// JSON is a method for actions.JSON
+func (action *PaymentSummaryAction) JSON() {
+	action.Do(action.LoadRecords, func() {
+		hal.Render(action.W, NewPaymentSummaryResource(action.Query.Address, action.Query.TimeRange, action.Records))
+	})
+}
@@ -1,6 +1,8 @@
 package horizon
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
 
 	. "github.com/smartystreets/goconvey/convey"
@@ -21,6 +23,36 @@ func TestPaymentActions(t *testing.T) {
 			So(w.Body, ShouldBePageOf, 4)
 		})
 
+		Convey("GET /payments?format=csv", func() {
+			w := rh.Get("/payments?format=csv", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Header().Get("Content-Type"), ShouldStartWith, "text/csv")
+
+			lines := strings.Split(strings.TrimSuffix(w.Body.String(), "\r\n"), "\r\n")
+			So(len(lines), ShouldEqual, 5)
+			So(lines[0], ShouldStartWith, "id,paging_token,type,source_account,from,to,")
+			So(lines[1], ShouldContainSubstring, ",create_account,")
+		})
+
+		Convey("GET /payments includes what each recipient received", func() {
+			w := rh.Get("/payments?limit=1", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+
+			var result struct {
+				Embedded struct {
+					Records []map[string]interface{}
+				} `json:"_embedded"`
+			}
+			err := json.Unmarshal(w.Body.Bytes(), &result)
+			So(err, ShouldBeNil)
+
+			payment := result.Embedded.Records[0]
+			So(payment["type"], ShouldEqual, "create_account")
+			So(payment["received_by"], ShouldEqual, payment["account"])
+			So(payment["amount_received"], ShouldEqual, payment["starting_balance"])
+			So(payment["asset_received_type"], ShouldEqual, "native")
+		})
+
 		Convey("GET /ledgers/:ledger_id/payments", func() {
 			w := rh.Get("/ledgers/1/payments", test.RequestHelperNoop)
 			So(w.Code, ShouldEqual, 200)
@@ -42,6 +74,31 @@ func TestPaymentActions(t *testing.T) {
 			So(w.Body, ShouldBePageOf, 3)
 		})
 
+		Convey("GET /accounts/:account_id/payments/summary", func() {
+			test.LoadScenario("base")
+
+			w := rh.Get("/accounts/GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU/payments/summary", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+
+			var result PaymentSummaryResource
+			err := json.Unmarshal(w.Body.Bytes(), &result)
+			So(err, ShouldBeNil)
+			So(len(result.Assets), ShouldEqual, 1)
+			So(result.Assets[0].Type, ShouldEqual, "native")
+			So(result.Assets[0].Received, ShouldEqual, "100.0000000")
+			So(result.Assets[0].Sent, ShouldEqual, "5.0000000")
+
+			w = rh.Get("/accounts/GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU/payments/summary?start_time=2015-10-07T23:07:28Z", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			err = json.Unmarshal(w.Body.Bytes(), &result)
+			So(err, ShouldBeNil)
+			So(result.Assets[0].Received, ShouldEqual, "0.0000000")
+			So(result.Assets[0].SentCount, ShouldEqual, 1)
+
+			w = rh.Get("/accounts/GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU/payments/summary?start_time=2015-10-08T00:00:00Z&end_time=2015-10-07T00:00:00Z", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 400)
+		})
+
 		Convey("GET /transactions/:tx_id/payments", func() {
 			test.LoadScenario("pathed_payment")
 
@@ -54,5 +111,25 @@ func TestPaymentActions(t *testing.T) {
 			So(w.Body, ShouldBePageOf, 1)
 		})
 
+		Convey("GET /payments includes account merges", func() {
+			test.LoadScenario("account_merge")
+
+			w := rh.Get("/payments?order=desc&limit=1", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+
+			var result struct {
+				Embedded struct {
+					Records []map[string]interface{}
+				} `json:"_embedded"`
+			}
+			err := json.Unmarshal(w.Body.Bytes(), &result)
+			So(err, ShouldBeNil)
+
+			merge := result.Embedded.Records[0]
+			So(merge["type"], ShouldEqual, "account_merge")
+			So(merge["received_by"], ShouldEqual, "GA5WBPYA5Y4WAEHXWR2UKO2UO4BUGHUQ74EUPKON2QHV4WRHOIRNKKH2")
+			So(merge["amount_received"], ShouldEqual, "999.99999")
+		})
+
 	})
 }
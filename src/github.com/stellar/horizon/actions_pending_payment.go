@@ -0,0 +1,88 @@
+package horizon
+
+import (
+	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/render/hal"
+	"github.com/stellar/horizon/render/sse"
+)
+
+// This file contains the actions:
+//
+// PendingPaymentsIndexAction: pages of payments awaiting a trustline
+
+// PendingPaymentsIndexAction renders a page of the payments to an account
+// that failed because the account did not trust the asset delivered, ordered
+// like operations.
+type PendingPaymentsIndexAction struct {
+	Action
+	Query   db.PendingPaymentPageQuery
+	Records []db.PendingPaymentRecord
+	Page    hal.Page
+}
+
+// LoadQuery sets action.Query from the request params
+func (action *PendingPaymentsIndexAction) LoadQuery() {
+	action.ValidateCursor()
+	action.Query = db.PendingPaymentPageQuery{
+		SqlQuery:  action.App.CoreQuery(),
+		PageQuery: action.GetPageQuery(),
+		Address:   action.GetAccountID("account_id"),
+		History:   action.App.HistoryQuery(),
+	}
+}
+
+// LoadRecords populates action.Records
+func (action *PendingPaymentsIndexAction) LoadRecords() {
+	action.LoadQuery()
+	if action.Err != nil {
+		return
+	}
+
+	action.Err = action.Select(action.Query, &action.Records)
+}
+
+// LoadPage populates action.Page
+func (action *PendingPaymentsIndexAction) LoadPage() {
+	action.LoadRecords()
+	if action.Err != nil {
+		return
+	}
+
+	action.Page, action.Err = NewPendingPaymentResourcePage(action.Records, action.Query)
+}
+
+// JSON is a method for actions.JSON
+func (action *PendingPaymentsIndexAction) JSON() {
+	action.LoadPage()
+	if action.Err != nil {
+		return
+	}
+
+	hal.Render(action.W, action.Page)
+}
+
+// SSE is a method for actions.SSE
+func (action *PendingPaymentsIndexAction) SSE(stream sse.Stream) {
+	action.LoadRecords()
+	if action.Err != nil {
+		stream.Err(action.Err)
+		return
+	}
+
+	for _, record := range action.Records[stream.SentCount():] {
+		r, err := NewPendingPaymentResource(record)
+		if err != nil {
+			stream.Err(err)
+			return
+		}
+
+		stream.Send(sse.Event{
+			ID:   record.PagingToken(),
+			Data: r,
+		})
+	}
+
+	if stream.SentCount() >= int(action.Query.Limit) {
+		stream.Done()
+	}
+}
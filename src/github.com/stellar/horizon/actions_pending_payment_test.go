@@ -0,0 +1,39 @@
+package horizon
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/test"
+)
+
+func TestPendingPaymentActions(t *testing.T) {
+	test.LoadScenario("non_native_payment")
+	app := NewTestApp()
+	defer app.Close()
+	rh := NewRequestHelper(app)
+
+	Convey("Pending Payment Actions:", t, func() {
+
+		Convey("GET /accounts/:account_id/pending_payments", func() {
+			// every payment of the scenario succeeded
+			w := rh.Get("/accounts/GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON/pending_payments", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Body, ShouldBePageOf, 0)
+
+			w = rh.Get("/accounts/GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON/pending_payments?order=desc", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Body, ShouldBePageOf, 0)
+
+			// an account history has never seen created has been paid nothing
+			w = rh.Get("/accounts/GA5WBPYA5Y4WAEHXWR2UKO2UO4BUGHUQ74EUPKON2QHV4WRHOIRNKKH2/pending_payments", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Body, ShouldBePageOf, 0)
+		})
+
+		Convey("GET /accounts/:account_id/pending_payments?cursor=bad", func() {
+			w := rh.Get("/accounts/GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON/pending_payments?cursor=bad", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 400)
+		})
+	})
+}
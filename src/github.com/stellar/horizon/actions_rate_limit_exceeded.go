@@ -8,10 +8,12 @@ import (
 	"github.com/stellar/horizon/render/problem"
 )
 
-// RateLimitExceededAction renders a 429 response
+// RateLimitExceededAction renders a 429 response, with Problem when it is set
+// and problem.RateLimitExceeded otherwise.
 type RateLimitExceededAction struct {
 	Action
-	App *App
+	App     *App
+	Problem *problem.P
 }
 
 // ServeHTTPC is a method for web.Handler
@@ -24,5 +26,10 @@ func (action RateLimitExceededAction) ServeHTTP(w http.ResponseWriter, r *http.R
 	}
 	ap.Prepare(c, w, r)
 	ap.App = action.App
+	if action.Problem != nil {
+		problem.Render(action.Ctx, action.W, *action.Problem)
+		return
+	}
+
 	problem.Render(action.Ctx, action.W, problem.RateLimitExceeded)
 }
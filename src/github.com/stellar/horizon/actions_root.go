@@ -2,14 +2,25 @@ package horizon
 
 import (
 	"github.com/jagregory/halgo"
+	"github.com/stellar/horizon/db"
 	"github.com/stellar/horizon/render/hal"
 )
 
-// RootResource is the initial map of links into the api.
+// RootResource is the initial map of links into the api, along with what
+// clients need to check they are talking to the network they expect: its
+// passphrase, the versions of the software serving it, the ledgers this server
+// holds and the network parameters in force as of the latest ledger.
 type RootResource struct {
 	halgo.Links
-	HorizonVersion     string `json:"horizon_version"`
-	StellarCoreVersion string `json:"core_version"`
+	HorizonVersion         string `json:"horizon_version"`
+	StellarCoreVersion     string `json:"core_version"`
+	NetworkPassphrase      string `json:"network_passphrase"`
+	HistoryLatestLedger    int32  `json:"history_latest_ledger"`
+	HistoryElderLedger     int32  `json:"history_elder_ledger"`
+	CoreLatestLedger       int32  `json:"core_latest_ledger"`
+	CurrentProtocolVersion int32  `json:"current_protocol_version"`
+	BaseFee                int32  `json:"base_fee"`
+	BaseReserve            int32  `json:"base_reserve"`
 }
 
 type RootAction struct {
@@ -17,9 +28,29 @@ type RootAction struct {
 }
 
 func (action *RootAction) JSON() {
+	ls, err := action.App.LedgerState(action.Ctx)
+	if err != nil {
+		action.Err = err
+		return
+	}
+
+	var headers []db.CoreLedgerHeaderRecord
+	err = db.Select(action.Ctx, db.CoreLedgerHeadersBySequenceQuery{
+		SqlQuery:  action.App.CoreQuery(),
+		Sequences: []int32{ls.StellarCoreSequence},
+	}, &headers)
+	if err != nil {
+		action.Err = err
+		return
+	}
+
 	var response = RootResource{
-		HorizonVersion:     action.App.horizonVersion,
-		StellarCoreVersion: action.App.coreVersion,
+		HorizonVersion:      action.App.horizonVersion,
+		StellarCoreVersion:  action.App.coreVersion,
+		NetworkPassphrase:   action.App.networkPassphrase,
+		HistoryLatestLedger: ls.HorizonSequence,
+		HistoryElderLedger:  ls.HorizonElderSequence,
+		CoreLatestLedger:    ls.StellarCoreSequence,
 		Links: halgo.Links{}.
 			Self("/").
 			Link("account", "/accounts/{address}").
@@ -27,8 +58,25 @@ func (action *RootAction) JSON() {
 			Link("transaction", "/transactions/{hash}").
 			Link("transactions", "/transactions{?cursor,limit,order}").
 			Link("order_book", "/order_book{?selling_asset_type,selling_asset_code,selling_issuer,buying_asset_type,buying_asset_code,buying_issuer}").
-			Link("metrics", "/metrics").
 			Link("friendbot", "/friendbot{?addr}"),
 	}
+
+	// metrics are served by the admin server, when there is one
+	if action.App.config.AdminAddr == "" {
+		response.Links = response.Links.Link("metrics", "/metrics")
+	}
+
+	if len(headers) > 0 {
+		header, err := headers[0].Header()
+		if err != nil {
+			action.Err = err
+			return
+		}
+
+		response.CurrentProtocolVersion = int32(header.LedgerVersion)
+		response.BaseFee = int32(header.BaseFee)
+		response.BaseReserve = int32(header.BaseReserve)
+	}
+
 	hal.Render(action.W, response)
 }
@@ -3,6 +3,7 @@ package horizon
 import (
 	"encoding/json"
 	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/db"
 	"github.com/stellar/horizon/test"
 	"testing"
 )
@@ -14,6 +15,7 @@ func TestRootAction(t *testing.T) {
 		app := NewTestApp()
 		app.coreVersion = "test-core"
 		app.horizonVersion = "test-horizon"
+		app.networkPassphrase = "test-network"
 
 		defer app.Close()
 		rh := NewRequestHelper(app)
@@ -28,6 +30,24 @@ func TestRootAction(t *testing.T) {
 
 		So(result.HorizonVersion, ShouldEqual, "test-horizon")
 		So(result.StellarCoreVersion, ShouldEqual, "test-core")
-
+		So(result.NetworkPassphrase, ShouldEqual, "test-network")
+		So(result.HistoryLatestLedger, ShouldEqual, 3)
+		So(result.HistoryElderLedger, ShouldEqual, 1)
+		So(result.CoreLatestLedger, ShouldEqual, 3)
+		So(result.CurrentProtocolVersion, ShouldEqual, 1)
+		So(result.BaseFee, ShouldEqual, 100)
+		So(result.BaseReserve, ShouldEqual, 100000000)
+
+		Convey("advertises the oldest ledger left once history is reaped", func() {
+			So(db.DeleteLedgerRange(app.historyDb, 1, 1), ShouldBeNil)
+
+			w := rh.Get("/", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+
+			var result RootResource
+			err := json.Unmarshal(w.Body.Bytes(), &result)
+			So(err, ShouldBeNil)
+			So(result.HistoryElderLedger, ShouldEqual, 2)
+		})
 	})
 }
@@ -0,0 +1,65 @@
+package horizon
+
+import (
+	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/render/hal"
+)
+
+// This file contains the actions:
+//
+// SignerAccountsIndexAction: pages of the accounts a key signs for
+
+// SignerAccountsIndexAction renders a page of the accounts the key given as
+// signer_id is a signer of, ordered by address.  They are those of the
+// signers that ingestion keeps current, as of the latest ledger ingested, and
+// unlike /accounts?signer= they leave out the account whose master key it is.
+type SignerAccountsIndexAction struct {
+	Action
+	Query   db.SignerAccountsPageQuery
+	Records []db.SignerRecord
+	Page    hal.Page
+}
+
+// LoadQuery sets action.Query from the request params
+func (action *SignerAccountsIndexAction) LoadQuery() {
+	signer := action.GetAccountID("signer_id")
+	if action.Err != nil {
+		return
+	}
+
+	action.Query = db.SignerAccountsPageQuery{
+		SqlQuery:  action.App.HistoryQuery(),
+		PageQuery: action.GetPageQuery(),
+		Signer:    signer,
+	}
+}
+
+// LoadRecords populates action.Records
+func (action *SignerAccountsIndexAction) LoadRecords() {
+	action.LoadQuery()
+	if action.Err != nil {
+		return
+	}
+
+	action.Err = action.Select(action.Query, &action.Records)
+}
+
+// LoadPage populates action.Page
+func (action *SignerAccountsIndexAction) LoadPage() {
+	action.LoadRecords()
+	if action.Err != nil {
+		return
+	}
+
+	action.Page, action.Err = NewSignerAccountResourcePage(action.Records, action.Query)
+}
+
+// JSON is a method for actions.JSON
+func (action *SignerAccountsIndexAction) JSON() {
+	action.LoadPage()
+	if action.Err != nil {
+		return
+	}
+
+	hal.Render(action.W, action.Page)
+}
@@ -0,0 +1,57 @@
+package horizon
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/test"
+)
+
+func TestSignerActions(t *testing.T) {
+	test.LoadScenario("base")
+	app := NewTestApp()
+	defer app.Close()
+	rh := NewRequestHelper(app)
+
+	Convey("Signer Actions:", t, func() {
+		Convey("GET /signers/:signer_id/accounts", func() {
+			const signer = "GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4"
+			Reset(func() { app.historyDb.MustExec("DELETE FROM current_signers") })
+
+			for i, account := range []string{
+				"GA5WBPYA5Y4WAEHXWR2UKO2UO4BUGHUQ74EUPKON2QHV4WRHOIRNKKH2",
+				"GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU",
+			} {
+				app.historyDb.MustExec(`
+					INSERT INTO current_signers (accountid, publickey, weight, lastmodified)
+					VALUES ($1, $2, $3, 3)`,
+					account, signer, i+1)
+			}
+
+			w := rh.Get("/signers/"+signer+"/accounts?order=desc", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+
+			var result struct {
+				Embedded struct {
+					Records []SignerAccountResource
+				} `json:"_embedded"`
+			}
+			err := json.Unmarshal(w.Body.Bytes(), &result)
+			So(err, ShouldBeNil)
+			So(len(result.Embedded.Records), ShouldEqual, 2)
+
+			account := result.Embedded.Records[0]
+			So(account.Account, ShouldEqual, "GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU")
+			So(account.Signer, ShouldEqual, signer)
+			So(account.Weight, ShouldEqual, 2)
+
+			w = rh.Get("/signers/"+signer+"/accounts?order=desc&cursor="+account.PagingToken, test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Body, ShouldBePageOf, 1)
+
+			w = rh.Get("/signers/notakey/accounts", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 400)
+		})
+	})
+}
@@ -0,0 +1,303 @@
+package horizon
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/stellar/horizon/actions"
+	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/render/problem"
+	"github.com/stellar/horizon/render/sse"
+)
+
+// This file contains the actions:
+//
+// StreamAction: multiple streams of resources over a single connection
+
+// StreamAction streams several collections of resources, called topics, to a
+// client over a single connection.  Topics are provided as a comma separated
+// list in the `topics` param and name a collection using its path, e.g.
+// `ledgers` or `accounts/GABC.../payments`.  Every event sent is named after
+// the topic it belongs to.
+//
+// The id of each event encodes the cursor of every topic, allowing a
+// reconnecting client to resume all of its topics from its last event id.
+type StreamAction struct {
+	Action
+	PageQuery db.PageQuery
+	Topics    []*StreamTopic
+}
+
+// StreamTopic is a single collection streamed by a StreamAction.
+type StreamTopic struct {
+	Name   string
+	Cursor string
+	Load   func(db.PageQuery) ([]sse.Event, error)
+}
+
+// SSE is a method for actions.SSE
+func (action *StreamAction) SSE(stream sse.Stream) {
+	if action.Topics == nil {
+		action.Do(action.LoadPageQuery, action.LoadTopics)
+	}
+
+	if action.Err != nil {
+		stream.Err(action.Err)
+		return
+	}
+
+	for _, topic := range action.Topics {
+		query := action.PageQuery
+		query.Cursor = topic.Cursor
+
+		events, err := topic.Load(query)
+		if err != nil {
+			stream.Err(err)
+			return
+		}
+
+		for _, e := range events {
+			topic.Cursor = e.ID
+			e.ID = encodeStreamCursors(action.Topics)
+			e.Event = topic.Name
+			stream.Send(e)
+		}
+	}
+}
+
+// LoadPageQuery sets action.PageQuery from the request params.  Streams are
+// always delivered in ascending order, and the cursor param provides the
+// starting cursor of every topic.
+func (action *StreamAction) LoadPageQuery() {
+	cursor := action.DecodeCursor(action.GetString(actions.ParamCursor))
+	limit := action.GetLimit(db.DefaultPageSize, db.MaxPageSize)
+	if action.Err != nil {
+		return
+	}
+
+	action.PageQuery, action.Err = db.NewPageQuery(cursor, db.OrderAscending, limit)
+}
+
+// LoadTopics populates action.Topics from the topics param, restoring each
+// topic's cursor from the client's last event id.
+func (action *StreamAction) LoadTopics() {
+	names := strings.Split(action.GetString("topics"), ",")
+	cursors := decodeStreamCursors(action.DecodeCursor(sse.LastEventID(action.R)))
+	if action.Err != nil {
+		return
+	}
+
+	seen := map[string]bool{}
+
+	for _, name := range names {
+		name = strings.Trim(strings.TrimSpace(name), "/")
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		topic, err := action.newTopic(name)
+		if err != nil {
+			action.Err = err
+			return
+		}
+
+		topic.Cursor = action.PageQuery.Cursor
+		if cursor, ok := cursors[name]; ok {
+			topic.Cursor = cursor
+		}
+
+		action.Topics = append(action.Topics, topic)
+	}
+
+	if len(action.Topics) == 0 {
+		action.Err = invalidStreamTopics("The topics param must name at least one topic to stream.")
+	}
+}
+
+// newTopic returns the topic identified by name, or a problem if name does not
+// identify a streamable collection.
+func (action *StreamAction) newTopic(name string) (*StreamTopic, error) {
+	var address, collection string
+
+	parts := strings.Split(name, "/")
+	switch {
+	case len(parts) == 1:
+		collection = parts[0]
+	case len(parts) == 3 && parts[0] == "accounts":
+		address, collection = parts[1], parts[2]
+	}
+
+	load := action.collectionLoader(collection, address)
+	if load == nil {
+		return nil, invalidStreamTopics("Unknown topic: " + name + ".")
+	}
+
+	return &StreamTopic{Name: name, Load: load}, nil
+}
+
+// collectionLoader returns the func that loads the next page of events for the
+// provided collection, optionally scoped to an account.  Returns nil for
+// unknown collections.
+func (action *Action) collectionLoader(collection, address string) func(db.PageQuery) ([]sse.Event, error) {
+	ctx := action.Ctx
+	hq := action.App.HistoryQuery()
+
+	switch collection {
+	case "ledgers":
+		if address != "" {
+			return nil
+		}
+
+		return func(pq db.PageQuery) ([]sse.Event, error) {
+			var records []db.LedgerRecord
+			err := db.Select(ctx, db.LedgerPageQuery{SqlQuery: hq, PageQuery: pq}, &records)
+			if err != nil {
+				return nil, err
+			}
+
+			events := make([]sse.Event, len(records))
+			for i, record := range records {
+				events[i] = sse.Event{ID: record.PagingToken(), Data: NewLedgerResource(record)}
+			}
+			return events, nil
+		}
+	case "transactions":
+		return func(pq db.PageQuery) ([]sse.Event, error) {
+			var records []db.TransactionRecord
+			query := db.TransactionPageQuery{
+				SqlQuery:       hq,
+				PageQuery:      pq,
+				AccountAddress: address,
+			}
+
+			err := db.Select(ctx, query, &records)
+			if err != nil {
+				return nil, err
+			}
+
+			events := make([]sse.Event, len(records))
+			for i, record := range records {
+				events[i] = sse.Event{ID: record.PagingToken(), Data: NewTransactionResource(record)}
+			}
+			return events, nil
+		}
+	case "operations", "payments":
+		var typeFilter string
+		if collection == "payments" {
+			typeFilter = db.PaymentTypeFilter
+		}
+
+		return func(pq db.PageQuery) ([]sse.Event, error) {
+			var records []db.OperationRecord
+			query := db.OperationPageQuery{
+				SqlQuery:       hq,
+				PageQuery:      pq,
+				AccountAddress: address,
+				TypeFilter:     typeFilter,
+			}
+
+			err := db.Select(ctx, query, &records)
+			if err != nil {
+				return nil, err
+			}
+
+			events := make([]sse.Event, len(records))
+			if collection == "payments" {
+				payments, err := NewPaymentResources(ctx, hq, records)
+				if err != nil {
+					return nil, err
+				}
+
+				for i, record := range records {
+					events[i] = sse.Event{ID: record.PagingToken(), Data: payments[i]}
+				}
+				return events, nil
+			}
+
+			for i, record := range records {
+				r, err := NewOperationResource(record)
+				if err != nil {
+					return nil, err
+				}
+				events[i] = sse.Event{ID: record.PagingToken(), Data: r}
+			}
+			return events, nil
+		}
+	case "effects":
+		return func(pq db.PageQuery) ([]sse.Event, error) {
+			var records []db.EffectRecord
+			query := db.EffectPageQuery{SqlQuery: hq, PageQuery: pq}
+			if address != "" {
+				query.Filter = &db.EffectAccountFilter{SqlQuery: hq, AccountAddress: address}
+			}
+
+			err := db.Select(ctx, query, &records)
+			if err != nil {
+				return nil, err
+			}
+
+			events := make([]sse.Event, len(records))
+			for i, record := range records {
+				r, err := NewEffectResource(record)
+				if err != nil {
+					return nil, err
+				}
+				events[i] = sse.Event{ID: record.PagingToken(), Data: r}
+			}
+			return events, nil
+		}
+	default:
+		return nil
+	}
+}
+
+// invalidStreamTopics returns the problem rendered when the topics param is
+// invalid, with the provided detail prepended to its explanation.
+func invalidStreamTopics(detail string) *problem.P {
+	return &problem.P{
+		Type:   "invalid_stream_topics",
+		Title:  "Invalid Stream Topics",
+		Status: http.StatusBadRequest,
+		Detail: detail + "  Topics are provided as a comma separated list of " +
+			"collection paths, e.g. ledgers,accounts/{account_id}/payments.  Valid " +
+			"collections are ledgers, transactions, operations, payments and effects, " +
+			"all but ledgers of which may be scoped to an account.",
+	}
+}
+
+// encodeStreamCursors encodes the current cursor of each of the provided
+// topics into a single event id of the form `topic=cursor,topic=cursor`.
+// Topics without a cursor are omitted.
+func encodeStreamCursors(topics []*StreamTopic) string {
+	parts := make([]string, 0, len(topics))
+
+	for _, topic := range topics {
+		if topic.Cursor == "" {
+			continue
+		}
+		parts = append(parts, topic.Name+"="+topic.Cursor)
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// decodeStreamCursors is the inverse of encodeStreamCursors, returning a map of
+// topic name to cursor.  Malformed entries are ignored.
+func decodeStreamCursors(id string) map[string]string {
+	result := map[string]string{}
+
+	if id == "" {
+		return result
+	}
+
+	for _, part := range strings.Split(id, ",") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			continue
+		}
+		result[kv[0]] = kv[1]
+	}
+
+	return result
+}
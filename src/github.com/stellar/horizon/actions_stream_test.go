@@ -0,0 +1,47 @@
+package horizon
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/test"
+)
+
+func TestStreamActions(t *testing.T) {
+	test.LoadScenario("base")
+	app := NewTestApp()
+	defer app.Close()
+	rh := NewRequestHelper(app)
+
+	Convey("Stream Actions:", t, func() {
+
+		Convey("GET /stream without topics", func() {
+			w := rh.Get("/stream", test.RequestHelperStreaming)
+			So(w.Body.String(), ShouldContainSubstring, "event: err\n")
+			So(w.Body.String(), ShouldContainSubstring, "at least one topic")
+		})
+
+		Convey("GET /stream with an unknown topic", func() {
+			w := rh.Get("/stream?topics=ledgers,accounts/foo/ledgers", test.RequestHelperStreaming)
+			So(w.Body.String(), ShouldContainSubstring, "event: err\n")
+			So(w.Body.String(), ShouldContainSubstring, "Unknown topic: accounts/foo/ledgers")
+		})
+
+		Convey("stream cursors round trip", func() {
+			topics := []*StreamTopic{
+				{Name: "ledgers", Cursor: "8589934592"},
+				{Name: "transactions"},
+				{Name: "accounts/GABC/effects", Cursor: "12884905985-1"},
+			}
+
+			id := encodeStreamCursors(topics)
+			So(id, ShouldEqual, "ledgers=8589934592,accounts/GABC/effects=12884905985-1")
+			So(decodeStreamCursors(id), ShouldResemble, map[string]string{
+				"ledgers":               "8589934592",
+				"accounts/GABC/effects": "12884905985-1",
+			})
+			So(decodeStreamCursors(""), ShouldBeEmpty)
+			So(decodeStreamCursors("garbage,=1"), ShouldBeEmpty)
+		})
+	})
+}
@@ -1,14 +1,38 @@
 package horizon
 
 import (
+	"net/http"
+
+	"github.com/stellar/go-stellar-base/xdr"
 	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/render/csv"
 	"github.com/stellar/horizon/render/hal"
+	"github.com/stellar/horizon/render/problem"
 	_ "github.com/stellar/horizon/render/sse"
 )
 
+// tradeColumns are the columns of trades rendered as csv.
+var tradeColumns = []string{
+	"id",
+	"paging_token",
+	"seller",
+	"sold_asset_type",
+	"sold_asset_code",
+	"sold_asset_issuer",
+	"buyer",
+	"bought_asset_type",
+	"bought_asset_code",
+	"bought_asset_issuer",
+	"offer_id",
+	"sold_amount",
+	"bought_amount",
+	"price",
+	"counterparty",
+}
+
 // TradeIndexAction renders a page of effect resources, filtered to include
-// only trades, identified by a normal page query and optionally filtered by an account
-// or order book
+// only trades, identified by a normal page query and optionally filtered by an
+// account, offer, order book or asset pair
 type TradeIndexAction struct {
 	Action
 	Query   db.EffectPageQuery
@@ -28,20 +52,43 @@ func (action *TradeIndexAction) JSON() {
 	)
 }
 
-// LoadQuery sets action.Query from the request params
+// CSV is a method for actions.CSV
+func (action *TradeIndexAction) CSV() {
+	action.Do(
+		action.LoadQuery,
+		action.LoadRecords,
+		action.LoadPage,
+		func() {
+			encoded, err := action.EncodeCursors(action.Page.Records)
+			if err != nil {
+				action.Err = err
+				return
+			}
+
+			records, _ := encoded.([]interface{})
+			action.Err = csv.Render(action.W, tradeColumns, records)
+		},
+	)
+}
+
+// LoadQuery sets action.Query from the request params.  Trades may be
+// restricted to those of an account, those against an offer (offer_id), those
+// of an order book (selling_asset_* and buying_asset_*) and those between a
+// pair of assets in either direction (base_asset_* and counter_asset_*).
 func (action *TradeIndexAction) LoadQuery() {
 	action.Query = db.EffectPageQuery{
 		SqlQuery:  action.App.HistoryQuery(),
 		PageQuery: action.GetPageQuery(),
-		Filter:    &db.EffectTypeFilter{db.EffectTrade},
 	}
 
-	if address := action.GetString("account_id"); address != "" {
-		action.Query.Filter = db.FilterAll(
-			action.Query.Filter,
-			&db.EffectAccountFilter{action.Query.SqlQuery, address},
-		)
-		return
+	filters := []db.SQLFilter{&db.EffectTypeFilter{db.EffectTrade}}
+
+	if address := action.GetAccountID("account_id"); address != "" {
+		filters = append(filters, &db.EffectAccountFilter{action.Query.SqlQuery, address})
+	}
+
+	if offerID := action.GetInt64("offer_id"); offerID != 0 {
+		filters = append(filters, &db.EffectOfferFilter{OfferID: offerID})
 	}
 
 	// HACK: see if it looks like we're specifying an order book on params
@@ -49,24 +96,66 @@ func (action *TradeIndexAction) LoadQuery() {
 	if action.GetString("selling_asset_type") != "" {
 		params := action.GetOrderBook()
 
-		action.Query.Filter = db.FilterAll(
-			action.Query.Filter,
-			&db.EffectOrderBookFilter{
-				SellingType:   params.SellingType,
-				SellingCode:   params.SellingCode,
-				SellingIssuer: params.SellingIssuer,
-				BuyingType:    params.BuyingType,
-				BuyingCode:    params.BuyingCode,
-				BuyingIssuer:  params.BuyingIssuer,
-			},
-		)
+		filters = append(filters, &db.EffectOrderBookFilter{
+			SellingType:   params.SellingType,
+			SellingCode:   params.SellingCode,
+			SellingIssuer: params.SellingIssuer,
+			BuyingType:    params.BuyingType,
+			BuyingCode:    params.BuyingCode,
+			BuyingIssuer:  params.BuyingIssuer,
+		})
+	}
+
+	if action.GetString("base_asset_type") != "" || action.GetString("counter_asset_type") != "" {
+		filters = append(filters, action.GetAssetPairFilter())
+	}
+
+	action.Query.Filter = db.FilterAll(filters...)
+}
+
+// GetAssetPairFilter returns a filter for the trades between the assets given
+// by the base_asset_* and counter_asset_* params.
+func (action *TradeIndexAction) GetAssetPairFilter() *db.EffectAssetPairFilter {
+	if action.Err != nil {
+		return nil
+	}
+
+	result := &db.EffectAssetPairFilter{
+		BaseType:      action.GetAssetType("base_asset_type"),
+		BaseCode:      action.GetString("base_asset_code"),
+		BaseIssuer:    action.GetString("base_asset_issuer"),
+		CounterType:   action.GetAssetType("counter_asset_type"),
+		CounterCode:   action.GetString("counter_asset_code"),
+		CounterIssuer: action.GetString("counter_asset_issuer"),
+	}
+
+	valid := action.Err == nil &&
+		(result.BaseType == xdr.AssetTypeAssetTypeNative || result.BaseCode != "" && result.BaseIssuer != "") &&
+		(result.CounterType == xdr.AssetTypeAssetTypeNative || result.CounterCode != "" && result.CounterIssuer != "")
+
+	if !valid {
+		action.Err = &problem.P{
+			Type:   "invalid_asset_pair",
+			Title:  "Invalid Asset Pair Parameters",
+			Status: http.StatusBadRequest,
+			Detail: "The parameters that specify the pair of assets to view trades " +
+				"between are invalid in some way.  Please ensure that both " +
+				"base_asset_type and counter_asset_type are one of the following " +
+				"valid values: native, credit_alphanum4, credit_alphanum12.  Also " +
+				"ensure that you have specified base_asset_code and " +
+				"base_asset_issuer if base_asset_type is not 'native', as well as " +
+				"counter_asset_code and counter_asset_issuer if counter_asset_type " +
+				"is not 'native'",
+		}
+		return nil
 	}
 
+	return result
 }
 
 // LoadRecords populates action.Records
 func (action *TradeIndexAction) LoadRecords() {
-	action.Err = db.Select(action.Ctx, action.Query, &action.Records)
+	action.Err = action.Select(action.Query, &action.Records)
 }
 
 // LoadPage populates action.Page
@@ -1,6 +1,8 @@
 package horizon
 
 import (
+	"encoding/json"
+
 	. "github.com/smartystreets/goconvey/convey"
 	"github.com/stellar/horizon/test"
 	"testing"
@@ -33,5 +35,47 @@ func TestTradeActions(t *testing.T) {
 			So(w.Code, ShouldEqual, 200)
 			So(w.Body, ShouldBePageOf, 1)
 		})
+
+		Convey("GET /trades", func() {
+			w := rh.Get("/trades", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Body, ShouldBePageOf, 2)
+
+			w = rh.Get("/trades?offer_id=1", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Body, ShouldBePageOf, 2)
+
+			w = rh.Get("/trades?offer_id=2", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Body, ShouldBePageOf, 0)
+		})
+
+		Convey("GET /trades by asset pair", func() {
+			url := "/trades?" +
+				"base_asset_type=credit_alphanum4&" +
+				"base_asset_code=USD&" +
+				"base_asset_issuer=GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4&" +
+				"counter_asset_type=credit_alphanum4&" +
+				"counter_asset_code=EUR&" +
+				"counter_asset_issuer=GCQPYGH4K57XBDENKKX55KDTWOTK5WDWRQOH2LHEDX3EKVIQRLMESGBG"
+
+			w := rh.Get(url, test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Body, ShouldBePageOf, 2)
+
+			var result struct {
+				Embedded struct {
+					Records []TradeResource
+				} `json:"_embedded"`
+			}
+			err := json.Unmarshal(w.Body.Bytes(), &result)
+			So(err, ShouldBeNil)
+			So(result.Embedded.Records[0].Price, ShouldEqual, "1.0000000")
+			So(result.Embedded.Records[0].Counterparty, ShouldEqual, result.Embedded.Records[0].Seller)
+
+			// the counter asset is required
+			w = rh.Get("/trades?base_asset_type=native", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 400)
+		})
 	})
 }
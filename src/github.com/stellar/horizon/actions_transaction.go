@@ -1,36 +1,53 @@
 package horizon
 
 import (
-	"github.com/stellar/horizon/actions"
+	"net/http"
+
 	"github.com/stellar/horizon/db"
 	"github.com/stellar/horizon/render/hal"
+	"github.com/stellar/horizon/render/ndjson"
 	"github.com/stellar/horizon/render/problem"
 	"github.com/stellar/horizon/render/sse"
+	"github.com/stellar/horizon/render/xdr"
+	"github.com/stellar/horizon/simulate"
+	"github.com/stellar/horizon/txsub"
+	"golang.org/x/net/context"
 )
 
 // This file contains the actions:
 //
 // TransactionIndexAction: pages of transactions
 // TransactionShowAction: single transaction by sequence, by hash or id
+// TransactionCreateAction: submits a transaction
+// TransactionCreateAsyncAction: submits a transaction without awaiting its result
+// TransactionStatusAction: status of a transaction submitted asynchronously
+// TransactionSimulateAction: predicts the outcome of submitting a transaction
 
 // TransactionIndexAction renders a page of ledger resources, identified by
 // a normal page query.
 type TransactionIndexAction struct {
 	Action
 	Query   db.TransactionPageQuery
+	WithXDR bool
 	Records []db.TransactionRecord
 	Page    hal.Page
 }
 
 // LoadQuery sets action.Query from the request params
 func (action *TransactionIndexAction) LoadQuery() {
-	action.ValidateInt64(actions.ParamCursor)
+	action.ValidateCursor()
 	action.Query = db.TransactionPageQuery{
 		SqlQuery:       action.App.HistoryQuery(),
 		PageQuery:      action.GetPageQuery(),
-		AccountAddress: action.GetString("account_id"),
+		AccountAddress: action.GetAccountID("account_id"),
 		LedgerSequence: action.GetInt32("ledger_id"),
+		TimeRange:      action.GetTimeRange(),
+		IncludeFailed:  action.IncludeFailed(),
+		Core:           action.App.CoreQuery(),
 	}
+	action.ValidateCursorWithinHistory(action.Query.PageQuery)
+	action.EnsureHistoryFreshness()
+	action.WithXDR = action.IncludeXDR()
 }
 
 // LoadRecords populates action.Records
@@ -40,7 +57,7 @@ func (action *TransactionIndexAction) LoadRecords() {
 		return
 	}
 
-	action.Err = db.Select(action.Ctx, action.Query, &action.Records)
+	action.Err = action.Select(action.Query, &action.Records)
 }
 
 // LoadPage populates action.Page
@@ -50,7 +67,25 @@ func (action *TransactionIndexAction) LoadPage() {
 		return
 	}
 
-	action.Page, action.Err = NewTransactionResourcePage(action.Records, action.Query.PageQuery, action.Path())
+	action.Page, action.Err = NewTransactionResourcePage(action.Records, action.Query, action.WithXDR, action.Path())
+	if action.Err != nil || action.WithXDR {
+		return
+	}
+
+	for i, r := range action.Page.Records {
+		action.Page.Records[i] = r.(TransactionResource).WithoutXDR()
+	}
+}
+
+// resource returns the resource for record, less its xdr fields unless the
+// client asked for them.
+func (action *TransactionIndexAction) resource(record db.TransactionRecord) TransactionResource {
+	r := NewTransactionResource(record)
+	if !action.WithXDR {
+		r = r.WithoutXDR()
+	}
+
+	return r
 }
 
 // JSON is a method for actions.JSON
@@ -76,7 +111,7 @@ func (action *TransactionIndexAction) SSE(stream sse.Stream) {
 	for _, record := range records {
 		stream.Send(sse.Event{
 			ID:   record.PagingToken(),
-			Data: NewTransactionResource(record),
+			Data: action.resource(record),
 		})
 	}
 
@@ -85,10 +120,41 @@ func (action *TransactionIndexAction) SSE(stream sse.Stream) {
 	}
 }
 
-// TransactionShowAction renders a ledger found by its sequence number.
+// NDJSON is a method for actions.NDJSON
+func (action *TransactionIndexAction) NDJSON(export *ndjson.Stream) {
+	action.LoadQuery()
+	action.Query.Limit = db.MaxPageSize
+
+	for action.Err == nil && export.Continue() {
+		var records []db.TransactionRecord
+		action.Err = action.Select(action.Query, &records)
+		if action.Err != nil || len(records) == 0 {
+			return
+		}
+
+		for _, record := range records {
+			export.Write(action.resource(record))
+		}
+
+		action.Query.Cursor = records[len(records)-1].PagingToken()
+	}
+}
+
+// TransactionShowAction renders a ledger found by its sequence number, along
+// with the related collections named by the embed param.
 type TransactionShowAction struct {
 	Action
-	Record db.TransactionRecord
+	Record     db.TransactionRecord
+	Embeds     []string
+	Operations []db.OperationRecord
+	Effects    []db.EffectRecord
+}
+
+// embeddedTransactionResource is a transaction rendered with its related
+// collections.
+type embeddedTransactionResource struct {
+	TransactionResource
+	Embedded map[string][]interface{} `json:"_embedded"`
 }
 
 // Query returns a database query to find a ledger by sequence
@@ -99,33 +165,159 @@ func (action *TransactionShowAction) Query() db.TransactionByHashQuery {
 	}
 }
 
+// LoadRecord populates action.Record
+func (action *TransactionShowAction) LoadRecord() {
+	query := action.Query()
+
+	if action.Err != nil {
+		return
+	}
+
+	action.Err = db.Get(action.Ctx, action.Immutable(query), &action.Record)
+}
+
+// LoadEmbeds sets action.Embeds from the embed param
+func (action *TransactionShowAction) LoadEmbeds() {
+	action.Embeds = action.GetEmbeds("operations", "effects")
+}
+
+// LoadEmbedded populates the related collections named by action.Embeds.  Each
+// holds, at most, the first db.MaxPageSize records of the collection.
+func (action *TransactionShowAction) LoadEmbedded() {
+	page := db.MustPageQuery("", db.OrderAscending, db.MaxPageSize)
+
+	for _, name := range action.Embeds {
+		switch name {
+		case "operations":
+			action.Err = action.Select(db.OperationPageQuery{
+				SqlQuery:        action.App.HistoryQuery(),
+				PageQuery:       page,
+				TransactionHash: action.Record.TransactionHash,
+			}, &action.Operations)
+		case "effects":
+			action.Err = action.Select(db.EffectPageQuery{
+				SqlQuery:  action.App.HistoryQuery(),
+				PageQuery: page,
+				Filter: &db.EffectTransactionFilter{
+					SqlQuery:        action.App.HistoryQuery(),
+					TransactionHash: action.Record.TransactionHash,
+				},
+			}, &action.Effects)
+		}
+
+		if action.Err != nil {
+			return
+		}
+	}
+}
+
+// Resource returns the resource to render for the loaded transaction.
+func (action *TransactionShowAction) Resource(withXDR bool) (interface{}, error) {
+	resource := NewTransactionResource(action.Record)
+	if !withXDR {
+		resource = resource.WithoutXDR()
+	}
+
+	if len(action.Embeds) == 0 {
+		return resource, nil
+	}
+
+	result := embeddedTransactionResource{
+		TransactionResource: resource,
+		Embedded:            map[string][]interface{}{},
+	}
+
+	for _, name := range action.Embeds {
+		records := []interface{}{}
+
+		switch name {
+		case "operations":
+			for _, record := range action.Operations {
+				r, err := NewOperationResource(record)
+				if err != nil {
+					return nil, err
+				}
+				records = append(records, r)
+			}
+		case "effects":
+			for _, record := range action.Effects {
+				r, err := NewEffectResource(record)
+				if err != nil {
+					return nil, err
+				}
+				records = append(records, r)
+			}
+		}
+
+		result.Embedded[name] = records
+	}
+
+	return result, nil
+}
+
 // JSON is a method for actions.JSON
 func (action *TransactionShowAction) JSON() {
-	query := action.Query()
+	action.LoadRecord()
+	action.LoadEmbeds()
+	withXDR := action.IncludeXDR()
 
 	if action.Err != nil {
 		return
 	}
 
-	action.Err = db.Get(action.Ctx, query, &action.Record)
+	// validated transactions, and their operations and effects, never change
+	if action.NotModified(action.Record.LedgerSequence, action.Record.TransactionHash) {
+		return
+	}
 
+	action.LoadEmbedded()
 	if action.Err != nil {
 		return
 	}
 
-	hal.Render(action.W, NewTransactionResource(action.Record))
+	resource, err := action.Resource(withXDR)
+	if err != nil {
+		action.Err = err
+		return
+	}
+
+	hal.Render(action.W, resource)
+}
+
+// XDR is a method for actions.XDR, rendering the transaction's envelope.
+func (action *TransactionShowAction) XDR() {
+	action.LoadRecord()
+
+	if action.Err != nil {
+		return
+	}
+
+	action.Err = xdr.Render(action.W, action.Record.TxEnvelope)
 }
 
 // TransactionCreateAction submits a transaction to the stellar-core network
-// on behalf of the requesting client.
+// on behalf of the requesting client.  When given a callback_url, the
+// transaction's final status is also posted there once it succeeds or fails.
 type TransactionCreateAction struct {
 	Action
 }
 
 // JSON format action handler
 func (action *TransactionCreateAction) JSON() {
+	env := action.GetString("tx")
+	callback := action.GetCallbackURL()
+	if action.Err != nil {
+		return
+	}
 
-	l := action.App.submitter.Submit(action.Ctx, action.GetString("tx"))
+	l := action.App.submitter.Submit(action.Ctx, env)
+
+	if callback != "" {
+		hash, err := action.App.submitter.Hash(action.Ctx, env)
+		if err == nil {
+			l = action.App.NotifyResult(hash, callback, l)
+		}
+	}
 
 	select {
 	case result := <-l:
@@ -137,7 +329,152 @@ func (action *TransactionCreateAction) JSON() {
 			problem.Render(action.Ctx, action.W, resource.Error())
 		}
 	case <-action.Ctx.Done():
+		// the client is gone, unless the request timed out
+		if action.Ctx.Err() == context.DeadlineExceeded {
+			problem.Render(action.Ctx, action.W, problem.Timeout)
+		}
+	}
+
+}
+
+// TransactionCreateAsyncAction submits a transaction to the stellar-core
+// network without waiting for it to be included in a ledger.  Once
+// stellar-core accepts the transaction, it responds 201 Created with a pending
+// TransactionStatusResource, whose self link, also given as the Location, can
+// be polled until the transaction succeeds or fails.  Transactions whose
+// outcome is known without waiting are rendered as by TransactionCreateAction.
+type TransactionCreateAsyncAction struct {
+	Action
+}
+
+// JSON format action handler
+func (action *TransactionCreateAsyncAction) JSON() {
+	env := action.GetString("tx")
+	callback := action.GetCallbackURL()
+	if action.Err != nil {
+		return
+	}
+
+	hash, err := action.App.submitter.Hash(action.Ctx, env)
+	if err != nil {
+		resource := &ResultResource{txsub.Result{Err: err, EnvelopeXDR: env}}
+		problem.Render(action.Ctx, action.W, resource.Error())
+		return
+	}
+
+	// Submit returns without a result only when stellar-core has accepted the
+	// transaction and it awaits inclusion in a ledger.  Only then is the
+	// callback posted, as other outcomes are rendered right away.
+	l := action.App.submitter.Submit(action.Ctx, env)
+	select {
+	case result := <-l:
+		resource := &ResultResource{result}
+
+		if resource.IsSuccess() {
+			hal.Render(action.W, resource.Success())
+		} else {
+			problem.Render(action.Ctx, action.W, resource.Error())
+		}
+	default:
+		if callback != "" {
+			action.App.NotifyResult(hash, callback, l)
+		}
+
+		resource, err := NewTransactionStatusResource(
+			hash,
+			txsub.Result{Err: txsub.ErrNoResults},
+			true,
+			action.App.submitter.Attempts(action.Ctx, hash),
+		)
+		if err != nil {
+			action.Err = err
+			return
+		}
+
+		action.W.Header().Set("Location", action.LinkPrefix+"/transactions/"+hash+"/status")
+		hal.RenderStatus(action.W, http.StatusCreated, resource)
+	}
+}
+
+// TransactionStatusAction renders the status of a transaction, as submitted by
+// TransactionCreateAsyncAction.  Transactions neither awaiting inclusion in a
+// ledger nor found in one are not found.
+type TransactionStatusAction struct {
+	Action
+	Hash     string
+	Resource TransactionStatusResource
+}
+
+// LoadResource populates action.Resource
+func (action *TransactionStatusAction) LoadResource() {
+	action.Hash = action.GetString("tx_id")
+	if action.Err != nil {
+		return
+	}
+
+	r, pending := action.App.submitter.Status(action.Ctx, action.Hash)
+	if r.Err == txsub.ErrNoResults && !pending {
+		action.Err = &problem.NotFound
+		return
+	}
+
+	action.Resource, action.Err = NewTransactionStatusResource(
+		action.Hash,
+		r,
+		pending,
+		action.App.submitter.Attempts(action.Ctx, action.Hash),
+	)
+}
+
+// JSON is a method for actions.JSON
+func (action *TransactionStatusAction) JSON() {
+	action.Do(action.LoadResource)
+	if action.Err != nil {
+		return
+	}
+
+	hal.Render(action.W, action.Resource)
+}
+
+// TransactionSimulateAction predicts the outcome of submitting a transaction,
+// without submitting it, by simulating it against the latest ledger
+// stellar-core has closed.  The prediction is rendered whether or not the
+// transaction is predicted to succeed.
+type TransactionSimulateAction struct {
+	Action
+	Ledger   int32
+	Result   simulate.Result
+	Resource SimulationResource
+}
+
+// LoadResult populates action.Result, simulating the tx param
+func (action *TransactionSimulateAction) LoadResult() {
+	env := action.GetString("tx")
+	if action.Err != nil {
+		return
+	}
+
+	var err error
+	action.Result, action.Ledger, err = action.App.Simulate(action.Ctx, env)
+	if merr, ok := err.(*txsub.MalformedTransactionError); ok {
+		err = problem.TransactionMalformed.With(map[string]interface{}{
+			"envelope_xdr": merr.EnvelopeXDR,
+		})
+	}
+	action.Err = err
+}
+
+// LoadResource populates action.Resource
+func (action *TransactionSimulateAction) LoadResource() {
+	action.Resource, action.Err = NewSimulationResource(action.Ledger, action.Result)
+}
+
+// JSON is a method for actions.JSON
+func (action *TransactionSimulateAction) JSON() {
+	action.Do(action.LoadResult, action.LoadResource)
+	if action.Err != nil {
 		return
 	}
 
+	hal.Render(action.W, action.Resource)
 }
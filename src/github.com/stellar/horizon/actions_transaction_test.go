@@ -2,9 +2,16 @@ package horizon
 
 import (
 	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
 	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/go-stellar-base"
+	"github.com/stellar/go-stellar-base/build"
+	"github.com/stellar/horizon/render"
 	"github.com/stellar/horizon/test"
-	"testing"
 )
 
 func TestTransactionActions(t *testing.T) {
@@ -25,6 +32,75 @@ func TestTransactionActions(t *testing.T) {
 			So(result.Hash, ShouldEqual, "2374e99349b9ef7dba9a5db3339b78fda8f34777b1af33ba468ad5c0df946d4d")
 		})
 
+		Convey("GET /transactions/:id?include_xdr=false", func() {
+			w := rh.Get("/transactions/2374e99349b9ef7dba9a5db3339b78fda8f34777b1af33ba468ad5c0df946d4d?include_xdr=false", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Body.String(), ShouldNotContainSubstring, "envelope_xdr")
+			So(w.Body.String(), ShouldNotContainSubstring, "result_meta_xdr")
+
+			w = rh.Get("/transactions?include_xdr=false", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Body, ShouldBePageOf, 4)
+			So(w.Body.String(), ShouldNotContainSubstring, "envelope_xdr")
+
+			w = rh.Get("/transactions", test.RequestHelperNoop)
+			So(w.Body.String(), ShouldContainSubstring, "envelope_xdr")
+
+			w = rh.Get("/transactions?include_xdr=maybe", test.RequestHelperNoop)
+			So(w.Code, ShouldNotEqual, 200)
+		})
+
+		Convey("GET /transactions/:id?decode_xdr=true", func() {
+			w := rh.Get("/transactions/2374e99349b9ef7dba9a5db3339b78fda8f34777b1af33ba468ad5c0df946d4d?decode_xdr=true", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+
+			var result struct {
+				Decoded map[string]map[string]interface{} `json:"decoded_xdr"`
+			}
+			err := json.Unmarshal(w.Body.Bytes(), &result)
+			So(err, ShouldBeNil)
+			So(result.Decoded["envelope_xdr"]["tx"], ShouldNotBeNil)
+			So(result.Decoded["result_xdr"]["fee_charged"], ShouldNotBeNil)
+			So(result.Decoded["result_meta_xdr"], ShouldNotBeNil)
+
+			w = rh.Get("/transactions/2374e99349b9ef7dba9a5db3339b78fda8f34777b1af33ba468ad5c0df946d4d", test.RequestHelperNoop)
+			So(w.Body.String(), ShouldNotContainSubstring, "decoded_xdr")
+
+			w = rh.Get("/transactions?decode_xdr=maybe", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 400)
+		})
+
+		Convey("GET /transactions/:id?embed=operations,effects", func() {
+			w := rh.Get("/transactions/2374e99349b9ef7dba9a5db3339b78fda8f34777b1af33ba468ad5c0df946d4d?embed=operations,effects", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+
+			var result struct {
+				Hash     string
+				Embedded struct {
+					Operations []map[string]interface{}
+					Effects    []map[string]interface{}
+				} `json:"_embedded"`
+			}
+			err := json.Unmarshal(w.Body.Bytes(), &result)
+			So(err, ShouldBeNil)
+			So(result.Hash, ShouldEqual, "2374e99349b9ef7dba9a5db3339b78fda8f34777b1af33ba468ad5c0df946d4d")
+			So(len(result.Embedded.Operations), ShouldEqual, 1)
+			So(len(result.Embedded.Effects), ShouldBeGreaterThan, 0)
+
+			w = rh.Get("/transactions/2374e99349b9ef7dba9a5db3339b78fda8f34777b1af33ba468ad5c0df946d4d?embed=signers", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 400)
+			So(w.Header().Get("ETag"), ShouldBeBlank)
+		})
+
+		Convey("GET /transactions/:id as xdr", func() {
+			w := rh.Get("/transactions/2374e99349b9ef7dba9a5db3339b78fda8f34777b1af33ba468ad5c0df946d4d", func(r *http.Request) {
+				r.Header.Set("Accept", render.MimeXDR)
+			})
+			So(w.Code, ShouldEqual, 200)
+			So(w.Header().Get("Content-Type"), ShouldEqual, render.MimeXDR)
+			So(w.Body.Len(), ShouldBeGreaterThan, 0)
+		})
+
 		Convey("GET /transactions/not_real", func() {
 			w := rh.Get("/transactions/not_real", test.RequestHelperNoop)
 			So(w.Code, ShouldEqual, 404)
@@ -41,6 +117,71 @@ func TestTransactionActions(t *testing.T) {
 			So(w.Body, ShouldBePageOf, 4)
 		})
 
+		Convey("GET /transactions?start_time&end_time", func() {
+			w := rh.Get("/transactions?start_time=2015-10-07T23:07:28Z", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Body, ShouldBePageOf, 1)
+
+			w = rh.Get("/transactions?end_time=2015-10-07T23:07:28Z", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Body, ShouldBePageOf, 3)
+
+			w = rh.Get("/transactions?start_time=2015-10-08T00:00:00Z", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Body, ShouldBePageOf, 0)
+
+			w = rh.Get("/transactions?start_time=yesterday", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 400)
+		})
+
+		Convey("GET /transactions?include_failed=true", func() {
+			// the scenario's transactions all succeeded
+			w := rh.Get("/transactions?include_failed=true", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Body, ShouldBePageOf, 4)
+			So(w.Body.String(), ShouldContainSubstring, `"successful":true`)
+
+			w = rh.Get("/accounts/GA5WBPYA5Y4WAEHXWR2UKO2UO4BUGHUQ74EUPKON2QHV4WRHOIRNKKH2/transactions?include_failed=true&limit=1", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Body, ShouldBePageOf, 1)
+
+			w = rh.Get("/transactions?include_failed=maybe", test.RequestHelperNoop)
+			So(w.Code, ShouldNotEqual, 200)
+		})
+
+		Convey("GET /transactions?include_failed=true&include_xdr=false follows next", func() {
+			w := rh.Get("/transactions?include_failed=true&include_xdr=false&limit=3", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Body, ShouldBePageOf, 3)
+
+			var page map[string]interface{}
+			err := json.Unmarshal(w.Body.Bytes(), &page)
+			So(err, ShouldBeNil)
+			next := page["_links"].(map[string]interface{})["next"].(map[string]interface{})["href"].(string)
+			So(next, ShouldStartWith, "/transactions?include_failed=true&include_xdr=false&")
+
+			w = rh.Get(next, test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Body, ShouldBePageOf, 1)
+			So(w.Body.String(), ShouldNotContainSubstring, "envelope_xdr")
+		})
+
+		Convey("GET /transactions as ndjson", func() {
+			w := rh.Get("/transactions?limit=1", func(r *http.Request) {
+				r.Header.Set("Accept", render.MimeNDJSON)
+			})
+			So(w.Code, ShouldEqual, 200)
+			So(w.Header().Get("Content-Type"), ShouldEqual, render.MimeNDJSON)
+
+			lines := strings.Split(strings.TrimSuffix(w.Body.String(), "\n"), "\n")
+			So(len(lines), ShouldEqual, 4)
+
+			var result TransactionResource
+			err := json.Unmarshal([]byte(lines[0]), &result)
+			So(err, ShouldBeNil)
+			So(result.Hash, ShouldNotBeEmpty)
+		})
+
 		Convey("GET /ledgers/:ledger_id/transactions", func() {
 			w := rh.Get("/ledgers/1/transactions", test.RequestHelperNoop)
 			So(w.Code, ShouldEqual, 200)
@@ -69,5 +210,101 @@ func TestTransactionActions(t *testing.T) {
 			So(w.Body, ShouldBePageOf, 2)
 		})
 
+		Convey("GET /transactions/:id/status", func() {
+			w := rh.Get("/transactions/2374e99349b9ef7dba9a5db3339b78fda8f34777b1af33ba468ad5c0df946d4d/status", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+
+			var result TransactionStatusResource
+			err := json.Unmarshal(w.Body.Bytes(), &result)
+			So(err, ShouldBeNil)
+			So(result.Status, ShouldEqual, TransactionStatusSuccess)
+			So(result.Ledger, ShouldEqual, 2)
+			So(result.ResultCodes, ShouldBeNil)
+
+			w = rh.Get("/transactions/0000000000000000000000000000000000000000000000000000000000000000/status", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 404)
+		})
+
+		Convey("POST /transactions_async", func() {
+			w := rh.Post("/transactions_async", url.Values{"tx": []string{"not_xdr"}}, test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 400)
+			So(w.Body.String(), ShouldContainSubstring, "transaction_malformed")
+		})
+
+		Convey("POST /transactions refuses submissions once the app shuts down", func() {
+			app.shutdown()
+
+			w := rh.Post("/transactions", url.Values{"tx": []string{"not_xdr"}}, test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 503)
+			So(w.Body.String(), ShouldContainSubstring, "submission_interrupted")
+		})
+
+		Convey("POST /transactions refuses envelopes signed for another network", func() {
+			_, root, err := stellarbase.GenerateKeyFromSeed("SDHOAMBNLGCE2MV5ZKIVZAQD3VCLGP53P3OBSBI6UN5L5XZI5TKHFQL4")
+			So(err, ShouldBeNil)
+
+			tx := build.Transaction(
+				build.SourceAccount{Address: root.Address()},
+				build.Sequence{Sequence: 4},
+				build.PublicNetwork,
+				build.Payment(
+					build.Destination{Address: "GA5WBPYA5Y4WAEHXWR2UKO2UO4BUGHUQ74EUPKON2QHV4WRHOIRNKKH2"},
+					build.NativeAmount{Amount: "10"},
+				),
+			)
+			txe := tx.Sign(&root)
+			env, err := txe.Base64()
+			So(err, ShouldBeNil)
+
+			w := rh.Post("/transactions", url.Values{"tx": []string{env}}, test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 400)
+			So(w.Body.String(), ShouldContainSubstring, "transaction_wrong_network")
+		})
+
+		Convey("POST /transactions/simulate", func() {
+			_, root, err := stellarbase.GenerateKeyFromSeed("SDHOAMBNLGCE2MV5ZKIVZAQD3VCLGP53P3OBSBI6UN5L5XZI5TKHFQL4")
+			So(err, ShouldBeNil)
+
+			simulate := func(seq build.Sequence) SimulationResource {
+				tx := build.Transaction(
+					build.SourceAccount{Address: root.Address()},
+					seq,
+					build.TestNetwork,
+					build.Payment(
+						build.Destination{Address: "GA5WBPYA5Y4WAEHXWR2UKO2UO4BUGHUQ74EUPKON2QHV4WRHOIRNKKH2"},
+						build.NativeAmount{Amount: "10"},
+					),
+				)
+				txe := tx.Sign(&root)
+				env, err := txe.Base64()
+				So(err, ShouldBeNil)
+
+				w := rh.Post("/transactions/simulate", url.Values{"tx": []string{env}}, test.RequestHelperNoop)
+				So(w.Code, ShouldEqual, 200)
+
+				var result SimulationResource
+				err = json.Unmarshal(w.Body.Bytes(), &result)
+				So(err, ShouldBeNil)
+				So(result.Ledger, ShouldEqual, 3)
+				return result
+			}
+
+			result := simulate(build.Sequence{Sequence: 4})
+			So(result.Successful, ShouldBeTrue)
+			So(result.FeeCharged, ShouldEqual, 100)
+			So(result.ResultCodes.TransactionCode, ShouldEqual, "tx_success")
+			So(result.ResultCodes.OperationCodes, ShouldResemble, []string{"op_success"})
+			So(result.UncheckedOperations, ShouldBeEmpty)
+
+			result = simulate(build.Sequence{Sequence: 10})
+			So(result.Successful, ShouldBeFalse)
+			So(result.FeeCharged, ShouldEqual, 0)
+			So(result.ResultCodes.TransactionCode, ShouldEqual, "tx_bad_seq")
+
+			w := rh.Post("/transactions/simulate", url.Values{"tx": []string{"not_xdr"}}, test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 400)
+			So(w.Body.String(), ShouldContainSubstring, "transaction_malformed")
+		})
+
 	})
 }
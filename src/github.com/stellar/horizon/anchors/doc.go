@@ -0,0 +1,13 @@
+// Package anchors annotates assets with the metadata their issuers publish
+// about themselves, the anchors of the network.
+//
+// The issuer of an asset names, by the home domain of its account, the domain
+// whose stellar.toml file describes it: the name of the organization behind
+// it, and the currencies it issues.  An asset is verified when the
+// stellar.toml of its issuer's home domain lists it, issued by that issuer.
+// A Directory loads those files in the background and caches what it found
+// of each issuer, so that rendering an asset never waits on a domain.  The
+// files are fetched by a few workers, each request timing out and reading no
+// more than a bounded size, and only from home domains that are public
+// hostnames, as any account may name any domain as its home.
+package anchors
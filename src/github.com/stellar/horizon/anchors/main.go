@@ -0,0 +1,282 @@
+package anchors
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/go-errors/errors"
+	"github.com/golang/groupcache/lru"
+	"github.com/stellar/horizon/httpx"
+	"github.com/stellar/horizon/log"
+	"golang.org/x/net/context"
+)
+
+const (
+	// DefaultCacheSize is the number of issuers a Directory remembers when
+	// CacheSize is zero.
+	DefaultCacheSize = 1000
+
+	// DefaultCacheTTL is how long a Directory remembers an issuer when CacheTTL
+	// is zero.
+	DefaultCacheTTL = time.Hour
+
+	// fetchQueueSize is the most issuers waiting to be fetched at once.  The
+	// lookups of others, made while the queue is full, are not fetched.
+	fetchQueueSize = 100
+
+	// DefaultWorkers is the most issuers a Directory fetches at once when
+	// Workers is zero.
+	DefaultWorkers = 4
+
+	// DefaultTimeout is how long a Directory waits on each stellar.toml when
+	// its client has no timeout of its own.
+	DefaultTimeout = 10 * time.Second
+
+	// maxTomlSize is the most bytes read of a stellar.toml file.
+	maxTomlSize = 100 * 1024
+)
+
+// Anchor is what the issuer of an asset publishes about it.  Domain is the
+// home domain of the issuer, or empty when it has none.  Name and Image are
+// left empty when its stellar.toml does not give them, or cannot be loaded.
+type Anchor struct {
+	Domain   string
+	Name     string
+	Image    string
+	Verified bool
+}
+
+// Directory looks up the Anchors of assets, fetching the stellar.toml of the
+// home domain of each issuer in the background.  Start must be called before
+// lookups are fetched.  A Directory is safe for concurrent access.
+type Directory struct {
+	// HomeDomain returns the home domain of the account of issuer, or empty
+	// when it has none.
+	HomeDomain func(ctx context.Context, issuer string) (string, error)
+
+	// Client performs the directory's requests.  When nil, the client bound to
+	// the context given to Start by httpx.ClientContext is used.  Either is
+	// given DefaultTimeout when it has no timeout.
+	Client *http.Client
+
+	// CacheSize is the most issuers remembered at once.
+	CacheSize int
+
+	// CacheTTL is how long what was fetched of an issuer is remembered.
+	CacheTTL time.Duration
+
+	// Workers is the most issuers fetched at once in the background.
+	Workers int
+
+	// Insecure loads stellar.toml files over plain http rather than https,
+	// and from any host.  Otherwise, home domains that are IP addresses or
+	// names of private networks are not fetched.  It is meant for testing
+	// against local servers.
+	Insecure bool
+
+	lock    sync.Mutex
+	cache   *lru.Cache
+	pending map[string]bool
+	queue   chan string
+}
+
+// issuer is what a Directory fetched of an issuer: its domain, the name of its
+// organization, and the images of the codes of the currencies its stellar.toml
+// lists as issued by it.
+type issuer struct {
+	domain  string
+	name    string
+	images  map[string]string
+	expires time.Time
+}
+
+// Start fetches the issuers looked up, in the background, until ctx is done,
+// with as many Workers.
+func (d *Directory) Start(ctx context.Context) {
+	d.lock.Lock()
+	d.init()
+	queue := d.queue
+	d.lock.Unlock()
+
+	workers := d.Workers
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+
+	for i := 0; i < workers; i++ {
+		go d.work(ctx, queue)
+	}
+}
+
+// work fetches the issuers of queue until ctx is done
+func (d *Directory) work(ctx context.Context, queue chan string) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case address := <-queue:
+			_, err := d.fetch(ctx, address)
+			if err != nil {
+				log.WithField(ctx, "issuer", address).
+					WithField("err", err.Error()).
+					Debug("failed to fetch anchor")
+			}
+
+			d.lock.Lock()
+			delete(d.pending, address)
+			d.lock.Unlock()
+		}
+	}
+}
+
+// Lookup returns the Anchor of the asset of code issued by address, as last
+// fetched.  An issuer yet to be fetched, or whose fetch has expired, is queued
+// to be fetched in the background, and found only once it has been; until
+// then, an expired issuer is found as last fetched.
+func (d *Directory) Lookup(code, address string) (Anchor, bool) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.init()
+
+	var found *issuer
+	if v, ok := d.cache.Get(address); ok {
+		found = v.(*issuer)
+	}
+
+	if (found == nil || time.Now().After(found.expires)) && !d.pending[address] {
+		select {
+		case d.queue <- address:
+			d.pending[address] = true
+		default:
+		}
+	}
+
+	if found == nil {
+		return Anchor{}, false
+	}
+
+	return found.anchor(code), true
+}
+
+// Fetch loads the Anchor of the asset of code issued by address from the
+// stellar.toml of the issuer's home domain, caching what was found of the
+// issuer.
+func (d *Directory) Fetch(ctx context.Context, code, address string) (Anchor, error) {
+	found, err := d.fetch(ctx, address)
+	if err != nil {
+		return Anchor{}, err
+	}
+
+	return found.anchor(code), nil
+}
+
+// fetch loads what the issuer of address publishes about itself, caching it.
+// An issuer whose stellar.toml cannot be loaded is cached along with its
+// domain, so that it is not fetched again until it expires.
+func (d *Directory) fetch(ctx context.Context, address string) (*issuer, error) {
+	domain, err := d.HomeDomain(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &issuer{domain: domain, images: map[string]string{}}
+	if domain != "" {
+		err = d.load(ctx, result, address)
+	}
+
+	ttl := d.CacheTTL
+	if ttl == 0 {
+		ttl = DefaultCacheTTL
+	}
+	result.expires = time.Now().Add(ttl)
+
+	d.lock.Lock()
+	d.init()
+	d.cache.Add(address, result)
+	d.lock.Unlock()
+
+	return result, err
+}
+
+// load reads the stellar.toml of the domain of i into it, keeping the
+// currencies it lists as issued by address.
+func (d *Directory) load(ctx context.Context, i *issuer, address string) error {
+	scheme := "https"
+	if d.Insecure {
+		scheme = "http"
+	} else if httpx.ValidatePublicHost(i.domain) != nil {
+		return errors.Errorf("home domain %q is not a public hostname", i.domain)
+	}
+
+	client := d.Client
+	if client == nil {
+		client = httpx.ClientFromContext(ctx)
+	}
+	client = httpx.ClientWithTimeout(client, DefaultTimeout)
+
+	resp, err := client.Get(fmt.Sprintf("%s://%s/.well-known/stellar.toml", scheme, i.domain))
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("stellar.toml of %s responded with status %d", i.domain, resp.StatusCode)
+	}
+
+	var stellarToml struct {
+		Documentation struct {
+			OrgName string `toml:"ORG_NAME"`
+		} `toml:"DOCUMENTATION"`
+		Currencies []struct {
+			Code   string `toml:"code"`
+			Issuer string `toml:"issuer"`
+			Image  string `toml:"image"`
+		} `toml:"CURRENCIES"`
+	}
+
+	_, err = toml.DecodeReader(io.LimitReader(resp.Body, maxTomlSize), &stellarToml)
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	i.name = stellarToml.Documentation.OrgName
+	for _, c := range stellarToml.Currencies {
+		if c.Issuer == address && c.Code != "" {
+			i.images[c.Code] = c.Image
+		}
+	}
+
+	return nil
+}
+
+// anchor returns the Anchor of the asset of code issued by i
+func (i *issuer) anchor(code string) Anchor {
+	image, verified := i.images[code]
+	return Anchor{
+		Domain:   i.domain,
+		Name:     i.name,
+		Image:    image,
+		Verified: verified,
+	}
+}
+
+// init creates the cache and queue of d, unless created.  d.lock must be held.
+func (d *Directory) init() {
+	if d.cache != nil {
+		return
+	}
+
+	size := d.CacheSize
+	if size == 0 {
+		size = DefaultCacheSize
+	}
+
+	d.cache = lru.New(size)
+	d.pending = map[string]bool{}
+	d.queue = make(chan string, fetchQueueSize)
+}
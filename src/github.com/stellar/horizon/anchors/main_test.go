@@ -0,0 +1,132 @@
+package anchors
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/test"
+	"golang.org/x/net/context"
+)
+
+func TestDirectory(t *testing.T) {
+	const (
+		issuer = "GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4"
+		other  = "GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON"
+	)
+	ctx := test.Context()
+
+	Convey("Directory", t, func() {
+		var fetches int32
+		mux := http.NewServeMux()
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		domain := strings.TrimPrefix(server.URL, "http://")
+
+		mux.HandleFunc("/.well-known/stellar.toml", func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&fetches, 1)
+			fmt.Fprintf(w, `
+[DOCUMENTATION]
+ORG_NAME="Example Anchor"
+
+[[CURRENCIES]]
+code="USD"
+issuer="%s"
+image="https://example.com/usd.png"
+
+[[CURRENCIES]]
+code="EUR"
+issuer="%s"
+`, issuer, other)
+		})
+
+		domains := map[string]string{issuer: domain, other: domain}
+		d := &Directory{
+			Insecure: true,
+			HomeDomain: func(ctx context.Context, address string) (string, error) {
+				return domains[address], nil
+			},
+		}
+
+		Convey("verifies the assets the stellar.toml of their issuer lists", func() {
+			a, err := d.Fetch(ctx, "USD", issuer)
+			So(err, ShouldBeNil)
+			So(a, ShouldResemble, Anchor{
+				Domain:   domain,
+				Name:     "Example Anchor",
+				Image:    "https://example.com/usd.png",
+				Verified: true,
+			})
+
+			a, ok := d.Lookup("EUR", issuer)
+			So(ok, ShouldBeTrue)
+			So(a.Name, ShouldEqual, "Example Anchor")
+			So(a.Verified, ShouldBeFalse)
+			So(atomic.LoadInt32(&fetches), ShouldEqual, 1)
+		})
+
+		Convey("leaves issuers without a home domain unverified", func() {
+			a, err := d.Fetch(ctx, "USD", "GA5WBPYA5Y4WAEHXWR2UKO2UO4BUGHUQ74EUPKON2QHV4WRHOIRNKKH2")
+			So(err, ShouldBeNil)
+			So(a, ShouldResemble, Anchor{})
+			So(atomic.LoadInt32(&fetches), ShouldEqual, 0)
+		})
+
+		Convey("leaves issuers whose home domain is not a public hostname unverified", func() {
+			d.Insecure = false
+			for _, domain := range []string{domain, "localhost", "169.254.169.254", "printer.local"} {
+				domains[issuer] = domain
+				_, err := d.Fetch(ctx, "USD", issuer)
+				So(err, ShouldNotBeNil)
+
+				a, ok := d.Lookup("USD", issuer)
+				So(ok, ShouldBeTrue)
+				So(a, ShouldResemble, Anchor{Domain: domain})
+			}
+			So(atomic.LoadInt32(&fetches), ShouldEqual, 0)
+		})
+
+		Convey("fetches the issuers looked up in the background", func() {
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			d.Start(ctx)
+
+			_, ok := d.Lookup("USD", issuer)
+			So(ok, ShouldBeFalse)
+
+			var a Anchor
+			for i := 0; i < 100 && !ok; i++ {
+				time.Sleep(10 * time.Millisecond)
+				a, ok = d.Lookup("USD", issuer)
+			}
+			So(ok, ShouldBeTrue)
+			So(a.Verified, ShouldBeTrue)
+		})
+
+		Convey("fetches expired issuers again", func() {
+			d.CacheTTL = time.Nanosecond
+			_, err := d.Fetch(ctx, "USD", issuer)
+			So(err, ShouldBeNil)
+			time.Sleep(time.Millisecond)
+
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			d.Start(ctx)
+
+			a, ok := d.Lookup("USD", issuer)
+			So(ok, ShouldBeTrue)
+			So(a.Verified, ShouldBeTrue)
+
+			for i := 0; i < 100 && atomic.LoadInt32(&fetches) < 2; i++ {
+				time.Sleep(10 * time.Millisecond)
+			}
+			So(atomic.LoadInt32(&fetches), ShouldBeGreaterThanOrEqualTo, 2)
+		})
+	})
+}
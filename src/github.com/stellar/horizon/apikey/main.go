@@ -0,0 +1,185 @@
+// Package apikey authenticates the requests of clients given api keys by the
+// operator of a horizon, such as partners offered more generous rate limits or
+// access to endpoints others lack.  Keys are loaded from a toml file, from the
+// horizon_api_keys table of the history database, or both.
+package apikey
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	stderr "errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stellar/horizon/log"
+	"github.com/stellar/horizon/ratelimit"
+	"golang.org/x/net/context"
+)
+
+// DefaultMaxAge is how long a Keyring holds the keys it loaded before loading
+// them again, when MaxAge is zero.
+const DefaultMaxAge = time.Minute
+
+// ErrUnknownKey is returned when looking up a token no key has
+// NOTE: this is not a go-errors based error, as stack traces are unnecessary
+var ErrUnknownKey = stderr.New("unknown api key")
+
+// Key is an api key given to a client
+type Key struct {
+	// Name identifies the key, such as by the client it was given to, in logs
+	// and rate limits.
+	Name string
+
+	// TokenHash is the hex encoded sha256 hash of the token the client gives,
+	// as returned by Hash.
+	TokenHash string
+
+	// RateLimit is the bucket of the requests made with the key, whose Rate is
+	// zero when they are limited as those made without a key are.
+	RateLimit ratelimit.Bucket
+
+	// Scopes are the endpoints the key may request
+	Scopes []Scope
+}
+
+// Allows returns true if one of the scopes of k covers r
+func (k *Key) Allows(r *http.Request) bool {
+	for _, s := range k.Scopes {
+		if s.Covers(r) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Scope is a set of endpoints: those requested with Method, any method when it
+// is empty, whose path is Path or lies beneath it, e.g. "/accounts" covering
+// "/accounts/GABC/payments".  A Path of "/" covers every endpoint.
+type Scope struct {
+	Method string
+	Path   string
+}
+
+// ParseScope parses a scope written as "[METHOD] PATH", such as
+// "POST /transactions" or "/ledgers".  "*" stands for every endpoint.
+func ParseScope(s string) Scope {
+	fields := strings.Fields(s)
+
+	switch {
+	case len(fields) == 0:
+		return Scope{}
+	case len(fields) == 1 && fields[0] == "*":
+		return Scope{Path: "/"}
+	case len(fields) == 1:
+		return Scope{Path: strings.TrimSuffix(fields[0], "/")}
+	}
+
+	path := fields[1]
+	if path == "*" {
+		path = "/"
+	}
+	if path != "/" {
+		path = strings.TrimSuffix(path, "/")
+	}
+
+	return Scope{Method: strings.ToUpper(fields[0]), Path: path}
+}
+
+// Covers returns true if r is of the endpoints of s
+func (s Scope) Covers(r *http.Request) bool {
+	if s.Path == "" {
+		return false
+	}
+
+	if s.Method != "" && s.Method != r.Method {
+		return false
+	}
+
+	if s.Path == "/" {
+		return true
+	}
+
+	path := r.URL.Path
+	return path == s.Path || strings.HasPrefix(path, s.Path+"/")
+}
+
+// Hash returns the hash of token keys hold
+func Hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Source loads keys
+type Source interface {
+	Load() ([]Key, error)
+}
+
+// Keyring looks up keys by token, loaded from Sources.  The keys are held for
+// MaxAge, then loaded again, so that keys added to or removed from a source
+// take effect without restarting horizon.  Should loading them fail, those
+// loaded before are held on to.
+//
+// A Keyring is safe for concurrent access.
+type Keyring struct {
+	Sources []Source
+	MaxAge  time.Duration
+
+	lock     sync.Mutex
+	keys     map[string]*Key
+	loadedAt time.Time
+}
+
+// Lookup returns the key of token
+func (k *Keyring) Lookup(ctx context.Context, token string) (*Key, error) {
+	keys, err := k.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	key, ok := keys[Hash(token)]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	return key, nil
+}
+
+// load returns the keys of the keyring by token hash, loading them again once
+// older than MaxAge.
+func (k *Keyring) load(ctx context.Context) (map[string]*Key, error) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	maxAge := k.MaxAge
+	if maxAge == 0 {
+		maxAge = DefaultMaxAge
+	}
+
+	if k.keys != nil && time.Since(k.loadedAt) < maxAge {
+		return k.keys, nil
+	}
+
+	keys := map[string]*Key{}
+	for _, source := range k.Sources {
+		loaded, err := source.Load()
+		if err != nil && k.keys == nil {
+			return nil, err
+		}
+		if err != nil {
+			log.WithField(ctx, "err", err.Error()).Warn("failed to load api keys, using those loaded before")
+			k.loadedAt = time.Now()
+			return k.keys, nil
+		}
+
+		for i := range loaded {
+			keys[loaded[i].TokenHash] = &loaded[i]
+		}
+	}
+
+	k.keys = keys
+	k.loadedAt = time.Now()
+	return keys, nil
+}
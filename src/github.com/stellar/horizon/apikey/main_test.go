@@ -0,0 +1,134 @@
+package apikey
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-errors/errors"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/test"
+)
+
+type fakeSource struct {
+	keys  []Key
+	err   error
+	loads int
+}
+
+func (s *fakeSource) Load() ([]Key, error) {
+	s.loads++
+	return s.keys, s.err
+}
+
+func TestScope(t *testing.T) {
+	request := func(method, path string) *http.Request {
+		r, _ := http.NewRequest(method, path, nil)
+		return r
+	}
+
+	Convey("Scope", t, func() {
+		So(ParseScope("*"), ShouldResemble, Scope{Path: "/"})
+		So(ParseScope("/accounts/"), ShouldResemble, Scope{Path: "/accounts"})
+		So(ParseScope("post /transactions"), ShouldResemble, Scope{Method: "POST", Path: "/transactions"})
+		So(ParseScope("GET *"), ShouldResemble, Scope{Method: "GET", Path: "/"})
+
+		accounts := ParseScope("GET /accounts")
+		So(accounts.Covers(request("GET", "/accounts")), ShouldBeTrue)
+		So(accounts.Covers(request("GET", "/accounts/GABC/payments")), ShouldBeTrue)
+		So(accounts.Covers(request("POST", "/accounts/batch")), ShouldBeFalse)
+		So(accounts.Covers(request("GET", "/accounts_other")), ShouldBeFalse)
+		So(ParseScope("*").Covers(request("POST", "/transactions")), ShouldBeTrue)
+		So(ParseScope("").Covers(request("GET", "/")), ShouldBeFalse)
+
+		key := Key{Scopes: []Scope{accounts, ParseScope("/ledgers")}}
+		So(key.Allows(request("GET", "/ledgers/1")), ShouldBeTrue)
+		So(key.Allows(request("GET", "/transactions")), ShouldBeFalse)
+		So((&Key{}).Allows(request("GET", "/")), ShouldBeFalse)
+	})
+}
+
+func TestKeyring(t *testing.T) {
+	ctx := test.Context()
+
+	Convey("Keyring", t, func() {
+		source := &fakeSource{keys: []Key{{Name: "partner", TokenHash: Hash("secret")}}}
+		keyring := &Keyring{Sources: []Source{source}, MaxAge: time.Hour}
+
+		key, err := keyring.Lookup(ctx, "secret")
+		So(err, ShouldBeNil)
+		So(key.Name, ShouldEqual, "partner")
+
+		_, err = keyring.Lookup(ctx, "other")
+		So(err, ShouldEqual, ErrUnknownKey)
+		So(source.loads, ShouldEqual, 1)
+
+		Convey("loads keys again once old", func() {
+			keyring.MaxAge = time.Nanosecond
+			source.keys = nil
+
+			_, err := keyring.Lookup(ctx, "secret")
+			So(err, ShouldEqual, ErrUnknownKey)
+		})
+
+		Convey("holds on to the keys loaded when loading fails", func() {
+			keyring.MaxAge = time.Nanosecond
+			source.err = errors.New("unreachable")
+
+			key, err := keyring.Lookup(ctx, "secret")
+			So(err, ShouldBeNil)
+			So(key.Name, ShouldEqual, "partner")
+		})
+
+		Convey("fails when keys were never loaded", func() {
+			source.err = errors.New("unreachable")
+			_, err := (&Keyring{Sources: []Source{source}}).Lookup(ctx, "secret")
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestFileSource(t *testing.T) {
+	Convey("FileSource", t, func() {
+		f, err := ioutil.TempFile("", "api-keys")
+		So(err, ShouldBeNil)
+		defer os.Remove(f.Name())
+
+		_, err = f.WriteString(`
+[[keys]]
+name = "partner"
+token = "secret"
+per_hour_rate_limit = 3600
+rate_limit_burst = 10
+scopes = ["GET /accounts", "*"]
+
+[[keys]]
+name = "hashed"
+token_sha256 = "9F86D081884C7D659A2FEAA0C55AD015A3BF4F1B2B0B822CD15D6C15B0F00A08"
+`)
+		So(err, ShouldBeNil)
+		f.Close()
+
+		keys, err := (&FileSource{Path: f.Name()}).Load()
+		So(err, ShouldBeNil)
+		So(len(keys), ShouldEqual, 2)
+
+		So(keys[0].Name, ShouldEqual, "partner")
+		So(keys[0].TokenHash, ShouldEqual, Hash("secret"))
+		So(keys[0].RateLimit.Rate, ShouldEqual, 1)
+		So(keys[0].RateLimit.Burst, ShouldEqual, 10)
+		So(keys[0].Scopes, ShouldResemble, []Scope{{Method: "GET", Path: "/accounts"}, {Path: "/"}})
+
+		So(keys[1].TokenHash, ShouldEqual, Hash("test"))
+		So(keys[1].RateLimit.Rate, ShouldEqual, 0)
+		So(keys[1].Scopes, ShouldBeEmpty)
+
+		Convey("rejects keys without a token", func() {
+			ioutil.WriteFile(f.Name(), []byte("[[keys]]\nname = \"partner\"\n"), 0600)
+			_, err := (&FileSource{Path: f.Name()}).Load()
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,116 @@
+package apikey
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/PuerkitoBio/throttled"
+	"github.com/go-errors/errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/stellar/horizon/ratelimit"
+)
+
+// FileSource loads the keys of the toml file at Path, each a table of the
+// keys array:
+//
+//	[[keys]]
+//	name = "partner"
+//	token_sha256 = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+//	per_hour_rate_limit = 36000
+//	rate_limit_burst = 100
+//	scopes = ["GET /accounts", "POST /transactions"]
+//
+// A plain token may be given rather than its hash.  Keys without a
+// per_hour_rate_limit are limited as requests made without a key are.
+type FileSource struct {
+	Path string
+}
+
+// fileKey is a key of the file of a FileSource
+type fileKey struct {
+	Name             string   `toml:"name"`
+	Token            string   `toml:"token"`
+	TokenSHA256      string   `toml:"token_sha256"`
+	PerHourRateLimit int      `toml:"per_hour_rate_limit"`
+	RateLimitBurst   int      `toml:"rate_limit_burst"`
+	Scopes           []string `toml:"scopes"`
+}
+
+// Load is a method for Source
+func (s *FileSource) Load() ([]Key, error) {
+	var file struct {
+		Keys []fileKey `toml:"keys"`
+	}
+
+	_, err := toml.DecodeFile(s.Path, &file)
+	if err != nil {
+		return nil, errors.Wrap(err, 1)
+	}
+
+	keys := make([]Key, 0, len(file.Keys))
+	for _, fk := range file.Keys {
+		hash := strings.ToLower(fk.TokenSHA256)
+		if fk.Token != "" {
+			hash = Hash(fk.Token)
+		}
+		if fk.Name == "" || hash == "" {
+			return nil, errors.Errorf("%s: api keys need a name and a token or token_sha256", s.Path)
+		}
+
+		keys = append(keys, newKey(fk.Name, hash, fk.PerHourRateLimit, fk.RateLimitBurst, fk.Scopes))
+	}
+
+	return keys, nil
+}
+
+// DBSource loads the keys of the horizon_api_keys table of DB
+type DBSource struct {
+	DB *sqlx.DB
+}
+
+// Load is a method for Source
+func (s *DBSource) Load() ([]Key, error) {
+	var rows []struct {
+		Name             string `db:"name"`
+		TokenSHA256      string `db:"token_sha256"`
+		PerHourRateLimit int    `db:"per_hour_rate_limit"`
+		RateLimitBurst   int    `db:"rate_limit_burst"`
+		Scopes           string `db:"scopes"`
+	}
+
+	err := s.DB.Select(&rows, `
+		SELECT name, token_sha256, per_hour_rate_limit, rate_limit_burst,
+			array_to_json(scopes)::text AS scopes
+		FROM horizon_api_keys`)
+	if err != nil {
+		return nil, errors.Wrap(err, 1)
+	}
+
+	keys := make([]Key, 0, len(rows))
+	for _, row := range rows {
+		var scopes []string
+		err = json.Unmarshal([]byte(row.Scopes), &scopes)
+		if err != nil {
+			return nil, errors.Wrap(err, 1)
+		}
+
+		keys = append(keys, newKey(row.Name, row.TokenSHA256, row.PerHourRateLimit, row.RateLimitBurst, scopes))
+	}
+
+	return keys, nil
+}
+
+func newKey(name, hash string, perHour, burst int, scopes []string) Key {
+	k := Key{Name: name, TokenHash: hash}
+
+	if perHour > 0 {
+		k.RateLimit = ratelimit.FromQuota(throttled.PerHour(perHour), burst)
+	}
+
+	for _, s := range scopes {
+		k.Scopes = append(k.Scopes, ParseScope(s))
+	}
+
+	return k
+}
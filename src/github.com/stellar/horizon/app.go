@@ -1,20 +1,35 @@
 package horizon
 
 import (
+	"crypto/tls"
 	"fmt"
+	"net"
 	"net/http"
 	"runtime"
+	"sync"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/garyburd/redigo/redis"
 	"github.com/jmoiron/sqlx"
 	"github.com/rcrowley/go-metrics"
 	"github.com/stellar/go-stellar-base/build"
+	"github.com/stellar/horizon/anchors"
+	"github.com/stellar/horizon/apikey"
+	"github.com/stellar/horizon/cache"
 	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/db/schema"
+	"github.com/stellar/horizon/friendbot"
+	"github.com/stellar/horizon/ingest"
 	"github.com/stellar/horizon/log"
+	"github.com/stellar/horizon/netcheck"
+	"github.com/stellar/horizon/paths"
 	"github.com/stellar/horizon/pump"
+	"github.com/stellar/horizon/reap"
 	"github.com/stellar/horizon/render/sse"
+	"github.com/stellar/horizon/trace"
 	"github.com/stellar/horizon/txsub"
+	"github.com/stellar/horizon/webhook"
 	"github.com/zenazn/goji/bind"
 	"github.com/zenazn/goji/graceful"
 	"golang.org/x/net/context"
@@ -22,32 +37,74 @@ import (
 
 var appContextKey = 0
 
+// streamShutdownTimeout is how long open streams are given to close when the
+// app is shutting down.
+const streamShutdownTimeout = 5 * time.Second
+
+// DefaultFeeStatsLedgers is the number of ledgers summarized by /fee_stats
+// when Config.FeeStatsLedgers is not set.
+const DefaultFeeStatsLedgers = 5
+
 // You can override this variable using: gb build -ldflags "-X main.version aabbccdd"
 var version = ""
 
 type App struct {
-	config            Config
-	web               *Web
-	historyDb         *sqlx.DB
-	coreDb            *sqlx.DB
-	ctx               context.Context
-	cancel            func()
-	redis             *redis.Pool
-	log               *logrus.Entry
-	logMetrics        *log.Metrics
-	coreVersion       string
-	horizonVersion    string
-	networkPassphrase string
-	submitter         *txsub.System
-	pump              *pump.Pump
+	config                Config
+	configLock            sync.RWMutex
+	configSource          func() (Config, error)
+	reloadLock            sync.Mutex
+	web                   *Web
+	historyDb             *sqlx.DB
+	coreDb                *sqlx.DB
+	ctx                   context.Context
+	cancel                func()
+	redis                 *redis.Pool
+	log                   *logrus.Entry
+	logMetrics            *log.Metrics
+	coreVersion           string
+	horizonVersion        string
+	networkPassphrase     string
+	submitter             *txsub.System
+	pump                  *pump.Pump
+	streams               *sse.ConnectionRegistry
+	streamHub             *sse.Hub
+	streamReplay          *sse.ReplayBuffer
+	paths                 *paths.Finder
+	cache                 *cache.Cache
+	cursors               *db.CursorCodec
+	apiKeys               *apikey.Keyring
+	webhooks              *webhook.Sender
+	friendbot             *friendbot.Bot
+	captcha               friendbot.Verifier
+	anchors               *anchors.Directory
+	inflationDestinations *inflationDestinations
+	ingester              *ingest.System
+	networkCheck          *netcheck.Checker
+	ingestedLedgers       <-chan struct{}
+	reaper                *reap.System
+	tracer                *trace.Tracer
+	keypair               *keypair
+	tlsServer             *http.Server
+	slowQueries           db.SlowQueryLog
+
+	// network names the network of Config.Networks the app serves under its
+	// parent, which serves the others and is nil for the app horizon starts
+	// with.  streamPump drives the streams of such an app.
+	network    string
+	parent     *App
+	networks   []*App
+	streamPump *sse.Pump
 
 	// metrics
 	metrics                metrics.Registry
 	horizonLedgerGauge     metrics.Gauge
 	stellarCoreLedgerGauge metrics.Gauge
+	historyLagGauge        metrics.Gauge
 	horizonConnGauge       metrics.Gauge
 	stellarCoreConnGauge   metrics.Gauge
 	goroutineGauge         metrics.Gauge
+	sseConnGauge           metrics.Gauge
+	sseClientGauge         metrics.Gauge
 }
 
 func SetVersion(v string) {
@@ -66,6 +123,9 @@ func NewApp(config Config) (*App, error) {
 	result := &App{config: config}
 	result.horizonVersion = version
 	result.networkPassphrase = build.DefaultNetwork.Passphrase
+	if config.NetworkPassphrase != "" {
+		result.networkPassphrase = config.NetworkPassphrase
+	}
 	appInit.Run(result)
 
 	return result, nil
@@ -75,26 +135,57 @@ func NewApp(config Config) (*App, error) {
 // the shutdown signals and starting the appropriate db-streaming pumps.
 func (a *App) Serve() {
 
+	// refuse to serve from a schema other than the one this binary expects,
+	// whose queries would fail or mislead.
+	err := schema.Check(a.historyDb)
+	if err != nil {
+		log.Panic(a.ctx, err)
+	}
+
 	a.web.router.Compile()
-	http.Handle("/", a.web.router)
+	a.serveNetworks()
 
 	listenStr := fmt.Sprintf(":%d", a.config.Port)
 	listener := bind.Socket(listenStr)
 	log.Infof(a.ctx, "Starting horizon on %s", listener.Addr())
 
+	if a.web.adminRouter != nil {
+		a.serveAdmin()
+	}
+
+	go a.reloadOnSignal()
+
+	if a.keypair != nil {
+		a.tlsServer = a.newTLSServer()
+	}
+
 	graceful.HandleSignals()
+	graceful.Timeout(a.config.ShutdownTimeout)
 	bind.Ready()
 	graceful.PreHook(func() {
 		log.Info(a.ctx, "received signal, gracefully stopping")
-		a.Cancel()
+		a.shutdown()
 	})
 	graceful.PostHook(func() {
+		// the requests in flight have been drained, so the subsystems they
+		// relied upon may stop
+		a.Cancel()
+		if a.tracer != nil {
+			a.tracer.Close()
+		}
 		log.Info(a.ctx, "stopped")
 	})
 
 	sse.SetPump(a.ctx, a.pump.Subscribe())
 
-	err := graceful.Serve(listener, http.DefaultServeMux)
+	// the router is served itself rather than through http.DefaultServeMux,
+	// onto which net/http/pprof registers the profiling endpoints that only
+	// the admin server may serve
+	if a.tlsServer != nil {
+		err = a.serveTLS(listener)
+	} else {
+		err = graceful.Serve(listener, a.web.router)
+	}
 
 	if err != nil {
 		log.Panic(a.ctx, err)
@@ -103,19 +194,156 @@ func (a *App) Serve() {
 	graceful.Wait()
 }
 
+// newTLSServer returns the server the public api is served with over TLS,
+// speaking HTTP/2 with the clients that offer it, whose streams then share a
+// connection rather than each taking one of the few a browser opens to a host.
+func (a *App) newTLSServer() *http.Server {
+	return &http.Server{
+		Handler: a.web.router,
+		TLSConfig: &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: a.keypair.GetCertificate,
+		},
+	}
+}
+
+// serveTLS serves the public api over TLS on listener.  graceful cannot stop
+// such a server, as the connections it tracks hide the TLS they are made over
+// from net/http, which then cannot negotiate HTTP/2: the server is shut down
+// by shutdown instead, along with the rest of the app.
+func (a *App) serveTLS(listener net.Listener) error {
+	log.Info(a.ctx, "Serving over TLS, HTTP/2 enabled")
+
+	err := a.tlsServer.ServeTLS(listener, "", "")
+	if err == http.ErrServerClosed {
+		return nil
+	}
+
+	return err
+}
+
+// serveAdmin starts the admin server on Config.AdminAddr, stopped gracefully
+// along with the public one.
+func (a *App) serveAdmin() {
+	a.web.adminRouter.Compile()
+
+	listener, err := net.Listen("tcp", a.config.AdminAddr)
+	if err != nil {
+		log.Panic(a.ctx, err)
+	}
+	log.Infof(a.ctx, "Starting admin server on %s", listener.Addr())
+
+	go func() {
+		err := graceful.Serve(listener, a.web.adminRouter)
+		if err != nil {
+			log.Error(a.ctx, err)
+		}
+	}()
+}
+
+// serveNetworks readies the apps of the networks served under the paths of
+// the app to serve alongside it, driving their streams by their own pumps.
+func (a *App) serveNetworks() {
+	for _, network := range a.networks {
+		err := schema.Check(network.historyDb)
+		if err != nil {
+			log.Panic(network.ctx, err)
+		}
+
+		network.web.router.Compile()
+		network.streamPump = sse.NewPump(network.ctx, network.pump.Subscribe())
+		log.Infof(a.ctx, "Serving network %s on /%s", network.network, network.network)
+	}
+}
+
 // Cancel triggers the app's cancellation signal, which will trigger the shutdown
-// of all child subsystems.  Note connections to external systems (such as db
-// connections) are not closed.  Use `Close()` to force immediate closure of
-// those resources
+// of all child subsystems, those of the networks it serves included.  Note
+// connections to external systems (such as db connections) are not closed.
+// Use `Close()` to force immediate closure of those resources
 func (a *App) Cancel() {
+	for _, network := range a.networks {
+		network.Cancel()
+	}
 	a.cancel()
 }
 
 // Close cancels the app and forces the closure of db connections
 func (a *App) Close() {
 	a.Cancel()
+	for _, network := range a.networks {
+		network.historyDb.Close()
+		network.coreDb.Close()
+	}
 	a.historyDb.Close()
 	a.coreDb.Close()
+	if a.tracer != nil {
+		a.tracer.Close()
+	}
+}
+
+// shutdown readies the app to stop, before its listeners are closed and the
+// requests in flight are drained, all within Config.ShutdownTimeout.  Open and
+// queued submissions are answered, the clients of streams are told goodbye and
+// ingestion stops once the ledger it is writing is committed, so that the
+// next horizon carries on from a ledger written in full.  The networks served
+// under the paths of the app stop alongside it.
+func (a *App) shutdown() {
+	ctx, cancel := context.WithCancel(a.ctx)
+	if a.config.ShutdownTimeout > 0 {
+		ctx, cancel = context.WithTimeout(a.ctx, a.config.ShutdownTimeout)
+	}
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, network := range a.networks {
+		wg.Add(1)
+		go func(network *App) {
+			defer wg.Done()
+			network.stop(ctx)
+		}(network)
+	}
+	a.stop(ctx)
+	wg.Wait()
+
+	if a.tlsServer != nil {
+		err := a.tlsServer.Shutdown(ctx)
+		if err != nil {
+			log.Warnf(a.ctx, "requests did not finish before shutdown: %s", err)
+			a.tlsServer.Close()
+		}
+	}
+}
+
+// stop answers the submissions of the app, closes its streams and stops its
+// ingestion, within ctx.
+func (a *App) stop(ctx context.Context) {
+	if a.submitter != nil {
+		n := a.submitter.Shutdown(ctx)
+		log.WithField(a.ctx, "submissions", n).Info("submission system stopped")
+	}
+
+	a.shutdownStreams(ctx)
+
+	if a.ingester != nil {
+		cursor, err := a.ingester.Stop(ctx)
+		if err != nil {
+			log.Warnf(a.ctx, "ingestion did not stop before shutdown: %s", err)
+		} else {
+			log.WithField(a.ctx, "cursor", cursor).Info("ingestion stopped")
+		}
+	}
+}
+
+// shutdownStreams asks clients of open streams to reconnect, giving them up to
+// streamShutdownTimeout to do so before the app is cancelled.
+func (a *App) shutdownStreams(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, streamShutdownTimeout)
+	defer cancel()
+
+	err := a.streams.Shutdown(ctx)
+	if err != nil {
+		log.Warnf(a.ctx, "streams did not close before shutdown: %s", err)
+	}
 }
 
 // HistoryQuery returns a SqlQuery that can be embedded in a parent query
@@ -130,11 +358,80 @@ func (a *App) CoreQuery() db.SqlQuery {
 	return db.SqlQuery{DB: a.coreDb}
 }
 
+// LedgerState returns the latest ledgers known to horizon and stellar-core.
+// It is loaded at most once per pump, however many callers ask for it.
+func (a *App) LedgerState(ctx context.Context) (db.LedgerState, error) {
+	shared, err := a.streamHub.Fetch("ledger-state", func() (interface{}, error) {
+		var ls db.LedgerState
+		q := db.LedgerStateQuery{Horizon: a.HistoryQuery(), Core: a.CoreQuery()}
+		err := db.Get(ctx, q, &ls)
+		return ls, err
+	})
+	if err != nil {
+		return db.LedgerState{}, err
+	}
+
+	return shared.(db.LedgerState), nil
+}
+
+// FeeStats returns the fee statistics of the latest Config.FeeStatsLedgers
+// ledgers.  Like LedgerState, it is computed at most once per pump.
+func (a *App) FeeStats(ctx context.Context) (db.FeeStats, error) {
+	shared, err := a.streamHub.Fetch("fee-stats", func() (interface{}, error) {
+		ledgers := int32(a.config.FeeStatsLedgers)
+		if ledgers <= 0 {
+			ledgers = DefaultFeeStatsLedgers
+		}
+
+		var stats db.FeeStats
+		q := db.FeeStatsQuery{Horizon: a.HistoryQuery(), Core: a.CoreQuery(), Ledgers: ledgers}
+		err := db.Get(ctx, q, &stats)
+		return stats, err
+	})
+	if err != nil {
+		return db.FeeStats{}, err
+	}
+
+	return shared.(db.FeeStats), nil
+}
+
+// AssetStats returns the statistics of every asset issued on the network, as
+// of the latest ledger known to stellar-core.  Aggregating them reads every
+// trustline of stellar-core, so like LedgerState they are loaded at most once
+// per pump and paged by CoreAssetStatPageQuery.
+func (a *App) AssetStats(ctx context.Context) ([]db.CoreAssetStatRecord, error) {
+	shared, err := a.streamHub.Fetch("asset-stats", func() (interface{}, error) {
+		var stats []db.CoreAssetStatRecord
+		err := db.Select(ctx, db.CoreAssetStatsQuery{SqlQuery: a.CoreQuery()}, &stats)
+		return stats, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return shared.([]db.CoreAssetStatRecord), nil
+}
+
+// StreamStatus returns the status periodically sent to streaming clients.
+func (a *App) StreamStatus(ctx context.Context) (StatusResource, error) {
+	ls, err := a.LedgerState(ctx)
+	if err != nil {
+		return StatusResource{}, err
+	}
+
+	return NewStatusResource(ls, time.Now()), nil
+}
+
 // UpdateMetrics triggers a refresh of several metrics gauges, such as open
-// db connections and ledger state
+// db connections and ledger state, of the app and the networks it serves.
 func (a *App) UpdateMetrics(ctx context.Context) {
+	for _, network := range a.networks {
+		network.UpdateMetrics(ctx)
+	}
 
 	a.goroutineGauge.Update(int64(runtime.NumGoroutine()))
+	a.sseConnGauge.Update(int64(a.streams.Count()))
+	a.sseClientGauge.Update(int64(a.streams.ClientCount()))
 
 	var ls db.LedgerState
 	q := db.LedgerStateQuery{a.HistoryQuery(), a.CoreQuery()}
@@ -150,6 +447,14 @@ func (a *App) UpdateMetrics(ctx context.Context) {
 	a.horizonLedgerGauge.Update(int64(ls.HorizonSequence))
 	a.stellarCoreLedgerGauge.Update(int64(ls.StellarCoreSequence))
 
+	lag := ls.StellarCoreSequence - ls.HorizonSequence
+	if lag < 0 {
+		lag = 0
+	}
+	a.historyLagGauge.Update(int64(lag))
+
 	a.horizonConnGauge.Update(int64(a.historyDb.Stats().OpenConnections))
 	a.stellarCoreConnGauge.Update(int64(a.coreDb.Stats().OpenConnections))
+	db.PoolOf(a.historyDb).Update()
+	db.PoolOf(a.coreDb).Update()
 }
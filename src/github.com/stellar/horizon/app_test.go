@@ -142,6 +142,19 @@ func TestApp(t *testing.T) {
 		So(app.horizonLedgerGauge.Value(), ShouldEqual, 3)
 		So(app.stellarCoreLedgerGauge.Value(), ShouldEqual, 3)
 	})
+
+	Convey("app.StreamStatus", t, func() {
+		test.LoadScenario("base")
+		app := NewTestApp()
+		defer app.Close()
+
+		status, err := app.StreamStatus(test.Context())
+		So(err, ShouldBeNil)
+		So(status.HorizonSequence, ShouldEqual, 3)
+		So(status.StellarCoreSequence, ShouldEqual, 3)
+		So(status.IngestionLag, ShouldEqual, 0)
+		So(status.ServerTime.IsZero(), ShouldBeFalse)
+	})
 }
 
 func shouldHaveASentryHook(actual interface{}, options ...interface{}) string {
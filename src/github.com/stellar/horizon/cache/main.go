@@ -0,0 +1,270 @@
+// Package cache holds the results of the read queries of the db package, so
+// that loading the same resource again need not make a trip to the database.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/golang/groupcache/lru"
+	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/log"
+	"golang.org/x/net/context"
+)
+
+const (
+	// DefaultSize is the count of query results a Cache holds in memory when
+	// Size is zero.
+	DefaultSize = 10000
+
+	// DefaultTTL is how long the redis tier of a Cache holds the results of
+	// immutable queries when TTL is zero.
+	DefaultTTL = 24 * time.Hour
+
+	// DefaultPrefix prefixes the redis keys of a Cache when Prefix is empty.
+	DefaultPrefix = "horizon:cache:"
+)
+
+// Cache holds the results of db queries.  The results of immutable queries,
+// those of resources that never change once found such as closed ledgers and
+// the transactions and operations within them, are held until evicted to make
+// room for others.  The results of mutable queries, such as those of accounts
+// and order books, are held until the next call to Invalidate, made as each
+// ledger is ingested.
+//
+// The zero value is ready to use, and a Cache is safe for concurrent access.
+// A nil Cache holds nothing, its queries always making the trip to the
+// database.
+type Cache struct {
+	// Size is the most query results held in memory at once.
+	Size int
+
+	// Redis, when set, is a second tier holding the results of immutable
+	// queries, shared by every horizon using it.  The results of mutable
+	// queries are only ever held in memory, as each horizon ingests, and
+	// invalidates them, on its own time.
+	Redis *redis.Pool
+
+	// Prefix prefixes the keys of the results held in Redis.
+	Prefix string
+
+	// TTL is how long Redis holds the results of an immutable query.
+	TTL time.Duration
+
+	lock       sync.Mutex
+	entries    *lru.Cache
+	generation uint64
+}
+
+type entry struct {
+	value      reflect.Value
+	mutable    bool
+	generation uint64
+}
+
+// Immutable returns a query loading the results of q through c.  Results are
+// only held when some are found, as a resource missing now may be ingested
+// later.
+func (c *Cache) Immutable(q db.Query) db.Query {
+	if c == nil {
+		return q
+	}
+
+	return &query{cache: c, query: q}
+}
+
+// Mutable returns a query loading the results of q through c, held until the
+// next Invalidate.
+func (c *Cache) Mutable(q db.Query) db.Query {
+	if c == nil {
+		return q
+	}
+
+	return &query{cache: c, query: q, mutable: true}
+}
+
+// Invalidate forgets the results of every mutable query
+func (c *Cache) Invalidate() {
+	if c == nil {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.generation++
+}
+
+// lookup returns the results held for key, and the generation of mutable
+// results loaded now.
+func (c *Cache) lookup(key string) (reflect.Value, bool, uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.entries == nil {
+		return reflect.Value{}, false, c.generation
+	}
+
+	found, ok := c.entries.Get(key)
+	if !ok {
+		return reflect.Value{}, false, c.generation
+	}
+
+	e := found.(entry)
+	if e.mutable && e.generation != c.generation {
+		c.entries.Remove(key)
+		return reflect.Value{}, false, c.generation
+	}
+
+	return e.value, true, c.generation
+}
+
+func (c *Cache) store(key string, e entry) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	// results loaded before the last Invalidate are stale already
+	if e.mutable && e.generation != c.generation {
+		return
+	}
+
+	if c.entries == nil {
+		size := c.Size
+		if size == 0 {
+			size = DefaultSize
+		}
+		c.entries = lru.New(size)
+	}
+
+	c.entries.Add(key, e)
+}
+
+// redisKey returns the key under which Redis holds the results for key
+func (c *Cache) redisKey(key string) string {
+	prefix := c.Prefix
+	if prefix == "" {
+		prefix = DefaultPrefix
+	}
+
+	sum := sha256.Sum256([]byte(key))
+	return prefix + hex.EncodeToString(sum[:])
+}
+
+// loadRedis populates dest with the results Redis holds for key, returning
+// true if it held some.  Failing to reach Redis is no error: the query is
+// made against the database instead.
+func (c *Cache) loadRedis(ctx context.Context, key string, dest interface{}) bool {
+	conn := c.Redis.Get()
+	defer conn.Close()
+
+	data, err := redis.Bytes(conn.Do("GET", c.redisKey(key)))
+	if err == redis.ErrNil {
+		return false
+	}
+	if err != nil {
+		log.WithField(ctx, "err", err.Error()).Warn("failed to load query results from redis")
+		return false
+	}
+
+	return json.Unmarshal(data, dest) == nil
+}
+
+func (c *Cache) storeRedis(ctx context.Context, key string, results interface{}) {
+	data, err := json.Marshal(results)
+	if err != nil {
+		return
+	}
+
+	ttl := c.TTL
+	if ttl == 0 {
+		ttl = DefaultTTL
+	}
+
+	conn := c.Redis.Get()
+	defer conn.Close()
+
+	_, err = conn.Do("SET", c.redisKey(key), data, "EX", int64(ttl/time.Second))
+	if err != nil {
+		log.WithField(ctx, "err", err.Error()).Warn("failed to store query results in redis")
+	}
+}
+
+// query is a db.Query loading its results through a Cache
+type query struct {
+	cache   *Cache
+	query   db.Query
+	mutable bool
+}
+
+// Select is a method for db.Query.  dest is populated with a copy of the
+// results held, so that callers may modify it.
+func (q *query) Select(ctx context.Context, dest interface{}) error {
+	dv := reflect.Indirect(reflect.ValueOf(dest))
+	if dv.Kind() != reflect.Slice {
+		return q.query.Select(ctx, dest)
+	}
+
+	key, err := signature(q.query, dest)
+	if err != nil {
+		return q.query.Select(ctx, dest)
+	}
+
+	held, ok, generation := q.cache.lookup(key)
+	if ok {
+		dv.Set(clone(held))
+		return nil
+	}
+
+	if !q.mutable && q.cache.Redis != nil && q.cache.loadRedis(ctx, key, dest) {
+		q.cache.store(key, entry{value: clone(dv)})
+		return nil
+	}
+
+	err = q.query.Select(ctx, dest)
+	if err != nil {
+		return err
+	}
+
+	if !q.mutable && dv.Len() == 0 {
+		return nil
+	}
+
+	q.cache.store(key, entry{value: clone(dv), mutable: q.mutable, generation: generation})
+	if !q.mutable && q.cache.Redis != nil {
+		q.cache.storeRedis(ctx, key, dest)
+	}
+
+	return nil
+}
+
+// MarshalJSON marshals the query q loads the results of, so that the
+// signature of q is that of the query itself.
+func (q *query) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type  string
+		Query db.Query
+	}{fmt.Sprintf("%T", q.query), q.query})
+}
+
+// signature returns a key that is identical for queries of the same type and
+// parameters, loading the same type of records.
+func signature(q db.Query, dest interface{}) (string, error) {
+	params, err := json.Marshal(q)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%T:%T:%s", q, dest, params), nil
+}
+
+// clone returns a copy of the slice v
+func clone(v reflect.Value) reflect.Value {
+	result := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+	reflect.Copy(result, v)
+	return result
+}
@@ -0,0 +1,110 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/test"
+	"golang.org/x/net/context"
+)
+
+type countingQuery struct {
+	ID      int
+	Results []int `json:"-"`
+	calls   *int
+}
+
+func (q countingQuery) Select(ctx context.Context, dest interface{}) error {
+	*q.calls++
+	*dest.(*[]int) = append([]int{}, q.Results...)
+	return nil
+}
+
+func TestCache(t *testing.T) {
+	ctx := test.Context()
+
+	Convey("Cache", t, func() {
+		c := &Cache{}
+		calls := 0
+		q := countingQuery{ID: 1, Results: []int{1, 2}, calls: &calls}
+
+		Convey("holds the results of immutable queries", func() {
+			var results []int
+			So(db.Select(ctx, c.Immutable(q), &results), ShouldBeNil)
+			So(db.Select(ctx, c.Immutable(q), &results), ShouldBeNil)
+			So(results, ShouldResemble, []int{1, 2})
+			So(calls, ShouldEqual, 1)
+
+			c.Invalidate()
+			So(db.Select(ctx, c.Immutable(q), &results), ShouldBeNil)
+			So(calls, ShouldEqual, 1)
+
+			other := countingQuery{ID: 2, Results: []int{3}, calls: &calls}
+			So(db.Select(ctx, c.Immutable(other), &results), ShouldBeNil)
+			So(results, ShouldResemble, []int{3})
+			So(calls, ShouldEqual, 2)
+		})
+
+		Convey("holds immutable queries only once they find results", func() {
+			q.Results = nil
+
+			var results []int
+			So(db.Select(ctx, c.Immutable(q), &results), ShouldBeNil)
+			So(db.Select(ctx, c.Immutable(q), &results), ShouldBeNil)
+			So(calls, ShouldEqual, 2)
+		})
+
+		Convey("holds the results of mutable queries until invalidated", func() {
+			var results []int
+			So(db.Select(ctx, c.Mutable(q), &results), ShouldBeNil)
+			So(db.Select(ctx, c.Mutable(q), &results), ShouldBeNil)
+			So(calls, ShouldEqual, 1)
+
+			c.Invalidate()
+			So(db.Select(ctx, c.Mutable(q), &results), ShouldBeNil)
+			So(calls, ShouldEqual, 2)
+		})
+
+		Convey("returns copies of the results held", func() {
+			var results []int
+			So(db.Select(ctx, c.Immutable(q), &results), ShouldBeNil)
+			results[0] = 99
+
+			So(db.Select(ctx, c.Immutable(q), &results), ShouldBeNil)
+			So(results, ShouldResemble, []int{1, 2})
+		})
+
+		Convey("holds nothing when nil", func() {
+			var nilCache *Cache
+			var results []int
+			So(db.Select(ctx, nilCache.Immutable(q), &results), ShouldBeNil)
+			So(db.Select(ctx, nilCache.Mutable(q), &results), ShouldBeNil)
+			So(calls, ShouldEqual, 2)
+		})
+	})
+
+	Convey("Cache with Redis", t, func() {
+		pool := &redis.Pool{
+			MaxIdle:     1,
+			IdleTimeout: 10 * time.Second,
+			Dial:        func() (redis.Conn, error) { return redis.Dial("tcp", "127.0.0.1:6379") },
+		}
+		defer pool.Close()
+
+		prefix := "horizon:test:cache:"
+		calls := 0
+		q := countingQuery{ID: int(time.Now().UnixNano() % 1000000), Results: []int{1, 2}, calls: &calls}
+
+		var results []int
+		first := &Cache{Redis: pool, Prefix: prefix}
+		So(db.Select(ctx, first.Immutable(q), &results), ShouldBeNil)
+
+		second := &Cache{Redis: pool, Prefix: prefix}
+		So(db.Select(ctx, second.Immutable(q), &results), ShouldBeNil)
+		So(results, ShouldResemble, []int{1, 2})
+		So(calls, ShouldEqual, 1)
+	})
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/stellar/go-stellar-base/build"
+	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/db/schema"
+	"github.com/stellar/horizon/ingest"
+	"golang.org/x/net/context"
+)
+
+var dbCmd = &cobra.Command{
+	Use:   "db [command]",
+	Short: "commands to manage horizon's history database",
+}
+
+var dbIndexCmd = &cobra.Command{
+	Use:   "index",
+	Short: "creates the indexes deep pages of history need, without blocking ingestion",
+	Run:   createPagingIndexes,
+}
+
+var dbMigrateCmd = &cobra.Command{
+	Use:   "migrate [command]",
+	Short: "commands to migrate the schema horizon keeps beyond that of horizon-importer",
+}
+
+var dbMigrateUpCmd = &cobra.Command{
+	Use:   "up [count]",
+	Short: "applies the migrations not yet applied, or the next [count] of them",
+	Run:   migrateUp,
+}
+
+var dbMigrateDownCmd = &cobra.Command{
+	Use:   "down [count]",
+	Short: "reverts the newest migration applied, or the newest [count] of them",
+	Run:   migrateDown,
+}
+
+var dbMigrateRedoCmd = &cobra.Command{
+	Use:   "redo",
+	Short: "reverts the newest migration applied and applies it again",
+	Run:   migrateRedo,
+}
+
+var dbMigrateStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "prints the version of the schema and the migrations applied to it",
+	Run:   migrateStatus,
+}
+
+var dbReingestCmd = &cobra.Command{
+	Use:   "reingest [command]",
+	Short: "commands to rewrite the history of ledgers from stellar-core or a history archive",
+}
+
+var dbReingestRangeCmd = &cobra.Command{
+	Use:   "range <from> <to>",
+	Short: "rewrites the history of the ledgers from <from> to <to>, inclusive",
+	Run:   reingestRange,
+}
+
+func init() {
+	viper.BindEnv("network-passphrase", "NETWORK_PASSPHRASE")
+	viper.BindEnv("history-archive-url", "HISTORY_ARCHIVE_URL")
+
+	dbReingestRangeCmd.Flags().Int(
+		"parallel-workers",
+		1,
+		"the count of ranges of ledgers rewritten at once, each in its own db transaction",
+	)
+
+	dbReingestRangeCmd.Flags().String(
+		"network-passphrase",
+		build.DefaultNetwork.Passphrase,
+		"the passphrase of the network whose ledgers are rewritten",
+	)
+
+	dbReingestRangeCmd.Flags().String(
+		"history-archive-url",
+		"",
+		"history archive to load ledgers from rather than stellar-core: http(s)://, s3://, gs:// or file:// urls",
+	)
+
+	viper.BindPFlags(dbReingestRangeCmd.Flags())
+
+	dbReingestCmd.AddCommand(dbReingestRangeCmd)
+	dbCmd.AddCommand(dbReingestCmd)
+	dbCmd.AddCommand(dbIndexCmd)
+
+	dbMigrateCmd.AddCommand(dbMigrateUpCmd)
+	dbMigrateCmd.AddCommand(dbMigrateDownCmd)
+	dbMigrateCmd.AddCommand(dbMigrateRedoCmd)
+	dbMigrateCmd.AddCommand(dbMigrateStatusCmd)
+	dbCmd.AddCommand(dbMigrateCmd)
+}
+
+func createPagingIndexes(cmd *cobra.Command, args []string) {
+	if viper.GetString("db-url") == "" {
+		cmd.Help()
+		log.Fatal("--db-url is required")
+	}
+
+	horizonDB, err := db.Open(viper.GetString("db-url"))
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	created, err := db.CreatePagingIndexes(horizonDB)
+	if err != nil {
+		log.Fatalf("created indexes %v before failing: %s", created, err)
+	}
+
+	log.Printf("created %d indexes %v", len(created), created)
+}
+
+func migrateUp(cmd *cobra.Command, args []string) {
+	migrate(cmd, args, schema.Up, 0)
+}
+
+func migrateDown(cmd *cobra.Command, args []string) {
+	migrate(cmd, args, schema.Down, 1)
+}
+
+// migrate runs the migrations of dir, as many as the count of args or count
+// when none is given.
+func migrate(cmd *cobra.Command, args []string, dir schema.Direction, count int) {
+	if len(args) > 1 {
+		cmd.Help()
+		log.Fatal("expected at most the count of migrations to run")
+	}
+
+	if len(args) == 1 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 {
+			log.Fatalf("invalid count of migrations: %s", args[0])
+		}
+		count = n
+	}
+
+	n, err := schema.Migrate(openMigrated(cmd), dir, count)
+	if err != nil {
+		log.Fatalf("ran %d migrations before failing: %s", n, err)
+	}
+
+	log.Printf("ran %d migrations", n)
+}
+
+func migrateRedo(cmd *cobra.Command, args []string) {
+	err := schema.Redo(openMigrated(cmd))
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	log.Print("redone")
+}
+
+func migrateStatus(cmd *cobra.Command, args []string) {
+	status, err := schema.GetStatus(openMigrated(cmd))
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	for _, m := range schema.Migrations() {
+		state := "pending"
+		switch {
+		case m.Version == status.Version && status.Dirty:
+			state = "dirty"
+		case m.Version <= status.Version:
+			state = "applied"
+		}
+
+		fmt.Printf("%4d %-40s %s\n", m.Version, m.Name, state)
+	}
+
+	switch {
+	case status.Dirty:
+		fmt.Printf("schema is dirty at version %d: %s\n", status.Version, schema.ErrDirty)
+	case status.Version > status.Latest:
+		fmt.Printf("schema is at version %d, ahead of this horizon's %d\n", status.Version, status.Latest)
+	case !status.Current():
+		fmt.Printf("schema is at version %d of %d\n", status.Version, status.Latest)
+	default:
+		fmt.Printf("schema is current, at version %d\n", status.Version)
+	}
+}
+
+// openMigrated opens the history database whose schema the migrate commands
+// migrate
+func openMigrated(cmd *cobra.Command) *sqlx.DB {
+	if viper.GetString("db-url") == "" {
+		cmd.Help()
+		log.Fatal("--db-url is required")
+	}
+
+	horizonDB, err := db.Open(viper.GetString("db-url"))
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	return horizonDB
+}
+
+func reingestRange(cmd *cobra.Command, args []string) {
+	if len(args) != 2 {
+		cmd.Help()
+		log.Fatal("expected the ledgers to start and end at")
+	}
+
+	from, err := strconv.ParseInt(args[0], 10, 32)
+	if err != nil {
+		log.Fatalf("invalid ledger to start at: %s", args[0])
+	}
+
+	to, err := strconv.ParseInt(args[1], 10, 32)
+	if err != nil {
+		log.Fatalf("invalid ledger to end at: %s", args[1])
+	}
+
+	archiveURL := viper.GetString("history-archive-url")
+	if viper.GetString("db-url") == "" || (archiveURL == "" && viper.GetString("stellar-core-db-url") == "") {
+		cmd.Help()
+		log.Fatal("--db-url and one of --stellar-core-db-url or --history-archive-url are required")
+	}
+
+	workers := viper.GetInt("parallel-workers")
+	passphrase := viper.GetString("network-passphrase")
+
+	horizonDB, err := db.Open(viper.GetString("db-url"))
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	horizonDB.SetMaxOpenConns(workers + 1)
+
+	sys := &ingest.System{
+		HorizonDB:         horizonDB,
+		NetworkPassphrase: passphrase,
+	}
+
+	if archiveURL != "" {
+		sys.Backend, err = ingest.NewArchiveBackend(archiveURL, passphrase)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+	} else {
+		sys.CoreDB, err = db.Open(viper.GetString("stellar-core-db-url"))
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		sys.CoreDB.SetMaxOpenConns(workers + 1)
+	}
+
+	n, err := sys.ReingestRange(context.Background(), int32(from), int32(to), workers)
+	if err != nil {
+		log.Fatalf("reingested %d ledgers before failing: %s", n, err)
+	}
+
+	log.Printf("reingested %d ledgers", n)
+}
@@ -1,15 +1,23 @@
 package main
 
 import (
+	"fmt"
 	"log"
 	"os"
+	"regexp"
 	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/PuerkitoBio/throttled"
 	"github.com/Sirupsen/logrus"
+	"github.com/spf13/cast"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/stellar/horizon"
+	"github.com/stellar/horizon/friendbot"
 	hlog "github.com/stellar/horizon/log"
 )
 
@@ -17,6 +25,10 @@ var app *horizon.App
 var rootCmd *cobra.Command
 var version string
 
+// networkName matches the names of the networks of the config file, each
+// served under the path of its name
+var networkName = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
 func main() {
 	if version != "" {
 		horizon.SetVersion(version)
@@ -28,20 +40,87 @@ func main() {
 func init() {
 	viper.SetDefault("port", 8000)
 	viper.SetDefault("autopump", false)
+	viper.SetDefault("ingest", false)
 
+	viper.BindEnv("config-file", "CONFIG_FILE")
 	viper.BindEnv("port", "PORT")
+	viper.BindEnv("admin-addr", "ADMIN_ADDR")
+	viper.BindEnv("tls-cert-file", "TLS_CERT_FILE")
+	viper.BindEnv("tls-key-file", "TLS_KEY_FILE")
+	viper.BindEnv("shutdown-timeout", "SHUTDOWN_TIMEOUT")
 	viper.BindEnv("autopump", "AUTOPUMP")
+	viper.BindEnv("ledger-notifications", "LEDGER_NOTIFICATIONS")
+	viper.BindEnv("ingest", "INGEST")
+	viper.BindEnv("ingest-processors", "INGEST_PROCESSORS")
+	viper.BindEnv("ingest-verify-interval", "INGEST_VERIFY_INTERVAL")
+	viper.BindEnv("history-retention-days", "HISTORY_RETENTION_DAYS")
 	viper.BindEnv("db-url", "DATABASE_URL")
 	viper.BindEnv("stellar-core-db-url", "STELLAR_CORE_DATABASE_URL")
+	viper.BindEnv("page-query-timeout", "PAGE_QUERY_TIMEOUT")
+	viper.BindEnv("stream-query-timeout", "STREAM_QUERY_TIMEOUT")
+	viper.BindEnv("ingest-query-timeout", "INGEST_QUERY_TIMEOUT")
+	viper.BindEnv("request-timeout", "REQUEST_TIMEOUT")
+	viper.BindEnv("route-timeouts", "ROUTE_TIMEOUTS")
+	viper.BindEnv("slow-query-threshold", "SLOW_QUERY_THRESHOLD")
+	viper.BindEnv("slow-query-log-file", "SLOW_QUERY_LOG_FILE")
 	viper.BindEnv("stellar-core-url", "STELLAR_CORE_URL")
+	viper.BindEnv("network-passphrase", "NETWORK_PASSPHRASE")
+	viper.BindEnv("network-check-interval", "NETWORK_CHECK_INTERVAL")
+	viper.BindEnv("txsub-core-urls", "TXSUB_CORE_URLS")
 	viper.BindEnv("friendbot-secret", "FRIENDBOT_SECRET")
+	viper.BindEnv("friendbot-amount", "FRIENDBOT_AMOUNT")
+	viper.BindEnv("friendbot-per-hour-rate-limit", "FRIENDBOT_PER_HOUR_RATE_LIMIT")
+	viper.BindEnv("friendbot-per-account-per-hour-rate-limit", "FRIENDBOT_PER_ACCOUNT_PER_HOUR_RATE_LIMIT")
+	viper.BindEnv("friendbot-captcha-url", "FRIENDBOT_CAPTCHA_URL")
+	viper.BindEnv("friendbot-captcha-secret", "FRIENDBOT_CAPTCHA_SECRET")
 	viper.BindEnv("per-hour-rate-limit", "PER_HOUR_RATE_LIMIT")
+	viper.BindEnv("rate-limit-burst", "RATE_LIMIT_BURST")
+	viper.BindEnv("api-keys-file", "API_KEYS_FILE")
+	viper.BindEnv("api-keys-db", "API_KEYS_DB")
+	viper.BindEnv("api-keys-required", "API_KEYS_REQUIRED")
 	viper.BindEnv("redis-url", "REDIS_URL")
+	viper.BindEnv("query-cache-size", "QUERY_CACHE_SIZE")
+	viper.BindEnv("query-cache-redis", "QUERY_CACHE_REDIS")
 	viper.BindEnv("ruby-horizon-url", "RUBY_HORIZON_URL")
 	viper.BindEnv("log-level", "LOG_LEVEL")
+	viper.BindEnv("log-format", "LOG_FORMAT")
+	viper.BindEnv("log-fields", "LOG_FIELDS")
 	viper.BindEnv("sentry-dsn", "SENTRY_DSN")
 	viper.BindEnv("loggly-token", "LOGGLY_TOKEN")
 	viper.BindEnv("loggly-host", "LOGGLY_HOST")
+	viper.BindEnv("tracing-otlp-url", "TRACING_OTLP_URL")
+	viper.BindEnv("tracing-sample-rate", "TRACING_SAMPLE_RATE")
+	viper.BindEnv("tracing-service-name", "TRACING_SERVICE_NAME")
+	viper.BindEnv("sse-heartbeat", "SSE_HEARTBEAT")
+	viper.BindEnv("sse-max-connections", "SSE_MAX_CONNECTIONS")
+	viper.BindEnv("sse-max-connections-per-ip", "SSE_MAX_CONNECTIONS_PER_IP")
+	viper.BindEnv("sse-compression", "SSE_COMPRESSION")
+	viper.BindEnv("sse-max-retry", "SSE_MAX_RETRY")
+	viper.BindEnv("sse-replay-size", "SSE_REPLAY_SIZE")
+	viper.BindEnv("sse-replay-window", "SSE_REPLAY_WINDOW")
+	viper.BindEnv("sse-status-interval", "SSE_STATUS_INTERVAL")
+	viper.BindEnv("sse-buffer-size", "SSE_BUFFER_SIZE")
+	viper.BindEnv("cors-allowed-origins", "CORS_ALLOWED_ORIGINS")
+	viper.BindEnv("cors-allowed-headers", "CORS_ALLOWED_HEADERS")
+	viper.BindEnv("cors-allow-credentials", "CORS_ALLOW_CREDENTIALS")
+	viper.BindEnv("cursor-secret", "CURSOR_SECRET")
+	viper.BindEnv("cursor-accept-legacy", "CURSOR_ACCEPT_LEGACY")
+	viper.BindEnv("jsonp", "JSONP")
+	viper.BindEnv("path-max-length", "PATH_MAX_LENGTH")
+	viper.BindEnv("fee-stats-ledgers", "FEE_STATS_LEDGERS")
+	viper.BindEnv("history-stale-threshold", "HISTORY_STALE_THRESHOLD")
+	viper.BindEnv("federation-resolution", "FEDERATION_RESOLUTION")
+	viper.BindEnv("anchor-metadata", "ANCHOR_METADATA")
+	viper.BindEnv("tx-resubmit-ledgers", "TX_RESUBMIT_LEDGERS")
+	viper.BindEnv("tx-resubmit-max-attempts", "TX_RESUBMIT_MAX_ATTEMPTS")
+	viper.BindEnv("txsub-per-hour-rate-limit", "TXSUB_PER_HOUR_RATE_LIMIT")
+	viper.BindEnv("txsub-per-account-per-hour-rate-limit", "TXSUB_PER_ACCOUNT_PER_HOUR_RATE_LIMIT")
+	viper.BindEnv("txsub-max-open", "TXSUB_MAX_OPEN")
+	viper.BindEnv("txsub-max-operations", "TXSUB_MAX_OPERATIONS")
+	viper.BindEnv("txsub-max-signatures", "TXSUB_MAX_SIGNATURES")
+	viper.BindEnv("txsub-max-fee", "TXSUB_MAX_FEE")
+	viper.BindEnv("txsub-max-delay", "TXSUB_MAX_DELAY")
+	viper.BindEnv("callback-secret", "CALLBACK_SECRET")
 
 	rootCmd = &cobra.Command{
 		Use:   "horizon",
@@ -50,13 +129,13 @@ func init() {
 		Run:   run,
 	}
 
-	rootCmd.Flags().String(
+	rootCmd.PersistentFlags().String(
 		"db-url",
 		"",
 		"horizon postgres database to connect with",
 	)
 
-	rootCmd.Flags().String(
+	rootCmd.PersistentFlags().String(
 		"stellar-core-db-url",
 		"",
 		"stellar-core postgres database to connect with",
@@ -68,28 +147,184 @@ func init() {
 		"stellar-core to connect with (for http commands)",
 	)
 
+	rootCmd.Flags().String(
+		"network-passphrase",
+		"",
+		"passphrase of the stellar network stellar-core is connected to. the test network's when empty",
+	)
+
+	rootCmd.Flags().Int(
+		"network-check-interval",
+		60,
+		"seconds between the checks that stellar-core and its db are on the configured network, made on startup too. ingestion and submissions are refused while they are not. 0 checks on startup only",
+	)
+
+	rootCmd.Flags().String(
+		"txsub-core-urls",
+		"",
+		"comma-separated list of further stellar-core instances to submit transactions to",
+	)
+
+	rootCmd.Flags().String(
+		"config-file",
+		"",
+		"toml file of settings named as flags are, such as log-level = \"debug\", overridden by flags and env vars given. Its log level, rate limits and sse heartbeat are reloaded on SIGHUP. Its [networks.<name>] tables, of a db-url, stellar-core-db-url, stellar-core-url, network-passphrase, ingest and friendbot-secret each, configure further networks served under /<name>",
+	)
+
 	rootCmd.Flags().Int(
 		"port",
 		8000,
 		"tcp port to listen on for http requests",
 	)
 
+	rootCmd.Flags().String(
+		"admin-addr",
+		"",
+		"address, such as 127.0.0.1:8001, to serve metrics, profiling, ingestion controls and config of on, apart from the public api; they are served publicly when empty",
+	)
+
+	rootCmd.Flags().String(
+		"tls-cert-file",
+		"",
+		"pem encoded certificate (chain) to serve the api with over TLS and HTTP/2, reloaded once renewed on disk; the api is served in the clear when empty",
+	)
+
+	rootCmd.Flags().String(
+		"tls-key-file",
+		"",
+		"pem encoded private key of tls-cert-file",
+	)
+
+	rootCmd.Flags().Int(
+		"shutdown-timeout",
+		30,
+		"seconds horizon, once signalled to stop, gives in-flight requests and ingestion to finish before closing their connections. 0 waits for them however long they take",
+	)
+
 	rootCmd.Flags().Bool(
 		"autopump",
 		false,
 		"pump streams every second, instead of once per ledger close",
 	)
 
+	rootCmd.Flags().Bool(
+		"ledger-notifications",
+		false,
+		"notify the ledgers ingested over postgres LISTEN/NOTIFY, or when not ingesting, listen for them rather than checking for new ledgers every second",
+	)
+
+	rootCmd.Flags().Bool(
+		"ingest",
+		false,
+		"ingest the ledgers closed by stellar-core into the history database",
+	)
+
+	rootCmd.Flags().String(
+		"ingest-processors",
+		"",
+		"the processors deciding which transactions are ingested, such as \"accounts=GABC...,GDEF...;assets=USD:GHIJ...\". all are ingested when empty",
+	)
+
+	rootCmd.Flags().Int(
+		"ingest-verify-interval",
+		10,
+		"minutes between the comparisons of a sample of accounts recomputed from ingested history with stellar-core. 0 disables verification",
+	)
+
+	rootCmd.Flags().Int(
+		"page-query-timeout",
+		30,
+		"seconds a database query loading the response to a request may run before it is cancelled. 0 disables the timeout",
+	)
+
+	rootCmd.Flags().Int(
+		"stream-query-timeout",
+		10,
+		"seconds a database query polling for the events of a stream may run before it is cancelled. 0 disables the timeout",
+	)
+
+	rootCmd.Flags().Int(
+		"ingest-query-timeout",
+		0,
+		"seconds a database statement run by ingestion may run before it is cancelled. 0 disables the timeout",
+	)
+
+	rootCmd.Flags().Int(
+		"request-timeout",
+		30,
+		"seconds a request, streams aside, may take to be answered before it is cut off with a timeout problem. 0 disables the timeout",
+	)
+
+	rootCmd.Flags().String(
+		"route-timeouts",
+		"",
+		"timeouts of routes apart from request-timeout, as route=seconds pairs such as \"/paths/strict-receive=60,/order_book=10\". 0 disables the timeout of a route",
+	)
+
+	rootCmd.Flags().Int(
+		"slow-query-threshold",
+		0,
+		"milliseconds a database query may run before it is recorded, with the route and params of its request, into the slow query log. 0 disables the log",
+	)
+
+	rootCmd.Flags().String(
+		"slow-query-log-file",
+		"",
+		"file the slow query log is appended to, apart from the log of horizon. Slow queries are logged along with the rest when empty",
+	)
+
+	rootCmd.Flags().Int(
+		"history-retention-days",
+		0,
+		"the days of history to keep, older history being reaped. 0 keeps all history",
+	)
+
 	rootCmd.Flags().Int(
 		"per-hour-rate-limit",
 		3600,
 		"max count of requests allowed in a one hour period, by remote ip address",
 	)
 
+	rootCmd.Flags().Int(
+		"rate-limit-burst",
+		0,
+		"max count of requests allowed at once, by remote ip address, their allowance refilling at the per hour rate limit. 0 allows an hour's worth",
+	)
+
+	rootCmd.Flags().String(
+		"api-keys-file",
+		"",
+		"toml file of the api keys clients may give in the X-API-Key header, each with its own rate limit and the endpoints it may request",
+	)
+
+	rootCmd.Flags().Bool(
+		"api-keys-db",
+		false,
+		"load api keys from the horizon_api_keys table of the history database too",
+	)
+
+	rootCmd.Flags().Bool(
+		"api-keys-required",
+		false,
+		"refuse requests made without an api key, rather than limiting them by remote ip address",
+	)
+
 	rootCmd.Flags().String(
 		"redis-url",
 		"",
-		"redis to connect with, for rate limiting",
+		"redis to connect with, for rate limiting and, with --query-cache-redis, caching query results",
+	)
+
+	rootCmd.Flags().Int(
+		"query-cache-size",
+		10000,
+		"count of query results, such as closed ledgers and current accounts, kept in memory to serve again. 0 disables the cache",
+	)
+
+	rootCmd.Flags().Bool(
+		"query-cache-redis",
+		false,
+		"share the results of the queries of closed ledgers, transactions and operations between horizons through redis",
 	)
 
 	rootCmd.Flags().String(
@@ -104,6 +339,18 @@ func init() {
 		"Minimum log severity (debug, info, warn, error) to log",
 	)
 
+	rootCmd.Flags().String(
+		"log-format",
+		"text",
+		"format of log lines: text, or json for log aggregators to parse",
+	)
+
+	rootCmd.Flags().String(
+		"log-fields",
+		"",
+		"comma separated key=value fields, such as service=horizon, included in every log line",
+	)
+
 	rootCmd.Flags().String(
 		"sentry-dsn",
 		"",
@@ -122,13 +369,259 @@ func init() {
 		"Hostname to be added to every loggly log event",
 	)
 
+	rootCmd.Flags().String(
+		"tracing-otlp-url",
+		"",
+		"OTLP/HTTP traces endpoint, such as http://localhost:4318/v1/traces of a collector or of jaeger, to which spans are exported (empty disables tracing)",
+	)
+
+	rootCmd.Flags().Float64(
+		"tracing-sample-rate",
+		1,
+		"fraction, from 0 to 1, of the traces begun by horizon that are recorded",
+	)
+
+	rootCmd.Flags().String(
+		"tracing-service-name",
+		"horizon",
+		"service name spans are exported under",
+	)
+
+	rootCmd.Flags().Int(
+		"sse-heartbeat",
+		15,
+		"seconds a stream may be idle before a keepalive is sent (0 disables keepalives)",
+	)
+
+	rootCmd.Flags().Int(
+		"sse-max-connections",
+		0,
+		"maximum number of concurrent streaming connections (0 is unlimited)",
+	)
+
+	rootCmd.Flags().Int(
+		"sse-max-connections-per-ip",
+		0,
+		"maximum number of concurrent streaming connections from a single ip (0 is unlimited)",
+	)
+
+	rootCmd.Flags().Bool(
+		"sse-compression",
+		false,
+		"compress event streams for clients that accept gzip or deflate encoding",
+	)
+
+	rootCmd.Flags().Int(
+		"sse-max-retry",
+		0,
+		"milliseconds: when non-zero, the retry hint sent to clients as streams close rises towards this value as the server nears its maximum streaming connections",
+	)
+
+	rootCmd.Flags().Int(
+		"sse-replay-size",
+		100,
+		"number of recent events kept in memory for each stream, replayed to clients that reconnect shortly after disconnecting. 0 disables replay",
+	)
+
+	rootCmd.Flags().Int(
+		"sse-replay-window",
+		5,
+		"seconds: how long after disconnecting a client may be replayed the events it missed from memory",
+	)
+
+	rootCmd.Flags().Int(
+		"sse-status-interval",
+		30,
+		"seconds between the status events, reporting ledger state and ingestion lag, sent on every stream. 0 disables status events",
+	)
+
+	rootCmd.Flags().Int(
+		"sse-buffer-size",
+		0,
+		"number of events queued for each streaming client, which is disconnected once it falls that far behind. 0 writes events to clients directly",
+	)
+
+	rootCmd.Flags().String(
+		"cors-allowed-origins",
+		"*",
+		"comma separated list of origins allowed to make cross-origin requests, or * for any",
+	)
+
+	rootCmd.Flags().String(
+		"cors-allowed-headers",
+		"*",
+		"comma separated list of headers cross-origin requests may include, or * for any",
+	)
+
+	rootCmd.Flags().Bool(
+		"cors-allow-credentials",
+		false,
+		"allow cross-origin requests to include credentials such as cookies",
+	)
+
+	rootCmd.Flags().String(
+		"cursor-secret",
+		"",
+		"when set, paging tokens are handed to clients as opaque cursors, signed with this secret, that page only the endpoint they were found at",
+	)
+
+	rootCmd.Flags().Bool(
+		"cursor-accept-legacy",
+		true,
+		"accept raw numeric paging tokens in place of signed cursors",
+	)
+
+	rootCmd.Flags().Bool(
+		"jsonp",
+		false,
+		"allow json responses to be requested as jsonp scripts through the callback param",
+	)
+
+	rootCmd.Flags().Int(
+		"path-max-length",
+		3,
+		"the most intermediate assets a found path may pass through. clients may request shorter paths, but not longer",
+	)
+
+	rootCmd.Flags().Int(
+		"fee-stats-ledgers",
+		5,
+		"the number of latest ledgers whose transactions /fee_stats summarizes",
+	)
+
+	rootCmd.Flags().Int(
+		"history-stale-threshold",
+		0,
+		"the most ledgers history may lag behind stellar-core before requests for it are refused as stale. 0 never refuses them",
+	)
+
+	rootCmd.Flags().Bool(
+		"federation-resolution",
+		false,
+		"resolve federation addresses, such as bob*example.com, given in place of account ids",
+	)
+
+	rootCmd.Flags().Bool(
+		"anchor-metadata",
+		false,
+		"annotate assets with what the stellar.toml of their issuer's home domain publishes of them, fetched in the background",
+	)
+
+	rootCmd.Flags().Int(
+		"tx-resubmit-ledgers",
+		0,
+		"resend submitted transactions to stellar-core when not included after this many ledgers, doubling the wait after each attempt. 0 disables resubmission",
+	)
+
+	rootCmd.Flags().Int(
+		"tx-resubmit-max-attempts",
+		3,
+		"the most times a submitted transaction is resent to stellar-core",
+	)
+
+	rootCmd.Flags().Int(
+		"txsub-per-hour-rate-limit",
+		0,
+		"the most transactions an ip address may submit per hour. 0 leaves submissions to the general rate limit",
+	)
+
+	rootCmd.Flags().Int(
+		"txsub-per-account-per-hour-rate-limit",
+		0,
+		"the most transactions that may be submitted from a source account per hour. 0 disables the limit",
+	)
+
+	rootCmd.Flags().Int(
+		"txsub-max-open",
+		0,
+		"the most submissions awaiting inclusion in a ledger at once, beyond which submissions are refused. 0 disables the cap",
+	)
+
+	rootCmd.Flags().Int(
+		"txsub-max-operations",
+		0,
+		"the most operations a submitted transaction may have. 0 applies stellar-core's limit",
+	)
+
+	rootCmd.Flags().Int(
+		"txsub-max-signatures",
+		0,
+		"the most signatures a submitted transaction may have. 0 applies stellar-core's limit",
+	)
+
+	rootCmd.Flags().Int(
+		"txsub-max-fee",
+		0,
+		"the highest fee, in stroops, a submitted transaction may offer. 0 disables the ceiling",
+	)
+
+	rootCmd.Flags().Int(
+		"txsub-max-delay",
+		0,
+		"how far in the future, in seconds, the lower time bound of a submitted transaction may be. 0 disables the check",
+	)
+
+	rootCmd.Flags().String(
+		"callback-secret",
+		"",
+		"the secret callbacks of submitted transactions are signed with. callbacks are disabled when empty",
+	)
+
+	rootCmd.Flags().String(
+		"friendbot-secret",
+		"",
+		"the seed of the account friendbot funds new accounts from, on test networks. friendbot is disabled when empty",
+	)
+
+	rootCmd.Flags().String(
+		"friendbot-amount",
+		"10000",
+		"the lumens friendbot creates each account with",
+	)
+
+	rootCmd.Flags().Int(
+		"friendbot-per-hour-rate-limit",
+		10,
+		"the most accounts an ip address may ask friendbot to fund per hour. 0 leaves friendbot to the general rate limit",
+	)
+
+	rootCmd.Flags().Int(
+		"friendbot-per-account-per-hour-rate-limit",
+		1,
+		"the most times friendbot may be asked to fund an account per hour. 0 disables the limit",
+	)
+
+	rootCmd.Flags().String(
+		"friendbot-captcha-url",
+		friendbot.TurnstileURL,
+		"the siteverify endpoint friendbot checks captchas against, such as that of turnstile, hcaptcha or recaptcha",
+	)
+
+	rootCmd.Flags().String(
+		"friendbot-captcha-secret",
+		"",
+		"the secret friendbot checks captchas with. clients need not solve a captcha when empty",
+	)
+
 	viper.BindPFlags(rootCmd.Flags())
+	viper.BindPFlags(rootCmd.PersistentFlags())
+
+	rootCmd.AddCommand(dbCmd)
 }
 
 func run(cmd *cobra.Command, args []string) {
 
 	var err error
 
+	configFile := viper.GetString("config-file")
+	if configFile != "" {
+		viper.SetConfigFile(configFile)
+		err = viper.ReadInConfig()
+		if err != nil {
+			log.Fatalf("Could not read config-file: %v", err)
+		}
+	}
+
 	if viper.GetString("db-url") == "" {
 		rootCmd.Help()
 		os.Exit(1)
@@ -139,34 +632,274 @@ func run(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	config, err := loadConfig()
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	hlog.SetDefaultLoggerLevel(config.LogLevel)
+
+	lf, err := hlog.ParseFormat(config.LogFormat)
+
+	if err != nil {
+		log.Fatalf("Could not parse log-format: %v", config.LogFormat)
+	}
+
+	hlog.SetDefaultLoggerFormatter(lf)
+
+	app, err = horizon.NewApp(config)
+
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	if configFile != "" {
+		app.SetConfigSource(func() (horizon.Config, error) {
+			err := viper.ReadInConfig()
+			if err != nil {
+				return horizon.Config{}, fmt.Errorf("Could not read config-file: %v", err)
+			}
+
+			return loadConfig()
+		})
+	}
+
+	app.Serve()
+}
+
+// loadConfig builds the config of horizon from the flags, env vars and config
+// file it was given, as viper last read them.
+func loadConfig() (horizon.Config, error) {
 	ll, err := logrus.ParseLevel(viper.GetString("log-level"))
 
 	if err != nil {
-		log.Fatalf("Could not parse log-level: %v", viper.GetString("log-level"))
+		return horizon.Config{}, fmt.Errorf("Could not parse log-level: %v", viper.GetString("log-level"))
+	}
+
+	_, err = hlog.ParseFormat(viper.GetString("log-format"))
+
+	if err != nil {
+		return horizon.Config{}, fmt.Errorf("Could not parse log-format: %v", viper.GetString("log-format"))
 	}
 
-	hlog.SetDefaultLoggerLevel(ll)
+	fields, err := parseFields(viper.GetString("log-fields"))
+
+	if err != nil {
+		return horizon.Config{}, fmt.Errorf("Could not parse log-fields: %v", err)
+	}
+
+	routeTimeouts, err := parseTimeouts(viper.GetString("route-timeouts"))
+
+	if err != nil {
+		return horizon.Config{}, fmt.Errorf("Could not parse route-timeouts: %v", err)
+	}
+
+	networks, err := parseNetworks(viper.GetStringMap("networks"))
+
+	if err != nil {
+		return horizon.Config{}, fmt.Errorf("Could not parse networks: %v", err)
+	}
 
 	config := horizon.Config{
 		DatabaseUrl:            viper.GetString("db-url"),
 		StellarCoreDatabaseUrl: viper.GetString("stellar-core-db-url"),
 		StellarCoreUrl:         viper.GetString("stellar-core-url"),
+		NetworkPassphrase:      viper.GetString("network-passphrase"),
+		Networks:               networks,
+		NetworkCheckInterval:   time.Duration(viper.GetInt("network-check-interval")) * time.Second,
 		Autopump:               viper.GetBool("autopump"),
+		LedgerNotifications:    viper.GetBool("ledger-notifications"),
+		Ingest:                 viper.GetBool("ingest"),
+		IngestProcessors:       viper.GetString("ingest-processors"),
+		IngestVerifyInterval:   time.Duration(viper.GetInt("ingest-verify-interval")) * time.Minute,
+		HistoryRetention:       time.Duration(viper.GetInt("history-retention-days")) * 24 * time.Hour,
+		PageQueryTimeout:       time.Duration(viper.GetInt("page-query-timeout")) * time.Second,
+		StreamQueryTimeout:     time.Duration(viper.GetInt("stream-query-timeout")) * time.Second,
+		IngestQueryTimeout:     time.Duration(viper.GetInt("ingest-query-timeout")) * time.Second,
+		RequestTimeout:         time.Duration(viper.GetInt("request-timeout")) * time.Second,
+		RouteTimeouts:          routeTimeouts,
+		SlowQueryThreshold:     time.Duration(viper.GetInt("slow-query-threshold")) * time.Millisecond,
+		SlowQueryLogFile:       viper.GetString("slow-query-log-file"),
 		Port:                   viper.GetInt("port"),
+		AdminAddr:              viper.GetString("admin-addr"),
+		TLSCertFile:            viper.GetString("tls-cert-file"),
+		TLSKeyFile:             viper.GetString("tls-key-file"),
+		ShutdownTimeout:        time.Duration(viper.GetInt("shutdown-timeout")) * time.Second,
 		RateLimit:              throttled.PerHour(viper.GetInt("per-hour-rate-limit")),
+		RateLimitBurst:         viper.GetInt("rate-limit-burst"),
+		APIKeysFile:            viper.GetString("api-keys-file"),
+		APIKeysDB:              viper.GetBool("api-keys-db"),
+		APIKeysRequired:        viper.GetBool("api-keys-required"),
 		RedisUrl:               viper.GetString("redis-url"),
+		QueryCacheSize:         viper.GetInt("query-cache-size"),
+		QueryCacheRedis:        viper.GetBool("query-cache-redis"),
 		RubyHorizonUrl:         viper.GetString("ruby-horizon-url"),
 		LogLevel:               ll,
+		LogFormat:              viper.GetString("log-format"),
+		LogFields:              fields,
 		SentryDSN:              viper.GetString("sentry-dsn"),
 		LogglyToken:            viper.GetString("loggly-token"),
 		LogglyHost:             viper.GetString("loggly-host"),
+		TracingUrl:             viper.GetString("tracing-otlp-url"),
+		TracingSampleRate:      viper.GetFloat64("tracing-sample-rate"),
+		TracingServiceName:     viper.GetString("tracing-service-name"),
+		SSEHeartbeat:           time.Duration(viper.GetInt("sse-heartbeat")) * time.Second,
+		SSEMaxConnections:      viper.GetInt("sse-max-connections"),
+		SSEMaxConnectionsPerIP: viper.GetInt("sse-max-connections-per-ip"),
+		SSECompression:         viper.GetBool("sse-compression"),
+		SSEMaxRetry:            time.Duration(viper.GetInt("sse-max-retry")) * time.Millisecond,
+		SSEReplaySize:          viper.GetInt("sse-replay-size"),
+		SSEReplayWindow:        time.Duration(viper.GetInt("sse-replay-window")) * time.Second,
+		SSEStatusInterval:      time.Duration(viper.GetInt("sse-status-interval")) * time.Second,
+		SSEBufferSize:          viper.GetInt("sse-buffer-size"),
+		CORSAllowedOrigins:     splitList(viper.GetString("cors-allowed-origins")),
+		CORSAllowedHeaders:     splitList(viper.GetString("cors-allowed-headers")),
+		CORSAllowCredentials:   viper.GetBool("cors-allow-credentials"),
+		CursorSecret:           viper.GetString("cursor-secret"),
+		CursorAcceptLegacy:     viper.GetBool("cursor-accept-legacy"),
+		JSONP:                  viper.GetBool("jsonp"),
+		PathMaxLength:          viper.GetInt("path-max-length"),
+		FeeStatsLedgers:        viper.GetInt("fee-stats-ledgers"),
+		HistoryStaleThreshold:  viper.GetInt("history-stale-threshold"),
+		FederationResolution:   viper.GetBool("federation-resolution"),
+		AnchorMetadata:         viper.GetBool("anchor-metadata"),
+		TxResubmitLedgers:      viper.GetInt("tx-resubmit-ledgers"),
+		TxResubmitMaxAttempts:  viper.GetInt("tx-resubmit-max-attempts"),
+		TxSubRateLimit:         perHourQuota(viper.GetInt("txsub-per-hour-rate-limit")),
+		TxSubAccountRateLimit:  perHourQuota(viper.GetInt("txsub-per-account-per-hour-rate-limit")),
+		TxSubMaxOpen:           viper.GetInt("txsub-max-open"),
+		TxSubCoreUrls:          splitList(viper.GetString("txsub-core-urls")),
+		TxSubMaxOperations:     viper.GetInt("txsub-max-operations"),
+		TxSubMaxSignatures:     viper.GetInt("txsub-max-signatures"),
+		TxSubMaxFee:            viper.GetInt("txsub-max-fee"),
+		TxSubMaxDelay:          time.Duration(viper.GetInt("txsub-max-delay")) * time.Second,
+		CallbackSecret:         viper.GetString("callback-secret"),
+		FriendbotSecret:        viper.GetString("friendbot-secret"),
+		FriendbotAmount:        viper.GetString("friendbot-amount"),
+		FriendbotRateLimit:     perHourQuota(viper.GetInt("friendbot-per-hour-rate-limit")),
+		FriendbotAccountLimit:  perHourQuota(viper.GetInt("friendbot-per-account-per-hour-rate-limit")),
+		FriendbotCaptchaURL:    viper.GetString("friendbot-captcha-url"),
+		FriendbotCaptchaSecret: viper.GetString("friendbot-captcha-secret"),
 	}
 
-	app, err = horizon.NewApp(config)
+	return config, nil
+}
 
-	if err != nil {
-		log.Fatal(err.Error())
+// splitList splits a comma separated list, as provided by a flag, into its
+// trimmed and non-empty elements.
+func splitList(list string) []string {
+	var result []string
+
+	for _, item := range strings.Split(list, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			result = append(result, item)
+		}
 	}
 
-	app.Serve()
+	return result
+}
+
+// parseFields parses a comma separated list of key=value pairs, as provided by
+// the log-fields flag, into log fields.
+func parseFields(list string) (logrus.Fields, error) {
+	fields := logrus.Fields{}
+
+	for _, item := range splitList(list) {
+		parts := strings.SplitN(item, "=", 2)
+		key := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || key == "" {
+			return nil, fmt.Errorf("%q is not a key=value pair", item)
+		}
+
+		fields[key] = strings.TrimSpace(parts[1])
+	}
+
+	return fields, nil
+}
+
+// parseTimeouts parses a comma separated list of route=seconds pairs, as
+// provided by the route-timeouts flag, into the timeouts of those routes.
+func parseTimeouts(list string) (map[string]time.Duration, error) {
+	timeouts := map[string]time.Duration{}
+
+	for _, item := range splitList(list) {
+		parts := strings.SplitN(item, "=", 2)
+		route := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || route == "" {
+			return nil, fmt.Errorf("%q is not a route=seconds pair", item)
+		}
+
+		seconds, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil || seconds < 0 {
+			return nil, fmt.Errorf("%q does not give a whole number of seconds", item)
+		}
+
+		timeouts[route] = time.Duration(seconds) * time.Second
+	}
+
+	return timeouts, nil
+}
+
+// parseNetworks parses the tables of the networks of the config file, such as
+// [networks.public], into the further networks horizon serves, in order of
+// name.  Their settings are named as flags are, and their names may not be
+// those of the paths horizon routes itself, such as ledgers.
+func parseNetworks(tables map[string]interface{}) ([]horizon.NetworkConfig, error) {
+	names := make([]string, 0, len(tables))
+	for name := range tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	routed := make(map[string]bool)
+	for _, segment := range horizon.RouteSegments() {
+		routed[segment] = true
+	}
+
+	var networks []horizon.NetworkConfig
+	for _, name := range names {
+		if !networkName.MatchString(name) {
+			return nil, fmt.Errorf("%q is not a name of lowercase letters, digits, - and _", name)
+		}
+
+		// the routes of horizon take precedence over those of its networks
+		if routed[name] {
+			return nil, fmt.Errorf("network %s cannot be served under /%s, which horizon routes itself", name, name)
+		}
+
+		settings := cast.ToStringMap(tables[name])
+		nc := horizon.NetworkConfig{
+			Name:                   name,
+			DatabaseUrl:            cast.ToString(settings["db-url"]),
+			StellarCoreDatabaseUrl: cast.ToString(settings["stellar-core-db-url"]),
+			StellarCoreUrl:         cast.ToString(settings["stellar-core-url"]),
+			NetworkPassphrase:      cast.ToString(settings["network-passphrase"]),
+			Ingest:                 cast.ToBool(settings["ingest"]),
+			FriendbotSecret:        cast.ToString(settings["friendbot-secret"]),
+		}
+
+		if nc.DatabaseUrl == "" || nc.StellarCoreDatabaseUrl == "" {
+			return nil, fmt.Errorf("network %s needs a db-url and a stellar-core-db-url", name)
+		}
+
+		if nc.NetworkPassphrase == "" {
+			return nil, fmt.Errorf("network %s needs a network-passphrase", name)
+		}
+
+		networks = append(networks, nc)
+	}
+
+	return networks, nil
+}
+
+// perHourQuota returns a quota of n requests per hour, or nil when n is not
+// positive, leaving the limit it configures disabled.
+func perHourQuota(n int) throttled.Quota {
+	if n <= 0 {
+		return nil
+	}
+
+	return throttled.PerHour(n)
 }
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParseNetworks(t *testing.T) {
+
+	Convey("parseNetworks", t, func() {
+		network := func() map[string]interface{} {
+			return map[string]interface{}{
+				"db-url":              "postgres://localhost/horizon_public",
+				"stellar-core-db-url": "postgres://localhost/core_public",
+				"network-passphrase":  "Public Global Stellar Network ; September 2015",
+			}
+		}
+
+		Convey("parses networks in order of name", func() {
+			networks, err := parseNetworks(map[string]interface{}{
+				"public":  network(),
+				"private": network(),
+			})
+			So(err, ShouldBeNil)
+			So(len(networks), ShouldEqual, 2)
+			So(networks[0].Name, ShouldEqual, "private")
+			So(networks[1].Name, ShouldEqual, "public")
+		})
+
+		Convey("refuses networks named for the routes of horizon", func() {
+			for _, name := range []string{"ledgers", "accounts", "assets", "transactions", "friendbot", "stream", "metrics"} {
+				_, err := parseNetworks(map[string]interface{}{name: network()})
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "/"+name)
+			}
+		})
+	})
+}
@@ -1,6 +1,8 @@
 package horizon
 
 import (
+	"time"
+
 	"github.com/PuerkitoBio/throttled"
 	"github.com/Sirupsen/logrus"
 )
@@ -11,13 +13,94 @@ type Config struct {
 	DatabaseUrl            string
 	StellarCoreDatabaseUrl string
 	StellarCoreUrl         string
+	NetworkPassphrase      string
+	Networks               []NetworkConfig
+	NetworkCheckInterval   time.Duration
 	RubyHorizonUrl         string
 	Port                   int
+	AdminAddr              string
+	TLSCertFile            string
+	TLSKeyFile             string
+	ShutdownTimeout        time.Duration
 	Autopump               bool
+	LedgerNotifications    bool
+	Ingest                 bool
+	IngestProcessors       string
+	IngestVerifyInterval   time.Duration
+	HistoryRetention       time.Duration
+	PageQueryTimeout       time.Duration
+	StreamQueryTimeout     time.Duration
+	IngestQueryTimeout     time.Duration
+	RequestTimeout         time.Duration
+	RouteTimeouts          map[string]time.Duration
+	SlowQueryThreshold     time.Duration
+	SlowQueryLogFile       string
 	RateLimit              throttled.Quota
+	RateLimitBurst         int
+	APIKeysFile            string
+	APIKeysDB              bool
+	APIKeysRequired        bool
 	RedisUrl               string
+	QueryCacheSize         int
+	QueryCacheRedis        bool
 	LogLevel               logrus.Level
+	LogFormat              string
+	LogFields              logrus.Fields
 	SentryDSN              string
 	LogglyHost             string
 	LogglyToken            string
+	TracingUrl             string
+	TracingSampleRate      float64
+	TracingServiceName     string
+	SSEHeartbeat           time.Duration
+	SSEMaxConnections      int
+	SSEMaxConnectionsPerIP int
+	SSECompression         bool
+	SSEMaxRetry            time.Duration
+	SSEReplaySize          int
+	SSEReplayWindow        time.Duration
+	SSEStatusInterval      time.Duration
+	SSEBufferSize          int
+	CORSAllowedOrigins     []string
+	CORSAllowedHeaders     []string
+	CORSAllowCredentials   bool
+	CursorSecret           string
+	CursorAcceptLegacy     bool
+	JSONP                  bool
+	PathMaxLength          int
+	FeeStatsLedgers        int
+	HistoryStaleThreshold  int
+	FederationResolution   bool
+	AnchorMetadata         bool
+	TxResubmitLedgers      int
+	TxResubmitMaxAttempts  int
+	TxSubRateLimit         throttled.Quota
+	TxSubAccountRateLimit  throttled.Quota
+	TxSubMaxOpen           int
+	TxSubCoreUrls          []string
+	TxSubMaxOperations     int
+	TxSubMaxSignatures     int
+	TxSubMaxFee            int
+	TxSubMaxDelay          time.Duration
+	CallbackSecret         string
+	FriendbotSecret        string
+	FriendbotAmount        string
+	FriendbotRateLimit     throttled.Quota
+	FriendbotAccountLimit  throttled.Quota
+	FriendbotCaptchaURL    string
+	FriendbotCaptchaSecret string
+}
+
+// NetworkConfig configures a further stellar network horizon serves, under the
+// path /<Name>, alongside the one of Config.  Each network has databases and a
+// stellar-core of its own, and shares the http server, rate limits and metrics
+// of the others.
+type NetworkConfig struct {
+	Name                   string
+	DatabaseUrl            string
+	StellarCoreDatabaseUrl string
+	StellarCoreUrl         string
+	NetworkPassphrase      string
+	Ingest                 bool
+	FriendbotSecret        string
 }
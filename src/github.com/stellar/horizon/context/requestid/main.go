@@ -3,11 +3,18 @@
 package requestid
 
 import (
+	"net/http"
+
 	"github.com/zenazn/goji/web"
 	"github.com/zenazn/goji/web/middleware"
 	"golang.org/x/net/context"
 )
 
+// Header is the http header request ids are given in: by clients of horizon,
+// in the responses horizon serves them and in the requests it makes of other
+// services while serving them.
+const Header = "X-Request-ID"
+
 var key = 0
 
 // Context create a context from the provided parent and the provided request id
@@ -44,3 +51,15 @@ func FromContext(ctx context.Context) string {
 
 	return ""
 }
+
+// Propagate sets the Header of req, a request made while serving another, to
+// the request id of ctx, so that the logs of the service called may be
+// stitched to those of horizon.  req is left as is when ctx has no request id.
+func Propagate(ctx context.Context, req *http.Request) {
+	id := FromContext(ctx)
+	if id == "" {
+		return
+	}
+
+	req.Header.Set(Header, id)
+}
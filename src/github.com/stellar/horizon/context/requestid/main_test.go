@@ -1,6 +1,8 @@
 package requestid
 
 import (
+	"net/http"
+
 	. "github.com/smartystreets/goconvey/convey"
 	"github.com/zenazn/goji/web"
 	"golang.org/x/net/context"
@@ -37,4 +39,13 @@ func TestRequestId(t *testing.T) {
 		So(FromContext(ctx), ShouldEqual, "2")
 		So(FromContext(ctx2), ShouldEqual, "3")
 	})
+
+	Convey("requestid.Propagate", t, func() {
+		req, _ := http.NewRequest("GET", "http://localhost:11626/tx", nil)
+		Propagate(context.Background(), req)
+		So(req.Header.Get(Header), ShouldEqual, "")
+
+		Propagate(Context(context.Background(), "2"), req)
+		So(req.Header.Get(Header), ShouldEqual, "2")
+	})
 }
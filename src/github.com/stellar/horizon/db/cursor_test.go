@@ -0,0 +1,80 @@
+package db
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/test"
+)
+
+func TestCursorCodec(t *testing.T) {
+	Convey("CursorCodec", t, func() {
+		codec := &CursorCodec{Key: []byte("secret")}
+		path := "/accounts/GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H/payments"
+
+		Convey("round trips paging tokens", func() {
+			for _, token := range []string{"12884905984", "12884905985-1"} {
+				cursor := codec.Encode(path, token)
+				So(cursor, ShouldNotEqual, token)
+				So(cursor, ShouldNotContainSubstring, "=")
+
+				decoded, err := codec.Decode(path, cursor)
+				So(err, ShouldBeNil)
+				So(decoded, ShouldEqual, token)
+			}
+		})
+
+		Convey("passes empty cursors through", func() {
+			So(codec.Encode(path, ""), ShouldEqual, "")
+
+			decoded, err := codec.Decode(path, "")
+			So(err, ShouldBeNil)
+			So(decoded, ShouldEqual, "")
+		})
+
+		Convey("rejects cursors it did not issue", func() {
+			other := &CursorCodec{Key: []byte("other")}
+
+			for _, cursor := range []string{
+				other.Encode(path, "12884905984"),
+				codec.Encode(path, "12884905984")[1:],
+				"12884905984",
+				"not a cursor",
+			} {
+				_, err := codec.Decode(path, cursor)
+				So(err, test.ShouldBeErr, ErrInvalidCursor)
+			}
+		})
+
+		Convey("rejects cursors issued for other endpoints", func() {
+			cursor := codec.Encode("/payments", "12884905984")
+
+			_, err := codec.Decode(path, cursor)
+			So(err, test.ShouldBeErr, ErrInvalidCursor)
+
+			decoded, err := codec.Decode("/payments", cursor)
+			So(err, ShouldBeNil)
+			So(decoded, ShouldEqual, "12884905984")
+		})
+
+		Convey("accepts raw paging tokens in legacy mode", func() {
+			codec.AcceptLegacy = true
+
+			decoded, err := codec.Decode(path, "12884905985-1")
+			So(err, ShouldBeNil)
+			So(decoded, ShouldEqual, "12884905985-1")
+
+			_, err = codec.Decode(path, "1-2-3")
+			So(err, test.ShouldBeErr, ErrInvalidCursor)
+		})
+
+		Convey("nil codecs expose raw paging tokens", func() {
+			var codec *CursorCodec
+			So(codec.Encode(path, "12884905984"), ShouldEqual, "12884905984")
+
+			decoded, err := codec.Decode(path, "12884905984")
+			So(err, ShouldBeNil)
+			So(decoded, ShouldEqual, "12884905984")
+		})
+	})
+}
@@ -0,0 +1,172 @@
+package db
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"net/url"
+	"strings"
+
+	"github.com/go-errors/errors"
+	"github.com/jmoiron/sqlx"
+	sq "github.com/lann/squirrel"
+	"github.com/lib/pq"
+)
+
+// CockroachDriverName is the database/sql driver of the databases opened as
+// CockroachDB, which speaks the postgres wire protocol.  Open uses it for urls
+// of the cockroach:// and cockroachdb:// schemes.
+const CockroachDriverName = "cockroach"
+
+// MaxTransactionAttempts is the count of times Transact runs a transaction
+// that keeps being aborted by retryable errors.
+const MaxTransactionAttempts = 5
+
+// Dialect is the flavor of SQL spoken by a database horizon runs on, covering
+// how the databases it supports differ.  Horizon takes no advisory locks, which
+// CockroachDB lacks, so that its dialect need not offer them.
+type Dialect interface {
+	// Name identifies the dialect, for logs and errors
+	Name() string
+
+	// PlaceholderFormat is the format of the placeholders of the queries built
+	// for the database
+	PlaceholderFormat() sq.PlaceholderFormat
+
+	// Retryable returns true if err aborted a transaction that may commit if run
+	// again, as the serialization failures of CockroachDB do.
+	Retryable(err error) bool
+
+	// Notifications returns true if the database delivers notifications sent
+	// with NOTIFY to the connections that LISTEN for them.
+	Notifications() bool
+
+	// Cancellable returns true if the statement a connection runs may be
+	// cancelled from another connection, by its backend pid.
+	Cancellable() bool
+
+	// CopyIn returns true if the database loads the rows sent it with COPY FROM
+	// STDIN, the quickest way to write many.
+	CopyIn() bool
+
+	// Ranges returns true if the database has range types such as int8range,
+	// of which the time_bounds of the history schema of horizon-importer are.
+	// Horizon cannot keep its history in databases without them.
+	Ranges() bool
+}
+
+var (
+	// Postgres is the dialect of PostgreSQL
+	Postgres Dialect = postgresDialect{}
+
+	// CockroachDB is the dialect of CockroachDB.  Its transactions are
+	// serializable, so that those contending with others are aborted and must
+	// be retried by the client.  It lacks the range types of the history
+	// schema, so that horizon refuses to start on it until it keeps a schema
+	// of its own.
+	CockroachDB Dialect = cockroachDialect{}
+)
+
+// DialectOf returns the dialect of the database d, as opened by Open
+func DialectOf(d *sqlx.DB) Dialect {
+	if d != nil && d.DriverName() == CockroachDriverName {
+		return CockroachDB
+	}
+
+	return Postgres
+}
+
+// Transact runs fn within a db transaction of d, committed when fn returns no
+// error and rolled back otherwise.  Transactions aborted by errors the dialect
+// of d deems retryable are run again, up to MaxTransactionAttempts times, so
+// fn must start anew each time it is called.
+func Transact(d *sqlx.DB, fn func(tx *sqlx.Tx) error) (err error) {
+	dialect := DialectOf(d)
+
+	for attempt := 1; ; attempt++ {
+		err = transactOnce(d, fn)
+		if err == nil || attempt == MaxTransactionAttempts || !dialect.Retryable(err) {
+			return
+		}
+	}
+}
+
+func transactOnce(d *sqlx.DB, fn func(tx *sqlx.Tx) error) error {
+	tx, err := d.Beginx()
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	err = fn(tx)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	return nil
+}
+
+// openURL returns the driver and the data source name Open opens rawurl with
+func openURL(rawurl string) (driver string, dsn string) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return "postgres", rawurl
+	}
+
+	switch u.Scheme {
+	case "cockroach", "cockroachdb":
+		u.Scheme = "postgresql"
+		return CockroachDriverName, u.String()
+	}
+
+	return "postgres", rawurl
+}
+
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string                            { return "postgres" }
+func (postgresDialect) PlaceholderFormat() sq.PlaceholderFormat { return sq.Dollar }
+func (postgresDialect) Retryable(err error) bool                { return false }
+func (postgresDialect) Notifications() bool                     { return true }
+func (postgresDialect) Cancellable() bool                       { return true }
+func (postgresDialect) CopyIn() bool                            { return true }
+func (postgresDialect) Ranges() bool                            { return true }
+
+type cockroachDialect struct{}
+
+func (cockroachDialect) Name() string                            { return "cockroachdb" }
+func (cockroachDialect) PlaceholderFormat() sq.PlaceholderFormat { return sq.Dollar }
+func (cockroachDialect) Notifications() bool                     { return false }
+func (cockroachDialect) Cancellable() bool                       { return false }
+func (cockroachDialect) CopyIn() bool                            { return false }
+func (cockroachDialect) Ranges() bool                            { return false }
+
+// Retryable is a method for Dialect.  CockroachDB reports the transactions it
+// aborted to be retried as serialization failures.
+func (cockroachDialect) Retryable(err error) bool {
+	if e, ok := err.(*errors.Error); ok {
+		err = e.Err
+	}
+
+	pqErr, ok := err.(*pq.Error)
+	if !ok {
+		return false
+	}
+
+	return pqErr.Code == "40001" || strings.Contains(pqErr.Message, "restart transaction")
+}
+
+// cockroachDriver is the driver of CockroachDB connections, those of lib/pq
+type cockroachDriver struct{}
+
+func (cockroachDriver) Open(name string) (driver.Conn, error) {
+	return pq.Open(name)
+}
+
+func init() {
+	sql.Register(CockroachDriverName, cockroachDriver{})
+}
@@ -0,0 +1,46 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/go-errors/errors"
+	"github.com/lib/pq"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDialect(t *testing.T) {
+	Convey("openURL", t, func() {
+		driver, dsn := openURL("postgres://localhost/horizon?sslmode=disable")
+		So(driver, ShouldEqual, "postgres")
+		So(dsn, ShouldEqual, "postgres://localhost/horizon?sslmode=disable")
+
+		driver, dsn = openURL("cockroach://root@localhost:26257/horizon?sslmode=disable")
+		So(driver, ShouldEqual, CockroachDriverName)
+		So(dsn, ShouldEqual, "postgresql://root@localhost:26257/horizon?sslmode=disable")
+
+		driver, _ = openURL("cockroachdb://root@localhost:26257/horizon")
+		So(driver, ShouldEqual, CockroachDriverName)
+	})
+
+	Convey("Dialect.Retryable", t, func() {
+		serialization := &pq.Error{Code: "40001", Message: "restart transaction: retry txn"}
+		other := &pq.Error{Code: "23505", Message: "duplicate key value"}
+
+		So(CockroachDB.Retryable(serialization), ShouldBeTrue)
+		So(CockroachDB.Retryable(errors.Wrap(serialization, 1)), ShouldBeTrue)
+		So(CockroachDB.Retryable(other), ShouldBeFalse)
+		So(CockroachDB.Retryable(errors.New("boom")), ShouldBeFalse)
+
+		So(Postgres.Retryable(serialization), ShouldBeFalse)
+	})
+
+	Convey("Dialect.Ranges", t, func() {
+		So(Postgres.Ranges(), ShouldBeTrue)
+		So(CockroachDB.Ranges(), ShouldBeFalse)
+	})
+
+	Convey("DialectOf", t, func() {
+		So(DialectOf(nil).Name(), ShouldEqual, "postgres")
+		So(DialectOf(history).Name(), ShouldEqual, "postgres")
+	})
+}
@@ -0,0 +1,33 @@
+package db
+
+import (
+	"github.com/go-errors/errors"
+	"github.com/jmoiron/sqlx"
+)
+
+// DeleteLedgerRange deletes, using ex, the history of the ledgers from `from`
+// to `to` inclusive: the ledgers themselves, their transactions, operations,
+// effects and the participants of each.  History accounts are kept, as they
+// are shared with later ledgers.
+func DeleteLedgerRange(ex sqlx.Execer, from, to int32) error {
+	start := TotalOrderId{LedgerSequence: from}.ToInt64()
+	end := TotalOrderId{LedgerSequence: to + 1}.ToInt64()
+
+	for _, stmt := range []string{
+		`DELETE FROM history_effects WHERE history_operation_id >= $1 AND history_operation_id < $2`,
+		`DELETE FROM history_operation_participants WHERE history_operation_id >= $1 AND history_operation_id < $2`,
+		`DELETE FROM history_operations WHERE id >= $1 AND id < $2`,
+		`DELETE FROM history_transaction_participants WHERE transaction_hash IN (
+			SELECT transaction_hash FROM history_transactions WHERE id >= $1 AND id < $2
+		)`,
+		`DELETE FROM history_transactions WHERE id >= $1 AND id < $2`,
+		`DELETE FROM history_ledgers WHERE id >= $1 AND id < $2`,
+	} {
+		_, err := ex.Exec(stmt, start, end)
+		if err != nil {
+			return errors.Wrap(err, 1)
+		}
+	}
+
+	return nil
+}
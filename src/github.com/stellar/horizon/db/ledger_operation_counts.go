@@ -0,0 +1,70 @@
+package db
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"strconv"
+
+	"github.com/go-errors/errors"
+	"github.com/stellar/go-stellar-base/xdr"
+)
+
+// OperationTypeCounts counts the operations of a ledger by their type, as
+// ingestion writes them to the operation_type_counts column migration 4 adds
+// to history_ledgers.  Types the ledger holds none of are left out.
+type OperationTypeCounts map[xdr.OperationType]int32
+
+// Value implements driver.Valuer, writing c as history keeps it: a json object
+// keyed by the integer type of the operations counted.  Nil counts are null.
+func (c OperationTypeCounts) Value() (driver.Value, error) {
+	if c == nil {
+		return nil, nil
+	}
+
+	raw := make(map[string]int32, len(c))
+	for t, n := range c {
+		raw[strconv.Itoa(int(t))] = n
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, errors.Wrap(err, 1)
+	}
+
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner, reading the counts history keeps.  Null counts,
+// those of the ledgers ingested before history kept them, scan as nil.
+func (c *OperationTypeCounts) Scan(src interface{}) error {
+	var data []byte
+	switch src := src.(type) {
+	case nil:
+		*c = nil
+		return nil
+	case []byte:
+		data = src
+	case string:
+		data = []byte(src)
+	default:
+		return errors.Errorf("cannot scan %T into OperationTypeCounts", src)
+	}
+
+	var raw map[string]int32
+	err := json.Unmarshal(data, &raw)
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	result := make(OperationTypeCounts, len(raw))
+	for key, n := range raw {
+		t, err := strconv.Atoi(key)
+		if err != nil {
+			return errors.Wrap(err, 1)
+		}
+		result[xdr.OperationType(t)] = n
+	}
+
+	*c = result
+	return nil
+}
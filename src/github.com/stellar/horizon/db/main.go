@@ -54,9 +54,11 @@ func (r HistoryRecord) PagingToken() string {
 }
 
 // Open the postgres database at the provided url and performing an initial
-// ping to ensure we can connect to it.
+// ping to ensure we can connect to it.  Urls of the cockroach:// scheme open
+// a CockroachDB database, whose dialect is CockroachDB, though horizon cannot
+// yet keep its history in one.
 func Open(url string) (*sqlx.DB, error) {
-	db, err := sqlx.Connect("postgres", url)
+	db, err := sqlx.Connect(openURL(url))
 	if err != nil {
 		return db, errors.Wrap(err, 1)
 	}
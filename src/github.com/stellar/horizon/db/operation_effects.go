@@ -0,0 +1,321 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/go-errors/errors"
+	"github.com/stellar/go-stellar-base/xdr"
+)
+
+// SignerCheck reports whether key already signs for account, which tells the
+// signers an operation adds apart from those it updates.
+type SignerCheck func(account, key string) (bool, error)
+
+// NewEffectRecords returns the effects of op, a successful operation whose
+// record is record, result is result and ledger entry changes are changes, in
+// the order history holds them.
+//
+// The records name the account each effect applies to by address only: their
+// HistoryAccountID is left for the caller to set.
+func NewEffectRecords(
+	record OperationRecord,
+	op xdr.Operation,
+	result xdr.OperationResult,
+	changes xdr.LedgerEntryChanges,
+	isSigner SignerCheck,
+) ([]EffectRecord, error) {
+	b := &effectBuilder{operation: record}
+	source := record.SourceAccount
+	tr := result.MustTr()
+
+	switch op.Body.Type {
+	case xdr.OperationTypeCreateAccount:
+		o := op.Body.MustCreateAccountOp()
+		dest := b.address(o.Destination)
+		b.add(dest, EffectAccountCreated, map[string]interface{}{
+			"starting_balance": detailAmount(o.StartingBalance),
+		})
+		b.add(source, EffectAccountDebited, b.amount(o.StartingBalance, nativeAsset()))
+		b.add(dest, EffectSignerCreated, map[string]interface{}{
+			"public_key": dest,
+			"weight":     1,
+		})
+	case xdr.OperationTypePayment:
+		o := op.Body.MustPaymentOp()
+		b.add(b.address(o.Destination), EffectAccountCredited, b.amount(o.Amount, o.Asset))
+		b.add(source, EffectAccountDebited, b.amount(o.Amount, o.Asset))
+	case xdr.OperationTypePathPayment:
+		o := op.Body.MustPathPaymentOp()
+		r := tr.MustPathPaymentResult()
+		b.add(b.address(o.Destination), EffectAccountCredited, b.amount(o.DestAmount, o.DestAsset))
+		b.add(source, EffectAccountDebited, b.amount(pathPaymentSent(o, r), o.SendAsset))
+		b.trades(source, r.MustSuccess().Offers)
+	case xdr.OperationTypeManageOffer:
+		r := tr.MustManageOfferResult().MustSuccess()
+		b.trades(source, r.OffersClaimed)
+		b.offer(source, op.Body.MustManageOfferOp().OfferId, r.Offer)
+	case xdr.OperationTypeCreatePassiveOffer:
+		r := tr.MustCreatePassiveOfferResult().MustSuccess()
+		b.trades(source, r.OffersClaimed)
+		b.offer(source, 0, r.Offer)
+	case xdr.OperationTypeSetOptions:
+		b.setOptions(source, op.Body.MustSetOptionsOp(), isSigner)
+	case xdr.OperationTypeChangeTrust:
+		o := op.Body.MustChangeTrustOp()
+		effect := int32(EffectTrustlineUpdated)
+		switch {
+		case o.Limit == 0:
+			effect = EffectTrustlineRemoved
+		case createsTrustline(changes):
+			effect = EffectTrustlineCreated
+		}
+
+		details := map[string]interface{}{"limit": detailAmount(o.Limit)}
+		b.asset(details, "", o.Line)
+		b.add(source, effect, details)
+	case xdr.OperationTypeAllowTrust:
+		o := op.Body.MustAllowTrustOp()
+		effect := int32(EffectTrustlineDeauthorized)
+		if o.Authorize {
+			effect = EffectTrustlineAuthorized
+		}
+
+		details, err := operationDetails(op, source)
+		if err != nil {
+			return nil, err
+		}
+
+		b.add(source, effect, map[string]interface{}{
+			"trustor":    details["trustor"],
+			"asset_type": details["asset_type"],
+			"asset_code": details["asset_code"],
+		})
+	case xdr.OperationTypeAccountMerge:
+		balance := *tr.MustAccountMergeResult().SourceAccountBalance
+		b.add(source, EffectAccountDebited, b.amount(balance, nativeAsset()))
+		b.add(b.address(op.Body.MustDestination()), EffectAccountCredited, b.amount(balance, nativeAsset()))
+		b.add(source, EffectAccountRemoved, map[string]interface{}{})
+	case xdr.OperationTypeInflation:
+		payouts := tr.MustInflationResult().Payouts
+		if payouts != nil {
+			for _, payout := range *payouts {
+				b.add(b.address(payout.Destination), EffectAccountCredited, b.amount(payout.Amount, nativeAsset()))
+			}
+		}
+	}
+
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	return b.records, nil
+}
+
+// effectBuilder accumulates the effects of an operation.  Once an error
+// occurs, the builder adds no more effects and the error is reported by
+// NewEffectRecords.
+type effectBuilder struct {
+	operation OperationRecord
+	records   []EffectRecord
+	err       error
+}
+
+// add adds an effect of type effect on account
+func (b *effectBuilder) add(account string, effect int32, details map[string]interface{}) {
+	if b.err != nil {
+		return
+	}
+
+	raw, err := json.Marshal(details)
+	if err != nil {
+		b.err = errors.Wrap(err, 1)
+		return
+	}
+
+	b.records = append(b.records, EffectRecord{
+		Account:            account,
+		HistoryOperationID: b.operation.Id,
+		Order:              int32(len(b.records) + 1),
+		Type:               effect,
+		DetailsString:      sql.NullString{String: string(raw), Valid: true},
+	})
+}
+
+// address returns the address of aid
+func (b *effectBuilder) address(aid xdr.AccountId) string {
+	if b.err != nil {
+		return ""
+	}
+
+	var address string
+	address, b.err = accountAddress(aid)
+	return address
+}
+
+// asset sets the details of a on details, each name following prefix
+func (b *effectBuilder) asset(details map[string]interface{}, prefix string, a xdr.Asset) {
+	if b.err != nil {
+		return
+	}
+
+	b.err = setAssetDetails(details, prefix, a)
+}
+
+// amount returns the details of a credit or debit of v of asset a
+func (b *effectBuilder) amount(v xdr.Int64, a xdr.Asset) map[string]interface{} {
+	details := map[string]interface{}{"amount": detailAmount(v)}
+	b.asset(details, "", a)
+	return details
+}
+
+// trades adds a trade effect for each side of the offers the operation of
+// buyer crossed: one for buyer and one for the owner of the offer.
+func (b *effectBuilder) trades(buyer string, atoms []xdr.ClaimOfferAtom) {
+	for _, atom := range atoms {
+		seller := b.address(atom.SellerId)
+
+		bd := map[string]interface{}{
+			"seller":        seller,
+			"offer_id":      atom.OfferId,
+			"sold_amount":   detailAmount(atom.AmountBought),
+			"bought_amount": detailAmount(atom.AmountSold),
+		}
+		b.asset(bd, "sold_", atom.AssetBought)
+		b.asset(bd, "bought_", atom.AssetSold)
+		b.add(buyer, EffectTrade, bd)
+
+		sd := map[string]interface{}{
+			"seller":        buyer,
+			"offer_id":      atom.OfferId,
+			"sold_amount":   detailAmount(atom.AmountSold),
+			"bought_amount": detailAmount(atom.AmountBought),
+		}
+		b.asset(sd, "sold_", atom.AssetSold)
+		b.asset(sd, "bought_", atom.AssetBought)
+		b.add(seller, EffectTrade, sd)
+	}
+}
+
+// offer adds the effect an offer operation of source had on its own offer, the
+// one of id offerID or, when 0, a new one, once its trades are made.  New
+// offers that trade in full leave no offer to take effect on.
+func (b *effectBuilder) offer(source string, offerID xdr.Uint64, r xdr.ManageOfferSuccessResultOffer) {
+	var effect int32
+	switch r.Effect {
+	case xdr.ManageOfferEffectManageOfferCreated:
+		effect = EffectOfferCreated
+	case xdr.ManageOfferEffectManageOfferUpdated:
+		effect = EffectOfferUpdated
+	case xdr.ManageOfferEffectManageOfferDeleted:
+		if offerID != 0 {
+			b.add(source, EffectOfferRemoved, map[string]interface{}{"offer_id": offerID})
+		}
+		return
+	}
+
+	offer := r.MustOffer()
+	details := map[string]interface{}{
+		"offer_id": offer.OfferId,
+		"amount":   detailAmount(offer.Amount),
+	}
+	setPriceDetails(details, offer.Price)
+	b.asset(details, "buying_", offer.Buying)
+	b.asset(details, "selling_", offer.Selling)
+	b.add(source, effect, details)
+}
+
+// setOptions adds the effects of the set_options operation o on source
+func (b *effectBuilder) setOptions(source string, o xdr.SetOptionsOp, isSigner SignerCheck) {
+	if o.HomeDomain != nil {
+		b.add(source, EffectAccountHomeDomainUpdated, map[string]interface{}{
+			"home_domain": *o.HomeDomain,
+		})
+	}
+
+	if o.InflationDest != nil {
+		b.add(source, EffectAccountInflationDestinationUpdated, map[string]interface{}{
+			"inflation_destination": b.address(*o.InflationDest),
+		})
+	}
+
+	thresholds := map[string]interface{}{}
+	if o.LowThreshold != nil {
+		thresholds["low_threshold"] = *o.LowThreshold
+	}
+	if o.MedThreshold != nil {
+		thresholds["med_threshold"] = *o.MedThreshold
+	}
+	if o.HighThreshold != nil {
+		thresholds["high_threshold"] = *o.HighThreshold
+	}
+	if len(thresholds) > 0 {
+		b.add(source, EffectAccountThresholdsUpdated, thresholds)
+	}
+
+	flags := map[string]interface{}{}
+	if o.SetFlags != nil {
+		_, names := flagDetails(int32(*o.SetFlags))
+		for _, name := range names {
+			flags[name] = true
+		}
+	}
+	if o.ClearFlags != nil {
+		_, names := flagDetails(int32(*o.ClearFlags))
+		for _, name := range names {
+			flags[name] = false
+		}
+	}
+	if len(flags) > 0 {
+		b.add(source, EffectAccountFlagsUpdated, flags)
+	}
+
+	if o.MasterWeight != nil {
+		effect := int32(EffectSignerUpdated)
+		if *o.MasterWeight == 0 {
+			effect = EffectSignerRemoved
+		}
+		b.add(source, effect, map[string]interface{}{
+			"public_key": source,
+			"weight":     *o.MasterWeight,
+		})
+	}
+
+	if o.Signer != nil {
+		key := b.address(o.Signer.PubKey)
+		effect := int32(EffectSignerRemoved)
+		if o.Signer.Weight > 0 && b.err == nil {
+			var exists bool
+			exists, b.err = isSigner(source, key)
+			effect = EffectSignerCreated
+			if exists {
+				effect = EffectSignerUpdated
+			}
+		}
+		b.add(source, effect, map[string]interface{}{
+			"public_key": key,
+			"weight":     o.Signer.Weight,
+		})
+	}
+}
+
+// createsTrustline returns true if changes, those of a change_trust
+// operation, create a trustline
+func createsTrustline(changes xdr.LedgerEntryChanges) bool {
+	for _, change := range changes {
+		if change.Type != xdr.LedgerEntryChangeTypeLedgerEntryCreated {
+			continue
+		}
+
+		if change.Created.Data.Type == xdr.LedgerEntryTypeTrustline {
+			return true
+		}
+	}
+
+	return false
+}
+
+// nativeAsset returns the native asset
+func nativeAsset() xdr.Asset {
+	return xdr.Asset{Type: xdr.AssetTypeAssetTypeNative}
+}
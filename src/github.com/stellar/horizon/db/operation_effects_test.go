@@ -0,0 +1,321 @@
+package db
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/go-stellar-base/strkey"
+	"github.com/stellar/go-stellar-base/xdr"
+)
+
+func TestNewEffectRecords(t *testing.T) {
+	const (
+		source = "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H"
+		other  = "GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON"
+	)
+
+	aid := func(address string) xdr.AccountId {
+		var key xdr.Uint256
+		copy(key[:], strkey.MustDecode(strkey.VersionByteAccountID, address))
+		id, err := xdr.NewAccountId(xdr.CryptoKeyTypeKeyTypeEd25519, key)
+		So(err, ShouldBeNil)
+		return id
+	}
+
+	record := OperationRecord{
+		HistoryRecord: HistoryRecord{Id: TotalOrderId{LedgerSequence: 3, TransactionOrder: 1, OperationOrder: 1}.ToInt64()},
+		SourceAccount: source,
+	}
+
+	result := func(t xdr.OperationType, value interface{}) xdr.OperationResult {
+		tr, err := xdr.NewOperationResultTr(t, value)
+		So(err, ShouldBeNil)
+		r, err := xdr.NewOperationResult(xdr.OperationResultCodeOpInner, tr)
+		So(err, ShouldBeNil)
+		return r
+	}
+
+	noSigners := func(account, key string) (bool, error) { return false, nil }
+
+	usd := func() xdr.Asset {
+		var code [4]byte
+		copy(code[:], "USD")
+		a, err := xdr.NewAsset(xdr.AssetTypeAssetTypeCreditAlphanum4, xdr.AssetAlphaNum4{AssetCode: code, Issuer: aid(other)})
+		So(err, ShouldBeNil)
+		return a
+	}
+
+	Convey("NewEffectRecords", t, func() {
+		Convey("credits and debits the accounts of payments", func() {
+			body, err := xdr.NewOperationBody(xdr.OperationTypePayment, xdr.PaymentOp{
+				Destination: aid(other),
+				Asset:       nativeAsset(),
+				Amount:      50000000,
+			})
+			So(err, ShouldBeNil)
+
+			pr, err := xdr.NewPaymentResult(xdr.PaymentResultCodePaymentSuccess, nil)
+			So(err, ShouldBeNil)
+
+			effects, err := NewEffectRecords(record, xdr.Operation{Body: body}, result(xdr.OperationTypePayment, pr), nil, noSigners)
+			So(err, ShouldBeNil)
+			So(len(effects), ShouldEqual, 2)
+
+			So(effects[0].Account, ShouldEqual, other)
+			So(effects[0].Type, ShouldEqual, EffectAccountCredited)
+			So(effects[0].HistoryOperationID, ShouldEqual, record.Id)
+			So(effects[0].Order, ShouldEqual, 1)
+			details, err := effects[0].Details()
+			So(err, ShouldBeNil)
+			So(details["amount"], ShouldEqual, "5.0")
+			So(details["asset_type"], ShouldEqual, "native")
+
+			So(effects[1].Account, ShouldEqual, source)
+			So(effects[1].Type, ShouldEqual, EffectAccountDebited)
+			So(effects[1].Order, ShouldEqual, 2)
+		})
+
+		Convey("tells signers added from those updated", func() {
+			body, err := xdr.NewOperationBody(xdr.OperationTypeSetOptions, xdr.SetOptionsOp{
+				Signer: &xdr.Signer{PubKey: aid(other), Weight: 2},
+			})
+			So(err, ShouldBeNil)
+
+			sr, err := xdr.NewSetOptionsResult(xdr.SetOptionsResultCodeSetOptionsSuccess, nil)
+			So(err, ShouldBeNil)
+			op := xdr.Operation{Body: body}
+			r := result(xdr.OperationTypeSetOptions, sr)
+
+			effects, err := NewEffectRecords(record, op, r, nil, noSigners)
+			So(err, ShouldBeNil)
+			So(len(effects), ShouldEqual, 1)
+			So(effects[0].Type, ShouldEqual, EffectSignerCreated)
+
+			var checked []string
+			effects, err = NewEffectRecords(record, op, r, nil, func(account, key string) (bool, error) {
+				checked = []string{account, key}
+				return true, nil
+			})
+			So(err, ShouldBeNil)
+			So(effects[0].Type, ShouldEqual, EffectSignerUpdated)
+			So(checked, ShouldResemble, []string{source, other})
+
+			details, err := effects[0].Details()
+			So(err, ShouldBeNil)
+			So(details["public_key"], ShouldEqual, other)
+			So(details["weight"], ShouldEqual, 2)
+		})
+
+		Convey("records both sides of the trades of offers", func() {
+			var code [4]byte
+			copy(code[:], "USD")
+			usd, err := xdr.NewAsset(xdr.AssetTypeAssetTypeCreditAlphanum4, xdr.AssetAlphaNum4{AssetCode: code, Issuer: aid(other)})
+			So(err, ShouldBeNil)
+
+			body, err := xdr.NewOperationBody(xdr.OperationTypeManageOffer, xdr.ManageOfferOp{
+				Selling: nativeAsset(),
+				Buying:  usd,
+				Amount:  100000000,
+				Price:   xdr.Price{N: 1, D: 1},
+			})
+			So(err, ShouldBeNil)
+
+			offer, err := xdr.NewManageOfferSuccessResultOffer(xdr.ManageOfferEffectManageOfferDeleted, nil)
+			So(err, ShouldBeNil)
+			mr, err := xdr.NewManageOfferResult(xdr.ManageOfferResultCodeManageOfferSuccess, xdr.ManageOfferSuccessResult{
+				OffersClaimed: []xdr.ClaimOfferAtom{{
+					SellerId:     aid(other),
+					OfferId:      7,
+					AssetSold:    usd,
+					AmountSold:   100000000,
+					AssetBought:  nativeAsset(),
+					AmountBought: 100000000,
+				}},
+				Offer: offer,
+			})
+			So(err, ShouldBeNil)
+
+			effects, err := NewEffectRecords(record, xdr.Operation{Body: body}, result(xdr.OperationTypeManageOffer, mr), nil, noSigners)
+			So(err, ShouldBeNil)
+			So(len(effects), ShouldEqual, 2)
+
+			buyer, err := effects[0].Details()
+			So(err, ShouldBeNil)
+			So(effects[0].Account, ShouldEqual, source)
+			So(buyer["seller"], ShouldEqual, other)
+			So(buyer["sold_asset_type"], ShouldEqual, "native")
+			So(buyer["bought_asset_code"], ShouldEqual, "USD")
+
+			seller, err := effects[1].Details()
+			So(err, ShouldBeNil)
+			So(effects[1].Account, ShouldEqual, other)
+			So(seller["seller"], ShouldEqual, source)
+			So(seller["sold_asset_code"], ShouldEqual, "USD")
+			So(seller["bought_asset_type"], ShouldEqual, "native")
+		})
+
+		Convey("records the offers operations leave on the books", func() {
+			manage := func(offerID xdr.Uint64, effect xdr.ManageOfferEffect) []EffectRecord {
+				body, err := xdr.NewOperationBody(xdr.OperationTypeManageOffer, xdr.ManageOfferOp{
+					Selling: nativeAsset(),
+					Buying:  usd(),
+					Amount:  50000000,
+					Price:   xdr.Price{N: 1, D: 2},
+					OfferId: offerID,
+				})
+				So(err, ShouldBeNil)
+
+				var entry interface{}
+				if effect != xdr.ManageOfferEffectManageOfferDeleted {
+					entry = xdr.OfferEntry{
+						SellerId: aid(source),
+						OfferId:  9,
+						Selling:  nativeAsset(),
+						Buying:   usd(),
+						Amount:   50000000,
+						Price:    xdr.Price{N: 1, D: 2},
+					}
+				}
+				offer, err := xdr.NewManageOfferSuccessResultOffer(effect, entry)
+				So(err, ShouldBeNil)
+				mr, err := xdr.NewManageOfferResult(xdr.ManageOfferResultCodeManageOfferSuccess, xdr.ManageOfferSuccessResult{Offer: offer})
+				So(err, ShouldBeNil)
+
+				effects, err := NewEffectRecords(record, xdr.Operation{Body: body}, result(xdr.OperationTypeManageOffer, mr), nil, noSigners)
+				So(err, ShouldBeNil)
+				return effects
+			}
+
+			effects := manage(0, xdr.ManageOfferEffectManageOfferCreated)
+			So(len(effects), ShouldEqual, 1)
+			So(effects[0].Account, ShouldEqual, source)
+			So(effects[0].Type, ShouldEqual, EffectOfferCreated)
+			details, err := effects[0].Details()
+			So(err, ShouldBeNil)
+			So(details["offer_id"], ShouldEqual, 9)
+			So(details["amount"], ShouldEqual, "5.0")
+			So(details["price"], ShouldEqual, "0.5")
+			So(details["selling_asset_type"], ShouldEqual, "native")
+			So(details["buying_asset_code"], ShouldEqual, "USD")
+
+			effects = manage(9, xdr.ManageOfferEffectManageOfferUpdated)
+			So(len(effects), ShouldEqual, 1)
+			So(effects[0].Type, ShouldEqual, EffectOfferUpdated)
+
+			effects = manage(9, xdr.ManageOfferEffectManageOfferDeleted)
+			So(len(effects), ShouldEqual, 1)
+			So(effects[0].Type, ShouldEqual, EffectOfferRemoved)
+			details, err = effects[0].Details()
+			So(err, ShouldBeNil)
+			So(details["offer_id"], ShouldEqual, 9)
+		})
+
+		Convey("records updates of inflation destinations", func() {
+			dest := aid(other)
+			body, err := xdr.NewOperationBody(xdr.OperationTypeSetOptions, xdr.SetOptionsOp{InflationDest: &dest})
+			So(err, ShouldBeNil)
+			sr, err := xdr.NewSetOptionsResult(xdr.SetOptionsResultCodeSetOptionsSuccess, nil)
+			So(err, ShouldBeNil)
+
+			effects, err := NewEffectRecords(record, xdr.Operation{Body: body}, result(xdr.OperationTypeSetOptions, sr), nil, noSigners)
+			So(err, ShouldBeNil)
+			So(len(effects), ShouldEqual, 1)
+			So(effects[0].Type, ShouldEqual, EffectAccountInflationDestinationUpdated)
+			details, err := effects[0].Details()
+			So(err, ShouldBeNil)
+			So(details["inflation_destination"], ShouldEqual, other)
+		})
+
+		Convey("tells trustlines created from those updated and removed", func() {
+			cr, err := xdr.NewChangeTrustResult(xdr.ChangeTrustResultCodeChangeTrustSuccess, nil)
+			So(err, ShouldBeNil)
+			r := result(xdr.OperationTypeChangeTrust, cr)
+
+			changeTrust := func(limit xdr.Int64, changes xdr.LedgerEntryChanges) EffectRecord {
+				body, err := xdr.NewOperationBody(xdr.OperationTypeChangeTrust, xdr.ChangeTrustOp{Line: usd(), Limit: limit})
+				So(err, ShouldBeNil)
+
+				effects, err := NewEffectRecords(record, xdr.Operation{Body: body}, r, changes, noSigners)
+				So(err, ShouldBeNil)
+				So(len(effects), ShouldEqual, 1)
+				return effects[0]
+			}
+
+			created := xdr.LedgerEntryChanges{{
+				Type: xdr.LedgerEntryChangeTypeLedgerEntryCreated,
+				Created: &xdr.LedgerEntry{Data: xdr.LedgerEntryData{
+					Type:      xdr.LedgerEntryTypeTrustline,
+					TrustLine: &xdr.TrustLineEntry{AccountId: aid(source), Asset: usd(), Limit: 10000000},
+				}},
+			}}
+
+			effect := changeTrust(10000000, created)
+			So(effect.Type, ShouldEqual, EffectTrustlineCreated)
+			details, err := effect.Details()
+			So(err, ShouldBeNil)
+			So(details["limit"], ShouldEqual, "1.0")
+			So(details["asset_code"], ShouldEqual, "USD")
+
+			So(changeTrust(10000000, nil).Type, ShouldEqual, EffectTrustlineUpdated)
+			So(changeTrust(0, nil).Type, ShouldEqual, EffectTrustlineRemoved)
+		})
+
+		Convey("records the trustlines issuers authorize", func() {
+			var code [4]byte
+			copy(code[:], "USD")
+			asset, err := xdr.NewAllowTrustOpAsset(xdr.AssetTypeAssetTypeCreditAlphanum4, code)
+			So(err, ShouldBeNil)
+			ar, err := xdr.NewAllowTrustResult(xdr.AllowTrustResultCodeAllowTrustSuccess, nil)
+			So(err, ShouldBeNil)
+
+			body, err := xdr.NewOperationBody(xdr.OperationTypeAllowTrust, xdr.AllowTrustOp{Trustor: aid(other), Asset: asset, Authorize: true})
+			So(err, ShouldBeNil)
+
+			effects, err := NewEffectRecords(record, xdr.Operation{Body: body}, result(xdr.OperationTypeAllowTrust, ar), nil, noSigners)
+			So(err, ShouldBeNil)
+			So(len(effects), ShouldEqual, 1)
+			So(effects[0].Account, ShouldEqual, source)
+			So(effects[0].Type, ShouldEqual, EffectTrustlineAuthorized)
+			details, err := effects[0].Details()
+			So(err, ShouldBeNil)
+			So(details["trustor"], ShouldEqual, other)
+			So(details["asset_code"], ShouldEqual, "USD")
+		})
+
+		Convey("moves the balance of merged accounts", func() {
+			body, err := xdr.NewOperationBody(xdr.OperationTypeAccountMerge, aid(other))
+			So(err, ShouldBeNil)
+			mr, err := xdr.NewAccountMergeResult(xdr.AccountMergeResultCodeAccountMergeSuccess, xdr.Int64(70000000))
+			So(err, ShouldBeNil)
+
+			effects, err := NewEffectRecords(record, xdr.Operation{Body: body}, result(xdr.OperationTypeAccountMerge, mr), nil, noSigners)
+			So(err, ShouldBeNil)
+			So(len(effects), ShouldEqual, 3)
+			So(effects[0].Type, ShouldEqual, EffectAccountDebited)
+			So(effects[1].Account, ShouldEqual, other)
+			So(effects[1].Type, ShouldEqual, EffectAccountCredited)
+			So(effects[2].Type, ShouldEqual, EffectAccountRemoved)
+
+			details, err := effects[1].Details()
+			So(err, ShouldBeNil)
+			So(details["amount"], ShouldEqual, "7.0")
+		})
+
+		Convey("credits the payouts of inflation", func() {
+			body, err := xdr.NewOperationBody(xdr.OperationTypeInflation, nil)
+			So(err, ShouldBeNil)
+			ir, err := xdr.NewInflationResult(xdr.InflationResultCodeInflationSuccess, []xdr.InflationPayout{
+				{Destination: aid(other), Amount: 10000000},
+				{Destination: aid(source), Amount: 20000000},
+			})
+			So(err, ShouldBeNil)
+
+			effects, err := NewEffectRecords(record, xdr.Operation{Body: body}, result(xdr.OperationTypeInflation, ir), nil, noSigners)
+			So(err, ShouldBeNil)
+			So(len(effects), ShouldEqual, 2)
+			So(effects[0].Account, ShouldEqual, other)
+			So(effects[0].Type, ShouldEqual, EffectAccountCredited)
+			So(effects[1].Account, ShouldEqual, source)
+		})
+	})
+}
@@ -0,0 +1,67 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/go-errors/errors"
+	"github.com/jmoiron/sqlx"
+)
+
+// PagingIndex is an index of the history database that pages of records need
+// to be found quickly, however deep into history they are, beyond the indexes
+// of the schema of horizon-importer.
+type PagingIndex struct {
+	Name    string
+	Table   string
+	Columns string
+}
+
+// PagingIndexes are the indexes CreatePagingIndexes creates.  Pages are keyed
+// on the paging tokens of their records, so that each index leads with the
+// column a page is filtered on, followed by the columns of the paging token.
+var PagingIndexes = []PagingIndex{
+	// operations of given types, such as payments
+	{"hop_by_type_and_id", "history_operations", `type, id`},
+	// effects of a given type, such as trades
+	{"hist_e_by_type_and_order", "history_effects", `type, history_operation_id, "order"`},
+}
+
+// CreatePagingIndexes creates those of PagingIndexes the database d lacks,
+// returning the names of those it created.  The indexes are built
+// concurrently, not blocking the writes of ingestion, so that they cannot be
+// created within a transaction.  Indexes left invalid by an interrupted build
+// are built again.
+func CreatePagingIndexes(d *sqlx.DB) ([]string, error) {
+	var created []string
+
+	for _, index := range PagingIndexes {
+		var valid []bool
+		err := d.Select(&valid, `
+			SELECT i.indisvalid FROM pg_index i
+			JOIN pg_class c ON c.oid = i.indexrelid
+			WHERE c.relname = $1 AND pg_table_is_visible(c.oid)`, index.Name)
+		if err != nil {
+			return created, errors.Wrap(err, 1)
+		}
+
+		if len(valid) > 0 && valid[0] {
+			continue
+		}
+
+		if len(valid) > 0 {
+			_, err = d.Exec(fmt.Sprintf("DROP INDEX CONCURRENTLY %s", index.Name))
+			if err != nil {
+				return created, errors.Wrap(err, 1)
+			}
+		}
+
+		_, err = d.Exec(fmt.Sprintf("CREATE INDEX CONCURRENTLY %s ON %s (%s)", index.Name, index.Table, index.Columns))
+		if err != nil {
+			return created, errors.Wrap(err, 1)
+		}
+
+		created = append(created, index.Name)
+	}
+
+	return created, nil
+}
@@ -0,0 +1,22 @@
+package db
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/test"
+)
+
+func TestCreatePagingIndexes(t *testing.T) {
+	Convey("CreatePagingIndexes", t, func() {
+		test.LoadScenario("base")
+
+		created, err := CreatePagingIndexes(history)
+		So(err, ShouldBeNil)
+		So(len(created), ShouldEqual, len(PagingIndexes))
+
+		created, err = CreatePagingIndexes(history)
+		So(err, ShouldBeNil)
+		So(created, ShouldBeEmpty)
+	})
+}
@@ -0,0 +1,234 @@
+package db
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"sync"
+	"time"
+
+	"github.com/go-errors/errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/jmoiron/sqlx/reflectx"
+	"github.com/rcrowley/go-metrics"
+	"github.com/stellar/horizon/log"
+	"golang.org/x/net/context"
+)
+
+// cancelTimeout bounds how long cancelling a statement may wait on a
+// connection to cancel it from
+const cancelTimeout = 5 * time.Second
+
+// maxCachedPids is the count of connections a Pool remembers the backend
+// process of before forgetting them all, those of closed connections
+// included.
+const maxCachedPids = 1024
+
+// Pool instruments the pool of connections of a database for the queries run
+// through SqlQuery, which each reserve a connection of the pool while they
+// run.
+type Pool struct {
+	// InUse gauges the connections of the pool in use, as of the last Update
+	InUse metrics.Gauge
+
+	// Waiting counts the queries waiting on a connection of the pool
+	Waiting metrics.Counter
+
+	// Wait times how long queries waited on a connection of the pool
+	Wait metrics.Timer
+
+	// Slow meters the queries run on the pool that were slow, as recorded
+	// into the SlowQueryLog
+	Slow metrics.Meter
+
+	db *sqlx.DB
+
+	// pids holds the backend process of each connection, by driver connection,
+	// so that the statements it runs may be cancelled.
+	pidsLock sync.Mutex
+	pids     map[interface{}]int
+}
+
+var (
+	poolsLock sync.Mutex
+	pools     = map[*sqlx.DB]*Pool{}
+)
+
+// PoolOf returns the Pool of the database d
+func PoolOf(d *sqlx.DB) *Pool {
+	poolsLock.Lock()
+	defer poolsLock.Unlock()
+
+	p, ok := pools[d]
+	if !ok {
+		p = &Pool{
+			InUse:   metrics.NewGauge(),
+			Waiting: metrics.NewCounter(),
+			Wait:    metrics.NewTimer(),
+			Slow:    metrics.NewMeter(),
+			db:      d,
+			pids:    map[interface{}]int{},
+		}
+		pools[d] = p
+	}
+
+	return p
+}
+
+// Update refreshes the gauges of p read off the pool itself
+func (p *Pool) Update() {
+	p.InUse.Update(int64(p.db.Stats().InUse))
+}
+
+// run runs fn with a connection of the pool reserved for it, under the timeout
+// of the class of the queries of ctx.  Should ctx be done before fn returns,
+// as when the client of a request goes away or the timeout passes, the
+// statement running on the connection is cancelled and the connection
+// discarded.
+func (p *Pool) run(ctx context.Context, fn func(q sqlx.Queryer) error) error {
+	parent := ctx
+	if timeout := QueryTimeout(ctx); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	conn, err := p.acquire(ctx)
+	if err != nil {
+		return interrupted(parent, ctx, err)
+	}
+	defer conn.Close()
+
+	q := connQueryer{ctx: ctx, conn: conn, mapper: p.db.Mapper}
+	if ctx.Done() == nil || !DialectOf(p.db).Cancellable() {
+		return interrupted(parent, ctx, fn(q))
+	}
+
+	pid, err := p.backendPID(ctx, conn)
+	if err != nil {
+		return interrupted(parent, ctx, err)
+	}
+
+	done := make(chan struct{})
+	cancelled := make(chan bool, 1)
+	go func() {
+		select {
+		case <-done:
+			cancelled <- false
+		case <-ctx.Done():
+			p.cancel(ctx, pid)
+			cancelled <- true
+		}
+	}()
+
+	err = fn(q)
+	close(done)
+
+	if <-cancelled {
+		// a cancel delivered late must not reach the next query of the
+		// connection
+		conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+	}
+
+	return interrupted(parent, ctx, err)
+}
+
+// acquire reserves a connection of the pool, to be closed by the caller once
+// done with it.
+func (p *Pool) acquire(ctx context.Context) (*sql.Conn, error) {
+	start := time.Now()
+	p.Waiting.Inc(1)
+	conn, err := p.db.Conn(ctx)
+	p.Waiting.Dec(1)
+	p.Wait.UpdateSince(start)
+
+	if err != nil {
+		return nil, errors.Wrap(err, 1)
+	}
+
+	return conn, nil
+}
+
+// backendPID returns the backend process of conn
+func (p *Pool) backendPID(ctx context.Context, conn *sql.Conn) (int, error) {
+	var key interface{}
+	conn.Raw(func(dc interface{}) error {
+		key = dc
+		return nil
+	})
+
+	p.pidsLock.Lock()
+	pid, ok := p.pids[key]
+	p.pidsLock.Unlock()
+	if ok {
+		return pid, nil
+	}
+
+	err := conn.QueryRowContext(ctx, "SELECT pg_backend_pid()").Scan(&pid)
+	if err != nil {
+		return 0, errors.Wrap(err, 1)
+	}
+
+	p.pidsLock.Lock()
+	if len(p.pids) >= maxCachedPids {
+		p.pids = map[interface{}]int{}
+	}
+	p.pids[key] = pid
+	p.pidsLock.Unlock()
+
+	return pid, nil
+}
+
+// cancel cancels the statement the backend process pid runs, from another
+// connection of the pool
+func (p *Pool) cancel(ctx context.Context, pid int) {
+	cctx, cancel := context.WithTimeout(context.Background(), cancelTimeout)
+	defer cancel()
+
+	_, err := p.db.ExecContext(cctx, "SELECT pg_cancel_backend($1)", pid)
+	if err != nil {
+		log.WithField(ctx, "pid", pid).Warnf("failed to cancel query: %s", err)
+	}
+}
+
+// interrupted returns err, or the error telling why the query it failed was
+// interrupted when ctx, derived from parent, is done.  Queries running past
+// the deadline of parent, such as that of the request they load, have timed
+// out as much as those running past their own.
+func interrupted(parent, ctx context.Context, err error) error {
+	if err == nil || ctx.Err() == nil {
+		return err
+	}
+
+	if ctx.Err() == context.DeadlineExceeded && parent.Err() != context.Canceled {
+		return ErrQueryTimeout
+	}
+
+	return ErrQueryCanceled
+}
+
+// connQueryer is the sqlx.Queryer of the queries selected on a reserved
+// connection.
+type connQueryer struct {
+	ctx    context.Context
+	conn   *sql.Conn
+	mapper *reflectx.Mapper
+}
+
+func (q connQueryer) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return q.conn.QueryContext(q.ctx, query, args...)
+}
+
+func (q connQueryer) Queryx(query string, args ...interface{}) (*sqlx.Rows, error) {
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqlx.Rows{Rows: rows, Mapper: q.mapper}, nil
+}
+
+// QueryRowx is a method for sqlx.Queryer.  It is never called, as SqlQuery
+// gets its rows by selecting them.
+func (q connQueryer) QueryRowx(query string, args ...interface{}) *sqlx.Row {
+	panic("db: QueryRowx is unsupported on reserved connections")
+}
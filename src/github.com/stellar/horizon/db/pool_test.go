@@ -0,0 +1,55 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"golang.org/x/net/context"
+)
+
+func TestPool(t *testing.T) {
+	Convey("SqlQuery", t, func() {
+		q := SqlQuery{history}
+		var slept []string
+
+		Convey("cancels queries running past their timeout", func() {
+			SetQueryTimeouts(QueryTimeouts{Page: 100 * time.Millisecond})
+			defer SetQueryTimeouts(QueryTimeouts{})
+
+			start := time.Now()
+			err := q.SelectRaw(ctx, "SELECT pg_sleep(5)::text", nil, &slept)
+			So(err, ShouldEqual, ErrQueryTimeout)
+			So(time.Since(start), ShouldBeLessThan, 5*time.Second)
+		})
+
+		Convey("times out queries running past the deadline of their context", func() {
+			tctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+			defer cancel()
+
+			err := q.SelectRaw(tctx, "SELECT pg_sleep(5)::text", nil, &slept)
+			So(err, ShouldEqual, ErrQueryTimeout)
+		})
+
+		Convey("cancels queries whose context is done", func() {
+			cctx, cancel := context.WithCancel(ctx)
+			time.AfterFunc(100*time.Millisecond, cancel)
+
+			start := time.Now()
+			err := q.SelectRaw(cctx, "SELECT pg_sleep(5)::text", nil, &slept)
+			So(err, ShouldEqual, ErrQueryCanceled)
+			So(time.Since(start), ShouldBeLessThan, 5*time.Second)
+		})
+
+		Convey("times the wait for a connection", func() {
+			pool := PoolOf(history)
+			before := pool.Wait.Count()
+
+			var n int
+			So(q.GetRaw(ctx, "SELECT 1", nil, &n), ShouldBeNil)
+			So(n, ShouldEqual, 1)
+			So(pool.Wait.Count(), ShouldEqual, before+1)
+			So(pool.Waiting.Count(), ShouldEqual, 0)
+		})
+	})
+}
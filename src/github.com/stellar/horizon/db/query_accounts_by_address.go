@@ -0,0 +1,64 @@
+package db
+
+import (
+	sq "github.com/lann/squirrel"
+	"golang.org/x/net/context"
+)
+
+// AccountsByAddressQuery is the batch form of AccountByAddressQuery: it loads
+// the AccountRecords of many addresses at once, in a fixed number of queries.
+// The records are in the order of Addresses, less repeated addresses and those
+// of accounts either database does not know.
+type AccountsByAddressQuery struct {
+	History   SqlQuery
+	Core      SqlQuery
+	Addresses []string
+}
+
+func (q AccountsByAddressQuery) Select(ctx context.Context, dest interface{}) error {
+	result := []AccountRecord{}
+	if len(q.Addresses) == 0 {
+		return setOn(result, dest)
+	}
+
+	var histories []HistoryAccountRecord
+	err := q.History.Select(ctx, HistoryAccountRecordSelect.Where(sq.Eq{"ha.address": q.Addresses}), &histories)
+	if err != nil {
+		return err
+	}
+
+	var accounts []CoreAccountRecord
+	err = q.Core.Select(ctx, CoreAccountRecordSelect.Where(sq.Eq{"a.accountid": q.Addresses}), &accounts)
+	if err != nil {
+		return err
+	}
+
+	byAddress := map[string]HistoryAccountRecord{}
+	for _, h := range histories {
+		byAddress[h.Address] = h
+	}
+
+	cores := map[string]CoreAccountRecord{}
+	for _, a := range accounts {
+		cores[a.Accountid] = a
+	}
+
+	seen := map[string]bool{}
+	for _, address := range q.Addresses {
+		h, inHistory := byAddress[address]
+		a, inCore := cores[address]
+		if !inHistory || !inCore || seen[address] {
+			continue
+		}
+
+		seen[address] = true
+		result = append(result, AccountRecord{HistoryAccountRecord: h, CoreAccountRecord: a})
+	}
+
+	err = loadCoreAccountDetails(ctx, q.Core, result)
+	if err != nil {
+		return err
+	}
+
+	return setOn(result, dest)
+}
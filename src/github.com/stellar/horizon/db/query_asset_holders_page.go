@@ -0,0 +1,72 @@
+package db
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/go-errors/errors"
+	"golang.org/x/net/context"
+)
+
+// AssetHolderCursorSep separates the balance and account of a trustline in
+// the paging tokens of TrustlineRecords.
+const AssetHolderCursorSep = "_"
+
+// AssetHoldersPageQuery loads a page of the trustlines held for the asset of
+// code Code from Issuer, from the current_trustlines that ingestion keeps,
+// ordered by balance and then account.
+type AssetHoldersPageQuery struct {
+	SqlQuery
+	PageQuery
+	Code   string
+	Issuer string
+}
+
+func (q AssetHoldersPageQuery) Select(ctx context.Context, dest interface{}) error {
+	sql := TrustlineRecordSelect.
+		Where("tl.assetcode = ?", q.Code).
+		Where("tl.issuer = ?", q.Issuer).
+		Limit(uint64(q.Limit))
+
+	balance, account, err := q.CursorHolder()
+	if err != nil {
+		return err
+	}
+
+	switch q.Order {
+	case "asc":
+		if q.Cursor != "" {
+			sql = sql.Where("(tl.balance, tl.accountid) > (?, ?)", balance, account)
+		}
+		sql = sql.OrderBy("tl.balance asc", "tl.accountid asc")
+	case "desc":
+		if q.Cursor != "" {
+			sql = sql.Where("(tl.balance, tl.accountid) < (?, ?)", balance, account)
+		}
+		sql = sql.OrderBy("tl.balance desc", "tl.accountid desc")
+	}
+
+	return q.SqlQuery.Select(ctx, sql, dest)
+}
+
+// CursorHolder parses the query's Cursor as the balance and account of a
+// trustline.  An empty cursor returns zero values.
+func (q AssetHoldersPageQuery) CursorHolder() (balance int64, account string, err error) {
+	if q.Cursor == "" {
+		return
+	}
+
+	parts := strings.SplitN(q.Cursor, AssetHolderCursorSep, 2)
+	if len(parts) != 2 || parts[1] == "" {
+		err = errors.New(ErrInvalidCursor)
+		return
+	}
+
+	balance, err = strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		err = errors.New(ErrInvalidCursor)
+		return
+	}
+
+	return balance, parts[1], nil
+}
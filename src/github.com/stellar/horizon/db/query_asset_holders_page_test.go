@@ -0,0 +1,77 @@
+package db
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/test"
+)
+
+func TestAssetHoldersPageQuery(t *testing.T) {
+	const issuer = "GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4"
+
+	Convey("AssetHoldersPageQuery", t, func() {
+		test.LoadScenario("base")
+
+		for _, tl := range []struct {
+			account string
+			code    string
+			balance int64
+			removed bool
+		}{
+			{"GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON", "USD", 300, false},
+			{"GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU", "USD", 100, false},
+			{"GA5WBPYA5Y4WAEHXWR2UKO2UO4BUGHUQ74EUPKON2QHV4WRHOIRNKKH2", "USD", 200, false},
+			{"GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H", "USD", 0, true},
+			{"GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H", "EUR", 500, false},
+		} {
+			history.MustExec(`
+				INSERT INTO current_trustlines
+				(accountid, assettype, issuer, assetcode, tlimit, balance, flags, lastmodified, removed)
+				VALUES ($1, 1, $2, $3, 1000, $4, 1, 3, $5)`,
+				tl.account, issuer, tl.code, tl.balance, tl.removed)
+		}
+
+		makeQuery := func(c string, o string, l int32) AssetHoldersPageQuery {
+			pq, err := NewPageQuery(c, o, l)
+			So(err, ShouldBeNil)
+
+			return AssetHoldersPageQuery{
+				SqlQuery:  SqlQuery{history},
+				PageQuery: pq,
+				Code:      "USD",
+				Issuer:    issuer,
+			}
+		}
+
+		var records []TrustlineRecord
+
+		Convey("orders the holders of the asset by balance", func() {
+			MustSelect(ctx, makeQuery("", "asc", 0), &records)
+			So(len(records), ShouldEqual, 3)
+			So(records[0].Balance, ShouldEqual, 100)
+			So(records[1].Balance, ShouldEqual, 200)
+			So(records[2].Balance, ShouldEqual, 300)
+			So(records[2].IsAuthorized(), ShouldBeTrue)
+
+			MustSelect(ctx, makeQuery("", "desc", 0), &records)
+			So(records[0].Balance, ShouldEqual, 300)
+		})
+
+		Convey("cursor works properly", func() {
+			MustSelect(ctx, makeQuery("", "desc", 1), &records)
+			So(len(records), ShouldEqual, 1)
+
+			MustSelect(ctx, makeQuery(records[0].PagingToken(), "desc", 0), &records)
+			So(len(records), ShouldEqual, 2)
+			So(records[0].Balance, ShouldEqual, 200)
+
+			MustSelect(ctx, makeQuery(records[0].PagingToken(), "asc", 0), &records)
+			So(len(records), ShouldEqual, 1)
+			So(records[0].Balance, ShouldEqual, 300)
+
+			err := Select(ctx, makeQuery("300", "asc", 0), &records)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,130 @@
+package db
+
+import (
+	sq "github.com/lann/squirrel"
+	"golang.org/x/net/context"
+)
+
+// CoreAccountPageQuery loads a page of accounts from stellar-core, ordered by
+// address, along with their trustlines and signers.  Each filter that is set
+// restricts the page further: Signer to the accounts the key can sign for,
+// including the account whose master key it is; AssetCode and AssetIssuer to
+// the accounts trusting that asset; HomeDomain to the accounts with that home
+// domain.
+//
+// The records loaded are AccountRecords whose history account carries only the
+// address, as stellar-core knows nothing of history.
+type CoreAccountPageQuery struct {
+	SqlQuery
+	PageQuery
+	Signer      string
+	AssetCode   string
+	AssetIssuer string
+	HomeDomain  string
+}
+
+func (q CoreAccountPageQuery) Select(ctx context.Context, dest interface{}) error {
+	sql := CoreAccountRecordSelect.Limit(uint64(q.Limit))
+
+	if q.Signer != "" {
+		sql = sql.Where(
+			"(a.accountid = ? OR a.accountid IN (SELECT accountid FROM signers WHERE publickey = ?))",
+			q.Signer,
+			q.Signer,
+		)
+	}
+
+	if q.AssetCode != "" || q.AssetIssuer != "" {
+		sql = sql.Where(
+			"a.accountid IN (SELECT accountid FROM trustlines WHERE assetcode = ? AND issuer = ?)",
+			q.AssetCode,
+			q.AssetIssuer,
+		)
+	}
+
+	if q.HomeDomain != "" {
+		sql = sql.Where("a.homedomain = ?", q.HomeDomain)
+	}
+
+	cursor, err := q.CursorAddress()
+	if err != nil {
+		return err
+	}
+
+	switch q.Order {
+	case "asc":
+		if cursor != "" {
+			sql = sql.Where("a.accountid > ?", cursor)
+		}
+		sql = sql.OrderBy("a.accountid asc")
+	case "desc":
+		if cursor != "" {
+			sql = sql.Where("a.accountid < ?", cursor)
+		}
+		sql = sql.OrderBy("a.accountid desc")
+	}
+
+	var accounts []CoreAccountRecord
+	err = q.SqlQuery.Select(ctx, sql, &accounts)
+	if err != nil {
+		return err
+	}
+
+	result := make([]AccountRecord, len(accounts))
+	for i, account := range accounts {
+		result[i].Address = account.Accountid
+		result[i].CoreAccountRecord = account
+	}
+
+	err = loadCoreAccountDetails(ctx, q.SqlQuery, result)
+	if err != nil {
+		return err
+	}
+
+	return setOn(result, dest)
+}
+
+// loadCoreAccountDetails loads, in one query each, the trustlines and signers
+// of records, whose CoreAccountRecords are already loaded.
+func loadCoreAccountDetails(ctx context.Context, q SqlQuery, records []AccountRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	addresses := make([]string, len(records))
+	byAddress := make(map[string]*AccountRecord, len(records))
+	for i := range records {
+		addresses[i] = records[i].Accountid
+		byAddress[records[i].Accountid] = &records[i]
+	}
+
+	var trustlines []CoreTrustlineRecord
+	err := q.Select(ctx, CoreTrustlineRecordSelect.Where(sq.Eq{"tl.accountid": addresses}), &trustlines)
+	if err != nil {
+		return err
+	}
+
+	for _, tl := range trustlines {
+		r := byAddress[tl.Accountid]
+		r.Trustlines = append(r.Trustlines, tl)
+	}
+
+	var signers []CoreSignerRecord
+	err = q.Select(ctx, CoreSignerRecordSelect.Where(sq.Eq{"si.accountid": addresses}), &signers)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range signers {
+		r := byAddress[s.Accountid]
+		r.Signers = append(r.Signers, s)
+	}
+
+	return nil
+}
+
+// CursorAddress returns the query's Cursor as the address of an account.  An
+// empty cursor returns an empty string.
+func (q CoreAccountPageQuery) CursorAddress() (string, error) {
+	return q.Cursor, nil
+}
@@ -0,0 +1,79 @@
+package db
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/test"
+)
+
+func TestCoreAccountPageQuery(t *testing.T) {
+
+	Convey("CoreAccountPageQuery", t, func() {
+		test.LoadScenario("set_options")
+
+		makeQuery := func(c string, o string, l int32) CoreAccountPageQuery {
+			pq, err := NewPageQuery(c, o, l)
+
+			So(err, ShouldBeNil)
+
+			return CoreAccountPageQuery{
+				SqlQuery:  SqlQuery{core},
+				PageQuery: pq,
+			}
+		}
+
+		var records []AccountRecord
+
+		Convey("orders by address", func() {
+			MustSelect(ctx, makeQuery("", "asc", 0), &records)
+			So(len(records), ShouldEqual, 3)
+			So(records[0].Address, ShouldEqual, "GA5WBPYA5Y4WAEHXWR2UKO2UO4BUGHUQ74EUPKON2QHV4WRHOIRNKKH2")
+			So(records[1].Address, ShouldEqual, "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H")
+			So(records[2].Address, ShouldEqual, "GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU")
+
+			MustSelect(ctx, makeQuery("", "desc", 0), &records)
+			So(records[0].Address, ShouldEqual, "GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU")
+		})
+
+		Convey("filters by home domain", func() {
+			q := makeQuery("", "asc", 0)
+			q.HomeDomain = "nullstyle.com"
+			MustSelect(ctx, q, &records)
+			So(len(records), ShouldEqual, 1)
+			So(records[0].Address, ShouldEqual, "GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU")
+		})
+
+		Convey("filters by signer, including master keys", func() {
+			q := makeQuery("", "asc", 0)
+			q.Signer = "GA5WBPYA5Y4WAEHXWR2UKO2UO4BUGHUQ74EUPKON2QHV4WRHOIRNKKH2"
+			MustSelect(ctx, q, &records)
+			So(len(records), ShouldEqual, 1)
+			So(records[0].Address, ShouldEqual, "GA5WBPYA5Y4WAEHXWR2UKO2UO4BUGHUQ74EUPKON2QHV4WRHOIRNKKH2")
+		})
+
+		Convey("cursor works properly", func() {
+			MustSelect(ctx, makeQuery("GA5WBPYA5Y4WAEHXWR2UKO2UO4BUGHUQ74EUPKON2QHV4WRHOIRNKKH2", "asc", 0), &records)
+			So(len(records), ShouldEqual, 2)
+			So(records[0].Address, ShouldEqual, "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H")
+
+			MustSelect(ctx, makeQuery("GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H", "desc", 0), &records)
+			So(len(records), ShouldEqual, 1)
+			So(records[0].Address, ShouldEqual, "GA5WBPYA5Y4WAEHXWR2UKO2UO4BUGHUQ74EUPKON2QHV4WRHOIRNKKH2")
+		})
+
+		Convey("filters by asset, loading trustlines", func() {
+			test.LoadScenario("non_native_payment")
+
+			q := makeQuery("", "asc", 0)
+			q.AssetCode = "USD"
+			q.AssetIssuer = "GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4"
+			MustSelect(ctx, q, &records)
+			So(len(records), ShouldEqual, 2)
+			So(records[0].Address, ShouldEqual, "GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON")
+			So(records[1].Address, ShouldEqual, "GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU")
+			So(len(records[0].Trustlines), ShouldEqual, 1)
+			So(records[0].Trustlines[0].Assetcode, ShouldEqual, "USD")
+		})
+	})
+}
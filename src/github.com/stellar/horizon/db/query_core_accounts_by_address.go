@@ -0,0 +1,47 @@
+package db
+
+import (
+	sq "github.com/lann/squirrel"
+	"golang.org/x/net/context"
+)
+
+// CoreAccountsByAddressQuery loads from stellar-core alone the AccountRecords
+// of many addresses at once, along with their trustlines and signers.  As with
+// CoreAccountPageQuery, the history account of each record carries only the
+// address.  Records are ordered by address, and accounts stellar-core does not
+// know are left out.
+type CoreAccountsByAddressQuery struct {
+	SqlQuery
+	Addresses []string
+}
+
+func (q CoreAccountsByAddressQuery) Select(ctx context.Context, dest interface{}) error {
+	result := []AccountRecord{}
+	if len(q.Addresses) == 0 {
+		return setOn(result, dest)
+	}
+
+	sql := CoreAccountRecordSelect.
+		Where(sq.Eq{"a.accountid": q.Addresses}).
+		OrderBy("a.accountid asc")
+
+	var accounts []CoreAccountRecord
+	err := q.SqlQuery.Select(ctx, sql, &accounts)
+	if err != nil {
+		return err
+	}
+
+	for _, account := range accounts {
+		result = append(result, AccountRecord{
+			HistoryAccountRecord: HistoryAccountRecord{Address: account.Accountid},
+			CoreAccountRecord:    account,
+		})
+	}
+
+	err = loadCoreAccountDetails(ctx, q.SqlQuery, result)
+	if err != nil {
+		return err
+	}
+
+	return setOn(result, dest)
+}
@@ -0,0 +1,39 @@
+package db
+
+import (
+	"testing"
+
+	_ "github.com/lib/pq"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/test"
+)
+
+func TestCoreAccountsByAddressQuery(t *testing.T) {
+	test.LoadScenario("non_native_payment")
+
+	Convey("CoreAccountsByAddressQuery", t, func() {
+		var accounts []AccountRecord
+
+		withtl := "GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON"
+		notl := "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H"
+
+		q := CoreAccountsByAddressQuery{
+			SqlQuery:  SqlQuery{core},
+			Addresses: []string{withtl, "not_real", notl},
+		}
+
+		MustSelect(ctx, q, &accounts)
+		So(len(accounts), ShouldEqual, 2)
+
+		So(accounts[0].Address, ShouldEqual, notl)
+		So(len(accounts[0].Trustlines), ShouldEqual, 0)
+
+		So(accounts[1].Address, ShouldEqual, withtl)
+		So(accounts[1].Seqnum, ShouldEqual, 8589934593)
+		So(len(accounts[1].Trustlines), ShouldEqual, 1)
+
+		q.Addresses = nil
+		MustSelect(ctx, q, &accounts)
+		So(len(accounts), ShouldEqual, 0)
+	})
+}
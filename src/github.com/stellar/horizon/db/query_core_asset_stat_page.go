@@ -0,0 +1,86 @@
+package db
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/go-errors/errors"
+	"golang.org/x/net/context"
+)
+
+// AssetStatCursorSep separates the code and issuer of an asset in the paging
+// tokens of CoreAssetStatRecords.
+const AssetStatCursorSep = "_"
+
+// CoreAssetStatPageQuery pages Stats, the statistics of the assets issued on
+// the network loaded by CoreAssetStatsQuery, ordered by code and then issuer.
+// When set, Code and Issuer restrict the page to assets with that code, or from
+// that issuer.
+type CoreAssetStatPageQuery struct {
+	PageQuery
+	Code   string
+	Issuer string
+	Stats  []CoreAssetStatRecord `json:"-"`
+}
+
+func (q CoreAssetStatPageQuery) Select(ctx context.Context, dest interface{}) error {
+	code, issuer, err := q.CursorAsset()
+	if err != nil {
+		return err
+	}
+
+	// the first stat after the cursor, in ascending order
+	start := 0
+	if q.Cursor != "" {
+		start = sort.Search(len(q.Stats), func(i int) bool {
+			s := q.Stats[i]
+			return assetBefore(code, issuer, s.Assetcode, s.Issuer)
+		})
+	}
+
+	records := []CoreAssetStatRecord{}
+	matches := func(s CoreAssetStatRecord) bool {
+		return (q.Code == "" || s.Assetcode == q.Code) &&
+			(q.Issuer == "" || s.Issuer == q.Issuer)
+	}
+
+	switch q.Order {
+	case "asc":
+		for i := start; i < len(q.Stats) && len(records) < int(q.Limit); i++ {
+			if matches(q.Stats[i]) {
+				records = append(records, q.Stats[i])
+			}
+		}
+	case "desc":
+		end := len(q.Stats)
+		if q.Cursor != "" {
+			end = sort.Search(len(q.Stats), func(i int) bool {
+				s := q.Stats[i]
+				return !assetBefore(s.Assetcode, s.Issuer, code, issuer)
+			})
+		}
+
+		for i := end - 1; i >= 0 && len(records) < int(q.Limit); i-- {
+			if matches(q.Stats[i]) {
+				records = append(records, q.Stats[i])
+			}
+		}
+	}
+
+	return setOn(records, dest)
+}
+
+// CursorAsset parses the query's Cursor as the code and issuer of an asset.  An empty cursor returns empty strings.
+func (q CoreAssetStatPageQuery) CursorAsset() (code string, issuer string, err error) {
+	if q.Cursor == "" {
+		return
+	}
+
+	parts := strings.SplitN(q.Cursor, AssetStatCursorSep, 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		err = errors.New(ErrInvalidCursor)
+		return
+	}
+
+	return parts[0], parts[1], nil
+}
@@ -0,0 +1,100 @@
+package db
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/test"
+)
+
+func TestCoreAssetStatPageQuery(t *testing.T) {
+	test.LoadScenario("trades")
+
+	Convey("CoreAssetStatPageQuery", t, func() {
+		var stats []CoreAssetStatRecord
+		MustSelect(ctx, CoreAssetStatsQuery{SqlQuery{core}}, &stats)
+
+		makeQuery := func(c string, o string, l int32) CoreAssetStatPageQuery {
+			pq, err := NewPageQuery(c, o, l)
+
+			So(err, ShouldBeNil)
+
+			return CoreAssetStatPageQuery{
+				PageQuery: pq,
+				Stats:     stats,
+			}
+		}
+
+		var records []CoreAssetStatRecord
+
+		Convey("aggregates the trustlines of each asset", func() {
+			MustSelect(ctx, makeQuery("", "asc", 0), &records)
+			So(len(records), ShouldEqual, 2)
+
+			So(records[0].Assetcode, ShouldEqual, "EUR")
+			So(records[0].Issuer, ShouldEqual, "GCQPYGH4K57XBDENKKX55KDTWOTK5WDWRQOH2LHEDX3EKVIQRLMESGBG")
+			So(records[0].Amount, ShouldEqual, 5000000000)
+			So(records[0].Numaccounts, ShouldEqual, 2)
+			So(records[1].Assetcode, ShouldEqual, "USD")
+		})
+
+		Convey("filters properly", func() {
+			q := makeQuery("", "asc", 0)
+			q.Code = "USD"
+			MustSelect(ctx, q, &records)
+			So(len(records), ShouldEqual, 1)
+			So(records[0].Assetcode, ShouldEqual, "USD")
+
+			q = makeQuery("", "asc", 0)
+			q.Issuer = "GCQPYGH4K57XBDENKKX55KDTWOTK5WDWRQOH2LHEDX3EKVIQRLMESGBG"
+			MustSelect(ctx, q, &records)
+			So(len(records), ShouldEqual, 1)
+			So(records[0].Assetcode, ShouldEqual, "EUR")
+
+			q = makeQuery("", "asc", 0)
+			q.Code = "USD"
+			q.Issuer = "GCQPYGH4K57XBDENKKX55KDTWOTK5WDWRQOH2LHEDX3EKVIQRLMESGBG"
+			MustSelect(ctx, q, &records)
+			So(len(records), ShouldEqual, 0)
+		})
+
+		Convey("cursor works properly", func() {
+			var record CoreAssetStatRecord
+
+			MustGet(ctx, makeQuery("", "desc", 0), &record)
+			So(record.Assetcode, ShouldEqual, "USD")
+
+			MustGet(ctx, makeQuery("EUR_GCQPYGH4K57XBDENKKX55KDTWOTK5WDWRQOH2LHEDX3EKVIQRLMESGBG", "asc", 0), &record)
+			So(record.Assetcode, ShouldEqual, "USD")
+
+			MustSelect(ctx, makeQuery("EUR_GCQPYGH4K57XBDENKKX55KDTWOTK5WDWRQOH2LHEDX3EKVIQRLMESGBG", "desc", 0), &records)
+			So(len(records), ShouldEqual, 0)
+
+			err := Select(ctx, makeQuery("EUR", "asc", 0), &records)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("pages past filtered assets up to the limit", func() {
+			stats = []CoreAssetStatRecord{
+				{Assetcode: "AAA", Issuer: "GA"},
+				{Assetcode: "USD", Issuer: "GA"},
+				{Assetcode: "USD", Issuer: "GB"},
+				{Assetcode: "USD", Issuer: "GC"},
+				{Assetcode: "ZZZ", Issuer: "GA"},
+			}
+
+			q := makeQuery("USD_GA", "asc", 1)
+			q.Code = "USD"
+			MustSelect(ctx, q, &records)
+			So(len(records), ShouldEqual, 1)
+			So(records[0].Issuer, ShouldEqual, "GB")
+
+			q = makeQuery("USD_GC", "desc", 0)
+			q.Issuer = "GA"
+			MustSelect(ctx, q, &records)
+			So(len(records), ShouldEqual, 2)
+			So(records[0].Assetcode, ShouldEqual, "USD")
+			So(records[1].Assetcode, ShouldEqual, "AAA")
+		})
+	})
+}
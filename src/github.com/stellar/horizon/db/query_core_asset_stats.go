@@ -0,0 +1,45 @@
+package db
+
+import (
+	"sort"
+
+	"golang.org/x/net/context"
+)
+
+// CoreAssetStatsQuery loads the statistics of every asset issued on the
+// network, ordered by code and then issuer, as CoreAssetStatPageQuery pages
+// them.
+type CoreAssetStatsQuery struct {
+	SqlQuery
+}
+
+func (q CoreAssetStatsQuery) Select(ctx context.Context, dest interface{}) error {
+	var records []CoreAssetStatRecord
+	err := q.SqlQuery.Select(ctx, CoreAssetStatRecordSelect, &records)
+	if err != nil {
+		return err
+	}
+
+	// sorted here rather than by the database, whose collation may not order
+	// codes as CoreAssetStatPageQuery compares them
+	sort.Sort(assetStatsByAsset(records))
+	return setOn(records, dest)
+}
+
+// assetStatsByAsset sorts asset statistics by asset code and then issuer
+type assetStatsByAsset []CoreAssetStatRecord
+
+func (s assetStatsByAsset) Len() int      { return len(s) }
+func (s assetStatsByAsset) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s assetStatsByAsset) Less(i, j int) bool {
+	return assetBefore(s[i].Assetcode, s[i].Issuer, s[j].Assetcode, s[j].Issuer)
+}
+
+// assetBefore returns true if the asset code:issuer sorts before the asset
+// ocode:oissuer.
+func assetBefore(code, issuer, ocode, oissuer string) bool {
+	if code != ocode {
+		return code < ocode
+	}
+	return issuer < oissuer
+}
@@ -0,0 +1,38 @@
+package db
+
+import (
+	sq "github.com/lann/squirrel"
+	"golang.org/x/net/context"
+)
+
+// CoreInflationDestinationRecord totals the accounts voting for an inflation
+// destination, and the balances they vote with.
+type CoreInflationDestinationRecord struct {
+	Destination string `db:"destination"`
+	NumAccounts int32  `db:"num_accounts"`
+	Votes       int64  `db:"votes"`
+}
+
+// CoreInflationDestinationsQuery totals, for each account stellar-core knows
+// to be the inflation destination of others, the accounts voting for it and
+// their balances.  The records are ordered by votes, the most first, and then
+// by destination.
+type CoreInflationDestinationsQuery struct {
+	SqlQuery
+}
+
+// Select executes the query, populating dest with CoreInflationDestinationRecords
+func (q CoreInflationDestinationsQuery) Select(ctx context.Context, dest interface{}) error {
+	sql := sq.
+		Select(
+			"a.inflationdest AS destination",
+			"COUNT(*) AS num_accounts",
+			"SUM(a.balance)::bigint AS votes",
+		).
+		From("accounts a").
+		Where("a.inflationdest IS NOT NULL").
+		GroupBy("a.inflationdest").
+		OrderBy("votes desc", "destination asc")
+
+	return q.SqlQuery.Select(ctx, sql, dest)
+}
@@ -0,0 +1,31 @@
+package db
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/test"
+)
+
+func TestCoreInflationDestinationsQuery(t *testing.T) {
+	Convey("CoreInflationDestinationsQuery", t, func() {
+		var records []CoreInflationDestinationRecord
+
+		Convey("totals the votes for each destination", func() {
+			test.LoadScenario("set_options")
+			MustSelect(ctx, CoreInflationDestinationsQuery{SqlQuery{core}}, &records)
+			So(len(records), ShouldEqual, 1)
+			So(records[0].Destination, ShouldEqual, "GA5WBPYA5Y4WAEHXWR2UKO2UO4BUGHUQ74EUPKON2QHV4WRHOIRNKKH2")
+			So(records[0].NumAccounts, ShouldEqual, 1)
+			So(records[0].Votes, ShouldEqual, 9999999100)
+		})
+
+		Convey("orders destinations by votes", func() {
+			test.LoadScenario("inflation")
+			MustSelect(ctx, CoreInflationDestinationsQuery{SqlQuery{core}}, &records)
+			So(len(records), ShouldEqual, 2)
+			So(records[0].Destination, ShouldEqual, "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H")
+			So(records[1].Votes, ShouldEqual, 20003814419999907)
+		})
+	})
+}
@@ -0,0 +1,14 @@
+package db
+
+import "golang.org/x/net/context"
+
+// CoreOffersQuery loads every active offer known to stellar-core, in order of
+// offer id.
+type CoreOffersQuery struct {
+	SqlQuery
+}
+
+func (q CoreOffersQuery) Select(ctx context.Context, dest interface{}) error {
+	sql := CoreOfferRecordSelect.OrderBy("co.offerid asc")
+	return q.SqlQuery.Select(ctx, sql, dest)
+}
@@ -0,0 +1,25 @@
+package db
+
+import (
+	"testing"
+
+	_ "github.com/lib/pq"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/test"
+)
+
+func TestCoreOffersQuery(t *testing.T) {
+	test.LoadScenario("paths")
+
+	Convey("CoreOffersQuery", t, func() {
+		var offers []CoreOfferRecord
+
+		err := Select(ctx, CoreOffersQuery{SqlQuery{core}}, &offers)
+		So(err, ShouldBeNil)
+		So(len(offers), ShouldEqual, 10)
+
+		for i, offer := range offers {
+			So(offer.OfferID, ShouldEqual, i+1)
+		}
+	})
+}
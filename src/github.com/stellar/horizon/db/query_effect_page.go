@@ -30,24 +30,16 @@ func (q EffectPageQuery) Select(ctx context.Context, dest interface{}) (err erro
 		cursorOrd = math.MaxInt32
 	}
 
+	// the cursor is compared as a row, so that the page is a range scan of
+	// the indexes on (..., history_operation_id, order)
 	switch q.Order {
 	case "asc":
 		sql = sql.
-			Where(`(
-					 heff.history_operation_id > ? 
-				OR (
-							heff.history_operation_id = ?
-					AND heff.order > ?
-				))`, cursorOp, cursorOp, cursorOrd).
+			Where(`(heff.history_operation_id, heff.order) > (?, ?)`, cursorOp, cursorOrd).
 			OrderBy("heff.history_operation_id asc, heff.order asc")
 	case "desc":
 		sql = sql.
-			Where(`(
-					 heff.history_operation_id < ? 
-				OR (
-							heff.history_operation_id = ?
-					AND heff.order < ?
-				))`, cursorOp, cursorOp, cursorOrd).
+			Where(`(heff.history_operation_id, heff.order) < (?, ?)`, cursorOp, cursorOrd).
 			OrderBy("heff.history_operation_id desc, heff.order desc")
 	}
 
@@ -184,6 +184,48 @@ func TestEffectPageQueryByOrderBook(t *testing.T) {
 			So(dets["bought_asset_issuer"], ShouldEqual, "GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4")
 		})
 
+		Convey("restricts to offer properly", func() {
+			q := EffectPageQuery{
+				SqlQuery:  SqlQuery{history},
+				PageQuery: MustPageQuery("", "asc", 0),
+				Filter:    &EffectOfferFilter{OfferID: 1},
+			}
+
+			MustSelect(ctx, q, &records)
+			So(len(records), ShouldEqual, 2)
+
+			q.Filter = &EffectOfferFilter{OfferID: 2}
+			MustSelect(ctx, q, &records)
+			So(len(records), ShouldEqual, 0)
+		})
+
+		Convey("restricts to asset pair in either direction", func() {
+			q := EffectPageQuery{
+				SqlQuery:  SqlQuery{history},
+				PageQuery: MustPageQuery("", "asc", 0),
+				Filter: &EffectAssetPairFilter{
+					BaseType:      xdr.AssetTypeAssetTypeCreditAlphanum4,
+					BaseCode:      "USD",
+					BaseIssuer:    "GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4",
+					CounterType:   xdr.AssetTypeAssetTypeCreditAlphanum4,
+					CounterCode:   "EUR",
+					CounterIssuer: "GCQPYGH4K57XBDENKKX55KDTWOTK5WDWRQOH2LHEDX3EKVIQRLMESGBG",
+				},
+			}
+
+			MustSelect(ctx, q, &records)
+			So(len(records), ShouldEqual, 2)
+
+			q.Filter = &EffectAssetPairFilter{
+				BaseType:      xdr.AssetTypeAssetTypeNative,
+				CounterType:   xdr.AssetTypeAssetTypeCreditAlphanum4,
+				CounterCode:   "EUR",
+				CounterIssuer: "GCQPYGH4K57XBDENKKX55KDTWOTK5WDWRQOH2LHEDX3EKVIQRLMESGBG",
+			}
+			MustSelect(ctx, q, &records)
+			So(len(records), ShouldEqual, 0)
+		})
+
 		Convey("regression: does not crash when using a native asset", func() {
 			q := EffectPageQuery{
 				SqlQuery:  SqlQuery{history},
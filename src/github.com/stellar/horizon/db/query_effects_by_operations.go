@@ -0,0 +1,23 @@
+package db
+
+import (
+	sq "github.com/lann/squirrel"
+	"golang.org/x/net/context"
+)
+
+// EffectsByOperationsQuery loads the effects of the given type produced by any
+// of the given operations, in order.
+type EffectsByOperationsQuery struct {
+	SqlQuery
+	OperationIDs []int64
+	Type         int32
+}
+
+func (q EffectsByOperationsQuery) Select(ctx context.Context, dest interface{}) error {
+	sql := EffectRecordSelect.
+		Where(sq.Eq{"heff.history_operation_id": q.OperationIDs}).
+		Where("heff.type = ?", q.Type).
+		OrderBy("heff.history_operation_id asc, heff.order asc")
+
+	return q.SqlQuery.Select(ctx, sql, dest)
+}
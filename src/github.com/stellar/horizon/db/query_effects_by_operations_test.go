@@ -0,0 +1,31 @@
+package db
+
+import (
+	"testing"
+
+	_ "github.com/lib/pq"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/test"
+)
+
+func TestEffectsByOperationsQuery(t *testing.T) {
+	test.LoadScenario("account_merge")
+
+	Convey("EffectsByOperationsQuery", t, func() {
+		var records []EffectRecord
+
+		q := EffectsByOperationsQuery{
+			SqlQuery:     SqlQuery{history},
+			OperationIDs: []int64{8589938689, 12884905985},
+			Type:         EffectAccountCredited,
+		}
+
+		MustSelect(ctx, q, &records)
+		So(len(records), ShouldEqual, 1)
+		So(records[0].HistoryOperationID, ShouldEqual, 12884905985)
+
+		q.OperationIDs = []int64{8589938689}
+		MustSelect(ctx, q, &records)
+		So(len(records), ShouldEqual, 0)
+	})
+}
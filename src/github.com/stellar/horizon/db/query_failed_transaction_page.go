@@ -0,0 +1,258 @@
+package db
+
+import (
+	"time"
+
+	sq "github.com/lann/squirrel"
+	"github.com/stellar/go-stellar-base/xdr"
+	"golang.org/x/net/context"
+)
+
+// FailedTransactionPageQuery loads a page of the failed transactions
+// stellar-core has kept, in the form of the TransactionRecords history holds
+// for successful ones.  When set, AccountAddress restricts the page to the
+// transactions involving that account, LedgerSequence to those of that ledger,
+// and Ledgers to those of the ledgers within it.  Bound, when not zero, is the
+// id at which the page stops: stellar-core's transactions are scanned from the
+// cursor up to it only.
+type FailedTransactionPageQuery struct {
+	SqlQuery
+	PageQuery
+	AccountAddress string
+	LedgerSequence int32
+	Ledgers        LedgerRange
+	Bound          int64
+}
+
+func (q FailedTransactionPageQuery) Select(ctx context.Context, dest interface{}) error {
+	cursor, err := q.CursorInt64()
+	if err != nil {
+		return err
+	}
+
+	var filter sq.Sqlizer
+	if q.LedgerSequence != 0 {
+		filter = sq.Eq{"ctxh.ledgerseq": q.LedgerSequence}
+	}
+
+	result := []TransactionRecord{}
+	err = scanFailedTransactions(ctx, q.SqlQuery, q.Order, cursor, q.Bound, q.Ledgers.coreFilter(filter), func(tx TransactionRecord, env xdr.TransactionEnvelope) (bool, error) {
+		if !pastCursor(q.Order, tx.Id, cursor) {
+			return true, nil
+		}
+
+		if q.AccountAddress != "" {
+			involved, err := transactionInvolves(tx, env, q.AccountAddress)
+			if err != nil || !involved {
+				return err == nil, err
+			}
+		}
+
+		result = append(result, tx)
+		return len(result) < int(q.Limit), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return setOn(result, dest)
+}
+
+// FailedOperationPageQuery loads a page of the operations of the failed
+// transactions stellar-core has kept, in the form of the OperationRecords
+// history holds for successful ones.  Its filters are those of an
+// OperationPageQuery, and Bound that of a FailedTransactionPageQuery.
+type FailedOperationPageQuery struct {
+	SqlQuery
+	PageQuery
+	AccountAddress  string
+	LedgerSequence  int32
+	TransactionHash string
+	TypeFilter      string
+	Types           []xdr.OperationType
+	Ledgers         LedgerRange
+	Bound           int64
+}
+
+func (q FailedOperationPageQuery) Select(ctx context.Context, dest interface{}) error {
+	cursor, err := q.CursorInt64()
+	if err != nil {
+		return err
+	}
+
+	var filter sq.Sqlizer
+	switch {
+	case q.LedgerSequence != 0:
+		filter = sq.Eq{"ctxh.ledgerseq": q.LedgerSequence}
+	case q.TransactionHash != "":
+		filter = sq.Eq{"ctxh.txid": q.TransactionHash}
+	}
+
+	var types map[xdr.OperationType]bool
+	if filtered, ok := operationFilterMap[q.TypeFilter]; ok {
+		types = map[xdr.OperationType]bool{}
+		for _, t := range filtered {
+			types[t] = true
+		}
+	}
+
+	result := []OperationRecord{}
+	err = scanFailedTransactions(ctx, q.SqlQuery, q.Order, cursor, q.Bound, q.Ledgers.coreFilter(filter), func(tx TransactionRecord, env xdr.TransactionEnvelope) (bool, error) {
+		ops, err := NewOperationRecords(tx, env, nil)
+		if err != nil {
+			return false, err
+		}
+
+		if q.Order == OrderDescending {
+			for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+				ops[i], ops[j] = ops[j], ops[i]
+				env.Tx.Operations[i], env.Tx.Operations[j] = env.Tx.Operations[j], env.Tx.Operations[i]
+			}
+		}
+
+		for i, op := range ops {
+			if !pastCursor(q.Order, op.Id, cursor) {
+				continue
+			}
+
+			if types != nil && !types[op.Type] {
+				continue
+			}
+
+			if len(q.Types) > 0 && !containsType(q.Types, op.Type) {
+				continue
+			}
+
+			if q.AccountAddress != "" {
+				participants, err := OperationParticipants(env.Tx.Operations[i], op.SourceAccount)
+				if err != nil {
+					return false, err
+				}
+
+				if !containsAddress(participants, q.AccountAddress) {
+					continue
+				}
+			}
+
+			result = append(result, op)
+		}
+
+		return len(result) < int(q.Limit), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(result) > int(q.Limit) {
+		result = result[:q.Limit]
+	}
+
+	return setOn(result, dest)
+}
+
+// scanFailedTransactions calls fn with the failed transactions amongst those
+// scanned by scanCoreTransactions, along with their envelopes.
+func scanFailedTransactions(
+	ctx context.Context,
+	q SqlQuery,
+	order string,
+	cursor int64,
+	bound int64,
+	filter sq.Sqlizer,
+	fn func(TransactionRecord, xdr.TransactionEnvelope) (bool, error),
+) error {
+	return scanCoreTransactions(ctx, q, order, cursor, bound, filter, func(tx CoreTransactionRecord, closedAt time.Time) (bool, error) {
+		record, env, failed, err := failedTransaction(tx, closedAt)
+		if err != nil || !failed {
+			return err == nil, err
+		}
+
+		return fn(record, env)
+	})
+}
+
+// failedBound returns the Bound of the failed transactions, or of their
+// operations, that may join on a page the successful ones loaded from history,
+// last being the id of the last of these.  When the successful ones fill the
+// page, no failed one past last could be on it; otherwise the page stops at
+// the edge of the ledgers history holds.  ok is false when history holds no
+// ledgers, and so the page no failed transactions.
+func failedBound(ctx context.Context, history SqlQuery, page PageQuery, loaded int, last int64) (bound int64, ok bool, err error) {
+	if loaded >= int(page.Limit) {
+		return last, true, nil
+	}
+
+	elder, latest, err := historyLedgers(ctx, history)
+	if err != nil || latest == 0 {
+		return
+	}
+
+	ok = true
+	if page.Order == OrderDescending {
+		bound = TotalOrderId{LedgerSequence: elder}.ToInt64()
+		return
+	}
+
+	bound = TotalOrderId{LedgerSequence: latest + 1}.ToInt64()
+	return
+}
+
+// pastCursor returns true if id comes after cursor in the given order
+func pastCursor(order string, id int64, cursor int64) bool {
+	if order == OrderDescending {
+		return id < cursor
+	}
+
+	return id > cursor
+}
+
+// transactionInvolves returns true if address is the source of tx, whose
+// envelope is env, or participates in one of its operations.
+func transactionInvolves(tx TransactionRecord, env xdr.TransactionEnvelope, address string) (bool, error) {
+	if tx.Account == address {
+		return true, nil
+	}
+
+	for _, op := range env.Tx.Operations {
+		source := tx.Account
+		if op.SourceAccount != nil {
+			var err error
+			source, err = accountAddress(*op.SourceAccount)
+			if err != nil {
+				return false, err
+			}
+		}
+
+		participants, err := OperationParticipants(op, source)
+		if err != nil {
+			return false, err
+		}
+
+		if containsAddress(participants, address) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func containsAddress(addresses []string, address string) bool {
+	for _, a := range addresses {
+		if a == address {
+			return true
+		}
+	}
+
+	return false
+}
+
+// containsType returns true if types includes t
+func containsType(types []xdr.OperationType, t xdr.OperationType) bool {
+	for _, c := range types {
+		if c == t {
+			return true
+		}
+	}
+
+	return false
+}
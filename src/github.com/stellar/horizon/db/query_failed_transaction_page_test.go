@@ -0,0 +1,42 @@
+package db
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/test"
+)
+
+func TestFailedBound(t *testing.T) {
+	Convey("failedBound", t, func() {
+		test.LoadScenario("base")
+		q := SqlQuery{history}
+
+		Convey("stops at the last successful record of a full page", func() {
+			bound, ok, err := failedBound(ctx, q, PageQuery{Order: "asc", Limit: 2}, 2, 12345)
+			So(err, ShouldBeNil)
+			So(ok, ShouldBeTrue)
+			So(bound, ShouldEqual, 12345)
+		})
+
+		Convey("stops at the edge of history otherwise", func() {
+			bound, ok, err := failedBound(ctx, q, PageQuery{Order: "asc", Limit: 10}, 4, 12345)
+			So(err, ShouldBeNil)
+			So(ok, ShouldBeTrue)
+			So(bound, ShouldEqual, TotalOrderId{LedgerSequence: 4}.ToInt64())
+
+			bound, ok, err = failedBound(ctx, q, PageQuery{Order: "desc", Limit: 10}, 4, 12345)
+			So(err, ShouldBeNil)
+			So(ok, ShouldBeTrue)
+			So(bound, ShouldEqual, TotalOrderId{LedgerSequence: 1}.ToInt64())
+		})
+
+		Convey("finds nothing without history", func() {
+			history.MustExec("DELETE FROM history_ledgers")
+
+			_, ok, err := failedBound(ctx, q, PageQuery{Order: "asc", Limit: 10}, 0, 0)
+			So(err, ShouldBeNil)
+			So(ok, ShouldBeFalse)
+		})
+	})
+}
@@ -0,0 +1,149 @@
+package db
+
+import (
+	"math"
+	"sort"
+
+	"github.com/go-errors/errors"
+	sq "github.com/lann/squirrel"
+	"github.com/stellar/go-stellar-base/xdr"
+	"golang.org/x/net/context"
+)
+
+// FeePercentiles are the percentiles of FeeStats.Percentiles, in order.
+var FeePercentiles = []int{10, 20, 30, 40, 50, 60, 70, 80, 90, 95, 99}
+
+// FeeStats summarizes the fees paid by the transactions of the latest ledgers,
+// and how much of those ledgers' capacity was used.  Fees are per operation,
+// in stroops.
+type FeeStats struct {
+	LastLedger          int32
+	LastLedgerBaseFee   int32
+	Ledgers             int32
+	LedgerCapacityUsage float64
+	Min                 int64
+	Mode                int64
+	Percentiles         []int64
+}
+
+// FeeStatsQuery computes the FeeStats of the latest Ledgers ledgers known to
+// horizon.  When those ledgers hold no transactions, every fee reported is the
+// base fee of the last ledger.
+type FeeStatsQuery struct {
+	Horizon SqlQuery
+	Core    SqlQuery
+	Ledgers int32
+}
+
+// Select executes the query, populating dest with a single FeeStats
+func (q FeeStatsQuery) Select(ctx context.Context, dest interface{}) error {
+	var result FeeStats
+
+	var window struct {
+		LastLedger       int32 `db:"last_ledger"`
+		Ledgers          int32 `db:"ledgers"`
+		TransactionCount int64 `db:"transaction_count"`
+	}
+
+	var last int32
+	err := q.Horizon.Get(ctx, sq.Select("COALESCE(MAX(sequence), 0)").From("history_ledgers"), &last)
+	if err != nil {
+		return err
+	}
+
+	err = q.Horizon.Get(ctx, sq.
+		Select(
+			"COALESCE(MAX(sequence), 0) as last_ledger",
+			"COUNT(*) as ledgers",
+			"COALESCE(SUM(transaction_count), 0) as transaction_count",
+		).
+		From("history_ledgers").
+		Where("sequence > ?", last-q.Ledgers), &window)
+	if err != nil {
+		return err
+	}
+
+	var data string
+	err = q.Core.Get(ctx, sq.
+		Select("data").
+		From("ledgerheaders").
+		OrderBy("ledgerseq desc").
+		Limit(1), &data)
+	if err != nil {
+		return err
+	}
+
+	var header xdr.LedgerHeader
+	err = xdr.SafeUnmarshalBase64(data, &header)
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	var fees []int64
+	start := TotalOrderId{LedgerSequence: last - q.Ledgers + 1}
+	end := TotalOrderId{LedgerSequence: last + 1}
+	err = q.Horizon.Select(ctx, sq.
+		Select("fee_paid / operation_count").
+		From("history_transactions").
+		Where("id >= ? AND id < ?", start.ToInt64(), end.ToInt64()).
+		Where("operation_count > 0"), &fees)
+	if err != nil {
+		return err
+	}
+
+	result.LastLedger = window.LastLedger
+	result.LastLedgerBaseFee = int32(header.BaseFee)
+	result.Ledgers = window.Ledgers
+	if capacity := int64(window.Ledgers) * int64(header.MaxTxSetSize); capacity > 0 {
+		result.LedgerCapacityUsage = float64(window.TransactionCount) / float64(capacity)
+	}
+
+	result.Min, result.Mode, result.Percentiles = summarizeFees(fees, int64(header.BaseFee))
+
+	setOn([]FeeStats{result}, dest)
+	return nil
+}
+
+// summarizeFees returns the minimum, the most common and the FeePercentiles of
+// fees.  Percentiles are by nearest rank, and ties for the most common fee go
+// to the lowest.  When fees is empty, every value returned is base.
+func summarizeFees(fees []int64, base int64) (min int64, mode int64, percentiles []int64) {
+	percentiles = make([]int64, len(FeePercentiles))
+
+	if len(fees) == 0 {
+		for i := range percentiles {
+			percentiles[i] = base
+		}
+		return base, base, percentiles
+	}
+
+	sorted := append([]int64{}, fees...)
+	sort.Sort(int64s(sorted))
+
+	for i, p := range FeePercentiles {
+		rank := int(math.Ceil(float64(p) / 100 * float64(len(sorted))))
+		percentiles[i] = sorted[rank-1]
+	}
+
+	best := 0
+	for i, run := 0, 0; i < len(sorted); i++ {
+		if i > 0 && sorted[i] == sorted[i-1] {
+			run++
+		} else {
+			run = 1
+		}
+
+		if run > best {
+			best = run
+			mode = sorted[i]
+		}
+	}
+
+	return sorted[0], mode, percentiles
+}
+
+type int64s []int64
+
+func (s int64s) Len() int           { return len(s) }
+func (s int64s) Less(i, j int) bool { return s[i] < s[j] }
+func (s int64s) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
@@ -0,0 +1,49 @@
+package db
+
+import (
+	"testing"
+
+	_ "github.com/lib/pq"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/test"
+)
+
+func TestFeeStatsQuery(t *testing.T) {
+	test.LoadScenario("base")
+
+	Convey("FeeStatsQuery", t, func() {
+		var stats FeeStats
+
+		q := FeeStatsQuery{
+			Horizon: SqlQuery{history},
+			Core:    SqlQuery{core},
+			Ledgers: 5,
+		}
+
+		MustGet(ctx, q, &stats)
+		So(stats.LastLedger, ShouldEqual, 3)
+		So(stats.Ledgers, ShouldEqual, 3)
+		So(stats.LastLedgerBaseFee, ShouldBeGreaterThan, 0)
+		So(stats.Min, ShouldEqual, 0)
+		So(len(stats.Percentiles), ShouldEqual, len(FeePercentiles))
+	})
+
+	Convey("summarizeFees", t, func() {
+		min, mode, percentiles := summarizeFees(nil, 100)
+		So(min, ShouldEqual, 100)
+		So(mode, ShouldEqual, 100)
+		So(percentiles[0], ShouldEqual, 100)
+
+		fees := []int64{}
+		for i := int64(1); i <= 100; i++ {
+			fees = append(fees, i)
+		}
+		fees = append(fees, 50)
+
+		min, mode, percentiles = summarizeFees(fees, 100)
+		So(min, ShouldEqual, 1)
+		So(mode, ShouldEqual, 50)
+		So(percentiles[0], ShouldEqual, 11)
+		So(percentiles[len(percentiles)-1], ShouldEqual, 99)
+	})
+}
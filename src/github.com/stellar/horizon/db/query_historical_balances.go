@@ -0,0 +1,389 @@
+package db
+
+import (
+	"sort"
+	"time"
+
+	"github.com/go-errors/errors"
+	sq "github.com/lann/squirrel"
+	"github.com/stellar/go-stellar-base/xdr"
+	"golang.org/x/net/context"
+)
+
+// historicalBalancesBatch is the count of transactions whose meta a
+// HistoricalBalancesQuery loads at once, walking back through history
+const historicalBalancesBatch = 100
+
+// HistoricalBalancesRecord holds the balances of an account as of the close of
+// a ledger: its native balance and its trustlines, ordered by asset code and
+// then issuer.  The Lastmodified of each trustline is the ledger that last
+// changed it.
+type HistoricalBalancesRecord struct {
+	Address    string
+	Ledger     int32
+	ClosedAt   time.Time
+	Balance    int64
+	Trustlines []TrustlineRecord
+}
+
+// HistoricalBalancesQuery reconstructs the balances of the account at Address
+// as of the close of a past ledger: Ledger when set, otherwise the latest
+// ledger closed at or before At, otherwise the latest ledger in history.
+//
+// The ledger entries of the account are the states the meta of its
+// transactions last left them in, walking back from the ledger through the
+// transactions the account took part in or had effects from.  Effects alone
+// would not do, as they leave out the fees an account pays.  Balances cannot
+// be told for accounts that did not exist as of the ledger, nor from
+// transactions ingested without their meta, which are skipped.  The fees of
+// failed transactions, which history leaves out, are not accounted for.
+type HistoricalBalancesQuery struct {
+	SqlQuery
+	Address string
+	Ledger  int32
+	At      time.Time
+}
+
+// Select executes the query, populating dest with a single
+// HistoricalBalancesRecord, or none when the ledger is not in history or the
+// account did not exist as of it.
+func (q HistoricalBalancesQuery) Select(ctx context.Context, dest interface{}) error {
+	sql := sq.Select("sequence", "closed_at").
+		From("history_ledgers").
+		OrderBy("sequence desc").
+		Limit(1)
+
+	switch {
+	case q.Ledger > 0:
+		sql = sql.Where("sequence = ?", q.Ledger)
+	case !q.At.IsZero():
+		sql = sql.Where("closed_at <= ?", q.At)
+	}
+
+	var ledgers []struct {
+		Sequence int32     `db:"sequence"`
+		ClosedAt time.Time `db:"closed_at"`
+	}
+	err := q.SqlQuery.Select(ctx, sql, &ledgers)
+	if err != nil {
+		return err
+	}
+
+	if len(ledgers) == 0 {
+		return setOn([]HistoricalBalancesRecord{}, dest)
+	}
+	ledger := ledgers[0]
+
+	end := TotalOrderId{LedgerSequence: ledger.Sequence + 1}.ToInt64()
+
+	var account HistoryAccountRecord
+	err = Get(ctx, HistoryAccountByAddressQuery{q.SqlQuery, q.Address}, &account)
+	if err == ErrNoResults {
+		return setOn([]HistoricalBalancesRecord{}, dest)
+	}
+	if err != nil {
+		return err
+	}
+
+	var assets []struct {
+		Code   string `db:"code"`
+		Issuer string `db:"issuer"`
+	}
+	err = q.SqlQuery.Select(ctx, sq.
+		Select("DISTINCT he.details->>'asset_code' AS code", "he.details->>'asset_issuer' AS issuer").
+		From("history_effects he").
+		Where("he.history_account_id = ?", account.Id).
+		Where(sq.Eq{"he.type": []int32{EffectTrustlineCreated, EffectTrustlineUpdated, EffectTrustlineRemoved}}).
+		Where("he.history_operation_id < ?", end), &assets)
+	if err != nil {
+		return err
+	}
+
+	r := &balanceReplay{
+		address:    q.Address,
+		trustlines: map[string]*TrustlineRecord{},
+		wanted:     map[string]bool{},
+	}
+	for _, a := range assets {
+		r.wanted[a.Code+":"+a.Issuer] = true
+	}
+
+	// the ids of the transactions the account took part in or had effects
+	// from are found once, latest first, and their meta loaded in batches
+	var ids []int64
+	err = q.SqlQuery.SelectRaw(ctx, `
+		SELECT ht.id FROM history_transactions ht
+		JOIN history_transaction_participants htp USING (transaction_hash)
+		WHERE htp.account = $1 AND ht.id < $3
+		UNION
+		SELECT ho.transaction_id FROM history_operations ho
+		JOIN history_effects he ON he.history_operation_id = ho.id
+		WHERE he.history_account_id = $2 AND ho.id < $3
+		ORDER BY id DESC`, []interface{}{q.Address, account.Id, end}, &ids)
+	if err != nil {
+		return err
+	}
+
+	for start := 0; start < len(ids) && !r.complete(); start += historicalBalancesBatch {
+		batch := ids[start:]
+		if len(batch) > historicalBalancesBatch {
+			batch = batch[:historicalBalancesBatch]
+		}
+
+		var txs []struct {
+			ID        int64  `db:"id"`
+			Ledger    int32  `db:"ledger_sequence"`
+			TxMeta    string `db:"tx_meta"`
+			TxFeeMeta string `db:"tx_fee_meta"`
+		}
+		err = q.SqlQuery.Select(ctx, sq.
+			Select("ht.id", "ht.ledger_sequence", "ht.tx_meta", "COALESCE(ht.tx_fee_meta, '') AS tx_fee_meta").
+			From("history_transactions ht").
+			Where(sq.Eq{"ht.id": batch}).
+			OrderBy("ht.id desc"), &txs)
+		if err != nil {
+			return err
+		}
+
+		for _, tx := range txs {
+			err = r.transaction(tx.Ledger, tx.TxMeta, tx.TxFeeMeta)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	err = r.flush()
+	if err != nil {
+		return err
+	}
+
+	if !r.accountFound || r.accountRemoved {
+		return setOn([]HistoricalBalancesRecord{}, dest)
+	}
+
+	result := HistoricalBalancesRecord{
+		Address:  q.Address,
+		Ledger:   ledger.Sequence,
+		ClosedAt: ledger.ClosedAt,
+		Balance:  r.balance,
+	}
+
+	for _, tl := range r.trustlines {
+		if tl != nil {
+			result.Trustlines = append(result.Trustlines, *tl)
+		}
+	}
+	sort.Sort(trustlinesByAsset(result.Trustlines))
+
+	return setOn([]HistoricalBalancesRecord{result}, dest)
+}
+
+// balanceReplay finds the latest states of the ledger entries of an account,
+// being given the transactions that changed them latest first.  Within a
+// ledger, fees are charged before any transaction applies, so the fee changes
+// of the transactions of a ledger are looked at once all of their operations
+// have been.
+type balanceReplay struct {
+	address string
+
+	// the latest state found of the account itself: its balance, or its
+	// removal by a merge
+	accountFound   bool
+	accountRemoved bool
+	balance        int64
+
+	// trustlines holds, by asset, the latest state found of each trustline,
+	// nil for those found removed
+	trustlines map[string]*TrustlineRecord
+
+	// wanted holds the assets of the trustlines the account is known to have
+	// had, by the effects of changing them
+	wanted map[string]bool
+
+	ledger int32
+	fees   []string
+}
+
+// complete returns true once the state of the account and of every trustline
+// known to it has been found, or the account was found removed
+func (r *balanceReplay) complete() bool {
+	if r.accountRemoved {
+		return true
+	}
+
+	if !r.accountFound {
+		return false
+	}
+
+	for key := range r.wanted {
+		if _, found := r.trustlines[key]; !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// transaction looks at the changes of a transaction of ledger, whose meta and
+// fee meta are given, in the base64 form history keeps them.
+func (r *balanceReplay) transaction(ledger int32, meta, feeMeta string) error {
+	if ledger != r.ledger {
+		err := r.flush()
+		if err != nil {
+			return err
+		}
+		r.ledger = ledger
+	}
+
+	if feeMeta != "" {
+		r.fees = append(r.fees, feeMeta)
+	}
+
+	if meta == "" {
+		return nil
+	}
+
+	var txMeta xdr.TransactionMeta
+	err := xdr.SafeUnmarshalBase64(meta, &txMeta)
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	ops := txMeta.MustOperations()
+	for i := len(ops) - 1; i >= 0; i-- {
+		err = r.changes(ops[i].Changes)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// flush looks at the fee changes of the ledger whose transactions were last
+// given
+func (r *balanceReplay) flush() error {
+	for _, data := range r.fees {
+		var changes xdr.LedgerEntryChanges
+		err := xdr.SafeUnmarshalBase64(data, &changes)
+		if err != nil {
+			return errors.Wrap(err, 1)
+		}
+
+		err = r.changes(changes)
+		if err != nil {
+			return err
+		}
+	}
+
+	r.fees = nil
+	return nil
+}
+
+// changes records, latest first, the states of the account's entries changes
+// leaves them in, unless later ones were found
+func (r *balanceReplay) changes(changes xdr.LedgerEntryChanges) error {
+	for i := len(changes) - 1; i >= 0; i-- {
+		change := changes[i]
+
+		var entry *xdr.LedgerEntry
+		switch change.Type {
+		case xdr.LedgerEntryChangeTypeLedgerEntryCreated:
+			entry = change.Created
+		case xdr.LedgerEntryChangeTypeLedgerEntryUpdated:
+			entry = change.Updated
+		case xdr.LedgerEntryChangeTypeLedgerEntryRemoved:
+			err := r.removed(*change.Removed)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		switch entry.Data.Type {
+		case xdr.LedgerEntryTypeAccount:
+			if r.accountFound || r.accountRemoved {
+				continue
+			}
+
+			address, err := accountAddress(entry.Data.Account.AccountId)
+			if err != nil {
+				return err
+			}
+
+			if address == r.address {
+				r.accountFound = true
+				r.balance = int64(entry.Data.Account.Balance)
+			}
+		case xdr.LedgerEntryTypeTrustline:
+			tl, err := NewTrustlineRecord(*entry.Data.TrustLine, r.ledger)
+			if err != nil {
+				return err
+			}
+
+			r.trustline(tl, false)
+		}
+	}
+
+	return nil
+}
+
+// removed records the removal of the ledger entry of key, when one of the
+// account's
+func (r *balanceReplay) removed(key xdr.LedgerKey) error {
+	switch key.Type {
+	case xdr.LedgerEntryTypeAccount:
+		address, err := accountAddress(key.Account.AccountId)
+		if err != nil {
+			return err
+		}
+
+		if address == r.address && !r.accountFound {
+			r.accountRemoved = true
+		}
+	case xdr.LedgerEntryTypeTrustline:
+		tl, err := NewTrustlineRecord(xdr.TrustLineEntry{
+			AccountId: key.TrustLine.AccountId,
+			Asset:     key.TrustLine.Asset,
+		}, r.ledger)
+		if err != nil {
+			return err
+		}
+
+		r.trustline(tl, true)
+	}
+
+	return nil
+}
+
+// trustline records tl, removed or not, when it is the account's and no later
+// state of it was found
+func (r *balanceReplay) trustline(tl TrustlineRecord, removed bool) {
+	if tl.Accountid != r.address {
+		return
+	}
+
+	key := tl.Assetcode + ":" + tl.Issuer
+	if _, found := r.trustlines[key]; found {
+		return
+	}
+
+	if removed {
+		r.trustlines[key] = nil
+	} else {
+		r.trustlines[key] = &tl
+	}
+}
+
+// trustlinesByAsset sorts trustlines by asset code and then issuer
+type trustlinesByAsset []TrustlineRecord
+
+func (s trustlinesByAsset) Len() int      { return len(s) }
+func (s trustlinesByAsset) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s trustlinesByAsset) Less(i, j int) bool {
+	if s[i].Assetcode != s[j].Assetcode {
+		return s[i].Assetcode < s[j].Assetcode
+	}
+	return s[i].Issuer < s[j].Issuer
+}
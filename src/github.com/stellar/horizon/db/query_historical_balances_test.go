@@ -0,0 +1,136 @@
+package db
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/go-stellar-base/strkey"
+	"github.com/stellar/go-stellar-base/xdr"
+	"github.com/stellar/horizon/test"
+)
+
+func TestHistoricalBalancesQuery(t *testing.T) {
+	const payee = "GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON"
+	test.LoadScenario("base")
+
+	Convey("HistoricalBalancesQuery", t, func() {
+		var record HistoricalBalancesRecord
+
+		Convey("reconstructs the balances of the latest ledger by default", func() {
+			MustGet(ctx, HistoricalBalancesQuery{SqlQuery: SqlQuery{history}, Address: payee}, &record)
+			So(record.Ledger, ShouldEqual, 3)
+			So(record.Balance, ShouldEqual, 1050000000)
+			So(record.Trustlines, ShouldBeEmpty)
+		})
+
+		Convey("reconstructs the balances of past ledgers", func() {
+			MustGet(ctx, HistoricalBalancesQuery{SqlQuery: SqlQuery{history}, Address: payee, Ledger: 2}, &record)
+			So(record.Ledger, ShouldEqual, 2)
+			So(record.Balance, ShouldEqual, 1000000000)
+		})
+
+		Convey("finds nothing of accounts yet to be created", func() {
+			err := Get(ctx, HistoricalBalancesQuery{SqlQuery: SqlQuery{history}, Address: payee, Ledger: 1}, &record)
+			So(err, ShouldEqual, ErrNoResults)
+
+			err = Get(ctx, HistoricalBalancesQuery{SqlQuery: SqlQuery{history}, Address: payee, Ledger: 99}, &record)
+			So(err, ShouldEqual, ErrNoResults)
+		})
+	})
+}
+
+func TestBalanceReplay(t *testing.T) {
+	const (
+		address = "GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON"
+		issuer  = "GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4"
+	)
+
+	aid := func(address string) xdr.AccountId {
+		var key xdr.Uint256
+		copy(key[:], strkey.MustDecode(strkey.VersionByteAccountID, address))
+		id, err := xdr.NewAccountId(xdr.CryptoKeyTypeKeyTypeEd25519, key)
+		So(err, ShouldBeNil)
+		return id
+	}
+
+	usd := func() xdr.Asset {
+		var code [4]byte
+		copy(code[:], "USD")
+		a, err := xdr.NewAsset(xdr.AssetTypeAssetTypeCreditAlphanum4, xdr.AssetAlphaNum4{AssetCode: code, Issuer: aid(issuer)})
+		So(err, ShouldBeNil)
+		return a
+	}
+
+	account := func(balance xdr.Int64) xdr.LedgerEntryChange {
+		return xdr.LedgerEntryChange{
+			Type: xdr.LedgerEntryChangeTypeLedgerEntryUpdated,
+			Updated: &xdr.LedgerEntry{Data: xdr.LedgerEntryData{
+				Type:    xdr.LedgerEntryTypeAccount,
+				Account: &xdr.AccountEntry{AccountId: aid(address), Balance: balance},
+			}},
+		}
+	}
+
+	trustline := func(balance xdr.Int64) xdr.LedgerEntryChange {
+		return xdr.LedgerEntryChange{
+			Type: xdr.LedgerEntryChangeTypeLedgerEntryUpdated,
+			Updated: &xdr.LedgerEntry{Data: xdr.LedgerEntryData{
+				Type:      xdr.LedgerEntryTypeTrustline,
+				TrustLine: &xdr.TrustLineEntry{AccountId: aid(address), Asset: usd(), Balance: balance, Limit: 1000},
+			}},
+		}
+	}
+
+	meta := func(changes ...xdr.LedgerEntryChange) string {
+		ops := []xdr.OperationMeta{{Changes: changes}}
+		data, err := xdr.MarshalBase64(xdr.TransactionMeta{Operations: &ops})
+		So(err, ShouldBeNil)
+		return data
+	}
+
+	fees := func(changes ...xdr.LedgerEntryChange) string {
+		data, err := xdr.MarshalBase64(xdr.LedgerEntryChanges(changes))
+		So(err, ShouldBeNil)
+		return data
+	}
+
+	Convey("balanceReplay", t, func() {
+		r := &balanceReplay{
+			address:    address,
+			trustlines: map[string]*TrustlineRecord{},
+			wanted:     map[string]bool{"USD:" + issuer: true},
+		}
+
+		Convey("takes the operations of a ledger to follow its fees", func() {
+			So(r.transaction(5, meta(account(30)), fees(account(20))), ShouldBeNil)
+			So(r.transaction(5, "", fees(account(10))), ShouldBeNil)
+			So(r.flush(), ShouldBeNil)
+			So(r.balance, ShouldEqual, 30)
+		})
+
+		Convey("takes the fees of a ledger to follow earlier ledgers", func() {
+			So(r.transaction(5, meta(trustline(7)), fees(account(20))), ShouldBeNil)
+			So(r.complete(), ShouldBeFalse)
+			So(r.transaction(4, meta(account(30), trustline(5)), ""), ShouldBeNil)
+			So(r.complete(), ShouldBeTrue)
+			So(r.balance, ShouldEqual, 20)
+			So(r.trustlines["USD:"+issuer].Balance, ShouldEqual, 7)
+			So(r.trustlines["USD:"+issuer].Lastmodified, ShouldEqual, 5)
+		})
+
+		Convey("leaves out removed trustlines", func() {
+			removed := xdr.LedgerEntryChange{
+				Type: xdr.LedgerEntryChangeTypeLedgerEntryRemoved,
+				Removed: &xdr.LedgerKey{
+					Type:      xdr.LedgerEntryTypeTrustline,
+					TrustLine: &xdr.LedgerKeyTrustLine{AccountId: aid(address), Asset: usd()},
+				},
+			}
+
+			So(r.transaction(5, meta(trustline(0), removed), ""), ShouldBeNil)
+			So(r.transaction(4, meta(account(30), trustline(5)), ""), ShouldBeNil)
+			So(r.complete(), ShouldBeTrue)
+			So(r.trustlines["USD:"+issuer], ShouldBeNil)
+		})
+	})
+}
@@ -5,6 +5,7 @@ import "golang.org/x/net/context"
 type LedgerPageQuery struct {
 	SqlQuery
 	PageQuery
+	TimeRange TimeRange
 }
 
 func (q LedgerPageQuery) Select(ctx context.Context, dest interface{}) error {
@@ -23,5 +24,14 @@ func (q LedgerPageQuery) Select(ctx context.Context, dest interface{}) error {
 		sql = sql.Where("hl.id < ?", cursor).OrderBy("hl.id desc")
 	}
 
+	if !q.TimeRange.IsZero() {
+		ledgers, err := q.TimeRange.Ledgers(ctx, q.SqlQuery)
+		if err != nil {
+			return err
+		}
+
+		sql = sql.Where("hl.sequence >= ? AND hl.sequence < ?", ledgers.Start, ledgers.End)
+	}
+
 	return q.SqlQuery.Select(ctx, sql, dest)
 }
@@ -2,6 +2,7 @@ package db
 
 import (
 	"testing"
+	"time"
 
 	_ "github.com/lib/pq"
 	. "github.com/smartystreets/goconvey/convey"
@@ -17,7 +18,7 @@ func TestLedgerPageQuery(t *testing.T) {
 		pq, err := NewPageQuery("", "asc", 2)
 		So(err, ShouldBeNil)
 
-		q := LedgerPageQuery{SqlQuery{history}, pq}
+		q := LedgerPageQuery{SqlQuery: SqlQuery{history}, PageQuery: pq}
 		err = Select(ctx, q, &records)
 
 		So(err, ShouldBeNil)
@@ -34,5 +35,25 @@ func TestLedgerPageQuery(t *testing.T) {
 		So(err, ShouldBeNil)
 		t.Log(records)
 		So(len(records), ShouldEqual, 1)
+
+		Convey("obeys the time range", func() {
+			pq, err := NewPageQuery("", "asc", 10)
+			So(err, ShouldBeNil)
+
+			q := LedgerPageQuery{SqlQuery: SqlQuery{history}, PageQuery: pq}
+			q.TimeRange.Start = time.Date(2015, 10, 7, 23, 7, 27, 0, time.UTC)
+			MustSelect(ctx, q, &records)
+			So(len(records), ShouldEqual, 2)
+			So(records[0].Sequence, ShouldEqual, 2)
+
+			q.TimeRange.End = time.Date(2015, 10, 7, 23, 7, 28, 0, time.UTC)
+			MustSelect(ctx, q, &records)
+			So(len(records), ShouldEqual, 1)
+			So(records[0].Sequence, ShouldEqual, 2)
+
+			q.TimeRange = TimeRange{Start: time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)}
+			MustSelect(ctx, q, &records)
+			So(len(records), ShouldEqual, 0)
+		})
 	})
 }
@@ -6,10 +6,11 @@ import (
 )
 
 // LedgerState represents the latest known ledgers for both
-// horizon and stellar-core.
+// horizon and stellar-core, and the oldest ledger of horizon's history.
 type LedgerState struct {
-	HorizonSequence     int32
-	StellarCoreSequence int32
+	HorizonSequence      int32
+	HorizonElderSequence int32
+	StellarCoreSequence  int32
 }
 
 // LedgerStateQuery retrieves the latest ledgers for stellar-core and horizon.
@@ -21,7 +22,7 @@ type LedgerStateQuery struct {
 // Get executes the query, returning any found results
 func (q LedgerStateQuery) Select(ctx context.Context, dest interface{}) error {
 	hSql := sq.
-		Select("MAX(sequence) as horizonsequence").
+		Select("MAX(sequence) as horizonsequence", "MIN(sequence) as horizoneldersequence").
 		From("history_ledgers")
 
 	scSql := sq.
@@ -45,3 +46,16 @@ func (q LedgerStateQuery) Select(ctx context.Context, dest interface{}) error {
 	setOn([]LedgerState{result}, dest)
 	return nil
 }
+
+// historyLedgers returns the sequences of the oldest and latest ledgers
+// history holds, both zero when it holds none.
+func historyLedgers(ctx context.Context, history SqlQuery) (elder int32, latest int32, err error) {
+	var ledgers struct {
+		Elder  int32 `db:"elder"`
+		Latest int32 `db:"latest"`
+	}
+	err = history.Get(ctx, sq.
+		Select("COALESCE(MIN(sequence), 0) as elder", "COALESCE(MAX(sequence), 0) as latest").
+		From("history_ledgers"), &ledgers)
+	return ledgers.Elder, ledgers.Latest, err
+}
@@ -21,6 +21,7 @@ func TestLedgerStateQuery(t *testing.T) {
 		err := Get(ctx, q, &ls)
 		So(err, ShouldBeNil)
 		So(ls.HorizonSequence, ShouldEqual, 3)
+		So(ls.HorizonElderSequence, ShouldEqual, 1)
 		So(ls.StellarCoreSequence, ShouldEqual, 3)
 	})
 }
@@ -0,0 +1,65 @@
+package db
+
+import (
+	sq "github.com/lann/squirrel"
+	"github.com/stellar/go-stellar-base/xdr"
+	"golang.org/x/net/context"
+)
+
+// LedgerStatsRecord is a ledger along with the counts of its operations by
+// type.
+type LedgerStatsRecord struct {
+	Ledger              LedgerRecord
+	OperationTypeCounts OperationTypeCounts
+}
+
+// LedgerStatsQuery loads the LedgerStatsRecord of the ledger of Sequence.  The
+// counts are those ingestion kept of the ledger, or for the ledgers ingested
+// before it kept them, counted from the operations history holds.
+type LedgerStatsQuery struct {
+	SqlQuery
+	Sequence int32
+}
+
+// Select executes the query, populating dest with a single LedgerStatsRecord,
+// or none when history does not hold the ledger.
+func (q LedgerStatsQuery) Select(ctx context.Context, dest interface{}) error {
+	var ledgers []LedgerRecord
+	err := Select(ctx, LedgerBySequenceQuery{q.SqlQuery, q.Sequence}, &ledgers)
+	if err != nil {
+		return err
+	}
+
+	if len(ledgers) == 0 {
+		return setOn([]LedgerStatsRecord{}, dest)
+	}
+
+	result := LedgerStatsRecord{
+		Ledger:              ledgers[0],
+		OperationTypeCounts: ledgers[0].OperationTypeCounts,
+	}
+
+	if result.OperationTypeCounts == nil {
+		var counts []struct {
+			Type  xdr.OperationType `db:"type"`
+			Count int32             `db:"count"`
+		}
+		start := TotalOrderId{LedgerSequence: q.Sequence}.ToInt64()
+		end := TotalOrderId{LedgerSequence: q.Sequence + 1}.ToInt64()
+		err = q.SqlQuery.Select(ctx, sq.
+			Select("type", "COUNT(*) AS count").
+			From("history_operations").
+			Where("id >= ? AND id < ?", start, end).
+			GroupBy("type"), &counts)
+		if err != nil {
+			return err
+		}
+
+		result.OperationTypeCounts = OperationTypeCounts{}
+		for _, c := range counts {
+			result.OperationTypeCounts[c.Type] = c.Count
+		}
+	}
+
+	return setOn([]LedgerStatsRecord{result}, dest)
+}
@@ -0,0 +1,57 @@
+package db
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/go-stellar-base/xdr"
+	"github.com/stellar/horizon/test"
+)
+
+func TestLedgerStatsQuery(t *testing.T) {
+	Convey("LedgerStatsQuery", t, func() {
+		test.LoadScenario("base")
+		var record LedgerStatsRecord
+
+		Convey("counts the operations of ledgers ingested without counts", func() {
+			MustGet(ctx, LedgerStatsQuery{SqlQuery{history}, 2}, &record)
+			So(record.Ledger.Sequence, ShouldEqual, 2)
+			So(record.OperationTypeCounts, ShouldResemble, OperationTypeCounts{xdr.OperationTypeCreateAccount: 3})
+
+			MustGet(ctx, LedgerStatsQuery{SqlQuery{history}, 1}, &record)
+			So(record.OperationTypeCounts, ShouldBeEmpty)
+		})
+
+		Convey("loads the counts ingestion kept", func() {
+			history.MustExec(`UPDATE history_ledgers SET operation_type_counts = '{"1": 7}' WHERE sequence = 3`)
+			MustGet(ctx, LedgerStatsQuery{SqlQuery{history}, 3}, &record)
+			So(record.OperationTypeCounts, ShouldResemble, OperationTypeCounts{xdr.OperationTypePayment: 7})
+		})
+
+		Convey("finds nothing of ledgers missing from history", func() {
+			err := Get(ctx, LedgerStatsQuery{SqlQuery{history}, 100}, &record)
+			So(err, ShouldEqual, ErrNoResults)
+		})
+	})
+}
+
+func TestOperationTypeCounts(t *testing.T) {
+	Convey("OperationTypeCounts", t, func() {
+		counts := OperationTypeCounts{xdr.OperationTypeCreateAccount: 2, xdr.OperationTypeManageOffer: 1}
+
+		value, err := counts.Value()
+		So(err, ShouldBeNil)
+		So(value, ShouldEqual, `{"0":2,"3":1}`)
+
+		var scanned OperationTypeCounts
+		So(scanned.Scan([]byte(value.(string))), ShouldBeNil)
+		So(scanned, ShouldResemble, counts)
+
+		So(scanned.Scan(nil), ShouldBeNil)
+		So(scanned, ShouldBeNil)
+
+		value, err = scanned.Value()
+		So(err, ShouldBeNil)
+		So(value, ShouldBeNil)
+	})
+}
@@ -8,7 +8,7 @@ import (
 
 const (
 	// PaymentTypeFilter restricts an OperationPageQuery to return only
-	// Payment and PathPayment operations
+	// CreateAccount, Payment, PathPayment and AccountMerge operations
 	PaymentTypeFilter = "payment"
 )
 
@@ -17,11 +17,19 @@ var operationFilterMap = map[string][]xdr.OperationType{
 		xdr.OperationTypeCreateAccount,
 		xdr.OperationTypePayment,
 		xdr.OperationTypePathPayment,
+		xdr.OperationTypeAccountMerge,
 	},
 }
 
 // OperationPageQuery is the main query for paging through a collection
-// of operations in the history database.
+// of operations in the history database.  History holds only the operations
+// of successful transactions; IncludeFailed adds to the page the operations
+// of the failed transactions stellar-core has kept, read through Core.
+//
+// TypeFilter restricts the page to a named group of operation types, such as
+// PaymentTypeFilter, and Types to the operation types listed.  When both are
+// set, operations must match both.  TimeRange restricts the page to the
+// operations of the ledgers closed within it.
 type OperationPageQuery struct {
 	SqlQuery
 	PageQuery
@@ -29,6 +37,10 @@ type OperationPageQuery struct {
 	LedgerSequence  int32
 	TransactionHash string
 	TypeFilter      string
+	Types           []xdr.OperationType
+	TimeRange       TimeRange
+	IncludeFailed   bool
+	Core            SqlQuery
 }
 
 // Select executes the query and returns the results
@@ -60,6 +72,17 @@ func (q OperationPageQuery) Select(ctx context.Context, dest interface{}) error
 		return err
 	}
 
+	var ledgers LedgerRange
+	if !q.TimeRange.IsZero() {
+		ledgers, err = q.TimeRange.Ledgers(ctx, q.SqlQuery)
+		if err != nil {
+			return err
+		}
+
+		start, end := ledgers.IDs()
+		sql = sql.Where("hop.id >= ? AND hop.id < ?", start, end)
+	}
+
 	// filter by ledger sequence
 	if q.LedgerSequence != 0 {
 		var ledger LedgerRecord
@@ -78,6 +101,19 @@ func (q OperationPageQuery) Select(ctx context.Context, dest interface{}) error
 		var tx TransactionRecord
 		err := Get(ctx, TransactionByHashQuery{q.SqlQuery, q.TransactionHash}, &tx)
 
+		// a failed transaction is not in history, only its operations are
+		// on the page
+		if err == ErrNoResults && q.IncludeFailed {
+			var core CoreTransactionRecord
+			err = Get(ctx, CoreTransactionByHashQuery{q.Core, q.TransactionHash}, &core)
+			if err != nil {
+				return err
+			}
+
+			ledgers = LedgerRange{Start: core.LedgerSequence, End: core.LedgerSequence + 1}
+			return q.selectFailed(ctx, ledgers, nil, dest)
+		}
+
 		if err != nil {
 			return err
 		}
@@ -106,5 +142,63 @@ func (q OperationPageQuery) Select(ctx context.Context, dest interface{}) error
 		sql = sql.Where(sq.Eq{"hop.type": types})
 	}
 
-	return q.SqlQuery.Select(ctx, sql, dest)
+	if len(q.Types) > 0 {
+		sql = sql.Where(sq.Eq{"hop.type": q.Types})
+	}
+
+	if !q.IncludeFailed {
+		return q.SqlQuery.Select(ctx, sql, dest)
+	}
+
+	var successful []OperationRecord
+	err = q.SqlQuery.Select(ctx, sql, &successful)
+	if err != nil {
+		return err
+	}
+
+	return q.selectFailed(ctx, ledgers, successful, dest)
+}
+
+// selectFailed loads the operations of failed transactions matching the query
+// within ledgers, up to the failedBound of successful, and merges them, in page order, with the successful
+// operations already loaded from history.
+func (q OperationPageQuery) selectFailed(ctx context.Context, ledgers LedgerRange, successful []OperationRecord, dest interface{}) error {
+	var last int64
+	if len(successful) > 0 {
+		last = successful[len(successful)-1].Id
+	}
+
+	bound, ok, err := failedBound(ctx, q.SqlQuery, q.PageQuery, len(successful), last)
+	if err != nil {
+		return err
+	}
+
+	var failed []OperationRecord
+	if ok {
+		err = Select(ctx, FailedOperationPageQuery{
+			SqlQuery:        q.Core,
+			PageQuery:       q.PageQuery,
+			AccountAddress:  q.AccountAddress,
+			LedgerSequence:  q.LedgerSequence,
+			TransactionHash: q.TransactionHash,
+			TypeFilter:      q.TypeFilter,
+			Types:           q.Types,
+			Ledgers:         ledgers,
+			Bound:           bound,
+		}, &failed)
+		if err != nil {
+			return err
+		}
+	}
+
+	result := make([]OperationRecord, 0, len(successful)+len(failed))
+	for len(result) < int(q.Limit) && (len(successful) > 0 || len(failed) > 0) {
+		if len(failed) == 0 || (len(successful) > 0 && pastCursor(q.Order, failed[0].Id, successful[0].Id)) {
+			result, successful = append(result, successful[0]), successful[1:]
+		} else {
+			result, failed = append(result, failed[0]), failed[1:]
+		}
+	}
+
+	return setOn(result, dest)
 }
@@ -5,6 +5,7 @@ import (
 
 	_ "github.com/lib/pq"
 	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/go-stellar-base/xdr"
 	"github.com/stellar/horizon/test"
 )
 
@@ -131,6 +132,20 @@ func TestOperationPageQuery(t *testing.T) {
 
 		})
 
+		Convey("obeys the listed types", func() {
+			q := makeQuery("", "asc", 0)
+			q.Types = []xdr.OperationType{xdr.OperationTypePayment}
+			MustSelect(ctx, q, &records)
+
+			So(len(records), ShouldEqual, 1)
+			So(records[0].Type, ShouldEqual, xdr.OperationTypePayment)
+
+			q.Types = []xdr.OperationType{xdr.OperationTypePayment, xdr.OperationTypeCreateAccount}
+			MustSelect(ctx, q, &records)
+
+			So(len(records), ShouldEqual, 4)
+		})
+
 		Convey("obeys the type filter", func() {
 			test.LoadScenario("pathed_payment")
 
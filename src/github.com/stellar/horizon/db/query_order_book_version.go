@@ -0,0 +1,71 @@
+package db
+
+import (
+	"database/sql"
+
+	sq "github.com/lann/squirrel"
+	"github.com/stellar/go-stellar-base/xdr"
+	"golang.org/x/net/context"
+)
+
+// OrderBookVersion identifies the state of the offers of an order book, on both
+// of its sides.  Every offer created or updated is stamped with the ledger in
+// which it changed, raising Lastmodified, and an offer removed lowers Offers,
+// so that any change to the book changes its version.
+type OrderBookVersion struct {
+	Offers       int32 `db:"offers"`
+	Lastmodified int32 `db:"lastmodified"`
+}
+
+// OrderBookVersionQuery loads the OrderBookVersion of the order book between
+// the selling and buying assets.  It is far cheaper than summarizing the book,
+// and lets streams of the book send a fresh summary only when it changed.
+type OrderBookVersionQuery struct {
+	SqlQuery
+	SellingType   xdr.AssetType
+	SellingCode   string
+	SellingIssuer string
+	BuyingType    xdr.AssetType
+	BuyingCode    string
+	BuyingIssuer  string
+}
+
+// Select executes the query, populating the provided OrderBookVersion
+func (q OrderBookVersionQuery) Select(ctx context.Context, dest interface{}) error {
+	asks := offerSide(q.SellingType, q.SellingCode, q.SellingIssuer, q.BuyingType, q.BuyingCode, q.BuyingIssuer)
+	bids := offerSide(q.BuyingType, q.BuyingCode, q.BuyingIssuer, q.SellingType, q.SellingCode, q.SellingIssuer)
+
+	sql := sq.
+		Select("COUNT(*) as offers", "COALESCE(MAX(co.lastmodified), 0) as lastmodified").
+		From("offers co").
+		Where(sq.Or{asks, bids})
+
+	var result OrderBookVersion
+	err := q.SqlQuery.Get(ctx, sql, &result)
+	if err != nil {
+		return err
+	}
+
+	setOn([]OrderBookVersion{result}, dest)
+	return nil
+}
+
+// offerSide returns a condition matching the offers selling one asset for
+// another.  Native assets have no code or issuer.
+func offerSide(
+	sellingType xdr.AssetType, sellingCode, sellingIssuer string,
+	buyingType xdr.AssetType, buyingCode, buyingIssuer string,
+) sq.Eq {
+	nullable := func(s string) sql.NullString {
+		return sql.NullString{String: s, Valid: s != ""}
+	}
+
+	return sq.Eq{
+		"co.sellingassettype": sellingType,
+		"co.sellingassetcode": nullable(sellingCode),
+		"co.sellingissuer":    nullable(sellingIssuer),
+		"co.buyingassettype":  buyingType,
+		"co.buyingassetcode":  nullable(buyingCode),
+		"co.buyingissuer":     nullable(buyingIssuer),
+	}
+}
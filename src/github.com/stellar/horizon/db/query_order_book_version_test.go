@@ -0,0 +1,54 @@
+package db
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/go-stellar-base/xdr"
+	"github.com/stellar/horizon/test"
+)
+
+func TestOrderBookVersionQuery(t *testing.T) {
+	test.LoadScenario("order_books")
+
+	Convey("OrderBookVersionQuery", t, func() {
+		q := OrderBookVersionQuery{
+			SqlQuery:      SqlQuery{core},
+			SellingType:   xdr.AssetTypeAssetTypeCreditAlphanum4,
+			SellingCode:   "USD",
+			SellingIssuer: "GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4",
+			BuyingType:    xdr.AssetTypeAssetTypeNative,
+		}
+
+		Convey("counts the offers on both sides of the book", func() {
+			var result OrderBookVersion
+			MustGet(ctx, q, &result)
+
+			So(result.Offers, ShouldEqual, 6)
+			So(result.Lastmodified, ShouldEqual, 5)
+		})
+
+		Convey("is the same in either direction", func() {
+			inverted := OrderBookVersionQuery{
+				SqlQuery:     q.SqlQuery,
+				SellingType:  q.BuyingType,
+				BuyingType:   q.SellingType,
+				BuyingCode:   q.SellingCode,
+				BuyingIssuer: q.SellingIssuer,
+			}
+
+			var result, inversion OrderBookVersion
+			MustGet(ctx, q, &result)
+			MustGet(ctx, inverted, &inversion)
+			So(inversion, ShouldResemble, result)
+		})
+
+		Convey("is zero for an empty book", func() {
+			q.SellingCode = "EUR"
+
+			var result OrderBookVersion
+			MustGet(ctx, q, &result)
+			So(result, ShouldResemble, OrderBookVersion{})
+		})
+	})
+}
@@ -0,0 +1,65 @@
+package db
+
+import (
+	sq "github.com/lann/squirrel"
+	"golang.org/x/net/context"
+)
+
+// PaymentSummaryRecord totals the payments of an asset an account received
+// and sent.  Amounts are decimals of seven places, as amounts are rendered.
+type PaymentSummaryRecord struct {
+	AssetType     string `db:"asset_type"`
+	AssetCode     string `db:"asset_code"`
+	AssetIssuer   string `db:"asset_issuer"`
+	Received      string `db:"received"`
+	ReceivedCount int32  `db:"received_count"`
+	Sent          string `db:"sent"`
+	SentCount     int32  `db:"sent_count"`
+}
+
+// PaymentSummaryQuery totals, per asset, the payments the account at Address
+// received and sent within TimeRange, being the operations of
+// PaymentTypeFilter.  The credits and debits they had effects of are summed
+// by the database, the starting balance of an account created counting as
+// received.  The records are ordered by asset type, code and then issuer.
+type PaymentSummaryQuery struct {
+	SqlQuery
+	Address   string
+	TimeRange TimeRange
+}
+
+// Select executes the query, populating dest with a PaymentSummaryRecord for
+// each asset the account paid or was paid in.
+func (q PaymentSummaryQuery) Select(ctx context.Context, dest interface{}) error {
+	amount := "COALESCE(he.details->>'amount', he.details->>'starting_balance')::numeric"
+	sql := sq.
+		Select(
+			"COALESCE(he.details->>'asset_type', 'native') AS asset_type",
+			"COALESCE(he.details->>'asset_code', '') AS asset_code",
+			"COALESCE(he.details->>'asset_issuer', '') AS asset_issuer",
+		).
+		Column("ROUND(COALESCE(SUM("+amount+") FILTER (WHERE he.type <> ?), 0), 7)::text AS received", EffectAccountDebited).
+		Column("COUNT(*) FILTER (WHERE he.type <> ?) AS received_count", EffectAccountDebited).
+		Column("ROUND(COALESCE(SUM("+amount+") FILTER (WHERE he.type = ?), 0), 7)::text AS sent", EffectAccountDebited).
+		Column("COUNT(*) FILTER (WHERE he.type = ?) AS sent_count", EffectAccountDebited).
+		From("history_effects he").
+		Join("history_accounts ha ON ha.id = he.history_account_id").
+		Join("history_operations hop ON hop.id = he.history_operation_id").
+		Where("ha.address = ?", q.Address).
+		Where(sq.Eq{"he.type": []int32{EffectAccountCreated, EffectAccountCredited, EffectAccountDebited}}).
+		Where(sq.Eq{"hop.type": operationFilterMap[PaymentTypeFilter]}).
+		GroupBy("1", "2", "3").
+		OrderBy("1", "2", "3")
+
+	if !q.TimeRange.IsZero() {
+		ledgers, err := q.TimeRange.Ledgers(ctx, q.SqlQuery)
+		if err != nil {
+			return err
+		}
+
+		start, end := ledgers.IDs()
+		sql = sql.Where("he.history_operation_id >= ? AND he.history_operation_id < ?", start, end)
+	}
+
+	return q.SqlQuery.Select(ctx, sql, dest)
+}
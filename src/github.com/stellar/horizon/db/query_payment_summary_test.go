@@ -0,0 +1,59 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/test"
+)
+
+func TestPaymentSummaryQuery(t *testing.T) {
+	test.LoadScenario("base")
+
+	Convey("PaymentSummaryQuery", t, func() {
+		var records []PaymentSummaryRecord
+
+		Convey("totals the payments received and sent of each asset", func() {
+			q := PaymentSummaryQuery{
+				SqlQuery: SqlQuery{history},
+				Address:  "GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON",
+			}
+			MustSelect(ctx, q, &records)
+			So(len(records), ShouldEqual, 1)
+			So(records[0].AssetType, ShouldEqual, "native")
+			So(records[0].Received, ShouldEqual, "105.0000000")
+			So(records[0].ReceivedCount, ShouldEqual, 2)
+			So(records[0].Sent, ShouldEqual, "0.0000000")
+			So(records[0].SentCount, ShouldEqual, 0)
+
+			q.Address = "GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU"
+			MustSelect(ctx, q, &records)
+			So(len(records), ShouldEqual, 1)
+			So(records[0].Received, ShouldEqual, "100.0000000")
+			So(records[0].Sent, ShouldEqual, "5.0000000")
+			So(records[0].SentCount, ShouldEqual, 1)
+		})
+
+		Convey("restricts the payments to the time range", func() {
+			q := PaymentSummaryQuery{
+				SqlQuery:  SqlQuery{history},
+				Address:   "GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON",
+				TimeRange: TimeRange{Start: time.Date(2015, 10, 7, 23, 7, 28, 0, time.UTC)},
+			}
+			MustSelect(ctx, q, &records)
+			So(len(records), ShouldEqual, 1)
+			So(records[0].Received, ShouldEqual, "5.0000000")
+			So(records[0].ReceivedCount, ShouldEqual, 1)
+		})
+
+		Convey("finds nothing of accounts never paid", func() {
+			q := PaymentSummaryQuery{
+				SqlQuery: SqlQuery{history},
+				Address:  "GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4",
+			}
+			MustSelect(ctx, q, &records)
+			So(records, ShouldBeEmpty)
+		})
+	})
+}
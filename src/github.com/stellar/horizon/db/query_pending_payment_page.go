@@ -0,0 +1,89 @@
+package db
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// PendingPaymentPageQuery loads a page of the payments to Address that failed
+// because Address did not trust the asset delivered, ordered by the id of the
+// failed operation.
+//
+// stellar-core's `txhistory` table keeps failed transactions along with their
+// results, but has no index by destination, so the query scans transactions
+// from the cursor until the page is full.  The scan is bounded by the ledgers
+// History holds since it created Address, the only ones in which payments to
+// Address could have failed for want of a trustline.
+type PendingPaymentPageQuery struct {
+	SqlQuery
+	PageQuery
+	Address string
+	History SqlQuery
+}
+
+func (q PendingPaymentPageQuery) Select(ctx context.Context, dest interface{}) error {
+	cursor, err := q.CursorInt64()
+	if err != nil {
+		return err
+	}
+
+	result := []PendingPaymentRecord{}
+
+	var account HistoryAccountRecord
+	err = Get(ctx, HistoryAccountByAddressQuery{q.History, q.Address}, &account)
+	if err == ErrNoResults {
+		return setOn(result, dest)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, latest, err := historyLedgers(ctx, q.History)
+	if err != nil {
+		return err
+	}
+
+	created := TotalOrderId{LedgerSequence: ParseTotalOrderId(account.Id).LedgerSequence}.ToInt64()
+	bound := TotalOrderId{LedgerSequence: latest + 1}.ToInt64()
+	switch {
+	case q.Order == OrderDescending:
+		bound = created
+	case cursor < created:
+		cursor = created
+	}
+
+	err = scanCoreTransactions(ctx, q.SqlQuery, q.Order, cursor, bound, nil, func(tx CoreTransactionRecord, _ time.Time) (bool, error) {
+		found, err := pendingPayments(tx, q.Address)
+		if err != nil {
+			return false, err
+		}
+
+		if q.Order == OrderDescending {
+			for i, j := 0, len(found)-1; i < j; i, j = i+1, j-1 {
+				found[i], found[j] = found[j], found[i]
+			}
+		}
+
+		for _, record := range found {
+			if q.Order == OrderDescending && record.Id >= cursor {
+				continue
+			}
+			if q.Order != OrderDescending && record.Id <= cursor {
+				continue
+			}
+			result = append(result, record)
+		}
+
+		return len(result) < int(q.Limit), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(result) > int(q.Limit) {
+		result = result[:q.Limit]
+	}
+
+	return setOn(result, dest)
+}
@@ -0,0 +1,47 @@
+package db
+
+import (
+	"golang.org/x/net/context"
+)
+
+// SignerAccountsPageQuery loads a page of the signers of accounts whose key is
+// Signer, from the current_signers that ingestion keeps, ordered by account.
+// Unlike the signer filter of CoreAccountPageQuery, the account that Signer is
+// the master key of is not among them.
+type SignerAccountsPageQuery struct {
+	SqlQuery
+	PageQuery
+	Signer string
+}
+
+func (q SignerAccountsPageQuery) Select(ctx context.Context, dest interface{}) error {
+	sql := SignerRecordSelect.
+		Where("si.publickey = ?", q.Signer).
+		Limit(uint64(q.Limit))
+
+	cursor, err := q.CursorAddress()
+	if err != nil {
+		return err
+	}
+
+	switch q.Order {
+	case "asc":
+		if cursor != "" {
+			sql = sql.Where("si.accountid > ?", cursor)
+		}
+		sql = sql.OrderBy("si.accountid asc")
+	case "desc":
+		if cursor != "" {
+			sql = sql.Where("si.accountid < ?", cursor)
+		}
+		sql = sql.OrderBy("si.accountid desc")
+	}
+
+	return q.SqlQuery.Select(ctx, sql, dest)
+}
+
+// CursorAddress returns the query's Cursor as the address of an account.  An
+// empty cursor returns an empty string.
+func (q SignerAccountsPageQuery) CursorAddress() (string, error) {
+	return q.Cursor, nil
+}
@@ -0,0 +1,71 @@
+package db
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/test"
+)
+
+func TestSignerAccountsPageQuery(t *testing.T) {
+	const signer = "GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4"
+
+	Convey("SignerAccountsPageQuery", t, func() {
+		test.LoadScenario("base")
+
+		for _, si := range []struct {
+			account string
+			key     string
+			weight  int32
+			removed bool
+		}{
+			{"GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON", signer, 1, false},
+			{"GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU", signer, 2, false},
+			{"GA5WBPYA5Y4WAEHXWR2UKO2UO4BUGHUQ74EUPKON2QHV4WRHOIRNKKH2", signer, 3, false},
+			{"GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H", signer, 0, true},
+			{"GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H", "GA5WBPYA5Y4WAEHXWR2UKO2UO4BUGHUQ74EUPKON2QHV4WRHOIRNKKH2", 1, false},
+		} {
+			history.MustExec(`
+				INSERT INTO current_signers
+				(accountid, publickey, weight, lastmodified, removed)
+				VALUES ($1, $2, $3, 3, $4)`,
+				si.account, si.key, si.weight, si.removed)
+		}
+
+		makeQuery := func(c string, o string, l int32) SignerAccountsPageQuery {
+			pq, err := NewPageQuery(c, o, l)
+			So(err, ShouldBeNil)
+
+			return SignerAccountsPageQuery{
+				SqlQuery:  SqlQuery{history},
+				PageQuery: pq,
+				Signer:    signer,
+			}
+		}
+
+		var records []SignerRecord
+
+		Convey("orders the accounts the key signs for by address", func() {
+			MustSelect(ctx, makeQuery("", "asc", 0), &records)
+			So(len(records), ShouldEqual, 3)
+			So(records[0].Accountid, ShouldEqual, "GA5WBPYA5Y4WAEHXWR2UKO2UO4BUGHUQ74EUPKON2QHV4WRHOIRNKKH2")
+			So(records[0].Weight, ShouldEqual, 3)
+			So(records[2].Accountid, ShouldEqual, "GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU")
+
+			MustSelect(ctx, makeQuery("", "desc", 0), &records)
+			So(records[0].Accountid, ShouldEqual, "GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU")
+		})
+
+		Convey("cursor works properly", func() {
+			MustSelect(ctx, makeQuery("", "asc", 1), &records)
+			So(len(records), ShouldEqual, 1)
+
+			MustSelect(ctx, makeQuery(records[0].PagingToken(), "asc", 0), &records)
+			So(len(records), ShouldEqual, 2)
+			So(records[0].Accountid, ShouldEqual, "GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON")
+
+			MustSelect(ctx, makeQuery(records[0].PagingToken(), "desc", 0), &records)
+			So(len(records), ShouldEqual, 1)
+		})
+	})
+}
@@ -1,15 +1,23 @@
 package db
 
 import (
+	"database/sql"
+	"reflect"
+	"time"
+
 	"github.com/go-errors/errors"
 	"github.com/jmoiron/sqlx"
 	sq "github.com/lann/squirrel"
 	"github.com/stellar/horizon/log"
+	"github.com/stellar/horizon/trace"
 	"golang.org/x/net/context"
 )
 
-// SqlQuery helps facilitate queries against a postgresql database. See Select and Get for
-// the main methods used by collaborators.
+// SqlQuery helps facilitate queries against a postgresql database, built in the
+// Dialect of DB. See Select and Get for the main methods used by collaborators.
+//
+// Each query reserves a connection of the Pool of DB while it runs, and is
+// cancelled when its context is done first or its QueryTimeout passes.
 type SqlQuery struct {
 	DB *sqlx.DB
 }
@@ -17,7 +25,7 @@ type SqlQuery struct {
 // Select selects multiple rows returned by the provided sql builder into the provided dest.
 // dest must be a slice of the correct record type.
 func (q SqlQuery) Select(ctx context.Context, sql sq.SelectBuilder, dest interface{}) error {
-	sql = sql.PlaceholderFormat(sq.Dollar)
+	sql = sql.PlaceholderFormat(DialectOf(q.DB).PlaceholderFormat())
 	query, args, err := sql.ToSql()
 
 	if err != nil {
@@ -28,21 +36,35 @@ func (q SqlQuery) Select(ctx context.Context, sql sq.SelectBuilder, dest interfa
 }
 
 // SelectRaw runs the provided postgres query and args against this sqlquery's db.
-func (q SqlQuery) SelectRaw(ctx context.Context, query string, args []interface{}, dest interface{}) error {
+// The query is traced as a "db.query" span within the span of the work it is
+// a part of, without its args, which are only logged at debug level.  Queries
+// running for longer than the threshold of the SlowQueryLog are recorded into
+// it, the time spent waiting on a connection aside.
+func (q SqlQuery) SelectRaw(ctx context.Context, query string, args []interface{}, dest interface{}) (err error) {
 	log.WithField(ctx, "sql", query).Info("query sql")
 	log.WithField(ctx, "args", args).Debug("query args")
 
-	err := q.DB.Select(dest, query, args...)
-	if err != nil {
-		err = errors.Wrap(err, 1)
-	}
-	return err
+	ctx, span := trace.StartChild(ctx, "db.query")
+	span.SetAttribute("db.system", DialectOf(q.DB).Name())
+	span.SetAttribute("db.statement", query)
+	defer func() { span.Finish(err) }()
+
+	pool := PoolOf(q.DB)
+	return pool.run(ctx, func(db sqlx.Queryer) error {
+		start := time.Now()
+		err := sqlx.Select(db, dest, query, args...)
+		recordSlowQuery(ctx, pool, query, args, time.Since(start), err)
+		if err != nil {
+			return errors.Wrap(err, 1)
+		}
+		return nil
+	})
 }
 
 // Get gets a single row returned by the provided sql builder into the provided dest.
 // dest must be a non-slice value of the correct record type.
 func (q SqlQuery) Get(ctx context.Context, sql sq.SelectBuilder, dest interface{}) error {
-	sql = sql.PlaceholderFormat(sq.Dollar)
+	sql = sql.PlaceholderFormat(DialectOf(q.DB).PlaceholderFormat())
 	query, args, err := sql.ToSql()
 
 	if err != nil {
@@ -54,12 +76,21 @@ func (q SqlQuery) Get(ctx context.Context, sql sq.SelectBuilder, dest interface{
 
 // GetRaw runs the provided postgres query and args against this sqlquery's db.
 func (q SqlQuery) GetRaw(ctx context.Context, query string, args []interface{}, dest interface{}) error {
-	log.WithField(ctx, "sql", query).Info("query sql")
-	log.WithField(ctx, "args", args).Debug("query args")
+	if err := validateDestination(dest); err != nil {
+		return err
+	}
 
-	err := q.DB.Get(dest, query, args...)
+	// the rows are selected into a slice, the first of them being dest
+	rows := reflect.New(reflect.SliceOf(reflect.TypeOf(dest).Elem()))
+	err := q.SelectRaw(ctx, query, args, rows.Interface())
 	if err != nil {
-		err = errors.Wrap(err, 1)
+		return err
 	}
-	return err
+
+	if rows.Elem().Len() == 0 {
+		return errors.Wrap(sql.ErrNoRows, 1)
+	}
+
+	reflect.ValueOf(dest).Elem().Set(rows.Elem().Index(0))
+	return nil
 }
@@ -0,0 +1,126 @@
+package db
+
+import (
+	stderr "errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-errors/errors"
+	"github.com/jmoiron/sqlx"
+	"golang.org/x/net/context"
+)
+
+// ErrQueryTimeout is returned when a query runs past the timeout of its
+// QueryClass, and was cancelled.
+// NOTE: this is not a go-errors based error, as stack traces are unnecessary
+var ErrQueryTimeout = stderr.New("query timed out")
+
+// ErrQueryCanceled is returned when the context of a query was done before the
+// query completed, as when the client of a request goes away, and the query
+// was cancelled.
+// NOTE: this is not a go-errors based error, as stack traces are unnecessary
+var ErrQueryCanceled = stderr.New("query canceled")
+
+// QueryClass classifies the queries horizon runs by what they are run for,
+// each class having its own timeout.
+type QueryClass int
+
+const (
+	// PageQueries load the resources of a request.  Queries of contexts not
+	// given a class are PageQueries.
+	PageQueries QueryClass = iota
+
+	// StreamQueries poll for the events of a stream, as ledgers are pumped.
+	StreamQueries
+
+	// IngestQueries read and write history as ledgers are ingested.
+	IngestQueries
+)
+
+// QueryTimeouts are the timeouts of the statements of each QueryClass.  A zero
+// timeout leaves the statements of its class to run as long as they take.
+type QueryTimeouts struct {
+	Page   time.Duration
+	Stream time.Duration
+	Ingest time.Duration
+}
+
+var (
+	queryClassKey = 0
+
+	timeoutsLock sync.RWMutex
+	timeouts     QueryTimeouts
+)
+
+// SetQueryTimeouts sets the timeouts of the queries of each class.  None are
+// set by default.
+func SetQueryTimeouts(t QueryTimeouts) {
+	timeoutsLock.Lock()
+	defer timeoutsLock.Unlock()
+	timeouts = t
+}
+
+// WithQueryClass returns a context derived from ctx whose queries are of
+// class.
+func WithQueryClass(ctx context.Context, class QueryClass) context.Context {
+	return context.WithValue(ctx, &queryClassKey, class)
+}
+
+// QueryClassFromContext returns the class of the queries of ctx, PageQueries
+// unless set with WithQueryClass.
+func QueryClassFromContext(ctx context.Context) QueryClass {
+	if ctx == nil {
+		return PageQueries
+	}
+
+	class, ok := ctx.Value(&queryClassKey).(QueryClass)
+	if !ok {
+		return PageQueries
+	}
+
+	return class
+}
+
+// QueryTimeout returns the timeout of the queries of ctx, as set with
+// SetQueryTimeouts for their class.
+func QueryTimeout(ctx context.Context) time.Duration {
+	timeoutsLock.RLock()
+	defer timeoutsLock.RUnlock()
+	return timeouts.For(QueryClassFromContext(ctx))
+}
+
+// For returns the timeout of the statements of class
+func (t QueryTimeouts) For(class QueryClass) time.Duration {
+	switch class {
+	case StreamQueries:
+		return t.Stream
+	case IngestQueries:
+		return t.Ingest
+	default:
+		return t.Page
+	}
+}
+
+// SetStatementTimeout sets the statement timeout of the rest of tx to the
+// timeout of the queries of ctx, when they have one.  Statements run within a
+// transaction are not run through SqlQuery, so that the database itself must
+// enforce their timeout.
+func SetStatementTimeout(ctx context.Context, tx *sqlx.Tx) error {
+	timeout := QueryTimeout(ctx)
+	if timeout <= 0 {
+		return nil
+	}
+
+	ms := timeout / time.Millisecond
+	if ms < 1 {
+		ms = 1
+	}
+
+	_, err := tx.Exec(fmt.Sprintf("SET LOCAL statement_timeout = %d", ms))
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	return nil
+}
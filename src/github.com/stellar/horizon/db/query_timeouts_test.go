@@ -0,0 +1,29 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/test"
+)
+
+func TestQueryTimeouts(t *testing.T) {
+	ctx := test.Context()
+
+	Convey("QueryClassFromContext", t, func() {
+		So(QueryClassFromContext(ctx), ShouldEqual, PageQueries)
+		So(QueryClassFromContext(nil), ShouldEqual, PageQueries)
+		So(QueryClassFromContext(WithQueryClass(ctx, StreamQueries)), ShouldEqual, StreamQueries)
+		So(QueryClassFromContext(WithQueryClass(ctx, IngestQueries)), ShouldEqual, IngestQueries)
+	})
+
+	Convey("QueryTimeout", t, func() {
+		SetQueryTimeouts(QueryTimeouts{Page: 3 * time.Second, Stream: 1 * time.Second})
+		defer SetQueryTimeouts(QueryTimeouts{})
+
+		So(QueryTimeout(ctx), ShouldEqual, 3*time.Second)
+		So(QueryTimeout(WithQueryClass(ctx, StreamQueries)), ShouldEqual, 1*time.Second)
+		So(QueryTimeout(WithQueryClass(ctx, IngestQueries)), ShouldEqual, 0)
+	})
+}
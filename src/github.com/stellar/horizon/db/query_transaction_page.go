@@ -2,11 +2,19 @@ package db
 
 import "golang.org/x/net/context"
 
+// TransactionPageQuery is the main query for paging through a collection of
+// transactions in the history database.  History holds only successful
+// transactions; IncludeFailed adds to the page the failed transactions
+// stellar-core has kept, read through Core.  TimeRange restricts the page to
+// the transactions of the ledgers closed within it.
 type TransactionPageQuery struct {
 	SqlQuery
 	PageQuery
 	AccountAddress string
 	LedgerSequence int32
+	TimeRange      TimeRange
+	IncludeFailed  bool
+	Core           SqlQuery
 }
 
 func (q TransactionPageQuery) Select(ctx context.Context, dest interface{}) error {
@@ -42,5 +50,60 @@ func (q TransactionPageQuery) Select(ctx context.Context, dest interface{}) erro
 		sql = sql.Where("ht.ledger_sequence = ?", q.LedgerSequence)
 	}
 
-	return q.SqlQuery.Select(ctx, sql, dest)
+	var ledgers LedgerRange
+	if !q.TimeRange.IsZero() {
+		ledgers, err = q.TimeRange.Ledgers(ctx, q.SqlQuery)
+		if err != nil {
+			return err
+		}
+
+		start, end := ledgers.IDs()
+		sql = sql.Where("ht.id >= ? AND ht.id < ?", start, end)
+	}
+
+	if !q.IncludeFailed {
+		return q.SqlQuery.Select(ctx, sql, dest)
+	}
+
+	var successful []TransactionRecord
+	err = q.SqlQuery.Select(ctx, sql, &successful)
+	if err != nil {
+		return err
+	}
+
+	var last int64
+	if len(successful) > 0 {
+		last = successful[len(successful)-1].Id
+	}
+
+	bound, ok, err := failedBound(ctx, q.SqlQuery, q.PageQuery, len(successful), last)
+	if err != nil {
+		return err
+	}
+
+	var failed []TransactionRecord
+	if ok {
+		err = Select(ctx, FailedTransactionPageQuery{
+			SqlQuery:       q.Core,
+			PageQuery:      q.PageQuery,
+			AccountAddress: q.AccountAddress,
+			LedgerSequence: q.LedgerSequence,
+			Ledgers:        ledgers,
+			Bound:          bound,
+		}, &failed)
+		if err != nil {
+			return err
+		}
+	}
+
+	result := make([]TransactionRecord, 0, len(successful)+len(failed))
+	for len(result) < int(q.Limit) && (len(successful) > 0 || len(failed) > 0) {
+		if len(failed) == 0 || (len(successful) > 0 && pastCursor(q.Order, failed[0].Id, successful[0].Id)) {
+			result, successful = append(result, successful[0]), successful[1:]
+		} else {
+			result, failed = append(result, failed[0]), failed[1:]
+		}
+	}
+
+	return setOn(result, dest)
 }
@@ -0,0 +1,51 @@
+package db
+
+import (
+	sq "github.com/lann/squirrel"
+)
+
+// CoreAssetStatRecordSelect is a sql fragment to help select form queries that
+// select into a CoreAssetStatRecord.  Each row aggregates the trustlines held
+// for a single asset, joined to the account of the asset's issuer when it
+// still exists: the assets of merged issuers have no flags.
+var CoreAssetStatRecordSelect = sq.Select(
+	"tl.assettype",
+	"tl.assetcode",
+	"tl.issuer",
+	"SUM(tl.balance) as amount",
+	"COUNT(*) as numaccounts",
+	"COALESCE(ia.flags, 0) as flags",
+).
+	From("trustlines tl").
+	LeftJoin("accounts ia ON ia.accountid = tl.issuer").
+	GroupBy("tl.assettype", "tl.assetcode", "tl.issuer", "ia.flags")
+
+// CoreAssetStatRecord summarizes an asset issued on the network, as of the
+// latest ledger known to stellar-core: the amount of the asset held by
+// accounts other than its issuer, the number of accounts that trust it, and
+// the flags of its issuer.
+type CoreAssetStatRecord struct {
+	Assettype   int32  `db:"assettype"`
+	Assetcode   string `db:"assetcode"`
+	Issuer      string `db:"issuer"`
+	Amount      int64  `db:"amount"`
+	Numaccounts int32  `db:"numaccounts"`
+	Flags       int32  `db:"flags"`
+}
+
+// PagingToken returns a suitable paging token for the CoreAssetStatRecord
+func (r CoreAssetStatRecord) PagingToken() string {
+	return r.Assetcode + AssetStatCursorSep + r.Issuer
+}
+
+// IsAuthRequired returns true if the asset's issuer must authorize the
+// accounts that trust it.
+func (r CoreAssetStatRecord) IsAuthRequired() bool {
+	return (r.Flags & FlagAuthRequired) != 0
+}
+
+// IsAuthRevocable returns true if the asset's issuer may revoke its
+// authorization of the accounts that trust it.
+func (r CoreAssetStatRecord) IsAuthRevocable() bool {
+	return (r.Flags & FlagAuthRevocable) != 0
+}
@@ -0,0 +1,71 @@
+package db
+
+import (
+	"github.com/go-errors/errors"
+	sq "github.com/lann/squirrel"
+	"github.com/stellar/go-stellar-base/xdr"
+	"golang.org/x/net/context"
+)
+
+// CoreLedgerHeaderRecordSelect is a sql fragment to help select form queries
+// that select into a CoreLedgerHeaderRecord
+var CoreLedgerHeaderRecordSelect = sq.
+	Select("clh.ledgerhash", "clh.ledgerseq", "clh.closetime", "clh.data").
+	From("ledgerheaders clh")
+
+// CoreLedgerHeaderRecord is row of data from the `ledgerheaders` table from
+// stellar-core
+type CoreLedgerHeaderRecord struct {
+	LedgerHash string `db:"ledgerhash"`
+	Sequence   int32  `db:"ledgerseq"`
+	CloseTime  int64  `db:"closetime"`
+	DataXDR    string `db:"data"`
+}
+
+// Header decodes the record's LedgerHeader
+func (r CoreLedgerHeaderRecord) Header() (xdr.LedgerHeader, error) {
+	var header xdr.LedgerHeader
+	err := xdr.SafeUnmarshalBase64(r.DataXDR, &header)
+	if err != nil {
+		return header, errors.Wrap(err, 1)
+	}
+
+	return header, nil
+}
+
+// Upgrades decodes the ledger upgrades applied in the record's ledger, such as
+// changes to the protocol version or base fee, in the order applied.
+func (r CoreLedgerHeaderRecord) Upgrades() ([]xdr.LedgerUpgrade, error) {
+	header, err := r.Header()
+	if err != nil {
+		return nil, err
+	}
+
+	upgrades := make([]xdr.LedgerUpgrade, len(header.ScpValue.Upgrades))
+	for i, raw := range header.ScpValue.Upgrades {
+		err := xdr.SafeUnmarshal(raw, &upgrades[i])
+		if err != nil {
+			return nil, errors.Wrap(err, 1)
+		}
+	}
+
+	return upgrades, nil
+}
+
+// ledgerheaders queries
+
+// CoreLedgerHeadersBySequenceQuery loads the headers stellar-core has kept of
+// the ledgers of the given sequences.  Headers stellar-core no longer has are
+// left out.
+type CoreLedgerHeadersBySequenceQuery struct {
+	SqlQuery
+	Sequences []int32
+}
+
+func (q CoreLedgerHeadersBySequenceQuery) Select(ctx context.Context, dest interface{}) error {
+	sql := CoreLedgerHeaderRecordSelect.
+		Where(sq.Eq{"clh.ledgerseq": q.Sequences}).
+		OrderBy("clh.ledgerseq asc")
+
+	return q.SqlQuery.Select(ctx, sql, dest)
+}
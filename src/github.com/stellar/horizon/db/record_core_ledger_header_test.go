@@ -0,0 +1,43 @@
+package db
+
+import (
+	"testing"
+
+	_ "github.com/lib/pq"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/go-stellar-base/xdr"
+	"github.com/stellar/horizon/test"
+)
+
+func TestCoreLedgerHeadersBySequenceQuery(t *testing.T) {
+	test.LoadScenario("base")
+
+	Convey("CoreLedgerHeadersBySequenceQuery", t, func() {
+		var headers []CoreLedgerHeaderRecord
+
+		err := Select(ctx, CoreLedgerHeadersBySequenceQuery{SqlQuery{core}, []int32{3, 2, 100}}, &headers)
+		So(err, ShouldBeNil)
+		So(len(headers), ShouldEqual, 2)
+		So(headers[0].Sequence, ShouldEqual, 2)
+		So(headers[1].Sequence, ShouldEqual, 3)
+
+		header, err := headers[0].Header()
+		So(err, ShouldBeNil)
+		So(header.LedgerSeq, ShouldEqual, 2)
+		So(header.BaseFee, ShouldEqual, 100)
+
+		Convey("decodes the upgrades applied", func() {
+			upgrades, err := headers[0].Upgrades()
+			So(err, ShouldBeNil)
+			So(len(upgrades), ShouldEqual, 2)
+			So(upgrades[0].Type, ShouldEqual, xdr.LedgerUpgradeTypeLedgerUpgradeVersion)
+			So(upgrades[0].MustNewLedgerVersion(), ShouldEqual, 1)
+			So(upgrades[1].Type, ShouldEqual, xdr.LedgerUpgradeTypeLedgerUpgradeMaxTxSetSize)
+			So(upgrades[1].MustNewMaxTxSetSize(), ShouldEqual, 500)
+
+			upgrades, err = headers[1].Upgrades()
+			So(err, ShouldBeNil)
+			So(len(upgrades), ShouldEqual, 0)
+		})
+	})
+}
@@ -1,6 +1,8 @@
 package db
 
 import (
+	"time"
+
 	sq "github.com/lann/squirrel"
 	"golang.org/x/net/context"
 )
@@ -33,3 +35,89 @@ func (q CoreTransactionByHashQuery) Select(ctx context.Context, dest interface{}
 
 	return q.SqlQuery.Select(ctx, sql, dest)
 }
+
+// CoreTransactionsByLedgerQuery loads the transactions stellar-core applied in
+// the ledger of the given sequence, in the order applied.
+type CoreTransactionsByLedgerQuery struct {
+	SqlQuery
+	LedgerSequence int32
+}
+
+func (q CoreTransactionsByLedgerQuery) Select(ctx context.Context, dest interface{}) error {
+	sql := CoreTransactionRecordSelect.
+		Where("ctxh.ledgerseq = ?", q.LedgerSequence).
+		OrderBy("ctxh.txindex asc")
+
+	return q.SqlQuery.Select(ctx, sql, dest)
+}
+
+// CoreTransactionScanSize is the number of stellar-core transactions read at a
+// time by scanCoreTransactions.
+const CoreTransactionScanSize = 200
+
+// scanCoreTransactions calls fn with the transactions stellar-core has kept,
+// in the given order, along with the close time of their ledgers, starting
+// from the transaction that holds the TotalOrderId cursor and stopping short
+// of the one that holds bound.  A bound of zero leaves the scan unbounded.  A
+// filter, when not nil, restricts the transactions scanned.  The scan stops
+// once fn returns false or the transactions run out.
+//
+// txhistory has no index suited to the filters applied by callers, so the
+// transactions are read in batches of CoreTransactionScanSize.
+func scanCoreTransactions(
+	ctx context.Context,
+	q SqlQuery,
+	order string,
+	cursor int64,
+	bound int64,
+	filter sq.Sqlizer,
+	fn func(CoreTransactionRecord, time.Time) (bool, error),
+) error {
+	pos := ParseTotalOrderId(cursor)
+	end := ParseTotalOrderId(bound)
+	op, endOp, dir := ">=", "<", "asc"
+	if order == OrderDescending {
+		op, endOp, dir = "<=", ">", "desc"
+	}
+
+	for {
+		sql := sq.Select("ctxh.*", "lh.closetime").
+			From("txhistory ctxh").
+			Join("ledgerheaders lh ON lh.ledgerseq = ctxh.ledgerseq").
+			Where("(ctxh.ledgerseq, ctxh.txindex) "+op+" (?, ?)", pos.LedgerSequence, pos.TransactionOrder).
+			OrderBy("ctxh.ledgerseq "+dir, "ctxh.txindex "+dir).
+			Limit(CoreTransactionScanSize)
+
+		if bound != 0 {
+			sql = sql.Where("(ctxh.ledgerseq, ctxh.txindex) "+endOp+" (?, ?)", end.LedgerSequence, end.TransactionOrder)
+		}
+
+		if filter != nil {
+			sql = sql.Where(filter)
+		}
+
+		var txs []struct {
+			CoreTransactionRecord
+			CloseTime int64 `db:"closetime"`
+		}
+		err := q.Select(ctx, sql, &txs)
+		if err != nil {
+			return err
+		}
+
+		for _, tx := range txs {
+			more, err := fn(tx.CoreTransactionRecord, time.Unix(tx.CloseTime, 0).UTC())
+			if err != nil || !more {
+				return err
+			}
+		}
+
+		if len(txs) < CoreTransactionScanSize {
+			return nil
+		}
+
+		last := txs[len(txs)-1]
+		pos = TotalOrderId{LedgerSequence: last.LedgerSequence, TransactionOrder: last.Index}
+		op = op[:1]
+	}
+}
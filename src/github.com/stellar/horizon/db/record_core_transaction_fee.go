@@ -0,0 +1,38 @@
+package db
+
+import (
+	sq "github.com/lann/squirrel"
+	"golang.org/x/net/context"
+)
+
+// CoreTransactionFeeRecordSelect is a sql fragment to help select form queries
+// that select into a CoreTransactionFeeRecord
+var CoreTransactionFeeRecordSelect = sq.Select("ctxfh.*").From("txfeehistory ctxfh")
+
+// CoreTransactionFeeRecord is row of data from the `txfeehistory` table from
+// stellar-core, which holds the ledger changes made when charging the fee of a
+// transaction.
+type CoreTransactionFeeRecord struct {
+	TransactionHash string `db:"txid"`
+	LedgerSequence  int32  `db:"ledgerseq"`
+	Index           int32  `db:"txindex"`
+	ChangesXDR      string `db:"txchanges"`
+}
+
+// txfeehistory queries
+
+// CoreTransactionFeesByLedgerQuery loads the fee changes of the transactions
+// stellar-core applied in the ledger of the given sequence, in the order
+// applied.
+type CoreTransactionFeesByLedgerQuery struct {
+	SqlQuery
+	LedgerSequence int32
+}
+
+func (q CoreTransactionFeesByLedgerQuery) Select(ctx context.Context, dest interface{}) error {
+	sql := CoreTransactionFeeRecordSelect.
+		Where("ctxfh.ledgerseq = ?", q.LedgerSequence).
+		OrderBy("ctxfh.txindex asc")
+
+	return q.SqlQuery.Select(ctx, sql, dest)
+}
@@ -21,6 +21,8 @@ const (
 	EffectAccountHomeDomainUpdated = 5 // from set_options
 	EffectAccountFlagsUpdated      = 6 // from set_options
 
+	EffectAccountInflationDestinationUpdated = 7 // from set_options
+
 	// signer effects
 	EffectSignerCreated = 10 // from set_options
 	EffectSignerRemoved = 11 // from set_options
@@ -35,8 +37,8 @@ const (
 
 	// trading effects
 	EffectOfferCreated = 30 // from manage_offer, creat_passive_offer
-	EffectOfferRemoved = 31 // from manage_offer, creat_passive_offer, path_payment
-	EffectOfferUpdated = 32 // from manage_offer, creat_passive_offer, path_payment
+	EffectOfferRemoved = 31 // from manage_offer
+	EffectOfferUpdated = 32 // from manage_offer
 	EffectTrade        = 33 // from manage_offer, creat_passive_offer, path_payment
 )
 
@@ -179,55 +181,93 @@ type EffectOrderBookFilter struct {
 func (f *EffectOrderBookFilter) Apply(ctx context.Context, in sq.SelectBuilder) (sql sq.SelectBuilder, err error) {
 	sql = in
 
-	sellingType, err := assets.String(f.SellingType)
+	sold, err := tradedAsset("sold", f.SellingType, f.SellingCode, f.SellingIssuer)
 	if err != nil {
 		return
 	}
 
-	buyingType, err := assets.String(f.BuyingType)
+	bought, err := tradedAsset("bought", f.BuyingType, f.BuyingCode, f.BuyingIssuer)
 	if err != nil {
 		return
 	}
 
-	if f.SellingType == xdr.AssetTypeAssetTypeNative {
-		sql = sql.Where(`
-				(heff.details->>'sold_asset_type' = ? 
-		AND heff.details ?? 'sold_asset_code' = false
-		AND heff.details ?? 'sold_asset_issuer' = false)`,
-			sellingType,
-		)
-	} else {
-		sql = sql.Where(`
-				(heff.details->>'sold_asset_type' = ? 
-		AND heff.details->>'sold_asset_code' = ?
-		AND heff.details->>'sold_asset_issuer' = ?)`,
-			sellingType,
-			f.SellingCode,
-			f.SellingIssuer,
-		)
+	sql = sql.Where(sold).Where(bought)
+	return
+}
+
+// EffectOfferFilter represents a filter that excludes all rows that are not
+// trades against the specified offer
+type EffectOfferFilter struct {
+	OfferID int64
+}
+
+func (f *EffectOfferFilter) Apply(ctx context.Context, sql sq.SelectBuilder) (sq.SelectBuilder, error) {
+	return sql.Where("(heff.details->>'offer_id')::bigint = ?", f.OfferID), nil
+}
+
+// EffectAssetPairFilter represents a filter that excludes all rows that are
+// not trades between the base and counter assets, in either direction
+type EffectAssetPairFilter struct {
+	BaseType      xdr.AssetType
+	BaseCode      string
+	BaseIssuer    string
+	CounterType   xdr.AssetType
+	CounterCode   string
+	CounterIssuer string
+}
+
+func (f *EffectAssetPairFilter) Apply(ctx context.Context, sql sq.SelectBuilder) (sq.SelectBuilder, error) {
+	var clauses [4]sq.Sqlizer
+	var err error
+
+	sides := []struct {
+		name   string
+		t      xdr.AssetType
+		code   string
+		issuer string
+	}{
+		{"sold", f.BaseType, f.BaseCode, f.BaseIssuer},
+		{"bought", f.CounterType, f.CounterCode, f.CounterIssuer},
+		{"sold", f.CounterType, f.CounterCode, f.CounterIssuer},
+		{"bought", f.BaseType, f.BaseCode, f.BaseIssuer},
 	}
 
-	if f.BuyingType == xdr.AssetTypeAssetTypeNative {
-		sql = sql.Where(`
-				(heff.details->>'bought_asset_type' = ? 
-		AND heff.details ?? 'bought_asset_code' = false 
-		AND heff.details ?? 'bought_asset_issuer' = false)`,
-			buyingType,
-		)
-	} else {
-		sql = sql.Where(`
-				(heff.details->>'bought_asset_type' = ? 
-		AND heff.details->>'bought_asset_code' = ?
-		AND heff.details->>'bought_asset_issuer' = ?)`,
-			buyingType,
-			f.BuyingCode,
-			f.BuyingIssuer,
-		)
+	for i, side := range sides {
+		clauses[i], err = tradedAsset(side.name, side.t, side.code, side.issuer)
+		if err != nil {
+			return sql, err
+		}
 	}
 
+	return sql.Where(sq.Or{
+		sq.And{clauses[0], clauses[1]},
+		sq.And{clauses[2], clauses[3]},
+	}), nil
+}
+
+// tradedAsset returns a sql clause matching trade effects whose side, sold or
+// bought, is the provided asset.
+func tradedAsset(side string, t xdr.AssetType, code, issuer string) (sq.Sqlizer, error) {
+	name, err := assets.String(t)
 	if err != nil {
-		return
+		return nil, err
 	}
 
-	return
+	if t == xdr.AssetTypeAssetTypeNative {
+		return sq.Expr(fmt.Sprintf(`
+				(heff.details->>'%[1]s_asset_type' = ?
+		AND heff.details ?? '%[1]s_asset_code' = false
+		AND heff.details ?? '%[1]s_asset_issuer' = false)`, side),
+			name,
+		), nil
+	}
+
+	return sq.Expr(fmt.Sprintf(`
+				(heff.details->>'%[1]s_asset_type' = ?
+		AND heff.details->>'%[1]s_asset_code' = ?
+		AND heff.details->>'%[1]s_asset_issuer' = ?)`, side),
+		name,
+		code,
+		issuer,
+	), nil
 }
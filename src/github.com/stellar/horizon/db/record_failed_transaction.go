@@ -0,0 +1,436 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/go-errors/errors"
+	"github.com/stellar/go-stellar-base/amount"
+	"github.com/stellar/go-stellar-base/xdr"
+	"github.com/stellar/horizon/assets"
+)
+
+// failedTransaction converts a transaction stellar-core has kept into the
+// TransactionRecord horizon's history would hold for it, were failed
+// transactions imported.  ok is false when the transaction succeeded, in which
+// case history already holds it.
+func failedTransaction(tx CoreTransactionRecord, closedAt time.Time) (record TransactionRecord, env xdr.TransactionEnvelope, ok bool, err error) {
+	var trp xdr.TransactionResultPair
+	err = xdr.SafeUnmarshalBase64(tx.ResultXDR, &trp)
+	if err != nil {
+		err = errors.Wrap(err, 1)
+		return
+	}
+
+	if trp.Result.Result.Code == xdr.TransactionResultCodeTxSuccess {
+		return
+	}
+
+	record, env, err = NewTransactionRecord(tx, closedAt)
+	ok = err == nil
+	return
+}
+
+// NewTransactionRecord converts a transaction stellar-core has kept, closed in
+// a ledger at closedAt, into the TransactionRecord history holds for it and
+// returns it along with the transaction's envelope.  TxFeeMeta is left empty,
+// as stellar-core keeps the fee changes of transactions in another table.
+func NewTransactionRecord(tx CoreTransactionRecord, closedAt time.Time) (record TransactionRecord, env xdr.TransactionEnvelope, err error) {
+	var trp xdr.TransactionResultPair
+	err = xdr.SafeUnmarshalBase64(tx.ResultXDR, &trp)
+	if err != nil {
+		err = errors.Wrap(err, 1)
+		return
+	}
+
+	err = xdr.SafeUnmarshalBase64(tx.EnvelopeXDR, &env)
+	if err != nil {
+		err = errors.Wrap(err, 1)
+		return
+	}
+
+	result, err := xdr.MarshalBase64(trp.Result)
+	if err != nil {
+		err = errors.Wrap(err, 1)
+		return
+	}
+
+	account, err := accountAddress(env.Tx.SourceAccount)
+	if err != nil {
+		return
+	}
+
+	signatures := make([]string, len(env.Signatures))
+	for i, sig := range env.Signatures {
+		signatures[i] = base64.StdEncoding.EncodeToString(sig.Signature)
+	}
+
+	record = TransactionRecord{
+		HistoryRecord: HistoryRecord{Id: TotalOrderId{
+			LedgerSequence:   tx.LedgerSequence,
+			TransactionOrder: tx.Index,
+		}.ToInt64()},
+		TransactionHash:  tx.TransactionHash,
+		LedgerSequence:   tx.LedgerSequence,
+		LedgerCloseTime:  closedAt,
+		ApplicationOrder: tx.Index,
+		Account:          account,
+		AccountSequence:  int64(env.Tx.SeqNum),
+		MaxFee:           int32(env.Tx.Fee),
+		FeePaid:          int32(trp.Result.FeeCharged),
+		OperationCount:   int32(len(env.Tx.Operations)),
+		TxEnvelope:       tx.EnvelopeXDR,
+		TxResult:         result,
+		TxMeta:           tx.ResultMetaXDR,
+		SignatureString:  strings.Join(signatures, ","),
+		CreatedAt:        closedAt,
+		UpdatedAt:        closedAt,
+		Successful:       trp.Result.Result.Code == xdr.TransactionResultCodeTxSuccess,
+	}
+
+	record.MemoType, record.Memo = memoParts(env.Tx.Memo)
+
+	if tb := env.Tx.TimeBounds; tb != nil {
+		record.ValidAfter = sql.NullInt64{Int64: int64(tb.MinTime), Valid: true}
+		record.ValidBefore = sql.NullInt64{Int64: int64(tb.MaxTime), Valid: true}
+	}
+
+	return
+}
+
+// NewOperationRecords returns the OperationRecords of the operations of tx,
+// whose envelope is env.  results, the results of the operations, are nil for
+// failed transactions; when given, details only known once an operation
+// succeeds are included.
+func NewOperationRecords(tx TransactionRecord, env xdr.TransactionEnvelope, results []xdr.OperationResult) ([]OperationRecord, error) {
+	result := make([]OperationRecord, len(env.Tx.Operations))
+	for i, op := range env.Tx.Operations {
+		source := tx.Account
+		if op.SourceAccount != nil {
+			var err error
+			source, err = accountAddress(*op.SourceAccount)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		details, err := operationDetails(op, source)
+		if err != nil {
+			return nil, err
+		}
+
+		if i < len(results) && op.Body.Type == xdr.OperationTypePathPayment {
+			sent := pathPaymentSent(op.Body.MustPathPaymentOp(), results[i].MustTr().MustPathPaymentResult())
+			details["source_amount"] = detailAmount(sent)
+		}
+
+		raw, err := json.Marshal(details)
+		if err != nil {
+			return nil, errors.Wrap(err, 1)
+		}
+
+		id := ParseTotalOrderId(tx.Id)
+		id.OperationOrder = int32(i + 1)
+
+		result[i] = OperationRecord{
+			HistoryRecord:    HistoryRecord{Id: id.ToInt64()},
+			TransactionId:    tx.Id,
+			TransactionHash:  tx.TransactionHash,
+			ApplicationOrder: int32(i + 1),
+			Type:             op.Body.Type,
+			DetailsString:    sql.NullString{String: string(raw), Valid: true},
+			SourceAccount:    source,
+
+			TransactionSuccessful: tx.Successful,
+		}
+	}
+
+	return result, nil
+}
+
+// OperationParticipants returns the accounts op, whose source is source,
+// involves: its source and, for those operations that have one, the account
+// it acts upon.
+func OperationParticipants(op xdr.Operation, source string) ([]string, error) {
+	var other xdr.AccountId
+	switch op.Body.Type {
+	case xdr.OperationTypeCreateAccount:
+		other = op.Body.MustCreateAccountOp().Destination
+	case xdr.OperationTypePayment:
+		other = op.Body.MustPaymentOp().Destination
+	case xdr.OperationTypePathPayment:
+		other = op.Body.MustPathPaymentOp().Destination
+	case xdr.OperationTypeAllowTrust:
+		other = op.Body.MustAllowTrustOp().Trustor
+	case xdr.OperationTypeAccountMerge:
+		other = op.Body.MustDestination()
+	default:
+		return []string{source}, nil
+	}
+
+	address, err := accountAddress(other)
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{source, address}, nil
+}
+
+// operationDetails returns the details of op, whose source is source, in the
+// form history holds them.  Details only known once an operation succeeds,
+// such as the amount sent by a path payment, are omitted.
+func operationDetails(op xdr.Operation, source string) (map[string]interface{}, error) {
+	details := map[string]interface{}{}
+
+	var err error
+	address := func(aid xdr.AccountId) string {
+		var s string
+		if err == nil {
+			s, err = accountAddress(aid)
+		}
+		return s
+	}
+
+	asset := func(prefix string, a xdr.Asset) {
+		if err != nil {
+			return
+		}
+		err = setAssetDetails(details, prefix, a)
+	}
+
+	switch op.Body.Type {
+	case xdr.OperationTypeCreateAccount:
+		o := op.Body.MustCreateAccountOp()
+		details["funder"] = source
+		details["account"] = address(o.Destination)
+		details["starting_balance"] = detailAmount(o.StartingBalance)
+	case xdr.OperationTypePayment:
+		o := op.Body.MustPaymentOp()
+		details["from"] = source
+		details["to"] = address(o.Destination)
+		details["amount"] = detailAmount(o.Amount)
+		asset("", o.Asset)
+	case xdr.OperationTypePathPayment:
+		o := op.Body.MustPathPaymentOp()
+		details["from"] = source
+		details["to"] = address(o.Destination)
+		details["amount"] = detailAmount(o.DestAmount)
+		details["source_max"] = detailAmount(o.SendMax)
+		asset("", o.DestAsset)
+		asset("source_", o.SendAsset)
+
+		path := make([]map[string]interface{}, len(o.Path))
+		for i, a := range o.Path {
+			path[i] = map[string]interface{}{}
+			if err == nil {
+				err = setAssetDetails(path[i], "", a)
+			}
+		}
+		details["path"] = path
+	case xdr.OperationTypeManageOffer:
+		o := op.Body.MustManageOfferOp()
+		details["offer_id"] = o.OfferId
+		details["amount"] = detailAmount(o.Amount)
+		setPriceDetails(details, o.Price)
+		asset("buying_", o.Buying)
+		asset("selling_", o.Selling)
+	case xdr.OperationTypeCreatePassiveOffer:
+		o := op.Body.MustCreatePassiveOfferOp()
+		details["amount"] = detailAmount(o.Amount)
+		setPriceDetails(details, o.Price)
+		asset("buying_", o.Buying)
+		asset("selling_", o.Selling)
+	case xdr.OperationTypeSetOptions:
+		o := op.Body.MustSetOptionsOp()
+		if o.InflationDest != nil {
+			details["inflation_dest"] = address(*o.InflationDest)
+		}
+		if o.SetFlags != nil {
+			details["set_flags"], details["set_flags_s"] = flagDetails(int32(*o.SetFlags))
+		}
+		if o.ClearFlags != nil {
+			details["clear_flags"], details["clear_flags_s"] = flagDetails(int32(*o.ClearFlags))
+		}
+		if o.MasterWeight != nil {
+			details["master_key_weight"] = *o.MasterWeight
+		}
+		if o.LowThreshold != nil {
+			details["low_threshold"] = *o.LowThreshold
+		}
+		if o.MedThreshold != nil {
+			details["med_threshold"] = *o.MedThreshold
+		}
+		if o.HighThreshold != nil {
+			details["high_threshold"] = *o.HighThreshold
+		}
+		if o.HomeDomain != nil {
+			details["home_domain"] = *o.HomeDomain
+		}
+		if o.Signer != nil {
+			details["signer_key"] = address(o.Signer.PubKey)
+			details["signer_weight"] = o.Signer.Weight
+		}
+	case xdr.OperationTypeChangeTrust:
+		o := op.Body.MustChangeTrustOp()
+		details["trustor"] = source
+		details["limit"] = detailAmount(o.Limit)
+		asset("", o.Line)
+		if issuer, ok := details["asset_issuer"]; ok {
+			details["trustee"] = issuer
+		}
+	case xdr.OperationTypeAllowTrust:
+		o := op.Body.MustAllowTrustOp()
+		details["trustee"] = source
+		details["trustor"] = address(o.Trustor)
+		details["authorize"] = o.Authorize
+
+		var code []byte
+		switch o.Asset.Type {
+		case xdr.AssetTypeAssetTypeCreditAlphanum4:
+			c := o.Asset.MustAssetCode4()
+			code = c[:]
+		case xdr.AssetTypeAssetTypeCreditAlphanum12:
+			c := o.Asset.MustAssetCode12()
+			code = c[:]
+		}
+
+		t, terr := assets.String(o.Asset.Type)
+		if terr != nil && err == nil {
+			err = terr
+		}
+		details["asset_type"] = t
+		details["asset_code"] = strings.TrimRight(string(code), "\x00")
+		details["asset_issuer"] = source
+	case xdr.OperationTypeAccountMerge:
+		details["account"] = source
+		details["into"] = address(op.Body.MustDestination())
+	case xdr.OperationTypeInflation:
+		// no details
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return details, nil
+}
+
+// pathPaymentSent returns the amount of its send asset that the successful
+// path payment o, whose result is r, sent.  That is the amount delivered for
+// payments without a path, or else what the offers crossed bought of the send
+// asset.
+func pathPaymentSent(o xdr.PathPaymentOp, r xdr.PathPaymentResult) xdr.Int64 {
+	success := r.MustSuccess()
+	if len(success.Offers) == 0 {
+		return success.Last.Amount
+	}
+
+	var sent xdr.Int64
+	for _, atom := range success.Offers {
+		if sameAsset(atom.AssetBought, o.SendAsset) {
+			sent += atom.AmountBought
+		}
+	}
+
+	return sent
+}
+
+// sameAsset returns true if a and b are the same asset
+func sameAsset(a, b xdr.Asset) bool {
+	at, acode, aissuer, aerr := assetParts(a)
+	bt, bcode, bissuer, berr := assetParts(b)
+	return aerr == nil && berr == nil && at == bt && acode == bcode && aissuer == bissuer
+}
+
+// setAssetDetails sets the asset_type, asset_code and asset_issuer details of
+// a, each name following prefix.  The native asset has only a type.
+func setAssetDetails(details map[string]interface{}, prefix string, a xdr.Asset) error {
+	t, code, issuer, err := assetParts(a)
+	if err != nil {
+		return err
+	}
+
+	details[prefix+"asset_type"], err = assets.String(t)
+	if err != nil {
+		return err
+	}
+
+	if t != xdr.AssetTypeAssetTypeNative {
+		details[prefix+"asset_code"] = code
+		details[prefix+"asset_issuer"] = issuer
+	}
+
+	return nil
+}
+
+// setPriceDetails sets the price and price_r details of an offer
+func setPriceDetails(details map[string]interface{}, p xdr.Price) {
+	details["price_r"] = map[string]interface{}{"n": p.N, "d": p.D}
+	if p.D != 0 {
+		details["price"] = trimDecimal(big.NewRat(int64(p.N), int64(p.D)).FloatString(7))
+	}
+}
+
+// flagDetails returns the account flags set in flags, as values and names
+func flagDetails(flags int32) ([]int32, []string) {
+	values := []int32{}
+	names := []string{}
+	for _, f := range []struct {
+		value int32
+		name  string
+	}{
+		{FlagAuthRequired, "auth_required_flag"},
+		{FlagAuthRevocable, "auth_revocable_flag"},
+	} {
+		if flags&f.value != 0 {
+			values = append(values, f.value)
+			names = append(names, f.name)
+		}
+	}
+
+	return values, names
+}
+
+// detailAmount formats v like the amounts of operation details, keeping a
+// single decimal place for whole amounts.
+func detailAmount(v xdr.Int64) string {
+	return trimDecimal(amount.String(v))
+}
+
+// trimDecimal strips the trailing zeros of the decimal s, leaving at least
+// one decimal place.
+func trimDecimal(s string) string {
+	if !strings.Contains(s, ".") {
+		return s + ".0"
+	}
+
+	s = strings.TrimRight(s, "0")
+	if strings.HasSuffix(s, ".") {
+		s += "0"
+	}
+
+	return s
+}
+
+// memoParts returns the memo type and value of m, as history holds them
+func memoParts(m xdr.Memo) (string, sql.NullString) {
+	switch m.Type {
+	case xdr.MemoTypeMemoText:
+		return "text", sql.NullString{String: m.MustText(), Valid: true}
+	case xdr.MemoTypeMemoId:
+		return "id", sql.NullString{String: fmt.Sprintf("%d", m.MustId()), Valid: true}
+	case xdr.MemoTypeMemoHash:
+		h := m.MustHash()
+		return "hash", sql.NullString{String: base64.StdEncoding.EncodeToString(h[:]), Valid: true}
+	case xdr.MemoTypeMemoReturn:
+		h := m.MustRetHash()
+		return "return", sql.NullString{String: base64.StdEncoding.EncodeToString(h[:]), Valid: true}
+	default:
+		return "none", sql.NullString{}
+	}
+}
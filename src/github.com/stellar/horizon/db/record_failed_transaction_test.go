@@ -0,0 +1,136 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/go-stellar-base/strkey"
+	"github.com/stellar/go-stellar-base/xdr"
+)
+
+func TestFailedTransactions(t *testing.T) {
+	const (
+		source = "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H"
+		other  = "GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON"
+	)
+
+	aid := func(address string) xdr.AccountId {
+		var key xdr.Uint256
+		copy(key[:], strkey.MustDecode(strkey.VersionByteAccountID, address))
+		id, err := xdr.NewAccountId(xdr.CryptoKeyTypeKeyTypeEd25519, key)
+		So(err, ShouldBeNil)
+		return id
+	}
+
+	record := func(code xdr.TransactionResultCode, memo xdr.Memo, ops ...xdr.Operation) CoreTransactionRecord {
+		env, err := xdr.MarshalBase64(xdr.TransactionEnvelope{
+			Tx: xdr.Transaction{
+				SourceAccount: aid(source),
+				Fee:           200,
+				SeqNum:        7,
+				Memo:          memo,
+				Operations:    ops,
+			},
+		})
+		So(err, ShouldBeNil)
+
+		rr, err := xdr.NewTransactionResultResult(code, []xdr.OperationResult{})
+		So(err, ShouldBeNil)
+		res, err := xdr.MarshalBase64(xdr.TransactionResultPair{
+			Result: xdr.TransactionResult{FeeCharged: 200, Result: rr},
+		})
+		So(err, ShouldBeNil)
+
+		return CoreTransactionRecord{
+			TransactionHash: "abc",
+			LedgerSequence:  3,
+			Index:           2,
+			EnvelopeXDR:     env,
+			ResultXDR:       res,
+		}
+	}
+
+	Convey("failedTransaction", t, func() {
+		memo, err := xdr.NewMemo(xdr.MemoTypeMemoText, "hello")
+		So(err, ShouldBeNil)
+		closedAt := time.Unix(1444259248, 0).UTC()
+
+		Convey("converts failed transactions", func() {
+			body, err := xdr.NewOperationBody(xdr.OperationTypeCreateAccount, xdr.CreateAccountOp{
+				Destination:     aid(other),
+				StartingBalance: 1000000000,
+			})
+			So(err, ShouldBeNil)
+
+			tx, env, ok, err := failedTransaction(
+				record(xdr.TransactionResultCodeTxFailed, memo, xdr.Operation{Body: body}),
+				closedAt,
+			)
+			So(err, ShouldBeNil)
+			So(ok, ShouldBeTrue)
+			So(tx.Id, ShouldEqual, TotalOrderId{LedgerSequence: 3, TransactionOrder: 2}.ToInt64())
+			So(tx.Account, ShouldEqual, source)
+			So(tx.AccountSequence, ShouldEqual, 7)
+			So(tx.FeePaid, ShouldEqual, 200)
+			So(tx.OperationCount, ShouldEqual, 1)
+			So(tx.MemoType, ShouldEqual, "text")
+			So(tx.Memo.String, ShouldEqual, "hello")
+			So(tx.LedgerCloseTime, ShouldResemble, closedAt)
+			So(tx.Successful, ShouldBeFalse)
+
+			involved, err := transactionInvolves(tx, env, other)
+			So(err, ShouldBeNil)
+			So(involved, ShouldBeTrue)
+
+			ops, err := NewOperationRecords(tx, env, nil)
+			So(err, ShouldBeNil)
+			So(len(ops), ShouldEqual, 1)
+			So(ops[0].Id, ShouldEqual, TotalOrderId{LedgerSequence: 3, TransactionOrder: 2, OperationOrder: 1}.ToInt64())
+			So(ops[0].TransactionId, ShouldEqual, tx.Id)
+			So(ops[0].SourceAccount, ShouldEqual, source)
+			So(ops[0].TransactionSuccessful, ShouldBeFalse)
+
+			details, err := ops[0].Details()
+			So(err, ShouldBeNil)
+			So(details["funder"], ShouldEqual, source)
+			So(details["account"], ShouldEqual, other)
+			So(details["starting_balance"], ShouldEqual, "100.0")
+		})
+
+		Convey("skips successful transactions", func() {
+			_, _, ok, err := failedTransaction(record(xdr.TransactionResultCodeTxSuccess, memo), closedAt)
+			So(err, ShouldBeNil)
+			So(ok, ShouldBeFalse)
+		})
+	})
+
+	Convey("operationDetails", t, func() {
+		Convey("describes offers", func() {
+			var code [4]byte
+			copy(code[:], "USD")
+			usd, err := xdr.NewAsset(xdr.AssetTypeAssetTypeCreditAlphanum4, xdr.AssetAlphaNum4{AssetCode: code, Issuer: aid(other)})
+			So(err, ShouldBeNil)
+			native, err := xdr.NewAsset(xdr.AssetTypeAssetTypeNative, nil)
+			So(err, ShouldBeNil)
+
+			body, err := xdr.NewOperationBody(xdr.OperationTypeManageOffer, xdr.ManageOfferOp{
+				Selling: usd,
+				Buying:  native,
+				Amount:  12500000,
+				Price:   xdr.Price{N: 10, D: 9},
+			})
+			So(err, ShouldBeNil)
+
+			details, err := operationDetails(xdr.Operation{Body: body}, source)
+			So(err, ShouldBeNil)
+			So(details["amount"], ShouldEqual, "1.25")
+			So(details["price"], ShouldEqual, "1.1111111")
+			So(details["selling_asset_code"], ShouldEqual, "USD")
+			So(details["selling_asset_issuer"], ShouldEqual, other)
+			So(details["buying_asset_type"], ShouldEqual, "native")
+			_, ok := details["buying_asset_code"]
+			So(ok, ShouldBeFalse)
+		})
+	})
+}
@@ -21,4 +21,8 @@ type LedgerRecord struct {
 	ClosedAt           time.Time      `db:"closed_at"`
 	CreatedAt          time.Time      `db:"created_at"`
 	UpdatedAt          time.Time      `db:"updated_at"`
+
+	// OperationTypeCounts is nil for the ledgers ingested before history kept
+	// the counts
+	OperationTypeCounts OperationTypeCounts `db:"operation_type_counts"`
 }
@@ -16,7 +16,8 @@ var OperationRecordSelect sq.SelectBuilder = sq.
 		"hop.type, " +
 		"hop.details, " +
 		"hop.source_account, " +
-		"ht.transaction_hash").
+		"ht.transaction_hash, " +
+		"TRUE AS transaction_successful").
 	From("history_operations hop").
 	LeftJoin("history_transactions ht ON ht.id = hop.transaction_id")
 
@@ -28,6 +29,9 @@ type OperationRecord struct {
 	Type             xdr.OperationType `db:"type"`
 	DetailsString    sql.NullString    `db:"details"`
 	SourceAccount    string            `db:"source_account"`
+	// history holds only the operations of successful transactions; those of
+	// failed ones are loaded by a FailedOperationPageQuery
+	TransactionSuccessful bool `db:"transaction_successful"`
 }
 
 func (r OperationRecord) Details() (result map[string]interface{}, err error) {
@@ -0,0 +1,174 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-errors/errors"
+	"github.com/stellar/go-stellar-base/xdr"
+	"github.com/stellar/horizon/codes"
+	"github.com/stellar/horizon/strkeys"
+)
+
+// PendingPaymentRecord is a payment, or path payment, that failed because its
+// destination did not trust the asset delivered.  Such payments are found
+// amongst the failed transactions stellar-core keeps in its `txhistory` table.
+//
+// Id is the TotalOrderId the operation would have had, had it succeeded.
+type PendingPaymentRecord struct {
+	Id              int64
+	TransactionHash string
+	LedgerSequence  int32
+	Type            xdr.OperationType
+	From            string
+	To              string
+	AssetType       xdr.AssetType
+	AssetCode       string
+	AssetIssuer     string
+	Amount          int64
+	ResultCode      string
+}
+
+// PagingToken returns a suitable paging token for the PendingPaymentRecord
+func (r PendingPaymentRecord) PagingToken() string {
+	return fmt.Sprintf("%d", r.Id)
+}
+
+// pendingPayments returns the payments of tx to address that failed for want
+// of a trustline
+func pendingPayments(tx CoreTransactionRecord, address string) ([]PendingPaymentRecord, error) {
+	var trp xdr.TransactionResultPair
+	err := xdr.SafeUnmarshalBase64(tx.ResultXDR, &trp)
+	if err != nil {
+		return nil, errors.Wrap(err, 1)
+	}
+
+	results, ok := trp.Result.Result.GetResults()
+	if trp.Result.Result.Code != xdr.TransactionResultCodeTxFailed || !ok {
+		return nil, nil
+	}
+
+	var env xdr.TransactionEnvelope
+	err = xdr.SafeUnmarshalBase64(tx.EnvelopeXDR, &env)
+	if err != nil {
+		return nil, errors.Wrap(err, 1)
+	}
+
+	var found []PendingPaymentRecord
+	for i, op := range env.Tx.Operations {
+		if i >= len(results) {
+			break
+		}
+
+		tr, ok := results[i].GetTr()
+		if !ok {
+			continue
+		}
+
+		var (
+			dest   xdr.AccountId
+			asset  xdr.Asset
+			amount xdr.Int64
+			code   interface{}
+		)
+
+		switch op.Body.Type {
+		case xdr.OperationTypePayment:
+			r := tr.MustPaymentResult()
+			if r.Code != xdr.PaymentResultCodePaymentNoTrust {
+				continue
+			}
+			p := op.Body.MustPaymentOp()
+			dest, asset, amount, code = p.Destination, p.Asset, p.Amount, r.Code
+		case xdr.OperationTypePathPayment:
+			r := tr.MustPathPaymentResult()
+			if r.Code != xdr.PathPaymentResultCodePathPaymentNoTrust {
+				continue
+			}
+			p := op.Body.MustPathPaymentOp()
+			dest, asset, amount, code = p.Destination, p.DestAsset, p.DestAmount, r.Code
+		default:
+			continue
+		}
+
+		to, err := accountAddress(dest)
+		if err != nil {
+			return nil, err
+		}
+
+		if to != address {
+			continue
+		}
+
+		source := env.Tx.SourceAccount
+		if op.SourceAccount != nil {
+			source = *op.SourceAccount
+		}
+
+		from, err := accountAddress(source)
+		if err != nil {
+			return nil, err
+		}
+
+		record := PendingPaymentRecord{
+			Id: TotalOrderId{
+				LedgerSequence:   tx.LedgerSequence,
+				TransactionOrder: tx.Index,
+				OperationOrder:   int32(i + 1),
+			}.ToInt64(),
+			TransactionHash: tx.TransactionHash,
+			LedgerSequence:  tx.LedgerSequence,
+			Type:            op.Body.Type,
+			From:            from,
+			To:              to,
+			Amount:          int64(amount),
+		}
+
+		record.AssetType, record.AssetCode, record.AssetIssuer, err = assetParts(asset)
+		if err != nil {
+			return nil, err
+		}
+
+		record.ResultCode, err = codes.String(code)
+		if err != nil {
+			return nil, err
+		}
+
+		found = append(found, record)
+	}
+
+	return found, nil
+}
+
+// accountAddress returns the strkey address of aid
+func accountAddress(aid xdr.AccountId) (string, error) {
+	address, err := strkeys.Address(aid)
+	if err != nil {
+		return "", errors.Wrap(err, 1)
+	}
+
+	return address, nil
+}
+
+// assetParts returns the type, code and issuer of a.  The code and issuer of
+// the native asset are empty.
+func assetParts(a xdr.Asset) (t xdr.AssetType, code string, issuer string, err error) {
+	t = a.Type
+
+	var raw []byte
+	var aid xdr.AccountId
+	switch t {
+	case xdr.AssetTypeAssetTypeNative:
+		return
+	case xdr.AssetTypeAssetTypeCreditAlphanum4:
+		an := a.MustAlphaNum4()
+		raw, aid = an.AssetCode[:], an.Issuer
+	case xdr.AssetTypeAssetTypeCreditAlphanum12:
+		an := a.MustAlphaNum12()
+		raw, aid = an.AssetCode[:], an.Issuer
+	}
+
+	code = strings.TrimRight(string(raw), "\x00")
+	issuer, err = accountAddress(aid)
+	return
+}
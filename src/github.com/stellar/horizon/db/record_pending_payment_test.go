@@ -0,0 +1,115 @@
+package db
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/go-stellar-base/strkey"
+	"github.com/stellar/go-stellar-base/xdr"
+)
+
+func TestPendingPayments(t *testing.T) {
+	const (
+		sender    = "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H"
+		recipient = "GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON"
+		issuer    = "GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4"
+	)
+
+	aid := func(address string) xdr.AccountId {
+		var key xdr.Uint256
+		copy(key[:], strkey.MustDecode(strkey.VersionByteAccountID, address))
+		id, err := xdr.NewAccountId(xdr.CryptoKeyTypeKeyTypeEd25519, key)
+		So(err, ShouldBeNil)
+		return id
+	}
+
+	payment := func(to string) xdr.Operation {
+		var code [4]byte
+		copy(code[:], "USD")
+		asset, err := xdr.NewAsset(xdr.AssetTypeAssetTypeCreditAlphanum4, xdr.AssetAlphaNum4{
+			AssetCode: code,
+			Issuer:    aid(issuer),
+		})
+		So(err, ShouldBeNil)
+
+		body, err := xdr.NewOperationBody(xdr.OperationTypePayment, xdr.PaymentOp{
+			Destination: aid(to),
+			Asset:       asset,
+			Amount:      100000000,
+		})
+		So(err, ShouldBeNil)
+		return xdr.Operation{Body: body}
+	}
+
+	result := func(code xdr.PaymentResultCode) xdr.OperationResult {
+		pr, err := xdr.NewPaymentResult(code, nil)
+		So(err, ShouldBeNil)
+		tr, err := xdr.NewOperationResultTr(xdr.OperationTypePayment, pr)
+		So(err, ShouldBeNil)
+		r, err := xdr.NewOperationResult(xdr.OperationResultCodeOpInner, tr)
+		So(err, ShouldBeNil)
+		return r
+	}
+
+	record := func(code xdr.TransactionResultCode, ops []xdr.Operation, results []xdr.OperationResult) CoreTransactionRecord {
+		env, err := xdr.MarshalBase64(xdr.TransactionEnvelope{
+			Tx: xdr.Transaction{SourceAccount: aid(sender), Operations: ops},
+		})
+		So(err, ShouldBeNil)
+
+		rr, err := xdr.NewTransactionResultResult(code, results)
+		So(err, ShouldBeNil)
+		res, err := xdr.MarshalBase64(xdr.TransactionResultPair{
+			Result: xdr.TransactionResult{Result: rr},
+		})
+		So(err, ShouldBeNil)
+
+		return CoreTransactionRecord{
+			TransactionHash: "abc",
+			LedgerSequence:  3,
+			Index:           2,
+			EnvelopeXDR:     env,
+			ResultXDR:       res,
+		}
+	}
+
+	Convey("pendingPayments", t, func() {
+		Convey("finds payments to the address that failed for want of trust", func() {
+			tx := record(
+				xdr.TransactionResultCodeTxFailed,
+				[]xdr.Operation{payment(issuer), payment(recipient), payment(recipient)},
+				[]xdr.OperationResult{
+					result(xdr.PaymentResultCodePaymentNoTrust),
+					result(xdr.PaymentResultCodePaymentNoTrust),
+					result(xdr.PaymentResultCodePaymentUnderfunded),
+				},
+			)
+
+			found, err := pendingPayments(tx, recipient)
+			So(err, ShouldBeNil)
+			So(len(found), ShouldEqual, 1)
+
+			p := found[0]
+			So(p.Id, ShouldEqual, TotalOrderId{3, 2, 2}.ToInt64())
+			So(p.From, ShouldEqual, sender)
+			So(p.To, ShouldEqual, recipient)
+			So(p.AssetType, ShouldEqual, xdr.AssetTypeAssetTypeCreditAlphanum4)
+			So(p.AssetCode, ShouldEqual, "USD")
+			So(p.AssetIssuer, ShouldEqual, issuer)
+			So(p.Amount, ShouldEqual, 100000000)
+			So(p.ResultCode, ShouldEqual, "op_no_trust")
+		})
+
+		Convey("ignores successful transactions", func() {
+			tx := record(
+				xdr.TransactionResultCodeTxSuccess,
+				[]xdr.Operation{payment(recipient)},
+				[]xdr.OperationResult{result(xdr.PaymentResultCodePaymentSuccess)},
+			)
+
+			found, err := pendingPayments(tx, recipient)
+			So(err, ShouldBeNil)
+			So(found, ShouldBeEmpty)
+		})
+	})
+}
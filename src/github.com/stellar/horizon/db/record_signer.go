@@ -0,0 +1,58 @@
+package db
+
+import (
+	sq "github.com/lann/squirrel"
+	"github.com/stellar/go-stellar-base/xdr"
+)
+
+// SignerRecordSelect is a sql fragment to help select form queries that
+// select into a SignerRecord, leaving out removed signers.
+var SignerRecordSelect = sq.Select(
+	"si.accountid",
+	"si.publickey",
+	"si.weight",
+	"si.lastmodified",
+).From("current_signers si").Where("NOT si.removed")
+
+// SignerRecord is a row of the current_signers table that ingestion keeps:
+// the latest state ingested of a signer of an account, other than its master
+// key, as of the ledger Lastmodified.  As with current_trustlines, the rows of
+// removed signers are kept, marked removed.
+type SignerRecord struct {
+	Accountid    string `db:"accountid"`
+	Publickey    string `db:"publickey"`
+	Weight       int32  `db:"weight"`
+	Lastmodified int32  `db:"lastmodified"`
+}
+
+// NewSignerRecords returns the records of the signers of the account entry,
+// as changed by the ledger of sequence ledger.
+func NewSignerRecords(entry xdr.AccountEntry, ledger int32) ([]SignerRecord, error) {
+	account, err := accountAddress(entry.AccountId)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]SignerRecord, len(entry.Signers))
+	for i, s := range entry.Signers {
+		key, err := accountAddress(s.PubKey)
+		if err != nil {
+			return nil, err
+		}
+
+		result[i] = SignerRecord{
+			Accountid:    account,
+			Publickey:    key,
+			Weight:       int32(s.Weight),
+			Lastmodified: ledger,
+		}
+	}
+
+	return result, nil
+}
+
+// PagingToken returns a suitable paging token for the SignerRecord, as
+// ordered among the accounts its key signs for.
+func (r SignerRecord) PagingToken() string {
+	return r.Accountid
+}
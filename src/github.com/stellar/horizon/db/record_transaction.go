@@ -29,7 +29,8 @@ var TransactionRecordSelect sq.SelectBuilder = sq.
 		"ht.memo, " +
 		"lower(ht.time_bounds) AS valid_after, " +
 		"upper(ht.time_bounds) AS valid_before, " +
-		"hl.closed_at AS ledger_close_time").
+		"hl.closed_at AS ledger_close_time, " +
+		"TRUE AS successful").
 	From("history_transactions ht").
 	LeftJoin("history_ledgers hl ON ht.ledger_sequence = hl.sequence")
 
@@ -55,6 +56,9 @@ type TransactionRecord struct {
 	ValidBefore      sql.NullInt64  `db:"valid_before"`
 	CreatedAt        time.Time      `db:"created_at"`
 	UpdatedAt        time.Time      `db:"updated_at"`
+	// history holds only successful transactions; failed ones are loaded by
+	// a FailedTransactionPageQuery
+	Successful bool `db:"successful"`
 }
 
 func (r TransactionRecord) TableName() string {
@@ -0,0 +1,73 @@
+package db
+
+import (
+	"fmt"
+
+	sq "github.com/lann/squirrel"
+	"github.com/stellar/go-stellar-base/xdr"
+)
+
+// TrustlineRecordSelect is a sql fragment to help select form queries that
+// select into a TrustlineRecord, leaving out removed trustlines.
+var TrustlineRecordSelect = sq.Select(
+	"tl.accountid",
+	"tl.assettype",
+	"tl.issuer",
+	"tl.assetcode",
+	"tl.tlimit",
+	"tl.balance",
+	"tl.flags",
+	"tl.lastmodified",
+).From("current_trustlines tl").Where("NOT tl.removed")
+
+// TrustlineRecord is a row of the current_trustlines table that ingestion
+// keeps: the latest state ingested of a trustline, as of the ledger
+// Lastmodified.  The rows of removed trustlines are kept, marked removed, so
+// that ingesting older ledgers again cannot bring them back.
+type TrustlineRecord struct {
+	Accountid    string `db:"accountid"`
+	Assettype    int32  `db:"assettype"`
+	Issuer       string `db:"issuer"`
+	Assetcode    string `db:"assetcode"`
+	Tlimit       int64  `db:"tlimit"`
+	Balance      int64  `db:"balance"`
+	Flags        int32  `db:"flags"`
+	Lastmodified int32  `db:"lastmodified"`
+}
+
+// NewTrustlineRecord returns the record of the trustline entry, as changed by
+// the ledger of sequence ledger.
+func NewTrustlineRecord(entry xdr.TrustLineEntry, ledger int32) (TrustlineRecord, error) {
+	account, err := accountAddress(entry.AccountId)
+	if err != nil {
+		return TrustlineRecord{}, err
+	}
+
+	t, code, issuer, err := assetParts(entry.Asset)
+	if err != nil {
+		return TrustlineRecord{}, err
+	}
+
+	return TrustlineRecord{
+		Accountid:    account,
+		Assettype:    int32(t),
+		Issuer:       issuer,
+		Assetcode:    code,
+		Tlimit:       int64(entry.Limit),
+		Balance:      int64(entry.Balance),
+		Flags:        int32(entry.Flags),
+		Lastmodified: ledger,
+	}, nil
+}
+
+// PagingToken returns a suitable paging token for the TrustlineRecord, as
+// ordered among the holders of its asset.
+func (r TrustlineRecord) PagingToken() string {
+	return fmt.Sprintf("%d%s%s", r.Balance, AssetHolderCursorSep, r.Accountid)
+}
+
+// IsAuthorized returns true if the issuer of the asset authorizes the account
+// to hold it.
+func (r TrustlineRecord) IsAuthorized() bool {
+	return (xdr.TrustLineFlags(r.Flags) & xdr.TrustLineFlagsAuthorizedFlag) != 0
+}
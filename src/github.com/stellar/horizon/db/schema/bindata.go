@@ -0,0 +1,333 @@
+// Code generated by go-bindata.
+// sources:
+// migrations/1_current_trustlines.sql
+// migrations/2_paging_indexes.sql
+// migrations/3_api_keys.sql
+// migrations/4_ledger_operation_counts.sql
+// migrations/5_current_signers.sql
+// DO NOT EDIT!
+
+package schema
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+	"os"
+	"time"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+)
+
+func bindataRead(data []byte, name string) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewBuffer(data))
+	if err != nil {
+		return nil, fmt.Errorf("Read %q: %v", name, err)
+	}
+
+	var buf bytes.Buffer
+	_, err = io.Copy(&buf, gz)
+	clErr := gz.Close()
+
+	if err != nil {
+		return nil, fmt.Errorf("Read %q: %v", name, err)
+	}
+	if clErr != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+type asset struct {
+	bytes []byte
+	info  os.FileInfo
+}
+
+type bindataFileInfo struct {
+	name string
+	size int64
+	mode os.FileMode
+	modTime time.Time
+}
+
+func (fi bindataFileInfo) Name() string {
+	return fi.name
+}
+func (fi bindataFileInfo) Size() int64 {
+	return fi.size
+}
+func (fi bindataFileInfo) Mode() os.FileMode {
+	return fi.mode
+}
+func (fi bindataFileInfo) ModTime() time.Time {
+	return fi.modTime
+}
+func (fi bindataFileInfo) IsDir() bool {
+	return false
+}
+func (fi bindataFileInfo) Sys() interface{} {
+	return nil
+}
+
+var __1_current_trustlinesSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x8d\x52\x41\x6e\xc2\x30\x10\xbc\xfb\x15\x7b\x24\x6a\x38\xb4\x52\x7b\xe1\x94\x36\x46\x8d\x9a\x06\x14\x82\x0a\x27\xe4\x38\x4b\xb0\xe4\xd8\xc8\x76\xa8\xf8\x7d\xad\x80\x92\x82\x40\xad\x8f\x9e\xd1\xce\xec\xcc\x8e\xc7\xf0\xd0\x88\xda\x30\x87\xb0\xdc\x13\xf2\x96\xd3\xa8\xa0\x50\x44\xaf\x29\x85\x64\x0a\xd9\xac\x00\xba\x4a\x16\xc5\x02\x78\x6b\x0c\x2a\xb7\x71\xa6\xb5\x4e\x0a\x85\x16\x46\x04\xfc\x63\x9c\xeb\x56\x39\x51\x01\xdf\x31\xc3\xb8\x43\x03\x07\x66\x8e\x42\xd5\xa3\xe7\x97\xa0\x9b\x91\x2d\xd3\x34\x3c\xb1\xad\x45\xe7\x8e\x7b\x04\xa1\x1c\xd6\x9e\x7b\x89\x0b\x6b\x5b\xff\xf9\xef\x51\x5c\x57\x78\x83\xfd\xf8\x74\xcd\xf6\x9e\x1b\xe1\xa0\x14\xb5\x17\xbe\xc2\x4a\x26\x99\xe2\x78\x1b\xdc\x4a\x56\xdb\x3b\x6e\x25\xb3\xae\xd1\x95\xd8\x0a\xac\xee\x50\x0c\x36\xfa\xe0\xd1\x52\x6b\x89\x4c\xf5\x28\xc4\x74\x1a\x2d\xd3\x02\xb6\x4c\x5a\x3c\x71\xe7\x79\xf2\x19\xe5\x6b\xf8\xa0\x6b\x18\xf5\xb9\x86\xe7\x50\xc2\x61\xe3\x80\x04\x93\xbe\xad\x24\x8b\xe9\xea\xcf\xb6\x36\xe5\x71\xb3\xd3\xb2\xf2\xe9\x74\x5a\xb3\xec\x66\xa5\xbd\xc4\xa0\x7a\x4e\x27\x1c\x9a\x0e\xba\x09\x5f\xef\x34\xa7\x9d\xe8\x79\x47\x6f\x69\xfc\xeb\xa0\x62\xfd\xad\x08\x89\xf3\xd9\x7c\x38\xa8\xbb\xf6\x26\xe4\x07\xf2\x87\x78\x03\x8c\x02\x00\x00")
+
+func _1_current_trustlinesSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1_current_trustlinesSql,
+		"1_current_trustlines.sql",
+	)
+}
+
+func _1_current_trustlinesSql() (*asset, error) {
+	bytes, err := _1_current_trustlinesSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1_current_trustlines.sql", size: 652, mode: os.FileMode(420), modTime: time.Unix(1791962448, 0)}
+	a := &asset{bytes: bytes, info:  info}
+	return a, nil
+}
+
+var __2_paging_indexesSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x85\x90\xdf\x4e\xc3\x20\x1c\x85\xef\x79\x8a\x93\xdd\xa8\xb1\xdd\x0b\xf4\x4a\x5d\x4d\x76\xb3\x9a\x6d\x26\xbb\xeb\x68\xf9\xb5\x10\x3b\x68\x80\x3a\xeb\xd3\x0b\x36\x71\xea\xe2\x24\xe1\x82\x3f\xdf\x77\x0e\xa4\x29\xee\x07\xd5\x79\x48\xb2\x84\xa3\xf2\x52\x69\x78\x49\x38\xa8\xd6\x72\xaf\x8c\xbe\x72\xf0\x96\x6b\xc7\xeb\xb8\x4a\x50\x75\xa6\x7e\x51\xba\x45\x98\xe4\xe2\x1e\x8e\x52\x75\x14\xa9\x91\xa5\x29\xaa\xe0\x13\x73\xa0\xd0\xe8\xb8\x6d\x09\x82\x7b\x5e\x71\x47\x2e\x81\x1d\x34\xf6\xd2\x58\xf5\x1e\x30\x51\x05\x87\xa0\xb7\x3d\x1a\x65\x9d\x87\x37\x13\x1b\x4d\x87\x68\xaa\x8d\xae\x07\x6b\x49\xfb\x6e\x4c\xd0\x11\x7f\x8d\xb9\xa1\xa2\x3b\xd5\x83\x36\xb1\x73\x1b\x69\x61\xe6\x2c\x72\xb7\xd3\x29\xe1\xb9\x67\xec\x61\x9d\xdf\x6d\x73\x2c\x57\x8b\x7c\x87\xe5\x23\x56\xc5\x16\xf9\x6e\xb9\xd9\x6e\x20\x4d\x5f\x56\x63\xe9\xc7\x9e\x4a\xae\x45\xa9\x04\x43\x18\xc5\x0a\x21\xc2\x1b\x3b\x96\xa6\xa7\x29\xc6\xe1\x3a\x5e\x4b\xa0\xc4\x4d\x76\x59\x1a\xd0\x92\x7e\x78\x8d\x15\x64\x7f\xab\xa9\x69\xa8\xf6\x5f\xde\xb3\xc4\xd0\x26\xc1\xec\x13\x9d\xc5\xc8\xef\xef\x5a\x98\xa3\x66\x6c\xb1\x2e\x9e\x4e\x15\xfe\x89\xcf\xfe\xb8\x7e\xf6\x05\x19\xfb\x00\x69\xd4\x74\x94\x14\x02\x00\x00")
+
+func _2_paging_indexesSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__2_paging_indexesSql,
+		"2_paging_indexes.sql",
+	)
+}
+
+func _2_paging_indexesSql() (*asset, error) {
+	bytes, err := _2_paging_indexesSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "2_paging_indexes.sql", size: 532, mode: os.FileMode(420), modTime: time.Unix(1791962448, 0)}
+	a := &asset{bytes: bytes, info:  info}
+	return a, nil
+}
+
+var __3_api_keysSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x85\x91\x41\x6f\xda\x40\x10\x85\xef\xfe\x15\x4f\xbe\x18\x1a\xa0\x55\xd5\xe6\x92\x93\x5b\x1c\x05\x85\x00\x05\x5b\x6a\x14\x45\xd6\xc6\x1e\xec\x55\xe2\x5d\x77\x77\x81\xba\x55\xff\x7b\xc6\x36\xa2\xad\xa8\x54\x5f\x3c\xbb\x7a\xef\xcd\xcc\xb7\xe3\x31\xe2\x92\x20\x6a\x89\x67\x6a\x2c\x0a\xb9\x27\x05\xa7\x91\xbd\x48\x52\xce\x8e\x40\x22\x2b\x21\x73\x3e\xc8\xad\xa4\x1c\x4f\x0d\x1c\x3b\x6c\x29\xde\x7f\xbc\x44\x29\x6c\x09\xbd\x85\x74\xd6\x1b\x8f\xd9\xf8\x4c\x6a\x84\x92\xbe\x83\x54\xa6\x73\xca\x39\x60\x52\x4c\x8e\xa7\x41\x2e\x0b\xb2\x6e\x10\x74\xba\x60\x84\xa0\x8f\x09\x86\x5c\xb2\x29\x18\xe2\x20\x5d\xd9\x26\xd5\x45\x66\x9a\xda\xe9\x09\xb0\xc9\x74\x4d\x16\xc2\x10\x0e\x46\x3a\xc7\x03\x0a\x0b\xff\xe1\x2e\x8a\x6f\x96\xd3\x47\xac\xc2\xf8\xc6\x3f\xf6\xf1\x57\xcb\x4d\x8c\xb7\xce\x08\x65\x45\xe6\xa4\x56\xd6\x6f\xe3\xb4\x81\xff\xc6\xc7\x96\xff\xb4\x27\xd3\xf0\x40\x79\xad\xa5\x72\x9c\x7f\xdb\x2e\xde\xf6\xd5\x3b\x07\x81\x9a\x4c\xca\xa5\x49\x8d\x70\x94\xbe\xc8\x4a\xba\xae\x77\x57\x31\x00\xd1\x6d\x6a\xe8\xdb\x8e\x57\xb1\xa8\x44\x4e\x7f\xb8\x99\x62\xab\x9e\x78\xad\xe8\xa2\x92\x45\x9b\x82\xa4\xf6\xbc\xcf\xeb\x28\x8c\x23\xc4\xe1\xa7\x79\x84\x52\x1b\xf9\x43\xab\x94\xc1\xa7\x1d\xf8\x81\x07\xfe\x94\xa8\x08\x59\x29\x0c\xcf\x4e\x06\x7b\x61\x1a\xa9\x8a\xc1\xe5\x87\x21\x56\xeb\xd9\x5d\xb8\xbe\xc7\x6d\x74\x3f\xea\xb4\x1d\xc3\xf4\xf8\x0e\x27\x4f\xa7\x5d\x2c\x63\x2c\x92\xf9\x1c\xc9\x62\xf6\x25\x89\x7a\xfd\xbf\xf6\x62\x00\x54\x70\xa3\x93\x61\x1a\x5d\x87\xc9\x3c\xc6\xbb\xde\xf3\x5b\x9a\x3e\xed\x8c\xfd\xbf\xc1\xf6\x6f\x75\xb6\xc2\xc3\xe3\xb9\x25\xf8\xf9\x2b\xe8\x5d\x99\x21\x6e\x94\xa7\xc2\xc1\xc9\x8a\xb1\x8a\xaa\x3e\x31\x6d\x6f\xc0\xac\xe8\x3c\x41\xe9\xc3\x60\xe8\x0d\xaf\xfe\x86\x3d\xd5\x07\xe5\x79\xd3\xf5\x72\x75\x84\x3d\xbb\x46\xf4\x75\xb6\x89\x37\x67\xd8\xaf\xbc\x57\x5f\x5f\xcd\x89\x01\x03\x00\x00")
+
+func _3_api_keysSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__3_api_keysSql,
+		"3_api_keys.sql",
+	)
+}
+
+func _3_api_keysSql() (*asset, error) {
+	bytes, err := _3_api_keysSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "3_api_keys.sql", size: 769, mode: os.FileMode(420), modTime: time.Unix(1791963065, 0)}
+	a := &asset{bytes: bytes, info:  info}
+	return a, nil
+}
+
+var __4_ledger_operation_countsSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x7d\x90\xcb\x4a\xc3\x40\x14\x86\xf7\x79\x8a\x9f\x6e\x4d\x82\x0a\x6e\xec\x2a\x9a\x08\x85\xd8\x48\x9b\x82\xbb\x90\xcb\xc9\xa5\x4e\xe7\x84\xcc\x84\x12\xc4\x77\x77\x66\x04\xb5\x9b\xae\x86\x73\xfb\xbe\x73\x26\x08\x90\xf7\x84\x9a\x67\xa9\xc1\x2d\xb4\x09\x78\xa4\xa9\xd4\x03\x4b\x65\x33\x54\xd6\x3d\xf4\x32\x12\x4a\x08\x6a\x3a\x9a\xd0\xb3\x68\x94\x8f\x52\x99\xd4\x51\xb1\x04\x57\x47\xaa\xb5\x17\x04\xf8\xa0\x85\x1a\x54\x8b\x03\x0d\x52\x93\xed\xff\x05\x3a\x8e\x0f\x0a\xbb\x10\x9f\xab\xdb\xd5\x23\xee\x7d\xac\xee\xcc\xfb\xf0\x15\x02\xa9\xc3\x2b\xcb\x19\x64\x47\x4a\x5b\x14\xb5\x3c\x91\xc3\xd5\x2c\xe6\x93\xc4\xd9\x7a\x9b\xc6\xd4\x4a\x53\x10\xd4\x6a\xc8\x59\x88\xd0\xb3\x73\x37\xa7\xa1\x33\x2e\xc2\x61\xf4\xbc\x28\xcd\x93\x1d\xf2\xe8\x29\x4d\xd0\x0f\x4a\xf3\xb4\x14\x3f\x27\x28\x44\x71\x8c\xe7\x2c\x3d\xbc\x6e\xb1\x79\xc1\x36\xcb\x91\xbc\x6f\xf6\xf9\xfe\x6f\xd9\xc2\x2e\x5b\xb8\x9f\x51\xee\xcc\x6a\x7d\xa9\x88\xf9\x2c\xaf\x4b\xe2\x5d\xf6\xf6\xcf\x72\xcd\xb0\xf6\xbe\x01\x86\x81\x93\xf0\x8b\x01\x00\x00")
+
+func _4_ledger_operation_countsSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__4_ledger_operation_countsSql,
+		"4_ledger_operation_counts.sql",
+	)
+}
+
+func _4_ledger_operation_countsSql() (*asset, error) {
+	bytes, err := _4_ledger_operation_countsSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "4_ledger_operation_counts.sql", size: 395, mode: os.FileMode(420), modTime: time.Unix(1791970000, 0)}
+	a := &asset{bytes: bytes, info:  info}
+	return a, nil
+}
+
+var __5_current_signersSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x02\xff\x8d\x91\x4f\x4f\xc3\x30\x0c\xc5\xef\xf9\x14\x3e\x6e\xa2\xdd\x0d\x2e\x3b\x0d\x16\x44\xc5\xd8\x50\xe9\xc4\x76\x9a\xb2\xd6\x6b\x23\xda\x18\x25\x29\x65\xdf\x1e\x37\xec\x8f\x26\x10\xa2\x97\xd6\x7e\x2f\xbf\xda\x2f\x71\x0c\x59\x85\xe0\x74\x69\xd0\x3a\xa0\x1d\x78\x2e\x55\x9e\x53\x6b\xfc\xa9\x36\xe8\x3b\xb2\x6f\x11\x10\x57\x96\x5b\xca\xf4\x7d\x6d\xa1\x51\xce\x73\xe7\x0d\xf7\x2e\x12\x71\x0c\xda\x14\xf8\x89\x05\x6c\xf7\x07\x66\x2f\x81\xa3\xfe\x8c\xbf\x64\xab\x20\xe5\x8c\xea\x9d\xb0\x23\x0b\xca\x22\xbf\x5b\x53\xf4\xa8\x4e\xfb\x8a\x5a\x0f\x8e\x2d\x46\x9b\x12\xf8\x4f\x75\xad\x6c\x9c\x93\xc5\x11\x40\x62\x4a\x74\x5e\x13\x9f\x47\x2c\x5c\x80\x7b\xb5\xad\x19\x61\xa9\xb9\x70\x07\x5c\xa5\x59\xd2\x1e\x2a\xaa\xd9\x6d\x08\x2c\x75\x6e\x24\x7a\xed\xaa\xd1\xa5\x55\x1e\x61\xf9\x2e\xc4\x5d\x2a\x27\x99\x84\x6c\x72\x3b\x93\x90\xdc\xc3\x7c\x91\x81\x5c\x25\x2f\xd9\x0b\xe4\xad\xb5\x68\xfc\xe6\x18\xd7\x40\x00\x3f\x87\x8d\x74\x01\x79\xa5\xac\xca\xfb\x40\x3e\x94\xdd\xf3\xcc\x83\xeb\x9b\x61\x00\xcc\x97\xb3\x59\x14\xdc\xef\xed\xb6\xd6\x79\x58\xfd\x1f\xee\x0e\x75\x59\x79\x8e\xd5\x63\xc9\xc6\x4b\xb1\xe6\xf0\x1b\x2a\xf4\x4e\x73\xe2\xbf\x5b\x2c\x36\xf4\xd1\xdf\x07\x51\x8d\x1c\xf5\x51\x85\xa9\xbc\x9f\x2c\x67\x19\xec\x54\xed\xf0\xdb\xfb\x9c\x26\x4f\x93\x74\x0d\x8f\x72\x0d\x83\xd3\x52\xd1\x79\xe2\xa1\x18\x8e\x4f\xf9\x24\xf3\xa9\x5c\xfd\x9d\xcf\x66\xbb\x3f\x7c\x06\xfe\x62\xfe\x33\xc0\x13\x3b\x3a\xc7\x38\x0c\xee\xd7\x07\x99\xca\x00\x3f\xec\x30\xbe\xbc\xaa\x29\x75\x46\x88\x69\xba\x78\x3e\x5f\xd5\xef\x63\x8c\xc5\x17\x41\x8c\x21\xaf\xe6\x02\x00\x00")
+
+func _5_current_signersSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__5_current_signersSql,
+		"5_current_signers.sql",
+	)
+}
+
+func _5_current_signersSql() (*asset, error) {
+	bytes, err := _5_current_signersSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "5_current_signers.sql", size: 742, mode: os.FileMode(420), modTime: time.Unix(1791980000, 0)}
+	a := &asset{bytes: bytes, info:  info}
+	return a, nil
+}
+
+// Asset loads and returns the asset for the given name.
+// It returns an error if the asset could not be found or
+// could not be loaded.
+func Asset(name string) ([]byte, error) {
+	cannonicalName := strings.Replace(name, "\\", "/", -1)
+	if f, ok := _bindata[cannonicalName]; ok {
+		a, err := f()
+		if err != nil {
+			return nil, fmt.Errorf("Asset %s can't read by error: %v", name, err)
+		}
+		return a.bytes, nil
+	}
+	return nil, fmt.Errorf("Asset %s not found", name)
+}
+
+// MustAsset is like Asset but panics when Asset would return an error.
+// It simplifies safe initialization of global variables.
+func MustAsset(name string) []byte {
+	a, err := Asset(name)
+	if (err != nil) {
+		panic("asset: Asset(" + name + "): " + err.Error())
+	}
+
+	return a
+}
+
+// AssetInfo loads and returns the asset info for the given name.
+// It returns an error if the asset could not be found or
+// could not be loaded.
+func AssetInfo(name string) (os.FileInfo, error) {
+	cannonicalName := strings.Replace(name, "\\", "/", -1)
+	if f, ok := _bindata[cannonicalName]; ok {
+		a, err := f()
+		if err != nil {
+			return nil, fmt.Errorf("AssetInfo %s can't read by error: %v", name, err)
+		}
+		return a.info, nil
+	}
+	return nil, fmt.Errorf("AssetInfo %s not found", name)
+}
+
+// AssetNames returns the names of the assets.
+func AssetNames() []string {
+	names := make([]string, 0, len(_bindata))
+	for name := range _bindata {
+		names = append(names, name)
+	}
+	return names
+}
+
+// _bindata is a table, holding each asset generator, mapped to its name.
+var _bindata = map[string]func() (*asset, error){
+	"1_current_trustlines.sql": _1_current_trustlinesSql,
+	"2_paging_indexes.sql": _2_paging_indexesSql,
+	"3_api_keys.sql": _3_api_keysSql,
+	"4_ledger_operation_counts.sql": _4_ledger_operation_countsSql,
+	"5_current_signers.sql": _5_current_signersSql,
+}
+
+// AssetDir returns the file names below a certain
+// directory embedded in the file by go-bindata.
+// For example if you run go-bindata on data/... and data contains the
+// following hierarchy:
+//     data/
+//       foo.txt
+//       img/
+//         a.png
+//         b.png
+// then AssetDir("data") would return []string{"foo.txt", "img"}
+// AssetDir("data/img") would return []string{"a.png", "b.png"}
+// AssetDir("foo.txt") and AssetDir("notexist") would return an error
+// AssetDir("") will return []string{"data"}.
+func AssetDir(name string) ([]string, error) {
+	node := _bintree
+	if len(name) != 0 {
+		cannonicalName := strings.Replace(name, "\\", "/", -1)
+		pathList := strings.Split(cannonicalName, "/")
+		for _, p := range pathList {
+			node = node.Children[p]
+			if node == nil {
+				return nil, fmt.Errorf("Asset %s not found", name)
+			}
+		}
+	}
+	if node.Func != nil {
+		return nil, fmt.Errorf("Asset %s not found", name)
+	}
+	rv := make([]string, 0, len(node.Children))
+	for childName := range node.Children {
+		rv = append(rv, childName)
+	}
+	return rv, nil
+}
+
+type bintree struct {
+	Func func() (*asset, error)
+	Children map[string]*bintree
+}
+var _bintree = &bintree{nil, map[string]*bintree{
+	"1_current_trustlines.sql": &bintree{_1_current_trustlinesSql, map[string]*bintree{
+	}},
+	"2_paging_indexes.sql": &bintree{_2_paging_indexesSql, map[string]*bintree{
+	}},
+	"3_api_keys.sql": &bintree{_3_api_keysSql, map[string]*bintree{
+	}},
+	"4_ledger_operation_counts.sql": &bintree{_4_ledger_operation_countsSql, map[string]*bintree{
+	}},
+	"5_current_signers.sql": &bintree{_5_current_signersSql, map[string]*bintree{
+	}},
+}}
+
+// RestoreAsset restores an asset under the given directory
+func RestoreAsset(dir, name string) error {
+        data, err := Asset(name)
+        if err != nil {
+                return err
+        }
+        info, err := AssetInfo(name)
+        if err != nil {
+                return err
+        }
+        err = os.MkdirAll(_filePath(dir, path.Dir(name)), os.FileMode(0755))
+        if err != nil {
+                return err
+        }
+        err = ioutil.WriteFile(_filePath(dir, name), data, info.Mode())
+        if err != nil {
+                return err
+        }
+        err = os.Chtimes(_filePath(dir, name), info.ModTime(), info.ModTime())
+        if err != nil {
+                return err
+        }
+        return nil
+}
+
+// RestoreAssets restores an asset under the given directory recursively
+func RestoreAssets(dir, name string) error {
+        children, err := AssetDir(name)
+        // File
+        if err != nil {
+                return RestoreAsset(dir, name)
+        }
+        // Dir
+        for _, child := range children {
+                err = RestoreAssets(dir, path.Join(name, child))
+                if err != nil {
+                        return err
+                }
+        }
+        return nil
+}
+
+func _filePath(dir, name string) string {
+        cannonicalName := strings.Replace(name, "\\", "/", -1)
+        return filepath.Join(append([]string{dir}, strings.Split(cannonicalName, "/")...)...)
+}
+
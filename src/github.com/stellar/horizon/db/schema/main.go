@@ -0,0 +1,352 @@
+// Package schema migrates the schema horizon keeps within its history
+// database, beyond that of horizon-importer, using the migrations embedded in
+// the horizon binary.
+//
+// Each migration is a file of the migrations directory named for its version
+// and what it does, such as 1_current_trustlines.sql, holding the statements
+// that apply it following a "-- +migrate Up" line and those that revert it
+// following a "-- +migrate Down" line.  Versions start at 1 and leave no gaps.
+// After adding or changing a migration, run `go generate` to embed it.
+package schema
+
+import (
+	stderr "errors"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-errors/errors"
+	"github.com/jmoiron/sqlx"
+)
+
+//go:generate go get github.com/jteeuwen/go-bindata/go-bindata
+//go:generate go-bindata -pkg schema -prefix migrations/ migrations
+
+// VersionTable is the table recording the version of the schema, kept apart
+// from the schema_migrations table of horizon-importer.  It holds a single
+// row.
+const VersionTable = "horizon_schema_version"
+
+// ErrDirty is returned when a migration was interrupted before it completed,
+// as when horizon died running it, leaving the schema in a state no version
+// describes.  The schema must be repaired by hand, and the dirty flag of
+// VersionTable cleared, before it is migrated again.
+// NOTE: this is not a go-errors based error, as stack traces are unnecessary
+var ErrDirty = stderr.New("schema is dirty: a migration was interrupted, repair it by hand")
+
+// ErrConcurrentMigration is returned when the schema was migrated by another
+// process while a migration was under way.
+// NOTE: this is not a go-errors based error, as stack traces are unnecessary
+var ErrConcurrentMigration = stderr.New("schema was migrated concurrently")
+
+// Direction is the way a migration is run
+type Direction int
+
+const (
+	// Up applies migrations, oldest first
+	Up Direction = iota
+	// Down reverts migrations, newest first
+	Down
+)
+
+// Migration is a change of the schema, from the version before it to its own
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status is the state of the schema of a database
+type Status struct {
+	// Version is that of the last migration applied, or, when Dirty, that of
+	// the migration interrupted.
+	Version int
+
+	// Dirty is true when the migration of Version was interrupted
+	Dirty bool
+
+	// Latest is the version of the newest migration of this binary, the one
+	// it expects the schema to be at.
+	Latest int
+}
+
+// Current returns true if the schema is at the version this binary expects
+func (s Status) Current() bool {
+	return !s.Dirty && s.Version == s.Latest
+}
+
+var migrations = mustLoad()
+
+// Migrations returns the migrations embedded in the binary, oldest first
+func Migrations() []Migration {
+	return append([]Migration(nil), migrations...)
+}
+
+// Latest returns the version of the newest migration, that the binary expects
+// the schema to be at.
+func Latest() int {
+	return len(migrations)
+}
+
+// GetStatus returns the status of the schema of d.  A database never migrated
+// is at version 0.
+func GetStatus(d *sqlx.DB) (Status, error) {
+	result := Status{Latest: Latest()}
+
+	var found int
+	err := d.Get(&found, `
+		SELECT COUNT(*) FROM information_schema.tables
+		WHERE table_schema = current_schema() AND table_name = $1`, VersionTable)
+	if err != nil {
+		return result, errors.Wrap(err, 1)
+	}
+
+	if found == 0 {
+		return result, nil
+	}
+
+	err = d.QueryRowx(fmt.Sprintf("SELECT version, dirty FROM %s", VersionTable)).
+		Scan(&result.Version, &result.Dirty)
+	if err != nil {
+		return result, errors.Wrap(err, 1)
+	}
+
+	return result, nil
+}
+
+// Check returns an error unless the schema of d is at the version this binary
+// expects, telling how to set it right.
+func Check(d *sqlx.DB) error {
+	s, err := GetStatus(d)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case s.Dirty:
+		return ErrDirty
+	case s.Version < s.Latest:
+		return errors.Errorf("schema is at version %d, behind the %d horizon expects: run `horizon db migrate up`", s.Version, s.Latest)
+	case s.Version > s.Latest:
+		return errors.Errorf("schema is at version %d, ahead of the %d horizon expects: upgrade horizon", s.Version, s.Latest)
+	}
+
+	return nil
+}
+
+// Migrate runs up to max migrations of d in direction dir, all those left to
+// run when max is 0, returning the count run.  Each migration is run within
+// its own transaction.  The schema is marked dirty while it runs, the mark
+// cleared once it commits or rolls back, so that one interrupted midway is
+// detected.
+func Migrate(d *sqlx.DB, dir Direction, max int) (int, error) {
+	err := createVersionTable(d)
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for max == 0 || n < max {
+		s, err := GetStatus(d)
+		if err != nil {
+			return n, err
+		}
+		if s.Dirty {
+			return n, ErrDirty
+		}
+
+		var m Migration
+		var statements string
+		var to int
+
+		switch {
+		case dir == Up && s.Version < s.Latest:
+			m = migrations[s.Version]
+			statements, to = m.Up, m.Version
+		case dir == Down && s.Version > 0 && s.Version <= s.Latest:
+			m = migrations[s.Version-1]
+			statements, to = m.Down, m.Version-1
+		case dir == Down && s.Version > s.Latest:
+			return n, errors.Errorf("schema is at version %d, ahead of the migrations of this horizon", s.Version)
+		default:
+			return n, nil
+		}
+
+		err = run(d, s.Version, m.Version, to, statements)
+		if err != nil {
+			return n, errors.Errorf("migration %d_%s: %s", m.Version, m.Name, err)
+		}
+
+		n++
+	}
+
+	return n, nil
+}
+
+// Redo reverts the newest migration applied to d and applies it again
+func Redo(d *sqlx.DB) error {
+	n, err := Migrate(d, Down, 1)
+	if err != nil || n == 0 {
+		return err
+	}
+
+	_, err = Migrate(d, Up, 1)
+	return err
+}
+
+// run runs statements, the migration of version taking the schema from the
+// version from to the version to.
+func run(d *sqlx.DB, from, version, to int, statements string) error {
+	marked, err := d.Exec(fmt.Sprintf(
+		"UPDATE %s SET version = $1, dirty = true WHERE version = $2 AND NOT dirty", VersionTable,
+	), version, from)
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	rows, err := marked.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+	if rows != 1 {
+		return ErrConcurrentMigration
+	}
+
+	err = apply(d, statements, to)
+	if err == nil {
+		return nil
+	}
+
+	// the transaction rolled back, leaving the schema as it was
+	_, clearErr := d.Exec(fmt.Sprintf("UPDATE %s SET version = $1, dirty = false", VersionTable), from)
+	if clearErr != nil {
+		return errors.Wrap(clearErr, 1)
+	}
+
+	return err
+}
+
+// apply runs statements and records the version to within one transaction of
+// d, rolled back should either fail.
+func apply(d *sqlx.DB, statements string, to int) error {
+	tx, err := d.Beginx()
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	_, err = tx.Exec(statements)
+	if err == nil {
+		_, err = tx.Exec(fmt.Sprintf("UPDATE %s SET version = $1, dirty = false", VersionTable), to)
+	}
+	if err != nil {
+		tx.Rollback()
+		return errors.Wrap(err, 1)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	return nil
+}
+
+// createVersionTable creates VersionTable, at version 0, unless it exists
+func createVersionTable(d *sqlx.DB) error {
+	_, err := d.Exec(fmt.Sprintf(`
+		CREATE TABLE IF NOT EXISTS %[1]s (
+			version integer NOT NULL,
+			dirty boolean NOT NULL
+		);
+		INSERT INTO %[1]s (version, dirty)
+			SELECT 0, false WHERE NOT EXISTS (SELECT 1 FROM %[1]s);
+	`, VersionTable))
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	return nil
+}
+
+// mustLoad parses the embedded migrations, panicking when they are malformed
+func mustLoad() []Migration {
+	result, err := load(AssetNames(), MustAsset)
+	if err != nil {
+		panic(err)
+	}
+
+	return result
+}
+
+// load parses the migration files names, whose contents are read with read
+func load(names []string, read func(string) []byte) ([]Migration, error) {
+	var result []Migration
+
+	for _, name := range names {
+		base := strings.TrimSuffix(path.Base(name), ".sql")
+		parts := strings.SplitN(base, "_", 2)
+		if len(parts) != 2 || base == path.Base(name) {
+			return nil, errors.Errorf("migration %s: expected a name such as 1_what_it_does.sql", name)
+		}
+
+		version, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return nil, errors.Errorf("migration %s: invalid version %q", name, parts[0])
+		}
+
+		up, down, err := parse(string(read(name)))
+		if err != nil {
+			return nil, errors.Errorf("migration %s: %s", name, err)
+		}
+
+		result = append(result, Migration{
+			Version: version,
+			Name:    parts[1],
+			Up:      up,
+			Down:    down,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version < result[j].Version
+	})
+
+	for i, m := range result {
+		if m.Version != i+1 {
+			return nil, errors.Errorf("migration %d_%s: expected version %d", m.Version, m.Name, i+1)
+		}
+	}
+
+	return result, nil
+}
+
+// parse splits the contents of a migration file into the statements that
+// apply it and those that revert it.
+func parse(contents string) (up string, down string, err error) {
+	var section *string
+	sections := map[string]*string{
+		"-- +migrate Up":   &up,
+		"-- +migrate Down": &down,
+	}
+
+	for _, line := range strings.SplitAfter(contents, "\n") {
+		if s, ok := sections[strings.TrimSpace(line)]; ok {
+			section = s
+			continue
+		}
+
+		if section != nil {
+			*section += line
+		}
+	}
+
+	up, down = strings.TrimSpace(up), strings.TrimSpace(down)
+	if up == "" || down == "" {
+		return "", "", errors.New("expected both -- +migrate Up and -- +migrate Down statements")
+	}
+
+	return up, down, nil
+}
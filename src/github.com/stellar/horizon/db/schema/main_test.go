@@ -0,0 +1,50 @@
+package schema
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestLoad(t *testing.T) {
+	Convey("load", t, func() {
+		files := map[string]string{
+			"2_second.sql": "-- +migrate Up\nCREATE TABLE b ();\n-- +migrate Down\nDROP TABLE b;\n",
+			"1_first.sql":  "-- what it does\n\n-- +migrate Up\nCREATE TABLE a ();\n\n-- +migrate Down\nDROP TABLE a;\n",
+		}
+		read := func(name string) []byte { return []byte(files[name]) }
+
+		Convey("orders the migrations by version", func() {
+			result, err := load([]string{"2_second.sql", "1_first.sql"}, read)
+			So(err, ShouldBeNil)
+			So(len(result), ShouldEqual, 2)
+			So(result[0], ShouldResemble, Migration{1, "first", "CREATE TABLE a ();", "DROP TABLE a;"})
+			So(result[1].Name, ShouldEqual, "second")
+		})
+
+		Convey("rejects gaps between versions", func() {
+			_, err := load([]string{"2_second.sql"}, read)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("rejects malformed names", func() {
+			files["first.sql"] = files["1_first.sql"]
+			_, err := load([]string{"first.sql"}, read)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("rejects migrations that cannot be reverted", func() {
+			files["1_first.sql"] = "-- +migrate Up\nCREATE TABLE a ();\n"
+			_, err := load([]string{"1_first.sql"}, read)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("parses the embedded migrations", func() {
+			So(Latest(), ShouldEqual, len(AssetNames()))
+			for _, m := range Migrations() {
+				So(m.Up, ShouldNotBeEmpty)
+				So(m.Down, ShouldNotBeEmpty)
+			}
+		})
+	})
+}
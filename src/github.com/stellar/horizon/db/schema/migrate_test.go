@@ -0,0 +1,89 @@
+package schema_test
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	. "github.com/stellar/horizon/db/schema"
+	"github.com/stellar/horizon/test"
+)
+
+func TestMigrate(t *testing.T) {
+	history := test.OpenDatabase(test.DatabaseUrl())
+	defer history.Close()
+
+	Convey("Migrate", t, func() {
+		// scenarios are loaded migrated, to the latest version
+		test.LoadScenario("base")
+		_, err := Migrate(history, Down, 0)
+		So(err, ShouldBeNil)
+		Reset(func() {
+			history.MustExec("DROP TABLE IF EXISTS " + VersionTable)
+			history.MustExec("DROP TABLE IF EXISTS current_trustlines")
+			history.MustExec("DROP TABLE IF EXISTS current_signers")
+			history.MustExec("DROP TABLE IF EXISTS horizon_api_keys")
+			history.MustExec("ALTER TABLE history_ledgers DROP COLUMN IF EXISTS operation_type_counts")
+			history.MustExec("DROP INDEX IF EXISTS hop_by_type_and_id")
+			history.MustExec("DROP INDEX IF EXISTS hist_e_by_type_and_order")
+		})
+
+		status, err := GetStatus(history)
+		So(err, ShouldBeNil)
+		So(status, ShouldResemble, Status{Version: 0, Latest: Latest()})
+		So(Check(history), ShouldNotBeNil)
+
+		n, err := Migrate(history, Up, 0)
+		So(err, ShouldBeNil)
+		So(n, ShouldEqual, Latest())
+		So(Check(history), ShouldBeNil)
+
+		Convey("applies nothing when current", func() {
+			n, err := Migrate(history, Up, 0)
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, 0)
+		})
+
+		Convey("reverts and reapplies migrations", func() {
+			n, err := Migrate(history, Down, 1)
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, 1)
+
+			status, err := GetStatus(history)
+			So(err, ShouldBeNil)
+			So(status.Version, ShouldEqual, Latest()-1)
+			So(Check(history), ShouldNotBeNil)
+
+			So(Redo(history), ShouldBeNil)
+			status, err = GetStatus(history)
+			So(err, ShouldBeNil)
+			So(status.Version, ShouldEqual, Latest()-1)
+
+			n, err = Migrate(history, Down, 0)
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, Latest()-1)
+
+			var found int
+			err = history.Get(&found, `
+				SELECT COUNT(*) FROM information_schema.tables
+				WHERE table_name = 'current_trustlines'`)
+			So(err, ShouldBeNil)
+			So(found, ShouldEqual, 0)
+		})
+
+		Convey("refuses a dirty schema", func() {
+			history.MustExec("UPDATE " + VersionTable + " SET dirty = true")
+
+			So(Check(history), ShouldEqual, ErrDirty)
+			_, err := Migrate(history, Down, 1)
+			So(err, ShouldEqual, ErrDirty)
+		})
+
+		Convey("refuses a schema ahead of the binary", func() {
+			history.MustExec("UPDATE "+VersionTable+" SET version = $1", Latest()+1)
+
+			So(Check(history), ShouldNotBeNil)
+			_, err := Migrate(history, Down, 1)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
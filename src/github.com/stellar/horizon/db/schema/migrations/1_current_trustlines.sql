@@ -0,0 +1,22 @@
+-- +migrate Up
+
+CREATE TABLE IF NOT EXISTS current_trustlines (
+    accountid character varying(56) NOT NULL,
+    assettype integer NOT NULL,
+    issuer character varying(56) NOT NULL,
+    assetcode character varying(12) NOT NULL,
+    tlimit bigint NOT NULL,
+    balance bigint NOT NULL,
+    flags integer NOT NULL,
+    lastmodified integer NOT NULL,
+    removed boolean NOT NULL DEFAULT false,
+    PRIMARY KEY (accountid, issuer, assetcode)
+);
+
+CREATE INDEX IF NOT EXISTS current_trustlines_by_holding
+    ON current_trustlines (assetcode, issuer, balance, accountid)
+    WHERE NOT removed;
+
+-- +migrate Down
+
+DROP TABLE IF EXISTS current_trustlines;
@@ -0,0 +1,16 @@
+-- Built here within the migration's transaction, blocking ingestion while they
+-- build.  On large databases, run `horizon db index` first to build them
+-- concurrently, leaving this migration nothing to do.
+
+-- +migrate Up
+
+CREATE INDEX IF NOT EXISTS hop_by_type_and_id
+    ON history_operations (type, id);
+
+CREATE INDEX IF NOT EXISTS hist_e_by_type_and_order
+    ON history_effects (type, history_operation_id, "order");
+
+-- +migrate Down
+
+DROP INDEX IF EXISTS hist_e_by_type_and_order;
+DROP INDEX IF EXISTS hop_by_type_and_id;
@@ -0,0 +1,20 @@
+-- The api keys given to clients, each identified by the sha256 hash of its
+-- token, hex encoded, e.g. encode(digest('token', 'sha256'), 'hex') with
+-- pgcrypto.  Scopes are written as "[METHOD] PATH", e.g. "POST /transactions"
+-- or "*" for every endpoint.  Keys without a per_hour_rate_limit are limited as
+-- requests made without a key are.
+
+-- +migrate Up
+
+CREATE TABLE horizon_api_keys (
+    name character varying(64) PRIMARY KEY,
+    token_sha256 character(64) NOT NULL UNIQUE,
+    per_hour_rate_limit integer NOT NULL DEFAULT 0,
+    rate_limit_burst integer NOT NULL DEFAULT 0,
+    scopes character varying[] NOT NULL DEFAULT '{}',
+    created_at timestamp without time zone NOT NULL DEFAULT now()
+);
+
+-- +migrate Down
+
+DROP TABLE IF EXISTS horizon_api_keys;
@@ -0,0 +1,11 @@
+-- The count of the operations of each type a ledger holds, as a json object
+-- keyed by the integer operation type, e.g. {"0": 2, "1": 5}.  Ledgers
+-- ingested before the column was added are left null.
+
+-- +migrate Up
+
+ALTER TABLE history_ledgers ADD COLUMN IF NOT EXISTS operation_type_counts jsonb;
+
+-- +migrate Down
+
+ALTER TABLE history_ledgers DROP COLUMN IF EXISTS operation_type_counts;
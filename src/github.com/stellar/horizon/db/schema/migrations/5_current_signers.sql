@@ -0,0 +1,23 @@
+-- The signers of the accounts of the network, other than their master keys,
+-- indexed by signer key so that the accounts a key can sign for are found
+-- without scanning stellar-core.  Ingestion seeds the table from stellar-core
+-- while it holds no rows.
+
+-- +migrate Up
+
+CREATE TABLE IF NOT EXISTS current_signers (
+    accountid character varying(56) NOT NULL,
+    publickey character varying(56) NOT NULL,
+    weight integer NOT NULL,
+    lastmodified integer NOT NULL,
+    removed boolean NOT NULL DEFAULT false,
+    PRIMARY KEY (accountid, publickey)
+);
+
+CREATE INDEX IF NOT EXISTS current_signers_by_signer
+    ON current_signers (publickey, accountid)
+    WHERE NOT removed;
+
+-- +migrate Down
+
+DROP TABLE IF EXISTS current_signers;
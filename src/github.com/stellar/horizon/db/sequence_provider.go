@@ -0,0 +1,33 @@
+package db
+
+import (
+	"github.com/jmoiron/sqlx"
+	sq "github.com/lann/squirrel"
+	"golang.org/x/net/context"
+)
+
+// SequenceProvider implements txsub.SequenceProvider, looking up the sequence
+// numbers of accounts in stellar-core's database.
+type SequenceProvider struct {
+	Core *sqlx.DB
+}
+
+func (sp *SequenceProvider) Get(ctx context.Context, addresses []string) (map[string]uint64, error) {
+	result := map[string]uint64{}
+	if len(addresses) == 0 {
+		return result, nil
+	}
+
+	var accounts []CoreAccountRecord
+	q := SqlQuery{sp.Core}
+	err := q.Select(ctx, CoreAccountRecordSelect.Where(sq.Eq{"a.accountid": addresses}), &accounts)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range accounts {
+		result[a.Accountid] = uint64(a.Seqnum)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,27 @@
+package db
+
+import (
+	"testing"
+
+	_ "github.com/lib/pq"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/test"
+)
+
+func TestSequenceProvider(t *testing.T) {
+	test.LoadScenario("non_native_payment")
+
+	Convey("SequenceProvider", t, func() {
+		sp := &SequenceProvider{Core: core}
+
+		withtl := "GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON"
+
+		seqs, err := sp.Get(ctx, []string{withtl, "not_real"})
+		So(err, ShouldBeNil)
+		So(seqs, ShouldResemble, map[string]uint64{withtl: 8589934593})
+
+		seqs, err = sp.Get(ctx, nil)
+		So(err, ShouldBeNil)
+		So(len(seqs), ShouldEqual, 0)
+	})
+}
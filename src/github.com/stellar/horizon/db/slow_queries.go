@@ -0,0 +1,88 @@
+package db
+
+import (
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/stellar/horizon/log"
+	"github.com/stellar/horizon/prometheus"
+	"golang.org/x/net/context"
+)
+
+// SlowQueryLog is where the queries that run for longer than a threshold are
+// recorded, along with the route and params of the request they were run for,
+// so that the filters missing an index may be found.
+type SlowQueryLog struct {
+	// Threshold is how long a query may run before it is slow.  Zero disables
+	// the log.
+	Threshold time.Duration
+
+	// Log logs a line for each slow query
+	Log *logrus.Entry
+
+	// Routes counts the slow queries by the route of their request, when set.
+	// Queries run outside of a request count under an empty route.
+	Routes *prometheus.CounterVec
+}
+
+// querySource is the request a query is run to serve
+type querySource struct {
+	route  string
+	params url.Values
+}
+
+var (
+	querySourceKey = 0
+
+	slowQueriesLock sync.RWMutex
+	slowQueries     SlowQueryLog
+)
+
+// SetSlowQueryLog sets the log of slow queries.  None are logged by default.
+func SetSlowQueryLog(l SlowQueryLog) {
+	slowQueriesLock.Lock()
+	defer slowQueriesLock.Unlock()
+	slowQueries = l
+}
+
+// WithRoute returns a context derived from ctx whose queries are run to serve
+// a request of route with params, as recorded with those that are slow.
+func WithRoute(ctx context.Context, route string, params url.Values) context.Context {
+	return context.WithValue(ctx, &querySourceKey, querySource{route: route, params: params})
+}
+
+// recordSlowQuery records query, run with args on p for elapsed, into the log
+// of slow queries, should it be slow.
+func recordSlowQuery(ctx context.Context, p *Pool, query string, args []interface{}, elapsed time.Duration, err error) {
+	slowQueriesLock.RLock()
+	l := slowQueries
+	slowQueriesLock.RUnlock()
+
+	if l.Threshold <= 0 || elapsed < l.Threshold || l.Log == nil {
+		return
+	}
+
+	source, _ := ctx.Value(&querySourceKey).(querySource)
+
+	p.Slow.Mark(1)
+	if l.Routes != nil {
+		l.Routes.Inc(source.route)
+	}
+
+	entry := l.Log.WithFields(logrus.Fields{
+		"sql":      query,
+		"args":     args,
+		"duration": elapsed.Seconds(),
+		"route":    source.route,
+		"params":   source.params.Encode(),
+	})
+	if id, ok := log.FromContext(ctx).Data[log.RequestIDField]; ok {
+		entry = entry.WithField(log.RequestIDField, id)
+	}
+	if err != nil {
+		entry = entry.WithField("err", err.Error())
+	}
+	entry.Warn("slow query")
+}
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/prometheus"
+	"github.com/stellar/horizon/test"
+)
+
+func TestSlowQueryLog(t *testing.T) {
+	test.LoadScenario("base")
+
+	Convey("SlowQueryLog", t, func() {
+		var out bytes.Buffer
+		l := logrus.New()
+		l.Out = &out
+		l.Formatter = &logrus.JSONFormatter{}
+
+		routes := prometheus.NewCounterVec("slow_queries_total", "Slow queries.", "route")
+		slow := SlowQueryLog{Threshold: time.Nanosecond, Log: logrus.NewEntry(l), Routes: routes}
+		defer SetSlowQueryLog(SlowQueryLog{})
+
+		q := SqlQuery{history}
+		rctx := WithRoute(ctx, "/ledgers/:id", url.Values{"order": []string{"desc"}})
+		before := PoolOf(history).Slow.Count()
+
+		Convey("records queries past the threshold with their route and params", func() {
+			SetSlowQueryLog(slow)
+
+			var seqs []int32
+			err := q.SelectRaw(rctx, "SELECT sequence FROM history_ledgers WHERE sequence > $1", []interface{}{1}, &seqs)
+			So(err, ShouldBeNil)
+
+			var line map[string]interface{}
+			So(json.Unmarshal(out.Bytes(), &line), ShouldBeNil)
+			So(line["msg"], ShouldEqual, "slow query")
+			So(line["sql"], ShouldEqual, "SELECT sequence FROM history_ledgers WHERE sequence > $1")
+			So(line["route"], ShouldEqual, "/ledgers/:id")
+			So(line["params"], ShouldEqual, "order=desc")
+			So(PoolOf(history).Slow.Count(), ShouldEqual, before+1)
+
+			var buf bytes.Buffer
+			So(routes.Collect(&buf), ShouldBeNil)
+			So(buf.String(), ShouldContainSubstring, `slow_queries_total{route="/ledgers/:id"} 1`)
+		})
+
+		Convey("leaves queries within the threshold unrecorded", func() {
+			slow.Threshold = time.Hour
+			SetSlowQueryLog(slow)
+
+			var seqs []int32
+			err := q.SelectRaw(rctx, "SELECT sequence FROM history_ledgers", nil, &seqs)
+			So(err, ShouldBeNil)
+			So(out.Len(), ShouldEqual, 0)
+			So(PoolOf(history).Slow.Count(), ShouldEqual, before)
+		})
+	})
+}
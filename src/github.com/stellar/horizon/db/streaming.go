@@ -4,12 +4,17 @@ import (
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/stellar/horizon/log"
 	"golang.org/x/net/context"
 )
 
 const (
 	MaxHistoryLedger = "SELECT MAX(sequence) FROM history_ledgers"
+
+	// LedgerNotifyChannel is the postgres channel notified, with its sequence,
+	// as each ledger written to history commits.
+	LedgerNotifyChannel = "horizon_ledgers"
 )
 
 // NewLedgerClosePump starts a background proc that continually watches the
@@ -61,3 +66,53 @@ func NewLedgerClosePump(ctx context.Context, db *sqlx.DB) <-chan struct{} {
 
 	return result
 }
+
+// NewLedgerNotifyPump starts a background proc that listens on
+// LedgerNotifyChannel of the history database at url, emitting on the
+// returned channel each time a ledger written to history commits.  It also
+// emits when the connection to the database is re-established, as ledgers
+// may have been written while it was lost.  The proc stops, closing the
+// channel, after the provided context is cancelled.
+func NewLedgerNotifyPump(ctx context.Context, url string) <-chan struct{} {
+	result := make(chan struct{})
+
+	listener := pq.NewListener(url, 1*time.Second, 1*time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Warn(ctx, "ledger notification listener failed", err)
+		}
+	})
+
+	go func() {
+		err := listener.Listen(LedgerNotifyChannel)
+		if err != nil {
+			log.Warn(ctx, "Failed to listen for ledger notifications", err)
+		}
+
+		for {
+			select {
+			case n := <-listener.Notify:
+				// a nil notification follows reconnections
+				if n != nil {
+					log.Debugf(ctx, "notified of new ledger: %s", n.Extra)
+				}
+
+				select {
+				case result <- struct{}{}:
+				default:
+					log.Debug(ctx, "ledger pump channel is blocked.  waiting...")
+				}
+
+			case <-time.After(1 * time.Minute):
+				go listener.Ping()
+
+			case <-ctx.Done():
+				log.Info(ctx, "canceling ledger notification pump")
+				listener.Close()
+				close(result)
+				return
+			}
+		}
+	}()
+
+	return result
+}
@@ -22,4 +22,17 @@ func TestStreaming(t *testing.T) {
 			So(log.String(), ShouldContainSubstring, "canceling")
 		})
 	})
+
+	Convey("LedgerNotifyPump", t, func() {
+		ctx, log := test.ContextWithLogBuffer()
+		ctx, cancel := context.WithCancel(ctx)
+
+		Convey("can cancel", func() {
+			pump := NewLedgerNotifyPump(ctx, test.DatabaseUrl())
+			cancel()
+			_, more := <-pump
+			So(more, ShouldBeFalse)
+			So(log.String(), ShouldContainSubstring, "canceling")
+		})
+	})
 }
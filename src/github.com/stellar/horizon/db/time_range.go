@@ -0,0 +1,118 @@
+package db
+
+import (
+	"math"
+	"time"
+
+	sq "github.com/lann/squirrel"
+	"golang.org/x/net/context"
+)
+
+// TimeRange restricts a page query to the records of the ledgers closed at or
+// after Start and before End.  A zero Start or End leaves that side of the
+// range open.
+type TimeRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// LedgerRange is a range of ledger sequences, from Start up to but excluding
+// End.  The zero LedgerRange holds every ledger.
+type LedgerRange struct {
+	Start int32
+	End   int32
+}
+
+// IsZero returns true if r restricts nothing
+func (r TimeRange) IsZero() bool {
+	return r.Start.IsZero() && r.End.IsZero()
+}
+
+// Ledgers resolves r to the range of the ledgers, known to the history
+// database q, that closed within it.  Both ends are found through the index on
+// history_ledgers.closed_at, so that the queries restricted to the range can
+// use the indexes on their ids.
+func (r TimeRange) Ledgers(ctx context.Context, q SqlQuery) (LedgerRange, error) {
+	result := LedgerRange{Start: 0, End: math.MaxInt32}
+
+	if !r.Start.IsZero() {
+		start, ok, err := firstLedgerClosedSince(ctx, q, r.Start)
+		if err != nil {
+			return LedgerRange{}, err
+		}
+
+		// no ledger has closed since the start of the range, so it is empty
+		if !ok {
+			return LedgerRange{Start: math.MaxInt32, End: math.MaxInt32}, nil
+		}
+
+		result.Start = start
+	}
+
+	if !r.End.IsZero() {
+		end, ok, err := firstLedgerClosedSince(ctx, q, r.End)
+		if err != nil {
+			return LedgerRange{}, err
+		}
+
+		if ok {
+			result.End = end
+		}
+	}
+
+	return result, nil
+}
+
+// IsZero returns true if r holds every ledger
+func (r LedgerRange) IsZero() bool {
+	return r.Start == 0 && r.End == 0
+}
+
+// Contains returns true if the ledger of sequence seq is within r
+func (r LedgerRange) Contains(seq int32) bool {
+	return r.IsZero() || (seq >= r.Start && seq < r.End)
+}
+
+// IDs returns the range of the total order ids of the ledgers, transactions
+// and operations within r, from start up to but excluding end.
+func (r LedgerRange) IDs() (start int64, end int64) {
+	start = TotalOrderId{LedgerSequence: r.Start}.ToInt64()
+	end = TotalOrderId{LedgerSequence: r.End}.ToInt64()
+	return
+}
+
+// coreFilter returns filter, a condition on stellar-core's txhistory rows, also
+// restricted to the ledgers within r.  filter may be nil.
+func (r LedgerRange) coreFilter(filter sq.Sqlizer) sq.Sqlizer {
+	if r.IsZero() {
+		return filter
+	}
+
+	within := sq.Expr("ctxh.ledgerseq >= ? AND ctxh.ledgerseq < ?", r.Start, r.End)
+	if filter == nil {
+		return within
+	}
+
+	return sq.And{filter, within}
+}
+
+// firstLedgerClosedSince returns the sequence of the first ledger closed at or
+// after t, if any has.
+func firstLedgerClosedSince(ctx context.Context, q SqlQuery, t time.Time) (int32, bool, error) {
+	var found []int32
+	err := q.Select(ctx, sq.
+		Select("sequence").
+		From("history_ledgers").
+		Where("closed_at >= ?", t.UTC()).
+		OrderBy("closed_at asc").
+		Limit(1), &found)
+	if err != nil {
+		return 0, false, err
+	}
+
+	if len(found) == 0 {
+		return 0, false, nil
+	}
+
+	return found[0], true, nil
+}
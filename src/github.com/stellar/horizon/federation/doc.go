@@ -0,0 +1,10 @@
+// Package federation resolves stellar federation addresses, such as
+// `bob*example.com`, into the account ids they stand for.
+//
+// A federation address names an account at a domain.  The domain publishes,
+// in its stellar.toml file, the url of a federation server, and the server
+// answers name lookups with the account id of the address.  A Resolver
+// performs both requests and caches the accounts found, so that repeated
+// lookups of an address do not reach the domain again until their entry
+// expires.
+package federation
@@ -0,0 +1,265 @@
+package federation
+
+import (
+	"encoding/json"
+	stderr "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/go-errors/errors"
+	"github.com/golang/groupcache/lru"
+	"github.com/stellar/horizon/httpx"
+	"github.com/stellar/horizon/strkeys"
+	"golang.org/x/net/context"
+)
+
+const (
+	// DefaultCacheSize is the number of addresses a Resolver remembers when
+	// CacheSize is zero.
+	DefaultCacheSize = 1000
+
+	// DefaultCacheTTL is how long a Resolver remembers an address when CacheTTL
+	// is zero.
+	DefaultCacheTTL = 10 * time.Minute
+
+	// DefaultTimeout is how long a Resolver waits on each of its requests when
+	// its client has no timeout of its own.
+	DefaultTimeout = 10 * time.Second
+
+	// maxTomlSize and maxResponseSize are the most bytes read of a stellar.toml
+	// file and of the response of a federation server.
+	maxTomlSize     = 100 * 1024
+	maxResponseSize = 10 * 1024
+)
+
+// NOTE: the errors below are not go-errors based errors, as stack traces are
+// unnecessary
+var (
+	// ErrInvalidAddress is returned when resolving a string that is not of the
+	// form `name*domain`, or whose domain is not a public hostname.
+	ErrInvalidAddress = stderr.New("invalid federation address")
+
+	// ErrNotFound is returned when the federation server of an address' domain
+	// knows of no account by that name.
+	ErrNotFound = stderr.New("federation address not found")
+
+	// ErrNoFederationServer is returned when the stellar.toml of an address'
+	// domain cannot be loaded, or names no https federation server on a public
+	// hostname.
+	ErrNoFederationServer = stderr.New("domain has no federation server")
+
+	// ErrInvalidAccountID is returned when the federation server of an
+	// address' domain resolves it to something other than an account id.
+	ErrInvalidAccountID = stderr.New("federation server returned an invalid account id")
+)
+
+// IsAddress returns true if s looks like a federation address, that is, if it
+// contains the `*` separating a name from its domain.
+func IsAddress(s string) bool {
+	return strings.Contains(s, "*")
+}
+
+// Resolver looks up the account ids of federation addresses, caching those
+// found.  The zero value is ready to use, and a Resolver is safe for concurrent
+// access.
+type Resolver struct {
+	// Client performs the resolver's requests.  When nil, the client bound
+	// to the context of each lookup by httpx.ClientContext is used.  Either
+	// is given DefaultTimeout when it has no timeout.
+	Client *http.Client
+
+	// CacheSize is the most addresses remembered at once.
+	CacheSize int
+
+	// CacheTTL is how long a resolved address is remembered.
+	CacheTTL time.Duration
+
+	// Insecure loads stellar.toml files over plain http rather than https,
+	// and from any host, as it does federation servers.  It is meant for
+	// testing against local servers.
+	Insecure bool
+
+	lock  sync.Mutex
+	cache *lru.Cache
+}
+
+type cached struct {
+	accountID string
+	expires   time.Time
+}
+
+// Resolve returns the account id of address, loading it from the federation
+// server of the address' domain unless it was resolved recently.
+func (r *Resolver) Resolve(ctx context.Context, address string) (string, error) {
+	i := strings.LastIndex(address, "*")
+	if i <= 0 || i == len(address)-1 {
+		return "", ErrInvalidAddress
+	}
+	domain := address[i+1:]
+
+	if !r.Insecure && httpx.ValidatePublicHost(domain) != nil {
+		return "", ErrInvalidAddress
+	}
+
+	if accountID, ok := r.lookup(address); ok {
+		return accountID, nil
+	}
+
+	server, err := r.federationServer(ctx, domain)
+	if err != nil {
+		return "", err
+	}
+
+	accountID, err := r.query(ctx, server, address)
+	if err != nil {
+		return "", err
+	}
+
+	r.store(address, accountID)
+	return accountID, nil
+}
+
+// federationServer loads the url of domain's federation server from its
+// stellar.toml file
+func (r *Resolver) federationServer(ctx context.Context, domain string) (string, error) {
+	scheme := "https"
+	if r.Insecure {
+		scheme = "http"
+	}
+
+	resp, err := r.client(ctx).Get(fmt.Sprintf("%s://%s/.well-known/stellar.toml", scheme, domain))
+	if err != nil {
+		return "", errors.Wrap(err, 1)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", ErrNoFederationServer
+	}
+
+	var stellarToml struct {
+		FederationServer string `toml:"FEDERATION_SERVER"`
+	}
+
+	_, err = toml.DecodeReader(io.LimitReader(resp.Body, maxTomlSize), &stellarToml)
+	if err != nil {
+		return "", errors.Wrap(err, 1)
+	}
+
+	if stellarToml.FederationServer == "" {
+		return "", ErrNoFederationServer
+	}
+
+	if !r.Insecure {
+		u, err := url.Parse(stellarToml.FederationServer)
+		if err != nil || u.Scheme != "https" || httpx.ValidatePublicHost(u.Hostname()) != nil {
+			return "", ErrNoFederationServer
+		}
+	}
+
+	return stellarToml.FederationServer, nil
+}
+
+// query asks the federation server at server for the account id of address
+func (r *Resolver) query(ctx context.Context, server, address string) (string, error) {
+	u, err := url.Parse(server)
+	if err != nil {
+		return "", errors.Wrap(err, 1)
+	}
+
+	q := u.Query()
+	q.Set("q", address)
+	q.Set("type", "name")
+	u.RawQuery = q.Encode()
+
+	resp, err := r.client(ctx).Get(u.String())
+	if err != nil {
+		return "", errors.Wrap(err, 1)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return "", ErrNotFound
+	default:
+		return "", errors.Errorf("federation server responded with status %d", resp.StatusCode)
+	}
+
+	var record struct {
+		AccountID string `json:"account_id"`
+	}
+
+	err = json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&record)
+	if err != nil {
+		return "", errors.Wrap(err, 1)
+	}
+
+	if record.AccountID == "" {
+		return "", ErrNotFound
+	}
+
+	_, err = strkeys.Decode(strkeys.AccountID, record.AccountID)
+	if err != nil {
+		return "", ErrInvalidAccountID
+	}
+
+	return record.AccountID, nil
+}
+
+func (r *Resolver) client(ctx context.Context) *http.Client {
+	client := r.Client
+	if client == nil {
+		client = httpx.ClientFromContext(ctx)
+	}
+
+	return httpx.ClientWithTimeout(client, DefaultTimeout)
+}
+
+func (r *Resolver) lookup(address string) (string, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.cache == nil {
+		return "", false
+	}
+
+	found, ok := r.cache.Get(address)
+	if !ok {
+		return "", false
+	}
+
+	entry := found.(cached)
+	if time.Now().After(entry.expires) {
+		r.cache.Remove(address)
+		return "", false
+	}
+
+	return entry.accountID, true
+}
+
+func (r *Resolver) store(address, accountID string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.cache == nil {
+		size := r.CacheSize
+		if size == 0 {
+			size = DefaultCacheSize
+		}
+		r.cache = lru.New(size)
+	}
+
+	ttl := r.CacheTTL
+	if ttl == 0 {
+		ttl = DefaultCacheTTL
+	}
+
+	r.cache.Add(address, cached{accountID: accountID, expires: time.Now().Add(ttl)})
+}
@@ -0,0 +1,108 @@
+package federation
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/test"
+)
+
+func TestResolver(t *testing.T) {
+	ctx := test.Context()
+
+	Convey("Resolver", t, func() {
+		lookups := 0
+		mux := http.NewServeMux()
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		domain := strings.TrimPrefix(server.URL, "http://")
+
+		mux.HandleFunc("/.well-known/stellar.toml", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "FEDERATION_SERVER=\"%s/federation\"\n", server.URL)
+		})
+
+		mux.HandleFunc("/federation", func(w http.ResponseWriter, r *http.Request) {
+			lookups++
+			if r.URL.Query().Get("q") == "carol*"+domain {
+				fmt.Fprint(w, `{"stellar_address": "carol", "account_id": "SBQWY3DNPFWGSZTFNV4WQZLBOJ2GQYLTMJSWK3TTMVZHG5DBOJ2XK43UEBSDEZTXMNXW43DLMRUGC3DH"}`)
+				return
+			}
+			if r.URL.Query().Get("type") != "name" || r.URL.Query().Get("q") != "bob*"+domain {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"detail": "not found"}`)
+				return
+			}
+			fmt.Fprint(w, `{"stellar_address": "bob", "account_id": "GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON"}`)
+		})
+
+		r := &Resolver{Insecure: true}
+
+		Convey("resolves the account id of an address", func() {
+			aid, err := r.Resolve(ctx, "bob*"+domain)
+			So(err, ShouldBeNil)
+			So(aid, ShouldEqual, "GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON")
+		})
+
+		Convey("caches resolved addresses", func() {
+			_, err := r.Resolve(ctx, "bob*"+domain)
+			So(err, ShouldBeNil)
+			_, err = r.Resolve(ctx, "bob*"+domain)
+			So(err, ShouldBeNil)
+			So(lookups, ShouldEqual, 1)
+		})
+
+		Convey("forgets cached addresses once expired", func() {
+			r.CacheTTL = time.Nanosecond
+			_, err := r.Resolve(ctx, "bob*"+domain)
+			So(err, ShouldBeNil)
+			time.Sleep(time.Millisecond)
+			_, err = r.Resolve(ctx, "bob*"+domain)
+			So(err, ShouldBeNil)
+			So(lookups, ShouldEqual, 2)
+		})
+
+		Convey("returns ErrNotFound for unknown names", func() {
+			_, err := r.Resolve(ctx, "alice*"+domain)
+			So(err, ShouldEqual, ErrNotFound)
+		})
+
+		Convey("returns ErrInvalidAddress for malformed addresses", func() {
+			for _, address := range []string{"bob", "*" + domain, "bob*"} {
+				_, err := r.Resolve(ctx, address)
+				So(err, ShouldEqual, ErrInvalidAddress)
+			}
+		})
+
+		Convey("returns ErrInvalidAccountID for names resolved to other strings", func() {
+			_, err := r.Resolve(ctx, "carol*"+domain)
+			So(err, ShouldEqual, ErrInvalidAccountID)
+
+			_, err = r.Resolve(ctx, "carol*"+domain)
+			So(err, ShouldEqual, ErrInvalidAccountID)
+			So(lookups, ShouldEqual, 2)
+		})
+
+		Convey("returns ErrInvalidAddress for domains that are not public hostnames", func() {
+			secure := &Resolver{}
+			for _, d := range []string{domain, "localhost", "169.254.169.254", "metadata.internal"} {
+				_, err := secure.Resolve(ctx, "bob*"+d)
+				So(err, ShouldEqual, ErrInvalidAddress)
+			}
+			So(lookups, ShouldEqual, 0)
+		})
+
+		Convey("returns ErrNoFederationServer when the domain has none", func() {
+			other := httptest.NewServer(http.NotFoundHandler())
+			defer other.Close()
+
+			_, err := r.Resolve(ctx, "bob*"+strings.TrimPrefix(other.URL, "http://"))
+			So(err, ShouldEqual, ErrNoFederationServer)
+		})
+	})
+}
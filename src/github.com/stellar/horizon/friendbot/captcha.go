@@ -0,0 +1,88 @@
+package friendbot
+
+import (
+	"encoding/json"
+	stderr "errors"
+	"net/http"
+	"net/url"
+
+	"github.com/go-errors/errors"
+	"github.com/stellar/horizon/httpx"
+	"golang.org/x/net/context"
+)
+
+// TurnstileURL is the siteverify endpoint of Cloudflare Turnstile
+const TurnstileURL = "https://challenges.cloudflare.com/turnstile/v0/siteverify"
+
+// ErrCaptchaFailed is returned by a Verifier when the captcha a client was
+// to solve is missing or was not solved.
+// NOTE: this is not a go-errors based error, as stack traces are unnecessary
+var ErrCaptchaFailed = stderr.New("captcha failed")
+
+// Verifier verifies the captchas solved by the clients asking to be funded.
+type Verifier interface {
+	// Verify returns ErrCaptchaFailed unless token is that of a captcha solved
+	// by the client at remoteIP.
+	Verify(ctx context.Context, token, remoteIP string) error
+}
+
+// SiteVerifier is the Verifier of captchas checked through a siteverify
+// endpoint, the api Cloudflare Turnstile, hCaptcha and reCAPTCHA share.
+type SiteVerifier struct {
+	// URL is the siteverify endpoint, such as TurnstileURL
+	URL string
+
+	// Secret is the key the server verifies captchas with
+	Secret string
+
+	// Client performs the verifier's requests.  When nil, the client bound to
+	// the context of each verification by httpx.ClientContext is used.
+	Client *http.Client
+}
+
+// Verify is a method for Verifier
+func (v *SiteVerifier) Verify(ctx context.Context, token, remoteIP string) error {
+	if token == "" {
+		return ErrCaptchaFailed
+	}
+
+	form := url.Values{
+		"secret":   []string{v.Secret},
+		"response": []string{token},
+	}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+
+	resp, err := v.client(ctx).PostForm(v.URL, form)
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("captcha verifier responded with status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Success bool `json:"success"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	if !result.Success {
+		return ErrCaptchaFailed
+	}
+
+	return nil
+}
+
+func (v *SiteVerifier) client(ctx context.Context) *http.Client {
+	if v.Client == nil {
+		return httpx.ClientFromContext(ctx)
+	}
+
+	return v.Client
+}
@@ -0,0 +1,10 @@
+// Package friendbot funds new accounts on test networks, creating each with
+// lumens from the account of a secret held by the server.
+//
+// Requests are expected to be limited by client ip and by the account funded
+// before they reach a Bot, and may be made to solve a captcha, checked by a
+// Verifier such as SiteVerifier.  A Bot submits its transactions through the
+// submission system of the server, numbering them itself, so that the
+// accounts asked for at once are funded within a ledger or two rather than one
+// by one.
+package friendbot
@@ -0,0 +1,174 @@
+package friendbot
+
+import (
+	stderr "errors"
+	"sync"
+
+	"github.com/go-errors/errors"
+	"github.com/stellar/go-stellar-base"
+	"github.com/stellar/go-stellar-base/amount"
+	"github.com/stellar/go-stellar-base/build"
+	"github.com/stellar/go-stellar-base/xdr"
+	"github.com/stellar/horizon/strkeys"
+	"github.com/stellar/horizon/txsub"
+	"golang.org/x/net/context"
+)
+
+// ErrInvalidAddress is returned by Bot.Fund when given an address that is not
+// that of an account.
+// NOTE: this is not a go-errors based error, as stack traces are unnecessary
+var ErrInvalidAddress = stderr.New("invalid account address")
+
+// ErrAccountExists is returned by Bot.Fund when the account to fund already
+// exists.
+// NOTE: this is not a go-errors based error, as stack traces are unnecessary
+var ErrAccountExists = stderr.New("account already exists")
+
+// Submitter submits transaction envelopes to the network, as txsub.System
+// does.
+type Submitter interface {
+	Submit(ctx context.Context, env string) <-chan txsub.Result
+}
+
+// Bot funds accounts from the account of its secret.  A Bot is safe for
+// concurrent access.
+type Bot struct {
+	key             stellarbase.PrivateKey
+	address         string
+	network         build.Network
+	startingBalance string
+	submitter       Submitter
+	sequences       txsub.SequenceProvider
+
+	// sequence is that of the last transaction the bot built, zero until it
+	// is loaded from sequences
+	lock     sync.Mutex
+	sequence uint64
+}
+
+// New returns a bot funding accounts from the account of secret on the
+// network of passphrase, each with startingBalance lumens.  Its transactions
+// are submitted through submitter, the sequence number of its account being
+// looked up from sequences.
+func New(secret, passphrase, startingBalance string, submitter Submitter, sequences txsub.SequenceProvider) (*Bot, error) {
+	_, key, err := stellarbase.GenerateKeyFromSeed(secret)
+	if err != nil {
+		return nil, errors.Errorf("invalid friendbot secret: %s", err)
+	}
+
+	_, err = amount.Parse(startingBalance)
+	if err != nil {
+		return nil, errors.Errorf("invalid friendbot amount %q: %s", startingBalance, err)
+	}
+
+	return &Bot{
+		key:             key,
+		address:         key.Address(),
+		network:         build.Network{Passphrase: passphrase},
+		startingBalance: startingBalance,
+		submitter:       submitter,
+		sequences:       sequences,
+	}, nil
+}
+
+// Address returns the address of the account the bot funds accounts from
+func (bot *Bot) Address() string {
+	return bot.address
+}
+
+// Fund submits the transaction creating the account of address, returning the
+// channel its result is sent on.  Should the transaction fail, the bot looks
+// up the sequence number of its account anew before building the next.
+func (bot *Bot) Fund(ctx context.Context, address string) (<-chan txsub.Result, error) {
+	if strkeys.ValidateAccountID(address) != nil {
+		return nil, ErrInvalidAddress
+	}
+
+	existing, err := bot.sequences.Get(ctx, []string{address})
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := existing[address]; ok {
+		return nil, ErrAccountExists
+	}
+
+	seq, err := bot.nextSequence(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	env, err := bot.envelope(address, seq)
+	if err != nil {
+		bot.reset()
+		return nil, err
+	}
+
+	results := bot.submitter.Submit(ctx, env)
+	out := make(chan txsub.Result, 1)
+	go func() {
+		r := <-results
+		if r.Err != nil {
+			bot.reset()
+		}
+		out <- r
+	}()
+
+	return out, nil
+}
+
+// nextSequence returns the sequence number of the next transaction of the bot
+func (bot *Bot) nextSequence(ctx context.Context) (uint64, error) {
+	bot.lock.Lock()
+	defer bot.lock.Unlock()
+
+	if bot.sequence == 0 {
+		sequences, err := bot.sequences.Get(ctx, []string{bot.address})
+		if err != nil {
+			return 0, err
+		}
+
+		current, ok := sequences[bot.address]
+		if !ok {
+			return 0, errors.Errorf("friendbot account %s does not exist", bot.address)
+		}
+		bot.sequence = current
+	}
+
+	bot.sequence++
+	return bot.sequence, nil
+}
+
+// reset forgets the sequence number of the bot's account, so that the next
+// transaction looks it up anew.  Transactions that fail to be submitted leave
+// the sequence number unused, and those the bot numbered after them would
+// otherwise fail as well.
+func (bot *Bot) reset() {
+	bot.lock.Lock()
+	bot.sequence = 0
+	bot.lock.Unlock()
+}
+
+// envelope returns the signed, base64 encoded envelope of the transaction
+// creating the account of address, with sequence number seq.
+func (bot *Bot) envelope(address string, seq uint64) (string, error) {
+	tx := build.Transaction(
+		build.SourceAccount{Address: bot.address},
+		build.Sequence{Sequence: xdr.SequenceNumber(seq)},
+		bot.network,
+		build.CreateAccount(
+			build.Destination{Address: address},
+			build.NativeAmount{Amount: bot.startingBalance},
+		),
+	)
+	if tx.Err != nil {
+		return "", errors.Wrap(tx.Err, 1)
+	}
+
+	txe := tx.Sign(&bot.key)
+	env, err := txe.Base64()
+	if err != nil {
+		return "", errors.Wrap(err, 1)
+	}
+
+	return env, nil
+}
@@ -0,0 +1,142 @@
+package friendbot
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/go-stellar-base"
+	"github.com/stellar/go-stellar-base/build"
+	"github.com/stellar/go-stellar-base/xdr"
+	"github.com/stellar/horizon/test"
+	"github.com/stellar/horizon/txsub"
+	"golang.org/x/net/context"
+)
+
+const (
+	secret      = "SDHOAMBNLGCE2MV5ZKIVZAQD3VCLGP53P3OBSBI6UN5L5XZI5TKHFQL4"
+	destination = "GA5WBPYA5Y4WAEHXWR2UKO2UO4BUGHUQ74EUPKON2QHV4WRHOIRNKKH2"
+)
+
+type mockSubmitter struct {
+	envs []string
+	err  error
+}
+
+func (s *mockSubmitter) Submit(ctx context.Context, env string) <-chan txsub.Result {
+	s.envs = append(s.envs, env)
+	result := make(chan txsub.Result, 1)
+	result <- txsub.Result{Err: s.err, EnvelopeXDR: env}
+	return result
+}
+
+type mockSequences map[string]uint64
+
+func (s mockSequences) Get(ctx context.Context, addresses []string) (map[string]uint64, error) {
+	result := map[string]uint64{}
+	for _, address := range addresses {
+		if seq, ok := s[address]; ok {
+			result[address] = seq
+		}
+	}
+	return result, nil
+}
+
+// sequenceOf returns the sequence number of the transaction of env
+func sequenceOf(env string) uint64 {
+	var e xdr.TransactionEnvelope
+	So(xdr.SafeUnmarshalBase64(env, &e), ShouldBeNil)
+	return uint64(e.Tx.SeqNum)
+}
+
+func TestBot(t *testing.T) {
+	ctx := test.Context()
+
+	Convey("Bot", t, func() {
+		submitter := &mockSubmitter{}
+		sequences := mockSequences{}
+		bot, err := New(secret, build.TestNetworkPassphrase, "10000", submitter, sequences)
+		So(err, ShouldBeNil)
+		sequences[bot.Address()] = 7
+
+		Convey("creates accounts with the starting balance", func() {
+			results, err := bot.Fund(ctx, destination)
+			So(err, ShouldBeNil)
+			So((<-results).Err, ShouldBeNil)
+
+			var e xdr.TransactionEnvelope
+			So(xdr.SafeUnmarshalBase64(submitter.envs[0], &e), ShouldBeNil)
+			So(e.Tx.SeqNum, ShouldEqual, 8)
+			So(len(e.Signatures), ShouldEqual, 1)
+
+			op := e.Tx.Operations[0].Body.MustCreateAccountOp()
+			aid, err := stellarbase.AddressToAccountId(destination)
+			So(err, ShouldBeNil)
+			So(op.Destination, ShouldResemble, aid)
+			So(op.StartingBalance, ShouldEqual, 10000*10000000)
+		})
+
+		Convey("numbers the transactions it builds at once consecutively", func() {
+			_, err := bot.Fund(ctx, destination)
+			So(err, ShouldBeNil)
+			_, err = bot.Fund(ctx, destination)
+			So(err, ShouldBeNil)
+
+			So(sequenceOf(submitter.envs[0]), ShouldEqual, 8)
+			So(sequenceOf(submitter.envs[1]), ShouldEqual, 9)
+		})
+
+		Convey("looks its sequence number up anew once a transaction fails", func() {
+			submitter.err = &txsub.FailedTransactionError{}
+			results, err := bot.Fund(ctx, destination)
+			So(err, ShouldBeNil)
+			So((<-results).Err, ShouldNotBeNil)
+
+			submitter.err = nil
+			_, err = bot.Fund(ctx, destination)
+			So(err, ShouldBeNil)
+			So(sequenceOf(submitter.envs[1]), ShouldEqual, 8)
+		})
+
+		Convey("refuses accounts that exist", func() {
+			sequences[destination] = 1
+			_, err := bot.Fund(ctx, destination)
+			So(err, ShouldEqual, ErrAccountExists)
+			So(submitter.envs, ShouldBeEmpty)
+		})
+
+		Convey("refuses invalid addresses", func() {
+			_, err := bot.Fund(ctx, secret)
+			So(err, ShouldEqual, ErrInvalidAddress)
+		})
+	})
+
+	Convey("New refuses invalid secrets and amounts", t, func() {
+		_, err := New(destination, build.TestNetworkPassphrase, "10000", nil, nil)
+		So(err, ShouldNotBeNil)
+		_, err = New(secret, build.TestNetworkPassphrase, "lots", nil, nil)
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestSiteVerifier(t *testing.T) {
+	ctx := test.Context()
+
+	Convey("SiteVerifier", t, func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.FormValue("secret") == "shh" && r.FormValue("response") == "solved" {
+				w.Write([]byte(`{"success": true}`))
+				return
+			}
+			w.Write([]byte(`{"success": false, "error-codes": ["invalid-input-response"]}`))
+		}))
+		defer server.Close()
+
+		v := &SiteVerifier{URL: server.URL, Secret: "shh", Client: http.DefaultClient}
+
+		So(v.Verify(ctx, "solved", "127.0.0.1"), ShouldBeNil)
+		So(v.Verify(ctx, "guessed", "127.0.0.1"), ShouldEqual, ErrCaptchaFailed)
+		So(v.Verify(ctx, "", "127.0.0.1"), ShouldEqual, ErrCaptchaFailed)
+	})
+}
@@ -0,0 +1,6 @@
+// Package graphql implements the subset of GraphQL that horizon's /graphql
+// endpoint supports: single query or subscription operations made of fields,
+// aliases and arguments, with variables.  Rather than resolving fields against
+// a schema, horizon loads its usual resources and uses Select to project the
+// fields a client asked for from their json form.
+package graphql
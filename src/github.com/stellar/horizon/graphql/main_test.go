@@ -0,0 +1,143 @@
+package graphql
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-errors/errors"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/render/problem"
+	"github.com/stellar/horizon/test"
+)
+
+func TestGraphqlPackage(t *testing.T) {
+	ctx := test.Context()
+
+	Convey("graphql.Parse", t, func() {
+		Convey("parses the shorthand query form", func() {
+			doc, err := Parse(`{ account(id: "GABC") { id sequence } }`, nil)
+			So(err, ShouldBeNil)
+			So(doc.Operation, ShouldEqual, OperationQuery)
+			So(len(doc.Fields), ShouldEqual, 1)
+
+			account := doc.Fields[0]
+			So(account.Name, ShouldEqual, "account")
+			So(account.Args["id"], ShouldEqual, "GABC")
+			So(len(account.Selections), ShouldEqual, 2)
+			So(account.Selections[1].Name, ShouldEqual, "sequence")
+		})
+
+		Convey("parses operations, aliases, arguments and comments", func() {
+			doc, err := Parse(`
+				subscription Payments {
+					# the newest payments
+					latest: payments(cursor: "now", limit: 5, order: desc, all: true) {
+						id
+					}
+				}`, nil)
+			So(err, ShouldBeNil)
+			So(doc.Operation, ShouldEqual, OperationSubscription)
+			So(doc.Name, ShouldEqual, "Payments")
+
+			field := doc.Fields[0]
+			So(field.Key(), ShouldEqual, "latest")
+			So(field.Name, ShouldEqual, "payments")
+			So(field.Args["limit"], ShouldEqual, int64(5))
+			So(field.Args["order"], ShouldEqual, "desc")
+			So(field.Args["all"], ShouldEqual, true)
+		})
+
+		Convey("substitutes variables, falling back to their defaults", func() {
+			doc, err := Parse(`
+				query ($id: String!, $limit: Int = 3) {
+					transactions(account_id: $id, limit: $limit) { hash }
+				}`, map[string]interface{}{"id": "GABC"})
+			So(err, ShouldBeNil)
+			So(doc.Fields[0].Args["account_id"], ShouldEqual, "GABC")
+			So(doc.Fields[0].Args["limit"], ShouldEqual, int64(3))
+		})
+
+		Convey("rejects invalid documents", func() {
+			for _, src := range []string{
+				`{ account(id: "GABC") { id }`,
+				`mutation { account { id } }`,
+				`{ }`,
+				`{ account(id: ) { id } }`,
+				`{ ledgers { id } } { ledgers { id } }`,
+				`{ account(id: "unterminated) { id } }`,
+			} {
+				_, err := Parse(src, nil)
+				So(err, ShouldNotBeNil)
+			}
+		})
+	})
+
+	Convey("graphql.Select", t, func() {
+		type asset struct {
+			Code string `json:"code"`
+		}
+		type resource struct {
+			ID     string  `json:"id"`
+			Amount int     `json:"amount"`
+			Asset  asset   `json:"asset"`
+			Assets []asset `json:"assets"`
+		}
+		data := resource{ID: "1", Amount: 10, Asset: asset{"USD"}, Assets: []asset{{"EUR"}, {"JPY"}}}
+
+		selection := func(src string) []*Field {
+			doc, err := Parse(src, nil)
+			So(err, ShouldBeNil)
+			return doc.Fields
+		}
+
+		Convey("projects the requested fields, in order", func() {
+			result, err := Select(data, selection(`{ amount id asset { code } assets { code } missing }`))
+			So(err, ShouldBeNil)
+
+			js, _ := json.Marshal(result)
+			So(string(js), ShouldEqual, `{"amount":10,"id":"1","asset":{"code":"USD"},"assets":[{"code":"EUR"},{"code":"JPY"}],"missing":null}`)
+		})
+
+		Convey("projects each element of lists", func() {
+			result, err := Select([]resource{data, data}, selection(`{ id }`))
+			So(err, ShouldBeNil)
+
+			js, _ := json.Marshal(result)
+			So(string(js), ShouldEqual, `[{"id":"1"},{"id":"1"}]`)
+		})
+
+		Convey("requires objects to select subfields", func() {
+			_, err := Select(data, selection(`{ asset }`))
+			So(err, ShouldNotBeNil)
+
+			_, err = Select(data, selection(`{ id { code } }`))
+			So(err, ShouldNotBeNil)
+		})
+	})
+
+	Convey("graphql.Render", t, func() {
+		Convey("renders data", func() {
+			w := httptest.NewRecorder()
+			Render(ctx, w, Object{{Key: "ledgers", Value: []int{1}}}, nil)
+			So(w.Code, ShouldEqual, http.StatusOK)
+
+			var doc map[string]interface{}
+			So(json.Unmarshal(w.Body.Bytes(), &doc), ShouldBeNil)
+			So(doc["data"], ShouldNotBeNil)
+			So(doc["errors"], ShouldBeNil)
+		})
+
+		Convey("renders errors with the status of their problem", func() {
+			w := httptest.NewRecorder()
+			Render(ctx, w, nil, &problem.NotFound)
+			So(w.Code, ShouldEqual, http.StatusNotFound)
+			So(w.Body.String(), ShouldContainSubstring, `"type": "https://stellar.org/horizon-errors/not_found"`)
+
+			w = httptest.NewRecorder()
+			Render(ctx, w, nil, errors.New("busted"))
+			So(w.Code, ShouldEqual, http.StatusInternalServerError)
+		})
+	})
+}
@@ -0,0 +1,463 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-errors/errors"
+)
+
+const (
+	// OperationQuery is the type of operations that fetch data once.
+	OperationQuery = "query"
+	// OperationSubscription is the type of operations that stream data.
+	OperationSubscription = "subscription"
+)
+
+// Document is a parsed graphql request: a single operation and the fields it
+// selects.
+type Document struct {
+	Operation string
+	Name      string
+	Fields    []*Field
+}
+
+// Field is a field selected by a document, along with its arguments and the
+// fields selected from its value, if any.
+type Field struct {
+	Alias      string
+	Name       string
+	Args       map[string]interface{}
+	Selections []*Field
+}
+
+// Key returns the name under which the field's value is returned: its alias,
+// or failing that its name.
+func (f *Field) Key() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+
+	return f.Name
+}
+
+// Parse parses the provided graphql source, substituting variables for the
+// variable references within it.  Parse supports a single operation per
+// document; fragments and directives are not supported.
+func Parse(src string, variables map[string]interface{}) (*Document, error) {
+	p := &parser{lex: lexer{src: src}, variables: variables}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	doc, err := p.document()
+	if err != nil {
+		return nil, errors.Wrap(err, 1)
+	}
+
+	return doc, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunct
+	tokenName
+	tokenString
+	tokenNumber
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "end of document"
+	}
+
+	return strconv.Quote(t.value)
+}
+
+// lexer splits graphql source into tokens, skipping whitespace, commas and
+// comments, all of which are insignificant.
+type lexer struct {
+	src string
+	pos int
+}
+
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			l.pos++
+		case c == '#':
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+				l.pos++
+			}
+		default:
+			return l.token()
+		}
+	}
+
+	return token{kind: tokenEOF, pos: l.pos}, nil
+}
+
+func (l *lexer) token() (token, error) {
+	start := l.pos
+	c := l.src[l.pos]
+
+	switch {
+	case strings.IndexByte("{}():!$=[]", c) >= 0:
+		l.pos++
+		return token{kind: tokenPunct, value: string(c), pos: start}, nil
+	case c == '"':
+		l.pos++
+		for l.pos < len(l.src) && l.src[l.pos] != '"' {
+			if l.src[l.pos] == '\\' {
+				l.pos++
+			}
+			l.pos++
+		}
+		if l.pos >= len(l.src) {
+			return token{}, fmt.Errorf("unterminated string at %d", start)
+		}
+		l.pos++
+
+		value, err := strconv.Unquote(l.src[start:l.pos])
+		if err != nil {
+			return token{}, fmt.Errorf("invalid string at %d", start)
+		}
+		return token{kind: tokenString, value: value, pos: start}, nil
+	case c == '-' || isDigit(c):
+		l.pos++
+		for l.pos < len(l.src) && strings.IndexByte("0123456789.eE+-", l.src[l.pos]) >= 0 {
+			l.pos++
+		}
+		return token{kind: tokenNumber, value: l.src[start:l.pos], pos: start}, nil
+	case isNameStart(c):
+		for l.pos < len(l.src) && (isNameStart(l.src[l.pos]) || isDigit(l.src[l.pos])) {
+			l.pos++
+		}
+		return token{kind: tokenName, value: l.src[start:l.pos], pos: start}, nil
+	default:
+		return token{}, fmt.Errorf("unexpected character %q at %d", c, start)
+	}
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// parser is a recursive descent parser over the tokens of a lexer, with a
+// single token of lookahead.
+type parser struct {
+	lex       lexer
+	tok       token
+	variables map[string]interface{}
+}
+
+func (p *parser) advance() (err error) {
+	p.tok, err = p.lex.next()
+	return
+}
+
+// peek returns true if the current token is the provided punctuator.
+func (p *parser) peek(punct string) bool {
+	return p.tok.kind == tokenPunct && p.tok.value == punct
+}
+
+func (p *parser) expect(punct string) error {
+	if !p.peek(punct) {
+		return fmt.Errorf("expected %q at %d, found %s", punct, p.tok.pos, p.tok)
+	}
+
+	return p.advance()
+}
+
+func (p *parser) name() (string, error) {
+	if p.tok.kind != tokenName {
+		return "", fmt.Errorf("expected a name at %d, found %s", p.tok.pos, p.tok)
+	}
+
+	name := p.tok.value
+	return name, p.advance()
+}
+
+func (p *parser) document() (*Document, error) {
+	doc := &Document{Operation: OperationQuery}
+
+	// the shorthand form, a bare selection set, is a query
+	if !p.peek("{") {
+		op, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+
+		switch op {
+		case OperationQuery, OperationSubscription:
+			doc.Operation = op
+		default:
+			return nil, fmt.Errorf("unsupported operation type %q", op)
+		}
+
+		if p.tok.kind == tokenName {
+			doc.Name = p.tok.value
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+		}
+
+		if p.peek("(") {
+			if err := p.variableDefinitions(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	fields, err := p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+	doc.Fields = fields
+
+	if p.tok.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %s at %d: only a single operation is supported", p.tok, p.tok.pos)
+	}
+
+	return doc, nil
+}
+
+// variableDefinitions parses the variables an operation declares.  Their types
+// are not checked, but a declared default is used for variables that were not
+// provided.
+func (p *parser) variableDefinitions() error {
+	if err := p.expect("("); err != nil {
+		return err
+	}
+
+	for !p.peek(")") {
+		if err := p.expect("$"); err != nil {
+			return err
+		}
+
+		name, err := p.name()
+		if err != nil {
+			return err
+		}
+
+		if err := p.expect(":"); err != nil {
+			return err
+		}
+
+		if err := p.skipType(); err != nil {
+			return err
+		}
+
+		if p.peek("=") {
+			if err := p.advance(); err != nil {
+				return err
+			}
+
+			value, err := p.value()
+			if err != nil {
+				return err
+			}
+
+			if _, ok := p.variables[name]; !ok {
+				if p.variables == nil {
+					p.variables = map[string]interface{}{}
+				}
+				p.variables[name] = value
+			}
+		}
+	}
+
+	return p.expect(")")
+}
+
+func (p *parser) skipType() error {
+	if p.peek("[") {
+		if err := p.advance(); err != nil {
+			return err
+		}
+		if err := p.skipType(); err != nil {
+			return err
+		}
+		if err := p.expect("]"); err != nil {
+			return err
+		}
+	} else if _, err := p.name(); err != nil {
+		return err
+	}
+
+	if p.peek("!") {
+		return p.advance()
+	}
+
+	return nil
+}
+
+func (p *parser) selectionSet() ([]*Field, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	var fields []*Field
+	for !p.peek("}") {
+		field, err := p.field()
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty selection at %d", p.tok.pos)
+	}
+
+	return fields, p.advance()
+}
+
+func (p *parser) field() (*Field, error) {
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+
+	field := &Field{Name: name}
+
+	if p.peek(":") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+
+		field.Alias = name
+		if field.Name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.peek("(") {
+		if field.Args, err = p.arguments(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.peek("{") {
+		if field.Selections, err = p.selectionSet(); err != nil {
+			return nil, err
+		}
+	}
+
+	return field, nil
+}
+
+func (p *parser) arguments() (map[string]interface{}, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	args := map[string]interface{}{}
+	for !p.peek(")") {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+
+		if args[name], err = p.value(); err != nil {
+			return nil, err
+		}
+	}
+
+	return args, p.advance()
+}
+
+// value parses an argument value.  Numbers are returned as int64 or float64,
+// enum values as strings, lists as []interface{} and input objects as
+// map[string]interface{}.
+func (p *parser) value() (interface{}, error) {
+	tok := p.tok
+
+	switch {
+	case p.peek("$"):
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		return p.variables[name], nil
+	case p.peek("["):
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		list := []interface{}{}
+		for !p.peek("]") {
+			v, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		return list, p.advance()
+	case p.peek("{"):
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		obj := map[string]interface{}{}
+		for !p.peek("}") {
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			if obj[name], err = p.value(); err != nil {
+				return nil, err
+			}
+		}
+		return obj, p.advance()
+	case tok.kind == tokenString:
+		return tok.value, p.advance()
+	case tok.kind == tokenNumber:
+		if i, err := strconv.ParseInt(tok.value, 10, 64); err == nil {
+			return i, p.advance()
+		}
+		f, err := strconv.ParseFloat(tok.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s at %d", tok, tok.pos)
+		}
+		return f, p.advance()
+	case tok.kind == tokenName:
+		var v interface{}
+		switch tok.value {
+		case "true":
+			v = true
+		case "false":
+			v = false
+		case "null":
+			v = nil
+		default:
+			v = tok.value
+		}
+		return v, p.advance()
+	default:
+		return nil, fmt.Errorf("expected a value at %d, found %s", tok.pos, tok)
+	}
+}
@@ -0,0 +1,68 @@
+package graphql
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/stellar/horizon/log"
+	"github.com/stellar/horizon/render/problem"
+	"golang.org/x/net/context"
+)
+
+// Response is the json document returned for a graphql request.
+type Response struct {
+	Data   interface{} `json:"data,omitempty"`
+	Errors []Error     `json:"errors,omitempty"`
+}
+
+// Error is a single error of a Response.  Its extensions carry the type and
+// status of the problem horizon would render for the error elsewhere.
+type Error struct {
+	Message    string                 `json:"message"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// NewError returns the graphql error for err, along with the http status of
+// its problem.
+func NewError(ctx context.Context, err error) (Error, int) {
+	p := problem.Resolve(ctx, err)
+	if p.Status >= http.StatusInternalServerError {
+		log.WithStack(ctx, err).Error(err)
+	}
+
+	message := p.Detail
+	if message == "" {
+		message = p.Title
+	}
+
+	return Error{
+		Message: message,
+		Extensions: map[string]interface{}{
+			"type":   p.Type,
+			"status": p.Status,
+		},
+	}, p.Status
+}
+
+// Render writes the response for data to w or, if err is non-nil, the response
+// for err.
+func Render(ctx context.Context, w http.ResponseWriter, data interface{}, err error) {
+	status := http.StatusOK
+	response := Response{Data: data}
+
+	if err != nil {
+		var gqlErr Error
+		gqlErr, status = NewError(ctx, err)
+		response = Response{Errors: []Error{gqlErr}}
+	}
+
+	js, err := json.MarshalIndent(response, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(js)
+}
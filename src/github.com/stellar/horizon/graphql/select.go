@@ -0,0 +1,110 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-errors/errors"
+)
+
+// Object is a json object whose members are serialized in order, as graphql
+// requires a response's fields to appear in the order they were requested.
+type Object []Member
+
+// Member is a single member of an Object.
+type Member struct {
+	Key   string
+	Value interface{}
+}
+
+// MarshalJSON implements json.Marshaler
+func (o Object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+
+	for i, m := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		key, err := json.Marshal(m.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := json.Marshal(m.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// Select returns the fields of data selected by fields, where data is any
+// value that serializes to json, such as a resource.  Lists are projected
+// element by element.  Fields absent from data are null, and fields whose
+// values are objects must select their own subfields.
+func Select(data interface{}, fields []*Field) (interface{}, error) {
+	js, err := json.Marshal(data)
+	if err != nil {
+		return nil, errors.Wrap(err, 1)
+	}
+
+	var doc interface{}
+	dec := json.NewDecoder(bytes.NewReader(js))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, errors.Wrap(err, 1)
+	}
+
+	return project(doc, fields, "")
+}
+
+func project(value interface{}, fields []*Field, path string) (interface{}, error) {
+	switch v := value.(type) {
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, elem := range v {
+			projected, err := project(elem, fields, path)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = projected
+		}
+		return result, nil
+	case map[string]interface{}:
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("field %q must select subfields", path)
+		}
+
+		result := make(Object, 0, len(fields))
+		for _, field := range fields {
+			projected, err := project(v[field.Name], field.Selections, join(path, field.Name))
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, Member{Key: field.Key(), Value: projected})
+		}
+		return result, nil
+	default:
+		if len(fields) > 0 && v != nil {
+			return nil, fmt.Errorf("field %q has no subfields to select", path)
+		}
+		return v, nil
+	}
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
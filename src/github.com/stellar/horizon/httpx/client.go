@@ -2,6 +2,7 @@ package httpx
 
 import (
 	"net/http"
+	"time"
 
 	"golang.org/x/net/context"
 )
@@ -32,3 +33,15 @@ func ClientContext(parent context.Context, client *http.Client) context.Context
 
 	return context.WithValue(parent, &clientContextKey, client)
 }
+
+// ClientWithTimeout returns client, or a copy of it that gives up on requests
+// after timeout when client has no timeout of its own.
+func ClientWithTimeout(client *http.Client, timeout time.Duration) *http.Client {
+	if client.Timeout != 0 {
+		return client
+	}
+
+	timed := *client
+	timed.Timeout = timeout
+	return &timed
+}
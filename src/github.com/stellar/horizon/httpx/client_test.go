@@ -3,6 +3,7 @@ package httpx
 import (
 	"net/http"
 	"testing"
+	"time"
 
 	"golang.org/x/net/context"
 
@@ -21,6 +22,18 @@ func TestClientContext(t *testing.T) {
 		So(ClientFromContext(ctx), ShouldEqual, c)
 	})
 
+	Convey("ClientWithTimeout", t, func() {
+		// gives a client without a timeout one
+		c := &http.Client{}
+		timed := ClientWithTimeout(c, time.Second)
+		So(timed.Timeout, ShouldEqual, time.Second)
+		So(c.Timeout, ShouldEqual, 0)
+
+		// keeps the timeout a client has
+		c = &http.Client{Timeout: time.Minute}
+		So(ClientWithTimeout(c, time.Second), ShouldEqual, c)
+	})
+
 	Convey("ClientContext panics if nil is used", t, func() {
 		So(func() {
 			ClientContext(context.Background(), nil)
@@ -0,0 +1,124 @@
+package httpx
+
+import (
+	stderr "errors"
+	"net"
+	"strings"
+
+	"golang.org/x/net/context"
+)
+
+// ErrNotPublicHost is returned by ValidatePublicHost for hosts that are not
+// hostnames of the public internet.
+// NOTE: this is not a go-errors based error, as stack traces are unnecessary
+var ErrNotPublicHost = stderr.New("not a public hostname")
+
+// privateSuffixes are the top level domains, and the names, reserved for use
+// within private networks or never to be resolved on the internet.
+var privateSuffixes = []string{
+	"localhost",
+	"local",
+	"localdomain",
+	"internal",
+	"intranet",
+	"lan",
+	"home",
+	"corp",
+	"test",
+	"invalid",
+	"example",
+}
+
+// ValidatePublicHost returns ErrNotPublicHost unless host is a hostname that
+// may be fetched from the public internet: a name of at least two labels of
+// letters, digits and hyphens, with no port, that is neither an IP address nor
+// a name reserved for private networks, such as localhost.  It guards the
+// requests made to hosts that clients or the network name.
+func ValidatePublicHost(host string) error {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+
+	if host == "" || len(host) > 253 || net.ParseIP(host) != nil {
+		return ErrNotPublicHost
+	}
+
+	labels := strings.Split(host, ".")
+	if len(labels) < 2 {
+		return ErrNotPublicHost
+	}
+
+	for _, label := range labels {
+		if !validLabel(label) {
+			return ErrNotPublicHost
+		}
+	}
+
+	tld := labels[len(labels)-1]
+	if strings.Trim(tld, "0123456789") == "" {
+		return ErrNotPublicHost
+	}
+
+	for _, suffix := range privateSuffixes {
+		if tld == suffix {
+			return ErrNotPublicHost
+		}
+	}
+
+	return nil
+}
+
+// ResolvePublicHost is ValidatePublicHost for hosts about to be fetched: it
+// also returns ErrNotPublicHost when any address host resolves to is not
+// routable on the public internet, as is the case of names that point into
+// private networks.
+func ResolvePublicHost(ctx context.Context, host string) error {
+	err := ValidatePublicHost(host)
+	if err != nil {
+		return err
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return err
+	}
+
+	for _, addr := range addrs {
+		if !publicIP(addr.IP) {
+			return ErrNotPublicHost
+		}
+	}
+
+	return nil
+}
+
+// publicIP returns true if ip is routable on the public internet: neither a
+// loopback, link-local, private, multicast nor unspecified address.
+func publicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() ||
+		ip.IsPrivate() ||
+		ip.IsUnspecified())
+}
+
+// validLabel returns true if label is a valid label of a hostname
+func validLabel(label string) bool {
+	if label == "" || len(label) > 63 {
+		return false
+	}
+
+	if label[0] == '-' || label[len(label)-1] == '-' {
+		return false
+	}
+
+	for _, c := range label {
+		switch {
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '-':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,69 @@
+package httpx
+
+import (
+	"net"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestValidatePublicHost(t *testing.T) {
+
+	Convey("ValidatePublicHost", t, func() {
+		Convey("accepts public hostnames", func() {
+			for _, host := range []string{"stellar.org", "www.Stellar.org", "stellar.org.", "my-anchor.co.uk"} {
+				So(ValidatePublicHost(host), ShouldBeNil)
+			}
+		})
+
+		Convey("refuses IP addresses, ports and private names", func() {
+			for _, host := range []string{
+				"",
+				"127.0.0.1",
+				"10.0.0.1",
+				"::1",
+				"[::1]",
+				"169.254.169.254",
+				"localhost",
+				"api.localhost",
+				"printer.local",
+				"metadata.google.internal",
+				"stellar.org:8080",
+				"user@stellar.org",
+				"stellar.org/path",
+				"-bad.org",
+				"1.2.3.4.5",
+			} {
+				So(ValidatePublicHost(host), ShouldEqual, ErrNotPublicHost)
+			}
+		})
+	})
+}
+
+func TestPublicIP(t *testing.T) {
+
+	Convey("publicIP", t, func() {
+		Convey("accepts public addresses", func() {
+			for _, ip := range []string{"8.8.8.8", "151.101.1.69", "2606:4700::1111"} {
+				So(publicIP(net.ParseIP(ip)), ShouldBeTrue)
+			}
+		})
+
+		Convey("refuses loopback, link-local and private addresses", func() {
+			for _, ip := range []string{
+				"127.0.0.1",
+				"::1",
+				"169.254.169.254",
+				"fe80::1",
+				"10.0.0.1",
+				"172.16.5.4",
+				"192.168.1.1",
+				"fd00::1",
+				"0.0.0.0",
+				"224.0.0.1",
+			} {
+				So(publicIP(net.ParseIP(ip)), ShouldBeFalse)
+			}
+		})
+	})
+}
@@ -0,0 +1,40 @@
+package httpx
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+var requestTimeoutKey = 0
+
+// requestTimeout is the timeout of a request, as set by WithRequestTimeout
+type requestTimeout struct {
+	parent context.Context
+	cancel context.CancelFunc
+}
+
+// WithRequestTimeout returns a context derived from parent that is done once
+// timeout passes, as the context of a request that must be answered by then.
+// Requests that turn out to be long-lived, such as streams, lift the timeout
+// with WithoutRequestTimeout.
+func WithRequestTimeout(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	t := &requestTimeout{parent: parent}
+	ctx, cancel := context.WithTimeout(context.WithValue(parent, &requestTimeoutKey, t), timeout)
+	t.cancel = cancel
+	return ctx, cancel
+}
+
+// WithoutRequestTimeout lifts the timeout set upon ctx by WithRequestTimeout,
+// returning the context the request had before it.  Values added to ctx since
+// are not carried over, and ctx itself is cancelled.  A ctx without a request
+// timeout is returned as is.
+func WithoutRequestTimeout(ctx context.Context) context.Context {
+	t, ok := ctx.Value(&requestTimeoutKey).(*requestTimeout)
+	if !ok {
+		return ctx
+	}
+
+	t.cancel()
+	return t.parent
+}
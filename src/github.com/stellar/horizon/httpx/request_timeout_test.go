@@ -0,0 +1,39 @@
+package httpx
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"golang.org/x/net/context"
+)
+
+func TestRequestTimeout(t *testing.T) {
+	Convey("WithRequestTimeout times out its context", t, func() {
+		ctx, cancel := WithRequestTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+
+		<-ctx.Done()
+		So(ctx.Err(), ShouldEqual, context.DeadlineExceeded)
+	})
+
+	Convey("WithoutRequestTimeout lifts the timeout", t, func() {
+		parent, cancelParent := context.WithCancel(context.Background())
+		defer cancelParent()
+
+		ctx, cancel := WithRequestTimeout(parent, time.Millisecond)
+		defer cancel()
+
+		lifted := WithoutRequestTimeout(ctx)
+		So(lifted, ShouldEqual, parent)
+		So(ctx.Err(), ShouldEqual, context.Canceled)
+
+		_, ok := lifted.Deadline()
+		So(ok, ShouldBeFalse)
+	})
+
+	Convey("WithoutRequestTimeout returns contexts without a timeout as is", t, func() {
+		ctx := context.Background()
+		So(WithoutRequestTimeout(ctx), ShouldEqual, ctx)
+	})
+}
@@ -0,0 +1,390 @@
+package ingest
+
+import (
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/go-errors/errors"
+	"github.com/stellar/go-stellar-base/build"
+	"github.com/stellar/go-stellar-base/xdr"
+	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/httpx"
+	"golang.org/x/net/context"
+)
+
+// CheckpointFrequency is the count of ledgers between the checkpoints of a
+// history archive.  Each checkpoint holds the ledgers since the one before it,
+// and the first the ledgers up to 63.
+const CheckpointFrequency = 64
+
+// archiveCacheCheckpoints is the count of checkpoints an ArchiveBackend keeps
+// loaded, enough for the chunks of a few reingestion workers
+const archiveCacheCheckpoints = 16
+
+// ArchiveBackend loads ledgers from a stellar history archive, the checkpoint
+// files stellar-core publishes to S3, GCS or any http server, so that old
+// ledgers can be ingested without a stellar-core that still holds them.
+//
+// Archives hold no transaction meta nor fee changes: transactions ingested
+// from them have neither, and the change_trust operations they hold are told
+// to update their trustline even when they create it.
+type ArchiveBackend struct {
+	// NetworkPassphrase identifies the network of the archive, which the hashes
+	// of the transactions it holds depend on.
+	NetworkPassphrase string
+
+	// Client is the http client archives are fetched with, the one bound to
+	// the context of each request when nil.
+	Client *http.Client
+
+	root string
+	dir  string
+
+	lock   sync.Mutex
+	cache  map[int32]map[int32]Ledger
+	cached []int32
+}
+
+// NewArchiveBackend returns a backend loading ledgers from the history archive
+// at rawurl, of the network identified by passphrase.  Archives are read over
+// http(s), from local files as "file:///path" and from public S3 and GCS
+// buckets as "s3://bucket/path" and "gs://bucket/path".
+func NewArchiveBackend(rawurl, passphrase string) (*ArchiveBackend, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, errors.Wrap(err, 1)
+	}
+
+	b := &ArchiveBackend{NetworkPassphrase: passphrase}
+	path := strings.TrimSuffix(u.Path, "/")
+
+	switch u.Scheme {
+	case "http", "https":
+		b.root = strings.TrimSuffix(rawurl, "/")
+	case "s3":
+		b.root = "https://" + u.Host + ".s3.amazonaws.com" + path
+	case "gs":
+		b.root = "https://storage.googleapis.com/" + u.Host + path
+	case "file":
+		b.dir = filepath.FromSlash(u.Path)
+	default:
+		return nil, errors.Errorf("unsupported history archive: %s", rawurl)
+	}
+
+	return b, nil
+}
+
+// LatestLedger is a method for Backend, returning the current ledger of the
+// archive, the latest ledger of its latest checkpoint.
+func (b *ArchiveBackend) LatestLedger(ctx context.Context) (int32, error) {
+	var state struct {
+		CurrentLedger int32 `json:"currentLedger"`
+	}
+
+	err := b.fetch(ctx, ".well-known/stellar-history.json", func(r io.Reader) error {
+		return json.NewDecoder(r).Decode(&state)
+	})
+	if err == ErrLedgerMissing {
+		return 0, errors.Errorf("no history archive state in %s", b.location())
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, 1)
+	}
+
+	return state.CurrentLedger, nil
+}
+
+// FirstLedger is a method for Backend.  Archives hold every ledger up to their
+// current one, from the genesis ledger.
+func (b *ArchiveBackend) FirstLedger(ctx context.Context, seq int32) (int32, error) {
+	latest, err := b.LatestLedger(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if seq < 1 {
+		seq = 1
+	}
+
+	if seq > latest {
+		return 0, nil
+	}
+
+	return seq, nil
+}
+
+// Ledger is a method for Backend, loading the checkpoint holding the ledger.
+func (b *ArchiveBackend) Ledger(ctx context.Context, seq int32) (Ledger, error) {
+	ledgers, err := b.checkpoint(ctx, checkpointOf(seq))
+	if err != nil {
+		return Ledger{}, err
+	}
+
+	l, ok := ledgers[seq]
+	if !ok {
+		return Ledger{}, ErrLedgerMissing
+	}
+
+	return l, nil
+}
+
+// checkpoint returns the ledgers of the checkpoint cp, by sequence, loading
+// them from the archive unless cached.
+func (b *ArchiveBackend) checkpoint(ctx context.Context, cp int32) (map[int32]Ledger, error) {
+	b.lock.Lock()
+	ledgers, ok := b.cache[cp]
+	b.lock.Unlock()
+	if ok {
+		return ledgers, nil
+	}
+
+	ledgers, err := b.loadCheckpoint(ctx, cp)
+	if err != nil {
+		return nil, err
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.cache == nil {
+		b.cache = map[int32]map[int32]Ledger{}
+	}
+	if _, ok := b.cache[cp]; !ok {
+		if len(b.cached) == archiveCacheCheckpoints {
+			delete(b.cache, b.cached[0])
+			b.cached = b.cached[1:]
+		}
+		b.cache[cp] = ledgers
+		b.cached = append(b.cached, cp)
+	}
+
+	return ledgers, nil
+}
+
+// loadCheckpoint loads the ledgers of the checkpoint cp from the ledger,
+// transactions and results files of the archive.  The transactions of each
+// ledger are ordered as applied, the order of their results.
+func (b *ArchiveBackend) loadCheckpoint(ctx context.Context, cp int32) (map[int32]Ledger, error) {
+	ledgers := map[int32]Ledger{}
+
+	err := b.fetchRecords(ctx, checkpointPath("ledger", cp), func(record []byte) error {
+		var entry xdr.LedgerHeaderHistoryEntry
+		err := xdr.SafeUnmarshal(record, &entry)
+		if err != nil {
+			return errors.Wrap(err, 1)
+		}
+
+		data, err := xdr.MarshalBase64(entry.Header)
+		if err != nil {
+			return errors.Wrap(err, 1)
+		}
+
+		seq := int32(entry.Header.LedgerSeq)
+		ledgers[seq] = Ledger{Header: db.CoreLedgerHeaderRecord{
+			LedgerHash: hex.EncodeToString(entry.Hash[:]),
+			Sequence:   seq,
+			CloseTime:  int64(entry.Header.ScpValue.CloseTime),
+			DataXDR:    data,
+		}}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	envelopes := map[int32]map[xdr.Hash]xdr.TransactionEnvelope{}
+	err = b.fetchRecords(ctx, checkpointPath("transactions", cp), func(record []byte) error {
+		var entry xdr.TransactionHistoryEntry
+		err := xdr.SafeUnmarshal(record, &entry)
+		if err != nil {
+			return errors.Wrap(err, 1)
+		}
+
+		byHash := map[xdr.Hash]xdr.TransactionEnvelope{}
+		for _, env := range entry.TxSet.Txs {
+			txb := build.TransactionBuilder{TX: &env.Tx}
+			txb.Mutate(build.Network{Passphrase: b.NetworkPassphrase})
+
+			hash, err := txb.Hash()
+			if err != nil {
+				return errors.Wrap(err, 1)
+			}
+			byHash[xdr.Hash(hash)] = env
+		}
+
+		envelopes[int32(entry.LedgerSeq)] = byHash
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = b.fetchRecords(ctx, checkpointPath("results", cp), func(record []byte) error {
+		var entry xdr.TransactionHistoryResultEntry
+		err := xdr.SafeUnmarshal(record, &entry)
+		if err != nil {
+			return errors.Wrap(err, 1)
+		}
+
+		seq := int32(entry.LedgerSeq)
+		l, ok := ledgers[seq]
+		if !ok {
+			return errors.Errorf("results of ledger %d missing from checkpoint %d", seq, cp)
+		}
+
+		for i, result := range entry.TxResultSet.Results {
+			env, ok := envelopes[seq][result.TransactionHash]
+			if !ok {
+				return errors.Errorf("transaction %x of ledger %d missing from checkpoint %d", result.TransactionHash[:], seq, cp)
+			}
+
+			envXDR, err := xdr.MarshalBase64(env)
+			if err != nil {
+				return errors.Wrap(err, 1)
+			}
+
+			resultXDR, err := xdr.MarshalBase64(result)
+			if err != nil {
+				return errors.Wrap(err, 1)
+			}
+
+			l.Transactions = append(l.Transactions, db.CoreTransactionRecord{
+				TransactionHash: hex.EncodeToString(result.TransactionHash[:]),
+				LedgerSequence:  seq,
+				Index:           int32(i + 1),
+				EnvelopeXDR:     envXDR,
+				ResultXDR:       resultXDR,
+			})
+		}
+
+		ledgers[seq] = l
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ledgers, nil
+}
+
+// fetchRecords calls fn with each of the records of the gzipped xdr stream at
+// path within the archive
+func (b *ArchiveBackend) fetchRecords(ctx context.Context, path string, fn func(record []byte) error) error {
+	return b.fetch(ctx, path, func(r io.Reader) error {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return errors.Wrap(err, 1)
+		}
+		defer gz.Close()
+
+		return readRecords(gz, fn)
+	})
+}
+
+// fetch calls fn with the contents of the file at path within the archive,
+// returning ErrLedgerMissing when the archive has no such file.
+func (b *ArchiveBackend) fetch(ctx context.Context, path string, fn func(r io.Reader) error) error {
+	if b.dir != "" {
+		f, err := os.Open(filepath.Join(b.dir, filepath.FromSlash(path)))
+		if os.IsNotExist(err) {
+			return ErrLedgerMissing
+		}
+		if err != nil {
+			return errors.Wrap(err, 1)
+		}
+		defer f.Close()
+
+		return fn(f)
+	}
+
+	resp, err := b.client(ctx).Get(b.root + "/" + path)
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden:
+		// S3 and GCS answer 403 for the objects missing from public buckets
+		return ErrLedgerMissing
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return errors.Errorf("history archive responded with status %d for %s", resp.StatusCode, path)
+	}
+
+	return fn(resp.Body)
+}
+
+func (b *ArchiveBackend) client(ctx context.Context) *http.Client {
+	if b.Client == nil {
+		return httpx.ClientFromContext(ctx)
+	}
+
+	return b.Client
+}
+
+// location returns where the archive is, for errors
+func (b *ArchiveBackend) location() string {
+	if b.dir != "" {
+		return b.dir
+	}
+
+	return b.root
+}
+
+// readRecords calls fn with each of the records of the xdr stream r, each
+// made of fragments preceded by their length, the last of which has the high
+// bit of its length set.
+func readRecords(r io.Reader, fn func(record []byte) error) error {
+	for {
+		var record []byte
+		for {
+			var mark uint32
+			err := binary.Read(r, binary.BigEndian, &mark)
+			if err == io.EOF && record == nil {
+				return nil
+			}
+			if err != nil {
+				return errors.Wrap(err, 1)
+			}
+
+			fragment := make([]byte, mark&0x7fffffff)
+			_, err = io.ReadFull(r, fragment)
+			if err != nil {
+				return errors.Wrap(err, 1)
+			}
+
+			record = append(record, fragment...)
+			if mark&0x80000000 != 0 {
+				break
+			}
+		}
+
+		err := fn(record)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// checkpointOf returns the checkpoint holding the ledger seq, the sequence of
+// the last ledger within it
+func checkpointOf(seq int32) int32 {
+	return (seq/CheckpointFrequency+1)*CheckpointFrequency - 1
+}
+
+// checkpointPath returns the path within an archive of the file of category,
+// such as "ledger" or "transactions", of the checkpoint cp
+func checkpointPath(category string, cp int32) string {
+	name := fmt.Sprintf("%08x", uint32(cp))
+	return fmt.Sprintf("%s/%s/%s/%s/%s-%s.xdr.gz", category, name[0:2], name[2:4], name[4:6], category, name)
+}
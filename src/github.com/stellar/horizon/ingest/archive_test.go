@@ -0,0 +1,195 @@
+package ingest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/go-stellar-base/build"
+	"github.com/stellar/go-stellar-base/strkey"
+	"github.com/stellar/go-stellar-base/xdr"
+	"golang.org/x/net/context"
+)
+
+func TestArchiveBackend(t *testing.T) {
+	const source = "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H"
+	passphrase := build.TestNetwork.Passphrase
+	ctx := context.Background()
+
+	// writeStream writes records as the gzipped xdr stream at path within dir
+	writeStream := func(dir, path string, records ...interface{}) {
+		var raw bytes.Buffer
+		for _, record := range records {
+			var buf bytes.Buffer
+			_, err := xdr.Marshal(&buf, record)
+			So(err, ShouldBeNil)
+			So(binary.Write(&raw, binary.BigEndian, uint32(buf.Len())|0x80000000), ShouldBeNil)
+			raw.Write(buf.Bytes())
+		}
+
+		var gz bytes.Buffer
+		w := gzip.NewWriter(&gz)
+		w.Write(raw.Bytes())
+		So(w.Close(), ShouldBeNil)
+
+		file := filepath.Join(dir, filepath.FromSlash(path))
+		So(os.MkdirAll(filepath.Dir(file), 0755), ShouldBeNil)
+		So(ioutil.WriteFile(file, gz.Bytes(), 0644), ShouldBeNil)
+	}
+
+	tx := func(seq xdr.SequenceNumber) (xdr.TransactionEnvelope, xdr.Hash) {
+		var key xdr.Uint256
+		copy(key[:], strkey.MustDecode(strkey.VersionByteAccountID, source))
+		aid, err := xdr.NewAccountId(xdr.CryptoKeyTypeKeyTypeEd25519, key)
+		So(err, ShouldBeNil)
+
+		env := xdr.TransactionEnvelope{Tx: xdr.Transaction{SourceAccount: aid, Fee: 100, SeqNum: seq}}
+		txb := build.TransactionBuilder{TX: &env.Tx}
+		txb.Mutate(build.Network{Passphrase: passphrase})
+		hash, err := txb.Hash()
+		So(err, ShouldBeNil)
+		return env, xdr.Hash(hash)
+	}
+
+	result := func(hash xdr.Hash) xdr.TransactionResultPair {
+		return xdr.TransactionResultPair{
+			TransactionHash: hash,
+			Result: xdr.TransactionResult{
+				FeeCharged: 100,
+				Result: xdr.TransactionResultResult{
+					Code:    xdr.TransactionResultCodeTxSuccess,
+					Results: &[]xdr.OperationResult{},
+				},
+			},
+		}
+	}
+
+	// archive writes an archive whose current ledger is 3, the second ledger
+	// holding two transactions applied in the reverse order of its tx set.
+	archive := func() string {
+		dir, err := ioutil.TempDir("", "horizon-archive")
+		So(err, ShouldBeNil)
+		Reset(func() { os.RemoveAll(dir) })
+
+		has := filepath.Join(dir, ".well-known", "stellar-history.json")
+		So(os.MkdirAll(filepath.Dir(has), 0755), ShouldBeNil)
+		So(ioutil.WriteFile(has, []byte(`{"version": 1, "currentLedger": 3}`), 0644), ShouldBeNil)
+
+		var headers []interface{}
+		for seq := 1; seq <= 3; seq++ {
+			headers = append(headers, xdr.LedgerHeaderHistoryEntry{
+				Hash: xdr.Hash{byte(seq)},
+				Header: xdr.LedgerHeader{
+					LedgerSeq: xdr.Uint32(seq),
+					ScpValue:  xdr.StellarValue{CloseTime: xdr.Uint64(1000 + seq)},
+				},
+			})
+		}
+		writeStream(dir, "ledger/00/00/00/ledger-0000003f.xdr.gz", headers...)
+
+		first, firstHash := tx(1)
+		second, secondHash := tx(2)
+		writeStream(dir, "transactions/00/00/00/transactions-0000003f.xdr.gz", xdr.TransactionHistoryEntry{
+			LedgerSeq: 2,
+			TxSet:     xdr.TransactionSet{Txs: []xdr.TransactionEnvelope{first, second}},
+		})
+		writeStream(dir, "results/00/00/00/results-0000003f.xdr.gz", xdr.TransactionHistoryResultEntry{
+			LedgerSeq:   2,
+			TxResultSet: xdr.TransactionResultSet{Results: []xdr.TransactionResultPair{result(secondHash), result(firstHash)}},
+		})
+
+		return dir
+	}
+
+	Convey("ArchiveBackend", t, func() {
+		dir := archive()
+
+		check := func(b *ArchiveBackend) {
+			latest, err := b.LatestLedger(ctx)
+			So(err, ShouldBeNil)
+			So(latest, ShouldEqual, 3)
+
+			first, err := b.FirstLedger(ctx, 0)
+			So(err, ShouldBeNil)
+			So(first, ShouldEqual, 1)
+
+			first, err = b.FirstLedger(ctx, 4)
+			So(err, ShouldBeNil)
+			So(first, ShouldEqual, 0)
+
+			l, err := b.Ledger(ctx, 1)
+			So(err, ShouldBeNil)
+			So(l.Header.Sequence, ShouldEqual, 1)
+			So(l.Header.CloseTime, ShouldEqual, 1001)
+			So(l.Header.LedgerHash, ShouldEqual, "01"+strings.Repeat("00", 31))
+			So(l.Transactions, ShouldBeEmpty)
+
+			header, err := l.Header.Header()
+			So(err, ShouldBeNil)
+			So(header.LedgerSeq, ShouldEqual, 1)
+
+			l, err = b.Ledger(ctx, 2)
+			So(err, ShouldBeNil)
+			So(len(l.Transactions), ShouldEqual, 2)
+
+			_, secondHash := tx(2)
+			So(l.Transactions[0].TransactionHash, ShouldEqual, hex.EncodeToString(secondHash[:]))
+			So(l.Transactions[0].Index, ShouldEqual, 1)
+			So(l.Transactions[0].LedgerSequence, ShouldEqual, 2)
+			So(l.Transactions[0].ResultMetaXDR, ShouldEqual, "")
+
+			var env xdr.TransactionEnvelope
+			So(xdr.SafeUnmarshalBase64(l.Transactions[0].EnvelopeXDR, &env), ShouldBeNil)
+			So(env.Tx.SeqNum, ShouldEqual, 2)
+			So(l.Transactions[1].Index, ShouldEqual, 2)
+
+			_, err = b.Ledger(ctx, 64)
+			So(err, ShouldEqual, ErrLedgerMissing)
+		}
+
+		Convey("loads ledgers from local archives", func() {
+			b, err := NewArchiveBackend("file://"+filepath.ToSlash(dir), passphrase)
+			So(err, ShouldBeNil)
+			check(b)
+		})
+
+		Convey("loads ledgers from archives served over http", func() {
+			server := httptest.NewServer(http.FileServer(http.Dir(dir)))
+			defer server.Close()
+
+			b, err := NewArchiveBackend(server.URL+"/", passphrase)
+			So(err, ShouldBeNil)
+			check(b)
+		})
+	})
+
+	Convey("NewArchiveBackend", t, func() {
+		b, err := NewArchiveBackend("s3://history.stellar.org/prd/core-live/core_live_001/", passphrase)
+		So(err, ShouldBeNil)
+		So(b.root, ShouldEqual, "https://history.stellar.org.s3.amazonaws.com/prd/core-live/core_live_001")
+
+		b, err = NewArchiveBackend("gs://archive/testnet", passphrase)
+		So(err, ShouldBeNil)
+		So(b.root, ShouldEqual, "https://storage.googleapis.com/archive/testnet")
+
+		_, err = NewArchiveBackend("ftp://archive", passphrase)
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("checkpointPath", t, func() {
+		So(checkpointOf(1), ShouldEqual, 63)
+		So(checkpointOf(63), ShouldEqual, 63)
+		So(checkpointOf(64), ShouldEqual, 127)
+		So(checkpointPath("ledger", 63), ShouldEqual, "ledger/00/00/00/ledger-0000003f.xdr.gz")
+		So(checkpointPath("results", 0x1234567f), ShouldEqual, "results/12/34/56/results-1234567f.xdr.gz")
+	})
+}
@@ -0,0 +1,82 @@
+package ingest
+
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/stellar/horizon/db"
+	"golang.org/x/net/context"
+)
+
+// Backend is a source of the ledgers a System ingests
+type Backend interface {
+	// LatestLedger returns the sequence of the latest ledger the backend has, 0
+	// when it has none.
+	LatestLedger(ctx context.Context) (int32, error)
+
+	// FirstLedger returns the sequence of the first ledger the backend has at or
+	// after seq, 0 when it has none.
+	FirstLedger(ctx context.Context, seq int32) (int32, error)
+
+	// Ledger loads the ledger of sequence seq, returning ErrLedgerMissing when
+	// the backend lacks it.
+	Ledger(ctx context.Context, seq int32) (Ledger, error)
+}
+
+// Ledger is a ledger as loaded from a Backend: its header, its transactions in
+// the order applied and their fee changes, in the form stellar-core keeps them
+// in its database.
+type Ledger struct {
+	Header       db.CoreLedgerHeaderRecord
+	Transactions []db.CoreTransactionRecord
+	Fees         []db.CoreTransactionFeeRecord
+}
+
+// CoreBackend loads ledgers from the database of stellar-core.  It is the
+// backend of a System that has none set.
+type CoreBackend struct {
+	DB *sqlx.DB
+}
+
+// LatestLedger is a method for Backend
+func (b *CoreBackend) LatestLedger(ctx context.Context) (int32, error) {
+	var seq int32
+	err := b.core().GetRaw(ctx, "SELECT COALESCE(MAX(ledgerseq), 0) FROM ledgerheaders", nil, &seq)
+	return seq, err
+}
+
+// FirstLedger is a method for Backend
+func (b *CoreBackend) FirstLedger(ctx context.Context, seq int32) (int32, error) {
+	var first int32
+	err := b.core().GetRaw(ctx,
+		"SELECT COALESCE(MIN(ledgerseq), 0) FROM ledgerheaders WHERE ledgerseq >= $1",
+		[]interface{}{seq},
+		&first,
+	)
+	return first, err
+}
+
+// Ledger is a method for Backend
+func (b *CoreBackend) Ledger(ctx context.Context, seq int32) (l Ledger, err error) {
+	var headers []db.CoreLedgerHeaderRecord
+	err = db.Select(ctx, db.CoreLedgerHeadersBySequenceQuery{SqlQuery: b.core(), Sequences: []int32{seq}}, &headers)
+	if err != nil {
+		return
+	}
+
+	if len(headers) == 0 {
+		err = ErrLedgerMissing
+		return
+	}
+	l.Header = headers[0]
+
+	err = db.Select(ctx, db.CoreTransactionsByLedgerQuery{SqlQuery: b.core(), LedgerSequence: seq}, &l.Transactions)
+	if err != nil {
+		return
+	}
+
+	err = db.Select(ctx, db.CoreTransactionFeesByLedgerQuery{SqlQuery: b.core(), LedgerSequence: seq}, &l.Fees)
+	return
+}
+
+func (b *CoreBackend) core() db.SqlQuery {
+	return db.SqlQuery{DB: b.DB}
+}
@@ -0,0 +1,157 @@
+package ingest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-errors/errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/stellar/horizon/db"
+)
+
+// batchRows is the count of rows a batch holds before it is written
+const batchRows = 5000
+
+// maxInsertParams is the most parameters postgres binds to a single statement
+const maxInsertParams = 65535
+
+// batch holds the rows inserted into a table, to be written at once
+type batch struct {
+	table   string
+	columns []string
+	rows    [][]interface{}
+}
+
+// writer writes the rows of history within the db transaction tx in batches,
+// one per table, with COPY when the dialect of the database allows and with
+// multi-row inserts otherwise.  Far fewer round trips are made than when
+// inserting each row on its own, the most of ingestion's time when
+// reingesting.  Rows are held until flushed, so that whatever reads history
+// within tx must flush the writer first.
+type writer struct {
+	tx      *sqlx.Tx
+	copy    bool
+	batches map[string]*batch
+	order   []*batch
+}
+
+// newWriter returns a writer of the rows of history within tx, a transaction
+// of the database d.
+func newWriter(d *sqlx.DB, tx *sqlx.Tx) *writer {
+	return &writer{
+		tx:      tx,
+		copy:    db.DialectOf(d).CopyIn(),
+		batches: map[string]*batch{},
+	}
+}
+
+// add adds a row of values for columns to the batch of table, writing the
+// batch once full.
+func (w *writer) add(table string, columns []string, values ...interface{}) error {
+	b, ok := w.batches[table]
+	if !ok {
+		b = &batch{table: table, columns: columns}
+		w.batches[table] = b
+		w.order = append(w.order, b)
+	}
+
+	if len(values) != len(b.columns) {
+		return errors.Errorf("%d values for the %d columns of %s", len(values), len(b.columns), table)
+	}
+
+	b.rows = append(b.rows, values)
+	if len(b.rows) < batchRows {
+		return nil
+	}
+
+	return w.write(b)
+}
+
+// flush writes the rows held, in the order their tables were first added to
+func (w *writer) flush() error {
+	for _, b := range w.order {
+		err := w.write(b)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// write writes the rows of b, emptying it
+func (w *writer) write(b *batch) error {
+	if len(b.rows) == 0 {
+		return nil
+	}
+
+	var err error
+	if w.copy {
+		err = w.copyIn(b)
+	} else {
+		err = w.insert(b)
+	}
+	if err != nil {
+		return err
+	}
+
+	b.rows = b.rows[:0]
+	return nil
+}
+
+func (w *writer) copyIn(b *batch) error {
+	stmt, err := w.tx.Prepare(pq.CopyIn(b.table, b.columns...))
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+	defer stmt.Close()
+
+	for _, row := range b.rows {
+		_, err = stmt.Exec(row...)
+		if err != nil {
+			return errors.Wrap(err, 1)
+		}
+	}
+
+	_, err = stmt.Exec()
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	return nil
+}
+
+func (w *writer) insert(b *batch) error {
+	columns := make([]string, len(b.columns))
+	for i, column := range b.columns {
+		columns[i] = pq.QuoteIdentifier(column)
+	}
+	prefix := fmt.Sprintf("INSERT INTO %s (%s) VALUES ", b.table, strings.Join(columns, ", "))
+	perStatement := maxInsertParams / len(b.columns)
+
+	for start := 0; start < len(b.rows); start += perStatement {
+		end := start + perStatement
+		if end > len(b.rows) {
+			end = len(b.rows)
+		}
+
+		values := make([]string, 0, end-start)
+		args := make([]interface{}, 0, (end-start)*len(b.columns))
+		for _, row := range b.rows[start:end] {
+			placeholders := make([]string, len(row))
+			for i := range row {
+				placeholders[i] = fmt.Sprintf("$%d", len(args)+i+1)
+			}
+			values = append(values, "("+strings.Join(placeholders, ", ")+")")
+			args = append(args, row...)
+		}
+
+		_, err := w.tx.Exec(prefix+strings.Join(values, ", "), args...)
+		if err != nil {
+			return errors.Wrap(err, 1)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package ingest
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/test"
+)
+
+func TestWriter(t *testing.T) {
+	horizon := test.OpenDatabase(test.DatabaseUrl())
+	defer horizon.Close()
+
+	Convey("writer", t, func() {
+		test.LoadScenario("base")
+		horizon.MustExec("DELETE FROM history_transactions")
+
+		tx := horizon.MustBegin()
+		defer tx.Rollback()
+
+		w := newWriter(horizon, tx)
+		now := time.Now().UTC()
+		row := func(id int64, bounds interface{}) []interface{} {
+			return []interface{}{
+				id, "hash", int32(1), int32(id), "account", int64(1), int32(100), int32(100), int32(1),
+				"envelope", "result", "meta", "fee meta", "{sig1,sig2}", "none", nil, bounds, now, now,
+			}
+		}
+
+		write := func() {
+			So(w.add("history_transactions", transactionColumns, row(1, timeBounds(nil))...), ShouldBeNil)
+			So(w.add("history_transactions", transactionColumns, row(2, "[10,)")...), ShouldBeNil)
+			So(w.add("history_transactions", transactionColumns, row(3, "[10,20)")...), ShouldBeNil)
+			So(w.flush(), ShouldBeNil)
+
+			var upper []bool
+			err := tx.Select(&upper, "SELECT upper_inf(time_bounds) FROM history_transactions WHERE time_bounds IS NOT NULL ORDER BY id")
+			So(err, ShouldBeNil)
+			So(upper, ShouldResemble, []bool{true, false})
+
+			var signatures []int
+			err = tx.Select(&signatures, "SELECT array_length(signatures, 1) FROM history_transactions ORDER BY id")
+			So(err, ShouldBeNil)
+			So(signatures, ShouldResemble, []int{2, 2, 2})
+		}
+
+		Convey("copies rows in", func() {
+			So(w.copy, ShouldBeTrue)
+			write()
+		})
+
+		Convey("inserts rows many at once", func() {
+			w.copy = false
+			write()
+		})
+
+		Convey("rejects rows of the wrong width", func() {
+			So(w.add("history_accounts", accountColumns, int64(1)), ShouldNotBeNil)
+		})
+	})
+}
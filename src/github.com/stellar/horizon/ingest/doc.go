@@ -0,0 +1,18 @@
+// Package ingest imports the ledgers stellar-core closes into horizon's history
+// database.  The ingestion of each ledger, from its header to the effects of
+// its operations, is written in a single database transaction, so that
+// history never holds part of a ledger and ingestion resumes after restarts
+// from the last ledger written.
+package ingest
+
+// Package layout:
+// - main.go: ingest.System, which tails stellar-core for newly closed ledgers
+// - backend.go: the sources ledgers are loaded from, stellar-core's database by default
+// - archive.go: the loading of ledgers from the checkpoints of history archives
+// - ingestion.go: the writing of a ledger's history within a db transaction
+// - gaps.go: the detection and backfilling of the ledgers missing from history
+// - reingest.go: the rewriting of the history of a range of ledgers by concurrent workers
+// - processors.go: the processors deciding which transactions history indexes
+// - trustlines.go: the current_trustlines snapshot kept from the trustline changes ingested
+// - signers.go: the current_signers index kept from the account changes ingested
+// - verify.go: the comparison of the accounts recomputed from history with stellar-core
@@ -0,0 +1,240 @@
+package ingest
+
+import (
+	"time"
+
+	"github.com/stellar/horizon/log"
+	"golang.org/x/net/context"
+)
+
+// Gap is a range of ledgers missing from history, from Start to End
+// inclusive.
+type Gap struct {
+	Start int32 `db:"start" json:"start"`
+	End   int32 `db:"end" json:"end"`
+}
+
+// Len returns the count of ledgers missing in the gap
+func (g Gap) Len() int32 {
+	return g.End - g.Start + 1
+}
+
+// Progress describes the backfilling of the gaps in history
+type Progress struct {
+	// Gaps are the gaps remaining, oldest first
+	Gaps []Gap
+
+	// Missing is the count of ledgers within Gaps
+	Missing int32
+
+	// Backfilled is the count of ledgers backfilled since the system started
+	Backfilled int
+
+	// DetectedAt is when the gaps were last detected from the history database,
+	// zero until the first backfill.
+	DetectedAt time.Time
+
+	// Err is the error that stopped the latest backfill, if any
+	Err error
+}
+
+// Progress returns the progress of the backfilling of the gaps in history
+func (sys *System) Progress() Progress {
+	sys.gapLock.Lock()
+	defer sys.gapLock.Unlock()
+
+	p := Progress{
+		Gaps:       append([]Gap{}, sys.gaps...),
+		Backfilled: sys.backfilled,
+		DetectedAt: sys.detectedAt,
+		Err:        sys.backfillErr,
+	}
+
+	for _, g := range p.Gaps {
+		p.Missing += g.Len()
+	}
+
+	return p
+}
+
+// DetectGaps returns the gaps between the ledgers history holds, oldest
+// first.  The ledgers before the first one in history are not a gap: history
+// starts where stellar-core did, or where its older ledgers were reaped.
+func (sys *System) DetectGaps(ctx context.Context) ([]Gap, error) {
+	var gaps []Gap
+	err := sys.history().SelectRaw(ctx, `
+		SELECT prev + 1 AS start, sequence - 1 AS "end"
+		FROM (
+			SELECT sequence, LAG(sequence) OVER (ORDER BY sequence) AS prev
+			FROM history_ledgers
+		) l
+		WHERE sequence > prev + 1
+		ORDER BY sequence ASC`, nil, &gaps)
+	if err != nil {
+		return nil, err
+	}
+
+	return gaps, nil
+}
+
+// Backfill ingests the ledgers missing from history, oldest first, up to
+// MaxLedgersPerTick of them, returning the count of ledgers backfilled.  It
+// detects the gaps in history on its first run; later gaps are recorded by
+// Tick as it skips them.  Ledgers the backend lacks are left in their gap, to
+// be retried by later backfills.
+func (sys *System) Backfill(ctx context.Context) (n int, err error) {
+	if sys.Paused() || sys.guarded() {
+		return
+	}
+
+	defer func() {
+		sys.gapLock.Lock()
+		sys.backfilled += n
+		sys.backfillErr = err
+		sys.gapLock.Unlock()
+	}()
+
+	gaps, err := sys.pendingGaps(ctx)
+	if err != nil {
+		return
+	}
+
+	max := sys.maxLedgersPerTick()
+	for _, gap := range gaps {
+		if n >= max {
+			return
+		}
+
+		var seq int32
+		seq, err = sys.backend().FirstLedger(ctx, gap.Start)
+		if err != nil {
+			return
+		}
+
+		for seq != 0 && seq <= gap.End && n < max && !sys.Paused() {
+			var ingested bool
+			ingested, err = sys.ingestLedger(ctx, seq)
+			if err == ErrLedgerMissing {
+				seq, err = sys.backend().FirstLedger(ctx, seq+1)
+				if err != nil {
+					return
+				}
+				continue
+			}
+			if err != nil {
+				return
+			}
+
+			sys.fill(seq)
+			if ingested {
+				n++
+			}
+			seq++
+		}
+	}
+
+	return
+}
+
+// pendingGaps returns the gaps left to backfill, detecting them from history
+// when they have yet to be.  The gaps before the first ledger in history, as
+// left by reaping, are dropped.
+func (sys *System) pendingGaps(ctx context.Context) ([]Gap, error) {
+	var elder int32
+	err := sys.history().GetRaw(ctx, "SELECT COALESCE(MIN(sequence), 0) FROM history_ledgers", nil, &elder)
+	if err != nil {
+		return nil, err
+	}
+	sys.forget(elder)
+
+	sys.gapLock.Lock()
+	detected := !sys.detectedAt.IsZero()
+	sys.gapLock.Unlock()
+
+	if !detected {
+		gaps, err := sys.DetectGaps(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, gap := range gaps {
+			sys.recordGap(ctx, gap)
+		}
+
+		sys.gapLock.Lock()
+		sys.detectedAt = time.Now()
+		sys.gapLock.Unlock()
+	}
+
+	sys.gapLock.Lock()
+	defer sys.gapLock.Unlock()
+	return append([]Gap{}, sys.gaps...), nil
+}
+
+// recordGap adds gap to the gaps left to backfill, keeping them in order
+func (sys *System) recordGap(ctx context.Context, gap Gap) {
+	sys.gapLock.Lock()
+	defer sys.gapLock.Unlock()
+
+	for _, g := range sys.gaps {
+		if g == gap {
+			return
+		}
+	}
+
+	i := len(sys.gaps)
+	for i > 0 && sys.gaps[i-1].Start > gap.Start {
+		i--
+	}
+	sys.gaps = append(sys.gaps, Gap{})
+	copy(sys.gaps[i+1:], sys.gaps[i:])
+	sys.gaps[i] = gap
+
+	log.WithField(ctx, "start", gap.Start).
+		WithField("end", gap.End).
+		Warn("gap in history")
+}
+
+// forget removes the ledgers before seq from the gaps left to backfill
+func (sys *System) forget(seq int32) {
+	sys.gapLock.Lock()
+	defer sys.gapLock.Unlock()
+
+	var gaps []Gap
+	for _, g := range sys.gaps {
+		if g.End < seq {
+			continue
+		}
+
+		if g.Start < seq {
+			g.Start = seq
+		}
+		gaps = append(gaps, g)
+	}
+
+	sys.gaps = gaps
+}
+
+// fill removes the ledger seq from the gaps left to backfill, splitting the
+// gap it falls within when needed.
+func (sys *System) fill(seq int32) {
+	sys.gapLock.Lock()
+	defer sys.gapLock.Unlock()
+
+	var gaps []Gap
+	for _, g := range sys.gaps {
+		if seq < g.Start || seq > g.End {
+			gaps = append(gaps, g)
+			continue
+		}
+
+		if seq > g.Start {
+			gaps = append(gaps, Gap{Start: g.Start, End: seq - 1})
+		}
+		if seq < g.End {
+			gaps = append(gaps, Gap{Start: seq + 1, End: g.End})
+		}
+	}
+
+	sys.gaps = gaps
+}
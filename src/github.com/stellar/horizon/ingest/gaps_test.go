@@ -0,0 +1,41 @@
+package ingest
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/test"
+)
+
+func TestGaps(t *testing.T) {
+	ctx := test.Context()
+
+	Convey("System gaps", t, func() {
+		sys := &System{}
+
+		Convey("are recorded in order, once", func() {
+			sys.recordGap(ctx, Gap{Start: 10, End: 12})
+			sys.recordGap(ctx, Gap{Start: 2, End: 5})
+			sys.recordGap(ctx, Gap{Start: 10, End: 12})
+
+			p := sys.Progress()
+			So(p.Gaps, ShouldResemble, []Gap{{Start: 2, End: 5}, {Start: 10, End: 12}})
+			So(p.Missing, ShouldEqual, 7)
+		})
+
+		Convey("shrink as their ledgers are filled", func() {
+			sys.recordGap(ctx, Gap{Start: 2, End: 5})
+			sys.recordGap(ctx, Gap{Start: 7, End: 7})
+
+			sys.fill(2)
+			sys.fill(4)
+			sys.fill(7)
+			So(sys.Progress().Gaps, ShouldResemble, []Gap{{Start: 3, End: 3}, {Start: 5, End: 5}})
+
+			sys.fill(3)
+			sys.fill(5)
+			So(sys.Progress().Gaps, ShouldBeEmpty)
+			So(sys.Progress().Missing, ShouldEqual, 0)
+		})
+	})
+}
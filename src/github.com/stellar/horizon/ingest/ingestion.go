@@ -0,0 +1,547 @@
+package ingest
+
+import (
+	"database/sql"
+	"encoding/hex"
+	stderr "errors"
+	"fmt"
+	"time"
+
+	"github.com/go-errors/errors"
+	"github.com/jmoiron/sqlx"
+	sq "github.com/lann/squirrel"
+	"github.com/stellar/go-stellar-base"
+	"github.com/stellar/go-stellar-base/xdr"
+	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/log"
+	"golang.org/x/net/context"
+)
+
+// the columns of the history tables ingestion writes
+var (
+	ledgerColumns = []string{
+		"id",
+		"sequence",
+		"importer_version",
+		"ledger_hash",
+		"previous_ledger_hash",
+		"transaction_count",
+		"operation_count",
+		"operation_type_counts",
+		"closed_at",
+		"created_at",
+		"updated_at",
+	}
+
+	transactionColumns = []string{
+		"id",
+		"transaction_hash",
+		"ledger_sequence",
+		"application_order",
+		"account",
+		"account_sequence",
+		"max_fee",
+		"fee_paid",
+		"operation_count",
+		"tx_envelope",
+		"tx_result",
+		"tx_meta",
+		"tx_fee_meta",
+		"signatures",
+		"memo_type",
+		"memo",
+		"time_bounds",
+		"created_at",
+		"updated_at",
+	}
+
+	transactionParticipantColumns = []string{"transaction_hash", "account", "created_at", "updated_at"}
+	operationColumns              = []string{"id", "transaction_id", "application_order", "type", "details", "source_account"}
+	operationParticipantColumns   = []string{"history_operation_id", "history_account_id"}
+	effectColumns                 = []string{"history_account_id", "history_operation_id", "order", "type", "details"}
+	accountColumns                = []string{"id", "address"}
+)
+
+// ingestion writes the history of the ledger of sequence sequence within the
+// db transaction tx, through the writer w, indexing the transactions
+// processors accept.  accounts caches the ids of the history accounts it has
+// loaded or created, by address, provisional counts those it created for
+// accounts history has yet to see created, and rows counts the rows it has
+// written.  The rows added to w are left for the caller to flush.
+type ingestion struct {
+	ctx         context.Context
+	tx          *sqlx.Tx
+	w           *writer
+	network     string
+	sequence    int32
+	processors  []Processor
+	accounts    map[string]int64
+	provisional int32
+	rows        int
+}
+
+// ledger writes the history of the ledger whose header is header, from its
+// transactions txs and their fee changes fees, as loaded from the backend.
+// Failed transactions are left out of history, as they are loaded from
+// stellar-core when asked for.  The operations of the ledger are counted by
+// type, whichever transactions processors index.
+func (is *ingestion) ledger(header db.CoreLedgerHeaderRecord, txs []db.CoreTransactionRecord, fees []db.CoreTransactionFeeRecord) error {
+	closedAt := time.Unix(header.CloseTime, 0).UTC()
+
+	feeChanges := map[int32]string{}
+	for _, fee := range fees {
+		feeChanges[fee.Index] = fee.ChangesXDR
+	}
+
+	// the genesis ledger creates the master account of the network, the
+	// first account in history
+	if header.Sequence == 1 {
+		err := is.createAccount(masterAddress(is.network), 1)
+		if err != nil {
+			return err
+		}
+	}
+
+	var txCount, opCount int
+	opTypeCounts := db.OperationTypeCounts{}
+	for _, coreTx := range txs {
+		record, env, err := db.NewTransactionRecord(coreTx, closedAt)
+		if err != nil {
+			return err
+		}
+
+		if !record.Successful {
+			continue
+		}
+
+		record.TxFeeMeta = feeChanges[coreTx.Index]
+		err = is.transaction(coreTx, record, env)
+		if err != nil {
+			return err
+		}
+
+		txCount++
+		opCount += len(env.Tx.Operations)
+		for _, op := range env.Tx.Operations {
+			opTypeCounts[op.Body.Type]++
+		}
+	}
+
+	h, err := header.Header()
+	if err != nil {
+		return err
+	}
+
+	var previous sql.NullString
+	if header.Sequence > 1 {
+		previous = sql.NullString{String: hex.EncodeToString(h.PreviousLedgerHash[:]), Valid: true}
+	}
+
+	return is.write("history_ledgers", ledgerColumns,
+		db.TotalOrderId{LedgerSequence: header.Sequence}.ToInt64(),
+		header.Sequence,
+		CurrentVersion,
+		header.LedgerHash,
+		previous,
+		txCount,
+		opCount,
+		opTypeCounts,
+		closedAt,
+		time.Now().UTC(),
+		time.Now().UTC(),
+	)
+}
+
+// transaction writes the history of the successful transaction record, whose
+// envelope is env, loaded from the stellar-core transaction coreTx: the
+// transaction itself, its operations, their effects and the accounts taking
+// part in each.  Nothing but the changes it made to trustlines and signers is
+// written when the processors do not index it.
+func (is *ingestion) transaction(coreTx db.CoreTransactionRecord, record db.TransactionRecord, env xdr.TransactionEnvelope) error {
+	var trp xdr.TransactionResultPair
+	err := xdr.SafeUnmarshalBase64(coreTx.ResultXDR, &trp)
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	// transactions loaded from history archives come without their meta,
+	// leaving their effects without the ledger entries they changed
+	var changes []xdr.OperationMeta
+	if coreTx.ResultMetaXDR != "" {
+		var meta xdr.TransactionMeta
+		err = xdr.SafeUnmarshalBase64(coreTx.ResultMetaXDR, &meta)
+		if err != nil {
+			return errors.Wrap(err, 1)
+		}
+		changes = meta.MustOperations()
+	}
+
+	// the trustlines and signers kept current are those of the whole network,
+	// whichever transactions processors index
+	err = is.trustlines(changes)
+	if err != nil {
+		return err
+	}
+
+	err = is.signers(changes)
+	if err != nil {
+		return err
+	}
+
+	results := trp.Result.Result.MustResults()
+
+	ops, err := db.NewOperationRecords(record, env, results)
+	if err != nil {
+		return err
+	}
+
+	opParticipants := make([][]string, len(ops))
+	participants := []string{record.Account}
+	for i, op := range ops {
+		opParticipants[i], err = db.OperationParticipants(env.Tx.Operations[i], op.SourceAccount)
+		if err != nil {
+			return err
+		}
+		participants = append(participants, opParticipants[i]...)
+	}
+	participants = unique(participants)
+
+	indexed, err := is.index(record, env, ops, participants)
+	if err != nil || !indexed {
+		return err
+	}
+
+	err = is.write("history_transactions", transactionColumns,
+		record.Id,
+		record.TransactionHash,
+		record.LedgerSequence,
+		record.ApplicationOrder,
+		record.Account,
+		record.AccountSequence,
+		record.MaxFee,
+		record.FeePaid,
+		record.OperationCount,
+		record.TxEnvelope,
+		record.TxResult,
+		record.TxMeta,
+		record.TxFeeMeta,
+		"{"+record.SignatureString+"}",
+		record.MemoType,
+		record.Memo,
+		timeBounds(env.Tx.TimeBounds),
+		record.CreatedAt,
+		record.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	for i, op := range ops {
+		xop := env.Tx.Operations[i]
+
+		if xop.Body.Type == xdr.OperationTypeCreateAccount {
+			details, err := op.Details()
+			if err != nil {
+				return err
+			}
+
+			err = is.createAccount(details["account"].(string), op.Id)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = is.operation(op, opParticipants[i])
+		if err != nil {
+			return err
+		}
+
+		var opChanges xdr.LedgerEntryChanges
+		if changes != nil {
+			opChanges = changes[i].Changes
+		}
+
+		effects, err := db.NewEffectRecords(op, xop, results[i], opChanges, is.isSigner)
+		if err != nil {
+			return err
+		}
+
+		err = is.effects(effects)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, address := range participants {
+		err = is.write("history_transaction_participants", transactionParticipantColumns,
+			record.TransactionHash, address, record.CreatedAt, record.UpdatedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// index returns true when the processors of the ingestion index the
+// transaction record, whose envelope is env, operations ops and participants
+// participants.
+func (is *ingestion) index(record db.TransactionRecord, env xdr.TransactionEnvelope, ops []db.OperationRecord, participants []string) (bool, error) {
+	if len(is.processors) == 0 {
+		return true, nil
+	}
+
+	assets, err := operationAssets(ops)
+	if err != nil {
+		return false, err
+	}
+
+	tx := &Transaction{
+		Record:       record,
+		Envelope:     env,
+		Operations:   ops,
+		Participants: participants,
+		Assets:       assets,
+	}
+
+	for _, p := range is.processors {
+		indexed, err := p.Index(tx)
+		if err != nil || indexed {
+			return indexed, err
+		}
+	}
+
+	return false, nil
+}
+
+// operation writes op and the accounts taking part in it
+func (is *ingestion) operation(op db.OperationRecord, participants []string) error {
+	err := is.write("history_operations", operationColumns,
+		op.Id, op.TransactionId, op.ApplicationOrder, op.Type, op.DetailsString, op.SourceAccount)
+	if err != nil {
+		return err
+	}
+
+	for _, address := range unique(participants) {
+		id, err := is.participantID(address)
+		if err != nil {
+			return err
+		}
+
+		err = is.write("history_operation_participants", operationParticipantColumns, op.Id, id)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// effects writes effects, setting the history account of each
+func (is *ingestion) effects(effects []db.EffectRecord) error {
+	for _, effect := range effects {
+		id, err := is.participantID(effect.Account)
+		if err != nil {
+			return err
+		}
+
+		err = is.write("history_effects", effectColumns,
+			id, effect.HistoryOperationID, effect.Order, effect.Type, effect.DetailsString)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// createAccount adds address to the history accounts with the given id,
+// unless history already knows it, as for accounts created again after being
+// merged.
+func (is *ingestion) createAccount(address string, id int64) error {
+	_, err := is.accountID(address)
+	if err == nil {
+		return nil
+	}
+
+	if err != errUnknownAccount {
+		return err
+	}
+
+	err = is.write("history_accounts", accountColumns, id, address)
+	if err != nil {
+		return err
+	}
+
+	is.accounts[address] = id
+	return nil
+}
+
+// participantID returns the id of the history account of address, an account
+// taking part in the ledger.  Accounts history has yet to see created, those
+// created within a gap that is still to be backfilled, are given a provisional
+// id, made of the ledger's sequence and an index with no transaction, which no
+// operation's id can clash with.  The account keeps it once backfilled.
+func (is *ingestion) participantID(address string) (int64, error) {
+	id, err := is.accountID(address)
+	if err != errUnknownAccount {
+		return id, err
+	}
+
+	is.provisional++
+	if is.provisional > db.TotalOrderOperationMask {
+		return 0, errors.Errorf("too many provisional accounts in ledger %d", is.sequence)
+	}
+
+	id = db.TotalOrderId{LedgerSequence: is.sequence, OperationOrder: is.provisional}.ToInt64()
+	err = is.createAccount(address, id)
+	if err != nil {
+		return 0, err
+	}
+
+	log.WithField(is.ctx, "address", address).
+		WithField("id", id).
+		Info("created provisional history account")
+	return id, nil
+}
+
+// errUnknownAccount is returned by accountID for addresses history knows
+// nothing of
+var errUnknownAccount = stderr.New("no history account for address")
+
+// accountID returns the id of the history account of address
+func (is *ingestion) accountID(address string) (int64, error) {
+	if id, ok := is.accounts[address]; ok {
+		return id, nil
+	}
+
+	var id int64
+	err := is.tx.Get(&id, "SELECT id FROM history_accounts WHERE address = $1", address)
+	if err == sql.ErrNoRows {
+		return 0, errUnknownAccount
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, 1)
+	}
+
+	is.accounts[address] = id
+	return id, nil
+}
+
+// isSigner returns true if key signs for account, according to the latest
+// signer effect history holds for them.  It is the db.SignerCheck of the
+// effects ingested.  The effects held by the writer are flushed first, so that
+// those of the ledgers just ingested are seen.
+func (is *ingestion) isSigner(account, key string) (bool, error) {
+	err := is.w.flush()
+	if err != nil {
+		return false, err
+	}
+
+	id, err := is.accountID(account)
+	if err == errUnknownAccount {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	var effect int32
+	err = is.tx.Get(&effect, `
+		SELECT type FROM history_effects
+		WHERE history_account_id = $1
+		AND type IN ($2, $3, $4)
+		AND details->>'public_key' = $5
+		ORDER BY history_operation_id DESC, "order" DESC
+		LIMIT 1`,
+		id,
+		db.EffectSignerCreated,
+		db.EffectSignerRemoved,
+		db.EffectSignerUpdated,
+		key,
+	)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, 1)
+	}
+
+	return effect != db.EffectSignerRemoved, nil
+}
+
+// write adds a row of values for the columns of table to the ingestion's
+// writer
+func (is *ingestion) write(table string, columns []string, values ...interface{}) error {
+	err := is.w.add(table, columns, values...)
+	if err != nil {
+		return err
+	}
+
+	is.rows++
+	return nil
+}
+
+// exec runs the statement built by b within the ingestion's db transaction
+func (is *ingestion) exec(b sq.Sqlizer) error {
+	query, args, err := b.ToSql()
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	log.WithField(is.ctx, "sql", query).Debug("ingest sql")
+
+	_, err = is.tx.Exec(query, args...)
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	is.rows++
+	return nil
+}
+
+// insert returns a builder of an insert into table
+func insert(table string) sq.InsertBuilder {
+	return sq.Insert(table).PlaceholderFormat(sq.Dollar)
+}
+
+// timeBounds returns the value of the time_bounds column of a transaction, a
+// range whose upper bound is exclusive, written as text so that it may be
+// copied.  A MaxTime of 0 means the transaction has no upper bound.
+func timeBounds(tb *xdr.TimeBounds) interface{} {
+	if tb == nil {
+		return nil
+	}
+
+	if tb.MaxTime == 0 {
+		return fmt.Sprintf("[%d,)", int64(tb.MinTime))
+	}
+
+	return fmt.Sprintf("[%d,%d)", int64(tb.MinTime), int64(tb.MaxTime))
+}
+
+// masterAddress returns the address of the master account of the network
+// identified by passphrase, whose seed is the hash of the passphrase.
+func masterAddress(passphrase string) string {
+	pub, _, err := stellarbase.GenerateKeyFromRawSeed(stellarbase.RawSeed(stellarbase.Hash([]byte(passphrase))))
+	if err != nil {
+		panic(fmt.Sprintf("invalid master seed: %s", err))
+	}
+
+	return pub.Address()
+}
+
+// unique returns addresses without duplicates, in the order first seen
+func unique(addresses []string) []string {
+	seen := map[string]bool{}
+	result := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		if seen[address] {
+			continue
+		}
+		seen[address] = true
+		result = append(result, address)
+	}
+
+	return result
+}
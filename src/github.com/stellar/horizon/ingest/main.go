@@ -0,0 +1,424 @@
+package ingest
+
+import (
+	stderr "errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-errors/errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/rcrowley/go-metrics"
+	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/log"
+	"github.com/stellar/horizon/trace"
+	"golang.org/x/net/context"
+)
+
+const (
+	// CurrentVersion is the importer_version recorded on the ledgers ingested
+	// by this package.
+	CurrentVersion = 3
+
+	// DefaultMaxLedgersPerTick is the count of ledgers a Tick ingests at most
+	// when System.MaxLedgersPerTick is not set.
+	DefaultMaxLedgersPerTick = 100
+)
+
+// ErrLedgerMissing is returned when the next ledger to ingest is one the
+// backend lacks, such as one stellar-core no longer has after catching up from
+// a recent checkpoint rather than replaying the complete history of the
+// network.
+// NOTE: this is not a go-errors based error, so that callers can compare it.
+var ErrLedgerMissing = stderr.New("the backend lacks the next ledger to ingest")
+
+// System ingests the ledgers closed by stellar-core into the history database.
+// Its cursor is the latest ledger in history: as every ledger is written in a
+// single db transaction, a System resumes from where the last one stopped.
+type System struct {
+	HorizonDB *sqlx.DB
+	CoreDB    *sqlx.DB
+
+	// Backend is where ledgers are loaded from, the database of stellar-core
+	// at CoreDB when not set.
+	Backend Backend
+
+	// NetworkPassphrase identifies the network whose ledgers are ingested.  Its
+	// master account is the one created by the genesis ledger.
+	NetworkPassphrase string
+
+	// Processors decide which transactions are indexed, all of them when empty
+	Processors []Processor
+
+	// Notify, when set, is sent on as each ledger written to history commits,
+	// without blocking: a notification still pending covers the ledgers
+	// written since.  It makes a bus for the processes within horizon that
+	// follow history.
+	Notify chan<- struct{}
+
+	// NotifyPostgres makes the db transaction of each ledger written to history
+	// notify db.LedgerNotifyChannel with its sequence, for other processes
+	// following history, such as horizons that do not ingest.  Postgres
+	// delivers the notification as the ledger commits.
+	NotifyPostgres bool
+
+	// Guard, when set, is asked before each Tick and Backfill whether ledgers
+	// may be ingested: nothing is ingested while it returns an error, as when
+	// stellar-core is found to be on another network.
+	Guard func() error
+
+	// MaxLedgersPerTick caps the ledgers a Tick or a Backfill ingests.  A Tick
+	// finding history further behind stellar-core skips to the latest ledgers,
+	// leaving the older ones as a gap for Backfill to fill.
+	MaxLedgersPerTick int
+
+	Metrics struct {
+		// LedgerTimer exposes timing metrics about the rate and latency of the
+		// ingestion of ledgers
+		LedgerTimer metrics.Timer
+
+		// Mismatches counts the accounts Verify finds history to disagree with
+		// stellar-core about
+		Mismatches metrics.Counter
+
+		// RowMeter exposes the rate at which rows are written to history, marked
+		// as each db transaction commits
+		RowMeter metrics.Meter
+	}
+
+	// lock serializes the ingestion of ledgers, so that live ingestion and
+	// backfilling do not create the same history accounts
+	lock      sync.Mutex
+	ingesting int32
+
+	// paused is 1 while ingestion is paused
+	paused int32
+
+	trustlinesLock  sync.Mutex
+	trustlinesReady bool
+
+	signersLock  sync.Mutex
+	signersReady bool
+
+	gapLock     sync.Mutex
+	gaps        []Gap
+	backfilled  int
+	detectedAt  time.Time
+	backfillErr error
+}
+
+// Tick ingests, in order, the ledgers the backend has after the latest one in
+// history, up to MaxLedgersPerTick of them, returning the count of
+// ledgers ingested.  Ingestion stops at the first ledger that fails to be
+// ingested, to be retried by the next tick.
+//
+// Ledgers Tick does not get to, as when history is further behind than one
+// tick catches up on or when the backend lacks them, are recorded as a gap and
+// left to Backfill.  History that is empty is caught up on in order, starting
+// at the first ledger the backend has.
+func (sys *System) Tick(ctx context.Context) (int, error) {
+	if sys.Paused() || sys.guarded() {
+		return 0, nil
+	}
+
+	cursor, err := sys.Cursor(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	latest, err := sys.backend().LatestLedger(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	max := sys.maxLedgersPerTick()
+	from := cursor + 1
+	if cursor > 0 && latest-cursor > int32(max) {
+		from = latest - int32(max) + 1
+	}
+
+	first, err := sys.backend().FirstLedger(ctx, cursor+1)
+	if err != nil {
+		return 0, err
+	}
+
+	if first > from {
+		from = first
+	}
+
+	if cursor > 0 && first != 0 && from > cursor+1 {
+		sys.recordGap(ctx, Gap{Start: cursor + 1, End: from - 1})
+	}
+
+	ingested := 0
+	for seq := from; first != 0 && seq <= latest && ingested < max && !sys.Paused(); seq++ {
+		_, err = sys.ingestLedger(ctx, seq)
+		if err != nil {
+			return ingested, err
+		}
+		ingested++
+	}
+
+	return ingested, nil
+}
+
+// Pause stops Tick and Backfill from ingesting ledgers until Resume is called,
+// as when an operator wants history to hold still.  A ledger being ingested
+// as ingestion is paused is written in full.
+func (sys *System) Pause() {
+	atomic.StoreInt32(&sys.paused, 1)
+}
+
+// Resume undoes Pause
+func (sys *System) Resume() {
+	atomic.StoreInt32(&sys.paused, 0)
+}
+
+// Paused returns true while ingestion is paused
+func (sys *System) Paused() bool {
+	return atomic.LoadInt32(&sys.paused) == 1
+}
+
+// guarded returns true while Guard refuses ingestion
+func (sys *System) guarded() bool {
+	return sys.Guard != nil && sys.Guard() != nil
+}
+
+// Stop pauses ingestion, as when horizon shuts down, and waits for the ledger
+// being ingested, if any, to be written.  It returns the cursor of history as
+// of then, the one ingestion carries on from once horizon restarts.
+func (sys *System) Stop(ctx context.Context) (int32, error) {
+	sys.Pause()
+
+	written := make(chan struct{})
+	go func() {
+		sys.lock.Lock()
+		sys.lock.Unlock()
+		close(written)
+	}()
+
+	select {
+	case <-written:
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+
+	return sys.Cursor(ctx)
+}
+
+// Busy returns true while a ledger is being ingested
+func (sys *System) Busy() bool {
+	return atomic.LoadInt32(&sys.ingesting) > 0
+}
+
+// Cursor returns the sequence of the latest ledger ingested, 0 when history is
+// empty.
+func (sys *System) Cursor(ctx context.Context) (int32, error) {
+	var seq int32
+	err := sys.history().GetRaw(ctx, "SELECT COALESCE(MAX(sequence), 0) FROM history_ledgers", nil, &seq)
+	return seq, err
+}
+
+// ingestLedger writes the history of the ledger of sequence seq, loaded from
+// the backend, within a single db transaction.  It returns false, writing
+// nothing, when history already holds the ledger.
+func (sys *System) ingestLedger(ctx context.Context, seq int32) (ingested bool, err error) {
+	sys.lock.Lock()
+	defer sys.lock.Unlock()
+
+	var existing int
+	err = sys.history().GetRaw(ctx, "SELECT COUNT(*) FROM history_ledgers WHERE sequence = $1", []interface{}{seq}, &existing)
+	if err != nil || existing > 0 {
+		return
+	}
+
+	atomic.AddInt32(&sys.ingesting, 1)
+	defer atomic.AddInt32(&sys.ingesting, -1)
+
+	if sys.Metrics.LedgerTimer != nil {
+		defer sys.Metrics.LedgerTimer.UpdateSince(time.Now())
+	}
+
+	ctx, span := trace.Start(ctx, "ingest.ledger")
+	span.SetAttribute("ledger.sequence", seq)
+	defer func() { span.Finish(err) }()
+
+	loadCtx, loadSpan := trace.Start(ctx, "ingest.load")
+	l, err := sys.backend().Ledger(loadCtx, seq)
+	loadSpan.Finish(err)
+	if err != nil {
+		return
+	}
+
+	err = sys.prepareTrustlines(ctx)
+	if err != nil {
+		return
+	}
+
+	err = sys.prepareSigners(ctx)
+	if err != nil {
+		return
+	}
+
+	var rows int
+	_, writeSpan := trace.Start(ctx, "ingest.write")
+	err = db.Transact(sys.HorizonDB, func(tx *sqlx.Tx) error {
+		err := db.SetStatementTimeout(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		is := &ingestion{
+			ctx:        ctx,
+			tx:         tx,
+			w:          newWriter(sys.HorizonDB, tx),
+			network:    sys.NetworkPassphrase,
+			sequence:   seq,
+			processors: sys.Processors,
+			accounts:   map[string]int64{},
+		}
+
+		err = is.ledger(l.Header, l.Transactions, l.Fees)
+		if err != nil {
+			return err
+		}
+
+		err = is.w.flush()
+		if err != nil {
+			return err
+		}
+
+		rows = is.rows
+		return sys.notifyPostgres(tx, seq)
+	})
+	writeSpan.SetAttribute("ingest.rows", rows)
+	writeSpan.Finish(err)
+	if err != nil {
+		return
+	}
+
+	sys.notify()
+	sys.markRows(rows)
+	log.WithField(ctx, "ledger", seq).Info("ingested ledger")
+	ingested = true
+	return
+}
+
+// Status is the state of ingestion relative to its backend
+type Status struct {
+	// Cursor is the latest ledger ingested, 0 when history is empty
+	Cursor int32
+
+	// Latest is the latest ledger the backend has
+	Latest int32
+
+	// LagLedgers is the count of ledgers the backend has after the cursor
+	LagLedgers int32
+
+	// LagSeconds is how long ago the ledger of the cursor closed while history
+	// is behind the backend, 0 once caught up.
+	LagSeconds int64
+
+	// RowsPerSecond is the rate at which rows were written to history over the
+	// last minute, 0 without a Metrics.RowMeter.
+	RowsPerSecond float64
+
+	// Paused is true while ingestion is paused
+	Paused bool
+}
+
+// Status returns the state of ingestion relative to its backend, as of now
+func (sys *System) Status(ctx context.Context) (Status, error) {
+	// ledgers close in order, the latest last
+	var cursor struct {
+		Sequence int32      `db:"sequence"`
+		ClosedAt *time.Time `db:"closed_at"`
+	}
+	err := sys.history().GetRaw(ctx, `
+		SELECT COALESCE(MAX(sequence), 0) AS sequence, MAX(closed_at) AS closed_at
+		FROM history_ledgers`, nil, &cursor)
+	if err != nil {
+		return Status{}, err
+	}
+
+	latest, err := sys.backend().LatestLedger(ctx)
+	if err != nil {
+		return Status{}, err
+	}
+
+	s := Status{Cursor: cursor.Sequence, Latest: latest, Paused: sys.Paused()}
+	if latest > s.Cursor {
+		s.LagLedgers = latest - s.Cursor
+		if cursor.ClosedAt != nil {
+			s.LagSeconds = int64(time.Since(*cursor.ClosedAt) / time.Second)
+		}
+	}
+
+	if sys.Metrics.RowMeter != nil {
+		s.RowsPerSecond = sys.Metrics.RowMeter.Rate1()
+	}
+
+	return s, nil
+}
+
+// maxLedgersPerTick returns MaxLedgersPerTick, or its default when not set
+func (sys *System) maxLedgersPerTick() int {
+	if sys.MaxLedgersPerTick <= 0 {
+		return DefaultMaxLedgersPerTick
+	}
+
+	return sys.MaxLedgersPerTick
+}
+
+func (sys *System) history() db.SqlQuery {
+	return db.SqlQuery{DB: sys.HorizonDB}
+}
+
+// notifyPostgres notifies db.LedgerNotifyChannel of the ledger seq within tx,
+// when NotifyPostgres is set
+func (sys *System) notifyPostgres(tx *sqlx.Tx, seq int32) error {
+	if !sys.NotifyPostgres {
+		return nil
+	}
+
+	_, err := tx.Exec("SELECT pg_notify($1, $2)", db.LedgerNotifyChannel, strconv.Itoa(int(seq)))
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	return nil
+}
+
+// notify sends on Notify, when set, unless a notification is still pending
+func (sys *System) notify() {
+	if sys.Notify == nil {
+		return
+	}
+
+	select {
+	case sys.Notify <- struct{}{}:
+	default:
+	}
+}
+
+// markRows marks the meter of the rows written with n rows, when set
+func (sys *System) markRows(n int) {
+	if sys.Metrics.RowMeter != nil {
+		sys.Metrics.RowMeter.Mark(int64(n))
+	}
+}
+
+// backend returns the Backend of the system, or the database of stellar-core
+// when not set
+func (sys *System) backend() Backend {
+	if sys.Backend == nil {
+		return &CoreBackend{DB: sys.CoreDB}
+	}
+
+	return sys.Backend
+}
+
+func (sys *System) core() db.SqlQuery {
+	return db.SqlQuery{DB: sys.CoreDB}
+}
@@ -0,0 +1,277 @@
+package ingest
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/go-stellar-base/build"
+	"github.com/stellar/go-stellar-base/xdr"
+	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/test"
+)
+
+func TestIngest(t *testing.T) {
+	ctx := test.Context()
+	horizon := test.OpenDatabase(test.DatabaseUrl())
+	core := test.OpenDatabase(test.StellarCoreDatabaseUrl())
+	defer horizon.Close()
+	defer core.Close()
+
+	count := func(table string) int {
+		var n int
+		err := horizon.Get(&n, "SELECT COUNT(*) FROM "+table)
+		So(err, ShouldBeNil)
+		return n
+	}
+
+	Convey("System", t, func() {
+		test.LoadScenario("base")
+		for _, table := range []string{
+			"history_accounts",
+			"history_effects",
+			"history_ledgers",
+			"history_operation_participants",
+			"history_operations",
+			"history_transaction_participants",
+			"history_transactions",
+		} {
+			horizon.MustExec("DELETE FROM " + table)
+		}
+
+		sys := &System{
+			HorizonDB:         horizon,
+			CoreDB:            core,
+			NetworkPassphrase: build.TestNetwork.Passphrase,
+		}
+
+		Convey("ingests nothing while paused", func() {
+			sys.Pause()
+			n, err := sys.Tick(ctx)
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, 0)
+			So(count("history_ledgers"), ShouldEqual, 0)
+
+			sys.Resume()
+			n, err = sys.Tick(ctx)
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, 3)
+		})
+
+		Convey("ingests nothing while guarded", func() {
+			sys.Guard = func() error { return errors.New("refused") }
+			n, err := sys.Tick(ctx)
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, 0)
+			So(count("history_ledgers"), ShouldEqual, 0)
+		})
+
+		Convey("stops at the cursor of history", func() {
+			_, err := sys.Tick(ctx)
+			So(err, ShouldBeNil)
+
+			cursor, err := sys.Stop(ctx)
+			So(err, ShouldBeNil)
+			So(cursor, ShouldEqual, 3)
+			So(sys.Paused(), ShouldBeTrue)
+		})
+
+		Convey("ingests the ledgers stellar-core closed", func() {
+			n, err := sys.Tick(ctx)
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, 3)
+
+			So(count("history_ledgers"), ShouldEqual, 3)
+			So(count("history_transactions"), ShouldEqual, 4)
+			So(count("history_operations"), ShouldEqual, 4)
+			So(count("history_accounts"), ShouldEqual, 4)
+			So(count("history_effects"), ShouldEqual, 11)
+			So(count("history_transaction_participants"), ShouldEqual, 8)
+			So(count("history_operation_participants"), ShouldEqual, 8)
+
+			var account db.HistoryAccountRecord
+			err = db.Get(ctx, db.HistoryAccountByAddressQuery{
+				SqlQuery: db.SqlQuery{DB: horizon},
+				Address:  "GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU",
+			}, &account)
+			So(err, ShouldBeNil)
+			So(account.Id, ShouldEqual, 8589938689)
+
+			var tx db.TransactionRecord
+			err = db.Get(ctx, db.TransactionByHashQuery{
+				SqlQuery: db.SqlQuery{DB: horizon},
+				Hash:     "2374e99349b9ef7dba9a5db3339b78fda8f34777b1af33ba468ad5c0df946d4d",
+			}, &tx)
+			So(err, ShouldBeNil)
+			So(tx.Id, ShouldEqual, 8589938688)
+			So(tx.FeePaid, ShouldEqual, 100)
+			So(tx.TxFeeMeta, ShouldNotBeBlank)
+
+			var ledger db.LedgerRecord
+			err = db.Get(ctx, db.LedgerBySequenceQuery{SqlQuery: db.SqlQuery{DB: horizon}, Sequence: 2}, &ledger)
+			So(err, ShouldBeNil)
+			So(ledger.OperationTypeCounts, ShouldResemble, db.OperationTypeCounts{xdr.OperationTypeCreateAccount: 3})
+
+			Convey("and nothing more once caught up", func() {
+				n, err := sys.Tick(ctx)
+				So(err, ShouldBeNil)
+				So(n, ShouldEqual, 0)
+
+				status, err := sys.Status(ctx)
+				So(err, ShouldBeNil)
+				So(status.LagLedgers, ShouldEqual, 0)
+				So(status.LagSeconds, ShouldEqual, 0)
+			})
+		})
+
+		Convey("notifies the ledgers it writes", func() {
+			bus := make(chan struct{}, 1)
+			sys.Notify = bus
+			sys.NotifyPostgres = true
+
+			n, err := sys.Tick(ctx)
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, 3)
+			So(len(bus), ShouldEqual, 1)
+		})
+
+		Convey("ingests only the transactions its processors index", func() {
+			sys.Processors = []Processor{ProcessorFunc(func(tx *Transaction) (bool, error) {
+				return tx.Record.TransactionHash == "2374e99349b9ef7dba9a5db3339b78fda8f34777b1af33ba468ad5c0df946d4d", nil
+			})}
+
+			n, err := sys.Tick(ctx)
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, 3)
+
+			So(count("history_ledgers"), ShouldEqual, 3)
+			So(count("history_transactions"), ShouldEqual, 1)
+			So(count("history_operations"), ShouldEqual, 1)
+			So(count("history_accounts"), ShouldEqual, 2)
+		})
+
+		Convey("resumes from the latest ledger in history", func() {
+			sys.MaxLedgersPerTick = 2
+
+			n, err := sys.Tick(ctx)
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, 2)
+
+			cursor, err := sys.Cursor(ctx)
+			So(err, ShouldBeNil)
+			So(cursor, ShouldEqual, 2)
+
+			status, err := sys.Status(ctx)
+			So(err, ShouldBeNil)
+			So(status.Cursor, ShouldEqual, 2)
+			So(status.Latest, ShouldEqual, 3)
+			So(status.LagLedgers, ShouldEqual, 1)
+			So(status.LagSeconds, ShouldBeGreaterThan, 0)
+
+			restarted := &System{
+				HorizonDB:         horizon,
+				CoreDB:            core,
+				NetworkPassphrase: build.TestNetwork.Passphrase,
+			}
+
+			n, err = restarted.Tick(ctx)
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, 1)
+			So(count("history_ledgers"), ShouldEqual, 3)
+		})
+
+		Convey("starts at the first ledger stellar-core has", func() {
+			core.MustExec("DELETE FROM ledgerheaders WHERE ledgerseq = 1")
+
+			n, err := sys.Tick(ctx)
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, 2)
+			So(count("history_ledgers"), ShouldEqual, 2)
+			So(sys.Progress().Gaps, ShouldBeEmpty)
+		})
+
+		Convey("skips ledgers stellar-core lacks, leaving a gap", func() {
+			n, err := sys.Tick(ctx)
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, 3)
+
+			So(db.DeleteLedgerRange(horizon, 2, 3), ShouldBeNil)
+			core.MustExec("DELETE FROM ledgerheaders WHERE ledgerseq = 2")
+
+			n, err = sys.Tick(ctx)
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, 1)
+			So(sys.Progress().Gaps, ShouldResemble, []Gap{{Start: 2, End: 2}})
+
+			Convey("which backfilling leaves until stellar-core has them", func() {
+				n, err := sys.Backfill(ctx)
+				So(err, ShouldBeNil)
+				So(n, ShouldEqual, 0)
+				So(sys.Progress().Missing, ShouldEqual, 1)
+			})
+		})
+
+		Convey("skips to the latest ledgers when far behind", func() {
+			sys.MaxLedgersPerTick = 1
+
+			n, err := sys.Tick(ctx)
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, 1)
+
+			n, err = sys.Tick(ctx)
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, 1)
+
+			cursor, err := sys.Cursor(ctx)
+			So(err, ShouldBeNil)
+			So(cursor, ShouldEqual, 3)
+			So(sys.Progress().Gaps, ShouldResemble, []Gap{{Start: 2, End: 2}})
+
+			Convey("and backfills the gap left behind", func() {
+				n, err := sys.Backfill(ctx)
+				So(err, ShouldBeNil)
+				So(n, ShouldEqual, 1)
+
+				p := sys.Progress()
+				So(p.Gaps, ShouldBeEmpty)
+				So(p.Backfilled, ShouldEqual, 1)
+				So(count("history_ledgers"), ShouldEqual, 3)
+				So(count("history_transactions"), ShouldEqual, 4)
+				So(count("history_accounts"), ShouldEqual, 4)
+
+				gaps, err := sys.DetectGaps(ctx)
+				So(err, ShouldBeNil)
+				So(gaps, ShouldBeEmpty)
+			})
+		})
+
+		Convey("detects the gaps in history when first backfilling", func() {
+			n, err := sys.Tick(ctx)
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, 3)
+
+			horizon.MustExec("DELETE FROM history_ledgers WHERE sequence = 2")
+
+			restarted := &System{
+				HorizonDB:         horizon,
+				CoreDB:            core,
+				NetworkPassphrase: build.TestNetwork.Passphrase,
+			}
+
+			gaps, err := restarted.DetectGaps(ctx)
+			So(err, ShouldBeNil)
+			So(gaps, ShouldResemble, []Gap{{Start: 2, End: 2}})
+
+			_, err = restarted.pendingGaps(ctx)
+			So(err, ShouldBeNil)
+			So(restarted.Progress().Gaps, ShouldResemble, gaps)
+			So(restarted.Progress().DetectedAt.IsZero(), ShouldBeFalse)
+		})
+	})
+}
+
+func TestMasterAddress(t *testing.T) {
+	Convey("masterAddress", t, func() {
+		So(masterAddress(build.TestNetwork.Passphrase), ShouldEqual, "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H")
+	})
+}
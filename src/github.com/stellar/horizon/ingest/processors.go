@@ -0,0 +1,243 @@
+package ingest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-errors/errors"
+	"github.com/stellar/go-stellar-base/xdr"
+	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/strkeys"
+)
+
+// Transaction is a successful transaction offered to the processors of a
+// System, which decide whether history indexes it.
+type Transaction struct {
+	Record     db.TransactionRecord
+	Envelope   xdr.TransactionEnvelope
+	Operations []db.OperationRecord
+
+	// Participants are the addresses of the accounts taking part in the
+	// transaction: its source and those of its operations.
+	Participants []string
+
+	// Assets are the assets named by its operations, as "native" or in the form
+	// "CODE:ISSUER".
+	Assets []string
+}
+
+// Processor decides which transactions history indexes.  A System with
+// processors indexes a transaction when any of them returns true for it, and
+// every transaction when it has none.
+//
+// History only holds the transactions indexed: a transaction left out leaves
+// out its operations and effects, and the history accounts it creates.
+// Accounts taking part in indexed transactions that history saw no creation of
+// are given provisional ids.  Reingestion still creates every account created
+// within its range, ahead of its workers.
+type Processor interface {
+	Index(tx *Transaction) (bool, error)
+}
+
+// ProcessorFunc is a function that is a Processor
+type ProcessorFunc func(tx *Transaction) (bool, error)
+
+// Index calls f
+func (f ProcessorFunc) Index(tx *Transaction) (bool, error) {
+	return f(tx)
+}
+
+// ProcessorFactory creates a processor from its configuration, the text
+// following its name in the specification given to NewProcessors.
+type ProcessorFactory func(config string) (Processor, error)
+
+var (
+	factoryLock sync.Mutex
+	factories   = map[string]ProcessorFactory{}
+)
+
+// RegisterProcessor makes the processors factory creates available to
+// NewProcessors under name.  Processors of other packages register from the
+// init function of their package, built into horizon by importing it from the
+// horizon command.  It panics when name is already registered.
+func RegisterProcessor(name string, factory ProcessorFactory) {
+	factoryLock.Lock()
+	defer factoryLock.Unlock()
+
+	if _, dup := factories[name]; dup {
+		panic("ingest: processor registered twice: " + name)
+	}
+
+	factories[name] = factory
+}
+
+// NewProcessors creates the processors of spec, a semicolon separated list of
+// registered processor names, each followed by "=" and its configuration, such
+// as "accounts=GABC...,GDEF...;assets=native,USD:GHIJ...".
+func NewProcessors(spec string) ([]Processor, error) {
+	factoryLock.Lock()
+	defer factoryLock.Unlock()
+
+	var result []Processor
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, "=", 2)
+		name := strings.TrimSpace(parts[0])
+		config := ""
+		if len(parts) == 2 {
+			config = strings.TrimSpace(parts[1])
+		}
+
+		factory, ok := factories[name]
+		if !ok {
+			return nil, errors.Errorf("unknown ingestion processor: %s", name)
+		}
+
+		p, err := factory(config)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, p)
+	}
+
+	return result, nil
+}
+
+// AccountsProcessor indexes the transactions in which any of its accounts,
+// by address, take part.  It is registered as "accounts", configured with a
+// comma separated list of addresses.
+type AccountsProcessor map[string]bool
+
+// Index is a method for Processor
+func (p AccountsProcessor) Index(tx *Transaction) (bool, error) {
+	for _, address := range tx.Participants {
+		if p[address] {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func newAccountsProcessor(config string) (Processor, error) {
+	p := AccountsProcessor{}
+	for _, address := range splitConfig(config) {
+		if strkeys.ValidateAccountID(address) != nil {
+			return nil, errors.Errorf("invalid account to index: %s", address)
+		}
+
+		p[address] = true
+	}
+
+	return p, nil
+}
+
+// AssetsProcessor indexes the transactions whose operations name any of its
+// assets, as "native" or "CODE:ISSUER".  It is registered as "assets",
+// configured with a comma separated list of assets.
+type AssetsProcessor map[string]bool
+
+// Index is a method for Processor
+func (p AssetsProcessor) Index(tx *Transaction) (bool, error) {
+	for _, asset := range tx.Assets {
+		if p[asset] {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func newAssetsProcessor(config string) (Processor, error) {
+	p := AssetsProcessor{}
+	for _, asset := range splitConfig(config) {
+		if asset != "native" {
+			parts := strings.SplitN(asset, ":", 2)
+			if len(parts) != 2 || parts[0] == "" {
+				return nil, errors.Errorf("invalid asset to index: %s", asset)
+			}
+
+			if strkeys.ValidateAccountID(parts[1]) != nil {
+				return nil, errors.Errorf("invalid issuer of asset to index: %s", asset)
+			}
+		}
+
+		p[asset] = true
+	}
+
+	return p, nil
+}
+
+// splitConfig splits a comma separated configuration into its trimmed, non
+// empty elements
+func splitConfig(config string) []string {
+	var result []string
+	for _, item := range strings.Split(config, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			result = append(result, item)
+		}
+	}
+
+	return result
+}
+
+// operationAssets returns the assets named by the details of ops, in the form
+// of Transaction.Assets, sorted and without duplicates
+func operationAssets(ops []db.OperationRecord) ([]string, error) {
+	seen := map[string]bool{}
+
+	var add func(details map[string]interface{})
+	add = func(details map[string]interface{}) {
+		for key, value := range details {
+			if path, ok := value.([]interface{}); ok && key == "path" {
+				for _, hop := range path {
+					if hd, ok := hop.(map[string]interface{}); ok {
+						add(hd)
+					}
+				}
+				continue
+			}
+
+			if !strings.HasSuffix(key, "asset_type") {
+				continue
+			}
+
+			prefix := strings.TrimSuffix(key, "asset_type")
+			if value == "native" {
+				seen["native"] = true
+				continue
+			}
+
+			seen[fmt.Sprintf("%v:%v", details[prefix+"asset_code"], details[prefix+"asset_issuer"])] = true
+		}
+	}
+
+	for _, op := range ops {
+		details, err := op.Details()
+		if err != nil {
+			return nil, err
+		}
+		add(details)
+	}
+
+	result := make([]string, 0, len(seen))
+	for asset := range seen {
+		result = append(result, asset)
+	}
+	sort.Strings(result)
+
+	return result, nil
+}
+
+func init() {
+	RegisterProcessor("accounts", newAccountsProcessor)
+	RegisterProcessor("assets", newAssetsProcessor)
+}
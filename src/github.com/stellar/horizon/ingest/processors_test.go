@@ -0,0 +1,87 @@
+package ingest
+
+import (
+	"database/sql"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/db"
+)
+
+func TestProcessors(t *testing.T) {
+	const (
+		watched = "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H"
+		other   = "GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU"
+	)
+
+	op := func(details string) db.OperationRecord {
+		return db.OperationRecord{DetailsString: sql.NullString{String: details, Valid: true}}
+	}
+
+	Convey("NewProcessors", t, func() {
+		Convey("creates the registered processors of a specification", func() {
+			ps, err := NewProcessors("accounts=" + watched + "; assets=native,USD:" + other)
+			So(err, ShouldBeNil)
+			So(len(ps), ShouldEqual, 2)
+			So(ps[0], ShouldResemble, AccountsProcessor{watched: true})
+			So(ps[1], ShouldResemble, AssetsProcessor{"native": true, "USD:" + other: true})
+		})
+
+		Convey("creates none from an empty specification", func() {
+			ps, err := NewProcessors("")
+			So(err, ShouldBeNil)
+			So(ps, ShouldBeEmpty)
+		})
+
+		Convey("errors on unknown processors", func() {
+			_, err := NewProcessors("everything=")
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("errors on invalid configurations", func() {
+			_, err := NewProcessors("accounts=GNOTANACCOUNT")
+			So(err, ShouldNotBeNil)
+
+			_, err = NewProcessors("assets=USD")
+			So(err, ShouldNotBeNil)
+		})
+	})
+
+	Convey("AccountsProcessor indexes the transactions of its accounts", t, func() {
+		p := AccountsProcessor{watched: true}
+
+		indexed, err := p.Index(&Transaction{Participants: []string{other, watched}})
+		So(err, ShouldBeNil)
+		So(indexed, ShouldBeTrue)
+
+		indexed, err = p.Index(&Transaction{Participants: []string{other}})
+		So(err, ShouldBeNil)
+		So(indexed, ShouldBeFalse)
+	})
+
+	Convey("AssetsProcessor indexes the transactions naming its assets", t, func() {
+		p := AssetsProcessor{"USD:" + other: true}
+
+		indexed, err := p.Index(&Transaction{Assets: []string{"native", "USD:" + other}})
+		So(err, ShouldBeNil)
+		So(indexed, ShouldBeTrue)
+
+		indexed, err = p.Index(&Transaction{Assets: []string{"native"}})
+		So(err, ShouldBeNil)
+		So(indexed, ShouldBeFalse)
+	})
+
+	Convey("operationAssets", t, func() {
+		assets, err := operationAssets([]db.OperationRecord{
+			op(`{"asset_type": "native", "amount": "10.0"}`),
+			op(`{
+				"asset_type": "credit_alphanum4", "asset_code": "USD", "asset_issuer": "` + other + `",
+				"source_asset_type": "native",
+				"path": [{"asset_type": "credit_alphanum4", "asset_code": "EUR", "asset_issuer": "` + other + `"}]
+			}`),
+			op(`{"starting_balance": "10.0"}`),
+		})
+		So(err, ShouldBeNil)
+		So(assets, ShouldResemble, []string{"EUR:" + other, "USD:" + other, "native"})
+	})
+}
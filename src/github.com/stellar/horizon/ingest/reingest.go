@@ -0,0 +1,328 @@
+package ingest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-errors/errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/stellar/go-stellar-base/xdr"
+	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/log"
+	"golang.org/x/net/context"
+)
+
+// ReingestChunkLedgers is the count of ledgers each worker of a reingestion
+// rewrites within a single db transaction.
+const ReingestChunkLedgers = 1000
+
+// accountBatchSize is the count of history accounts created per statement
+// ahead of a reingestion
+const accountBatchSize = 500
+
+// ledgerRange is a range of ledgers, from Start to End inclusive, rewritten by
+// a worker of a reingestion.
+type ledgerRange struct {
+	Start int32
+	End   int32
+}
+
+// ReingestRange rewrites the history of the ledgers from `from` to `to`
+// inclusive, as loaded from the backend, returning the count of ledgers
+// rewritten.  The range is split into chunks of ReingestChunkLedgers ledgers,
+// each rewritten by one of workers concurrent workers in its own db
+// transaction, so that a chunk that fails leaves history as it was.
+//
+// The history accounts created within the range are created ahead of the
+// chunks, so that every chunk finds the accounts created by those before it.
+// Signers are told as created rather than updated when the signer effect
+// history holds for them lies in a chunk that is still being rewritten.
+func (sys *System) ReingestRange(ctx context.Context, from, to int32, workers int) (int, error) {
+	if from < 1 || to < from {
+		return 0, errors.Errorf("invalid range: %d to %d", from, to)
+	}
+
+	return sys.reingest(ctx, chunkRange(from, to, ReingestChunkLedgers), workers)
+}
+
+// reingest rewrites the history of the ledgers of chunks, in the manner of
+// ReingestRange
+func (sys *System) reingest(ctx context.Context, chunks []ledgerRange, workers int) (int, error) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	total := chunks[len(chunks)-1].End - chunks[0].Start + 1
+
+	err := sys.createAccounts(ctx, chunks, workers)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		lock     sync.Mutex
+		ingested int
+	)
+
+	err = eachRange(chunks, workers, func(r ledgerRange) error {
+		start := time.Now()
+		err := sys.reingestChunk(ctx, r)
+		if err != nil {
+			return err
+		}
+
+		lock.Lock()
+		ingested += int(r.End - r.Start + 1)
+		done := ingested
+		lock.Unlock()
+
+		log.WithField(ctx, "start", r.Start).
+			WithField("end", r.End).
+			WithField("duration", time.Since(start).String()).
+			WithField("progress", fmt.Sprintf("%d/%d", done, total)).
+			Info("reingested ledgers")
+		return nil
+	})
+
+	return ingested, err
+}
+
+// reingestChunk rewrites the history of the ledgers of r within a single db
+// transaction: the history it holds is deleted and ingested anew.
+func (sys *System) reingestChunk(ctx context.Context, r ledgerRange) (err error) {
+	err = sys.prepareTrustlines(ctx)
+	if err != nil {
+		return
+	}
+
+	err = sys.prepareSigners(ctx)
+	if err != nil {
+		return
+	}
+
+	var rows int
+	err = db.Transact(sys.HorizonDB, func(tx *sqlx.Tx) error {
+		err := db.SetStatementTimeout(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		err = db.DeleteLedgerRange(tx, r.Start, r.End)
+		if err != nil {
+			return err
+		}
+
+		// the rows of the whole chunk are written in batches
+		w := newWriter(sys.HorizonDB, tx)
+		accounts := map[string]int64{}
+		rows = 0
+		for seq := r.Start; seq <= r.End; seq++ {
+			l, err := sys.backend().Ledger(ctx, seq)
+			if err != nil {
+				return err
+			}
+
+			is := &ingestion{
+				ctx:        ctx,
+				tx:         tx,
+				w:          w,
+				network:    sys.NetworkPassphrase,
+				sequence:   seq,
+				processors: sys.Processors,
+				accounts:   accounts,
+			}
+
+			err = is.ledger(l.Header, l.Transactions, l.Fees)
+			if err != nil {
+				return err
+			}
+			rows += is.rows
+		}
+
+		err = w.flush()
+		if err != nil {
+			return err
+		}
+
+		return sys.notifyPostgres(tx, r.End)
+	})
+	if err != nil {
+		return
+	}
+
+	sys.notify()
+	sys.markRows(rows)
+	return nil
+}
+
+// createAccounts adds to history the accounts created within chunks that it
+// does not know yet, with the id of the operation that first created each.
+// The chunks are read from the backend by workers concurrent workers.
+func (sys *System) createAccounts(ctx context.Context, chunks []ledgerRange, workers int) error {
+	var lock sync.Mutex
+	created := map[string]int64{}
+
+	if chunks[0].Start == 1 {
+		created[masterAddress(sys.NetworkPassphrase)] = 1
+	}
+
+	err := eachRange(chunks, workers, func(r ledgerRange) error {
+		found, err := sys.accountsCreated(ctx, r)
+		if err != nil {
+			return err
+		}
+
+		lock.Lock()
+		defer lock.Unlock()
+		for address, id := range found {
+			if existing, ok := created[address]; !ok || id < existing {
+				created[address] = id
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	addresses := make([]string, 0, len(created))
+	for address := range created {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+
+	for i := 0; i < len(addresses); i += accountBatchSize {
+		j := i + accountBatchSize
+		if j > len(addresses) {
+			j = len(addresses)
+		}
+
+		values := make([]string, 0, j-i)
+		args := make([]interface{}, 0, 2*(j-i))
+		for _, address := range addresses[i:j] {
+			values = append(values, fmt.Sprintf("($%d::bigint, $%d)", len(args)+1, len(args)+2))
+			args = append(args, created[address], address)
+		}
+
+		_, err = sys.HorizonDB.Exec(`
+			INSERT INTO history_accounts (id, address)
+			SELECT v.id, v.address FROM (VALUES `+strings.Join(values, ", ")+`) v(id, address)
+			WHERE NOT EXISTS (SELECT 1 FROM history_accounts h WHERE h.address = v.address)`,
+			args...,
+		)
+		if err != nil {
+			return errors.Wrap(err, 1)
+		}
+	}
+
+	return nil
+}
+
+// accountsCreated returns the ids of the create_account operations of the
+// successful transactions of the ledgers of r, by the address they created,
+// keeping the first operation of those creating the same address.
+func (sys *System) accountsCreated(ctx context.Context, r ledgerRange) (map[string]int64, error) {
+	created := map[string]int64{}
+
+	for seq := r.Start; seq <= r.End; seq++ {
+		l, err := sys.backend().Ledger(ctx, seq)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, coreTx := range l.Transactions {
+			record, env, err := db.NewTransactionRecord(coreTx, time.Time{})
+			if err != nil {
+				return nil, err
+			}
+
+			if !record.Successful {
+				continue
+			}
+
+			ops, err := db.NewOperationRecords(record, env, nil)
+			if err != nil {
+				return nil, err
+			}
+
+			for i, op := range ops {
+				if env.Tx.Operations[i].Body.Type != xdr.OperationTypeCreateAccount {
+					continue
+				}
+
+				details, err := op.Details()
+				if err != nil {
+					return nil, err
+				}
+
+				address := details["account"].(string)
+				if _, ok := created[address]; !ok {
+					created[address] = op.Id
+				}
+			}
+		}
+	}
+
+	return created, nil
+}
+
+// chunkRange splits the ledgers from `from` to `to` inclusive into ranges of
+// size ledgers at most, in order
+func chunkRange(from, to int32, size int32) []ledgerRange {
+	var chunks []ledgerRange
+	for start := from; start <= to; start += size {
+		end := start + size - 1
+		if end > to {
+			end = to
+		}
+		chunks = append(chunks, ledgerRange{Start: start, End: end})
+	}
+
+	return chunks
+}
+
+// eachRange calls fn with each of ranges from workers concurrent goroutines,
+// returning the first error fn returns.  No more ranges are handed out once
+// fn fails.
+func eachRange(ranges []ledgerRange, workers int, fn func(ledgerRange) error) error {
+	work := make(chan ledgerRange)
+	errs := make(chan error, workers)
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for r := range work {
+				err := fn(r)
+				if err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+
+	var err error
+dispatch:
+	for _, r := range ranges {
+		select {
+		case work <- r:
+		case err = <-errs:
+			break dispatch
+		}
+	}
+	close(work)
+	wg.Wait()
+	close(errs)
+
+	if err == nil {
+		err = <-errs
+	}
+
+	return err
+}
@@ -0,0 +1,127 @@
+package ingest
+
+import (
+	stderr "errors"
+	"sync"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/go-stellar-base/build"
+	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/test"
+)
+
+func TestReingest(t *testing.T) {
+	ctx := test.Context()
+	horizon := test.OpenDatabase(test.DatabaseUrl())
+	core := test.OpenDatabase(test.StellarCoreDatabaseUrl())
+	defer horizon.Close()
+	defer core.Close()
+
+	count := func(table string) int {
+		var n int
+		err := horizon.Get(&n, "SELECT COUNT(*) FROM "+table)
+		So(err, ShouldBeNil)
+		return n
+	}
+
+	Convey("System.ReingestRange", t, func() {
+		test.LoadScenario("base")
+		sys := &System{
+			HorizonDB:         horizon,
+			CoreDB:            core,
+			NetworkPassphrase: build.TestNetwork.Passphrase,
+		}
+
+		Convey("rewrites the history of the range", func() {
+			n, err := sys.ReingestRange(ctx, 1, 3, 2)
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, 3)
+
+			So(count("history_ledgers"), ShouldEqual, 3)
+			So(count("history_transactions"), ShouldEqual, 4)
+			So(count("history_operations"), ShouldEqual, 4)
+			So(count("history_effects"), ShouldEqual, 11)
+			So(count("history_transaction_participants"), ShouldEqual, 8)
+			So(count("history_operation_participants"), ShouldEqual, 8)
+		})
+
+		Convey("imports history from scratch with concurrent workers", func() {
+			for _, table := range []string{
+				"history_accounts",
+				"history_effects",
+				"history_ledgers",
+				"history_operation_participants",
+				"history_operations",
+				"history_transaction_participants",
+				"history_transactions",
+			} {
+				horizon.MustExec("DELETE FROM " + table)
+			}
+
+			n, err := sys.reingest(ctx, chunkRange(1, 3, 1), 3)
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, 3)
+			So(count("history_ledgers"), ShouldEqual, 3)
+			So(count("history_accounts"), ShouldEqual, 4)
+
+			var account db.HistoryAccountRecord
+			err = db.Get(ctx, db.HistoryAccountByAddressQuery{
+				SqlQuery: db.SqlQuery{DB: horizon},
+				Address:  "GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU",
+			}, &account)
+			So(err, ShouldBeNil)
+			So(account.Id, ShouldEqual, 8589938689)
+		})
+
+		Convey("leaves history as it was when a chunk fails", func() {
+			core.MustExec("DELETE FROM ledgerheaders WHERE ledgerseq = 3")
+
+			_, err := sys.ReingestRange(ctx, 2, 3, 1)
+			So(err, ShouldEqual, ErrLedgerMissing)
+			So(count("history_ledgers"), ShouldEqual, 3)
+			So(count("history_transactions"), ShouldEqual, 4)
+		})
+
+		Convey("rejects invalid ranges", func() {
+			_, err := sys.ReingestRange(ctx, 3, 2, 1)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestChunkRange(t *testing.T) {
+	Convey("chunkRange", t, func() {
+		So(chunkRange(1, 5, 2), ShouldResemble, []ledgerRange{{1, 2}, {3, 4}, {5, 5}})
+		So(chunkRange(3, 3, 10), ShouldResemble, []ledgerRange{{3, 3}})
+	})
+
+	Convey("eachRange", t, func() {
+		chunks := chunkRange(1, 100, 1)
+
+		Convey("calls fn with every range", func() {
+			var lock sync.Mutex
+			seen := map[int32]bool{}
+
+			err := eachRange(chunks, 4, func(r ledgerRange) error {
+				lock.Lock()
+				defer lock.Unlock()
+				seen[r.Start] = true
+				return nil
+			})
+			So(err, ShouldBeNil)
+			So(len(seen), ShouldEqual, 100)
+		})
+
+		Convey("stops at the first error", func() {
+			boom := stderr.New("boom")
+			err := eachRange(chunks, 4, func(r ledgerRange) error {
+				if r.Start == 10 {
+					return boom
+				}
+				return nil
+			})
+			So(err, ShouldEqual, boom)
+		})
+	})
+}
@@ -0,0 +1,258 @@
+package ingest
+
+import (
+	"github.com/go-errors/errors"
+	"github.com/jmoiron/sqlx"
+	sq "github.com/lann/squirrel"
+	"github.com/stellar/go-stellar-base/xdr"
+	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/log"
+	"github.com/stellar/horizon/strkeys"
+	"golang.org/x/net/context"
+)
+
+// signerSeedBatch is the count of signers seeded from stellar-core per insert
+const signerSeedBatch = 500
+
+// signerColumns are the columns of current_signers written by ingestion
+var signerColumns = []string{
+	"accountid",
+	"publickey",
+	"weight",
+	"lastmodified",
+	"removed",
+}
+
+// prepareSigners seeds the current_signers table ingestion keeps, which
+// migration 5 of the schema creates, as prepareTrustlines seeds
+// current_trustlines: with the signers stellar-core holds, when the System has
+// a CoreDB and the table holds none yet.
+func (sys *System) prepareSigners(ctx context.Context) error {
+	sys.signersLock.Lock()
+	defer sys.signersLock.Unlock()
+
+	if sys.signersReady {
+		return nil
+	}
+
+	if sys.CoreDB != nil {
+		err := db.Transact(sys.HorizonDB, func(tx *sqlx.Tx) error {
+			var held bool
+			err := tx.Get(&held, "SELECT EXISTS (SELECT 1 FROM current_signers)")
+			if err != nil {
+				return errors.Wrap(err, 1)
+			}
+			if held {
+				return nil
+			}
+
+			n, err := seedSigners(tx, sys.CoreDB)
+			if err != nil {
+				return err
+			}
+
+			log.WithField(ctx, "signers", n).Info("seeded current signers from stellar-core")
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	sys.signersReady = true
+	return nil
+}
+
+// seedSigners copies the signers of the stellar-core database core into
+// current_signers within tx, as of the ledger that last changed their
+// accounts, returning the count copied.
+func seedSigners(tx *sqlx.Tx, core *sqlx.DB) (int, error) {
+	rows, err := core.Queryx(`
+		SELECT si.accountid, si.publickey, si.weight, a.lastmodified
+		FROM signers si
+		JOIN accounts a ON a.accountid = si.accountid`)
+	if err != nil {
+		return 0, errors.Wrap(err, 1)
+	}
+	defer rows.Close()
+
+	n := 0
+	batch := insert("current_signers").Columns(signerColumns...)
+	for rows.Next() {
+		var r db.SignerRecord
+		err = rows.StructScan(&r)
+		if err != nil {
+			return 0, errors.Wrap(err, 1)
+		}
+
+		batch = batch.Values(signerValues(r, false)...)
+		n++
+
+		if n%signerSeedBatch == 0 {
+			err = execBatch(tx, batch)
+			if err != nil {
+				return 0, err
+			}
+			batch = insert("current_signers").Columns(signerColumns...)
+		}
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return 0, errors.Wrap(err, 1)
+	}
+
+	if n%signerSeedBatch != 0 {
+		err = execBatch(tx, batch)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return n, nil
+}
+
+// signers applies to current_signers the changes the operations of a
+// transaction made to the signers of accounts.  The signers of an account
+// are left as they are when a ledger older than the one that last changed
+// them changes the account, as when reingesting it.
+func (is *ingestion) signers(changes []xdr.OperationMeta) error {
+	for _, op := range changes {
+		for _, change := range op.Changes {
+			var entry *xdr.LedgerEntry
+			switch change.Type {
+			case xdr.LedgerEntryChangeTypeLedgerEntryCreated:
+				entry = change.Created
+			case xdr.LedgerEntryChangeTypeLedgerEntryUpdated:
+				entry = change.Updated
+			case xdr.LedgerEntryChangeTypeLedgerEntryRemoved:
+				key := change.Removed
+				if key.Type != xdr.LedgerEntryTypeAccount {
+					continue
+				}
+
+				account, err := strkeys.Address(key.Account.AccountId)
+				if err != nil {
+					return errors.Wrap(err, 1)
+				}
+
+				err = is.accountSigners(account, nil)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+
+			if entry == nil || entry.Data.Type != xdr.LedgerEntryTypeAccount {
+				continue
+			}
+
+			records, err := db.NewSignerRecords(*entry.Data.Account, is.sequence)
+			if err != nil {
+				return err
+			}
+
+			account, err := strkeys.Address(entry.Data.Account.AccountId)
+			if err != nil {
+				return errors.Wrap(err, 1)
+			}
+
+			err = is.accountSigners(account, records)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// accountSigners writes records as the current signers of account, marking
+// the others it had removed.  Accounts change far more often than their
+// signers, so only the signers that differ are written: the latest
+// lastmodified of the signers of an account is then the ledger that last
+// changed them, by which older ledgers are told apart.
+func (is *ingestion) accountSigners(account string, records []db.SignerRecord) error {
+	var current []struct {
+		Publickey    string `db:"publickey"`
+		Weight       int32  `db:"weight"`
+		Lastmodified int32  `db:"lastmodified"`
+		Removed      bool   `db:"removed"`
+	}
+	err := is.tx.Select(&current, `
+		SELECT publickey, weight, lastmodified, removed FROM current_signers
+		WHERE accountid = $1`, account)
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	type signer struct {
+		weight  int32
+		removed bool
+	}
+	held := make(map[string]signer, len(current))
+	for _, c := range current {
+		if c.Lastmodified > is.sequence {
+			return nil
+		}
+		held[c.Publickey] = signer{c.Weight, c.Removed}
+	}
+
+	signs := make(map[string]bool, len(records))
+	for _, r := range records {
+		signs[r.Publickey] = true
+
+		s, ok := held[r.Publickey]
+		switch {
+		case !ok:
+			err = is.exec(insert("current_signers").
+				Columns(signerColumns...).
+				Values(signerValues(r, false)...))
+		case s.removed || s.weight != r.Weight:
+			err = is.updateSigner(account, r.Publickey, r.Weight, false)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	for key, s := range held {
+		if s.removed || signs[key] {
+			continue
+		}
+
+		err = is.updateSigner(account, key, s.weight, true)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// updateSigner writes the weight of the signer key of account, removed or
+// not, as of the ledger ingested.
+func (is *ingestion) updateSigner(account, key string, weight int32, removed bool) error {
+	return is.exec(sq.Update("current_signers").
+		PlaceholderFormat(sq.Dollar).
+		SetMap(map[string]interface{}{
+			"weight":       weight,
+			"lastmodified": is.sequence,
+			"removed":      removed,
+		}).
+		Where(sq.Eq{
+			"accountid": account,
+			"publickey": key,
+		}))
+}
+
+// signerValues returns the values of signerColumns for r
+func signerValues(r db.SignerRecord, removed bool) []interface{} {
+	return []interface{}{
+		r.Accountid,
+		r.Publickey,
+		r.Weight,
+		r.Lastmodified,
+		removed,
+	}
+}
@@ -0,0 +1,118 @@
+package ingest
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/go-stellar-base/build"
+	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/test"
+)
+
+func TestCurrentSigners(t *testing.T) {
+	const (
+		account = "GCXKG6RN4ONIEPCMNFB732A436Z5PNDSRLGWK7GBLCMQLIFO4S7EYWVU"
+		signer  = "GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4"
+	)
+	ctx := test.Context()
+	horizon := test.OpenDatabase(test.DatabaseUrl())
+	core := test.OpenDatabase(test.StellarCoreDatabaseUrl())
+	defer horizon.Close()
+	defer core.Close()
+
+	// current returns the signers ingestion keeps, removed or not
+	current := func() []db.SignerRecord {
+		var records []db.SignerRecord
+		err := horizon.Select(&records, `
+			SELECT accountid, publickey, weight, lastmodified FROM current_signers
+			ORDER BY accountid, publickey`)
+		So(err, ShouldBeNil)
+		return records
+	}
+
+	removed := func() bool {
+		var removed bool
+		err := horizon.Get(&removed, `
+			SELECT removed FROM current_signers
+			WHERE accountid = $1 AND publickey = $2`, account, signer)
+		So(err, ShouldBeNil)
+		return removed
+	}
+
+	Convey("current_signers", t, func() {
+		test.LoadScenario("set_options")
+		for _, table := range []string{
+			"history_accounts",
+			"history_effects",
+			"history_ledgers",
+			"history_operation_participants",
+			"history_operations",
+			"history_transaction_participants",
+			"history_transactions",
+		} {
+			horizon.MustExec("DELETE FROM " + table)
+		}
+
+		sys := &System{
+			HorizonDB:         horizon,
+			Backend:           &CoreBackend{DB: core},
+			NetworkPassphrase: build.TestNetwork.Passphrase,
+		}
+
+		Convey("follow the signer changes of the ledgers ingested", func() {
+			_, err := sys.Tick(ctx)
+			So(err, ShouldBeNil)
+
+			// the signer added in ledger 8, and weighed again in ledger 9, is
+			// removed in ledger 11
+			So(current(), ShouldResemble, []db.SignerRecord{
+				{Accountid: account, Publickey: signer, Weight: 5, Lastmodified: 11},
+			})
+			So(removed(), ShouldBeTrue)
+
+			Convey("which reingesting older ledgers leaves current", func() {
+				_, err := sys.ReingestRange(ctx, 1, 9, 1)
+				So(err, ShouldBeNil)
+				So(removed(), ShouldBeTrue)
+			})
+
+			Convey("as of the last ledger ingested", func() {
+				horizon.MustExec("DELETE FROM current_signers")
+
+				_, err := sys.ReingestRange(ctx, 1, 9, 1)
+				So(err, ShouldBeNil)
+				So(current(), ShouldResemble, []db.SignerRecord{
+					{Accountid: account, Publickey: signer, Weight: 5, Lastmodified: 9},
+				})
+				So(removed(), ShouldBeFalse)
+			})
+		})
+
+		Convey("are seeded from stellar-core while none are held", func() {
+			core.MustExec(`INSERT INTO signers (accountid, publickey, weight) VALUES ($1, $2, 3)`, account, signer)
+
+			var lastmodified int32
+			err := core.Get(&lastmodified, "SELECT lastmodified FROM accounts WHERE accountid = $1", account)
+			So(err, ShouldBeNil)
+
+			sys.CoreDB = core
+			So(sys.prepareSigners(ctx), ShouldBeNil)
+			expected := []db.SignerRecord{
+				{Accountid: account, Publickey: signer, Weight: 3, Lastmodified: lastmodified},
+			}
+			So(current(), ShouldResemble, expected)
+
+			Convey("and not again once held", func() {
+				core.MustExec(`UPDATE signers SET weight = 4 WHERE accountid = $1`, account)
+
+				sys := &System{
+					HorizonDB:         horizon,
+					CoreDB:            core,
+					NetworkPassphrase: build.TestNetwork.Passphrase,
+				}
+				So(sys.prepareSigners(ctx), ShouldBeNil)
+				So(current(), ShouldResemble, expected)
+			})
+		})
+	})
+}
@@ -0,0 +1,237 @@
+package ingest
+
+import (
+	"database/sql"
+
+	"github.com/go-errors/errors"
+	"github.com/jmoiron/sqlx"
+	sq "github.com/lann/squirrel"
+	"github.com/stellar/go-stellar-base/xdr"
+	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/log"
+	"golang.org/x/net/context"
+)
+
+// trustlineSeedBatch is the count of trustlines seeded from stellar-core per
+// insert
+const trustlineSeedBatch = 500
+
+// trustlineColumns are the columns of current_trustlines written by ingestion
+var trustlineColumns = []string{
+	"accountid",
+	"assettype",
+	"issuer",
+	"assetcode",
+	"tlimit",
+	"balance",
+	"flags",
+	"lastmodified",
+	"removed",
+}
+
+// prepareTrustlines seeds the current_trustlines table ingestion keeps, which
+// migration 1 of the schema creates, with the trustlines stellar-core holds,
+// when the System has a CoreDB and the table holds none yet, as the ledgers
+// ingested before it was created are lost to it.
+func (sys *System) prepareTrustlines(ctx context.Context) error {
+	sys.trustlinesLock.Lock()
+	defer sys.trustlinesLock.Unlock()
+
+	if sys.trustlinesReady {
+		return nil
+	}
+
+	if sys.CoreDB != nil {
+		err := db.Transact(sys.HorizonDB, func(tx *sqlx.Tx) error {
+			var held bool
+			err := tx.Get(&held, "SELECT EXISTS (SELECT 1 FROM current_trustlines)")
+			if err != nil {
+				return errors.Wrap(err, 1)
+			}
+			if held {
+				return nil
+			}
+
+			n, err := seedTrustlines(tx, sys.CoreDB)
+			if err != nil {
+				return err
+			}
+
+			log.WithField(ctx, "trustlines", n).Info("seeded current trustlines from stellar-core")
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	sys.trustlinesReady = true
+	return nil
+}
+
+// seedTrustlines copies the trustlines of the stellar-core database core into
+// current_trustlines within tx, returning the count copied.
+func seedTrustlines(tx *sqlx.Tx, core *sqlx.DB) (int, error) {
+	rows, err := core.Queryx(`
+		SELECT accountid, assettype, issuer, assetcode, tlimit, balance, flags, lastmodified
+		FROM trustlines`)
+	if err != nil {
+		return 0, errors.Wrap(err, 1)
+	}
+	defer rows.Close()
+
+	n := 0
+	batch := insert("current_trustlines").Columns(trustlineColumns...)
+	for rows.Next() {
+		var r db.TrustlineRecord
+		err = rows.StructScan(&r)
+		if err != nil {
+			return 0, errors.Wrap(err, 1)
+		}
+
+		batch = batch.Values(trustlineValues(r, false)...)
+		n++
+
+		if n%trustlineSeedBatch == 0 {
+			err = execBatch(tx, batch)
+			if err != nil {
+				return 0, err
+			}
+			batch = insert("current_trustlines").Columns(trustlineColumns...)
+		}
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return 0, errors.Wrap(err, 1)
+	}
+
+	if n%trustlineSeedBatch != 0 {
+		err = execBatch(tx, batch)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return n, nil
+}
+
+// trustlines applies to current_trustlines the changes the operations of a
+// transaction made to trustlines.  Changes made by ledgers older than the
+// one a trustline was last changed by, as when reingesting them, are ignored.
+func (is *ingestion) trustlines(changes []xdr.OperationMeta) error {
+	for _, op := range changes {
+		for _, change := range op.Changes {
+			var entry *xdr.LedgerEntry
+			switch change.Type {
+			case xdr.LedgerEntryChangeTypeLedgerEntryCreated:
+				entry = change.Created
+			case xdr.LedgerEntryChangeTypeLedgerEntryUpdated:
+				entry = change.Updated
+			case xdr.LedgerEntryChangeTypeLedgerEntryRemoved:
+				key := change.Removed
+				if key.Type != xdr.LedgerEntryTypeTrustline {
+					continue
+				}
+
+				r, err := db.NewTrustlineRecord(xdr.TrustLineEntry{
+					AccountId: key.TrustLine.AccountId,
+					Asset:     key.TrustLine.Asset,
+				}, is.sequence)
+				if err != nil {
+					return err
+				}
+
+				err = is.trustline(r, true)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+
+			if entry == nil || entry.Data.Type != xdr.LedgerEntryTypeTrustline {
+				continue
+			}
+
+			r, err := db.NewTrustlineRecord(*entry.Data.TrustLine, is.sequence)
+			if err != nil {
+				return err
+			}
+
+			err = is.trustline(r, false)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// trustline writes r as the current state of its trustline, removed or not,
+// unless a later ledger last changed it.
+func (is *ingestion) trustline(r db.TrustlineRecord, removed bool) error {
+	var last int32
+	err := is.tx.Get(&last, `
+		SELECT lastmodified FROM current_trustlines
+		WHERE accountid = $1 AND issuer = $2 AND assetcode = $3`,
+		r.Accountid, r.Issuer, r.Assetcode,
+	)
+
+	switch {
+	case err == sql.ErrNoRows:
+		return is.exec(insert("current_trustlines").
+			Columns(trustlineColumns...).
+			Values(trustlineValues(r, removed)...))
+	case err != nil:
+		return errors.Wrap(err, 1)
+	case last > is.sequence:
+		return nil
+	}
+
+	return is.exec(sq.Update("current_trustlines").
+		PlaceholderFormat(sq.Dollar).
+		SetMap(map[string]interface{}{
+			"assettype":    r.Assettype,
+			"tlimit":       r.Tlimit,
+			"balance":      r.Balance,
+			"flags":        r.Flags,
+			"lastmodified": r.Lastmodified,
+			"removed":      removed,
+		}).
+		Where(sq.Eq{
+			"accountid": r.Accountid,
+			"issuer":    r.Issuer,
+			"assetcode": r.Assetcode,
+		}))
+}
+
+// trustlineValues returns the values of trustlineColumns for r
+func trustlineValues(r db.TrustlineRecord, removed bool) []interface{} {
+	return []interface{}{
+		r.Accountid,
+		r.Assettype,
+		r.Issuer,
+		r.Assetcode,
+		r.Tlimit,
+		r.Balance,
+		r.Flags,
+		r.Lastmodified,
+		removed,
+	}
+}
+
+// execBatch runs the insert b within tx
+func execBatch(tx *sqlx.Tx, b sq.InsertBuilder) error {
+	query, args, err := b.ToSql()
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	_, err = tx.Exec(query, args...)
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	return nil
+}
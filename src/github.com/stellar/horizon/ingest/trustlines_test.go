@@ -0,0 +1,104 @@
+package ingest
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/go-stellar-base/build"
+	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/test"
+)
+
+func TestCurrentTrustlines(t *testing.T) {
+	const (
+		holder = "GA5WBPYA5Y4WAEHXWR2UKO2UO4BUGHUQ74EUPKON2QHV4WRHOIRNKKH2"
+		usd    = "GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4"
+	)
+	ctx := test.Context()
+	horizon := test.OpenDatabase(test.DatabaseUrl())
+	core := test.OpenDatabase(test.StellarCoreDatabaseUrl())
+	defer horizon.Close()
+	defer core.Close()
+
+	// current returns the trustlines ingestion keeps, ordered as expected
+	current := func() []db.TrustlineRecord {
+		var records []db.TrustlineRecord
+		err := db.SqlQuery{DB: horizon}.Select(ctx, db.TrustlineRecordSelect.
+			OrderBy("tl.accountid", "tl.issuer", "tl.assetcode"), &records)
+		So(err, ShouldBeNil)
+		return records
+	}
+
+	Convey("current_trustlines", t, func() {
+		test.LoadScenario("trades")
+		for _, table := range []string{
+			"history_accounts",
+			"history_effects",
+			"history_ledgers",
+			"history_operation_participants",
+			"history_operations",
+			"history_transaction_participants",
+			"history_transactions",
+		} {
+			horizon.MustExec("DELETE FROM " + table)
+		}
+
+		var expected []db.TrustlineRecord
+		err := db.SqlQuery{DB: core}.SelectRaw(ctx, `
+			SELECT accountid, assettype, issuer, assetcode, tlimit, balance, flags, lastmodified
+			FROM trustlines
+			ORDER BY accountid, issuer, assetcode`, nil, &expected)
+		So(err, ShouldBeNil)
+		So(len(expected), ShouldEqual, 4)
+
+		Convey("follow the trustline changes of the ledgers ingested", func() {
+			sys := &System{
+				HorizonDB:         horizon,
+				Backend:           &CoreBackend{DB: core},
+				NetworkPassphrase: build.TestNetwork.Passphrase,
+			}
+			_, err := sys.Tick(ctx)
+			So(err, ShouldBeNil)
+
+			So(current(), ShouldResemble, expected)
+
+			Convey("which reingesting older ledgers leaves current", func() {
+				horizon.MustExec(`
+					UPDATE current_trustlines SET balance = 1, lastmodified = 100
+					WHERE accountid = $1 AND issuer = $2`, holder, usd)
+
+				_, err := sys.ReingestRange(ctx, 1, 6, 1)
+				So(err, ShouldBeNil)
+
+				var balance int64
+				err = horizon.Get(&balance, `
+					SELECT balance FROM current_trustlines
+					WHERE accountid = $1 AND issuer = $2`, holder, usd)
+				So(err, ShouldBeNil)
+				So(balance, ShouldEqual, 1)
+			})
+		})
+
+		Convey("are seeded from stellar-core while none are held", func() {
+			sys := &System{
+				HorizonDB:         horizon,
+				CoreDB:            core,
+				NetworkPassphrase: build.TestNetwork.Passphrase,
+			}
+			So(sys.prepareTrustlines(ctx), ShouldBeNil)
+			So(current(), ShouldResemble, expected)
+
+			Convey("and not again once held", func() {
+				horizon.MustExec("DELETE FROM current_trustlines WHERE accountid = $1", holder)
+
+				sys := &System{
+					HorizonDB:         horizon,
+					CoreDB:            core,
+					NetworkPassphrase: build.TestNetwork.Passphrase,
+				}
+				So(sys.prepareTrustlines(ctx), ShouldBeNil)
+				So(len(current()), ShouldBeLessThan, len(expected))
+			})
+		})
+	})
+}
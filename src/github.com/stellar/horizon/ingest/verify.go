@@ -0,0 +1,226 @@
+package ingest
+
+import (
+	"github.com/go-errors/errors"
+	"github.com/stellar/go-stellar-base/xdr"
+	"github.com/stellar/horizon/log"
+	"github.com/stellar/horizon/strkeys"
+	"github.com/stellar/horizon/trace"
+	"golang.org/x/net/context"
+)
+
+// DefaultVerifyAccounts is the count of accounts a verification samples when
+// not told otherwise.
+const DefaultVerifyAccounts = 100
+
+// Mismatch is an account whose state, as recomputed from ingested history,
+// differs from the one stellar-core holds.
+type Mismatch struct {
+	Address string
+	Ledger  int32
+
+	Balance     int64
+	CoreBalance int64
+
+	Sequence     int64
+	CoreSequence int64
+}
+
+// Verification is the outcome of a verification of ingested history
+type Verification struct {
+	// Checked is the count of accounts whose state was compared
+	Checked int
+
+	// Skipped is the count of accounts sampled whose state history cannot tell,
+	// such as those last changed by fees alone or in ledgers ingested without
+	// their meta.
+	Skipped int
+
+	Mismatches []Mismatch
+}
+
+// Verify compares the balances and sequence numbers of a random sample of
+// accounts, of up to `accounts` of them, as stellar-core holds them in its
+// database at CoreDB, with those recomputed from the meta of the transactions
+// ingested into history.  Each account is compared as of the ledger it was
+// last changed by, which history must hold in full: the last changes the
+// operations of its transactions made to the account are its state.
+//
+// Mismatches are logged as errors and counted by Metrics.Mismatches.  A
+// System with processors cannot be verified, as history leaves out changes to
+// accounts made by the transactions it does not index.
+func (sys *System) Verify(ctx context.Context, accounts int) (v Verification, err error) {
+	if len(sys.Processors) > 0 {
+		err = errors.New("cannot verify history that processors index part of")
+		return
+	}
+
+	if accounts <= 0 {
+		accounts = DefaultVerifyAccounts
+	}
+
+	ctx, span := trace.Start(ctx, "ingest.verify")
+	defer func() {
+		span.SetAttribute("verify.checked", v.Checked)
+		span.SetAttribute("verify.mismatches", len(v.Mismatches))
+		span.Finish(err)
+	}()
+
+	var bounds struct {
+		Elder  int32 `db:"elder"`
+		Latest int32 `db:"latest"`
+	}
+	err = sys.history().GetRaw(ctx, `
+		SELECT COALESCE(MIN(sequence), 0) AS elder, COALESCE(MAX(sequence), 0) AS latest
+		FROM history_ledgers`, nil, &bounds)
+	if err != nil {
+		return
+	}
+
+	var sample []struct {
+		Address      string `db:"accountid"`
+		Balance      int64  `db:"balance"`
+		Sequence     int64  `db:"seqnum"`
+		LastModified int32  `db:"lastmodified"`
+	}
+	err = sys.core().SelectRaw(ctx, `
+		SELECT accountid, balance, seqnum, lastmodified FROM accounts
+		WHERE lastmodified BETWEEN $1 AND $2
+		ORDER BY random()
+		LIMIT $3`,
+		[]interface{}{bounds.Elder, bounds.Latest, accounts},
+		&sample,
+	)
+	if err != nil {
+		return
+	}
+
+	metas := map[int32][]xdr.TransactionMeta{}
+	for _, account := range sample {
+		ledgerMetas, ok := metas[account.LastModified]
+		if !ok {
+			ledgerMetas, err = sys.ledgerMetas(ctx, account.LastModified)
+			if err != nil {
+				return
+			}
+			metas[account.LastModified] = ledgerMetas
+		}
+
+		var entry *xdr.AccountEntry
+		entry, err = lastAccountState(ledgerMetas, account.Address)
+		if err != nil {
+			return
+		}
+
+		if entry == nil {
+			v.Skipped++
+			continue
+		}
+
+		v.Checked++
+		if int64(entry.Balance) == account.Balance && int64(entry.SeqNum) == account.Sequence {
+			continue
+		}
+
+		m := Mismatch{
+			Address:      account.Address,
+			Ledger:       account.LastModified,
+			Balance:      int64(entry.Balance),
+			CoreBalance:  account.Balance,
+			Sequence:     int64(entry.SeqNum),
+			CoreSequence: account.Sequence,
+		}
+		v.Mismatches = append(v.Mismatches, m)
+
+		if sys.Metrics.Mismatches != nil {
+			sys.Metrics.Mismatches.Inc(1)
+		}
+
+		log.WithField(ctx, "address", m.Address).
+			WithField("ledger", m.Ledger).
+			WithField("balance", m.Balance).
+			WithField("core_balance", m.CoreBalance).
+			WithField("sequence", m.Sequence).
+			WithField("core_sequence", m.CoreSequence).
+			Error("ingested history disagrees with stellar-core")
+	}
+
+	return
+}
+
+// ledgerMetas returns the meta of the transactions history holds of the ledger
+// seq, in the order applied.  It returns none when history lacks the ledger or
+// the meta of any of its transactions, leaving the accounts it changed
+// unverifiable.
+func (sys *System) ledgerMetas(ctx context.Context, seq int32) ([]xdr.TransactionMeta, error) {
+	var found int
+	err := sys.history().GetRaw(ctx, "SELECT COUNT(*) FROM history_ledgers WHERE sequence = $1", []interface{}{seq}, &found)
+	if err != nil || found == 0 {
+		return nil, err
+	}
+
+	var raw []string
+	err = sys.history().SelectRaw(ctx, `
+		SELECT tx_meta FROM history_transactions
+		WHERE ledger_sequence = $1
+		ORDER BY application_order ASC`,
+		[]interface{}{seq},
+		&raw,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	metas := make([]xdr.TransactionMeta, len(raw))
+	for i, data := range raw {
+		if data == "" {
+			return nil, nil
+		}
+
+		err = xdr.SafeUnmarshalBase64(data, &metas[i])
+		if err != nil {
+			return nil, errors.Wrap(err, 1)
+		}
+	}
+
+	return metas, nil
+}
+
+// lastAccountState returns the state the operations of the transactions whose
+// meta are metas left the account of address in, nil when they did not change
+// it.  The fees of a ledger are charged before any of its transactions apply,
+// so it is the state of the account at the close of the ledger.
+func lastAccountState(metas []xdr.TransactionMeta, address string) (*xdr.AccountEntry, error) {
+	var state *xdr.AccountEntry
+
+	for _, meta := range metas {
+		for _, op := range meta.MustOperations() {
+			for _, change := range op.Changes {
+				var entry *xdr.LedgerEntry
+				switch change.Type {
+				case xdr.LedgerEntryChangeTypeLedgerEntryCreated:
+					entry = change.Created
+				case xdr.LedgerEntryChangeTypeLedgerEntryUpdated:
+					entry = change.Updated
+				default:
+					continue
+				}
+
+				if entry.Data.Type != xdr.LedgerEntryTypeAccount {
+					continue
+				}
+
+				changed, err := strkeys.Address(entry.Data.Account.AccountId)
+				if err != nil {
+					return nil, errors.Wrap(err, 1)
+				}
+
+				if changed == address {
+					state = entry.Data.Account
+				}
+			}
+		}
+	}
+
+	return state, nil
+}
@@ -0,0 +1,120 @@
+package ingest
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/go-stellar-base/build"
+	"github.com/stellar/go-stellar-base/strkey"
+	"github.com/stellar/go-stellar-base/xdr"
+	"github.com/stellar/horizon/test"
+)
+
+func TestVerify(t *testing.T) {
+	const payee = "GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON"
+	ctx := test.Context()
+	horizon := test.OpenDatabase(test.DatabaseUrl())
+	core := test.OpenDatabase(test.StellarCoreDatabaseUrl())
+	defer horizon.Close()
+	defer core.Close()
+
+	Convey("System.Verify", t, func() {
+		test.LoadScenario("base")
+		for _, table := range []string{
+			"history_accounts",
+			"history_effects",
+			"history_ledgers",
+			"history_operation_participants",
+			"history_operations",
+			"history_transaction_participants",
+			"history_transactions",
+		} {
+			horizon.MustExec("DELETE FROM " + table)
+		}
+
+		sys := &System{
+			HorizonDB:         horizon,
+			CoreDB:            core,
+			NetworkPassphrase: build.TestNetwork.Passphrase,
+		}
+		_, err := sys.Tick(ctx)
+		So(err, ShouldBeNil)
+
+		Convey("finds history to agree with stellar-core", func() {
+			v, err := sys.Verify(ctx, 10)
+			So(err, ShouldBeNil)
+			So(v.Checked+v.Skipped, ShouldEqual, 4)
+			So(v.Checked, ShouldBeGreaterThan, 0)
+			So(v.Mismatches, ShouldBeEmpty)
+		})
+
+		Convey("reports the accounts history disagrees with stellar-core about", func() {
+			core.MustExec("UPDATE accounts SET balance = balance + 1 WHERE accountid = $1", payee)
+
+			v, err := sys.Verify(ctx, 10)
+			So(err, ShouldBeNil)
+			So(len(v.Mismatches), ShouldEqual, 1)
+			So(v.Mismatches[0].Address, ShouldEqual, payee)
+			So(v.Mismatches[0].Ledger, ShouldEqual, 3)
+			So(v.Mismatches[0].CoreBalance, ShouldEqual, v.Mismatches[0].Balance+1)
+		})
+
+		Convey("refuses history that processors index part of", func() {
+			sys.Processors = []Processor{AccountsProcessor{payee: true}}
+			_, err := sys.Verify(ctx, 10)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestLastAccountState(t *testing.T) {
+	const (
+		address = "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H"
+		other   = "GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON"
+	)
+
+	account := func(address string, balance xdr.Int64) *xdr.LedgerEntry {
+		var key xdr.Uint256
+		copy(key[:], strkey.MustDecode(strkey.VersionByteAccountID, address))
+		aid, err := xdr.NewAccountId(xdr.CryptoKeyTypeKeyTypeEd25519, key)
+		So(err, ShouldBeNil)
+
+		return &xdr.LedgerEntry{Data: xdr.LedgerEntryData{
+			Type:    xdr.LedgerEntryTypeAccount,
+			Account: &xdr.AccountEntry{AccountId: aid, Balance: balance, SeqNum: 7},
+		}}
+	}
+
+	meta := func(changes ...xdr.LedgerEntryChange) xdr.TransactionMeta {
+		ops := []xdr.OperationMeta{{Changes: changes}}
+		return xdr.TransactionMeta{Operations: &ops}
+	}
+
+	Convey("lastAccountState", t, func() {
+		metas := []xdr.TransactionMeta{
+			meta(
+				xdr.LedgerEntryChange{Type: xdr.LedgerEntryChangeTypeLedgerEntryCreated, Created: account(address, 10)},
+				xdr.LedgerEntryChange{Type: xdr.LedgerEntryChangeTypeLedgerEntryUpdated, Updated: account(other, 20)},
+			),
+			meta(xdr.LedgerEntryChange{Type: xdr.LedgerEntryChangeTypeLedgerEntryUpdated, Updated: account(address, 30)}),
+		}
+
+		Convey("returns the last state the operations left an account in", func() {
+			state, err := lastAccountState(metas, address)
+			So(err, ShouldBeNil)
+			So(state, ShouldNotBeNil)
+			So(state.Balance, ShouldEqual, 30)
+			So(state.SeqNum, ShouldEqual, 7)
+
+			state, err = lastAccountState(metas, other)
+			So(err, ShouldBeNil)
+			So(state.Balance, ShouldEqual, 20)
+		})
+
+		Convey("returns nil for accounts the operations left unchanged", func() {
+			state, err := lastAccountState(metas[1:], other)
+			So(err, ShouldBeNil)
+			So(state, ShouldBeNil)
+		})
+	})
+}
@@ -0,0 +1,55 @@
+package horizon
+
+import (
+	"net/http/pprof"
+
+	"github.com/zenazn/goji/web"
+	"github.com/zenazn/goji/web/middleware"
+)
+
+// initAdmin creates the router of the admin server, served on
+// Config.AdminAddr apart from the public api: metrics, in prometheus format
+// too, profiling, the status and controls of ingestion, and the config horizon
+// runs with, which it may be told to reload.
+//
+// Such operational endpoints are left off the public router once an admin
+// server is configured, so that they are never reachable through the load
+// balancer in front of it.  No admin router is created when Config.AdminAddr
+// is empty, and metrics and the status of ingestion are served publicly, as
+// before.
+func initAdmin(app *App) {
+	if app.config.AdminAddr == "" {
+		return
+	}
+
+	r := web.New()
+	r.Use(middleware.EnvInit)
+	r.Use(app.Middleware)
+	r.Use(requestIDMiddleware)
+	r.Use(contextMiddleware(app.ctx))
+	r.Use(LoggerMiddleware)
+	r.Use(RecoverMiddleware)
+
+	r.Get("/metrics", &MetricsAction{})
+	r.Get("/metrics/prometheus", prometheusMetricsHandler)
+	r.Get("/config", &ConfigAction{})
+	r.Post("/config/reload", &ConfigReloadAction{})
+	r.Get("/ingestion", &IngestionStatusAction{})
+	r.Post("/ingestion/pause", &IngestionControlAction{Pause: true})
+	r.Post("/ingestion/resume", &IngestionControlAction{Pause: false})
+
+	r.Get("/debug/pprof/cmdline", pprof.Cmdline)
+	r.Get("/debug/pprof/profile", pprof.Profile)
+	r.Get("/debug/pprof/symbol", pprof.Symbol)
+	r.Post("/debug/pprof/symbol", pprof.Symbol)
+	r.Get("/debug/pprof/trace", pprof.Trace)
+	r.Get("/debug/pprof/*", pprof.Index)
+
+	r.NotFound(&NotFoundAction{})
+
+	app.web.adminRouter = r
+}
+
+func init() {
+	appInit.Add("web.admin", initAdmin, "web.init", "app-context")
+}
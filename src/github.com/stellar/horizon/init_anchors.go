@@ -0,0 +1,37 @@
+package horizon
+
+import (
+	"github.com/stellar/horizon/anchors"
+	"github.com/stellar/horizon/db"
+	"golang.org/x/net/context"
+)
+
+// initAnchors creates the directory of the anchors that assets are annotated
+// with, when Config.AnchorMetadata is set.  The home domains of issuers are
+// those stellar-core holds, and the stellar.toml files they publish are
+// fetched until the app is shut down.
+func initAnchors(app *App) {
+	if !app.config.AnchorMetadata {
+		return
+	}
+
+	app.anchors = &anchors.Directory{
+		HomeDomain: func(ctx context.Context, issuer string) (string, error) {
+			var accounts []db.CoreAccountRecord
+			err := db.Select(ctx, db.CoreAccountByAddressQuery{
+				SqlQuery: app.CoreQuery(),
+				Address:  issuer,
+			}, &accounts)
+			if err != nil || len(accounts) == 0 {
+				return "", err
+			}
+
+			return accounts[0].HomeDomain.String, nil
+		},
+	}
+	app.anchors.Start(app.ctx)
+}
+
+func init() {
+	appInit.Add("anchors", initAnchors, "app-context", "log", "core-db")
+}
@@ -0,0 +1,39 @@
+package horizon
+
+import (
+	"github.com/stellar/horizon/apikey"
+)
+
+// initAPIKeys creates the keyring of the api keys clients may give, loaded
+// from Config.APIKeysFile and, when Config.APIKeysDB is set, the history
+// database.  No keyring is created when neither is configured, leaving every
+// request limited by client ip.
+func initAPIKeys(app *App) {
+	var sources []apikey.Source
+	if app.config.APIKeysFile != "" {
+		sources = append(sources, &apikey.FileSource{Path: app.config.APIKeysFile})
+	}
+	if app.config.APIKeysDB {
+		sources = append(sources, &apikey.DBSource{DB: app.historyDb})
+	}
+
+	if len(sources) == 0 {
+		if app.config.APIKeysRequired {
+			app.log.Panic("api keys are required, but neither an api keys file nor the db is configured to load them from")
+		}
+		return
+	}
+
+	app.apiKeys = &apikey.Keyring{Sources: sources}
+
+	// fail at startup, rather than on the first request, on keys that cannot
+	// be loaded
+	_, err := app.apiKeys.Lookup(app.ctx, "")
+	if err != nil && err != apikey.ErrUnknownKey {
+		app.log.Panic(err)
+	}
+}
+
+func init() {
+	appInit.Add("api-keys", initAPIKeys, "app-context", "log", "history-db")
+}
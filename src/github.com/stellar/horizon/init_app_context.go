@@ -2,6 +2,7 @@ package horizon
 
 import (
 	"github.com/stellar/horizon/log"
+	"github.com/stellar/horizon/trace"
 	"golang.org/x/net/context"
 )
 
@@ -10,6 +11,9 @@ func initAppContext(app *App) {
 	ctx = context.WithValue(ctx, &appContextKey, app)
 
 	ctx = log.Context(ctx, app.log)
+	if app.tracer != nil {
+		ctx = trace.Context(ctx, app.tracer)
+	}
 	app.ctx = ctx
 	app.cancel = cancel
 }
@@ -19,5 +23,6 @@ func init() {
 		"app-context",
 		initAppContext,
 		"log",
+		"tracing",
 	)
 }
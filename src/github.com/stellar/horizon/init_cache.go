@@ -0,0 +1,47 @@
+package horizon
+
+import (
+	"github.com/stellar/horizon/cache"
+)
+
+// initQueryCache creates the cache holding the results of the queries of
+// resources the app serves again and again, unless Config.QueryCacheSize is
+// zero.  The results of mutable queries are forgotten with every pump, as
+// each ledger is ingested.  When Config.QueryCacheRedis is set, the results
+// of immutable queries are shared through the app's redis.
+func initQueryCache(app *App) {
+	if app.config.QueryCacheSize <= 0 {
+		return
+	}
+
+	app.cache = &cache.Cache{Size: app.config.QueryCacheSize}
+	if app.config.QueryCacheRedis {
+		app.cache.Redis = app.redis
+	}
+
+	// the results of the networks sharing the redis of their parent are kept
+	// apart
+	if app.network != "" {
+		app.cache.Prefix = cache.DefaultPrefix + app.network + ":"
+	}
+
+	go func() {
+		ticks := app.pump.Subscribe()
+
+		for {
+			select {
+			case _, more := <-ticks:
+				if !more {
+					return
+				}
+				app.cache.Invalidate()
+			case <-app.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func init() {
+	appInit.Add("query-cache", initQueryCache, "app-context", "redis", "pump")
+}
@@ -1,57 +1,46 @@
 package horizon
 
 import (
-	"encoding/json"
-	"fmt"
-	"github.com/stellar/horizon/log"
-	"io/ioutil"
 	"net/http"
+
+	"github.com/stellar/horizon/log"
+	"github.com/stellar/horizon/netcheck"
 )
 
+// initStellarCoreInfo loads the version of stellar-core and, unless
+// Config.NetworkPassphrase is set, adopts the passphrase of its network.
 func initStellarCoreInfo(app *App) {
 	if app.config.StellarCoreUrl == "" {
 		return
 	}
 
-	fail := func(err error) {
-		log.Warnf(app.ctx, "could not load stellar-core info: %s", err)
-	}
-
-	resp, err := http.Get(fmt.Sprint(app.config.StellarCoreUrl, "/info"))
-
-	if err != nil {
-		fail(err)
-		return
-	}
-
-	defer resp.Body.Close()
-	contents, err := ioutil.ReadAll(resp.Body)
+	info, err := netcheck.LoadInfo(app.ctx, http.DefaultClient, app.config.StellarCoreUrl)
 	if err != nil {
-		fail(err)
+		log.Warnf(app.ctx, "could not load stellar-core info: %s", err)
 		return
 	}
 
-	var responseJson map[string]*json.RawMessage
-	err = json.Unmarshal(contents, &responseJson)
-	if err != nil {
-		fail(err)
-		return
+	app.coreVersion = info.Build
+	if app.config.NetworkPassphrase == "" {
+		app.networkPassphrase = info.Network
 	}
+}
 
-	var serverInfo map[string]interface{}
-	err = json.Unmarshal(*responseJson["info"], &serverInfo)
-	if err != nil {
-		fail(err)
-		return
+// initNetworkCheck checks that stellar-core and its database are of the
+// network of the app before it ingests or submits anything, and again every
+// Config.NetworkCheckInterval.  Ingestion and submission are refused while
+// they are found to be of another network.
+func initNetworkCheck(app *App) {
+	app.networkCheck = &netcheck.Checker{
+		Passphrase: app.networkPassphrase,
+		CoreURL:    app.config.StellarCoreUrl,
+		Core:       app.CoreQuery(),
 	}
 
-	// TODO: make resilient to changes in stellar-core's info output
-	app.coreVersion = serverInfo["build"].(string)
-	app.networkPassphrase = serverInfo["network"].(string)
-
-	return
+	app.networkCheck.Watch(app.ctx, app.config.NetworkCheckInterval)
 }
 
 func init() {
 	appInit.Add("stellarCoreInfo", initStellarCoreInfo, "app-context", "log")
+	appInit.Add("network-check", initNetworkCheck, "app-context", "log", "core-db", "stellarCoreInfo")
 }
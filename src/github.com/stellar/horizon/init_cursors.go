@@ -0,0 +1,24 @@
+package horizon
+
+import (
+	"github.com/stellar/horizon/db"
+)
+
+// initCursors configures the codec through which the actions of app expose
+// paging tokens to clients.  Unless Config.CursorSecret is set, paging tokens
+// are exposed raw.
+func initCursors(app *App) {
+	if app.config.CursorSecret == "" {
+		app.cursors = nil
+		return
+	}
+
+	app.cursors = &db.CursorCodec{
+		Key:          []byte(app.config.CursorSecret),
+		AcceptLegacy: app.config.CursorAcceptLegacy,
+	}
+}
+
+func init() {
+	appInit.Add("cursors", initCursors)
+}
@@ -0,0 +1,74 @@
+package horizon
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/test"
+)
+
+func TestCursors(t *testing.T) {
+
+	Convey("app.cursors is nil when no CursorSecret is set", t, func() {
+		test.LoadScenario("base")
+		app := NewTestApp()
+		defer app.Close()
+		So(app.cursors, ShouldBeNil)
+
+		rh := NewRequestHelper(app)
+		w := rh.Get("/ledgers?cursor=4294967296", test.RequestHelperNoop)
+		So(w.Code, ShouldEqual, 200)
+	})
+
+	Convey("Opaque cursors", t, func() {
+		test.LoadScenario("base")
+		c := NewTestConfig()
+		c.CursorSecret = "hunter2"
+		app, _ := NewApp(c)
+		defer app.Close()
+		rh := NewRequestHelper(app)
+
+		var page struct {
+			Links struct {
+				Next struct {
+					Href string `json:"href"`
+				} `json:"next"`
+			} `json:"_links"`
+			Embedded struct {
+				Records []struct {
+					Sequence    int32  `json:"sequence"`
+					PagingToken string `json:"paging_token"`
+				} `json:"records"`
+			} `json:"_embedded"`
+		}
+
+		w := rh.Get("/ledgers?limit=1", test.RequestHelperNoop)
+		So(w.Code, ShouldEqual, 200)
+		So(json.Unmarshal(w.Body.Bytes(), &page), ShouldBeNil)
+		So(len(page.Embedded.Records), ShouldEqual, 1)
+
+		token := page.Embedded.Records[0].PagingToken
+		So(token, ShouldNotEqual, "4294967296")
+		So(page.Links.Next.Href, ShouldContainSubstring, "cursor="+token)
+
+		Convey("page the endpoint they were issued for", func() {
+			w := rh.Get(page.Links.Next.Href, test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(json.Unmarshal(w.Body.Bytes(), &page), ShouldBeNil)
+			So(page.Embedded.Records[0].Sequence, ShouldEqual, 2)
+		})
+
+		Convey("are refused by other endpoints", func() {
+			w := rh.Get("/transactions?cursor="+token, test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 400)
+			So(w.Body.String(), ShouldContainSubstring, "invalid_cursor")
+		})
+
+		Convey("take the place of raw paging tokens", func() {
+			w := rh.Get("/ledgers?cursor=4294967296", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 400)
+			So(w.Body.String(), ShouldContainSubstring, "invalid_cursor")
+		})
+	})
+}
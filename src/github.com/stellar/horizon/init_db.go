@@ -1,7 +1,10 @@
 package horizon
 
 import (
+	"os"
+
 	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/log"
 )
 
 func initHistoryDb(app *App) {
@@ -10,6 +13,18 @@ func initHistoryDb(app *App) {
 	if err != nil {
 		app.log.Panic(app.ctx, err)
 	}
+
+	// the history schema is that of horizon-importer, which cannot be held
+	// by databases without range types, so that horizon stops here rather
+	// than on the first query of its history
+	if !db.DialectOf(historyDb).Ranges() {
+		app.log.Panicf("the history schema is unsupported by %s, which lacks the int8range type of history_transactions.time_bounds", db.DialectOf(historyDb).Name())
+	}
+
+	if app.config.LedgerNotifications && !db.DialectOf(historyDb).Notifications() {
+		app.log.Panicf("ledger notifications are unsupported by %s", db.DialectOf(historyDb).Name())
+	}
+
 	historyDb.SetMaxIdleConns(4)
 	historyDb.SetMaxOpenConns(12)
 	app.historyDb = historyDb
@@ -27,7 +42,50 @@ func initCoreDb(app *App) {
 	app.coreDb = coreDb
 }
 
+// initQueryTimeouts configures the timeouts of the database queries of each
+// class, from Config.PageQueryTimeout and its siblings.
+func initQueryTimeouts(app *App) {
+	db.SetQueryTimeouts(db.QueryTimeouts{
+		Page:   app.config.PageQueryTimeout,
+		Stream: app.config.StreamQueryTimeout,
+		Ingest: app.config.IngestQueryTimeout,
+	})
+}
+
+// initSlowQueryLog sets up the log of the queries running for longer than
+// Config.SlowQueryThreshold, appended to Config.SlowQueryLogFile when set and
+// logged along with the rest otherwise, whose lines are counted by route.  The
+// log is that of the queries of every network, set up by the parent of their
+// apps.
+func initSlowQueryLog(app *App) {
+	if app.parent != nil {
+		return
+	}
+
+	l := app.log.WithField("log", "slow_queries")
+
+	if app.config.SlowQueryLogFile != "" {
+		f, err := os.OpenFile(app.config.SlowQueryLogFile, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+		if err != nil {
+			app.log.Panic(app.ctx, err)
+		}
+
+		l, _ = log.New()
+		l.Logger.Out = f
+		l.Logger.Formatter = app.log.Logger.Formatter
+	}
+
+	app.slowQueries = db.SlowQueryLog{
+		Threshold: app.config.SlowQueryThreshold,
+		Log:       l,
+		Routes:    app.web.slowQueryRoutes,
+	}
+	db.SetSlowQueryLog(app.slowQueries)
+}
+
 func init() {
+	appInit.Add("query-timeouts", initQueryTimeouts)
+	appInit.Add("slow-query-log", initSlowQueryLog, "app-context", "log", "web.init")
 	appInit.Add("history-db", initHistoryDb, "app-context", "log")
 	appInit.Add("core-db", initCoreDb, "app-context", "log")
 }
@@ -0,0 +1,42 @@
+package horizon
+
+import (
+	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/friendbot"
+	"github.com/stellar/horizon/log"
+)
+
+// initFriendbot creates the bot funding new accounts from the account of
+// Config.FriendbotSecret, and the verifier of the captchas its clients solve
+// when Config.FriendbotCaptchaSecret is set.  Friendbot is only served once a
+// secret is configured.
+func initFriendbot(app *App) {
+	if app.config.FriendbotSecret == "" {
+		return
+	}
+
+	bot, err := friendbot.New(
+		app.config.FriendbotSecret,
+		app.networkPassphrase,
+		app.config.FriendbotAmount,
+		app.submitter,
+		&db.SequenceProvider{Core: app.coreDb},
+	)
+	if err != nil {
+		log.Panic(app.ctx, err)
+	}
+	app.friendbot = bot
+
+	if app.config.FriendbotCaptchaSecret != "" {
+		app.captcha = &friendbot.SiteVerifier{
+			URL:    app.config.FriendbotCaptchaURL,
+			Secret: app.config.FriendbotCaptchaSecret,
+		}
+	}
+
+	log.WithField(app.ctx, "address", bot.Address()).Info("friendbot enabled")
+}
+
+func init() {
+	appInit.Add("friendbot", initFriendbot, "app-context", "log", "txsub", "core-db")
+}
@@ -0,0 +1,83 @@
+package horizon
+
+import (
+	"sync"
+
+	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/log"
+	"golang.org/x/net/context"
+)
+
+// InflationDestinations is the tally of the votes for each inflation
+// destination, as of the stellar-core ledger Ledger.
+type InflationDestinations struct {
+	Ledger  int32
+	Records []db.CoreInflationDestinationRecord
+}
+
+// inflationDestinations holds the latest tally loaded by
+// App.UpdateInflationDestinations.
+type inflationDestinations struct {
+	lock  sync.RWMutex
+	tally InflationDestinations
+}
+
+// initInflationDestinations tallies the votes for each inflation destination,
+// tallying them again with every pump so that the tally follows the latest
+// ledger.
+func initInflationDestinations(app *App) {
+	app.inflationDestinations = &inflationDestinations{}
+	app.UpdateInflationDestinations(app.ctx)
+
+	go func() {
+		ticks := app.pump.Subscribe()
+
+		for {
+			select {
+			case _, more := <-ticks:
+				if !more {
+					return
+				}
+				app.UpdateInflationDestinations(app.ctx)
+			case <-app.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// InflationDestinations returns the latest tally of the votes for each
+// inflation destination.
+func (a *App) InflationDestinations() InflationDestinations {
+	a.inflationDestinations.lock.RLock()
+	defer a.inflationDestinations.lock.RUnlock()
+	return a.inflationDestinations.tally
+}
+
+// UpdateInflationDestinations tallies again, from the accounts of
+// stellar-core, the votes for each inflation destination.  On failure, the
+// last tally is kept.
+func (a *App) UpdateInflationDestinations(ctx context.Context) {
+	var ls db.LedgerState
+	err := db.Get(ctx, db.LedgerStateQuery{Horizon: a.HistoryQuery(), Core: a.CoreQuery()}, &ls)
+
+	var records []db.CoreInflationDestinationRecord
+	if err == nil {
+		err = db.Select(ctx, db.CoreInflationDestinationsQuery{SqlQuery: a.CoreQuery()}, &records)
+	}
+
+	if err != nil {
+		log.WithStack(ctx, err).
+			WithField("err", err.Error()).
+			Error("failed to tally inflation destinations")
+		return
+	}
+
+	a.inflationDestinations.lock.Lock()
+	a.inflationDestinations.tally = InflationDestinations{Ledger: ls.StellarCoreSequence, Records: records}
+	a.inflationDestinations.lock.Unlock()
+}
+
+func init() {
+	appInit.Add("inflation-destinations", initInflationDestinations, "app-context", "log", "history-db", "core-db", "pump")
+}
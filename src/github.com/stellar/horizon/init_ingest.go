@@ -0,0 +1,109 @@
+package horizon
+
+import (
+	"time"
+
+	"github.com/rcrowley/go-metrics"
+	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/ingest"
+	"github.com/stellar/horizon/log"
+)
+
+// initIngester creates the ingestion system when enabled, ingesting the
+// ledgers closed by stellar-core every second until the app shuts down.  The
+// gaps in history are backfilled apart, so that filling them does not hold up
+// the ingestion of the latest ledgers, and ingested history is verified
+// against stellar-core every IngestVerifyInterval.  Nothing is ingested while
+// stellar-core is found to be of another network.  The queries of ingestion
+// are db.IngestQueries.
+func initIngester(app *App) {
+	if !app.config.Ingest {
+		return
+	}
+
+	processors, err := ingest.NewProcessors(app.config.IngestProcessors)
+	if err != nil {
+		app.log.Panic(app.ctx, err)
+	}
+
+	bus := make(chan struct{}, 1)
+	app.ingestedLedgers = bus
+
+	app.ingester = &ingest.System{
+		HorizonDB:         app.historyDb,
+		CoreDB:            app.coreDb,
+		NetworkPassphrase: app.networkPassphrase,
+		Processors:        processors,
+		Notify:            bus,
+		NotifyPostgres:    app.config.LedgerNotifications,
+		Guard:             app.networkCheck.Err,
+	}
+	app.ingester.Metrics.LedgerTimer = metrics.NewTimer()
+	app.ingester.Metrics.Mismatches = metrics.NewCounter()
+	app.ingester.Metrics.RowMeter = metrics.NewMeter()
+
+	ctx := db.WithQueryClass(app.ctx, db.IngestQueries)
+
+	go func() {
+		ticker := time.NewTicker(1 * time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-app.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			_, err := app.ingester.Tick(ctx)
+			if err != nil {
+				log.WithStack(app.ctx, err).Errorf("ingestion failed: %s", err)
+			}
+		}
+	}()
+
+	go func() {
+		ticker := time.NewTicker(1 * time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-app.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			_, err := app.ingester.Backfill(ctx)
+			if err != nil {
+				log.WithStack(app.ctx, err).Errorf("backfill failed: %s", err)
+			}
+		}
+	}()
+
+	// history that processors index part of cannot be verified
+	if app.config.IngestVerifyInterval <= 0 || len(processors) > 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(app.config.IngestVerifyInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-app.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			_, err := app.ingester.Verify(ctx, ingest.DefaultVerifyAccounts)
+			if err != nil {
+				log.WithStack(app.ctx, err).Errorf("verification failed: %s", err)
+			}
+		}
+	}()
+}
+
+func init() {
+	appInit.Add("ingester", initIngester, "app-context", "log", "history-db", "core-db", "stellarCoreInfo", "network-check")
+}
@@ -10,10 +10,30 @@ import (
 )
 
 // initLog initialized the logging subsystem, attaching app.log and
-// app.logMetrics.  It also configured the logger's level using Config.LogLevel.
+// app.logMetrics.  It also configured the logger's level using Config.LogLevel,
+// its format using Config.LogFormat and the fields of every line it logs using
+// Config.LogFields.  The app of a network logs through the logger of its
+// parent, with the name of the network.
 func initLog(app *App) {
+	if app.parent != nil {
+		app.log = app.parent.log.WithField("network", app.network)
+		app.logMetrics = &log.Metrics{}
+		return
+	}
+
 	l, m := log.New()
 	l.Logger.Level = app.config.LogLevel
+
+	formatter, err := log.ParseFormat(app.config.LogFormat)
+	if err != nil {
+		panic(err)
+	}
+	l.Logger.Formatter = formatter
+
+	if len(app.config.LogFields) > 0 {
+		l = l.WithFields(app.config.LogFields)
+	}
+
 	app.log = l
 	app.logMetrics = m
 }
@@ -21,7 +41,7 @@ func initLog(app *App) {
 // initSentryLog attaches a hook to our logging system that will report
 // errors and panics to the configured sentry server (from Config.SentryDSN).
 func initSentryLog(app *App) {
-	if app.config.SentryDSN == "" {
+	if app.config.SentryDSN == "" || app.parent != nil {
 		return
 	}
 
@@ -46,7 +66,7 @@ func initSentryLog(app *App) {
 // initLogglyLog attaches a loggly hook to our logging system.
 func initLogglyLog(app *App) {
 
-	if app.config.LogglyToken == "" {
+	if app.config.LogglyToken == "" || app.parent != nil {
 		return
 	}
 
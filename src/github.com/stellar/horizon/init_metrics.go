@@ -3,7 +3,9 @@ package horizon
 import (
 	"fmt"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/rcrowley/go-metrics"
+	"github.com/stellar/horizon/db"
 )
 
 func initMetrics(app *App) {
@@ -13,14 +15,31 @@ func initMetrics(app *App) {
 func initDbMetrics(app *App) {
 	app.horizonLedgerGauge = metrics.NewGauge()
 	app.stellarCoreLedgerGauge = metrics.NewGauge()
+	app.historyLagGauge = metrics.NewGauge()
 	app.horizonConnGauge = metrics.NewGauge()
 	app.stellarCoreConnGauge = metrics.NewGauge()
 	app.goroutineGauge = metrics.NewGauge()
 	app.metrics.Register("history.latest_ledger", app.horizonLedgerGauge)
 	app.metrics.Register("stellar_core.latest_ledger", app.stellarCoreLedgerGauge)
+	app.metrics.Register("history.lag_ledgers", app.historyLagGauge)
 	app.metrics.Register("history.open_connections", app.horizonConnGauge)
 	app.metrics.Register("stellar_core.open_connections", app.stellarCoreConnGauge)
 	app.metrics.Register("goroutines", app.goroutineGauge)
+
+	for name, d := range map[string]*sqlx.DB{"history": app.historyDb, "stellar_core": app.coreDb} {
+		pool := db.PoolOf(d)
+		app.metrics.Register(name+".connections_in_use", pool.InUse)
+		app.metrics.Register(name+".connections_waiting", pool.Waiting)
+		app.metrics.Register(name+".connection_wait", pool.Wait)
+		app.metrics.Register(name+".slow_queries", pool.Slow)
+	}
+}
+
+func initSSEMetrics(app *App) {
+	app.sseConnGauge = metrics.NewGauge()
+	app.sseClientGauge = metrics.NewGauge()
+	app.metrics.Register("sse.open_connections", app.sseConnGauge)
+	app.metrics.Register("sse.clients", app.sseClientGauge)
 }
 
 func initLogMetrics(app *App) {
@@ -33,8 +52,10 @@ func initLogMetrics(app *App) {
 func initTxSubMetrics(app *App) {
 	app.submitter.Init(app.ctx)
 	app.metrics.Register("txsub.pending", app.submitter.Metrics.OpenSubmissionsGauge)
+	app.metrics.Register("txsub.queued", app.submitter.Metrics.QueuedSubmissionsGauge)
 	app.metrics.Register("txsub.succeeded", app.submitter.Metrics.SuccessfulSubmissionsMeter)
 	app.metrics.Register("txsub.failed", app.submitter.Metrics.FailedSubmissionsMeter)
+	app.metrics.Register("txsub.resubmitted", app.submitter.Metrics.ResubmissionsMeter)
 	app.metrics.Register("txsub.total", app.submitter.Metrics.SubmissionTimer)
 }
 
@@ -46,10 +67,22 @@ func initWebMetrics(app *App) {
 	app.metrics.Register("requests.failed", app.web.failureMeter)
 }
 
+func initIngesterMetrics(app *App) {
+	if app.ingester == nil {
+		return
+	}
+
+	app.metrics.Register("ingester.ledger_ingestion", app.ingester.Metrics.LedgerTimer)
+	app.metrics.Register("ingester.verification_mismatches", app.ingester.Metrics.Mismatches)
+	app.metrics.Register("ingester.rows", app.ingester.Metrics.RowMeter)
+}
+
 func init() {
 	appInit.Add("metrics", initMetrics)
 	appInit.Add("log.metrics", initLogMetrics, "metrics")
 	appInit.Add("db-metrics", initDbMetrics, "metrics", "history-db", "core-db")
+	appInit.Add("sse.metrics", initSSEMetrics, "sse", "metrics")
 	appInit.Add("web.metrics", initWebMetrics, "web.init", "metrics")
 	appInit.Add("txsub.metrics", initTxSubMetrics, "txsub", "metrics")
+	appInit.Add("ingester.metrics", initIngesterMetrics, "ingester", "metrics")
 }
@@ -0,0 +1,100 @@
+package horizon
+
+import (
+	"net/http"
+	"strings"
+
+	gctx "github.com/goji/context"
+	"github.com/stellar/horizon/httpx"
+	"github.com/zenazn/goji/web"
+)
+
+// initNetworks creates an app for each of Config.Networks, served under the
+// path named for it by the router of app.  The app of a network connects to
+// its own databases and stellar-core, ingests and submits transactions of its
+// own, and is left the middleware of the requests of all networks, such as
+// their rate limit, to app.  Its metrics are registered into the registry of
+// app, prefixed by its name.
+func initNetworks(app *App) {
+	for _, nc := range app.config.Networks {
+		network := &App{
+			config:            networkAppConfig(app.config, nc),
+			network:           nc.Name,
+			parent:            app,
+			horizonVersion:    app.horizonVersion,
+			networkPassphrase: nc.NetworkPassphrase,
+		}
+		appInit.Run(network)
+
+		network.metrics.Each(func(name string, m interface{}) {
+			app.metrics.Register(nc.Name+"."+name, m)
+		})
+
+		h := &networkHandler{app: network, prefix: "/" + nc.Name}
+		app.web.router.Handle(h.prefix, h)
+		app.web.router.Handle(h.prefix+"/*", h)
+		app.networks = append(app.networks, network)
+	}
+}
+
+// networkAppConfig returns the config of the app of network nc: that of its
+// parent, connected to the databases and stellar-core of nc.  The settings of
+// the http server, and those applied by the middleware of the parent, are
+// cleared.
+func networkAppConfig(parent Config, nc NetworkConfig) Config {
+	config := parent
+	config.DatabaseUrl = nc.DatabaseUrl
+	config.StellarCoreDatabaseUrl = nc.StellarCoreDatabaseUrl
+	config.StellarCoreUrl = nc.StellarCoreUrl
+	config.NetworkPassphrase = nc.NetworkPassphrase
+	config.Ingest = nc.Ingest
+	config.FriendbotSecret = nc.FriendbotSecret
+	config.Networks = nil
+	config.AdminAddr = ""
+	config.TLSCertFile = ""
+	config.TLSKeyFile = ""
+	config.RubyHorizonUrl = ""
+	config.TxSubCoreUrls = nil
+	config.APIKeysFile = ""
+	config.APIKeysDB = false
+	config.APIKeysRequired = false
+	config.FederationResolution = false
+	return config
+}
+
+// networkHandler serves the requests of the paths under prefix with the
+// router of the app of a network, as if they were made of the path that
+// follows prefix.
+type networkHandler struct {
+	app    *App
+	prefix string
+}
+
+func (h *networkHandler) ServeHTTPC(c web.C, w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Path
+	r.URL.Path = strings.TrimPrefix(path, h.prefix)
+	if r.URL.Path == "" {
+		r.URL.Path = "/"
+	}
+	defer func() { r.URL.Path = path }()
+
+	// the network routes, and sets the app of, the request apart from the
+	// parent, whose middleware reads its own once the network is done
+	env := make(map[interface{}]interface{}, len(c.Env))
+	for k, v := range c.Env {
+		env[k] = v
+	}
+	delete(env, web.MatchKey)
+	c.Env = env
+	c.URLParams = nil
+
+	// the timeouts of the routes of the network take the place of that of the
+	// parent
+	gctx.Set(&c, httpx.WithoutRequestTimeout(gctx.FromC(c)))
+
+	h.app.web.router.ServeHTTPC(c, w, r)
+}
+
+func init() {
+	appInit.Add("networks", initNetworks, "app-context", "log", "tracing", "redis", "metrics", "web.init")
+}
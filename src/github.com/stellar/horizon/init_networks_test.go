@@ -0,0 +1,70 @@
+package horizon
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/test"
+)
+
+func TestNetworks(t *testing.T) {
+	test.LoadScenario("base")
+	config := NewTestConfig()
+	config.Networks = []NetworkConfig{{
+		Name:                   "public",
+		DatabaseUrl:            test.DatabaseUrl(),
+		StellarCoreDatabaseUrl: test.StellarCoreDatabaseUrl(),
+		NetworkPassphrase:      "Public Global Stellar Network ; September 2015",
+	}}
+	app, err := NewApp(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer app.Close()
+	rh := NewRequestHelper(app)
+
+	Convey("Networks:", t, func() {
+		So(len(app.networks), ShouldEqual, 1)
+		network := app.networks[0]
+		So(network.networkPassphrase, ShouldEqual, "Public Global Stellar Network ; September 2015")
+
+		Convey("serve their resources under their path, linking to it", func() {
+			w := rh.Get("/public/ledgers/1", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Body.String(), ShouldContainSubstring, `"href": "/public/ledgers/1"`)
+
+			w = rh.Get("/ledgers/1", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Body.String(), ShouldContainSubstring, `"href": "/ledgers/1"`)
+		})
+
+		Convey("serve their root at their path", func() {
+			w := rh.Get("/public", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Body.String(), ShouldContainSubstring, `"/public/`)
+		})
+
+		Convey("leave the operational endpoints to their parent", func() {
+			w := rh.Get("/public/metrics", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 404)
+		})
+
+		Convey("register their metrics with their parent, by name", func() {
+			So(app.metrics.Get("public.history.latest_ledger"), ShouldNotBeNil)
+		})
+	})
+
+	Convey("networkAppConfig clears the settings of the parent's server", t, func() {
+		parent := NewTestConfig()
+		parent.AdminAddr = "127.0.0.1:8001"
+		parent.Networks = config.Networks
+		parent.FriendbotSecret = "SDHOAMBNLGCE2MV5ZKIVZAQD3VCLGP53P3OBSBI6UN5L5XZI5TKHFQL4"
+
+		c := networkAppConfig(parent, config.Networks[0])
+		So(c.NetworkPassphrase, ShouldEqual, config.Networks[0].NetworkPassphrase)
+		So(c.Networks, ShouldBeNil)
+		So(c.AdminAddr, ShouldBeBlank)
+		So(c.FriendbotSecret, ShouldBeBlank)
+		So(c.RateLimit, ShouldEqual, parent.RateLimit)
+	})
+}
@@ -0,0 +1,72 @@
+package horizon
+
+import (
+	"github.com/stellar/go-stellar-base/xdr"
+	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/log"
+	"github.com/stellar/horizon/paths"
+	"golang.org/x/net/context"
+)
+
+// initPaths creates the finder through which paths are searched for, and
+// refreshes its graph of the order books with every pump, so that searches see
+// the offers of the latest ledger.
+func initPaths(app *App) {
+	app.paths = &paths.Finder{}
+	app.UpdatePaths(app.ctx)
+
+	go func() {
+		ticks := app.pump.Subscribe()
+
+		for {
+			select {
+			case _, more := <-ticks:
+				if !more {
+					return
+				}
+				app.UpdatePaths(app.ctx)
+			case <-app.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// UpdatePaths reloads every offer from stellar-core into the path finder.  On
+// failure, the finder keeps searching the last graph loaded.
+func (a *App) UpdatePaths(ctx context.Context) {
+	var records []db.CoreOfferRecord
+	err := db.Select(ctx, db.CoreOffersQuery{SqlQuery: a.CoreQuery()}, &records)
+
+	if err != nil {
+		log.WithStack(ctx, err).
+			WithField("err", err.Error()).
+			Error("failed to load offers for path finding")
+		return
+	}
+
+	offers := make([]paths.Offer, len(records))
+	for i, r := range records {
+		offers[i] = paths.Offer{
+			Selling: pathAsset(r.SellingAssetType, r.SellingAssetCode.String, r.SellingIssuer.String),
+			Buying:  pathAsset(r.BuyingAssetType, r.BuyingAssetCode.String, r.BuyingIssuer.String),
+			Amount:  r.Amount,
+			Pricen:  r.Pricen,
+			Priced:  r.Priced,
+		}
+	}
+
+	a.paths.Update(offers)
+}
+
+func pathAsset(t int32, code, issuer string) paths.Asset {
+	if xdr.AssetType(t) == xdr.AssetTypeAssetTypeNative {
+		return paths.Native
+	}
+
+	return paths.Asset{Type: xdr.AssetType(t), Code: code, Issuer: issuer}
+}
+
+func init() {
+	appInit.Add("paths", initPaths, "app-context", "log", "core-db", "pump")
+}
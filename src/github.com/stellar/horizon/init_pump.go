@@ -6,12 +6,21 @@ import (
 	"time"
 )
 
+// initPump creates the pump driving streams and the processes following
+// history.  A horizon that ingests pumps as each ledger it ingests commits;
+// others listen for the ledgers ingested when notified of them over postgres,
+// or check for them every second.
 func initPump(app *App) {
 	var trigger <-chan struct{}
 
-	if app.config.Autopump {
+	switch {
+	case app.config.Autopump:
 		trigger = pump.Tick(1 * time.Second)
-	} else {
+	case app.ingestedLedgers != nil:
+		trigger = app.ingestedLedgers
+	case app.config.LedgerNotifications:
+		trigger = db.NewLedgerNotifyPump(app.ctx, app.config.DatabaseUrl)
+	default:
 		trigger = db.NewLedgerClosePump(app.ctx, app.historyDb)
 	}
 
@@ -19,5 +28,5 @@ func initPump(app *App) {
 }
 
 func init() {
-	appInit.Add("pump", initPump, "app-context", "log", "history-db", "core-db")
+	appInit.Add("pump", initPump, "app-context", "log", "history-db", "core-db", "ingester")
 }
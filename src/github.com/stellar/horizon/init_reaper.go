@@ -0,0 +1,47 @@
+package horizon
+
+import (
+	"time"
+
+	"github.com/stellar/horizon/log"
+	"github.com/stellar/horizon/reap"
+)
+
+// initReaper creates the reaper of the history older than the retention
+// window, when one is configured.  Each second the app is idle, the reaper
+// deletes a batch of expired ledgers.
+func initReaper(app *App) {
+	if app.config.HistoryRetention <= 0 {
+		return
+	}
+
+	app.reaper = &reap.System{
+		HorizonDB:       app.historyDb,
+		RetentionWindow: app.config.HistoryRetention,
+		Idle: func() bool {
+			return app.ingester == nil || !app.ingester.Busy()
+		},
+	}
+
+	go func() {
+		ticker := time.NewTicker(1 * time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-app.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			_, err := app.reaper.Tick(app.ctx)
+			if err != nil {
+				log.WithStack(app.ctx, err).Errorf("reaping failed: %s", err)
+			}
+		}
+	}()
+}
+
+func init() {
+	appInit.Add("reaper", initReaper, "app-context", "log", "history-db", "ingester")
+}
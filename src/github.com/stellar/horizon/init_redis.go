@@ -8,7 +8,14 @@ import (
 	"github.com/stellar/horizon/log"
 )
 
+// initRedis connects to Config.RedisUrl, if set.  The apps of networks share
+// the connections of their parent.
 func initRedis(app *App) {
+	if app.parent != nil {
+		app.redis = app.parent.redis
+		return
+	}
+
 	if app.config.RedisUrl == "" {
 		return
 	}
@@ -0,0 +1,40 @@
+package horizon
+
+import (
+	"github.com/stellar/horizon/render/sse"
+)
+
+// initSSE creates the registry that tracks and limits the streaming
+// connections open across the app, configured from Config.SSEMaxConnections and
+// Config.SSEMaxConnectionsPerIP, along with the hub through which streams share
+// their queries and, unless Config.SSEReplaySize is zero, the buffer from which
+// reconnecting clients are replayed missed events.
+func initSSE(app *App) {
+	app.streams = sse.NewConnectionRegistry(
+		app.config.SSEMaxConnections,
+		app.config.SSEMaxConnectionsPerIP,
+	)
+	app.streamHub = sse.NewHub(app.streamPumped)
+
+	if app.config.SSEReplaySize > 0 {
+		app.streamReplay = sse.NewReplayBuffer(
+			app.config.SSEReplaySize,
+			app.config.SSEReplayWindow,
+		)
+	}
+}
+
+// streamPumped returns a channel closed the next time the pump the streams of
+// the app are driven by sends: that of its network, for the app of a network
+// in Config.Networks, and the default pump of sse otherwise.
+func (a *App) streamPumped() <-chan struct{} {
+	if a.streamPump != nil {
+		return a.streamPump.Pumped()
+	}
+
+	return sse.Pumped()
+}
+
+func init() {
+	appInit.Add("sse", initSSE, "app-context")
+}
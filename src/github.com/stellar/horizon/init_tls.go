@@ -0,0 +1,123 @@
+package horizon
+
+import (
+	"crypto/tls"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-errors/errors"
+	"github.com/stellar/horizon/log"
+	"golang.org/x/net/context"
+)
+
+// keypairCheckInterval is how often a keypair checks whether its files have
+// changed, at most
+const keypairCheckInterval = time.Minute
+
+// keypair is the certificate the public api is served over TLS with, loaded
+// from a cert and key file.  It is loaded anew once the files change, so that
+// certificates renewed on disk, as by certbot, are served without restarting
+// horizon and dropping its streams.
+type keypair struct {
+	ctx      context.Context
+	certFile string
+	keyFile  string
+
+	lock     sync.Mutex
+	cert     *tls.Certificate
+	modified time.Time
+	checked  time.Time
+}
+
+// loadKeypair loads the keypair of certFile and keyFile
+func loadKeypair(ctx context.Context, certFile, keyFile string) (*keypair, error) {
+	k := &keypair{ctx: ctx, certFile: certFile, keyFile: keyFile}
+
+	err := k.load(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return k, nil
+}
+
+// GetCertificate is a method for tls.Config, returning the certificate as of
+// its files last checked.  Certificates that fail to load are logged, the one
+// last loaded being served until they are fixed.
+func (k *keypair) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	now := time.Now()
+	if now.Sub(k.checked) < keypairCheckInterval {
+		return k.cert, nil
+	}
+
+	k.checked = now
+	if !k.latestModTime().After(k.modified) {
+		return k.cert, nil
+	}
+
+	err := k.load(now)
+	if err != nil {
+		log.Errorf(k.ctx, "could not reload tls certificate: %s", err)
+	}
+
+	return k.cert, nil
+}
+
+// load loads the keypair from its files as of now
+func (k *keypair) load(now time.Time) error {
+	cert, err := tls.LoadX509KeyPair(k.certFile, k.keyFile)
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	k.cert = &cert
+	k.modified = k.latestModTime()
+	k.checked = now
+	return nil
+}
+
+// latestModTime returns the time the later changed file of k was changed
+func (k *keypair) latestModTime() time.Time {
+	var latest time.Time
+
+	for _, path := range []string{k.certFile, k.keyFile} {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+
+	return latest
+}
+
+// initTLS loads the certificate the public api is served with over TLS, from
+// Config.TLSCertFile and Config.TLSKeyFile.  The api is served in the clear
+// when neither is configured.
+func initTLS(app *App) {
+	if app.config.TLSCertFile == "" && app.config.TLSKeyFile == "" {
+		return
+	}
+
+	if app.config.TLSCertFile == "" || app.config.TLSKeyFile == "" {
+		log.Panic(app.ctx, "tls needs both a cert file and a key file")
+	}
+
+	k, err := loadKeypair(app.ctx, app.config.TLSCertFile, app.config.TLSKeyFile)
+	if err != nil {
+		log.Panic(app.ctx, err)
+	}
+
+	app.keypair = k
+}
+
+func init() {
+	appInit.Add("tls", initTLS, "app-context", "log")
+}
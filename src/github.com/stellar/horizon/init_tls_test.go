@@ -0,0 +1,113 @@
+package horizon
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"golang.org/x/net/context"
+)
+
+// writeKeypair writes a self signed certificate for name, and its key, into
+// dir, returning the paths of the files.
+func writeKeypair(dir, name string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	So(err, ShouldBeNil)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	So(err, ShouldBeNil)
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	So(err, ShouldBeNil)
+
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	certPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPem := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	So(ioutil.WriteFile(certFile, certPem, 0600), ShouldBeNil)
+	So(ioutil.WriteFile(keyFile, keyPem, 0600), ShouldBeNil)
+	return certFile, keyFile
+}
+
+func commonName(k *keypair) string {
+	cert, err := k.GetCertificate(nil)
+	So(err, ShouldBeNil)
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	So(err, ShouldBeNil)
+	return leaf.Subject.CommonName
+}
+
+func TestKeypair(t *testing.T) {
+	Convey("keypair", t, func() {
+		dir, err := ioutil.TempDir("", "horizon-tls")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(dir)
+
+		certFile, keyFile := writeKeypair(dir, "old.example.com")
+		k, err := loadKeypair(context.Background(), certFile, keyFile)
+		So(err, ShouldBeNil)
+		So(commonName(k), ShouldEqual, "old.example.com")
+
+		Convey("fails to load files that are not a keypair", func() {
+			_, err := loadKeypair(context.Background(), certFile, certFile)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("reloads renewed certificates once checked again", func() {
+			writeKeypair(dir, "new.example.com")
+			later := time.Now().Add(time.Minute)
+			So(os.Chtimes(certFile, later, later), ShouldBeNil)
+			So(commonName(k), ShouldEqual, "old.example.com")
+
+			k.checked = time.Now().Add(-keypairCheckInterval)
+			So(commonName(k), ShouldEqual, "new.example.com")
+		})
+
+		Convey("serves the last certificate loaded while the files are broken", func() {
+			So(ioutil.WriteFile(keyFile, []byte("broken"), 0600), ShouldBeNil)
+			later := time.Now().Add(time.Minute)
+			So(os.Chtimes(keyFile, later, later), ShouldBeNil)
+
+			k.checked = time.Now().Add(-keypairCheckInterval)
+			So(commonName(k), ShouldEqual, "old.example.com")
+		})
+	})
+}
+
+// http2Writer is a response writer that may be flushed but not hijacked, as
+// those of HTTP/2 responses
+type http2Writer struct {
+	*httptest.ResponseRecorder
+}
+
+func TestWrapWriter(t *testing.T) {
+	Convey("wrapWriter keeps the writers of HTTP/2 responses flushable", t, func() {
+		w := http2Writer{httptest.NewRecorder()}
+		mw := wrapWriter(w)
+
+		f, ok := mw.(http.Flusher)
+		So(ok, ShouldBeTrue)
+		f.Flush()
+		So(w.Flushed, ShouldBeTrue)
+	})
+}
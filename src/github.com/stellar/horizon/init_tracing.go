@@ -0,0 +1,33 @@
+package horizon
+
+import (
+	"github.com/stellar/horizon/trace"
+)
+
+// initTracing creates the tracer that exports the spans of horizon to
+// Config.TracingUrl.  Tracing is disabled when no url is configured.  The apps
+// of networks share the tracer of their parent.
+func initTracing(app *App) {
+	if app.parent != nil {
+		app.tracer = app.parent.tracer
+		return
+	}
+
+	if app.config.TracingUrl == "" {
+		return
+	}
+
+	exporter := &trace.OTLPExporter{
+		URL:         app.config.TracingUrl,
+		ServiceName: app.config.TracingServiceName,
+	}
+
+	app.tracer = trace.NewTracer(exporter, app.config.TracingSampleRate)
+	app.tracer.OnError = func(err error) {
+		app.log.Warnf("failed to export spans: %s", err)
+	}
+}
+
+func init() {
+	appInit.Add("tracing", initTracing, "log")
+}
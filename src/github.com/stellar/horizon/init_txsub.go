@@ -7,14 +7,40 @@ import (
 )
 
 func initSubmissionSystem(app *App) {
+	submitter := txsub.NewDefaultSubmitter(http.DefaultClient, app.config.StellarCoreUrl)
+
+	var multi *txsub.MultiSubmitter
+	if len(app.config.TxSubCoreUrls) > 0 {
+		urls := append([]string{app.config.StellarCoreUrl}, app.config.TxSubCoreUrls...)
+		multi = txsub.NewMultiSubmitter(http.DefaultClient, urls)
+		submitter = multi
+	}
+
 	app.submitter = &txsub.System{
 		Pending:   txsub.NewDefaultSubmissionList(),
-		Submitter: txsub.NewDefaultSubmitter(http.DefaultClient, app.config.StellarCoreUrl),
+		Submitter: submitter,
 		Results: &db.ResultProvider{
 			Core:    app.coreDb,
 			History: app.historyDb,
 		},
-		NetworkPassphrase: app.networkPassphrase,
+		NetworkPassphrase:  app.networkPassphrase,
+		Queue:              txsub.NewDefaultSubmissionQueue(0),
+		Sequences:          &db.SequenceProvider{Core: app.coreDb},
+		MaxOpenSubmissions: app.config.TxSubMaxOpen,
+		Guard:              app.networkCheck.Err,
+		Validator: &txsub.Validator{
+			MaxOperations: app.config.TxSubMaxOperations,
+			MaxSignatures: app.config.TxSubMaxSignatures,
+			MaxFee:        app.config.TxSubMaxFee,
+			MaxDelay:      app.config.TxSubMaxDelay,
+		},
+	}
+
+	if app.config.TxResubmitLedgers > 0 {
+		app.submitter.Resubmitter = &txsub.Resubmitter{
+			Ledgers:     app.config.TxResubmitLedgers,
+			MaxAttempts: app.config.TxResubmitMaxAttempts,
+		}
 	}
 
 	go func() {
@@ -26,8 +52,21 @@ func initSubmissionSystem(app *App) {
 		}
 	}()
 
+	// health checks run apart from ticks, so that an unresponsive instance
+	// does not hold up the submission system
+	if multi != nil {
+		go func() {
+			ticks := app.pump.Subscribe()
+
+			for {
+				<-ticks
+				multi.CheckHealth(app.ctx)
+			}
+		}()
+	}
+
 }
 
 func init() {
-	appInit.Add("txsub", initSubmissionSystem, "app-context", "log", "history-db", "core-db", "pump")
+	appInit.Add("txsub", initSubmissionSystem, "app-context", "log", "history-db", "core-db", "pump", "network-check")
 }
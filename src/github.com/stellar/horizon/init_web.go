@@ -4,28 +4,54 @@ import (
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"sort"
 	"strings"
 
-	"github.com/PuerkitoBio/throttled"
-	"github.com/PuerkitoBio/throttled/store"
 	"github.com/rcrowley/go-metrics"
-	"github.com/rs/cors"
 	"github.com/sebest/xff"
 	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/federation"
+	"github.com/stellar/horizon/friendbot"
+	"github.com/stellar/horizon/netcheck"
+	"github.com/stellar/horizon/prometheus"
+	"github.com/stellar/horizon/ratelimit"
 	"github.com/stellar/horizon/render/problem"
+	"github.com/stellar/horizon/strkeys"
+	"github.com/stellar/horizon/txsub"
 	"github.com/zenazn/goji/web"
 	"github.com/zenazn/goji/web/middleware"
+	"golang.org/x/net/context"
 )
 
 // Web contains the http server related fields for horizon: the router,
 // rate limiter, etc.
 type Web struct {
 	router      *web.Mux
-	rateLimiter *throttled.Throttler
+	rateLimiter *ratelimit.Throttler
+
+	// adminRouter routes the requests of the admin server, nil when there is
+	// none
+	adminRouter *web.Mux
+
+	// the limiters of transaction submissions, by client ip and by source
+	// account, either of which may be nil
+	txsubIPLimiter      *ratelimit.Throttler
+	txsubAccountLimiter *ratelimit.Throttler
+
+	// the limiters of requests of friendbot, by client ip and by the account
+	// to fund, either of which may be nil
+	friendbotIPLimiter      *ratelimit.Throttler
+	friendbotAccountLimiter *ratelimit.Throttler
 
 	requestTimer metrics.Timer
 	failureMeter metrics.Meter
 	successMeter metrics.Meter
+
+	// routeHistogram counts the latency of requests by route and method
+	routeHistogram *prometheus.HistogramVec
+
+	// slowQueryRoutes counts the slow queries of requests by route
+	slowQueryRoutes *prometheus.CounterVec
 }
 
 // initWeb installed a new Web instance onto the provided app object.
@@ -35,43 +61,183 @@ func initWeb(app *App) {
 		requestTimer: metrics.NewTimer(),
 		failureMeter: metrics.NewMeter(),
 		successMeter: metrics.NewMeter(),
+		routeHistogram: prometheus.NewHistogramVec(
+			"horizon_http_request_duration_seconds",
+			"The latency of http requests, by route and method.",
+			prometheus.DefaultBuckets,
+			"route", "method",
+		),
+		slowQueryRoutes: prometheus.NewCounterVec(
+			"horizon_db_slow_queries_total",
+			"The database queries that ran past the slow query threshold, by the route of their request.",
+			"route",
+		),
 	}
 
 	// register problems
 	problem.RegisterError(db.ErrNoResults, problem.NotFound)
+	problem.RegisterError(db.ErrInvalidCursor, problem.P{
+		Type:   "invalid_cursor",
+		Title:  "Invalid Cursor",
+		Status: http.StatusBadRequest,
+		Detail: "The cursor provided is not one issued by this server for this endpoint.  Use the paging_token of a record, or the links of a page, found at the endpoint to page through its results.",
+	})
+	problem.RegisterError(strkeys.ErrInvalid, problem.InvalidAccountID)
+	problem.RegisterError(strkeys.ErrSeed, problem.SecretSeedGiven)
+	problem.RegisterError(txsub.ErrTooManyOpenSubmissions, problem.TooManySubmissions)
+	problem.RegisterError(txsub.ErrShuttingDown, problem.P{
+		Type:   "submission_interrupted",
+		Title:  "Submission Interrupted",
+		Status: http.StatusServiceUnavailable,
+		Detail: "This server shut down before the result of the transaction was known.  A transaction already sent to stellar-core may yet be included in a ledger: look it up by hash before submitting it again.",
+	})
+	problem.RegisterError(friendbot.ErrInvalidAddress, problem.P{
+		Type:   "invalid_address",
+		Title:  "Invalid Address",
+		Status: http.StatusBadRequest,
+		Detail: "The addr param must be the address of the account to fund, such as GABC...",
+	})
+	problem.RegisterError(friendbot.ErrAccountExists, problem.P{
+		Type:   "account_exists",
+		Title:  "Account Exists",
+		Status: http.StatusBadRequest,
+		Detail: "The account asked to be funded already exists.  Friendbot only funds new accounts.",
+	})
+	problem.RegisterError(friendbot.ErrCaptchaFailed, problem.P{
+		Type:   "captcha_failed",
+		Title:  "Captcha Failed",
+		Status: http.StatusForbidden,
+		Detail: "This server asks clients of friendbot to solve a captcha, and the captcha param of this request was missing or not solved.",
+	})
+	problem.RegisterError(netcheck.ErrMismatch, problem.P{
+		Type:   "network_mismatch",
+		Title:  "Network Mismatch",
+		Status: http.StatusServiceUnavailable,
+		Detail: "This server found the stellar-core it submits transactions to to be on a network other than the one it is configured for, and refuses submissions until they agree.  The transaction was not submitted.",
+	})
+	problem.RegisterError(db.ErrQueryTimeout, problem.Timeout)
+	problem.RegisterError(context.DeadlineExceeded, problem.Timeout)
 }
 
 // initWebMiddleware installs the middleware stack used for horizon onto the
-// provided app.
+// provided app.  The requests of the app of a network have been through the
+// middleware of its parent, which routes them to it, and are left to the
+// middleware particular to the network.
 func initWebMiddleware(app *App) {
+	if app.parent != nil {
+		initNetworkMiddleware(app)
+		return
+	}
 
 	r := app.web.router
 	r.Use(stripTrailingSlashMiddleware())
 	r.Use(middleware.EnvInit)
 	r.Use(app.Middleware)
-	r.Use(middleware.RequestID)
+	r.Use(requestIDMiddleware)
 	r.Use(contextMiddleware(app.ctx))
+	r.Use(tracingMiddleware)
 	r.Use(xff.Handler)
 	r.Use(LoggerMiddleware)
 	r.Use(requestMetricsMiddleware)
 	r.Use(RecoverMiddleware)
 	r.Use(middleware.AutomaticOptions)
 
-	c := cors.New(cors.Options{
-		AllowedOrigins: []string{"*"},
-		AllowedHeaders: []string{"*"},
-	})
-	r.Use(c.Handler)
+	r.Use(corsMiddleware(app.config))
 
+	if app.apiKeys != nil {
+		r.Use(apiKeyMiddleware(app))
+	}
 	r.Use(app.web.RateLimitMiddleware)
+	r.Use(app.web.TxSubRateLimitMiddleware)
+	r.Use(app.web.FriendbotRateLimitMiddleware)
+
+	if app.config.FederationResolution {
+		r.Use(federationMiddleware(&federation.Resolver{}))
+	}
+
+	// route before dispatching, so that requestMetricsMiddleware finds the
+	// route of each request
+	r.Use(r.Router)
+	r.Use(queryRouteMiddleware)
+	r.Use(requestTimeoutMiddleware)
+}
+
+// initNetworkMiddleware installs the middleware stack of the app of a network
+// onto the provided app: that setting the app and logger of its requests, and
+// the limits of its submissions and of its friendbot.
+func initNetworkMiddleware(app *App) {
+	r := app.web.router
+	r.Use(app.Middleware)
+	r.Use(networkContextMiddleware(app))
+	r.Use(app.web.TxSubRateLimitMiddleware)
+	r.Use(app.web.FriendbotRateLimitMiddleware)
+
+	r.Use(r.Router)
+	r.Use(queryRouteMiddleware)
+	r.Use(requestTimeoutMiddleware)
 }
 
 // initWebActions installs the routing configuration of horizon onto the
-// provided app.  All route registration should be implemented here.
+// provided app.
 func initWebActions(app *App) {
-	r := app.web.router
+	routeWebActions(app, routes{Mux: app.web.router})
+}
+
+// RouteSegments returns the first segments of the paths of the routes of
+// horizon, such as "ledgers", in order.  The networks of Config.Networks are
+// served under the path of their name, and so cannot be named for any.
+func RouteSegments() []string {
+	r := routes{Mux: web.New(), segments: map[string]bool{}}
+	routeWebActions(&App{}, r)
+
+	segments := make([]string, 0, len(r.segments))
+	for segment := range r.segments {
+		segments = append(segments, segment)
+	}
+	sort.Strings(segments)
+	return segments
+}
+
+// routes registers routes onto a router, recording the first segment of the
+// path of each into segments, when not nil.
+type routes struct {
+	*web.Mux
+	segments map[string]bool
+}
+
+func (r routes) Get(pattern string, handler web.HandlerType) {
+	r.record(pattern)
+	r.Mux.Get(pattern, handler)
+}
+
+func (r routes) Post(pattern string, handler web.HandlerType) {
+	r.record(pattern)
+	r.Mux.Post(pattern, handler)
+}
+
+func (r routes) record(pattern string) {
+	if r.segments == nil {
+		return
+	}
+
+	segment := strings.SplitN(strings.TrimPrefix(pattern, "/"), "/", 2)[0]
+	if segment != "" {
+		r.segments[segment] = true
+	}
+}
+
+// routeWebActions registers the routes of app onto r.  All route registration
+// should be implemented here.
+func routeWebActions(app *App, r routes) {
 	r.Get("/", &RootAction{})
-	r.Get("/metrics", &MetricsAction{})
+
+	// operational endpoints move to the admin server, when there is one, and
+	// those of networks are served by their parent
+	if app.config.AdminAddr == "" && app.parent == nil {
+		r.Get("/metrics", &MetricsAction{})
+		r.Get("/metrics/prometheus", prometheusMetricsHandler)
+		r.Get("/admin/ingestion", &IngestionStatusAction{})
+	}
 
 	// ledger actions
 	r.Get("/ledgers", &LedgerIndexAction{})
@@ -80,14 +246,19 @@ func initWebActions(app *App) {
 	r.Get("/ledgers/:ledger_id/operations", &OperationIndexAction{})
 	r.Get("/ledgers/:ledger_id/payments", &PaymentsIndexAction{})
 	r.Get("/ledgers/:ledger_id/effects", &EffectIndexAction{})
+	r.Get("/ledgers/:ledger_id/stats", &LedgerStatsAction{})
 
 	// account actions
 	r.Get("/accounts", &AccountIndexAction{})
+	r.Post("/accounts/batch", &AccountBatchAction{})
 	r.Get("/accounts/:id", &AccountShowAction{})
 	r.Get("/accounts/:account_id/transactions", &TransactionIndexAction{})
 	r.Get("/accounts/:account_id/operations", &OperationIndexAction{})
 	r.Get("/accounts/:account_id/payments", &PaymentsIndexAction{})
+	r.Get("/accounts/:account_id/payments/summary", &PaymentSummaryAction{})
+	r.Get("/accounts/:account_id/pending_payments", &PendingPaymentsIndexAction{})
 	r.Get("/accounts/:account_id/effects", &EffectIndexAction{})
+	r.Get("/accounts/:account_id/balances", &AccountBalancesAction{})
 	r.Get("/accounts/:account_id/offers", &OffersByAccountAction{})
 	r.Get("/accounts/:account_id/trades", &TradeIndexAction{})
 
@@ -97,6 +268,7 @@ func initWebActions(app *App) {
 	r.Get("/transactions/:tx_id/operations", &OperationIndexAction{})
 	r.Get("/transactions/:tx_id/payments", &PaymentsIndexAction{})
 	r.Get("/transactions/:tx_id/effects", &EffectIndexAction{})
+	r.Get("/transactions/:tx_id/status", &TransactionStatusAction{})
 
 	// operation actions
 	r.Get("/operations", &OperationIndexAction{})
@@ -105,16 +277,37 @@ func initWebActions(app *App) {
 
 	r.Get("/payments", &PaymentsIndexAction{})
 	r.Get("/effects", &EffectIndexAction{})
+	r.Get("/trades", &TradeIndexAction{})
 
+	r.Get("/assets", &AssetsIndexAction{})
+	r.Get("/assets/:asset/holders", &AssetHoldersIndexAction{})
+	r.Get("/signers/:signer_id/accounts", &SignerAccountsIndexAction{})
 	r.Get("/offers/:id", &NotImplementedAction{})
 	r.Get("/order_book", &OrderBookShowAction{})
 	r.Get("/order_book/trades", &TradeIndexAction{})
+	r.Get("/paths/strict-receive", &PathStrictReceiveAction{})
+	r.Get("/paths/strict-send", &PathStrictSendAction{})
+	r.Get("/fee_stats", &FeeStatsAction{})
+	r.Get("/inflation_destinations", &InflationDestinationsAction{})
 
 	r.Post("/transactions", &TransactionCreateAction{})
+	r.Post("/transactions/simulate", &TransactionSimulateAction{})
+	r.Post("/transactions_async", &TransactionCreateAsyncAction{})
+
+	// multiplexed streaming
+	r.Get("/stream", &StreamAction{})
+
+	// graphql
+	r.Get("/graphql", &GraphQLAction{})
+	r.Post("/graphql", &GraphQLAction{})
 
-	// horizon doesn't implement everything ruby-horizon did,
-	// so we reverse proxy if we can
-	if app.config.RubyHorizonUrl != "" {
+	// friendbot is served by horizon itself when it has a secret to fund
+	// accounts with, and otherwise by ruby-horizon, if we can reverse proxy
+	// to it
+	if app.config.FriendbotSecret != "" {
+		r.Post("/friendbot", &FriendbotAction{})
+		r.Get("/friendbot", &FriendbotAction{})
+	} else if app.config.RubyHorizonUrl != "" {
 
 		u, err := url.Parse(app.config.RubyHorizonUrl)
 		if err != nil {
@@ -132,21 +325,86 @@ func initWebActions(app *App) {
 	r.NotFound(&NotFoundAction{})
 }
 
+// initWebRateLimiter installs the token bucket rate limiters of the app: that
+// of every request, by client ip, and those of transaction submissions and of
+// friendbot, whose buckets hold the requests of an hour of their quota.
 func initWebRateLimiter(app *App) {
-	rateLimitStore := store.NewMemStore(1000)
-
-	if app.redis != nil {
-		rateLimitStore = store.NewRedisStore(app.redis, "throttle:", 0)
-	}
-
-	rateLimiter := throttled.RateLimit(
-		app.config.RateLimit,
-		&throttled.VaryBy{Custom: remoteAddrIP},
-		rateLimitStore,
+	rateLimiter := ratelimit.New(
+		ratelimit.FromQuota(app.config.RateLimit, app.config.RateLimitBurst),
+		remoteAddrIP,
+		newRateLimitStore(app, "throttle:"),
 	)
 
 	rateLimiter.DeniedHandler = &RateLimitExceededAction{App: app, Action: Action{}}
 	app.web.rateLimiter = rateLimiter
+
+	if app.config.TxSubRateLimit != nil {
+		app.web.txsubIPLimiter = ratelimit.New(
+			ratelimit.FromQuota(app.config.TxSubRateLimit, 0),
+			remoteAddrIP,
+			newRateLimitStore(app, "throttle:txsub:ip:"),
+		)
+		app.web.txsubIPLimiter.DeniedHandler = &RateLimitExceededAction{App: app, Action: Action{}}
+	}
+
+	if app.config.TxSubAccountRateLimit != nil {
+		app.web.txsubAccountLimiter = ratelimit.New(
+			ratelimit.FromQuota(app.config.TxSubAccountRateLimit, 0),
+			func(r *http.Request) string {
+				return sourceAddress(app, r)
+			},
+			newRateLimitStore(app, "throttle:txsub:account:"),
+		)
+		app.web.txsubAccountLimiter.DeniedHandler = &RateLimitExceededAction{
+			App:     app,
+			Action:  Action{},
+			Problem: &problem.SourceAccountRateLimitExceeded,
+		}
+	}
+
+	if app.config.FriendbotSecret == "" {
+		return
+	}
+
+	if app.config.FriendbotRateLimit != nil {
+		app.web.friendbotIPLimiter = ratelimit.New(
+			ratelimit.FromQuota(app.config.FriendbotRateLimit, 0),
+			remoteAddrIP,
+			newRateLimitStore(app, "throttle:friendbot:ip:"),
+		)
+		app.web.friendbotIPLimiter.DeniedHandler = &RateLimitExceededAction{App: app, Action: Action{}}
+	}
+
+	if app.config.FriendbotAccountLimit != nil {
+		app.web.friendbotAccountLimiter = ratelimit.New(
+			ratelimit.FromQuota(app.config.FriendbotAccountLimit, 0),
+			func(r *http.Request) string {
+				return r.FormValue("addr")
+			},
+			newRateLimitStore(app, "throttle:friendbot:account:"),
+		)
+		app.web.friendbotAccountLimiter.DeniedHandler = &RateLimitExceededAction{
+			App:     app,
+			Action:  Action{},
+			Problem: &friendbotAccountRateLimitExceeded,
+		}
+	}
+}
+
+// newRateLimitStore returns the store of the buckets of a rate limiter:
+// redis, under the provided key prefix, when the app has a redis connection,
+// and memory otherwise.  The buckets of the app of a network are kept apart
+// from those of its parent under the name of the network.
+func newRateLimitStore(app *App, prefix string) ratelimit.Store {
+	if app.network != "" {
+		prefix += app.network + ":"
+	}
+
+	if app.redis != nil {
+		return &ratelimit.RedisStore{Pool: app.redis, Prefix: prefix}
+	}
+
+	return ratelimit.NewMemoryStore(1000)
 }
 
 func remoteAddrIP(r *http.Request) string {
@@ -154,6 +412,18 @@ func remoteAddrIP(r *http.Request) string {
 	return ip
 }
 
+// sourceAddress returns the source account of the transaction submitted by r,
+// or an empty string when the envelope cannot be decoded, in which case the
+// submission is left to be rejected as malformed.
+func sourceAddress(app *App, r *http.Request) string {
+	address, err := app.submitter.SourceAddress(app.ctx, r.FormValue("tx"))
+	if err != nil {
+		return ""
+	}
+
+	return address
+}
+
 func init() {
 	appInit.Add(
 		"web.init",
@@ -175,6 +445,8 @@ func init() {
 		"web.init",
 		"web.rate-limiter",
 		"web.metrics",
+		"api-keys",
+		"redis",
 	)
 	appInit.Add(
 		"web.actions",
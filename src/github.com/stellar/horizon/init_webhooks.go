@@ -0,0 +1,52 @@
+package horizon
+
+import (
+	"github.com/stellar/horizon/log"
+	"github.com/stellar/horizon/txsub"
+	"github.com/stellar/horizon/webhook"
+)
+
+// initWebhooks creates the sender of the callbacks of transaction submissions.
+// Callbacks are only offered once a secret to sign them with is configured.
+func initWebhooks(app *App) {
+	if app.config.CallbackSecret == "" {
+		return
+	}
+
+	app.webhooks = &webhook.Sender{Secret: app.config.CallbackSecret}
+}
+
+// NotifyResult forwards the result of the submission of the transaction with
+// the provided hash from l to the returned channel, posting the transaction's
+// final TransactionStatusResource to url in the background.  Submissions that
+// end without the transaction either succeeding or failing, such as those that
+// time out, are not posted.
+func (a *App) NotifyResult(hash string, url string, l <-chan txsub.Result) <-chan txsub.Result {
+	forwarded := make(chan txsub.Result, 1)
+
+	go func() {
+		defer close(forwarded)
+
+		r, ok := <-l
+		if !ok {
+			return
+		}
+		forwarded <- r
+
+		resource, err := NewTransactionStatusResource(hash, r, false, a.submitter.Attempts(a.ctx, hash))
+		if err != nil {
+			log.WithField(a.ctx, "hash", hash).
+				WithField("err", err.Error()).
+				Debug("not posting callback of unfinished submission")
+			return
+		}
+
+		a.webhooks.Send(a.ctx, url, resource)
+	}()
+
+	return forwarded
+}
+
+func init() {
+	appInit.Add("webhooks", initWebhooks, "app-context", "log")
+}
@@ -0,0 +1,144 @@
+package horizon
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/go-stellar-base"
+	"github.com/stellar/go-stellar-base/build"
+	"github.com/stellar/horizon/test"
+	"github.com/stellar/horizon/txsub"
+	"github.com/stellar/horizon/webhook"
+)
+
+func TestWebhooks(t *testing.T) {
+
+	Convey("app.webhooks is nil when no CallbackSecret is set", t, func() {
+		app := NewTestApp()
+		defer app.Close()
+		So(app.webhooks, ShouldBeNil)
+
+		rh := NewRequestHelper(app)
+		w := rh.Post("/transactions", url.Values{
+			"tx":           []string{"not_xdr"},
+			"callback_url": []string{"https://example.com/callbacks"},
+		}, test.RequestHelperNoop)
+		So(w.Code, ShouldEqual, 400)
+		So(w.Body.String(), ShouldContainSubstring, "callbacks_disabled")
+	})
+
+	Convey("Callbacks", t, func() {
+		test.LoadScenario("base")
+		c := NewTestConfig()
+		c.CallbackSecret = "shh"
+		app, _ := NewApp(c)
+		defer app.Close()
+		So(app.webhooks, ShouldNotBeNil)
+
+		Convey("rejects invalid callback urls", func() {
+			rh := NewRequestHelper(app)
+			w := rh.Post("/transactions", url.Values{
+				"tx":           []string{"not_xdr"},
+				"callback_url": []string{"/callbacks"},
+			}, test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 400)
+			So(w.Body.String(), ShouldContainSubstring, "invalid_callback_url")
+		})
+
+		Convey("rejects callback urls of hosts that are not public", func() {
+			rh := NewRequestHelper(app)
+			for _, callback := range []string{
+				"http://127.0.0.1:8001/config/reload",
+				"https://[::1]/callbacks",
+				"https://localhost/callbacks",
+				"http://169.254.169.254/latest/meta-data",
+				"https://10.0.0.1/callbacks",
+				"https://192.168.1.1/callbacks",
+				"https://172.16.0.1/callbacks",
+				"http://example.com/callbacks",
+			} {
+				w := rh.Post("/transactions", url.Values{
+					"tx":           []string{"not_xdr"},
+					"callback_url": []string{callback},
+				}, test.RequestHelperNoop)
+				So(w.Code, ShouldEqual, 400)
+				So(w.Body.String(), ShouldContainSubstring, "invalid_callback_url")
+			}
+		})
+
+		Convey("POST /transactions_async renders submissions rejected right away", func() {
+			received := make(chan *http.Request, 1)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				received <- r
+			}))
+			defer server.Close()
+
+			_, root, err := stellarbase.GenerateKeyFromSeed("SDHOAMBNLGCE2MV5ZKIVZAQD3VCLGP53P3OBSBI6UN5L5XZI5TKHFQL4")
+			So(err, ShouldBeNil)
+			tx := build.Transaction(
+				build.SourceAccount{Address: root.Address()},
+				build.Sequence{Sequence: 4},
+				build.TestNetwork,
+				build.Payment(
+					build.Destination{Address: "GA5WBPYA5Y4WAEHXWR2UKO2UO4BUGHUQ74EUPKON2QHV4WRHOIRNKKH2"},
+					build.NativeAmount{Amount: "10"},
+				),
+			)
+			txe := tx.Sign(&root)
+			env, err := txe.Base64()
+			So(err, ShouldBeNil)
+
+			app.webhooks.Insecure = true
+			app.shutdown()
+
+			rh := NewRequestHelper(app)
+			w := rh.Post("/transactions_async", url.Values{
+				"tx":           []string{env},
+				"callback_url": []string{server.URL},
+			}, test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 503)
+			So(w.Body.String(), ShouldContainSubstring, "submission_interrupted")
+
+			select {
+			case <-received:
+				t.Error("posted the callback of a submission rendered right away")
+			case <-time.After(100 * time.Millisecond):
+			}
+		})
+
+		Convey("NotifyResult posts the final status of the transaction", func() {
+			received := make(chan *http.Request, 1)
+			bodies := make(chan []byte, 1)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+				received <- r
+				bodies <- body
+			}))
+			defer server.Close()
+
+			app.webhooks.Insecure = true
+			hash := "2374e99349b9ef7dba9a5db3339b78fda8f34777b1af33ba468ad5c0df946d4d"
+			l := make(chan txsub.Result, 1)
+			l <- txsub.Result{Hash: hash, LedgerSequence: 2}
+
+			r := <-app.NotifyResult(hash, server.URL, l)
+			So(r.Hash, ShouldEqual, hash)
+
+			req := <-received
+			body := <-bodies
+			So(req.Header.Get(webhook.SignatureHeader), ShouldEqual, webhook.Sign("shh", body))
+
+			var status TransactionStatusResource
+			So(json.Unmarshal(body, &status), ShouldBeNil)
+			So(status.Hash, ShouldEqual, hash)
+			So(status.Status, ShouldEqual, TransactionStatusSuccess)
+			So(status.Ledger, ShouldEqual, 2)
+		})
+	})
+}
@@ -1,12 +1,23 @@
 package log
 
 import (
+	stderr "errors"
+
 	"github.com/Sirupsen/logrus"
 	"github.com/go-errors/errors"
 	"golang.org/x/net/context"
 )
 
 var contextKey = 0
+
+// RequestIDField is the field of the request id in the log lines logged while
+// serving a request
+const RequestIDField = "req"
+
+// ErrUnknownFormat is returned by ParseFormat when given a format other than
+// "text" or "json".
+// NOTE: this is not a go-errors based error, as stack traces are unnecessary
+var ErrUnknownFormat = stderr.New("unknown log format")
 var defaultLogger *logrus.Entry
 var defaultMetrics *Metrics
 
@@ -66,6 +77,33 @@ func FromContext(ctx context.Context) *logrus.Entry {
 	return found.(*logrus.Entry)
 }
 
+// WithRequestID establishes a new context whose logger includes the request
+// id provided in every line it logs, so that the lines of a request, and of
+// the services it calls, may be found together.
+func WithRequestID(parent context.Context, id string) context.Context {
+	return PushContext(parent, func(entry *logrus.Entry) *logrus.Entry {
+		return entry.WithField(RequestIDField, id)
+	})
+}
+
+// ParseFormat returns the formatter of log lines of format: "text", the
+// default when empty, or "json", for log aggregators to parse.
+func ParseFormat(format string) (logrus.Formatter, error) {
+	switch format {
+	case "", "text":
+		return &logrus.TextFormatter{}, nil
+	case "json":
+		return &logrus.JSONFormatter{}, nil
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+// SetDefaultLoggerFormatter sets the formatter of the default logger
+func SetDefaultLoggerFormatter(f logrus.Formatter) {
+	defaultLogger.Logger.Formatter = f
+}
+
 // SetDefaultLoggerLevel sets the logging level for the default logger
 func SetDefaultLoggerLevel(level logrus.Level) {
 	defaultLogger.Logger.Level = level
@@ -189,4 +189,28 @@ func TestLogPackage(t *testing.T) {
 			So(meter.Count(), ShouldEqual, 1)
 		}
 	})
+
+	Convey("WithRequestID", t, func() {
+		output := new(bytes.Buffer)
+		l, _ := New()
+		l.Logger.Formatter.(*logrus.TextFormatter).DisableColors = true
+		l.Logger.Out = output
+		ctx := WithRequestID(Context(context.Background(), l), "host/abc-000001")
+
+		Warn(ctx, "hello")
+		So(output.String(), ShouldContainSubstring, `req="host/abc-000001"`)
+	})
+
+	Convey("ParseFormat", t, func() {
+		f, err := ParseFormat("")
+		So(err, ShouldBeNil)
+		So(f, ShouldHaveSameTypeAs, &logrus.TextFormatter{})
+
+		f, err = ParseFormat("json")
+		So(err, ShouldBeNil)
+		So(f, ShouldHaveSameTypeAs, &logrus.JSONFormatter{})
+
+		_, err = ParseFormat("xml")
+		So(err, ShouldEqual, ErrUnknownFormat)
+	})
 }
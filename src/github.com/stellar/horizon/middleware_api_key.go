@@ -0,0 +1,153 @@
+package horizon
+
+import (
+	"net/http"
+	"sync"
+
+	gctx "github.com/goji/context"
+	"github.com/stellar/horizon/apikey"
+	"github.com/stellar/horizon/ratelimit"
+	"github.com/stellar/horizon/render/problem"
+	"github.com/zenazn/goji/web"
+)
+
+// apiKeyEnvKey is the env key of the api key a request was made with
+const apiKeyEnvKey = "apikey"
+
+var (
+	// apiKeyRequired is rendered for requests made without an api key when
+	// Config.APIKeysRequired is set.
+	apiKeyRequired = problem.P{
+		Type:   "api_key_required",
+		Title:  "API Key Required",
+		Status: http.StatusUnauthorized,
+		Detail: "This server only answers requests made with an api key, given in " +
+			"the X-API-Key header or the api_key query param.",
+	}
+
+	// invalidAPIKey is rendered for requests made with a key the server does not
+	// know.
+	invalidAPIKey = problem.P{
+		Type:   "invalid_api_key",
+		Title:  "Invalid API Key",
+		Status: http.StatusUnauthorized,
+		Detail: "The api key this request was made with is not one this server " +
+			"knows.  It may have been revoked.",
+	}
+
+	// apiKeyScopeDenied is rendered for requests of endpoints their api key may
+	// not request.
+	apiKeyScopeDenied = problem.P{
+		Type:   "api_key_scope_denied",
+		Title:  "Endpoint Not Allowed",
+		Status: http.StatusForbidden,
+		Detail: "The api key this request was made with is not allowed to request " +
+			"this endpoint.",
+	}
+
+	// apiKeyRateLimitExceeded is rendered for requests made with an api key
+	// whose rate limit they are over.
+	apiKeyRateLimitExceeded = problem.P{
+		Type:   "api_key_rate_limit_exceeded",
+		Title:  "API key rate limit exceeded",
+		Status: 429,
+		Detail: "The rate limit of the api key this request was made with is over " +
+			"its alloted limit.  The allowed limit and requests left are " +
+			"communicated to clients via the http response headers 'X-RateLimit-*' " +
+			"headers.",
+	}
+)
+
+// apiKeyMiddleware authenticates the requests made with an api key, given in
+// the X-API-Key header or, for clients such as EventSource that cannot set
+// headers, the api_key query param.  Requests of endpoints beyond the scopes
+// of their key are denied, and those of keys with a rate limit of their own
+// are limited by key rather than by client ip.  Requests made without a key
+// pass through, unless Config.APIKeysRequired is set.
+func apiKeyMiddleware(app *App) func(c *web.C, next http.Handler) http.Handler {
+	limiters := &apiKeyLimiters{
+		app:   app,
+		store: newRateLimitStore(app, "throttle:key:"),
+		byKey: map[apiKeyLimiterID]*ratelimit.Throttler{},
+	}
+
+	return func(c *web.C, next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := gctx.FromC(*c)
+
+			token := r.Header.Get("X-API-Key")
+			if token == "" {
+				token = r.URL.Query().Get("api_key")
+			}
+
+			if token == "" {
+				if app.config.APIKeysRequired {
+					problem.Render(ctx, w, apiKeyRequired)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key, err := app.apiKeys.Lookup(ctx, token)
+			if err == apikey.ErrUnknownKey {
+				problem.Render(ctx, w, invalidAPIKey)
+				return
+			}
+			if err != nil {
+				problem.Render(ctx, w, err)
+				return
+			}
+
+			if !key.Allows(r) {
+				problem.Render(ctx, w, apiKeyScopeDenied)
+				return
+			}
+
+			c.Env[apiKeyEnvKey] = key
+			if key.RateLimit.Rate <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			limiters.get(key).Throttle(next).ServeHTTP(w, r)
+		})
+	}
+}
+
+// apiKeyLimiters holds the rate limiter of each api key with a rate limit of
+// its own, made anew should a key be loaded with a different limit.
+type apiKeyLimiters struct {
+	app   *App
+	store ratelimit.Store
+
+	lock  sync.Mutex
+	byKey map[apiKeyLimiterID]*ratelimit.Throttler
+}
+
+// apiKeyLimiterID identifies the limiter of an api key
+type apiKeyLimiterID struct {
+	name   string
+	bucket ratelimit.Bucket
+}
+
+func (l *apiKeyLimiters) get(key *apikey.Key) *ratelimit.Throttler {
+	id := apiKeyLimiterID{name: key.Name, bucket: key.RateLimit}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	limiter, ok := l.byKey[id]
+	if !ok {
+		name := key.Name
+		limiter = ratelimit.New(key.RateLimit, func(*http.Request) string { return name }, l.store)
+		limiter.DeniedHandler = &RateLimitExceededAction{
+			App:     l.app,
+			Action:  Action{},
+			Problem: &apiKeyRateLimitExceeded,
+		}
+		l.byKey[id] = limiter
+	}
+
+	return limiter
+}
@@ -0,0 +1,115 @@
+package horizon
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/PuerkitoBio/throttled"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/test"
+)
+
+func TestAPIKeyMiddleware(t *testing.T) {
+	withKey := func(token string) func(r *http.Request) {
+		return func(r *http.Request) {
+			r.Header.Set("X-API-Key", token)
+		}
+	}
+
+	Convey("API Keys", t, func() {
+		test.LoadScenario("base")
+
+		f, err := ioutil.TempFile("", "api-keys")
+		So(err, ShouldBeNil)
+		defer os.Remove(f.Name())
+		_, err = f.WriteString(`
+[[keys]]
+name = "partner"
+token = "partner-secret"
+per_hour_rate_limit = 3
+rate_limit_burst = 3
+scopes = ["GET /"]
+
+[[keys]]
+name = "ledgers"
+token = "ledgers-secret"
+scopes = ["GET /ledgers"]
+`)
+		So(err, ShouldBeNil)
+		f.Close()
+
+		c := NewTestConfig()
+		c.RateLimit = throttled.PerHour(1)
+		c.APIKeysFile = f.Name()
+		app, _ := NewApp(c)
+		defer app.Close()
+		rh := NewRequestHelper(app)
+
+		Convey("passes requests made without a key", func() {
+			w := rh.Get("/", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+		})
+
+		Convey("rejects unknown keys", func() {
+			w := rh.Get("/", withKey("unknown"))
+			So(w.Code, ShouldEqual, 401)
+			So(w.Body, ShouldBeProblem, invalidAPIKey)
+		})
+
+		Convey("reads keys from the api_key query param", func() {
+			w := rh.Get("/?api_key=unknown", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 401)
+
+			w = rh.Get("/?api_key=partner-secret", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+		})
+
+		Convey("denies endpoints beyond the scopes of a key", func() {
+			w := rh.Get("/ledgers", withKey("ledgers-secret"))
+			So(w.Code, ShouldEqual, 200)
+
+			w = rh.Get("/accounts", withKey("ledgers-secret"))
+			So(w.Code, ShouldEqual, 403)
+			So(w.Body, ShouldBeProblem, apiKeyScopeDenied)
+		})
+
+		Convey("limits keys with a rate limit of their own by key", func() {
+			for i := 0; i < 3; i++ {
+				w := rh.Get("/", withKey("partner-secret"))
+				So(w.Code, ShouldEqual, 200)
+				So(w.Header().Get("X-RateLimit-Limit"), ShouldEqual, "3")
+			}
+
+			w := rh.Get("/", withKey("partner-secret"))
+			So(w.Code, ShouldEqual, 429)
+			So(w.Body, ShouldBeProblem, apiKeyRateLimitExceeded)
+
+			// the ip of the key is left to requests made without it
+			w = rh.Get("/", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+		})
+
+		Convey("limits keys without a rate limit by ip", func() {
+			w := rh.Get("/ledgers", withKey("ledgers-secret"))
+			So(w.Code, ShouldEqual, 200)
+
+			w = rh.Get("/ledgers", withKey("ledgers-secret"))
+			So(w.Code, ShouldEqual, 429)
+		})
+	})
+
+	Convey("API Keys, when required", t, func() {
+		c := NewTestConfig()
+		c.APIKeysFile = "/dev/null"
+		c.APIKeysRequired = true
+		app, _ := NewApp(c)
+		defer app.Close()
+		rh := NewRequestHelper(app)
+
+		w := rh.Get("/", test.RequestHelperNoop)
+		So(w.Code, ShouldEqual, 401)
+		So(w.Body, ShouldBeProblem, apiKeyRequired)
+	})
+}
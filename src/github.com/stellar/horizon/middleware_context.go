@@ -1,9 +1,11 @@
 package horizon
 
 import (
+	"github.com/Sirupsen/logrus"
 	gctx "github.com/goji/context"
 	"github.com/stellar/horizon/context/requestid"
 	"github.com/stellar/horizon/httpx"
+	"github.com/stellar/horizon/log"
 	"github.com/zenazn/goji/web"
 	"golang.org/x/net/context"
 	"net/http"
@@ -14,6 +16,7 @@ func contextMiddleware(parent context.Context) func(c *web.C, next http.Handler)
 		fn := func(w http.ResponseWriter, r *http.Request) {
 			ctx := parent
 			ctx = requestid.ContextFromC(ctx, c)
+			ctx = log.WithRequestID(ctx, requestid.FromContext(ctx))
 			cancel := func() {}
 
 			// establish "cancel on close" context if possible
@@ -28,3 +31,21 @@ func contextMiddleware(parent context.Context) func(c *web.C, next http.Handler)
 		return http.HandlerFunc(fn)
 	}
 }
+
+// networkContextMiddleware binds the app of a network, and its name, to the
+// context of the requests routed to it by its parent, whose context they were
+// given.
+func networkContextMiddleware(app *App) func(c *web.C, next http.Handler) http.Handler {
+	return func(c *web.C, next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(gctx.FromC(*c), &appContextKey, app)
+			ctx = log.PushContext(ctx, func(l *logrus.Entry) *logrus.Entry {
+				return l.WithField("network", app.network)
+			})
+
+			gctx.Set(c, ctx)
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,30 @@
+package horizon
+
+import (
+	"net/http"
+
+	"github.com/rs/cors"
+)
+
+// corsMiddleware answers CORS preflight requests and sets the CORS headers of
+// every other response, be it a HAL document, a problem or a stream, according
+// to the app's configuration.  Allowed origins and headers default to any.
+func corsMiddleware(config Config) func(http.Handler) http.Handler {
+	origins := config.CORSAllowedOrigins
+	if len(origins) == 0 {
+		origins = []string{"*"}
+	}
+
+	headers := config.CORSAllowedHeaders
+	if len(headers) == 0 {
+		headers = []string{"*"}
+	}
+
+	c := cors.New(cors.Options{
+		AllowedOrigins:   origins,
+		AllowedHeaders:   headers,
+		AllowCredentials: config.CORSAllowCredentials,
+	})
+
+	return c.Handler
+}
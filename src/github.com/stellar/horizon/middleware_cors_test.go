@@ -0,0 +1,46 @@
+package horizon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCORSMiddleware(t *testing.T) {
+
+	Convey("corsMiddleware", t, func() {
+		ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+
+		request := func(origin string) *http.Request {
+			r, _ := http.NewRequest("GET", "/ledgers", nil)
+			r.Header.Set("Origin", origin)
+			return r
+		}
+
+		Convey("allows any origin by default", func() {
+			w := httptest.NewRecorder()
+			corsMiddleware(Config{})(ok).ServeHTTP(w, request("https://example.com"))
+			So(w.Header().Get("Access-Control-Allow-Origin"), ShouldEqual, "https://example.com")
+		})
+
+		Convey("only allows the configured origins", func() {
+			handler := corsMiddleware(Config{
+				CORSAllowedOrigins:   []string{"https://example.com"},
+				CORSAllowCredentials: true,
+			})(ok)
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, request("https://example.com"))
+			So(w.Header().Get("Access-Control-Allow-Origin"), ShouldEqual, "https://example.com")
+			So(w.Header().Get("Access-Control-Allow-Credentials"), ShouldEqual, "true")
+
+			w = httptest.NewRecorder()
+			handler.ServeHTTP(w, request("https://elsewhere.com"))
+			So(w.Header().Get("Access-Control-Allow-Origin"), ShouldEqual, "")
+		})
+	})
+}
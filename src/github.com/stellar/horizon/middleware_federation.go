@@ -0,0 +1,81 @@
+package horizon
+
+import (
+	"net/http"
+	"strings"
+
+	gctx "github.com/goji/context"
+	"github.com/stellar/horizon/federation"
+	"github.com/stellar/horizon/render/problem"
+	"github.com/zenazn/goji/web"
+)
+
+// federatedParams are the query params naming an account that may be given as
+// a federation address.
+var federatedParams = []string{"source_account", "destination_account", "signer"}
+
+// invalidFederationAddress is rendered for addresses that are malformed, or
+// whose domain has no federation server.
+var invalidFederationAddress = problem.P{
+	Type:   "invalid_federation_address",
+	Title:  "Invalid Federation Address",
+	Status: http.StatusBadRequest,
+	Detail: "A federation address given in place of an account id could not be " +
+		"resolved.  Federation addresses are of the form name*domain, and the " +
+		"domain must publish an https FEDERATION_SERVER in its stellar.toml, " +
+		"which must resolve the address to an account id.",
+}
+
+// federationMiddleware replaces the federation addresses a request gives in
+// place of account ids, in the `/accounts/:id` segment of its path and in its
+// federatedParams, with the account ids they resolve to, so that the actions
+// downstream only ever see account ids.  A request whose addresses cannot be
+// resolved is answered with a problem.
+func federationMiddleware(resolver *federation.Resolver) func(c *web.C, next http.Handler) http.Handler {
+	problem.RegisterError(federation.ErrInvalidAddress, invalidFederationAddress)
+	problem.RegisterError(federation.ErrNoFederationServer, invalidFederationAddress)
+	problem.RegisterError(federation.ErrInvalidAccountID, invalidFederationAddress)
+	problem.RegisterError(federation.ErrNotFound, problem.NotFound)
+
+	return func(c *web.C, next http.Handler) http.Handler {
+		fn := func(w http.ResponseWriter, r *http.Request) {
+			ctx := gctx.FromC(*c)
+
+			segments := strings.Split(r.URL.Path, "/")
+			if len(segments) > 2 && segments[1] == "accounts" && federation.IsAddress(segments[2]) {
+				aid, err := resolver.Resolve(ctx, segments[2])
+				if err != nil {
+					problem.Render(ctx, w, err)
+					return
+				}
+				segments[2] = aid
+				r.URL.Path = strings.Join(segments, "/")
+				r.URL.RawPath = ""
+			}
+
+			query := r.URL.Query()
+			rewritten := false
+			for _, name := range federatedParams {
+				value := query.Get(name)
+				if !federation.IsAddress(value) {
+					continue
+				}
+
+				aid, err := resolver.Resolve(ctx, value)
+				if err != nil {
+					problem.Render(ctx, w, err)
+					return
+				}
+				query.Set(name, aid)
+				rewritten = true
+			}
+
+			if rewritten {
+				r.URL.RawQuery = query.Encode()
+			}
+
+			next.ServeHTTP(w, r)
+		}
+		return http.HandlerFunc(fn)
+	}
+}
@@ -0,0 +1,87 @@
+package horizon
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gctx "github.com/goji/context"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/federation"
+	"github.com/stellar/horizon/test"
+	"github.com/zenazn/goji/web"
+)
+
+func TestFederationMiddleware(t *testing.T) {
+
+	Convey("federationMiddleware", t, func() {
+		mux := http.NewServeMux()
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		domain := strings.TrimPrefix(server.URL, "http://")
+		mux.HandleFunc("/.well-known/stellar.toml", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "FEDERATION_SERVER=\"%s/federation\"\n", server.URL)
+		})
+		mux.HandleFunc("/federation", func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("q") == "carol*"+domain {
+				fmt.Fprint(w, `{"account_id": "../../ledgers"}`)
+				return
+			}
+			if r.URL.Query().Get("q") != "bob*"+domain {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			fmt.Fprint(w, `{"account_id": "GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON"}`)
+		})
+
+		var seen *http.Request
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			seen = r
+			w.WriteHeader(http.StatusOK)
+		})
+
+		serve := func(url string) *httptest.ResponseRecorder {
+			c := web.C{Env: map[interface{}]interface{}{}}
+			gctx.Set(&c, test.Context())
+			r, _ := http.NewRequest("GET", url, nil)
+			w := httptest.NewRecorder()
+			federationMiddleware(&federation.Resolver{Insecure: true})(&c, next).ServeHTTP(w, r)
+			return w
+		}
+
+		Convey("resolves addresses in the account segment of the path", func() {
+			w := serve("/accounts/bob*" + domain + "/payments?limit=1")
+			So(w.Code, ShouldEqual, 200)
+			So(seen.URL.Path, ShouldEqual, "/accounts/GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON/payments")
+			So(seen.URL.Query().Get("limit"), ShouldEqual, "1")
+		})
+
+		Convey("resolves addresses in account params", func() {
+			w := serve("/paths?destination_account=bob*" + domain + "&destination_amount=10")
+			So(w.Code, ShouldEqual, 200)
+			So(seen.URL.Query().Get("destination_account"), ShouldEqual, "GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON")
+			So(seen.URL.Query().Get("destination_amount"), ShouldEqual, "10")
+		})
+
+		Convey("leaves account ids alone", func() {
+			w := serve("/accounts/GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON?signer=GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON")
+			So(w.Code, ShouldEqual, 200)
+			So(seen.URL.Path, ShouldEqual, "/accounts/GBXGQJWVLWOYHFLVTKWV5FGHA3LNYY2JQKM7OAJAUEQFU6LPCSEFVXON")
+		})
+
+		Convey("answers unknown addresses with a 404", func() {
+			w := serve("/accounts/alice*" + domain)
+			So(w.Code, ShouldEqual, 404)
+			So(seen, ShouldBeNil)
+		})
+
+		Convey("refuses addresses resolved to other than account ids", func() {
+			w := serve("/accounts/carol*" + domain + "/payments")
+			So(w.Code, ShouldEqual, 400)
+			So(seen, ShouldBeNil)
+		})
+	})
+}
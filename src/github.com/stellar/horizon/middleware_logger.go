@@ -18,7 +18,7 @@ import (
 func LoggerMiddleware(c *web.C, h http.Handler) http.Handler {
 	fn := func(w http.ResponseWriter, r *http.Request) {
 		ctx := gctx.FromC(*c)
-		mw := mutil.WrapWriter(w)
+		mw := wrapWriter(w)
 
 		logStartOfRequest(ctx, r)
 
@@ -34,13 +34,27 @@ func LoggerMiddleware(c *web.C, h http.Handler) http.Handler {
 
 func logStartOfRequest(ctx context.Context, r *http.Request) {
 	fields := logrus.Fields{
-		"path":   r.URL.String(),
+		"path":   redactedURL(r),
 		"method": r.Method,
 	}
 
 	log.WithFields(ctx, fields).Info("Starting request")
 }
 
+// redactedURL returns the url of r, without the api key it may have been made
+// with
+func redactedURL(r *http.Request) string {
+	query := r.URL.Query()
+	if query.Get("api_key") == "" {
+		return r.URL.String()
+	}
+
+	query.Set("api_key", "REDACTED")
+	u := *r.URL
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
 func logEndOfRequest(ctx context.Context, duration time.Duration, mw mutil.WriterProxy) {
 	fields := logrus.Fields{
 		"status":   mw.Status(),
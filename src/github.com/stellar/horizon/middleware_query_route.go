@@ -0,0 +1,21 @@
+package horizon
+
+import (
+	"net/http"
+
+	gctx "github.com/goji/context"
+	"github.com/stellar/horizon/db"
+	"github.com/zenazn/goji/web"
+)
+
+// queryRouteMiddleware tags the context of each request with its route and
+// params, recorded with the queries serving it that are slow.  It must run
+// once the request is routed, and before requestTimeoutMiddleware, so that
+// streams lifting their timeout keep the tag.
+func queryRouteMiddleware(c *web.C, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := db.WithRoute(gctx.FromC(*c), routeOf(*c), r.URL.Query())
+		gctx.Set(c, ctx)
+		next.ServeHTTP(w, r)
+	})
+}
@@ -1,10 +1,81 @@
 package horizon
 
 import (
+	"github.com/stellar/horizon/apikey"
 	"github.com/zenazn/goji/web"
 	"net/http"
 )
 
+// RateLimitMiddleware limits requests by client ip, save those made with an
+// api key having a rate limit of its own, which apiKeyMiddleware applies.
 func (web *Web) RateLimitMiddleware(c *web.C, next http.Handler) http.Handler {
-	return web.rateLimiter.Throttle(next)
+	limited := web.rateLimiter.Throttle(next)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if key, ok := c.Env[apiKeyEnvKey].(*apikey.Key); ok && key.RateLimit.Rate > 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		limited.ServeHTTP(w, r)
+	})
+}
+
+// TxSubRateLimitMiddleware limits transaction submissions further than
+// RateLimitMiddleware does: by client ip, and then by the source account of the
+// transaction submitted.  The X-RateLimit-* headers of submissions describe the
+// strictest of the limits they are subject to.
+func (web *Web) TxSubRateLimitMiddleware(c *web.C, next http.Handler) http.Handler {
+	limited := next
+	if web.txsubAccountLimiter != nil {
+		limited = web.txsubAccountLimiter.Throttle(limited)
+	}
+	if web.txsubIPLimiter != nil {
+		limited = web.txsubIPLimiter.Throttle(limited)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isSubmission(r) {
+			limited.ServeHTTP(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isSubmission reports whether r submits a transaction to the network.
+func isSubmission(r *http.Request) bool {
+	if r.Method != "POST" {
+		return false
+	}
+
+	switch r.URL.Path {
+	case "/transactions", "/transactions_async":
+		return true
+	default:
+		return false
+	}
+}
+
+// FriendbotRateLimitMiddleware limits the requests made of friendbot by client
+// ip, and then by the account asked to be funded, apart from the limits of
+// other requests.
+func (web *Web) FriendbotRateLimitMiddleware(c *web.C, next http.Handler) http.Handler {
+	limited := next
+	if web.friendbotAccountLimiter != nil {
+		limited = web.friendbotAccountLimiter.Throttle(limited)
+	}
+	if web.friendbotIPLimiter != nil {
+		limited = web.friendbotIPLimiter.Throttle(limited)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/friendbot" {
+			limited.ServeHTTP(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
 }
@@ -1,11 +1,16 @@
 package horizon
 
 import (
+	"net/http"
+	"net/url"
 	"strconv"
 	"testing"
 
 	"github.com/PuerkitoBio/throttled"
 	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/go-stellar-base"
+	"github.com/stellar/go-stellar-base/build"
+	"github.com/stellar/horizon/render/problem"
 	"github.com/stellar/horizon/test"
 )
 
@@ -39,8 +44,20 @@ func TestRateLimitMiddleware(t *testing.T) {
 		})
 
 		Convey("sets X-RateLimit-Reset header correctly", func() {
+			// a token is regained every 360 seconds
 			w := rh.Get("/", test.RequestHelperNoop)
-			So(w.Header().Get("X-RateLimit-Reset"), ShouldEqual, "3599")
+			So(w.Header().Get("X-RateLimit-Reset"), ShouldEqual, "360")
+		})
+
+		Convey("sets Retry-After header once limited", func() {
+			for i := 0; i < 10; i++ {
+				w := rh.Get("/", test.RequestHelperNoop)
+				So(w.Header().Get("Retry-After"), ShouldEqual, "")
+			}
+
+			w := rh.Get("/", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 429)
+			So(w.Header().Get("Retry-After"), ShouldEqual, "360")
 		})
 
 		Convey("Restricts based on RemoteAddr IP after too many requests", func() {
@@ -84,6 +101,100 @@ func TestRateLimitMiddleware(t *testing.T) {
 		})
 	})
 
+	Convey("Rate Limiting allows bursts of RateLimitBurst requests", t, func() {
+		c := NewTestConfig()
+		c.RateLimit = throttled.PerHour(10)
+		c.RateLimitBurst = 2
+		app, _ := NewApp(c)
+		defer app.Close()
+		rh := NewRequestHelper(app)
+
+		for i := 0; i < 2; i++ {
+			w := rh.Get("/", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+			So(w.Header().Get("X-RateLimit-Limit"), ShouldEqual, "2")
+		}
+
+		w := rh.Get("/", test.RequestHelperNoop)
+		So(w.Code, ShouldEqual, 429)
+	})
+
+	Convey("Transaction submission rate limiting", t, func() {
+		test.LoadScenario("base")
+		c := NewTestConfig()
+		c.TxSubRateLimit = throttled.PerHour(3)
+		c.TxSubAccountRateLimit = throttled.PerHour(2)
+		app, _ := NewApp(c)
+		defer app.Close()
+		rh := NewRequestHelper(app)
+
+		_, root, err := stellarbase.GenerateKeyFromSeed("SDHOAMBNLGCE2MV5ZKIVZAQD3VCLGP53P3OBSBI6UN5L5XZI5TKHFQL4")
+		So(err, ShouldBeNil)
+
+		envelope := func(source string) url.Values {
+			tx := build.Transaction(
+				build.SourceAccount{Address: source},
+				build.Sequence{Sequence: 4},
+				build.TestNetwork,
+				build.Payment(
+					build.Destination{Address: root.Address()},
+					build.NativeAmount{Amount: "10"},
+				),
+			)
+			txe := tx.Sign(&root)
+			env, err := txe.Base64()
+			So(err, ShouldBeNil)
+			return url.Values{"tx": []string{env}}
+		}
+
+		other := "GA5WBPYA5Y4WAEHXWR2UKO2UO4BUGHUQ74EUPKON2QHV4WRHOIRNKKH2"
+
+		Convey("leaves other requests to the general limit", func() {
+			for i := 0; i < 5; i++ {
+				w := rh.Get("/", test.RequestHelperNoop)
+				So(w.Code, ShouldEqual, 200)
+				So(w.Header().Get("X-RateLimit-Limit"), ShouldEqual, "1000")
+			}
+		})
+
+		Convey("restricts submissions by source account", func() {
+			for i := 0; i < 2; i++ {
+				w := rh.Post("/transactions", envelope(root.Address()), test.RequestHelperNoop)
+				So(w.Code, ShouldNotEqual, 429)
+			}
+
+			w := rh.Post("/transactions_async", envelope(root.Address()), test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 429)
+			So(w.Body, ShouldBeProblem, problem.SourceAccountRateLimitExceeded)
+
+			w = rh.Post("/transactions", envelope(other), test.RequestHelperNoop)
+			So(w.Code, ShouldNotEqual, 429)
+		})
+
+		Convey("answers submissions with the headers of the strictest limit", func() {
+			w := rh.Post("/transactions", envelope(root.Address()), test.RequestHelperNoop)
+			So(w.Code, ShouldNotEqual, 429)
+			So(w.Header()[http.CanonicalHeaderKey("X-RateLimit-Limit")], ShouldResemble, []string{"2"})
+			So(w.Header()[http.CanonicalHeaderKey("X-RateLimit-Remaining")], ShouldResemble, []string{"1"})
+			So(len(w.Header()[http.CanonicalHeaderKey("X-RateLimit-Reset")]), ShouldEqual, 1)
+		})
+
+		Convey("restricts submissions by ip", func() {
+			from3 := test.RequestHelperRemoteAddr("127.0.0.3")
+			for _, body := range []url.Values{envelope(root.Address()), envelope(other), {"tx": []string{"not_xdr"}}} {
+				w := rh.Post("/transactions", body, from3)
+				So(w.Code, ShouldNotEqual, 429)
+			}
+
+			w := rh.Post("/transactions", url.Values{"tx": []string{"not_xdr"}}, test.RequestHelperRemoteAddr("127.0.0.3"))
+			So(w.Code, ShouldEqual, 429)
+			So(w.Body, ShouldBeProblem, problem.RateLimitExceeded)
+
+			w = rh.Post("/transactions", url.Values{"tx": []string{"not_xdr"}}, test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 400)
+		})
+	})
+
 	Convey("Rate Limiting works with redis", t, func() {
 		c := NewTestConfig()
 		c.RateLimit = throttled.PerHour(10)
@@ -0,0 +1,52 @@
+package horizon
+
+import (
+	"net/http"
+
+	"github.com/stellar/horizon/context/requestid"
+	"github.com/zenazn/goji/web"
+	"github.com/zenazn/goji/web/middleware"
+)
+
+// maxRequestIDLength is the length of the longest request id horizon accepts
+// from clients
+const maxRequestIDLength = 200
+
+// requestIDMiddleware identifies each request, by the request id its client
+// gave in the X-Request-ID header, such as one assigned by a load balancer or
+// by the service calling horizon, or by one generated as goji's RequestID
+// middleware does.  The id is sent back in the X-Request-ID header of the
+// response.  Ids longer than maxRequestIDLength, or holding characters other
+// than printable ascii, are replaced with a generated one.
+func requestIDMiddleware(c *web.C, next http.Handler) http.Handler {
+	respond := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(requestid.Header, middleware.GetReqID(*c))
+		next.ServeHTTP(w, r)
+	})
+	generate := middleware.RequestID(c, respond)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestid.Header)
+		if !isValidRequestID(id) {
+			generate.ServeHTTP(w, r)
+			return
+		}
+
+		c.Env[middleware.RequestIDKey] = id
+		respond.ServeHTTP(w, r)
+	})
+}
+
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x20 || id[i] > 0x7e {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,56 @@
+package horizon
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/render/problem"
+	"github.com/stellar/horizon/test"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	withID := func(id string) func(r *http.Request) {
+		return func(r *http.Request) {
+			r.Header.Set("X-Request-ID", id)
+		}
+	}
+
+	// the instance of problems rendered is the id of their request
+	instanceOf := func(body []byte) string {
+		var p problem.P
+		So(json.Unmarshal(body, &p), ShouldBeNil)
+		return p.Instance
+	}
+
+	Convey("Request IDs", t, func() {
+		app := NewTestApp()
+		defer app.Close()
+		rh := NewRequestHelper(app)
+
+		Convey("are generated for requests made without one", func() {
+			w := rh.Get("/not_found", test.RequestHelperNoop)
+			id := w.Header().Get("X-Request-ID")
+			So(id, ShouldNotBeBlank)
+			So(instanceOf(w.Body.Bytes()), ShouldEqual, id)
+		})
+
+		Convey("given by clients are honored", func() {
+			w := rh.Get("/not_found", withID("lb-1234"))
+			So(w.Header().Get("X-Request-ID"), ShouldEqual, "lb-1234")
+			So(instanceOf(w.Body.Bytes()), ShouldEqual, "lb-1234")
+		})
+
+		Convey("given by clients are replaced when invalid", func() {
+			w := rh.Get("/not_found", withID(strings.Repeat("a", maxRequestIDLength+1)))
+			id := w.Header().Get("X-Request-ID")
+			So(id, ShouldNotBeBlank)
+			So(len(id), ShouldBeLessThanOrEqualTo, maxRequestIDLength)
+
+			w = rh.Get("/not_found", withID("caf\xc3\xa9"))
+			So(w.Header().Get("X-Request-ID"), ShouldNotEqual, "caf\xc3\xa9")
+		})
+	})
+}
@@ -1,7 +1,9 @@
 package horizon
 
 import (
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/zenazn/goji/web"
 	"github.com/zenazn/goji/web/mutil"
@@ -9,15 +11,19 @@ import (
 
 // Middleware that records metrics.
 //
-// It records success and failures using a meter, and times every request
+// It records success and failures using a meter, and times every request, by
+// route in the app's route histogram as well
 func requestMetricsMiddleware(c *web.C, h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		app := c.Env["app"].(*App)
-		mw := mutil.WrapWriter(w)
+		mw := wrapWriter(w)
 
-		app.web.requestTimer.Time(func() {
-			h.ServeHTTP(mw.(http.ResponseWriter), r)
-		})
+		start := time.Now()
+		h.ServeHTTP(mw.(http.ResponseWriter), r)
+		elapsed := time.Since(start)
+
+		app.web.requestTimer.Update(elapsed)
+		app.web.routeHistogram.Observe(elapsed.Seconds(), routeOf(*c), r.Method)
 
 		if 200 <= mw.Status() && mw.Status() < 400 {
 			// a success is in [200, 400)
@@ -29,3 +35,40 @@ func requestMetricsMiddleware(c *web.C, h http.Handler) http.Handler {
 
 	})
 }
+
+// routeOf returns the pattern of the route a request was routed to, such as
+// "/accounts/:id", or "not_found" when it matched none.  Labelling metrics by
+// route rather than by path keeps their count bounded.
+func routeOf(c web.C) string {
+	pattern := web.GetMatch(c).RawPattern()
+	if pattern == nil {
+		return "not_found"
+	}
+
+	return fmt.Sprint(pattern)
+}
+
+// wrapWriter wraps w as mutil.WrapWriter does, keeping w a flusher when it is
+// one but cannot be hijacked, as with the writers of HTTP/2 responses, which
+// mutil would otherwise wrap as a writer that streams find they cannot flush.
+func wrapWriter(w http.ResponseWriter) mutil.WriterProxy {
+	mw := mutil.WrapWriter(w)
+	if _, ok := mw.(http.Flusher); ok {
+		return mw
+	}
+
+	if _, ok := w.(http.Flusher); !ok {
+		return mw
+	}
+
+	return flushWriter{mw}
+}
+
+// flushWriter is a mutil.WriterProxy of a writer that may be flushed
+type flushWriter struct {
+	mutil.WriterProxy
+}
+
+func (f flushWriter) Flush() {
+	f.Unwrap().(http.Flusher).Flush()
+}
@@ -0,0 +1,46 @@
+package horizon
+
+import (
+	"net/http"
+	"time"
+
+	gctx "github.com/goji/context"
+	"github.com/stellar/horizon/httpx"
+	"github.com/zenazn/goji/web"
+)
+
+// requestTimeoutMiddleware gives the context of each request a deadline, past
+// which the queries and searches serving it are cancelled and a timeout
+// problem is rendered, so that runaway requests are cut off rather than left
+// to hold their database connections.  Streams lift the timeout once they
+// begin.  It must run once the request is routed, the timeout of each route
+// being its own.
+func requestTimeoutMiddleware(c *web.C, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		app := c.Env["app"].(*App)
+
+		timeout := requestTimeout(app.currentConfig(), routeOf(*c))
+		if timeout <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx, cancel := httpx.WithRequestTimeout(gctx.FromC(*c), timeout)
+		defer cancel()
+
+		gctx.Set(c, ctx)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requestTimeout returns the timeout of the requests of route under config:
+// that of Config.RouteTimeouts for the route, if any, or Config.RequestTimeout.
+// Requests without a timeout run as long as they take.
+func requestTimeout(config Config, route string) time.Duration {
+	timeout, ok := config.RouteTimeouts[route]
+	if ok {
+		return timeout
+	}
+
+	return config.RequestTimeout
+}
@@ -0,0 +1,43 @@
+package horizon
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/test"
+)
+
+func TestRequestTimeoutMiddleware(t *testing.T) {
+	Convey("Request timeouts", t, func() {
+		test.LoadScenario("base")
+		c := NewTestConfig()
+		c.RequestTimeout = time.Nanosecond
+		c.RouteTimeouts = map[string]time.Duration{"/ledgers/:id": 0}
+		app, err := NewApp(c)
+		So(err, ShouldBeNil)
+		defer app.Close()
+		rh := NewRequestHelper(app)
+
+		Convey("cut off requests running past them", func() {
+			w := rh.Get("/ledgers", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 504)
+			So(w.Body.String(), ShouldContainSubstring, "timeout")
+		})
+
+		Convey("are those of the route, when it has its own", func() {
+			w := rh.Get("/ledgers/1", test.RequestHelperNoop)
+			So(w.Code, ShouldEqual, 200)
+		})
+	})
+
+	Convey("requestTimeout", t, func() {
+		c := Config{
+			RequestTimeout: 30 * time.Second,
+			RouteTimeouts:  map[string]time.Duration{"/paths/strict-send": time.Minute},
+		}
+
+		So(requestTimeout(c, "/paths/strict-send"), ShouldEqual, time.Minute)
+		So(requestTimeout(c, "/ledgers"), ShouldEqual, 30*time.Second)
+	})
+}
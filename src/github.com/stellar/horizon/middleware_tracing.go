@@ -0,0 +1,46 @@
+package horizon
+
+import (
+	"fmt"
+	"net/http"
+
+	gctx "github.com/goji/context"
+	"github.com/stellar/horizon/context/requestid"
+	"github.com/stellar/horizon/trace"
+	"github.com/zenazn/goji/web"
+)
+
+// tracingMiddleware records a span for each request, the parent of the spans
+// of the queries and calls made to serve it.  It continues the trace of the
+// caller when the request carries a traceparent header.  Spans are named by
+// route, as metrics are labelled, once the request has been routed.
+func tracingMiddleware(c *web.C, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := trace.Extract(gctx.FromC(*c), r)
+		ctx, span := trace.Start(ctx, r.Method+" "+r.URL.Path)
+		if span == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		span.SetKind(trace.KindServer)
+		span.SetAttribute("http.method", r.Method)
+		span.SetAttribute("http.target", r.URL.RequestURI())
+		span.SetAttribute("request_id", requestid.FromContext(ctx))
+		gctx.Set(c, ctx)
+
+		mw := wrapWriter(w)
+		next.ServeHTTP(mw.(http.ResponseWriter), r)
+
+		route := routeOf(*c)
+		span.SetName(r.Method + " " + route)
+		span.SetAttribute("http.route", route)
+		span.SetAttribute("http.status_code", mw.Status())
+
+		var err error
+		if mw.Status() >= 500 {
+			err = fmt.Errorf("responded with status %d", mw.Status())
+		}
+		span.Finish(err)
+	})
+}
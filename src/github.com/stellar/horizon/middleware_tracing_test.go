@@ -0,0 +1,73 @@
+package horizon
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/test"
+)
+
+func TestTracingMiddleware(t *testing.T) {
+	test.LoadScenario("base")
+
+	Convey("Tracing", t, func() {
+		// spans exported to the collector, by name
+		var lock sync.Mutex
+		spans := map[string]map[string]interface{}{}
+
+		collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				ResourceSpans []struct {
+					ScopeSpans []struct {
+						Spans []map[string]interface{} `json:"spans"`
+					} `json:"scopeSpans"`
+				} `json:"resourceSpans"`
+			}
+
+			raw, _ := ioutil.ReadAll(r.Body)
+			json.Unmarshal(raw, &body)
+
+			lock.Lock()
+			defer lock.Unlock()
+			for _, rs := range body.ResourceSpans {
+				for _, ss := range rs.ScopeSpans {
+					for _, span := range ss.Spans {
+						spans[span["name"].(string)] = span
+					}
+				}
+			}
+		}))
+		defer collector.Close()
+
+		c := NewTestConfig()
+		c.TracingUrl = collector.URL
+		c.TracingSampleRate = 1
+		app, err := NewApp(c)
+		So(err, ShouldBeNil)
+		rh := NewRequestHelper(app)
+
+		w := rh.Get("/ledgers/1", func(r *http.Request) {
+			r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		})
+		So(w.Code, ShouldEqual, 200)
+
+		// closing the app flushes its spans
+		app.Close()
+
+		request := spans["GET /ledgers/:id"]
+		So(request, ShouldNotBeNil)
+		So(request["traceId"], ShouldEqual, "4bf92f3577b34da6a3ce929d0e0e4736")
+		So(request["parentSpanId"], ShouldEqual, "00f067aa0ba902b7")
+		So(request["kind"], ShouldEqual, 2.0)
+
+		query := spans["db.query"]
+		So(query, ShouldNotBeNil)
+		So(query["traceId"], ShouldEqual, "4bf92f3577b34da6a3ce929d0e0e4736")
+		So(query["parentSpanId"], ShouldEqual, request["spanId"])
+	})
+}
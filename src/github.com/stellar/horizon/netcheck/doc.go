@@ -0,0 +1,10 @@
+// Package netcheck verifies that the stellar-core horizon is connected to, and
+// the database it reads from, are of the network horizon is configured for.
+//
+// A horizon pointed at the stellar-core of another network, or at the database
+// of another stellar-core, would ingest the ledgers of one network into the
+// history of another and submit transactions to a network their signatures
+// are not for.  A Checker compares the network and protocol version stellar-core
+// reports of itself with its database and the configured passphrase, so that
+// ingestion and submission may be refused while they disagree.
+package netcheck
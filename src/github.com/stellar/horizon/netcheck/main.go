@@ -0,0 +1,253 @@
+package netcheck
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	stderr "errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-errors/errors"
+	"github.com/stellar/go-stellar-base/xdr"
+	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/httpx"
+	"github.com/stellar/horizon/log"
+	"github.com/stellar/horizon/simulate"
+	"golang.org/x/net/context"
+)
+
+// ErrMismatch is the error of a Checker whose last check found stellar-core,
+// or its database, to be of a network other than the configured one.
+// NOTE: this is not a go-errors based error, as stack traces are unnecessary
+var ErrMismatch = stderr.New("stellar-core is not on the configured network")
+
+// Info is what stellar-core reports of itself on its /info endpoint
+type Info struct {
+	// Build is the version of stellar-core
+	Build string `json:"build"`
+
+	// Network is the passphrase of the network stellar-core is on
+	Network string `json:"network"`
+
+	// ProtocolVersion is the latest protocol version stellar-core supports,
+	// zero when it does not report one
+	ProtocolVersion int32 `json:"protocol_version"`
+}
+
+// LoadInfo loads the info of the stellar-core at coreURL.
+func LoadInfo(ctx context.Context, client *http.Client, coreURL string) (Info, error) {
+	resp, err := client.Get(fmt.Sprint(coreURL, "/info"))
+	if err != nil {
+		return Info{}, errors.Wrap(err, 1)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Info{}, errors.Errorf("stellar-core responded with status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Info Info `json:"info"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&body)
+	if err != nil {
+		return Info{}, errors.Wrap(err, 1)
+	}
+
+	return body.Info, nil
+}
+
+// Checker checks stellar-core and its database against the network horizon is
+// configured for.  The outcome of the last check is kept for Err, which is
+// safe to call from the processes that refuse to run while they disagree.
+type Checker struct {
+	// Passphrase is that of the configured network
+	Passphrase string
+
+	// CoreURL is the http endpoint of stellar-core, whose info is checked when
+	// set.
+	CoreURL string
+
+	// Core is the database of stellar-core
+	Core db.SqlQuery
+
+	// Client performs the checker's requests.  When nil, the client bound to
+	// the context of each check by httpx.ClientContext is used.
+	Client *http.Client
+
+	lock sync.RWMutex
+	err  error
+}
+
+// Check checks stellar-core and its database against the configured network,
+// returning an error describing the first disagreement found, and making Err
+// return ErrMismatch until a later check finds none.  Failing to reach
+// stellar-core or its database is no disagreement: such an error is returned
+// without changing the outcome of the last check.
+//
+// The passphrase is checked against the one stellar-core reports, and
+// against its database by hashing the latest transaction it applied as a
+// transaction of the configured network.  The protocol version of the latest
+// ledger of the database must be one stellar-core supports.
+func (c *Checker) Check(ctx context.Context) error {
+	err := c.check(ctx)
+	_, mismatched := err.(*mismatchError)
+	if err != nil && !mismatched {
+		return err
+	}
+
+	c.lock.Lock()
+	c.err = nil
+	if mismatched {
+		c.err = ErrMismatch
+	}
+	c.lock.Unlock()
+
+	return err
+}
+
+// Err returns ErrMismatch while the last check found a disagreement, and nil
+// otherwise.
+func (c *Checker) Err() error {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.err
+}
+
+// Watch checks at once and then, in the background, every interval until ctx
+// is done, logging the disagreements found and the errors checking.  A
+// non-positive interval leaves the first check the only one.
+func (c *Checker) Watch(ctx context.Context, interval time.Duration) {
+	c.checkAndLog(ctx)
+
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			c.checkAndLog(ctx)
+		}
+	}()
+}
+
+func (c *Checker) checkAndLog(ctx context.Context) {
+	err := c.Check(ctx)
+	switch {
+	case err == nil:
+	case c.Err() != nil:
+		log.Errorf(ctx, "refusing to ingest or submit: %s", err)
+	default:
+		log.Warnf(ctx, "could not check the network of stellar-core: %s", err)
+	}
+}
+
+// mismatchError describes a disagreement found by a check
+type mismatchError struct {
+	reason string
+}
+
+func (e *mismatchError) Error() string {
+	return e.reason
+}
+
+func mismatch(format string, args ...interface{}) error {
+	return &mismatchError{reason: fmt.Sprintf(format, args...)}
+}
+
+// check returns a *mismatchError describing the first disagreement found, or
+// another error if stellar-core or its database could not be checked.
+func (c *Checker) check(ctx context.Context) error {
+	var info Info
+	if c.CoreURL != "" {
+		var err error
+		info, err = LoadInfo(ctx, c.client(ctx), c.CoreURL)
+		if err != nil {
+			return err
+		}
+
+		if info.Network != c.Passphrase {
+			return mismatch("stellar-core is on the network %q, not %q", info.Network, c.Passphrase)
+		}
+	}
+
+	err := c.checkTransactions(ctx)
+	if err != nil {
+		return err
+	}
+
+	return c.checkProtocol(ctx, info)
+}
+
+// checkTransactions checks the passphrase against the latest transaction
+// stellar-core applied, whose hash is that of its envelope on its network.
+func (c *Checker) checkTransactions(ctx context.Context) error {
+	var latest []db.CoreTransactionRecord
+	err := c.Core.SelectRaw(ctx, "SELECT * FROM txhistory ORDER BY ledgerseq DESC, txindex DESC LIMIT 1", nil, &latest)
+	if err != nil || len(latest) == 0 {
+		return err
+	}
+
+	var env xdr.TransactionEnvelope
+	err = xdr.SafeUnmarshalBase64(latest[0].EnvelopeXDR, &env)
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	hash, err := simulate.Hash(env.Tx, c.Passphrase)
+	if err != nil {
+		return err
+	}
+
+	if hex.EncodeToString(hash[:]) != latest[0].TransactionHash {
+		return mismatch("the transactions of the stellar-core database are not of the network %q", c.Passphrase)
+	}
+
+	return nil
+}
+
+// checkProtocol checks that stellar-core supports the protocol version of the
+// latest ledger of its database, unless it does not report the versions it
+// supports.
+func (c *Checker) checkProtocol(ctx context.Context, info Info) error {
+	if info.ProtocolVersion == 0 {
+		return nil
+	}
+
+	var latest []db.CoreLedgerHeaderRecord
+	sql := db.CoreLedgerHeaderRecordSelect.OrderBy("clh.ledgerseq DESC").Limit(1)
+	err := c.Core.Select(ctx, sql, &latest)
+	if err != nil || len(latest) == 0 {
+		return err
+	}
+
+	header, err := latest[0].Header()
+	if err != nil {
+		return err
+	}
+
+	if int32(header.LedgerVersion) > info.ProtocolVersion {
+		return mismatch("the stellar-core database is at protocol version %d, past the %d stellar-core supports", header.LedgerVersion, info.ProtocolVersion)
+	}
+
+	return nil
+}
+
+func (c *Checker) client(ctx context.Context) *http.Client {
+	if c.Client != nil {
+		return c.Client
+	}
+
+	return httpx.ClientFromContext(ctx)
+}
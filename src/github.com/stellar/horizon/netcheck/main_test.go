@@ -0,0 +1,92 @@
+package netcheck
+
+import (
+	"net/http"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/go-stellar-base/build"
+	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/test"
+)
+
+func TestLoadInfo(t *testing.T) {
+	Convey("LoadInfo", t, func() {
+		ctx := test.Context()
+
+		Convey("loads the info stellar-core reports", func() {
+			server := test.NewStaticMockServer(`{"info": {
+				"build": "v0.5.0",
+				"network": "Test SDF Network ; September 2015",
+				"protocol_version": 2
+			}}`)
+			defer server.Close()
+
+			info, err := LoadInfo(ctx, http.DefaultClient, server.URL)
+			So(err, ShouldBeNil)
+			So(info.Build, ShouldEqual, "v0.5.0")
+			So(info.Network, ShouldEqual, build.TestNetwork.Passphrase)
+			So(info.ProtocolVersion, ShouldEqual, 2)
+			So(server.LastRequest.URL.Path, ShouldEqual, "/info")
+		})
+
+		Convey("fails on a malformed response", func() {
+			server := test.NewStaticMockServer(`{`)
+			defer server.Close()
+
+			_, err := LoadInfo(ctx, http.DefaultClient, server.URL)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestChecker(t *testing.T) {
+	Convey("Checker", t, func() {
+		ctx := test.Context()
+		server := test.NewStaticMockServer(`{"info": {"network": "Test SDF Network ; September 2015"}}`)
+		defer server.Close()
+
+		checker := &Checker{
+			Passphrase: "Some Other Network",
+			CoreURL:    server.URL,
+			Client:     http.DefaultClient,
+		}
+
+		Convey("refuses a stellar-core on another network", func() {
+			So(checker.Check(ctx), ShouldNotBeNil)
+			So(checker.Err(), ShouldEqual, ErrMismatch)
+		})
+
+		Convey("keeps the last outcome while stellar-core is unreachable", func() {
+			So(checker.Check(ctx), ShouldNotBeNil)
+
+			checker.CoreURL = "http://127.0.0.1:0"
+			So(checker.Check(ctx), ShouldNotBeNil)
+			So(checker.Err(), ShouldEqual, ErrMismatch)
+		})
+
+		Convey("against the stellar-core database", func() {
+			test.LoadScenario("base")
+			core := test.OpenDatabase(test.StellarCoreDatabaseUrl())
+			defer core.Close()
+
+			checker.Core = db.SqlQuery{DB: core}
+			checker.CoreURL = ""
+
+			Convey("accepts the transactions of the configured network", func() {
+				checker.Passphrase = build.TestNetwork.Passphrase
+				So(checker.Check(ctx), ShouldBeNil)
+				So(checker.Err(), ShouldBeNil)
+			})
+
+			Convey("refuses the transactions of another network", func() {
+				So(checker.Check(ctx), ShouldNotBeNil)
+				So(checker.Err(), ShouldEqual, ErrMismatch)
+
+				checker.Passphrase = build.TestNetwork.Passphrase
+				So(checker.Check(ctx), ShouldBeNil)
+				So(checker.Err(), ShouldBeNil)
+			})
+		})
+	})
+}
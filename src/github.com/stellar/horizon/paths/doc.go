@@ -0,0 +1,14 @@
+// Package paths finds the paths by which a payment can be made from one asset
+// to another through the offers of the network's order books.
+//
+// Paths are searched for in a Graph: an in-memory snapshot of every offer, in
+// which each asset is a node and each order book an edge, weighted by the
+// amounts and prices of the book's offers.  A Finder holds the current graph,
+// and is refreshed with a new snapshot as each ledger closes.
+//
+// Two kinds of search are supported.  A strict-receive search starts from the
+// amount of an asset to be received and walks backwards through the books to
+// find what each candidate source asset would have to send.  A strict-send
+// search starts from the amount of an asset to be sent and walks forwards to
+// find what each candidate destination asset would receive.
+package paths
@@ -0,0 +1,35 @@
+package paths
+
+import (
+	"sync"
+)
+
+// Finder holds the graph searched for paths.  The graph is a snapshot, which
+// the owner of the finder replaces with a fresh one whenever the order books
+// change, such as when a ledger closes.  Searches in progress continue upon
+// the snapshot they started with.
+type Finder struct {
+	lock  sync.RWMutex
+	graph *Graph
+}
+
+// Graph returns the current snapshot.  Until the first Update, it is empty.
+func (f *Finder) Graph() *Graph {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	if f.graph == nil {
+		return NewGraph(nil)
+	}
+
+	return f.graph
+}
+
+// Update replaces the current snapshot with a graph of offers.
+func (f *Finder) Update(offers []Offer) {
+	g := NewGraph(offers)
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.graph = g
+}
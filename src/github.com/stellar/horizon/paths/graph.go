@@ -0,0 +1,229 @@
+package paths
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/go-errors/errors"
+	"github.com/stellar/go-stellar-base/xdr"
+)
+
+// ErrInvalidAsset is returned when parsing an asset that is not "native" or of
+// the form CODE:ISSUER.
+var ErrInvalidAsset = errors.New("invalid asset: must be 'native' or of the form CODE:ISSUER")
+
+// Asset identifies a node of the graph.
+type Asset struct {
+	Type   xdr.AssetType
+	Code   string
+	Issuer string
+}
+
+// Native is the native asset, lumens.
+var Native = Asset{Type: xdr.AssetTypeAssetTypeNative}
+
+// NewAsset returns the credit asset with the provided code and issuer, whose
+// type follows from the length of the code.
+func NewAsset(code, issuer string) Asset {
+	t := xdr.AssetTypeAssetTypeCreditAlphanum4
+	if len(code) > 4 {
+		t = xdr.AssetTypeAssetTypeCreditAlphanum12
+	}
+
+	return Asset{Type: t, Code: code, Issuer: issuer}
+}
+
+// ParseAsset parses an asset in the form String returns.
+func ParseAsset(s string) (Asset, error) {
+	if s == "native" {
+		return Native, nil
+	}
+
+	parts := strings.Split(s, ":")
+	if len(parts) != 2 || parts[0] == "" || len(parts[0]) > 12 || parts[1] == "" {
+		return Asset{}, errors.New(ErrInvalidAsset)
+	}
+
+	return NewAsset(parts[0], parts[1]), nil
+}
+
+// ParseAssets parses a comma separated list of assets.  An empty list returns
+// no assets.
+func ParseAssets(s string) ([]Asset, error) {
+	var result []Asset
+
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		asset, err := ParseAsset(part)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, asset)
+	}
+
+	return result, nil
+}
+
+// String returns "native" for the native asset, and CODE:ISSUER otherwise.
+func (a Asset) String() string {
+	if a.Type == xdr.AssetTypeAssetTypeNative {
+		return "native"
+	}
+
+	return fmt.Sprintf("%s:%s", a.Code, a.Issuer)
+}
+
+// Offer is an offer to sell Amount of Selling for Buying, at a price of
+// Pricen/Priced of Buying for each unit of Selling.
+type Offer struct {
+	Selling Asset
+	Buying  Asset
+	Amount  int64
+	Pricen  int32
+	Priced  int32
+}
+
+// Graph is a snapshot of the network's order books, indexed for path finding.
+// A Graph is immutable once built, and safe for concurrent use.
+type Graph struct {
+	// books holds the offers of each book, by the pair of assets they sell and
+	// buy, best priced first.
+	books map[pair][]Offer
+
+	// sellers holds, for each asset, the assets sold by the offers that buy
+	// it, and buyers the assets bought by the offers that sell it.
+	sellers map[Asset][]Asset
+	buyers  map[Asset][]Asset
+}
+
+type pair struct {
+	selling Asset
+	buying  Asset
+}
+
+// NewGraph builds a graph of the provided offers.  Offers that are empty, or
+// whose price is not positive, are ignored.
+func NewGraph(offers []Offer) *Graph {
+	g := &Graph{
+		books:   map[pair][]Offer{},
+		sellers: map[Asset][]Asset{},
+		buyers:  map[Asset][]Asset{},
+	}
+
+	for _, o := range offers {
+		if o.Amount <= 0 || o.Pricen <= 0 || o.Priced <= 0 {
+			continue
+		}
+
+		p := pair{selling: o.Selling, buying: o.Buying}
+		if _, ok := g.books[p]; !ok {
+			g.sellers[o.Buying] = append(g.sellers[o.Buying], o.Selling)
+			g.buyers[o.Selling] = append(g.buyers[o.Selling], o.Buying)
+		}
+		g.books[p] = append(g.books[p], o)
+	}
+
+	for _, book := range g.books {
+		sort.Sort(byPrice(book))
+	}
+
+	return g
+}
+
+// Len returns the number of offers in the graph.
+func (g *Graph) Len() int {
+	n := 0
+	for _, book := range g.books {
+		n += len(book)
+	}
+	return n
+}
+
+// cost returns the amount of buying that must be sent to the book selling
+// selling for buying to receive amount of selling.  Returns false if the book
+// cannot fill amount.
+func (g *Graph) cost(selling, buying Asset, amount int64) (int64, bool) {
+	remaining := amount
+	var total big.Int
+
+	for _, o := range g.books[pair{selling: selling, buying: buying}] {
+		if remaining == 0 {
+			break
+		}
+
+		take := o.Amount
+		if take > remaining {
+			take = remaining
+		}
+
+		total.Add(&total, priceCeil(take, o.Pricen, o.Priced))
+		remaining -= take
+	}
+
+	if remaining > 0 || !total.IsInt64() {
+		return 0, false
+	}
+
+	return total.Int64(), true
+}
+
+// proceeds returns the amount of selling received by sending amount of buying
+// to the book selling selling for buying.  Returns false if the book cannot
+// take all of amount, or nothing would be received.
+func (g *Graph) proceeds(selling, buying Asset, amount int64) (int64, bool) {
+	remaining := big.NewInt(amount)
+	var total big.Int
+
+	for _, o := range g.books[pair{selling: selling, buying: buying}] {
+		if remaining.Sign() == 0 {
+			break
+		}
+
+		// the price of the whole offer
+		whole := priceCeil(o.Amount, o.Pricen, o.Priced)
+		if remaining.Cmp(whole) >= 0 {
+			total.Add(&total, big.NewInt(o.Amount))
+			remaining.Sub(remaining, whole)
+			continue
+		}
+
+		// a part of the offer, rounded down in favor of the offer's seller
+		var part big.Int
+		part.Mul(remaining, big.NewInt(int64(o.Priced)))
+		part.Quo(&part, big.NewInt(int64(o.Pricen)))
+		total.Add(&total, &part)
+		remaining.SetInt64(0)
+	}
+
+	if remaining.Sign() > 0 || total.Sign() == 0 || !total.IsInt64() {
+		return 0, false
+	}
+
+	return total.Int64(), true
+}
+
+// priceCeil returns amount * n / d, rounded up.
+func priceCeil(amount int64, n, d int32) *big.Int {
+	var result, rem big.Int
+	result.Mul(big.NewInt(amount), big.NewInt(int64(n)))
+	result.QuoRem(&result, big.NewInt(int64(d)), &rem)
+	if rem.Sign() > 0 {
+		result.Add(&result, big.NewInt(1))
+	}
+	return &result
+}
+
+// byPrice orders offers from the lowest price to the highest
+type byPrice []Offer
+
+func (s byPrice) Len() int      { return len(s) }
+func (s byPrice) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byPrice) Less(i, j int) bool {
+	return int64(s[i].Pricen)*int64(s[j].Priced) < int64(s[j].Pricen)*int64(s[i].Priced)
+}
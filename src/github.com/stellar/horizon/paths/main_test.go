@@ -0,0 +1,167 @@
+package paths
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/go-stellar-base/xdr"
+)
+
+func TestPaths(t *testing.T) {
+	usd := NewAsset("USD", "GISSUER")
+	eur := NewAsset("EUR", "GISSUER")
+	gbp := NewAsset("GBP", "GISSUER")
+
+	g := NewGraph([]Offer{
+		// 2 native for each USD
+		{Selling: usd, Buying: Native, Amount: 100, Pricen: 2, Priced: 1},
+		// 1 USD for each EUR
+		{Selling: eur, Buying: usd, Amount: 50, Pricen: 1, Priced: 1},
+		// 3 native for each EUR, but few of them
+		{Selling: eur, Buying: Native, Amount: 10, Pricen: 3, Priced: 1},
+		// 1 EUR for each GBP
+		{Selling: gbp, Buying: eur, Amount: 50, Pricen: 1, Priced: 1},
+		{Selling: gbp, Buying: usd, Amount: 0, Pricen: 1, Priced: 1},
+	})
+
+	Convey("paths.ParseAsset", t, func() {
+		a, err := ParseAsset("native")
+		So(err, ShouldBeNil)
+		So(a, ShouldResemble, Native)
+
+		a, err = ParseAsset("USD:GISSUER")
+		So(err, ShouldBeNil)
+		So(a, ShouldResemble, usd)
+		So(a.String(), ShouldEqual, "USD:GISSUER")
+
+		a, err = ParseAsset("LONGERCODE:GISSUER")
+		So(err, ShouldBeNil)
+		So(a.Type, ShouldEqual, xdr.AssetTypeAssetTypeCreditAlphanum12)
+
+		for _, invalid := range []string{"", "USD", ":GISSUER", "USD:", "THIRTEENCHARS:GISSUER"} {
+			_, err = ParseAsset(invalid)
+			So(err, ShouldNotBeNil)
+		}
+
+		assets, err := ParseAssets("native, USD:GISSUER,")
+		So(err, ShouldBeNil)
+		So(assets, ShouldResemble, []Asset{Native, usd})
+	})
+
+	Convey("paths.Graph", t, func() {
+		Convey("ignores empty offers", func() {
+			So(g.Len(), ShouldEqual, 4)
+		})
+
+		Convey("costs round up, and proceeds round down", func() {
+			thirds := NewGraph([]Offer{{Selling: usd, Buying: Native, Amount: 100, Pricen: 1, Priced: 3}})
+
+			cost, ok := thirds.cost(usd, Native, 1)
+			So(ok, ShouldBeTrue)
+			So(cost, ShouldEqual, 1)
+
+			received, ok := thirds.proceeds(usd, Native, 1)
+			So(ok, ShouldBeTrue)
+			So(received, ShouldEqual, 3)
+
+			_, ok = thirds.cost(usd, Native, 101)
+			So(ok, ShouldBeFalse)
+		})
+
+		Convey("fills from the best priced offers first", func() {
+			book := NewGraph([]Offer{
+				{Selling: usd, Buying: Native, Amount: 10, Pricen: 3, Priced: 1},
+				{Selling: usd, Buying: Native, Amount: 10, Pricen: 1, Priced: 1},
+			})
+
+			cost, ok := book.cost(usd, Native, 15)
+			So(ok, ShouldBeTrue)
+			So(cost, ShouldEqual, 10+15)
+		})
+	})
+
+	Convey("paths.Graph.StrictReceive", t, func() {
+		Convey("finds multi-hop paths when the direct book is too thin", func() {
+			found := g.StrictReceive([]Asset{Native}, eur, 20, Options{})
+			So(len(found), ShouldEqual, 1)
+			So(found[0].Source, ShouldResemble, Native)
+			So(found[0].SourceAmount, ShouldEqual, 40)
+			So(found[0].Destination, ShouldResemble, eur)
+			So(found[0].DestinationAmount, ShouldEqual, 20)
+			So(found[0].Path, ShouldResemble, []Asset{usd})
+		})
+
+		Convey("prefers the cheapest path", func() {
+			found := g.StrictReceive([]Asset{Native}, eur, 5, Options{})
+			So(found[0].SourceAmount, ShouldEqual, 10)
+			So(found[0].Path, ShouldResemble, []Asset{usd})
+		})
+
+		Convey("avoids excluded assets", func() {
+			found := g.StrictReceive([]Asset{Native}, eur, 5, Options{Exclude: []Asset{usd}})
+			So(found[0].SourceAmount, ShouldEqual, 15)
+			So(found[0].Path, ShouldBeEmpty)
+
+			found = g.StrictReceive([]Asset{usd}, eur, 5, Options{Exclude: []Asset{usd}})
+			So(len(found), ShouldEqual, 1)
+			So(found[0].SourceAmount, ShouldEqual, 5)
+		})
+
+		Convey("limits the length of paths", func() {
+			found := g.StrictReceive([]Asset{Native}, gbp, 5, Options{})
+			So(len(found), ShouldEqual, 1)
+			So(found[0].Path, ShouldResemble, []Asset{usd, eur})
+
+			found = g.StrictReceive([]Asset{Native}, gbp, 5, Options{MaxLength: 1})
+			So(found[0].Path, ShouldResemble, []Asset{eur})
+			So(found[0].SourceAmount, ShouldEqual, 15)
+
+			found = g.StrictReceive([]Asset{Native}, gbp, 20, Options{MaxLength: 1})
+			So(found, ShouldBeEmpty)
+		})
+
+		Convey("returns one path for each source, cheapest first", func() {
+			found := g.StrictReceive([]Asset{Native, usd, eur}, gbp, 5, Options{Limit: 2})
+			So(len(found), ShouldEqual, 2)
+			// equally cheap, the shorter path comes first
+			So(found[0].Source, ShouldResemble, eur)
+			So(found[1].Source, ShouldResemble, usd)
+		})
+
+		Convey("stops searching once abandoned", func() {
+			done := make(chan struct{})
+			close(done)
+
+			found := g.StrictReceive([]Asset{Native}, eur, 5, Options{Done: done})
+			So(found, ShouldBeEmpty)
+		})
+	})
+
+	Convey("paths.Graph.StrictSend", t, func() {
+		Convey("finds the path delivering the most", func() {
+			found := g.StrictSend(Native, 40, []Asset{eur}, Options{})
+			So(len(found), ShouldEqual, 1)
+			So(found[0].Source, ShouldResemble, Native)
+			So(found[0].SourceAmount, ShouldEqual, 40)
+			So(found[0].DestinationAmount, ShouldEqual, 20)
+			So(found[0].Path, ShouldResemble, []Asset{usd})
+
+			found = g.StrictSend(Native, 6, []Asset{eur}, Options{Exclude: []Asset{usd}})
+			So(found[0].DestinationAmount, ShouldEqual, 2)
+			So(found[0].Path, ShouldBeEmpty)
+		})
+
+		Convey("ignores books that cannot take the whole amount", func() {
+			found := g.StrictSend(Native, 1000, []Asset{eur}, Options{})
+			So(found, ShouldBeEmpty)
+		})
+	})
+
+	Convey("paths.Finder", t, func() {
+		var f Finder
+		So(f.Graph().Len(), ShouldEqual, 0)
+
+		f.Update([]Offer{{Selling: usd, Buying: Native, Amount: 1, Pricen: 1, Priced: 1}})
+		So(f.Graph().Len(), ShouldEqual, 1)
+	})
+}
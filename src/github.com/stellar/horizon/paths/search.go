@@ -0,0 +1,282 @@
+package paths
+
+import (
+	"sort"
+)
+
+const (
+	// DefaultMaxLength is the number of intermediate assets a path may pass
+	// through when Options.MaxLength is zero.
+	DefaultMaxLength = 3
+
+	// DefaultLimit is the number of paths returned when Options.Limit is
+	// zero.
+	DefaultLimit = 20
+)
+
+// Options control a search.
+type Options struct {
+	// MaxLength is the most intermediate assets a path may pass through.
+	MaxLength int
+
+	// Exclude are assets that paths may not pass through.  They may still be
+	// the source or destination of a path.
+	Exclude []Asset
+
+	// Limit is the most paths returned.
+	Limit int
+
+	// Done, when set, abandons the search once closed, as when the request it
+	// answers times out.  The paths found by then are returned.
+	Done <-chan struct{}
+}
+
+// Path is a way of paying from one asset to another: sending SourceAmount of
+// Source, converted through each asset of Path in turn, delivers
+// DestinationAmount of Destination.
+type Path struct {
+	Source            Asset
+	SourceAmount      int64
+	Destination       Asset
+	DestinationAmount int64
+	Path              []Asset
+}
+
+// StrictReceive returns the paths that deliver amount of destination from any
+// of sources, cheapest first.  For each source, only the cheapest path is
+// returned.
+func (g *Graph) StrictReceive(sources []Asset, destination Asset, amount int64, opts Options) []Path {
+	s := newSearch(g, sources, opts)
+	s.receive(destination, amount, []Asset{destination})
+
+	for _, p := range s.found {
+		p.Destination = destination
+		p.DestinationAmount = amount
+	}
+
+	return s.results(func(a, b *Path) bool {
+		return a.SourceAmount < b.SourceAmount
+	})
+}
+
+// StrictSend returns the paths that deliver the most of any of destinations
+// for amount of source, most received first.  For each destination, only the
+// path delivering the most is returned.
+func (g *Graph) StrictSend(source Asset, amount int64, destinations []Asset, opts Options) []Path {
+	s := newSearch(g, destinations, opts)
+	s.send(source, amount, []Asset{source})
+
+	for _, p := range s.found {
+		p.Source = source
+		p.SourceAmount = amount
+	}
+
+	return s.results(func(a, b *Path) bool {
+		return a.DestinationAmount > b.DestinationAmount
+	})
+}
+
+// search is the state of a single search, which walks the graph depth first
+// from the asset whose amount is fixed towards the targets, keeping the best
+// path found to each target.
+type search struct {
+	graph    *Graph
+	opts     Options
+	targets  map[Asset]bool
+	excluded map[Asset]bool
+	found    map[Asset]*Path
+}
+
+func newSearch(g *Graph, targets []Asset, opts Options) *search {
+	if opts.MaxLength <= 0 {
+		opts.MaxLength = DefaultMaxLength
+	}
+
+	if opts.Limit <= 0 {
+		opts.Limit = DefaultLimit
+	}
+
+	s := &search{
+		graph:    g,
+		opts:     opts,
+		targets:  map[Asset]bool{},
+		excluded: map[Asset]bool{},
+		found:    map[Asset]*Path{},
+	}
+
+	for _, a := range targets {
+		s.targets[a] = true
+	}
+
+	for _, a := range opts.Exclude {
+		s.excluded[a] = true
+	}
+
+	return s
+}
+
+// receive visits asset, of which amount must be delivered to the next asset of
+// the path to receive the destination amount.  visited are the assets from
+// the destination to asset, inclusive.
+func (s *search) receive(asset Asset, amount int64, visited []Asset) {
+	if s.targets[asset] {
+		best, ok := s.found[asset]
+		if !ok || amount < best.SourceAmount || amount == best.SourceAmount && len(visited)-2 < len(best.Path) {
+			s.found[asset] = &Path{
+				Source:       asset,
+				SourceAmount: amount,
+				Path:         reversed(intermediates(visited)),
+			}
+		}
+	}
+
+	if !s.canExtend(asset, visited) {
+		return
+	}
+
+	for _, prev := range s.graph.buyers[asset] {
+		if !s.canVisit(prev, visited) {
+			continue
+		}
+
+		cost, ok := s.graph.cost(asset, prev, amount)
+		if !ok {
+			continue
+		}
+
+		s.receive(prev, cost, append(visited[:len(visited):len(visited)], prev))
+	}
+}
+
+// send visits asset, of which amount has been received from the previous asset
+// of the path.  visited are the assets from the source to asset, inclusive.
+func (s *search) send(asset Asset, amount int64, visited []Asset) {
+	if s.targets[asset] {
+		best, ok := s.found[asset]
+		if !ok || amount > best.DestinationAmount || amount == best.DestinationAmount && len(visited)-2 < len(best.Path) {
+			s.found[asset] = &Path{
+				Destination:       asset,
+				DestinationAmount: amount,
+				Path:              intermediates(visited),
+			}
+		}
+	}
+
+	if !s.canExtend(asset, visited) {
+		return
+	}
+
+	for _, next := range s.graph.sellers[asset] {
+		if !s.canVisit(next, visited) {
+			continue
+		}
+
+		received, ok := s.graph.proceeds(next, asset, amount)
+		if !ok {
+			continue
+		}
+
+		s.send(next, received, append(visited[:len(visited):len(visited)], next))
+	}
+}
+
+// canExtend returns true if a path through visited, ending at asset, may be
+// extended by another asset: with the asset that ends the path, the
+// intermediate assets of visited would number MaxLength at most.  Paths do not
+// pass through excluded assets, though they may start or end at them.
+func (s *search) canExtend(asset Asset, visited []Asset) bool {
+	if s.abandoned() {
+		return false
+	}
+
+	if len(visited) > 1 && s.excluded[asset] {
+		return false
+	}
+
+	return len(visited)-1 <= s.opts.MaxLength
+}
+
+// abandoned returns true once the search has been abandoned through
+// Options.Done
+func (s *search) abandoned() bool {
+	select {
+	case <-s.opts.Done:
+		return true
+	default:
+		return false
+	}
+}
+
+// canVisit returns true if asset may be the next asset of a path through
+// visited.  Paths do not visit an asset twice, and visit excluded assets only
+// to end at them.
+func (s *search) canVisit(asset Asset, visited []Asset) bool {
+	for _, a := range visited {
+		if a == asset {
+			return false
+		}
+	}
+
+	return !s.excluded[asset] || s.targets[asset]
+}
+
+// results returns the paths found, ordered by better, up to the limit.
+func (s *search) results(better func(a, b *Path) bool) []Path {
+	found := make([]*Path, 0, len(s.found))
+	for _, p := range s.found {
+		found = append(found, p)
+	}
+
+	sort.Sort(pathOrder{paths: found, better: better})
+
+	if len(found) > s.opts.Limit {
+		found = found[:s.opts.Limit]
+	}
+
+	result := make([]Path, len(found))
+	for i, p := range found {
+		result[i] = *p
+	}
+	return result
+}
+
+// intermediates returns the assets of visited between its first and last.
+func intermediates(visited []Asset) []Asset {
+	if len(visited) <= 2 {
+		return []Asset{}
+	}
+
+	return append([]Asset{}, visited[1:len(visited)-1]...)
+}
+
+func reversed(assets []Asset) []Asset {
+	for i, j := 0, len(assets)-1; i < j; i, j = i+1, j-1 {
+		assets[i], assets[j] = assets[j], assets[i]
+	}
+	return assets
+}
+
+// pathOrder orders paths by better, breaking ties in favor of shorter paths and
+// then by asset, so that results are deterministic.
+type pathOrder struct {
+	paths  []*Path
+	better func(a, b *Path) bool
+}
+
+func (o pathOrder) Len() int      { return len(o.paths) }
+func (o pathOrder) Swap(i, j int) { o.paths[i], o.paths[j] = o.paths[j], o.paths[i] }
+func (o pathOrder) Less(i, j int) bool {
+	a, b := o.paths[i], o.paths[j]
+	switch {
+	case o.better(a, b):
+		return true
+	case o.better(b, a):
+		return false
+	case len(a.Path) != len(b.Path):
+		return len(a.Path) < len(b.Path)
+	case a.Source != b.Source:
+		return a.Source.String() < b.Source.String()
+	default:
+		return a.Destination.String() < b.Destination.String()
+	}
+}
@@ -0,0 +1,333 @@
+// Package prometheus exposes the metrics of horizon in the text exposition
+// format scraped by prometheus: those of a go-metrics registry, converted as
+// they are written, and labelled counters and histograms counting
+// observations into buckets, which go-metrics lacks.
+package prometheus
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/rcrowley/go-metrics"
+)
+
+// ContentType is the content type of the text exposition format
+const ContentType = "text/plain; version=0.0.4"
+
+// DefaultBuckets are the upper bounds, in seconds, of the buckets of latency
+// histograms.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// quantiles are those of the summaries go-metrics timers and histograms are
+// written as
+var quantiles = []float64{0.5, 0.75, 0.95, 0.99, 0.999}
+
+// Collector writes metrics in the text exposition format
+type Collector interface {
+	Collect(w io.Writer) error
+}
+
+// Write writes the metrics of collectors to w, in order
+func Write(w io.Writer, collectors ...Collector) error {
+	for _, c := range collectors {
+		err := c.Collect(w)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Registry is the Collector of the metrics of a go-metrics registry, named as
+// they are registered under Namespace.  Gauges and counters are written as
+// gauges, counters going down as well as up; meters as counters of their
+// marks; histograms as summaries; and timers as summaries in seconds.
+type Registry struct {
+	Namespace string
+	Registry  metrics.Registry
+}
+
+// Collect is a method for Collector
+func (r *Registry) Collect(w io.Writer) error {
+	all := map[string]interface{}{}
+	r.Registry.Each(func(name string, i interface{}) {
+		all[name] = i
+	})
+
+	names := make([]string, 0, len(all))
+	for name := range all {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	for _, name := range names {
+		n := Name(r.Namespace, name)
+
+		switch metric := all[name].(type) {
+		case metrics.Counter:
+			writeType(&buf, n, "gauge")
+			writeSample(&buf, n, nil, float64(metric.Count()))
+		case metrics.Gauge:
+			writeType(&buf, n, "gauge")
+			writeSample(&buf, n, nil, float64(metric.Value()))
+		case metrics.GaugeFloat64:
+			writeType(&buf, n, "gauge")
+			writeSample(&buf, n, nil, metric.Value())
+		case metrics.Meter:
+			writeType(&buf, n+"_total", "counter")
+			writeSample(&buf, n+"_total", nil, float64(metric.Count()))
+		case metrics.Histogram:
+			h := metric.Snapshot()
+			writeSummary(&buf, n, h.Percentiles(quantiles), float64(h.Sum()), h.Count(), 1)
+		case metrics.Timer:
+			t := metric.Snapshot()
+			writeSummary(&buf, n+"_seconds", t.Percentiles(quantiles), float64(t.Sum()), t.Count(), 1e9)
+		}
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// HistogramVec is the Collector of a histogram for each combination of the
+// values of its Labels, created as they are first observed.
+//
+// A HistogramVec is safe for concurrent access.
+type HistogramVec struct {
+	Name    string
+	Help    string
+	Labels  []string
+	Buckets []float64
+
+	lock   sync.Mutex
+	series map[string]*histogram
+}
+
+// histogram is the series of a HistogramVec for a set of label values
+type histogram struct {
+	values []string
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// NewHistogramVec returns a histogram vector with the buckets provided, which
+// must be sorted, labelled by labels.
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	return &HistogramVec{
+		Name:    name,
+		Help:    help,
+		Labels:  labels,
+		Buckets: buckets,
+		series:  map[string]*histogram{},
+	}
+}
+
+// Observe counts value into the histogram of values, given in the order of
+// Labels
+func (h *HistogramVec) Observe(value float64, values ...string) {
+	key := strings.Join(values, "\x00")
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogram{values: values, counts: make([]uint64, len(h.Buckets))}
+		h.series[key] = s
+	}
+
+	for i, bound := range h.Buckets {
+		if value <= bound {
+			s.counts[i]++
+		}
+	}
+	s.sum += value
+	s.count++
+}
+
+// Collect is a method for Collector
+func (h *HistogramVec) Collect(w io.Writer) error {
+	h.lock.Lock()
+	keys := make([]string, 0, len(h.series))
+	for key := range h.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# HELP %s %s\n", h.Name, escapeHelp(h.Help))
+	writeType(&buf, h.Name, "histogram")
+
+	for _, key := range keys {
+		s := h.series[key]
+		labels := make([]string, len(h.Labels))
+		for i, name := range h.Labels {
+			labels[i] = label(name, s.values[i])
+		}
+
+		for i, bound := range h.Buckets {
+			le := label("le", strconv.FormatFloat(bound, 'g', -1, 64))
+			writeSample(&buf, h.Name+"_bucket", append(labels, le), float64(s.counts[i]))
+		}
+		writeSample(&buf, h.Name+"_bucket", append(labels, label("le", "+Inf")), float64(s.count))
+		writeSample(&buf, h.Name+"_sum", labels, s.sum)
+		writeSample(&buf, h.Name+"_count", labels, float64(s.count))
+	}
+	h.lock.Unlock()
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// CounterVec is the Collector of a counter for each combination of the values
+// of its Labels, created as they are first counted.
+//
+// A CounterVec is safe for concurrent access.
+type CounterVec struct {
+	Name   string
+	Help   string
+	Labels []string
+
+	lock   sync.Mutex
+	series map[string]*counter
+}
+
+// counter is the series of a CounterVec for a set of label values
+type counter struct {
+	values []string
+	count  uint64
+}
+
+// NewCounterVec returns a counter vector labelled by labels
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return &CounterVec{
+		Name:   name,
+		Help:   help,
+		Labels: labels,
+		series: map[string]*counter{},
+	}
+}
+
+// Inc increments the counter of values, given in the order of Labels
+func (c *CounterVec) Inc(values ...string) {
+	key := strings.Join(values, "\x00")
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	s, ok := c.series[key]
+	if !ok {
+		s = &counter{values: values}
+		c.series[key] = s
+	}
+	s.count++
+}
+
+// Collect is a method for Collector
+func (c *CounterVec) Collect(w io.Writer) error {
+	c.lock.Lock()
+	keys := make([]string, 0, len(c.series))
+	for key := range c.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# HELP %s %s\n", c.Name, escapeHelp(c.Help))
+	writeType(&buf, c.Name, "counter")
+
+	for _, key := range keys {
+		s := c.series[key]
+		labels := make([]string, len(c.Labels))
+		for i, name := range c.Labels {
+			labels[i] = label(name, s.values[i])
+		}
+		writeSample(&buf, c.Name, labels, float64(s.count))
+	}
+	c.lock.Unlock()
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// Name returns the metric name of the go-metrics name within namespace, its
+// characters other than letters, digits and underscores replaced with
+// underscores: "history.latest_ledger" becomes "horizon_history_latest_ledger"
+// within "horizon".
+func Name(namespace, name string) string {
+	if namespace != "" {
+		name = namespace + "_" + name
+	}
+
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
+
+func writeType(buf *bytes.Buffer, name, typ string) {
+	fmt.Fprintf(buf, "# TYPE %s %s\n", name, typ)
+}
+
+// writeSummary writes the summary of a go-metrics timer or histogram, whose
+// values are divided by unit.
+func writeSummary(buf *bytes.Buffer, name string, ps []float64, sum float64, count int64, unit float64) {
+	writeType(buf, name, "summary")
+	for i, q := range quantiles {
+		quantile := label("quantile", strconv.FormatFloat(q, 'g', -1, 64))
+		writeSample(buf, name, []string{quantile}, ps[i]/unit)
+	}
+	writeSample(buf, name+"_sum", nil, sum/unit)
+	writeSample(buf, name+"_count", nil, float64(count))
+}
+
+func writeSample(buf *bytes.Buffer, name string, labels []string, value float64) {
+	buf.WriteString(name)
+	if len(labels) > 0 {
+		buf.WriteString("{")
+		buf.WriteString(strings.Join(labels, ","))
+		buf.WriteString("}")
+	}
+	buf.WriteString(" ")
+	buf.WriteString(formatValue(value))
+	buf.WriteString("\n")
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// label returns the label name="value", value escaped
+func label(name, value string) string {
+	value = strings.Replace(value, `\`, `\\`, -1)
+	value = strings.Replace(value, "\n", `\n`, -1)
+	value = strings.Replace(value, `"`, `\"`, -1)
+	return name + `="` + value + `"`
+}
+
+func escapeHelp(help string) string {
+	help = strings.Replace(help, `\`, `\\`, -1)
+	return strings.Replace(help, "\n", `\n`, -1)
+}
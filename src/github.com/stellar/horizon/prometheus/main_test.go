@@ -0,0 +1,96 @@
+package prometheus
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/rcrowley/go-metrics"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestName(t *testing.T) {
+	Convey("Name", t, func() {
+		So(Name("horizon", "history.latest_ledger"), ShouldEqual, "horizon_history_latest_ledger")
+		So(Name("", "logging.warning"), ShouldEqual, "logging_warning")
+		So(Name("horizon", "txsub-queued"), ShouldEqual, "horizon_txsub_queued")
+	})
+}
+
+func TestRegistry(t *testing.T) {
+	Convey("Registry", t, func() {
+		r := metrics.NewRegistry()
+		gauge := metrics.NewGauge()
+		gauge.Update(7)
+		counter := metrics.NewCounter()
+		counter.Inc(3)
+		meter := metrics.NewMeter()
+		meter.Mark(2)
+		timer := metrics.NewTimer()
+		timer.Update(2 * time.Second)
+		r.Register("sse.clients", gauge)
+		r.Register("history.connections_waiting", counter)
+		r.Register("txsub.succeeded", meter)
+		r.Register("requests.total", timer)
+
+		var buf bytes.Buffer
+		err := Write(&buf, &Registry{Namespace: "horizon", Registry: r})
+		So(err, ShouldBeNil)
+
+		out := buf.String()
+		So(out, ShouldContainSubstring, "# TYPE horizon_sse_clients gauge\nhorizon_sse_clients 7\n")
+		So(out, ShouldContainSubstring, "# TYPE horizon_history_connections_waiting gauge\nhorizon_history_connections_waiting 3\n")
+		So(out, ShouldContainSubstring, "# TYPE horizon_txsub_succeeded_total counter\nhorizon_txsub_succeeded_total 2\n")
+		So(out, ShouldContainSubstring, "# TYPE horizon_requests_total_seconds summary\n")
+		So(out, ShouldContainSubstring, "horizon_requests_total_seconds{quantile=\"0.5\"} 2\n")
+		So(out, ShouldContainSubstring, "horizon_requests_total_seconds_sum 2\n")
+		So(out, ShouldContainSubstring, "horizon_requests_total_seconds_count 1\n")
+	})
+}
+
+func TestHistogramVec(t *testing.T) {
+	Convey("HistogramVec", t, func() {
+		h := NewHistogramVec("latency_seconds", "Latency.", []float64{0.1, 1}, "route", "method")
+		h.Observe(0.05, "/ledgers", "GET")
+		h.Observe(0.5, "/ledgers", "GET")
+		h.Observe(5, "/ledgers", "GET")
+		h.Observe(0.5, `/a"b`, "POST")
+
+		var buf bytes.Buffer
+		err := h.Collect(&buf)
+		So(err, ShouldBeNil)
+
+		So(buf.String(), ShouldEqual, `# HELP latency_seconds Latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{route="/a\"b",method="POST",le="0.1"} 0
+latency_seconds_bucket{route="/a\"b",method="POST",le="1"} 1
+latency_seconds_bucket{route="/a\"b",method="POST",le="+Inf"} 1
+latency_seconds_sum{route="/a\"b",method="POST"} 0.5
+latency_seconds_count{route="/a\"b",method="POST"} 1
+latency_seconds_bucket{route="/ledgers",method="GET",le="0.1"} 1
+latency_seconds_bucket{route="/ledgers",method="GET",le="1"} 2
+latency_seconds_bucket{route="/ledgers",method="GET",le="+Inf"} 3
+latency_seconds_sum{route="/ledgers",method="GET"} 5.55
+latency_seconds_count{route="/ledgers",method="GET"} 3
+`)
+	})
+}
+
+func TestCounterVec(t *testing.T) {
+	Convey("CounterVec", t, func() {
+		c := NewCounterVec("slow_queries_total", "Slow queries.", "route")
+		c.Inc("/ledgers")
+		c.Inc("/ledgers")
+		c.Inc("")
+
+		var buf bytes.Buffer
+		err := c.Collect(&buf)
+		So(err, ShouldBeNil)
+
+		So(buf.String(), ShouldEqual, `# HELP slow_queries_total Slow queries.
+# TYPE slow_queries_total counter
+slow_queries_total{route=""} 1
+slow_queries_total{route="/ledgers"} 2
+`)
+	})
+}
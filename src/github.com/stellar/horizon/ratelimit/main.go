@@ -0,0 +1,194 @@
+// Package ratelimit throttles the requests of each client with a token bucket:
+// a client may make as many requests at once as its bucket holds tokens, each
+// taking one, and its bucket refills at a steady rate.  Buckets are held by a
+// Store, either in memory or in redis, where they are shared by every horizon
+// using it.
+package ratelimit
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/PuerkitoBio/throttled"
+)
+
+// Bucket configures the token bucket of each client
+type Bucket struct {
+	// Rate is the count of tokens a bucket gains per second
+	Rate float64
+
+	// Burst is the most tokens a bucket holds, and the count a client is
+	// given when first seen.
+	Burst int
+}
+
+// FromQuota returns a bucket refilled at the rate of q, holding burst tokens
+// at most or, when burst is not positive, the count of requests q allows per
+// window.  As with throttled, q allows at least 1 request per second or
+// longer window.
+func FromQuota(q throttled.Quota, burst int) Bucket {
+	reqs, window := q.Quota()
+	if reqs < 1 {
+		reqs = 1
+	}
+	if window < time.Second {
+		window = time.Second
+	}
+	if burst <= 0 {
+		burst = reqs
+	}
+
+	return Bucket{Rate: float64(reqs) / window.Seconds(), Burst: burst}
+}
+
+// Result is the state of a bucket after a request tried to take a token
+type Result struct {
+	// Allowed is true when the request took a token
+	Allowed bool
+
+	// Remaining is the count of whole tokens left
+	Remaining int
+
+	// Reset is how long until the bucket is full again
+	Reset time.Duration
+
+	// RetryAfter is how long until the bucket holds a token again, when the
+	// request was not allowed.
+	RetryAfter time.Duration
+}
+
+// Store holds the buckets of clients, by key
+type Store interface {
+	// Take takes a token from the bucket of key, configured by b
+	Take(key string, b Bucket) (Result, error)
+}
+
+// Throttler is a throttled.Throttler whose bucket may be changed while it
+// throttles, as when horizon reloads its config.
+type Throttler struct {
+	*throttled.Throttler
+	limiter *limiter
+}
+
+// SetBucket configures the buckets of the clients of t by b from now on.  The
+// tokens buckets already hold are kept, up to the burst of b.
+func (t *Throttler) SetBucket(b Bucket) {
+	t.limiter.lock.Lock()
+	t.limiter.bucket = b
+	t.limiter.lock.Unlock()
+}
+
+// Bucket returns the bucket configuring those of the clients of t
+func (t *Throttler) Bucket() Bucket {
+	return t.limiter.currentBucket()
+}
+
+// New returns a throttler allowing the requests of each client, as told apart
+// by vary, while their bucket held by store has tokens left.  It sets the
+// following headers on each response:
+//
+//	X-RateLimit-Limit : the most tokens a bucket holds
+//	X-RateLimit-Remaining : the tokens left in the bucket
+//	X-RateLimit-Reset : seconds until the bucket is full again
+//
+// and, on the responses of the requests it denies:
+//
+//	Retry-After : seconds until the bucket holds a token again
+//
+// A request throttled by several throttlers is answered with the headers of
+// the strictest: that which denied it, or else that with the fewest tokens
+// left.
+func New(b Bucket, vary func(*http.Request) string, store Store) *Throttler {
+	l := &limiter{bucket: b, vary: vary, store: store}
+	return &Throttler{Throttler: throttled.Custom(l), limiter: l}
+}
+
+// limiter is the throttled.Limiter of the throttlers returned by New
+type limiter struct {
+	vary  func(*http.Request) string
+	store Store
+
+	lock   sync.RWMutex
+	bucket Bucket
+}
+
+func (l *limiter) currentBucket() Bucket {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+	return l.bucket
+}
+
+// Start is a method for throttled.Limiter
+func (l *limiter) Start() {}
+
+// Limit is a method for throttled.Limiter
+func (l *limiter) Limit(w http.ResponseWriter, r *http.Request) (<-chan bool, error) {
+	bucket := l.currentBucket()
+	result, err := l.store.Take(l.vary(r), bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	h := w.Header()
+	if !result.Allowed || !holdsFewer(h, result.Remaining) {
+		h.Set("X-RateLimit-Limit", strconv.Itoa(bucket.Burst))
+		h.Set("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		h.Set("X-RateLimit-Reset", seconds(result.Reset))
+	}
+	if !result.Allowed {
+		h.Set("Retry-After", seconds(result.RetryAfter))
+	}
+
+	ch := make(chan bool, 1)
+	ch <- result.Allowed
+	return ch, nil
+}
+
+// holdsFewer returns true if h carries the headers of a throttler that left no
+// more tokens than remaining.
+func holdsFewer(h http.Header, remaining int) bool {
+	held, err := strconv.Atoi(h.Get("X-RateLimit-Remaining"))
+	return err == nil && held <= remaining
+}
+
+// refill returns the tokens of a bucket configured by b that held tokens
+// elapsed ago.
+func refill(b Bucket, tokens float64, elapsed time.Duration) float64 {
+	if elapsed > 0 {
+		tokens += elapsed.Seconds() * b.Rate
+	}
+
+	return math.Min(tokens, float64(b.Burst))
+}
+
+// result returns the result of a request that left tokens in a bucket
+// configured by b.
+func result(b Bucket, tokens float64, allowed bool) Result {
+	r := Result{
+		Allowed:   allowed,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     duration((float64(b.Burst) - tokens) / b.Rate),
+	}
+
+	if !allowed {
+		r.RetryAfter = duration((1 - tokens) / b.Rate)
+	}
+
+	return r
+}
+
+func duration(secs float64) time.Duration {
+	if secs <= 0 || math.IsNaN(secs) {
+		return 0
+	}
+
+	return time.Duration(secs * float64(time.Second))
+}
+
+// seconds formats d as whole seconds, rounded up
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
@@ -0,0 +1,151 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/throttled"
+	"github.com/garyburd/redigo/redis"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFromQuota(t *testing.T) {
+	Convey("FromQuota", t, func() {
+		So(FromQuota(throttled.PerHour(3600), 0), ShouldResemble, Bucket{Rate: 1, Burst: 3600})
+		So(FromQuota(throttled.PerMin(60), 5), ShouldResemble, Bucket{Rate: 1, Burst: 5})
+		So(FromQuota(throttled.PerHour(0), 0), ShouldResemble, Bucket{Rate: 1.0 / 3600, Burst: 1})
+	})
+}
+
+func TestMemoryStore(t *testing.T) {
+	Convey("MemoryStore", t, func() {
+		now := time.Unix(1000, 0)
+		s := NewMemoryStore(10)
+		s.now = func() time.Time { return now }
+		b := Bucket{Rate: 0.5, Burst: 2}
+
+		Convey("allows bursts, refilling at the rate", func() {
+			r, _ := s.Take("a", b)
+			So(r, ShouldResemble, Result{Allowed: true, Remaining: 1, Reset: 2 * time.Second})
+			r, _ = s.Take("a", b)
+			So(r, ShouldResemble, Result{Allowed: true, Remaining: 0, Reset: 4 * time.Second})
+
+			r, _ = s.Take("a", b)
+			So(r.Allowed, ShouldBeFalse)
+			So(r.RetryAfter, ShouldEqual, 2*time.Second)
+
+			now = now.Add(time.Second)
+			r, _ = s.Take("a", b)
+			So(r.Allowed, ShouldBeFalse)
+			So(r.RetryAfter, ShouldEqual, time.Second)
+
+			now = now.Add(time.Second)
+			r, _ = s.Take("a", b)
+			So(r.Allowed, ShouldBeTrue)
+		})
+
+		Convey("keeps a bucket per key", func() {
+			s.Take("a", b)
+			s.Take("a", b)
+
+			r, _ := s.Take("b", b)
+			So(r.Allowed, ShouldBeTrue)
+		})
+
+		Convey("fills buckets no further than their burst", func() {
+			s.Take("a", b)
+			now = now.Add(time.Hour)
+
+			r, _ := s.Take("a", b)
+			So(r.Remaining, ShouldEqual, 1)
+		})
+	})
+}
+
+func TestNew(t *testing.T) {
+	Convey("New", t, func() {
+		s := NewMemoryStore(10)
+		s.now = func() time.Time { return time.Unix(1000, 0) }
+		throttler := New(Bucket{Rate: 0.1, Burst: 1}, func(*http.Request) string { return "client" }, s)
+		throttler.DeniedHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(429)
+		})
+		h := throttler.Throttle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+		get := func() *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			r, _ := http.NewRequest("GET", "/", nil)
+			h.ServeHTTP(w, r)
+			return w
+		}
+
+		w := get()
+		So(w.Code, ShouldEqual, 200)
+		So(w.Header().Get("X-RateLimit-Limit"), ShouldEqual, "1")
+		So(w.Header().Get("X-RateLimit-Remaining"), ShouldEqual, "0")
+		So(w.Header().Get("X-RateLimit-Reset"), ShouldEqual, "10")
+		So(w.Header().Get("Retry-After"), ShouldEqual, "")
+
+		w = get()
+		So(w.Code, ShouldEqual, 429)
+		So(w.Header().Get("Retry-After"), ShouldEqual, "10")
+
+		Convey("throttles by the bucket set last", func() {
+			throttler.SetBucket(Bucket{Rate: 0.1, Burst: 3})
+			So(throttler.Bucket(), ShouldResemble, Bucket{Rate: 0.1, Burst: 3})
+
+			w := get()
+			So(w.Code, ShouldEqual, 429)
+			So(w.Header().Get("X-RateLimit-Limit"), ShouldEqual, "3")
+		})
+
+		Convey("answers with the headers of the strictest of several throttlers", func() {
+			vary := func(*http.Request) string { return "other" }
+			loose := New(Bucket{Rate: 0.1, Burst: 5}, vary, s)
+			strict := New(Bucket{Rate: 0.1, Burst: 3}, vary, NewMemoryStore(10))
+			h := loose.Throttle(strict.Throttle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+
+			w := httptest.NewRecorder()
+			r, _ := http.NewRequest("GET", "/", nil)
+			h.ServeHTTP(w, r)
+
+			So(w.Code, ShouldEqual, 200)
+			So(w.Header()[http.CanonicalHeaderKey("X-RateLimit-Limit")], ShouldResemble, []string{"3"})
+			So(w.Header()[http.CanonicalHeaderKey("X-RateLimit-Remaining")], ShouldResemble, []string{"2"})
+			So(w.Header()[http.CanonicalHeaderKey("X-RateLimit-Reset")], ShouldResemble, []string{"10"})
+		})
+	})
+}
+
+func TestRedisStore(t *testing.T) {
+	Convey("RedisStore", t, func() {
+		pool := &redis.Pool{
+			Dial: func() (redis.Conn, error) { return redis.Dial("tcp", "127.0.0.1:6379") },
+		}
+		defer pool.Close()
+
+		conn := pool.Get()
+		_, err := conn.Do("DEL", "test:a")
+		conn.Close()
+		So(err, ShouldBeNil)
+
+		s := &RedisStore{Pool: pool, Prefix: "test:"}
+		b := Bucket{Rate: 0.001, Burst: 2}
+
+		r, err := s.Take("a", b)
+		So(err, ShouldBeNil)
+		So(r.Allowed, ShouldBeTrue)
+		So(r.Remaining, ShouldEqual, 1)
+
+		r, err = s.Take("a", b)
+		So(err, ShouldBeNil)
+		So(r.Allowed, ShouldBeTrue)
+
+		r, err = s.Take("a", b)
+		So(err, ShouldBeNil)
+		So(r.Allowed, ShouldBeFalse)
+		So(r.RetryAfter, ShouldBeGreaterThan, 0)
+	})
+}
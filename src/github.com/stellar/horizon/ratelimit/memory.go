@@ -0,0 +1,51 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+
+	"github.com/golang/groupcache/lru"
+)
+
+// MemoryStore holds buckets in memory, forgetting the least recently used
+// once it holds Size of them.  A forgotten bucket is full when next used.
+type MemoryStore struct {
+	lock    sync.Mutex
+	buckets *lru.Cache
+	now     func() time.Time
+}
+
+// memoryBucket is the state of a bucket of a MemoryStore
+type memoryBucket struct {
+	tokens float64
+	at     time.Time
+}
+
+// NewMemoryStore returns a store holding size buckets at most
+func NewMemoryStore(size int) *MemoryStore {
+	return &MemoryStore{
+		buckets: lru.New(size),
+		now:     time.Now,
+	}
+}
+
+// Take is a method for Store
+func (s *MemoryStore) Take(key string, b Bucket) (Result, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := s.now()
+	tokens := float64(b.Burst)
+	if found, ok := s.buckets.Get(key); ok {
+		held := found.(memoryBucket)
+		tokens = refill(b, held.tokens, now.Sub(held.at))
+	}
+
+	allowed := tokens >= 1
+	if allowed {
+		tokens--
+	}
+
+	s.buckets.Add(key, memoryBucket{tokens: tokens, at: now})
+	return result(b, tokens, allowed), nil
+}
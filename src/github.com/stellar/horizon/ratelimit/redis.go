@@ -0,0 +1,70 @@
+package ratelimit
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/go-errors/errors"
+)
+
+// takeScript takes a token from the bucket of KEYS[1], refilled at ARGV[1]
+// tokens per second and holding ARGV[2] at most, as of the clock of redis
+// itself so that the clocks of the horizons sharing it need not agree.  It
+// returns whether a token was taken and the tokens left, as a string lest lua
+// truncate them.  Buckets expire once full again.
+var takeScript = redis.NewScript(1, `
+redis.replicate_commands()
+
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) + tonumber(time[2]) / 1000000
+
+local held = redis.call('HMGET', KEYS[1], 'tokens', 'at')
+local tokens = tonumber(held[1]) or burst
+local at = tonumber(held[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - at) * rate)
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HMSET', KEYS[1], 'tokens', tostring(tokens), 'at', tostring(now))
+redis.call('EXPIRE', KEYS[1], math.ceil((burst - tokens) / rate) + 1)
+return {allowed, tostring(tokens)}
+`)
+
+// RedisStore holds buckets in redis, under keys prefixed with Prefix, shared
+// by every horizon using it.
+type RedisStore struct {
+	Pool   *redis.Pool
+	Prefix string
+}
+
+// Take is a method for Store
+func (s *RedisStore) Take(key string, b Bucket) (Result, error) {
+	conn := s.Pool.Get()
+	defer conn.Close()
+
+	reply, err := redis.Values(takeScript.Do(conn, s.Prefix+key, b.Rate, b.Burst))
+	if err != nil {
+		return Result{}, errors.Wrap(err, 1)
+	}
+
+	var allowed int
+	var held string
+	_, err = redis.Scan(reply, &allowed, &held)
+	if err != nil {
+		return Result{}, errors.Wrap(err, 1)
+	}
+
+	tokens, err := strconv.ParseFloat(held, 64)
+	if err != nil {
+		return Result{}, errors.Wrap(err, 1)
+	}
+
+	return result(b, math.Max(tokens, 0), allowed == 1), nil
+}
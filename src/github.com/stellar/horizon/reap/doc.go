@@ -0,0 +1,5 @@
+// Package reap deletes the history older than horizon's retention window,
+// in small batches so that reaping never holds up ingestion or requests for
+// long.  Clients learn of the truncated history from the elder ledger the
+// root resource advertises.
+package reap
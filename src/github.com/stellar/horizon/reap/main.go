@@ -0,0 +1,112 @@
+package reap
+
+import (
+	"time"
+
+	"github.com/go-errors/errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/log"
+	"golang.org/x/net/context"
+)
+
+// DefaultBatchLedgers is the count of ledgers a Tick reaps at most when
+// System.BatchLedgers is not set.
+const DefaultBatchLedgers = 100
+
+// System reaps the ledgers closed longer ago than RetentionWindow from the
+// history database, oldest first.
+type System struct {
+	HorizonDB *sqlx.DB
+
+	// RetentionWindow is how long history is kept once its ledger closed
+	RetentionWindow time.Duration
+
+	// BatchLedgers caps the ledgers a Tick reaps, each batch being deleted in a
+	// single db transaction.
+	BatchLedgers int
+
+	// Idle reports whether horizon is idle enough to reap.  Reaping is always
+	// allowed when nil.
+	Idle func() bool
+}
+
+// Tick reaps, within a single db transaction, the oldest batch of the ledgers
+// that have expired, returning the count of ledgers reaped.  Nothing is reaped
+// unless horizon is idle.  The latest ledger is never reaped, as ingestion
+// resumes from it.
+func (sys *System) Tick(ctx context.Context) (int, error) {
+	if sys.RetentionWindow <= 0 || (sys.Idle != nil && !sys.Idle()) {
+		return 0, nil
+	}
+
+	var bounds struct {
+		Elder  int32 `db:"elder"`
+		Cutoff int32 `db:"cutoff"`
+	}
+	err := sys.history().GetRaw(ctx, `
+		SELECT COALESCE(MIN(sequence), 0) AS elder, COALESCE(MAX(sequence), 0) AS cutoff
+		FROM history_ledgers
+		WHERE closed_at < $1
+		AND sequence < (SELECT MAX(sequence) FROM history_ledgers)`,
+		[]interface{}{time.Now().UTC().Add(-sys.RetentionWindow)},
+		&bounds,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	if bounds.Cutoff == 0 {
+		return 0, nil
+	}
+
+	batch := sys.BatchLedgers
+	if batch <= 0 {
+		batch = DefaultBatchLedgers
+	}
+
+	end := bounds.Elder + int32(batch) - 1
+	if end > bounds.Cutoff {
+		end = bounds.Cutoff
+	}
+
+	err = sys.reap(bounds.Elder, end)
+	if err != nil {
+		return 0, err
+	}
+
+	log.WithField(ctx, "start", bounds.Elder).
+		WithField("end", end).
+		Info("reaped history")
+	return int(end - bounds.Elder + 1), nil
+}
+
+// reap deletes the history of the ledgers from `from` to `to` inclusive
+func (sys *System) reap(from, to int32) (err error) {
+	tx, err := sys.HorizonDB.Beginx()
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	err = db.DeleteLedgerRange(tx, from, to)
+	if err != nil {
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	return nil
+}
+
+func (sys *System) history() db.SqlQuery {
+	return db.SqlQuery{DB: sys.HorizonDB}
+}
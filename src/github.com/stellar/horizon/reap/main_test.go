@@ -0,0 +1,73 @@
+package reap
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/test"
+)
+
+func TestReap(t *testing.T) {
+	ctx := test.Context()
+	horizon := test.OpenDatabase(test.DatabaseUrl())
+	defer horizon.Close()
+
+	count := func(table string) int {
+		var n int
+		err := horizon.Get(&n, "SELECT COUNT(*) FROM "+table)
+		So(err, ShouldBeNil)
+		return n
+	}
+
+	Convey("System.Tick", t, func() {
+		test.LoadScenario("base")
+		horizon.MustExec("UPDATE history_ledgers SET closed_at = NOW() - INTERVAL '1 hour' * (4 - sequence)")
+
+		sys := &System{
+			HorizonDB:       horizon,
+			RetentionWindow: 90 * time.Minute,
+		}
+
+		Convey("reaps the ledgers older than the retention window", func() {
+			n, err := sys.Tick(ctx)
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, 2)
+			So(count("history_ledgers"), ShouldEqual, 1)
+			So(count("history_transactions"), ShouldEqual, 1)
+			So(count("history_operations"), ShouldEqual, 1)
+			So(count("history_effects"), ShouldEqual, 2)
+
+			var elder int32
+			err = horizon.Get(&elder, "SELECT MIN(sequence) FROM history_ledgers")
+			So(err, ShouldBeNil)
+			So(elder, ShouldEqual, 3)
+
+			Convey("but never the latest ledger", func() {
+				sys.RetentionWindow = time.Minute
+				n, err := sys.Tick(ctx)
+				So(err, ShouldBeNil)
+				So(n, ShouldEqual, 0)
+				So(count("history_ledgers"), ShouldEqual, 1)
+			})
+		})
+
+		Convey("reaps in batches, oldest first", func() {
+			sys.BatchLedgers = 1
+
+			n, err := sys.Tick(ctx)
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, 1)
+			So(count("history_ledgers"), ShouldEqual, 2)
+		})
+
+		Convey("waits for horizon to be idle", func() {
+			sys.Idle = func() bool { return false }
+
+			n, err := sys.Tick(ctx)
+			So(err, ShouldBeNil)
+			So(n, ShouldEqual, 0)
+			So(count("history_ledgers"), ShouldEqual, 3)
+		})
+	})
+}
@@ -0,0 +1,169 @@
+package horizon
+
+import (
+	stderr "errors"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"syscall"
+
+	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/log"
+	"github.com/stellar/horizon/ratelimit"
+)
+
+// ErrNoConfigSource is returned by App.ReloadConfig when the app was given no
+// source to reload its config from.
+//
+// NOTE: this is not a go-errors based error
+var ErrNoConfigSource = stderr.New("no config source to reload from")
+
+// reloadableConfigFields are the fields of Config that a running app applies
+// when it reloads its config.  Changes to the others, such as the databases
+// horizon connects to, only take effect once it restarts.
+var reloadableConfigFields = map[string]bool{
+	"LogLevel":              true,
+	"RateLimit":             true,
+	"RateLimitBurst":        true,
+	"TxSubRateLimit":        true,
+	"TxSubAccountRateLimit": true,
+	"SSEHeartbeat":          true,
+	"RequestTimeout":        true,
+	"RouteTimeouts":         true,
+	"SlowQueryThreshold":    true,
+}
+
+// SetConfigSource sets the source the config of the app is reloaded from,
+// such as the config file horizon was started with.
+func (a *App) SetConfigSource(load func() (Config, error)) {
+	a.reloadLock.Lock()
+	a.configSource = load
+	a.reloadLock.Unlock()
+}
+
+// ReloadConfig loads the config of the app from its source and applies the
+// settings that are safe to change while horizon runs: its log level, rate
+// limits, request timeouts, the slow query threshold and the heartbeat of
+// streams, those already open keeping the one they were opened with.  Other
+// changes are logged as needing a restart, leaving open streams undisturbed.
+// The apps of the networks served under the paths of the app apply the same
+// settings.
+func (a *App) ReloadConfig() error {
+	a.reloadLock.Lock()
+	defer a.reloadLock.Unlock()
+
+	if a.configSource == nil {
+		return ErrNoConfigSource
+	}
+
+	c, err := a.configSource()
+	if err != nil {
+		return err
+	}
+
+	a.applyConfig(c)
+	for _, network := range a.networks {
+		for _, nc := range c.Networks {
+			if nc.Name == network.network {
+				network.applyConfig(networkAppConfig(c, nc))
+			}
+		}
+	}
+	return nil
+}
+
+// currentConfig returns the config of the app, as last reloaded.  Reloadable
+// settings must be read through it.
+func (a *App) currentConfig() Config {
+	a.configLock.RLock()
+	defer a.configLock.RUnlock()
+	return a.config
+}
+
+// applyConfig applies the reloadable settings of c that differ from those of
+// the app.  Only those fields of a.config are written, as the others are read
+// without taking configLock.
+func (a *App) applyConfig(c Config) {
+	var applied, ignored []string
+
+	a.configLock.Lock()
+	v, next := reflect.ValueOf(&a.config).Elem(), reflect.ValueOf(c)
+	for i := 0; i < v.NumField(); i++ {
+		name := v.Type().Field(i).Name
+		if reflect.DeepEqual(v.Field(i).Interface(), next.Field(i).Interface()) {
+			continue
+		}
+
+		if !a.reloadable(name, c) {
+			ignored = append(ignored, name)
+			continue
+		}
+
+		v.Field(i).Set(next.Field(i))
+		applied = append(applied, name)
+	}
+	current := a.config
+	a.configLock.Unlock()
+
+	a.log.Logger.Level = current.LogLevel
+	log.SetDefaultLoggerLevel(current.LogLevel)
+
+	if a.parent == nil {
+		a.slowQueries.Threshold = current.SlowQueryThreshold
+		db.SetSlowQueryLog(a.slowQueries)
+	}
+
+	if a.web.rateLimiter != nil && current.RateLimit != nil {
+		a.web.rateLimiter.SetBucket(ratelimit.FromQuota(current.RateLimit, current.RateLimitBurst))
+	}
+	if a.web.txsubIPLimiter != nil {
+		a.web.txsubIPLimiter.SetBucket(ratelimit.FromQuota(current.TxSubRateLimit, 0))
+	}
+	if a.web.txsubAccountLimiter != nil {
+		a.web.txsubAccountLimiter.SetBucket(ratelimit.FromQuota(current.TxSubAccountRateLimit, 0))
+	}
+
+	l := log.WithField(a.ctx, "applied", strings.Join(applied, ","))
+	if len(ignored) > 0 {
+		l = l.WithField("restart_required", strings.Join(ignored, ","))
+	}
+	l.Info("reloaded config")
+}
+
+// reloadable returns true if the field name of the config of the app may be
+// set to its value in c while the app runs.  The limits of submissions may
+// be changed but not enabled or disabled, which would add or remove their
+// throttlers from the router.
+func (a *App) reloadable(name string, c Config) bool {
+	switch name {
+	case "RateLimit":
+		return c.RateLimit != nil
+	case "TxSubRateLimit":
+		return a.web.txsubIPLimiter != nil && c.TxSubRateLimit != nil
+	case "TxSubAccountRateLimit":
+		return a.web.txsubAccountLimiter != nil && c.TxSubAccountRateLimit != nil
+	default:
+		return reloadableConfigFields[name]
+	}
+}
+
+// reloadOnSignal reloads the config of the app each time the process is sent
+// SIGHUP, until the app is cancelled.
+func (a *App) reloadOnSignal() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	for {
+		select {
+		case <-signals:
+			err := a.ReloadConfig()
+			if err != nil {
+				log.Errorf(a.ctx, "failed to reload config: %s", err)
+			}
+		case <-a.ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package horizon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/throttled"
+	"github.com/Sirupsen/logrus"
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/ratelimit"
+)
+
+func TestReloadConfig(t *testing.T) {
+	Convey("App.ReloadConfig", t, func() {
+		app := NewTestApp()
+		defer app.Close()
+
+		So(app.ReloadConfig(), ShouldEqual, ErrNoConfigSource)
+
+		reloaded := NewTestConfig()
+		reloaded.LogLevel = logrus.WarnLevel
+		reloaded.RateLimit = throttled.PerHour(10)
+		reloaded.RateLimitBurst = 2
+		reloaded.SSEHeartbeat = 5 * time.Second
+		reloaded.TxSubRateLimit = throttled.PerHour(1)
+		reloaded.DatabaseUrl = "postgres://localhost/elsewhere"
+		app.SetConfigSource(func() (Config, error) { return reloaded, nil })
+
+		So(app.ReloadConfig(), ShouldBeNil)
+
+		Convey("applies the settings safe to change", func() {
+			c := app.currentConfig()
+			So(c.LogLevel, ShouldEqual, logrus.WarnLevel)
+			So(app.log.Logger.Level, ShouldEqual, logrus.WarnLevel)
+			So(c.SSEHeartbeat, ShouldEqual, 5*time.Second)
+			So(app.web.rateLimiter.Bucket(), ShouldResemble, ratelimit.FromQuota(throttled.PerHour(10), 2))
+		})
+
+		Convey("leaves the others until a restart", func() {
+			c := app.currentConfig()
+			So(c.DatabaseUrl, ShouldEqual, NewTestConfig().DatabaseUrl)
+
+			// the test app limits no submissions, so that none may be limited
+			// until it restarts
+			So(c.TxSubRateLimit, ShouldBeNil)
+		})
+	})
+}
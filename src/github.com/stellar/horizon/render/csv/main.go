@@ -0,0 +1,95 @@
+// Package csv renders collections of resources as comma separated values, for
+// clients loading horizon's data straight into spreadsheets.
+package csv
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-errors/errors"
+)
+
+// contentType is that of csv responses.  The header parameter tells clients
+// that the first record names the columns.
+const contentType = "text/csv; charset=utf-8; header=present"
+
+// Render writes records to w as RFC 4180 csv: a header record naming columns,
+// followed by a record for each of records holding the corresponding fields of
+// its json form.  Fields absent from a record are left empty, and fields whose
+// values are objects or arrays are written as json.
+func Render(w http.ResponseWriter, columns []string, records []interface{}) error {
+	var buf bytes.Buffer
+	out := csv.NewWriter(&buf)
+	out.UseCRLF = true
+
+	if err := out.Write(columns); err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	for _, record := range records {
+		row, err := Row(record, columns)
+		if err != nil {
+			return err
+		}
+
+		if err := out.Write(row); err != nil {
+			return errors.Wrap(err, 1)
+		}
+	}
+
+	out.Flush()
+	if err := out.Error(); err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// Row returns the values of the named fields of record's json form.
+func Row(record interface{}, columns []string) ([]string, error) {
+	js, err := json.Marshal(record)
+	if err != nil {
+		return nil, errors.Wrap(err, 1)
+	}
+
+	var fields map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(js))
+	dec.UseNumber()
+	if err := dec.Decode(&fields); err != nil {
+		return nil, errors.Wrap(err, 1)
+	}
+
+	row := make([]string, len(columns))
+	for i, column := range columns {
+		row[i], err = value(fields[column])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return row, nil
+}
+
+func value(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return fmt.Sprint(v), nil
+	default:
+		js, err := json.Marshal(v)
+		if err != nil {
+			return "", errors.Wrap(err, 1)
+		}
+		return string(js), nil
+	}
+}
@@ -0,0 +1,41 @@
+package csv
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCsvPackage(t *testing.T) {
+	type asset struct {
+		Code string `json:"code"`
+	}
+
+	type record struct {
+		ID     int64       `json:"id"`
+		Memo   string      `json:"memo"`
+		Asset  asset       `json:"asset"`
+		Issuer interface{} `json:"issuer,omitempty"`
+		Native bool        `json:"native"`
+	}
+
+	Convey("csv.Render", t, func() {
+		w := httptest.NewRecorder()
+		columns := []string{"id", "memo", "asset", "issuer", "native"}
+
+		err := Render(w, columns, []interface{}{
+			record{ID: 12884905985, Memo: "plain", Native: true},
+			record{ID: 2, Memo: "with, \"quotes\"\nand lines", Asset: asset{"USD"}, Issuer: "GABC"},
+			map[string]interface{}{"id": 3},
+		})
+		So(err, ShouldBeNil)
+
+		So(w.Header().Get("Content-Type"), ShouldEqual, "text/csv; charset=utf-8; header=present")
+		So(w.Body.String(), ShouldEqual, ""+
+			"id,memo,asset,issuer,native\r\n"+
+			"12884905985,plain,\"{\"\"code\"\":\"\"\"\"}\",,true\r\n"+
+			"2,\"with, \"\"quotes\"\"\r\nand lines\",\"{\"\"code\"\":\"\"USD\"\"}\",GABC,false\r\n"+
+			"3,,,,\r\n")
+	})
+}
@@ -0,0 +1,115 @@
+package hal
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// CursorEncoder encodes the paging tokens of records as the cursors handed to
+// clients for the endpoint at path, such as a *db.CursorCodec does.
+type CursorEncoder interface {
+	Encode(path, token string) string
+}
+
+// WithCursors returns a writer through which Render encodes the paging tokens
+// of the documents it renders with enc: their paging_token fields, issued for
+// the endpoint at path, and the cursor params of their links, issued for the
+// endpoints they link to.
+//
+// If enc is nil, w is returned unchanged.
+func WithCursors(w http.ResponseWriter, enc CursorEncoder, path string) http.ResponseWriter {
+	if enc == nil {
+		return w
+	}
+
+	return &cursorWriter{ResponseWriter: w, enc: enc, path: path}
+}
+
+type cursorWriter struct {
+	http.ResponseWriter
+	enc  CursorEncoder
+	path string
+}
+
+// EncodeCursors returns data, as the generic value it marshals to, with the
+// paging tokens of it, and of the documents embedded in it, encoded by enc as
+// WithCursors describes.
+func EncodeCursors(data interface{}, enc CursorEncoder, path string) (interface{}, error) {
+	doc, err := generic(data)
+	if err != nil {
+		return nil, err
+	}
+
+	encodeCursors(doc, enc, path)
+	return doc, nil
+}
+
+// encodeCursors encodes the paging tokens of the generic json value doc in
+// place
+func encodeCursors(doc interface{}, enc CursorEncoder, path string) {
+	switch doc := doc.(type) {
+	case map[string]interface{}:
+		for key, value := range doc {
+			switch key {
+			case "paging_token":
+				if token, ok := value.(string); ok {
+					doc[key] = enc.Encode(path, token)
+				}
+			case "_links":
+				encodeHrefCursors(value, enc)
+			default:
+				encodeCursors(value, enc, path)
+			}
+		}
+	case []interface{}:
+		for _, value := range doc {
+			encodeCursors(value, enc, path)
+		}
+	}
+}
+
+// encodeHrefCursors encodes the cursor params of the hrefs of the links of
+// links, a "_links" object
+func encodeHrefCursors(links interface{}, enc CursorEncoder) {
+	switch links := links.(type) {
+	case map[string]interface{}:
+		if href, ok := links["href"].(string); ok {
+			links["href"] = encodeHrefCursor(href, enc)
+			return
+		}
+
+		for _, link := range links {
+			encodeHrefCursors(link, enc)
+		}
+	case []interface{}:
+		for _, link := range links {
+			encodeHrefCursors(link, enc)
+		}
+	}
+}
+
+// encodeHrefCursor returns href with its cursor param, if any, encoded for the
+// path it links to.  Templated links, and those of other hosts, are left
+// unchanged.
+func encodeHrefCursor(href string, enc CursorEncoder) string {
+	i := strings.Index(href, "?")
+	if i < 0 || !strings.HasPrefix(href, "/") || strings.HasPrefix(href, "//") || strings.Contains(href, "{") {
+		return href
+	}
+
+	path, params := href[:i], strings.Split(href[i+1:], "&")
+	for j, param := range params {
+		if !strings.HasPrefix(param, "cursor=") {
+			continue
+		}
+
+		token, err := url.QueryUnescape(strings.TrimPrefix(param, "cursor="))
+		if err != nil || token == "" {
+			continue
+		}
+		params[j] = "cursor=" + url.QueryEscape(enc.Encode(path, token))
+	}
+
+	return path + "?" + strings.Join(params, "&")
+}
@@ -0,0 +1,172 @@
+package hal
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/go-errors/errors"
+)
+
+// WithFields returns a writer through which Render renders only the fields
+// named in fields, a comma separated list such as "balances,sequence".  Nested
+// fields are named by their path, e.g. "thresholds.low_threshold".  The fields
+// of a Page apply to each of its records.  Links are always rendered, and
+// names that match no field are ignored.
+//
+// If fields is empty, w is returned unchanged.
+func WithFields(w http.ResponseWriter, fields string) http.ResponseWriter {
+	tree := parseFields(fields)
+	if len(tree) == 0 {
+		return w
+	}
+
+	return &fieldsWriter{ResponseWriter: w, fields: tree}
+}
+
+type fieldsWriter struct {
+	http.ResponseWriter
+	fields fieldTree
+}
+
+// fieldTree maps the names of the fields to select at one level of a document
+// to the subfields to select below them.  A nil subtree selects the field
+// whole.
+type fieldTree map[string]fieldTree
+
+func parseFields(fields string) fieldTree {
+	var tree fieldTree
+
+	for _, path := range strings.Split(fields, ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+
+		if tree == nil {
+			tree = fieldTree{}
+		}
+		tree.add(strings.Split(path, "."))
+	}
+
+	return tree
+}
+
+func (t fieldTree) add(path []string) {
+	name := path[0]
+	sub, seen := t[name]
+
+	switch {
+	case len(path) == 1:
+		t[name] = nil
+	case seen && sub == nil:
+		// the whole field is already selected
+	default:
+		if sub == nil {
+			sub = fieldTree{}
+			t[name] = sub
+		}
+		sub.add(path[1:])
+	}
+}
+
+// selectFields returns the json form of data, less the fields not selected.
+func selectFields(data interface{}, fields fieldTree) (json.RawMessage, error) {
+	js, err := json.Marshal(data)
+	if err != nil {
+		return nil, errors.Wrap(err, 1)
+	}
+
+	return project(js, fields)
+}
+
+func project(js json.RawMessage, fields fieldTree) (json.RawMessage, error) {
+	switch firstByte(js) {
+	case '[':
+		var elems []json.RawMessage
+		if err := json.Unmarshal(js, &elems); err != nil {
+			return nil, errors.Wrap(err, 1)
+		}
+
+		var buf bytes.Buffer
+		buf.WriteByte('[')
+		for i, elem := range elems {
+			projected, err := project(elem, fields)
+			if err != nil {
+				return nil, err
+			}
+
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.Write(projected)
+		}
+		buf.WriteByte(']')
+		return buf.Bytes(), nil
+	case '{':
+		return projectObject(js, fields)
+	default:
+		return js, nil
+	}
+}
+
+// projectObject projects the members of a json object, preserving their
+// order.
+func projectObject(js json.RawMessage, fields fieldTree) (json.RawMessage, error) {
+	dec := json.NewDecoder(bytes.NewReader(js))
+
+	// the opening brace
+	if _, err := dec.Token(); err != nil {
+		return nil, errors.Wrap(err, 1)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return nil, errors.Wrap(err, 1)
+		}
+
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, errors.Wrap(err, 1)
+		}
+
+		key, _ := token.(string)
+		sub, ok := fields[key]
+		if !ok && key != "_links" {
+			continue
+		}
+
+		if sub != nil {
+			value, err = project(value, sub)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+
+		name, _ := json.Marshal(key)
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func firstByte(js []byte) byte {
+	js = bytes.TrimLeft(js, " \t\r\n")
+	if len(js) == 0 {
+		return 0
+	}
+
+	return js[0]
+}
@@ -0,0 +1,59 @@
+package hal
+
+import (
+	"bytes"
+	"net/http"
+	"regexp"
+)
+
+// callbackPattern matches the javascript function names accepted as JSONP
+// callbacks: identifiers, optionally qualified by the objects they belong to,
+// e.g. "jQuery123.handle".
+var callbackPattern = regexp.MustCompile(`^[A-Za-z_$][\w$]*(\.[A-Za-z_$][\w$]*)*$`)
+
+// ValidCallback returns true if name may be used as a JSONP callback.
+func ValidCallback(name string) bool {
+	return len(name) <= 128 && callbackPattern.MatchString(name)
+}
+
+// WithFormat returns a writer through which Render indents the json it renders
+// when pretty is true and, when callback is not empty, renders it as a JSONP
+// script that calls the named javascript function with the document.  Callers
+// are responsible for checking the callback with ValidCallback.
+//
+// If neither option is set, w is returned unchanged.
+func WithFormat(w http.ResponseWriter, pretty bool, callback string) http.ResponseWriter {
+	if !pretty && callback == "" {
+		return w
+	}
+
+	return &formatWriter{ResponseWriter: w, pretty: pretty, callback: callback}
+}
+
+type formatWriter struct {
+	http.ResponseWriter
+	pretty   bool
+	callback string
+}
+
+// write writes js to the underlying writer, wrapped in the callback if any.
+func (w *formatWriter) write(js []byte) {
+	if w.callback == "" {
+		w.Header().Set("Content-Type", "application/hal+json")
+		w.ResponseWriter.Write(js)
+		return
+	}
+
+	// the leading comment keeps the response from being read as anything other
+	// than a script, should a browser sniff it.
+	var buf bytes.Buffer
+	buf.WriteString("/**/")
+	buf.WriteString(w.callback)
+	buf.WriteString("(")
+	buf.Write(js)
+	buf.WriteString(");")
+
+	w.Header().Set("Content-Type", "application/javascript; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.ResponseWriter.Write(buf.Bytes())
+}
@@ -0,0 +1,98 @@
+package hal
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// WithLinkPrefix returns a writer through which Render prefixes the links of
+// the documents it renders with prefix, such as "/testnet" for the documents
+// of a network served under it.  Links other than paths, such as templated or
+// absolute urls of other hosts, are left unchanged.
+//
+// If prefix is empty, w is returned unchanged.
+func WithLinkPrefix(w http.ResponseWriter, prefix string) http.ResponseWriter {
+	if prefix == "" {
+		return w
+	}
+
+	return &linkWriter{ResponseWriter: w, prefix: prefix}
+}
+
+type linkWriter struct {
+	http.ResponseWriter
+	prefix string
+}
+
+// PrefixLinks returns data, as the generic value it marshals to, with the href
+// of each of its links, and those of the documents embedded in it, prefixed
+// with prefix when it is a path.
+func PrefixLinks(data interface{}, prefix string) (interface{}, error) {
+	doc, err := generic(data)
+	if err != nil {
+		return nil, err
+	}
+
+	prefixLinks(doc, prefix)
+	return doc, nil
+}
+
+// generic returns data as the generic json value it marshals to
+func generic(data interface{}) (interface{}, error) {
+	js, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// numbers are kept as they were written, lest large ids lose precision
+	var doc interface{}
+	dec := json.NewDecoder(bytes.NewReader(js))
+	dec.UseNumber()
+	err = dec.Decode(&doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// prefixLinks prefixes the links of the generic json value doc in place
+func prefixLinks(doc interface{}, prefix string) {
+	switch doc := doc.(type) {
+	case map[string]interface{}:
+		for key, value := range doc {
+			if key == "_links" {
+				prefixHrefs(value, prefix)
+				continue
+			}
+			prefixLinks(value, prefix)
+		}
+	case []interface{}:
+		for _, value := range doc {
+			prefixLinks(value, prefix)
+		}
+	}
+}
+
+// prefixHrefs prefixes the hrefs of the links of links, a "_links" object
+func prefixHrefs(links interface{}, prefix string) {
+	switch links := links.(type) {
+	case map[string]interface{}:
+		if href, ok := links["href"].(string); ok {
+			if strings.HasPrefix(href, "/") && !strings.HasPrefix(href, "//") {
+				links["href"] = prefix + href
+			}
+			return
+		}
+
+		for _, link := range links {
+			prefixHrefs(link, prefix)
+		}
+	case []interface{}:
+		for _, link := range links {
+			prefixHrefs(link, prefix)
+		}
+	}
+}
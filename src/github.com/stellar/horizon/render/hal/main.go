@@ -11,6 +11,13 @@ import (
 // URIs simpler.
 var StandardPagingOptions = "{?cursor,limit,order}"
 
+// Encoder is implemented by writers that encode the documents rendered to them
+// in a format other than HAL.  Render passes them the document it would have
+// marshalled, after any field selection.
+type Encoder interface {
+	EncodeHAL(doc interface{})
+}
+
 type Page struct {
 	halgo.Links
 	Records []interface{}
@@ -25,24 +32,104 @@ func RenderToString(data interface{}, pretty bool) ([]byte, error) {
 	return json.Marshal(data)
 }
 
-// Render write data to w, after marshalling to json
+// Render write data to w, after marshalling to json.  When w was returned by
+// WithFields, only the selected fields of data are rendered, when it was
+// returned by WithCursors, the paging tokens of data are encoded, when it was
+// returned by WithLinkPrefix, the links of data are prefixed, when it was
+// returned by WithDecodedXDR, the xdr of data is decoded alongside it, and
+// when w is an Encoder, it encodes data in place of Render.  The json is
+// compact unless w, or the writer given to WithFields, was returned by
+// WithFormat.
 func Render(w http.ResponseWriter, data interface{}) {
+	fw, selecting := w.(*fieldsWriter)
+	if selecting {
+		w = fw.ResponseWriter
+	}
+
+	cw, encoding := w.(*cursorWriter)
+	if encoding {
+		w = cw.ResponseWriter
+	}
+
+	lw, prefixing := w.(*linkWriter)
+	if prefixing {
+		w = lw.ResponseWriter
+	}
+
+	xw, decoding := w.(*xdrWriter)
+	if decoding {
+		w = xw.ResponseWriter
+	}
+
+	format, formatting := w.(*formatWriter)
+	if !formatting {
+		format = &formatWriter{ResponseWriter: w}
+	}
+
 	if page, ok := data.(Page); ok {
+		var records interface{} = page.Records
+		if selecting {
+			projected, err := selectFields(page.Records, fw.fields)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			records = projected
+		}
+
 		data = map[string]interface{}{
 			"_links": page.Items,
 			"_embedded": map[string]interface{}{
-				"records": page.Records,
+				"records": records,
 			},
 		}
+	} else if selecting {
+		projected, err := selectFields(data, fw.fields)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		data = projected
+	}
+
+	if encoding {
+		encoded, err := EncodeCursors(data, cw.enc, cw.path)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		data = encoded
+	}
+
+	if prefixing {
+		prefixed, err := PrefixLinks(data, lw.prefix)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		data = prefixed
+	}
+
+	if decoding {
+		decoded, err := DecodeXDR(data)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		data = decoded
+	}
+
+	if enc, ok := w.(Encoder); ok {
+		enc.EncodeHAL(data)
+		return
 	}
 
-	js, err := RenderToString(data, true)
+	js, err := RenderToString(data, format.pretty)
 
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/hal+json")
-	w.Write(js)
+	format.write(js)
 }
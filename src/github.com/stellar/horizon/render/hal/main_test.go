@@ -0,0 +1,216 @@
+package hal
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jagregory/halgo"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type testThresholds struct {
+	Low  int `json:"low"`
+	High int `json:"high"`
+}
+
+type testResource struct {
+	halgo.Links
+	ID         string         `json:"id"`
+	Sequence   int64          `json:"sequence"`
+	Thresholds testThresholds `json:"thresholds"`
+	Balances   []string       `json:"balances"`
+}
+
+// testCursors encodes paging tokens as the path they were issued for and the
+// token, separated by a colon.
+type testCursors struct{}
+
+func (testCursors) Encode(path, token string) string {
+	if token == "" {
+		return ""
+	}
+	return path + ":" + token
+}
+
+func TestHal(t *testing.T) {
+	resource := testResource{
+		Links:      halgo.Links{}.Self("/resources/1"),
+		ID:         "1",
+		Sequence:   2,
+		Thresholds: testThresholds{Low: 1, High: 3},
+		Balances:   []string{"10.0"},
+	}
+
+	render := func(fields string, data interface{}) string {
+		w := httptest.NewRecorder()
+		Render(WithFields(w, fields), data)
+		So(w.Header().Get("Content-Type"), ShouldEqual, "application/hal+json")
+
+		var compact map[string]interface{}
+		So(json.Unmarshal(w.Body.Bytes(), &compact), ShouldBeNil)
+		js, _ := json.Marshal(compact)
+		return string(js)
+	}
+
+	Convey("hal.Render", t, func() {
+		Convey("renders every field without a selection", func() {
+			So(WithFields(httptest.NewRecorder(), " , "), ShouldHaveSameTypeAs, httptest.NewRecorder())
+			So(render("", resource), ShouldEqual, `{"_links":{"self":{"href":"/resources/1"}},"balances":["10.0"],"id":"1","sequence":2,"thresholds":{"high":3,"low":1}}`)
+		})
+
+		Convey("renders only the selected fields, and links", func() {
+			So(render("balances,sequence,unknown", resource), ShouldEqual, `{"_links":{"self":{"href":"/resources/1"}},"balances":["10.0"],"sequence":2}`)
+		})
+
+		Convey("selects nested fields by path", func() {
+			So(render("thresholds.low", resource), ShouldEqual, `{"_links":{"self":{"href":"/resources/1"}},"thresholds":{"low":1}}`)
+			So(render("thresholds,thresholds.low", resource), ShouldEqual, `{"_links":{"self":{"href":"/resources/1"}},"thresholds":{"high":3,"low":1}}`)
+		})
+
+		Convey("selects the fields of each record of a page", func() {
+			page := Page{
+				Links:   halgo.Links{}.Self("/resources"),
+				Records: []interface{}{resource, resource},
+			}
+
+			So(render("id", page), ShouldEqual, `{"_embedded":{"records":[{"_links":{"self":{"href":"/resources/1"}},"id":"1"},{"_links":{"self":{"href":"/resources/1"}},"id":"1"}]},"_links":{"self":{"href":"/resources"}}}`)
+		})
+
+		Convey("preserves the order of fields", func() {
+			w := httptest.NewRecorder()
+			Render(WithFields(w, "thresholds,id"), resource)
+
+			So(w.Body.String(), ShouldStartWith, `{"_links"`)
+			So(w.Body.String(), ShouldContainSubstring, `"id":"1","thresholds"`)
+		})
+
+		Convey("indents the json when pretty", func() {
+			w := httptest.NewRecorder()
+			Render(WithFields(WithFormat(w, true, ""), "id"), resource)
+
+			So(w.Header().Get("Content-Type"), ShouldEqual, "application/hal+json")
+			So(w.Body.String(), ShouldStartWith, "{\n  \"_links\"")
+			So(w.Body.String(), ShouldContainSubstring, "\"id\": \"1\"")
+		})
+
+		Convey("wraps the json in the callback as jsonp", func() {
+			w := httptest.NewRecorder()
+			Render(WithFormat(w, false, "cb.done"), Page{Links: halgo.Links{}.Self("/resources")})
+
+			So(w.Header().Get("Content-Type"), ShouldEqual, "application/javascript; charset=utf-8")
+			So(w.Header().Get("X-Content-Type-Options"), ShouldEqual, "nosniff")
+			So(w.Body.String(), ShouldStartWith, "/**/cb.done({")
+			So(w.Body.String(), ShouldEndWith, "});")
+		})
+	})
+
+	Convey("hal.RenderStatus", t, func() {
+		w := httptest.NewRecorder()
+		RenderStatus(WithFields(WithFormat(w, true, ""), "id"), http.StatusCreated, resource)
+
+		So(w.Code, ShouldEqual, http.StatusCreated)
+		So(w.Header().Get("Content-Type"), ShouldEqual, "application/hal+json")
+		So(w.Body.String(), ShouldContainSubstring, "\"id\": \"1\"")
+		So(w.Body.String(), ShouldNotContainSubstring, "sequence")
+	})
+
+	Convey("hal.WithLinkPrefix", t, func() {
+		page := Page{
+			Links: halgo.Links{}.
+				Self("/resources?cursor=").
+				Link("next", "/resources?cursor=1").
+				Link("docs", "https://www.stellar.org/developers"),
+			Records: []interface{}{resource},
+		}
+
+		Convey("prefixes the links of documents, and those they embed", func() {
+			w := httptest.NewRecorder()
+			Render(WithFields(WithLinkPrefix(WithFormat(w, false, ""), "/testnet"), "id"), page)
+
+			So(w.Body.String(), ShouldContainSubstring, `"self":{"href":"/testnet/resources?cursor="}`)
+			So(w.Body.String(), ShouldContainSubstring, `"next":{"href":"/testnet/resources?cursor=1"}`)
+			So(w.Body.String(), ShouldContainSubstring, `"docs":{"href":"https://www.stellar.org/developers"}`)
+			So(w.Body.String(), ShouldContainSubstring, `"self":{"href":"/testnet/resources/1"}`)
+			So(w.Body.String(), ShouldNotContainSubstring, "sequence")
+		})
+
+		Convey("keeps large numbers as they were", func() {
+			big := resource
+			big.Sequence = 1<<62 + 1
+
+			data, err := PrefixLinks(big, "/testnet")
+			So(err, ShouldBeNil)
+			js, _ := json.Marshal(data)
+			So(string(js), ShouldContainSubstring, `"sequence":4611686018427387905`)
+		})
+
+		Convey("leaves the writer alone without a prefix", func() {
+			w := httptest.NewRecorder()
+			So(WithLinkPrefix(w, ""), ShouldEqual, w)
+		})
+	})
+
+	Convey("hal.WithCursors", t, func() {
+		page := Page{
+			Links: halgo.Links{}.
+				Self("/resources?cursor=").
+				Link("next", "/resources?cursor=1").
+				Link("other", "/others?cursor=1"),
+			Records: []interface{}{map[string]interface{}{"paging_token": "1"}},
+		}
+
+		Convey("encodes the paging tokens of documents, and those of their links", func() {
+			w := httptest.NewRecorder()
+			Render(WithCursors(WithLinkPrefix(WithFormat(w, false, ""), "/testnet"), testCursors{}, "/resources"), page)
+
+			So(w.Body.String(), ShouldContainSubstring, `"self":{"href":"/testnet/resources?cursor="}`)
+			So(w.Body.String(), ShouldContainSubstring, `"next":{"href":"/testnet/resources?cursor=%2Fresources%3A1"}`)
+			So(w.Body.String(), ShouldContainSubstring, `"other":{"href":"/testnet/others?cursor=%2Fothers%3A1"}`)
+			So(w.Body.String(), ShouldContainSubstring, `"paging_token":"/resources:1"`)
+		})
+
+		Convey("keeps the other params of links", func() {
+			data, err := EncodeCursors(halgo.Links{}.Link("next", "/resources?order=asc&cursor=1&limit=10"), testCursors{}, "/resources")
+			So(err, ShouldBeNil)
+
+			links := data.(map[string]interface{})["_links"].(map[string]interface{})
+			next := links["next"].(map[string]interface{})
+			So(next["href"], ShouldEqual, "/resources?order=asc&cursor=%2Fresources%3A1&limit=10")
+		})
+
+		Convey("leaves the writer alone without an encoder", func() {
+			w := httptest.NewRecorder()
+			So(WithCursors(w, nil, "/resources"), ShouldEqual, w)
+		})
+	})
+
+	Convey("hal.WithDecodedXDR", t, func() {
+		env := "AAAAAGL8HQvQkbK2HA3WVjRrKmjX00fG8sLI7m0ERwJW/AX3AAAACgAAAAAAAAABAAAAAAAAAAAAAAABAAAAAAAAAAAAAAAArqN6LeOagjxMaUP96Bzfs9e0corNZXzBWJkFoK7kvkwAAAAAO5rKAAAAAAAAAAABVvwF9wAAAEAKZ7IPj/46PuWU6ZOtyMosctNAkXRNX9WCAI5RnfRk+AyxDLoDZP/9l3NvsxQtWj9juQOuoBlFLnWu8intgxQA"
+		doc := map[string]interface{}{"id": "1", "envelope_xdr": env}
+
+		Convey("renders the xdr of documents decoded alongside it", func() {
+			w := httptest.NewRecorder()
+			Render(WithFields(WithDecodedXDR(w, true), "id,envelope_xdr"), doc)
+
+			So(w.Body.String(), ShouldContainSubstring, `"envelope_xdr":"`+env+`"`)
+			So(w.Body.String(), ShouldContainSubstring, `"decoded_xdr":{"envelope_xdr":{`)
+			So(w.Body.String(), ShouldContainSubstring, `"type":"OperationTypeCreateAccount"`)
+		})
+
+		Convey("leaves the writer alone unless decoding", func() {
+			w := httptest.NewRecorder()
+			So(WithDecodedXDR(w, false), ShouldEqual, w)
+		})
+	})
+
+	Convey("hal.ValidCallback", t, func() {
+		So(ValidCallback("cb"), ShouldBeTrue)
+		So(ValidCallback("jQuery_1.$handle"), ShouldBeTrue)
+		So(ValidCallback(""), ShouldBeFalse)
+		So(ValidCallback("alert(1)"), ShouldBeFalse)
+		So(ValidCallback("a..b"), ShouldBeFalse)
+		So(ValidCallback("1cb"), ShouldBeFalse)
+	})
+}
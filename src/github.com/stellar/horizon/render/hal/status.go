@@ -0,0 +1,55 @@
+package hal
+
+import (
+	"net/http"
+)
+
+// RenderStatus is Render, but responds with status rather than 200 OK.
+func RenderStatus(w http.ResponseWriter, status int, data interface{}) {
+	Render(withStatus(w, status), data)
+}
+
+// withStatus returns w, with the writer beneath any fields, link prefix, xdr or
+// format options wrapped so that status is written once Render has set its
+// headers.
+func withStatus(w http.ResponseWriter, status int) http.ResponseWriter {
+	switch w := w.(type) {
+	case *fieldsWriter:
+		wrapped := *w
+		wrapped.ResponseWriter = withStatus(w.ResponseWriter, status)
+		return &wrapped
+	case *linkWriter:
+		wrapped := *w
+		wrapped.ResponseWriter = withStatus(w.ResponseWriter, status)
+		return &wrapped
+	case *xdrWriter:
+		wrapped := *w
+		wrapped.ResponseWriter = withStatus(w.ResponseWriter, status)
+		return &wrapped
+	case *formatWriter:
+		wrapped := *w
+		wrapped.ResponseWriter = withStatus(w.ResponseWriter, status)
+		return &wrapped
+	default:
+		return &statusWriter{ResponseWriter: w, status: status}
+	}
+}
+
+type statusWriter struct {
+	http.ResponseWriter
+	status  int
+	written bool
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.written = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if !w.written {
+		w.WriteHeader(w.status)
+	}
+
+	return w.ResponseWriter.Write(b)
+}
@@ -0,0 +1,36 @@
+package hal
+
+import (
+	"net/http"
+
+	"github.com/stellar/horizon/render/xdr"
+)
+
+// WithDecodedXDR returns a writer through which Render adds to the documents
+// it renders, and to those embedded in them, the decoding of the base64
+// encoded xdr they hold, as xdr.DecodeFields does.
+//
+// If decode is false, w is returned unchanged.
+func WithDecodedXDR(w http.ResponseWriter, decode bool) http.ResponseWriter {
+	if !decode {
+		return w
+	}
+
+	return &xdrWriter{ResponseWriter: w}
+}
+
+type xdrWriter struct {
+	http.ResponseWriter
+}
+
+// DecodeXDR returns data, as the generic value it marshals to, with the xdr of
+// its documents decoded as xdr.DecodeFields does.
+func DecodeXDR(data interface{}) (interface{}, error) {
+	doc, err := generic(data)
+	if err != nil {
+		return nil, err
+	}
+
+	xdr.DecodeFields(doc)
+	return doc, nil
+}
@@ -0,0 +1,203 @@
+// Package jsonapi renders horizon's resources as JSON:API documents
+// (http://jsonapi.org), for clients whose tooling is built around JSON:API
+// rather than HAL.  Documents are converted from the json form of the HAL
+// resources and pages that actions render, so that actions needn't know of
+// the format.
+package jsonapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/go-errors/errors"
+)
+
+// NewWriter returns a writer through which hal.Render renders JSON:API
+// documents to w in place of HAL.  The path of the request is used to type
+// resources that lack a self link, by the name of the collection it ends with.
+func NewWriter(w http.ResponseWriter, requestPath string) http.ResponseWriter {
+	return &writer{ResponseWriter: w, fallbackType: path.Base(requestPath)}
+}
+
+type writer struct {
+	http.ResponseWriter
+	fallbackType string
+}
+
+// EncodeHAL implements hal.Encoder, rendering the JSON:API form of doc.
+func (w *writer) EncodeHAL(doc interface{}) {
+	result, err := Convert(doc, w.fallbackType)
+	if err == nil {
+		var js []byte
+		js, err = json.MarshalIndent(result, "", "  ")
+		if err == nil {
+			w.Header().Set("Content-Type", "application/vnd.api+json")
+			w.Write(js)
+			return
+		}
+	}
+
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+// Document is a top-level JSON:API document.  Data is either a single
+// Resource or a slice of them, and Meta holds documents, such as metrics,
+// that are not resources.
+type Document struct {
+	Data  interface{}       `json:"data,omitempty"`
+	Links map[string]string `json:"links,omitempty"`
+	Meta  interface{}       `json:"meta,omitempty"`
+}
+
+// Resource is a JSON:API resource object.
+type Resource struct {
+	Type          string                  `json:"type"`
+	ID            string                  `json:"id"`
+	Attributes    map[string]interface{}  `json:"attributes,omitempty"`
+	Relationships map[string]Relationship `json:"relationships,omitempty"`
+	Links         map[string]string       `json:"links,omitempty"`
+}
+
+// Relationship is a JSON:API relationship, which horizon expresses solely by
+// the link to the related resource or collection.
+type Relationship struct {
+	Links map[string]string `json:"links"`
+}
+
+// Convert returns the JSON:API document for doc, the json form of a HAL
+// resource or page.  Resources are typed by the collection of their self
+// link, or else by fallbackType; HAL documents that have no id are not
+// resources, and are delivered as the document's meta.
+func Convert(doc interface{}, fallbackType string) (Document, error) {
+	js, err := json.Marshal(doc)
+	if err != nil {
+		return Document{}, errors.Wrap(err, 1)
+	}
+
+	var fields map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(js))
+	dec.UseNumber()
+	if err := dec.Decode(&fields); err != nil {
+		return Document{}, errors.Wrap(err, 1)
+	}
+
+	links := linksOf(fields)
+
+	if embedded, ok := fields["_embedded"].(map[string]interface{}); ok {
+		records, _ := embedded["records"].([]interface{})
+		data := make([]Resource, 0, len(records))
+
+		for _, record := range records {
+			fields, ok := record.(map[string]interface{})
+			if !ok {
+				return Document{}, errors.Errorf("record is not an object: %v", record)
+			}
+
+			r, _ := resource(fields, fallbackType)
+			data = append(data, r)
+		}
+
+		return Document{Data: data, Links: hrefs(links, "self", "next", "prev")}, nil
+	}
+
+	r, ok := resource(fields, fallbackType)
+	if !ok {
+		return Document{Meta: fields}, nil
+	}
+
+	return Document{Data: r, Links: hrefs(links, "self")}, nil
+}
+
+// resource converts the fields of a HAL resource, returning false if the
+// resource has no id.
+func resource(fields map[string]interface{}, fallbackType string) (Resource, bool) {
+	links := linksOf(fields)
+	r := Resource{
+		Type:  fallbackType,
+		Links: hrefs(links, "self"),
+	}
+
+	if self, ok := r.Links["self"]; ok {
+		r.Type = collection(self, fallbackType)
+	}
+
+	id, ok := fields["id"]
+	if !ok {
+		return r, false
+	}
+	r.ID = fmt.Sprint(id)
+
+	for name, value := range fields {
+		if name == "id" || name == "_links" {
+			continue
+		}
+
+		if r.Attributes == nil {
+			r.Attributes = map[string]interface{}{}
+		}
+		r.Attributes[name] = value
+	}
+
+	for name := range links {
+		if name == "self" {
+			continue
+		}
+
+		if r.Relationships == nil {
+			r.Relationships = map[string]Relationship{}
+		}
+		r.Relationships[name] = Relationship{
+			Links: map[string]string{"related": href(links, name)},
+		}
+	}
+
+	return r, true
+}
+
+func linksOf(fields map[string]interface{}) map[string]interface{} {
+	links, _ := fields["_links"].(map[string]interface{})
+	return links
+}
+
+// hrefs returns the hrefs of the named links, omitting those that are absent.
+func hrefs(links map[string]interface{}, names ...string) map[string]string {
+	var result map[string]string
+
+	for _, name := range names {
+		if h := href(links, name); h != "" {
+			if result == nil {
+				result = map[string]string{}
+			}
+			result[name] = h
+		}
+	}
+
+	return result
+}
+
+// href returns the href of the named link, less any uri template parameters.
+func href(links map[string]interface{}, name string) string {
+	link, _ := links[name].(map[string]interface{})
+	h, _ := link["href"].(string)
+
+	if i := strings.Index(h, "{"); i >= 0 {
+		h = h[:i]
+	}
+
+	return h
+}
+
+// collection returns the name of the collection that the resource at href
+// belongs to, e.g. "ledgers" for "/ledgers/1".
+func collection(href, fallback string) string {
+	parts := strings.Split(strings.Trim(strings.SplitN(href, "?", 2)[0], "/"), "/")
+	if len(parts) < 2 || parts[0] == "" {
+		return fallback
+	}
+
+	return parts[len(parts)-2]
+}
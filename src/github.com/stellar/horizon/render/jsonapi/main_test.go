@@ -0,0 +1,78 @@
+package jsonapi
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestJsonapiPackage(t *testing.T) {
+	ledger := map[string]interface{}{
+		"_links": map[string]interface{}{
+			"self":         map[string]interface{}{"href": "/ledgers/1"},
+			"transactions": map[string]interface{}{"href": "/ledgers/1/transactions{?cursor,limit,order}", "templated": true},
+		},
+		"id":       "abcd",
+		"sequence": 1,
+	}
+
+	Convey("jsonapi.Convert", t, func() {
+		Convey("converts resources", func() {
+			doc, err := Convert(ledger, "ledgers")
+			So(err, ShouldBeNil)
+			So(doc.Links, ShouldResemble, map[string]string{"self": "/ledgers/1"})
+
+			r := doc.Data.(Resource)
+			So(r.Type, ShouldEqual, "ledgers")
+			So(r.ID, ShouldEqual, "abcd")
+			So(r.Attributes["sequence"], ShouldEqual, json.Number("1"))
+			So(r.Attributes["id"], ShouldBeNil)
+			So(r.Relationships["transactions"].Links["related"], ShouldEqual, "/ledgers/1/transactions")
+		})
+
+		Convey("converts pages", func() {
+			effect := map[string]interface{}{"id": 12, "type": "account_created"}
+			page := map[string]interface{}{
+				"_links": map[string]interface{}{
+					"self": map[string]interface{}{"href": "/effects?order=asc"},
+					"next": map[string]interface{}{"href": "/effects?cursor=12"},
+				},
+				"_embedded": map[string]interface{}{
+					"records": []interface{}{ledger, effect},
+				},
+			}
+
+			doc, err := Convert(page, "effects")
+			So(err, ShouldBeNil)
+			So(doc.Links["next"], ShouldEqual, "/effects?cursor=12")
+			So(doc.Links["prev"], ShouldEqual, "")
+
+			records := doc.Data.([]Resource)
+			So(len(records), ShouldEqual, 2)
+			So(records[0].Type, ShouldEqual, "ledgers")
+			So(records[1].Type, ShouldEqual, "effects")
+			So(records[1].ID, ShouldEqual, "12")
+			So(records[1].Attributes["type"], ShouldEqual, "account_created")
+		})
+
+		Convey("delivers documents without ids as meta", func() {
+			doc, err := Convert(map[string]int{"goroutines": 10}, "metrics")
+			So(err, ShouldBeNil)
+			So(doc.Data, ShouldBeNil)
+			So(doc.Meta, ShouldNotBeNil)
+		})
+	})
+
+	Convey("jsonapi.NewWriter", t, func() {
+		w := httptest.NewRecorder()
+		NewWriter(w, "/ledgers/1").(*writer).EncodeHAL(ledger)
+
+		So(w.Header().Get("Content-Type"), ShouldEqual, "application/vnd.api+json")
+
+		var doc map[string]interface{}
+		So(json.Unmarshal(w.Body.Bytes(), &doc), ShouldBeNil)
+		So(doc["data"].(map[string]interface{})["type"], ShouldEqual, "ledgers")
+	})
+}
@@ -0,0 +1,6 @@
+// Package longpoll contains the long-polling transport used by horizon.  It
+// delivers the same events that package sse does in an ordinary json response,
+// holding the request open until at least one event is available or the
+// client's wait expires.  It serves clients behind proxies that buffer
+// text/event-stream responses, and so cannot stream.
+package longpoll
@@ -0,0 +1,195 @@
+package longpoll
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-errors/errors"
+	"github.com/stellar/horizon/render/problem"
+	"github.com/stellar/horizon/render/sse"
+	"golang.org/x/net/context"
+)
+
+// MaxWait is the longest a client may ask to wait for events.
+const MaxWait = 60 * time.Second
+
+// message is the json form of an sse.Event delivered in a response.
+type message struct {
+	ID    string      `json:"id,omitempty"`
+	Event string      `json:"event,omitempty"`
+	Data  interface{} `json:"data"`
+}
+
+// response is the json document rendered to long-polling clients.  Cursor
+// is the id of the last event delivered, from which the client should make
+// its next request.
+type response struct {
+	Events []message `json:"events"`
+	Cursor string    `json:"cursor,omitempty"`
+}
+
+// ParseWait parses the wait requested by a client, either a duration such as
+// "30s" or a plain number of seconds.  The result is capped at MaxWait.
+func ParseWait(wait string) (time.Duration, error) {
+	d, err := time.ParseDuration(wait)
+	if err != nil {
+		seconds, serr := strconv.Atoi(wait)
+		if serr != nil {
+			return 0, errors.Wrap(err, 1)
+		}
+		d = time.Duration(seconds) * time.Second
+	}
+
+	if d < 0 {
+		return 0, errors.Errorf("negative wait: %s", wait)
+	}
+
+	if d > MaxWait {
+		d = MaxWait
+	}
+
+	return d, nil
+}
+
+// NewStream returns a Stream that collects the events sent upon it, for
+// delivery to the client with a single call to Render.
+func NewStream(ctx context.Context, r *http.Request) *Stream {
+	return &Stream{ctx: ctx, lastID: sse.LastEventID(r)}
+}
+
+// Stream is an sse.Stream that collects events rather than writing them,
+// allowing actions written against the sse package to serve long-polling
+// clients unchanged.  A Stream is done as soon as it holds any events, so that
+// the client receives them without waiting for more.
+type Stream struct {
+	ctx    context.Context
+	events []message
+	lastID string
+	done   bool
+	err    error
+}
+
+// Send collects e for delivery to the client.
+func (s *Stream) Send(e sse.Event) {
+	if e.Error != nil {
+		s.Err(e.Error)
+		return
+	}
+
+	s.events = append(s.events, message{ID: e.ID, Event: e.Event, Data: e.Data})
+
+	if e.ID != "" {
+		s.lastID = e.ID
+	}
+}
+
+// SentCount returns the number of events collected.
+func (s *Stream) SentCount() int {
+	return len(s.events)
+}
+
+// Done ends the stream.
+func (s *Stream) Done() {
+	s.done = true
+}
+
+// IsDone returns true once the stream has ended, or holds events to deliver.
+func (s *Stream) IsDone() bool {
+	return s.done || len(s.events) > 0
+}
+
+// Err ends the stream, causing the problem for err to be rendered in place of
+// any events collected.
+func (s *Stream) Err(err error) {
+	s.err = err
+	s.done = true
+}
+
+// LastEventID returns the id of the last event collected, falling back to the
+// id the client reported.
+func (s *Stream) LastEventID() string {
+	return s.lastID
+}
+
+// Keepalive does nothing, as a long-polling client has nothing to keep alive.
+func (s *Stream) Keepalive() {}
+
+// Meta does nothing, as a long-polling response only delivers the stream's
+// events.
+func (s *Stream) Meta(e sse.Event) {}
+
+// Shutdown ends the stream, delivering whatever has been collected.
+func (s *Stream) Shutdown() {
+	s.done = true
+}
+
+// Render writes the events collected, or the problem for the stream's error,
+// to w.
+func (s *Stream) Render(w http.ResponseWriter) {
+	if s.err != nil {
+		problem.Render(s.ctx, w, s.err)
+		return
+	}
+
+	doc := response{Events: s.events, Cursor: s.lastID}
+	if doc.Events == nil {
+		doc.Events = []message{}
+	}
+
+	js, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		problem.Render(s.ctx, w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Write(js)
+}
+
+// Streamer handles the work of turning a channel of Eventable objects into a
+// long-polling response.  It is the long-polling counterpart of sse.Streamer.
+// Construct one and call `ServeHTTP` to do so.
+//
+// The response is rendered as soon as Data yields an event, along with any
+// further events immediately available, or once Wait has passed without any.
+type Streamer struct {
+	Ctx  context.Context
+	Data <-chan sse.Eventable
+	Wait time.Duration
+}
+
+func (s *Streamer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	stream := NewStream(s.Ctx, r)
+	defer stream.Render(w)
+
+	timeout := time.NewTimer(s.Wait)
+	defer timeout.Stop()
+
+	select {
+	case eventable, more := <-s.Data:
+		if !more {
+			return
+		}
+		stream.Send(eventable.SseEvent())
+	case <-timeout.C:
+		return
+	case <-s.Ctx.Done():
+		return
+	}
+
+	// deliver any events that are ready along with the first
+	for !stream.done {
+		select {
+		case eventable, more := <-s.Data:
+			if !more {
+				return
+			}
+			stream.Send(eventable.SseEvent())
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package longpoll
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/render/sse"
+	"github.com/stellar/horizon/test"
+)
+
+func TestLongpollPackage(t *testing.T) {
+	ctx := test.Context()
+
+	Convey("longpoll.ParseWait", t, func() {
+		d, err := ParseWait("30s")
+		So(err, ShouldBeNil)
+		So(d, ShouldEqual, 30*time.Second)
+
+		d, err = ParseWait("5")
+		So(err, ShouldBeNil)
+		So(d, ShouldEqual, 5*time.Second)
+
+		d, err = ParseWait("1h")
+		So(err, ShouldBeNil)
+		So(d, ShouldEqual, MaxWait)
+
+		_, err = ParseWait("soon")
+		So(err, ShouldNotBeNil)
+
+		_, err = ParseWait("-5s")
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("longpoll.Stream", t, func() {
+		r, _ := http.NewRequest("GET", "/ledgers?wait=30s", nil)
+		r.Header.Set("Last-Event-ID", "1234")
+		w := httptest.NewRecorder()
+		stream := NewStream(ctx, r)
+
+		Convey("is done once it holds events", func() {
+			So(stream.IsDone(), ShouldBeFalse)
+			stream.Send(sse.Event{ID: "1235", Data: "test"})
+			stream.Send(sse.Event{ID: "1236", Data: "test"})
+			So(stream.IsDone(), ShouldBeTrue)
+			So(stream.SentCount(), ShouldEqual, 2)
+
+			stream.Render(w)
+			So(w.Code, ShouldEqual, http.StatusOK)
+
+			var doc response
+			So(json.Unmarshal(w.Body.Bytes(), &doc), ShouldBeNil)
+			So(len(doc.Events), ShouldEqual, 2)
+			So(doc.Cursor, ShouldEqual, "1236")
+		})
+
+		Convey("renders no events with the client's cursor when empty", func() {
+			stream.Render(w)
+			So(w.Body.String(), ShouldContainSubstring, `"events": []`)
+			So(w.Body.String(), ShouldContainSubstring, `"cursor": "1234"`)
+		})
+
+		Convey("renders a problem for errors", func() {
+			stream.Send(sse.Event{ID: "1235", Data: "test"})
+			stream.Err(errors.New("busted"))
+			stream.Render(w)
+			So(w.Code, ShouldEqual, http.StatusInternalServerError)
+		})
+	})
+
+	Convey("longpoll.Streamer", t, func() {
+		r, _ := http.NewRequest("GET", "/ledgers?wait=30s", nil)
+		w := httptest.NewRecorder()
+
+		Convey("delivers every event that is ready", func() {
+			data := make(chan sse.Eventable, 2)
+			data <- sse.Event{ID: "1", Data: "one"}
+			data <- sse.Event{ID: "2", Data: "two"}
+
+			streamer := &Streamer{Ctx: ctx, Data: data, Wait: time.Second}
+			streamer.ServeHTTP(w, r)
+
+			var doc response
+			So(json.Unmarshal(w.Body.Bytes(), &doc), ShouldBeNil)
+			So(len(doc.Events), ShouldEqual, 2)
+		})
+
+		Convey("gives up once the wait expires", func() {
+			streamer := &Streamer{Ctx: ctx, Data: make(chan sse.Eventable), Wait: time.Millisecond}
+			streamer.ServeHTTP(w, r)
+			So(w.Body.String(), ShouldContainSubstring, `"events": []`)
+		})
+	})
+}
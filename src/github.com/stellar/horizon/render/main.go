@@ -12,14 +12,13 @@ import (
 // Negotiate inspects the Accept header of the provided request and determines
 // what the most appropriate response type should be.  Defaults to HAL.
 func Negotiate(ctx context.Context, r *http.Request) string {
-	alternatives := []string{MimeHal, MimeJSON, MimeEventStream}
 	accept := r.Header.Get("Accept")
 
 	if accept == "" {
 		return MimeHal
 	}
 
-	result := goautoneg.Negotiate(r.Header.Get("Accept"), alternatives)
+	result := goautoneg.Negotiate(r.Header.Get("Accept"), mimeTypes)
 
 	log.WithFields(ctx, logrus.Fields{
 		"content_type": result,
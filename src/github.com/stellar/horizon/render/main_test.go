@@ -2,6 +2,7 @@ package render
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"golang.org/x/net/context"
@@ -42,4 +43,67 @@ func TestRenderPackage(t *testing.T) {
 		})
 
 	})
+
+	Convey("render.Renderer", t, func() {
+		ctx := context.Background()
+		var called string
+		renderer := Renderer{
+			HAL:         func() { called = MimeHal },
+			EventStream: func() { called = MimeEventStream },
+		}
+
+		r, _ := http.NewRequest("GET", "/ledgers", nil)
+		w := httptest.NewRecorder()
+
+		Convey("calls the handler for the negotiated type", func() {
+			r.Header.Set("Accept", "text/event-stream")
+			renderer.Render(ctx, w, r)
+			So(called, ShouldEqual, MimeEventStream)
+		})
+
+		Convey("only negotiates the types it has handlers for", func() {
+			r.Header.Set("Accept", "application/x-stellar-xdr,application/hal+json;q=0.5")
+			renderer.Render(ctx, w, r)
+			So(called, ShouldEqual, MimeHal)
+
+			r.Header.Set("Accept", "application/x-stellar-xdr")
+			renderer.Render(ctx, w, r)
+			So(w.Code, ShouldEqual, http.StatusNotAcceptable)
+		})
+
+		Convey("lets the format param override the Accept header", func() {
+			renderer.CSV = func() { called = MimeCSV }
+
+			r, _ := http.NewRequest("GET", "/ledgers?format=csv", nil)
+			r.Header.Set("Accept", "application/hal+json")
+			renderer.Render(ctx, w, r)
+			So(called, ShouldEqual, MimeCSV)
+
+			r, _ = http.NewRequest("GET", "/ledgers?format=xdr", nil)
+			renderer.Render(ctx, w, r)
+			So(w.Code, ShouldEqual, http.StatusNotAcceptable)
+
+			r, _ = http.NewRequest("GET", "/ledgers?format=yaml", nil)
+			w = httptest.NewRecorder()
+			renderer.Render(ctx, w, r)
+			So(w.Code, ShouldEqual, http.StatusNotAcceptable)
+		})
+
+		Convey("refuses to stream over connections that cannot be flushed", func() {
+			r.Header.Set("Accept", "text/event-stream")
+			w := &unflushable{httptest.NewRecorder()}
+			renderer.Render(ctx, w, r)
+			So(called, ShouldEqual, "")
+			So(w.rec.Code, ShouldEqual, http.StatusBadRequest)
+		})
+	})
+}
+
+// unflushable is a ResponseWriter that does not implement http.Flusher.
+type unflushable struct {
+	rec *httptest.ResponseRecorder
 }
+
+func (w *unflushable) Header() http.Header         { return w.rec.Header() }
+func (w *unflushable) Write(p []byte) (int, error) { return w.rec.Write(p) }
+func (w *unflushable) WriteHeader(code int)        { w.rec.WriteHeader(code) }
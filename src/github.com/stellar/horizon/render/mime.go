@@ -9,4 +9,16 @@ const (
 	MimeJSON = "application/json"
 	//MimeProblem is the mime type for application/problem+json"
 	MimeProblem = "application/problem+json"
+	//MimeXDR is the mime type for raw, binary encoded xdr
+	MimeXDR = "application/x-stellar-xdr"
+	//MimeNDJSON is the mime type for newline-delimited json
+	MimeNDJSON = "application/x-ndjson"
+	//MimeCSV is the mime type for "text/csv"
+	MimeCSV = "text/csv"
+	//MimeJSONAPI is the mime type for "application/vnd.api+json"
+	MimeJSONAPI = "application/vnd.api+json"
 )
+
+// mimeTypes are the response types horizon renders, in order of preference
+// when a client accepts several equally.
+var mimeTypes = []string{MimeHal, MimeJSON, MimeEventStream, MimeXDR, MimeNDJSON, MimeCSV, MimeJSONAPI}
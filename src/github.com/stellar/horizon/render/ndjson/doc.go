@@ -0,0 +1,6 @@
+// Package ndjson contains the newline-delimited json transport used by horizon
+// to export whole collections.  Each record is written as a single line of
+// compact json as soon as it is loaded, without the envelope or page limit of
+// a HAL response, so that clients doing bulk exports needn't follow the links
+// of every page.
+package ndjson
@@ -0,0 +1,120 @@
+package ndjson
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-errors/errors"
+	"github.com/stellar/horizon/log"
+	"github.com/stellar/horizon/render"
+	"github.com/stellar/horizon/render/problem"
+	"golang.org/x/net/context"
+)
+
+// NewStream returns a Stream that writes records to w.
+func NewStream(ctx context.Context, w http.ResponseWriter) *Stream {
+	w.Header().Set("Content-Type", render.MimeNDJSON)
+	flusher, _ := w.(http.Flusher)
+	return &Stream{ctx: ctx, w: w, flusher: flusher}
+}
+
+// Stream writes each record of an export to the client as a line of json,
+// flushing as it goes when the response can be flushed.
+type Stream struct {
+	ctx       context.Context
+	w         http.ResponseWriter
+	flusher   http.Flusher
+	written   int
+	err       error
+	transform func(record interface{}) (interface{}, error)
+}
+
+// Transform sets f to be applied to each record written before it is
+// marshalled, such as to encode the paging tokens of records with
+// hal.EncodeCursors.
+func (s *Stream) Transform(f func(record interface{}) (interface{}, error)) {
+	s.transform = f
+}
+
+// Write writes record to the client.
+func (s *Stream) Write(record interface{}) {
+	if s.err != nil {
+		return
+	}
+
+	if s.transform != nil {
+		transformed, err := s.transform(record)
+		if err != nil {
+			s.Err(errors.Wrap(err, 1))
+			return
+		}
+		record = transformed
+	}
+
+	js, err := json.Marshal(record)
+	if err != nil {
+		s.Err(errors.Wrap(err, 1))
+		return
+	}
+
+	if _, err := s.w.Write(append(js, '\n')); err != nil {
+		s.err = err
+		return
+	}
+
+	s.written++
+	if s.flusher != nil {
+		s.flusher.Flush()
+	}
+}
+
+// Written returns the number of records written.
+func (s *Stream) Written() int {
+	return s.written
+}
+
+// Continue returns true while the export should go on: until the client goes
+// away, or the stream fails.
+func (s *Stream) Continue() bool {
+	if s.err != nil {
+		return false
+	}
+
+	select {
+	case <-s.ctx.Done():
+		return false
+	default:
+		return true
+	}
+}
+
+// Err ends the stream with err.  If no record has been written, the problem
+// for err is rendered as the response.  Otherwise, the problem is written as
+// the final line of the export, so that clients can tell a failed export from
+// a complete one.
+func (s *Stream) Err(err error) {
+	if s.err != nil {
+		return
+	}
+	s.err = err
+
+	if s.written == 0 {
+		problem.Render(s.ctx, s.w, err)
+		return
+	}
+
+	p := problem.Resolve(s.ctx, err)
+	if p.Status >= http.StatusInternalServerError {
+		log.WithStack(s.ctx, err).Error(err)
+	}
+
+	js, merr := json.Marshal(p)
+	if merr != nil {
+		return
+	}
+
+	s.w.Write(append(js, '\n'))
+	if s.flusher != nil {
+		s.flusher.Flush()
+	}
+}
@@ -0,0 +1,60 @@
+package ndjson
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/render"
+	"github.com/stellar/horizon/render/problem"
+	"github.com/stellar/horizon/test"
+	"golang.org/x/net/context"
+)
+
+func TestNdjsonPackage(t *testing.T) {
+	ctx := test.Context()
+
+	Convey("ndjson.Stream", t, func() {
+		w := httptest.NewRecorder()
+		stream := NewStream(ctx, w)
+
+		Convey("writes each record on its own line", func() {
+			stream.Write(map[string]int{"id": 1})
+			stream.Write(map[string]int{"id": 2})
+
+			So(w.Header().Get("Content-Type"), ShouldEqual, render.MimeNDJSON)
+			So(w.Body.String(), ShouldEqual, "{\"id\":1}\n{\"id\":2}\n")
+			So(stream.Written(), ShouldEqual, 2)
+			So(w.Flushed, ShouldBeTrue)
+		})
+
+		Convey("renders the problem for errors raised before any record", func() {
+			stream.Err(&problem.NotFound)
+
+			So(w.Code, ShouldEqual, http.StatusNotFound)
+			So(w.Header().Get("Content-Type"), ShouldEqual, "application/problem+json")
+			So(stream.Continue(), ShouldBeFalse)
+		})
+
+		Convey("ends exports with the problem for later errors", func() {
+			stream.Write(map[string]int{"id": 1})
+			stream.Err(errors.New("busted"))
+			stream.Write(map[string]int{"id": 2})
+
+			So(w.Code, ShouldEqual, http.StatusOK)
+			So(w.Body.String(), ShouldStartWith, "{\"id\":1}\n{\"type\":\"https://stellar.org/horizon-errors/server_error\"")
+			So(stream.Written(), ShouldEqual, 1)
+		})
+
+		Convey("stops once the client goes away", func() {
+			So(stream.Continue(), ShouldBeTrue)
+
+			cctx, cancel := context.WithCancel(ctx)
+			stream = NewStream(cctx, w)
+			cancel()
+			So(stream.Continue(), ShouldBeFalse)
+		})
+	})
+}
@@ -55,7 +55,7 @@ func Inflate(ctx context.Context, p *P) {
 	//TODO: add requesting url to extra info
 
 	//TODO: make this prefix configurable
-	p.Type = "https://stellar.org/horizon-errors/" + p.Type
+	p.Type = TypeURI(p.Type)
 
 	p.Instance = requestid.FromContext(ctx)
 }
@@ -68,24 +68,59 @@ func Inflate(ctx context.Context, p *P) {
 // of the `HasProblem` interface, or an error.  Any other value for `p` will
 // panic.
 func Render(ctx context.Context, w http.ResponseWriter, p interface{}) {
+	if err, ok := p.(error); ok && !isProblem(err) {
+		log.WithStack(ctx, err).Error(err)
+	}
+
+	render(ctx, w, Resolve(ctx, p))
+}
+
+// Resolve returns the inflated problem that Render would render for `p`,
+// without writing a response.  It is useful for delivering problems over
+// channels other than a plain http response, such as a stream.  `p` has the
+// same meaning as for Render, and any other value will panic.
+func Resolve(ctx context.Context, p interface{}) P {
+	var result P
+
 	switch p := p.(type) {
 	case P:
-		render(ctx, w, p)
+		result = p
 	case *P:
-		render(ctx, w, *p)
+		result = *p
 	case HasProblem:
-		render(ctx, w, p.Problem())
+		result = p.Problem()
 	case error:
-		renderErr(ctx, w, p)
+		result = fromErr(p)
 	default:
 		panic(fmt.Sprintf("Invalid problem: %v+", p))
 	}
+
+	complete(&result)
+	Inflate(ctx, &result)
+	return result
 }
 
-func render(ctx context.Context, w http.ResponseWriter, p P) {
+// complete fills in the title, status and detail p leaves out from the problem
+// registered under its code, so that a problem may be given by its code and
+// extras alone, such as P{Type: "before_history", Extras: ...}.
+func complete(p *P) {
+	registered, ok := Lookup(p.Type)
+	if !ok {
+		return
+	}
 
-	Inflate(ctx, &p)
+	if p.Title == "" {
+		p.Title = registered.Title
+	}
+	if p.Status == 0 {
+		p.Status = registered.Status
+	}
+	if p.Detail == "" {
+		p.Detail = registered.Detail
+	}
+}
 
+func render(ctx context.Context, w http.ResponseWriter, p P) {
 	w.Header().Set("Content-Type", "application/problem+json")
 	js, err := json.MarshalIndent(p, "", "  ")
 
@@ -100,7 +135,9 @@ func render(ctx context.Context, w http.ResponseWriter, p P) {
 	w.Write(js)
 }
 
-func renderErr(ctx context.Context, w http.ResponseWriter, err error) {
+// fromErr returns the problem registered for err, or ServerError if there is
+// none.
+func fromErr(err error) P {
 	origErr := err
 
 	if err, ok := err.(*errors.Error); ok {
@@ -113,26 +150,47 @@ func renderErr(ctx context.Context, w http.ResponseWriter, err error) {
 		p = ServerError
 	}
 
-	log.WithStack(ctx, err).Error(err)
-	render(ctx, w, p)
+	return p
+}
+
+// isProblem returns true if err is itself a problem, rather than an error that
+// is converted into one.
+func isProblem(err error) bool {
+	switch err.(type) {
+	case *P, HasProblem:
+		return true
+	default:
+		return false
+	}
+}
+
+// InvalidParam returns a BadRequest problem naming the param name, whose value
+// is refused for reason, such as "must be asc or desc".
+func InvalidParam(name string, reason string) *P {
+	p := BadRequest.With(map[string]interface{}{
+		"invalid_field": name,
+		"reason":        reason,
+	})
+	p.Detail = fmt.Sprintf("The %s param is invalid: it %s.", name, reason)
+	return p
 }
 
 // Well-known and reused problems below:
 var (
 	// NotFound is a well-known problem type.  Use it as a shortcut
 	// in your actions.
-	NotFound = P{
+	NotFound = Register(P{
 		Type:   "not_found",
 		Title:  "Resource Missing",
 		Status: http.StatusNotFound,
 		Detail: "The resource at the url requested was not found.  This is usually " +
 			"occurs for one of two reasons:  The url requested is not valid, or no " +
 			"data in our database could be found with the parameters provided.",
-	}
+	})
 
 	// ServerError is a well-known problem type.  Use it as a shortcut
 	// in your actions.
-	ServerError = P{
+	ServerError = Register(P{
 		Type:   "server_error",
 		Title:  "Internal Server Error",
 		Status: http.StatusInternalServerError,
@@ -141,11 +199,56 @@ var (
 			"succeed if the bug is transient, otherwise please report this issue " +
 			"to the issue tracker at: https://github.com/stellar/horizon/issues." +
 			" Please include this response in your issue.",
-	}
+	})
+
+	// BadRequest is a well-known problem type, rendered when a param of a
+	// request is invalid.  Its extras name the invalid_field and the reason it
+	// was refused.  Use InvalidParam to make one.
+	BadRequest = Register(P{
+		Type:   "bad_request",
+		Title:  "Bad Request",
+		Status: http.StatusBadRequest,
+		Detail: "The request you sent was invalid in some way.",
+	})
+
+	// InvalidAccountID is a well-known problem type, rendered when a param that
+	// names an account is not an account id.  Its extras name the
+	// invalid_field.
+	InvalidAccountID = Register(P{
+		Type:   "invalid_account_id",
+		Title:  "Invalid Account ID",
+		Status: http.StatusBadRequest,
+		Detail: "A param of this request must be the id of an account, a strkey " +
+			"such as GABC..., but is malformed or its checksum does not verify.",
+	})
+
+	// SecretSeedGiven is a well-known problem type, rendered when a param that
+	// names an account is a secret seed instead.  Its extras name the
+	// invalid_field.
+	SecretSeedGiven = Register(P{
+		Type:   "secret_seed_given",
+		Title:  "Secret Seed Given",
+		Status: http.StatusBadRequest,
+		Detail: "A param of this request that must be the id of an account, such " +
+			"as GABC..., is a secret seed.  Never send a seed to horizon: this " +
+			"request may have been logged along the way, so move the funds of " +
+			"its account to a new key.",
+	})
+
+	// Timeout is a well-known problem type.  Use it as a shortcut
+	// in your actions.
+	Timeout = Register(P{
+		Type:   "timeout",
+		Title:  "Timeout",
+		Status: http.StatusGatewayTimeout,
+		Detail: "This request took longer to answer than the server allows, and " +
+			"was cancelled.  Try again later, or narrow the request, such as with " +
+			"a smaller limit.",
+	})
 
 	// RateLimitExceeded is a well-known problem type.  Use it as a shortcut
 	// in your actions.
-	RateLimitExceeded = P{
+	RateLimitExceeded = Register(P{
 		Type:   "rate_limit_exceeded",
 		Title:  "Rate limit exceeded",
 		Status: 429,
@@ -153,25 +256,238 @@ var (
 			"limit.  The allowed limit and requests left per time period are " +
 			"communicated to clients via the http response headers 'X-RateLimit-*' " +
 			"headers.",
-	}
+	})
+
+	// SourceAccountRateLimitExceeded is a well-known problem type.  Use it as a
+	// shortcut in your actions.
+	SourceAccountRateLimitExceeded = Register(P{
+		Type:   "source_account_rate_limit_exceeded",
+		Title:  "Source account rate limit exceeded",
+		Status: 429,
+		Detail: "The rate limit for transactions submitted from this transaction's " +
+			"source account is over its alloted limit.  The allowed limit and " +
+			"submissions left per time period are communicated to clients via " +
+			"the http response headers 'X-RateLimit-*' headers.",
+	})
+
+	// TooManySubmissions is a well-known problem type.  Use it as a shortcut
+	// in your actions.
+	TooManySubmissions = Register(P{
+		Type:   "too_many_submissions",
+		Title:  "Too many submissions",
+		Status: http.StatusServiceUnavailable,
+		Detail: "The server has reached the maximum number of transaction " +
+			"submissions it awaits the results of at once.  Try again once " +
+			"the ledgers under way have closed.",
+	})
+
+	// TooManyStreams is a well-known problem type.  Use it as a shortcut
+	// in your actions.
+	TooManyStreams = Register(P{
+		Type:   "too_many_streams",
+		Title:  "Too many streams",
+		Status: http.StatusServiceUnavailable,
+		Detail: "The server, or the requesting IP address, has reached the maximum " +
+			"number of concurrent streaming connections allowed.  Close an open " +
+			"stream or try again later.",
+	})
+
+	// ShuttingDown is a well-known problem type.  Use it as a shortcut
+	// in your actions.
+	ShuttingDown = Register(P{
+		Type:   "shutting_down",
+		Title:  "Server shutting down",
+		Status: http.StatusServiceUnavailable,
+		Detail: "This server is shutting down and is no longer accepting streaming " +
+			"connections.  Please retry your request.",
+	})
+
+	// StreamingNotSupported is a well-known problem type.  Use it as a shortcut
+	// in your actions.
+	StreamingNotSupported = Register(P{
+		Type:   "streaming_not_supported",
+		Title:  "Streaming Not Supported",
+		Status: http.StatusBadRequest,
+		Detail: "The connection this request was made over cannot deliver a " +
+			"streaming response.  Please retry your request without streaming.",
+	})
 
 	// NotImplemented is a well-known problem type.  Use it as a shortcut
 	// in your actions.
-	NotImplemented = P{
+	NotImplemented = Register(P{
 		Type:   "not_implemented",
 		Title:  "Resource Not Yet Implemented",
 		Status: http.StatusNotFound,
 		Detail: "While the requested URL is expected to eventually point to a " +
 			"valid resource, the work to implement the resource has not yet " +
 			"been completed.",
-	}
+	})
 
 	// NotAcceptable is a well-known problem type.  Use it as a shortcut
 	// in your actions.
-	NotAcceptable = P{
+	NotAcceptable = Register(P{
 		Type: "not_acceptable",
 		Title: "An acceptable response content-type could not be provided for " +
 			"this request",
 		Status: http.StatusNotAcceptable,
-	}
+	})
+
+	// TransactionFailed is a well-known problem type, rendered when stellar-core
+	// rejects a submitted transaction.  Its extras carry the transaction's
+	// envelope_xdr and result_xdr, and its result_codes.
+	TransactionFailed = Register(P{
+		Type:   "transaction_failed",
+		Title:  "Transaction Failed",
+		Status: http.StatusBadRequest,
+		Detail: "The transaction failed when submitted to the stellar network.  " +
+			"The `extras.result_codes` field on this response contains further " +
+			"details.  Descriptions of each code can be found at: " +
+			"https://www.stellar.org/developers/learn/concepts/list-of-operations.html",
+	})
+
+	// TransactionMalformed is a well-known problem type, rendered when a
+	// submitted transaction envelope cannot be decoded.  Its extras carry the
+	// envelope_xdr as received.
+	TransactionMalformed = Register(P{
+		Type:   "transaction_malformed",
+		Title:  "Transaction Malformed",
+		Status: http.StatusBadRequest,
+		Detail: "Horizon could not decode the transaction envelope in this " +
+			"request.  A transaction should be an XDR TransactionEnvelope struct " +
+			"encoded using base64.  The envelope read from this request is " +
+			"echoed in the `extras.envelope_xdr` field of this response for your " +
+			"convenience.",
+	})
+
+	// TransactionWrongNetwork is a well-known problem type, rendered when a
+	// submitted transaction carries a signature by one of its source accounts that
+	// does not verify on this network.
+	TransactionWrongNetwork = Register(P{
+		Type:   "transaction_wrong_network",
+		Title:  "Transaction Signed For Another Network",
+		Status: http.StatusBadRequest,
+		Detail: "A signature of this transaction, by the key of one of its source " +
+			"accounts, does not verify on this server's network.  The transaction " +
+			"was most likely signed for another network: sign it again using this " +
+			"network's passphrase, given as `network_passphrase` at the root of " +
+			"this server.",
+	})
+
+	// TransactionInvalidTimeBounds is a well-known problem type, rendered when the
+	// time bounds of a submitted transaction are out of order.
+	TransactionInvalidTimeBounds = Register(P{
+		Type:   "transaction_invalid_time_bounds",
+		Title:  "Transaction Time Bounds Invalid",
+		Status: http.StatusBadRequest,
+		Detail: "The lower time bound of this transaction is after its upper time " +
+			"bound, so the transaction can never be included in a ledger.",
+	})
+
+	// TransactionTooLate is a well-known problem type, rendered when a submitted
+	// transaction's upper time bound has passed.
+	TransactionTooLate = Register(P{
+		Type:   "transaction_too_late",
+		Title:  "Transaction Too Late",
+		Status: http.StatusBadRequest,
+		Detail: "The upper time bound of this transaction has passed, so it can no " +
+			"longer be included in a ledger.  Build the transaction again with a " +
+			"later upper bound.",
+	})
+
+	// TransactionTooEarly is a well-known problem type, rendered when a submitted
+	// transaction's lower time bound is too far in the future.
+	TransactionTooEarly = Register(P{
+		Type:   "transaction_too_early",
+		Title:  "Transaction Too Early",
+		Status: http.StatusBadRequest,
+		Detail: "The lower time bound of this transaction is further in the future " +
+			"than this server accepts.  Submit the transaction closer to the time " +
+			"it becomes valid.",
+	})
+
+	// TransactionNoOperations is a well-known problem type, rendered when a
+	// submitted transaction has no operations.
+	TransactionNoOperations = Register(P{
+		Type:   "transaction_no_operations",
+		Title:  "Transaction Has No Operations",
+		Status: http.StatusBadRequest,
+		Detail: "This transaction has no operations.  A transaction must have at " +
+			"least one.",
+	})
+
+	// TransactionTooManyOperations is a well-known problem type, rendered when a
+	// submitted transaction has more operations than the server accepts.
+	TransactionTooManyOperations = Register(P{
+		Type:   "transaction_too_many_operations",
+		Title:  "Transaction Has Too Many Operations",
+		Status: http.StatusBadRequest,
+		Detail: "This transaction has more operations than this server accepts. " +
+			" Split the operations amongst several transactions.",
+	})
+
+	// TransactionFeeTooHigh is a well-known problem type, rendered when a
+	// submitted transaction offers a fee above the server's ceiling.
+	TransactionFeeTooHigh = Register(P{
+		Type:   "transaction_fee_too_high",
+		Title:  "Transaction Fee Too High",
+		Status: http.StatusBadRequest,
+		Detail: "The fee this transaction offers is above the ceiling this server " +
+			"accepts.  Build the transaction again with a lower fee.",
+	})
+
+	// TransactionUnsigned is a well-known problem type, rendered when a submitted
+	// transaction has no signatures.
+	TransactionUnsigned = Register(P{
+		Type:   "transaction_unsigned",
+		Title:  "Transaction Unsigned",
+		Status: http.StatusBadRequest,
+		Detail: "This transaction has no signatures.  Sign it with the keys of its " +
+			"source accounts before submitting it.",
+	})
+
+	// TransactionTooManySignatures is a well-known problem type, rendered when a
+	// submitted transaction has more signatures than the server accepts.
+	TransactionTooManySignatures = Register(P{
+		Type:   "transaction_too_many_signatures",
+		Title:  "Transaction Has Too Many Signatures",
+		Status: http.StatusBadRequest,
+		Detail: "This transaction has more signatures than this server accepts. " +
+			" Remove the signatures not needed to authorize it.",
+	})
+
+	// TransactionDuplicateSignature is a well-known problem type, rendered when a
+	// submitted transaction carries the same signature twice.
+	TransactionDuplicateSignature = Register(P{
+		Type:   "transaction_duplicate_signature",
+		Title:  "Transaction Has A Duplicate Signature",
+		Status: http.StatusBadRequest,
+		Detail: "This transaction carries the same signature more than once.  Remove " +
+			"the duplicates before submitting it.",
+	})
+
+	// BeforeHistory is a well-known problem type, rendered when a request asks
+	// for data from before the oldest ledger this server has recorded.  Its
+	// extras carry the sequence of that ledger as history_elder_ledger.
+	BeforeHistory = Register(P{
+		Type:   "before_history",
+		Title:  "Data Requested Is Before Recorded History",
+		Status: http.StatusGone,
+		Detail: "This horizon instance is configured to only track a portion of " +
+			"the stellar network's latest history.  This request is asking for " +
+			"results prior to the recorded history known to this horizon " +
+			"instance.",
+	})
+
+	// StaleHistory is a well-known problem type, rendered when this server's
+	// history has fallen too far behind stellar-core to answer a request
+	// reliably.  Its extras carry the latest history_latest_ledger and
+	// core_latest_ledger.
+	StaleHistory = Register(P{
+		Type:   "stale_history",
+		Title:  "Historical DB Is Too Stale",
+		Status: http.StatusServiceUnavailable,
+		Detail: "This horizon instance is configured to reject client requests " +
+			"when it is lagging behind the connected instance of stellar-core.  " +
+			"Please try again later, or use a different horizon instance.",
+	})
 )
@@ -41,6 +41,55 @@ func TestProblemPackage(t *testing.T) {
 		})
 	})
 
+	Convey("problem registry", t, func() {
+		Convey("looks up well-known problems by code", func() {
+			for _, code := range []string{"transaction_failed", "rate_limit_exceeded", "before_history", "stale_history"} {
+				p, ok := Lookup(code)
+				So(ok, ShouldBeTrue)
+				So(p.Type, ShouldEqual, code)
+			}
+
+			_, ok := Lookup("unknown")
+			So(ok, ShouldBeFalse)
+		})
+
+		Convey("panics when a code is registered twice", func() {
+			So(func() { Register(P{Type: "not_found"}) }, ShouldPanic)
+		})
+
+		Convey("completes problems given by their code", func() {
+			w := testRender(ctx, P{
+				Type:   "before_history",
+				Extras: map[string]interface{}{"history_elder_ledger": 3},
+			})
+			So(w.Code, ShouldEqual, 410)
+			So(w.Body.String(), ShouldContainSubstring, BeforeHistory.Title)
+			So(w.Body.String(), ShouldContainSubstring, `"history_elder_ledger": 3`)
+		})
+
+		Convey("renders problems with extras under their type uri", func() {
+			p := TransactionFailed.With(map[string]interface{}{"result_xdr": "AAAA"})
+			So(TransactionFailed.Extras, ShouldBeNil)
+
+			w := testRender(ctx, p)
+			So(w.Code, ShouldEqual, 400)
+			So(w.Body.String(), ShouldContainSubstring, TypeURI("transaction_failed"))
+			So(w.Body.String(), ShouldContainSubstring, `"result_xdr": "AAAA"`)
+		})
+	})
+
+	Convey("problem.InvalidParam", t, func() {
+		Convey("names the param and the reason it was refused", func() {
+			p := InvalidParam("limit", "must be a number from 1 to 200")
+			So(p.Type, ShouldEqual, "bad_request")
+			So(p.Status, ShouldEqual, 400)
+			So(p.Detail, ShouldContainSubstring, "limit")
+			So(p.Extras["invalid_field"], ShouldEqual, "limit")
+			So(p.Extras["reason"], ShouldEqual, "must be a number from 1 to 200")
+			So(BadRequest.Extras, ShouldBeNil)
+		})
+	})
+
 	Convey("problem.Inflate", t, func() {
 		Convey("sets Instance to the request id based upon the context", func() {
 			ctx2 := requestid.Context(ctx, "2")
@@ -55,6 +104,24 @@ func TestProblemPackage(t *testing.T) {
 		})
 	})
 
+	Convey("problem.Resolve", t, func() {
+		Convey("inflates problems", func() {
+			p := Resolve(requestid.Context(ctx, "2"), NotFound)
+			So(p.Type, ShouldEqual, "https://stellar.org/horizon-errors/not_found")
+			So(p.Instance, ShouldEqual, "2")
+			So(NotFound.Type, ShouldEqual, "not_found")
+		})
+
+		Convey("converts errors to problems", func() {
+			So(Resolve(ctx, errors.New("broke")).Status, ShouldEqual, 500)
+			So(Resolve(ctx, &NotAcceptable).Status, ShouldEqual, 406)
+		})
+
+		Convey("panics if non-compliant `p` is used", func() {
+			So(func() { Resolve(ctx, "hello") }, ShouldPanic)
+		})
+	})
+
 	Convey("problem.Render", t, func() {
 		Convey("renders the type correctly", func() {
 			w := testRender(ctx, P{Type: "foo"})
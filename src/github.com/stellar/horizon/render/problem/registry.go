@@ -0,0 +1,49 @@
+package problem
+
+import "fmt"
+
+// TypePrefix is prepended to the code of a problem to make the type uri it is
+// rendered with.  Codes, and so type uris, are stable: clients should identify
+// problems by their type rather than by title or detail, which may change.
+const TypePrefix = "https://stellar.org/horizon-errors/"
+
+var registry = map[string]P{}
+
+// Register records p as the problem identified by its Type, a machine-readable
+// code such as "transaction_failed", and returns it.  Registering the same code
+// twice panics, as codes must identify a single kind of problem.
+func Register(p P) P {
+	if _, ok := registry[p.Type]; ok {
+		panic(fmt.Sprintf("problem: %s registered twice", p.Type))
+	}
+
+	registry[p.Type] = p
+	return p
+}
+
+// Lookup returns the problem registered under code.
+func Lookup(code string) (P, bool) {
+	p, ok := registry[code]
+	return p, ok
+}
+
+// TypeURI returns the type uri of the problem identified by code.
+func TypeURI(code string) string {
+	return TypePrefix + code
+}
+
+// With returns a copy of p carrying extras, the fields particular to a single
+// occurrence of the problem, such as the result codes of a failed transaction.
+// The extras of p, if any, are kept unless replaced.
+func (p P) With(extras map[string]interface{}) *P {
+	merged := make(map[string]interface{}, len(p.Extras)+len(extras))
+	for k, v := range p.Extras {
+		merged[k] = v
+	}
+	for k, v := range extras {
+		merged[k] = v
+	}
+
+	p.Extras = merged
+	return &p
+}
@@ -0,0 +1,115 @@
+package render
+
+import (
+	"net/http"
+
+	"bitbucket.org/ww/goautoneg"
+	"github.com/Sirupsen/logrus"
+	"github.com/stellar/horizon/log"
+	"github.com/stellar/horizon/render/problem"
+	"golang.org/x/net/context"
+)
+
+// Renderer routes a request to the handler for the response type negotiated
+// from its Accept header, so that actions needn't each switch upon the content
+// type.  Only the types that have a handler are considered during negotiation,
+// and a problem is rendered when the client accepts none of them.
+type Renderer struct {
+	// HAL renders MimeHal and MimeJSON responses.
+	HAL func()
+	// EventStream renders MimeEventStream responses.  It is only called when the
+	// response can be streamed.
+	EventStream func()
+	// XDR renders MimeXDR responses.
+	XDR func()
+	// NDJSON renders MimeNDJSON responses.
+	NDJSON func()
+	// CSV renders MimeCSV responses.
+	CSV func()
+	// JSONAPI renders MimeJSONAPI responses.
+	JSONAPI func()
+}
+
+// ParamFormat is the query string param through which clients may choose a
+// response type by name, e.g. "?format=csv", in place of an Accept header.
+const ParamFormat = "format"
+
+// formats maps the names accepted by ParamFormat to their response types.
+var formats = map[string]string{
+	"hal":     MimeHal,
+	"json":    MimeJSON,
+	"xdr":     MimeXDR,
+	"ndjson":  MimeNDJSON,
+	"csv":     MimeCSV,
+	"jsonapi": MimeJSONAPI,
+}
+
+// Render negotiates the response type for r and calls the matching handler.
+func (rr Renderer) Render(ctx context.Context, w http.ResponseWriter, r *http.Request) {
+	handlers := map[string]func(){
+		MimeHal:         rr.HAL,
+		MimeJSON:        rr.HAL,
+		MimeEventStream: rr.EventStream,
+		MimeXDR:         rr.XDR,
+		MimeNDJSON:      rr.NDJSON,
+		MimeCSV:         rr.CSV,
+		MimeJSONAPI:     rr.JSONAPI,
+	}
+
+	var alternatives []string
+	for _, mime := range mimeTypes {
+		if handlers[mime] != nil {
+			alternatives = append(alternatives, mime)
+		}
+	}
+
+	contentType := negotiate(ctx, r, alternatives)
+	handler := handlers[contentType]
+
+	if handler == nil {
+		problem.Render(ctx, w, problem.NotAcceptable)
+		return
+	}
+
+	if contentType == MimeEventStream && !Streamable(w) {
+		problem.Render(ctx, w, problem.StreamingNotSupported)
+		return
+	}
+
+	handler()
+}
+
+// Streamable returns true if w can deliver a streaming response, i.e. it can be
+// flushed as each event is written.
+func Streamable(w http.ResponseWriter) bool {
+	_, ok := w.(http.Flusher)
+	return ok
+}
+
+func negotiate(ctx context.Context, r *http.Request, alternatives []string) string {
+	if len(alternatives) == 0 {
+		return ""
+	}
+
+	accept := r.Header.Get("Accept")
+
+	// a format named in the query string overrides the Accept header
+	if format := r.URL.Query().Get(ParamFormat); format != "" {
+		accept = formats[format]
+		if accept == "" {
+			return ""
+		}
+	}
+
+	result := alternatives[0]
+	if accept != "" {
+		result = goautoneg.Negotiate(accept, alternatives)
+	}
+
+	log.WithFields(ctx, logrus.Fields{
+		"content_type": result,
+		"accept":       accept,
+	}).Debug("Negotiated content type")
+
+	return result
+}
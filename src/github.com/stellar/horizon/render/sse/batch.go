@@ -0,0 +1,171 @@
+package sse
+
+import (
+	"time"
+)
+
+// BatchConfig configures the coalescing of events into batches, each sent to
+// the client as a single event whose data is the array of the batched events'
+// data.  A batch is sent once it holds Size events or, if Window is non-zero,
+// once Window has passed since its first event was queued.  A batch takes the
+// id of its last event, and the name shared by its events: an event with a
+// different name than those queued before it starts a new batch.
+//
+// The zero value disables batching.
+type BatchConfig struct {
+	Size   int
+	Window time.Duration
+}
+
+// Enabled returns true if the config coalesces events.
+func (c BatchConfig) Enabled() bool {
+	return c.Size > 1 || c.Window > 0
+}
+
+// batch accumulates events according to a BatchConfig.
+type batch struct {
+	config BatchConfig
+	data   []interface{}
+	last   Event
+	timer  *time.Timer
+}
+
+// add queues e, returning true if the batch is now full.
+func (b *batch) add(e Event) bool {
+	if len(b.data) == 0 && b.config.Window > 0 {
+		b.timer = time.NewTimer(b.config.Window)
+	}
+
+	b.data = append(b.data, e.Data)
+	b.last = e
+
+	return b.config.Size > 0 && len(b.data) >= b.config.Size
+}
+
+// fits returns true if e may join the queued events.
+func (b *batch) fits(e Event) bool {
+	return len(b.data) == 0 || b.last.Event == e.Event
+}
+
+// due returns a channel that receives once the window of the queued events has
+// passed, or nil if there is no such window.
+func (b *batch) due() <-chan time.Time {
+	if b.timer == nil {
+		return nil
+	}
+
+	return b.timer.C
+}
+
+// take returns the queued events as a single event, emptying the batch.
+// Returns false if the batch is empty.
+func (b *batch) take() (Event, bool) {
+	if len(b.data) == 0 {
+		return Event{}, false
+	}
+
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+
+	e := Event{ID: b.last.ID, Event: b.last.Event, Data: b.data}
+	b.data = nil
+	return e, true
+}
+
+// NewBatchedStream returns a Stream that coalesces the events sent upon it into
+// batches before sending them to s.  Batches that are not yet full are sent
+// when Flush is called, or before the stream ends.  SentCount counts the
+// events sent, not the batches.
+//
+// If config does not enable batching, s is returned unchanged.
+func NewBatchedStream(s Stream, config BatchConfig) Stream {
+	if !config.Enabled() {
+		return s
+	}
+
+	return &BatchedStream{Stream: s, batch: batch{config: config}}
+}
+
+// BatchedStream is the Stream returned by NewBatchedStream.
+type BatchedStream struct {
+	Stream
+	batch  batch
+	sent   int
+	lastID string
+}
+
+// Send queues e, sending the batch if it fills.  Error events are never
+// batched.
+func (s *BatchedStream) Send(e Event) {
+	if e.Error != nil {
+		s.Flush()
+		s.Stream.Send(e)
+		return
+	}
+
+	if !s.batch.fits(e) {
+		s.Flush()
+	}
+
+	s.sent++
+	if e.ID != "" {
+		s.lastID = e.ID
+	}
+
+	if s.batch.add(e) {
+		s.Flush()
+	}
+}
+
+// Flush sends the pending batch, if any.
+func (s *BatchedStream) Flush() {
+	if e, ok := s.batch.take(); ok {
+		s.Stream.Send(e)
+	}
+}
+
+// Due returns a channel that receives once the pending batch's window has
+// passed and it should be flushed, or nil when no batch is waiting on its
+// window.  A nil *BatchedStream is never due.
+func (s *BatchedStream) Due() <-chan time.Time {
+	if s == nil {
+		return nil
+	}
+
+	return s.batch.due()
+}
+
+// SentCount returns the number of events sent, batched or not.
+func (s *BatchedStream) SentCount() int {
+	return s.sent
+}
+
+// LastEventID returns the id of the most recent event sent, including those
+// still pending in a batch.
+func (s *BatchedStream) LastEventID() string {
+	if s.lastID == "" {
+		return s.Stream.LastEventID()
+	}
+
+	return s.lastID
+}
+
+// Done flushes the pending batch and ends the stream.
+func (s *BatchedStream) Done() {
+	s.Flush()
+	s.Stream.Done()
+}
+
+// Shutdown flushes the pending batch and shuts the stream down.
+func (s *BatchedStream) Shutdown() {
+	s.Flush()
+	s.Stream.Shutdown()
+}
+
+// Err flushes the pending batch and sends err to the client.
+func (s *BatchedStream) Err(err error) {
+	s.Flush()
+	s.Stream.Err(err)
+}
@@ -0,0 +1,180 @@
+package sse
+
+import (
+	"bytes"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// BufferConfig configures the send buffer of a BufferedWriter.
+type BufferConfig struct {
+	// Size is the number of events that may be queued for a client before it is
+	// considered too slow and dropped.
+	Size int
+	// FlushBytes is the number of written bytes after which the underlying
+	// writer is flushed.  Zero flushes after every event.
+	FlushBytes int
+	// FlushInterval bounds how long written bytes may go unflushed when
+	// FlushBytes has not been reached.  Zero disables the interval.
+	FlushInterval time.Duration
+}
+
+// BufferedWriter is an http.ResponseWriter that decouples the formatting of
+// events from the sending of them.  Each call to Flush queues the bytes written
+// since the previous call as a single event, which a background goroutine then
+// writes to the client.  Flush never blocks: once Size events are queued, the
+// client is considered too slow and is dropped, closing the channel returned by
+// Dropped.
+//
+// A BufferedWriter must only be used from a single goroutine, and must be
+// closed before the handler that created it returns.
+type BufferedWriter struct {
+	w       http.ResponseWriter
+	config  BufferConfig
+	current bytes.Buffer
+	queue   chan []byte
+	dropped chan struct{}
+	once    sync.Once
+	closed  bool
+	exited  chan struct{}
+}
+
+// NewBufferedWriter returns a BufferedWriter that sends to w, starting its
+// background goroutine.  Any headers must already have been written to w.
+func NewBufferedWriter(w http.ResponseWriter, config BufferConfig) *BufferedWriter {
+	if config.Size < 1 {
+		config.Size = 1
+	}
+
+	result := &BufferedWriter{
+		w:       w,
+		config:  config,
+		queue:   make(chan []byte, config.Size),
+		dropped: make(chan struct{}),
+		exited:  make(chan struct{}),
+	}
+
+	go result.run()
+	return result
+}
+
+// Header returns the header map of the underlying writer.  Since headers will
+// have been sent before the BufferedWriter was created, changes have no
+// effect.
+func (b *BufferedWriter) Header() http.Header {
+	return b.w.Header()
+}
+
+// Write appends p to the event currently being formatted.
+func (b *BufferedWriter) Write(p []byte) (int, error) {
+	return b.current.Write(p)
+}
+
+// WriteHeader is a no-op, the status has already been sent to the client.
+func (b *BufferedWriter) WriteHeader(int) {}
+
+// Flush queues the event currently being formatted for sending.  If the queue
+// is full the client is dropped and the event discarded.
+func (b *BufferedWriter) Flush() {
+	if b.closed {
+		b.current.Reset()
+		return
+	}
+
+	if b.current.Len() == 0 {
+		return
+	}
+
+	event := make([]byte, b.current.Len())
+	copy(event, b.current.Bytes())
+	b.current.Reset()
+
+	select {
+	case b.queue <- event:
+	default:
+		b.drop()
+	}
+}
+
+// Dropped returns a channel that is closed once the client has been dropped,
+// either because it fell too far behind or because a write to it failed.
+func (b *BufferedWriter) Dropped() <-chan struct{} {
+	return b.dropped
+}
+
+// Close sends any queued events to the client (unless it has been dropped) and
+// waits for the background goroutine to finish.
+func (b *BufferedWriter) Close() {
+	if !b.closed {
+		b.closed = true
+		close(b.queue)
+	}
+
+	<-b.exited
+}
+
+func (b *BufferedWriter) drop() {
+	b.markDropped()
+	b.closed = true
+	close(b.queue)
+}
+
+func (b *BufferedWriter) markDropped() {
+	b.once.Do(func() { close(b.dropped) })
+}
+
+// run writes queued events to the underlying writer, flushing according to the
+// writer's config.  run exits once the queue is closed and drained, or
+// immediately once the client is dropped.
+func (b *BufferedWriter) run() {
+	defer close(b.exited)
+
+	var (
+		unflushed int
+		tick      <-chan time.Time
+	)
+
+	if b.config.FlushInterval > 0 {
+		ticker := time.NewTicker(b.config.FlushInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	flusher := b.w.(http.Flusher)
+	flush := func() {
+		if unflushed > 0 {
+			flusher.Flush()
+			unflushed = 0
+		}
+	}
+
+	for {
+		select {
+		case event, more := <-b.queue:
+			if !more {
+				flush()
+				return
+			}
+
+			select {
+			case <-b.dropped:
+				return
+			default:
+			}
+
+			n, err := b.w.Write(event)
+			unflushed += n
+			if err != nil {
+				b.markDropped()
+				return
+			}
+
+			if unflushed >= b.config.FlushBytes {
+				flush()
+			}
+		case <-tick:
+			flush()
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package sse
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	// EncodingGzip is the content coding for gzip compressed streams.
+	EncodingGzip = "gzip"
+	// EncodingDeflate is the content coding for deflate compressed streams.  As
+	// per RFC 7230, these are zlib streams.
+	EncodingDeflate = "deflate"
+)
+
+// compressor is the shared interface of gzip.Writer and zlib.Writer.
+type compressor interface {
+	io.WriteCloser
+	Flush() error
+}
+
+// CompressedWriter is an http.ResponseWriter that compresses everything written
+// to it.  Each Flush completes the compressed block for the data written so
+// far before flushing the underlying writer, so that every event can be
+// decompressed by the client as soon as it arrives.
+type CompressedWriter struct {
+	http.ResponseWriter
+	c compressor
+}
+
+// NegotiateEncoding returns the content coding to use for a stream sent in
+// response to r, based upon its Accept-Encoding header: EncodingGzip,
+// EncodingDeflate, or "" if the client accepts neither.
+func NegotiateEncoding(r *http.Request) string {
+	accepted := map[string]bool{}
+
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		params := strings.Split(part, ";")
+		coding := strings.ToLower(strings.TrimSpace(params[0]))
+
+		disabled := false
+		for _, param := range params[1:] {
+			if q := strings.Replace(param, " ", "", -1); q == "q=0" || q == "q=0.0" {
+				disabled = true
+			}
+		}
+
+		accepted[coding] = !disabled
+	}
+
+	switch {
+	case accepted[EncodingGzip]:
+		return EncodingGzip
+	case accepted[EncodingDeflate]:
+		return EncodingDeflate
+	default:
+		return ""
+	}
+}
+
+// Compress negotiates a content coding for the response to r and, if one is
+// acceptable, returns a CompressedWriter wrapping w along with the func that
+// must be called once the response is complete.  Otherwise, or if w cannot be
+// flushed, w is returned unchanged.  Compress must be called before any headers
+// are written.
+func Compress(w http.ResponseWriter, r *http.Request) (http.ResponseWriter, func()) {
+	if _, ok := w.(http.Flusher); !ok {
+		return w, func() {}
+	}
+
+	w.Header().Add("Vary", "Accept-Encoding")
+
+	encoding := NegotiateEncoding(r)
+
+	var c compressor
+	switch encoding {
+	case EncodingGzip:
+		c = gzip.NewWriter(w)
+	case EncodingDeflate:
+		c = zlib.NewWriter(w)
+	default:
+		return w, func() {}
+	}
+
+	w.Header().Set("Content-Encoding", encoding)
+	w.Header().Del("Content-Length")
+
+	result := &CompressedWriter{ResponseWriter: w, c: c}
+	return result, result.Close
+}
+
+// Write compresses p into the response.
+func (w *CompressedWriter) Write(p []byte) (int, error) {
+	return w.c.Write(p)
+}
+
+// Flush sends all data written so far to the client.
+func (w *CompressedWriter) Flush() {
+	w.c.Flush()
+
+	w.ResponseWriter.(http.Flusher).Flush()
+}
+
+// Close completes the compressed stream, writing any trailing data.
+func (w *CompressedWriter) Close() {
+	w.c.Close()
+	w.ResponseWriter.(http.Flusher).Flush()
+}
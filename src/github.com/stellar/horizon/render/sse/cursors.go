@@ -0,0 +1,41 @@
+package sse
+
+import (
+	"github.com/stellar/horizon/render/hal"
+)
+
+// NewCursorStream returns a stream that encodes, with enc, the ids of the
+// events sent through it, as the cursors of the endpoint at path, and the
+// paging tokens of their data, as hal.WithCursors does for the documents of
+// single responses, before sending them on s.  If enc is nil, s is returned
+// unchanged.
+func NewCursorStream(s Stream, enc hal.CursorEncoder, path string) Stream {
+	if enc == nil {
+		return s
+	}
+
+	return &cursorStream{Stream: s, enc: enc, path: path}
+}
+
+type cursorStream struct {
+	Stream
+	enc  hal.CursorEncoder
+	path string
+}
+
+func (s *cursorStream) Send(e Event) {
+	e.ID = s.enc.Encode(s.path, e.ID)
+
+	if e.Error == nil && e.Data != nil {
+		data, err := hal.EncodeCursors(e.Data, s.enc, s.path)
+		if err != nil {
+			// send the event as it is, so the client receives the
+			// serialization error
+			s.Stream.Send(e)
+			return
+		}
+		e.Data = data
+	}
+
+	s.Stream.Send(e)
+}
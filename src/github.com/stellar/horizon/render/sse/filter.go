@@ -0,0 +1,173 @@
+package sse
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/go-errors/errors"
+)
+
+// Filter is a parsed filter expression that is evaluated against the data of
+// events before they are sent to the client.  An expression is a list of terms
+// separated by `&`, all of which must match for an event to be sent.  Each term
+// is of the form `field=value` or `field!=value`, where field is the name of a
+// property of the event's json form (use dots to reach nested properties, e.g.
+// `asset.code`) and value may list several alternatives separated by `|`.  For
+// example:
+//
+//	type=payment&asset_code=USD|EUR
+//
+// A nil *Filter matches every event.
+type Filter struct {
+	terms []filterTerm
+}
+
+type filterTerm struct {
+	path   []string
+	values []string
+	negate bool
+}
+
+// ParseFilter parses the provided filter expression.  An empty expression
+// results in a nil filter.
+func ParseFilter(expr string) (*Filter, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, nil
+	}
+
+	result := &Filter{}
+
+	for _, raw := range strings.Split(expr, "&") {
+		var term filterTerm
+
+		sep := "="
+		if strings.Contains(raw, "!=") {
+			sep = "!="
+			term.negate = true
+		}
+
+		parts := strings.SplitN(raw, sep, 2)
+		if len(parts) != 2 {
+			return nil, errors.Errorf("invalid filter term %q: missing operator", raw)
+		}
+
+		field := strings.TrimSpace(parts[0])
+		if field == "" {
+			return nil, errors.Errorf("invalid filter term %q: missing field", raw)
+		}
+
+		term.path = strings.Split(field, ".")
+		for _, v := range strings.Split(parts[1], "|") {
+			term.values = append(term.values, strings.TrimSpace(v))
+		}
+
+		result.terms = append(result.terms, term)
+	}
+
+	return result, nil
+}
+
+// Match returns true if the provided event satisfies every term of the filter.
+// Error events always match, as they must always reach the client.
+func (f *Filter) Match(e Event) bool {
+	if f == nil || e.Error != nil {
+		return true
+	}
+
+	js, err := json.Marshal(e.Data)
+	if err != nil {
+		// let the event through, so the client receives the serialization error
+		return true
+	}
+
+	var doc interface{}
+	dec := json.NewDecoder(bytes.NewReader(js))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return true
+	}
+
+	for _, term := range f.terms {
+		if !term.match(doc) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (t filterTerm) match(doc interface{}) bool {
+	actual, found := lookup(doc, t.path)
+
+	matched := false
+	if found {
+		for _, v := range t.values {
+			if actual == v {
+				matched = true
+				break
+			}
+		}
+	}
+
+	return matched != t.negate
+}
+
+// lookup finds the value at path within doc, returning its string form.
+func lookup(doc interface{}, path []string) (string, bool) {
+	for _, key := range path {
+		obj, ok := doc.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+
+		doc, ok = obj[key]
+		if !ok {
+			return "", false
+		}
+	}
+
+	switch v := doc.(type) {
+	case string:
+		return v, true
+	case nil:
+		return "null", true
+	case map[string]interface{}, []interface{}:
+		return "", false
+	default:
+		return fmt.Sprint(v), true
+	}
+}
+
+// NewFilteredStream returns a Stream that only sends the events that match f
+// to the client, discarding the rest.  Discarded events still count towards
+// SentCount, so that actions paging through their records by the number of
+// events sent see the same count regardless of the filter.
+func NewFilteredStream(s Stream, f *Filter) Stream {
+	if f == nil {
+		return s
+	}
+
+	return &filteredStream{Stream: s, filter: f}
+}
+
+type filteredStream struct {
+	Stream
+	filter  *Filter
+	skipped int
+}
+
+func (s *filteredStream) Send(e Event) {
+	if !s.filter.Match(e) {
+		s.skipped++
+		return
+	}
+
+	s.Stream.Send(e)
+}
+
+func (s *filteredStream) SentCount() int {
+	return s.Stream.SentCount() + s.skipped
+}
@@ -0,0 +1,62 @@
+package sse
+
+import (
+	"time"
+)
+
+// Heartbeat signals when a stream has been idle for a configured interval,
+// indicating a keepalive should be sent.  A Heartbeat with a zero interval never
+// fires, which is convenient for disabling heartbeats (in tests, for example).
+type Heartbeat struct {
+	interval time.Duration
+	timer    *time.Timer
+}
+
+// NewHeartbeat returns a Heartbeat that fires after d has passed without a call
+// to Reset.
+func NewHeartbeat(d time.Duration) *Heartbeat {
+	result := &Heartbeat{interval: d}
+
+	if d > 0 {
+		result.timer = time.NewTimer(d)
+	}
+
+	return result
+}
+
+// C returns the channel that receives a value when the heartbeat fires.  The
+// channel is nil for disabled heartbeats, and will block forever when used in a
+// select statement.
+func (h *Heartbeat) C() <-chan time.Time {
+	if h.timer == nil {
+		return nil
+	}
+
+	return h.timer.C
+}
+
+// Reset restarts the idle interval.  Call it whenever data is sent to the
+// client, as well as after the heartbeat fires.
+func (h *Heartbeat) Reset() {
+	if h.timer == nil {
+		return
+	}
+
+	if !h.timer.Stop() {
+		select {
+		case <-h.timer.C:
+		default:
+		}
+	}
+
+	h.timer.Reset(h.interval)
+}
+
+// Stop releases the resources associated with this heartbeat.
+func (h *Heartbeat) Stop() {
+	if h.timer == nil {
+		return
+	}
+
+	h.timer.Stop()
+}
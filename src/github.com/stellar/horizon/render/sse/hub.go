@@ -0,0 +1,99 @@
+package sse
+
+import (
+	"sync"
+)
+
+// Hub shares the results of identical fetches made by streams between pumps.
+// Streams are triggered together on every pump, and streams of the same
+// resource with the same parameters would otherwise each make an identical
+// trip to the database.  Through a Hub, the first stream to fetch a given key
+// after a pump performs the fetch on behalf of every other stream, which
+// receive the same result.
+//
+// Results are shared, not copied: callers must not modify them.
+type Hub struct {
+	lock     sync.Mutex
+	pumpedFn func() <-chan struct{}
+	pumped   <-chan struct{}
+	entries  map[string]*hubEntry
+}
+
+type hubEntry struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+// NewHub returns a new, empty hub whose results are shared until the channel
+// returned by pumped closes, such as that of the Pump the streams of its
+// callers are driven by.  A nil pumped shares them until the default pump set
+// by SetPump sends.
+func NewHub(pumped func() <-chan struct{}) *Hub {
+	if pumped == nil {
+		pumped = Pumped
+	}
+
+	return &Hub{pumpedFn: pumped, entries: map[string]*hubEntry{}}
+}
+
+// Fetch returns the result of calling fn, sharing it with all other callers
+// that provide the same key until the next pump.  Callers that arrive while
+// the fetch is in progress wait for it to complete.  Failed fetches are not
+// shared beyond the callers waiting upon them.
+//
+// Results cannot be shared when no pump has been set, in which case, as with a
+// nil *Hub, fn is called directly.
+func (h *Hub) Fetch(key string, fn func() (interface{}, error)) (interface{}, error) {
+	if h == nil {
+		return fn()
+	}
+
+	pumped := h.pumpedFn()
+	if pumped == nil {
+		return fn()
+	}
+
+	h.lock.Lock()
+
+	// results from before the latest pump are stale, discard them
+	if h.pumped != pumped {
+		h.pumped = pumped
+		h.entries = map[string]*hubEntry{}
+	}
+
+	entry, ok := h.entries[key]
+	if ok {
+		h.lock.Unlock()
+		<-entry.done
+		return entry.value, entry.err
+	}
+
+	entry = &hubEntry{done: make(chan struct{})}
+	h.entries[key] = entry
+	h.lock.Unlock()
+
+	entry.value, entry.err = fn()
+	close(entry.done)
+
+	if entry.err != nil {
+		h.lock.Lock()
+		if h.entries[key] == entry {
+			delete(h.entries, key)
+		}
+		h.lock.Unlock()
+	}
+
+	return entry.value, entry.err
+}
+
+// Len returns the number of keys fetched since the last pump.
+func (h *Hub) Len() int {
+	if h == nil {
+		return 0
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return len(h.entries)
+}
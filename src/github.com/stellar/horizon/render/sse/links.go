@@ -0,0 +1,37 @@
+package sse
+
+import (
+	"github.com/stellar/horizon/render/hal"
+)
+
+// NewLinkedStream returns a stream that prefixes the links of the events sent
+// through it with prefix before sending them on s, as hal.WithLinkPrefix does
+// for the documents of single responses.  If prefix is empty, s is returned
+// unchanged.
+func NewLinkedStream(s Stream, prefix string) Stream {
+	if prefix == "" {
+		return s
+	}
+
+	return &linkedStream{Stream: s, prefix: prefix}
+}
+
+type linkedStream struct {
+	Stream
+	prefix string
+}
+
+func (s *linkedStream) Send(e Event) {
+	if e.Error == nil && e.Data != nil {
+		data, err := hal.PrefixLinks(e.Data, s.prefix)
+		if err != nil {
+			// send the event as it is, so the client receives the
+			// serialization error
+			s.Stream.Send(e)
+			return
+		}
+		e.Data = data
+	}
+
+	s.Stream.Send(e)
+}
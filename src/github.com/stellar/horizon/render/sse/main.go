@@ -4,8 +4,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
+	"github.com/go-errors/errors"
 	"github.com/stellar/horizon/log"
+	"github.com/stellar/horizon/render"
+	"github.com/stellar/horizon/render/problem"
 	"golang.org/x/net/context"
 )
 
@@ -43,6 +48,21 @@ var goodbyeEvent = Event{
 	Retry: 10,
 }
 
+// When the server is shutting down, we send this event in place of the
+// "Goodbye" event.  Its longer retry value gives the server time to stop
+// accepting connections, so that the reconnecting client reaches another
+// instance.
+var shutdownEvent = Event{
+	Data:  "shutdown",
+	Event: "close",
+	Retry: 1000,
+}
+
+// heartbeatComment is written to idle streams so that load balancers and
+// reverse proxies do not consider the connection dead.  Lines beginning with a
+// colon are ignored by EventSource clients.
+const heartbeatComment = ": keepalive\n\n"
+
 // Eventable represents an object that can be converted to an SSE compatible
 // event.
 type Eventable interface {
@@ -50,20 +70,89 @@ type Eventable interface {
 	SseEvent() Event
 }
 
+// Provider produces the channel of events that a Streamer delivers to a
+// client.  lastEventID is the id of the last event the client received, as
+// reported by LastEventID, and is empty for clients that are not resuming.  A
+// Provider should only emit events that occur after lastEventID.
+type Provider func(lastEventID string) <-chan Eventable
+
 // Streamer handles the work of turning a channel of Eventable objects
 // into a http response to a client.  Construct one and call `ServeHTTP` to do
-// so
+// so.
+//
+// If Data is nil, Resume is used to create the data channel from the client's
+// last event id, allowing reconnecting clients to continue where they left off.
+//
+// If Heartbeat is non-zero, a keepalive comment is written to the client
+// whenever that much time passes without any data being sent.
+//
+// If Buffer.Size is non-zero, events are sent to the client through a
+// BufferedWriter, so that a slow client cannot stall the goroutine feeding
+// Data.  Clients that fall more than Buffer.Size events behind are
+// disconnected.
+//
+// Serializer controls how event data is rendered, defaulting to JSON.
+//
+// If Compress is true, the stream is compressed when the client's
+// Accept-Encoding header allows it.
+//
+// Retry controls the retry hints sent to the client when the stream opens and
+// closes.
+//
+// Batch, when enabled, coalesces the events read from Data into batches.
 type Streamer struct {
-	Ctx  context.Context
-	Data <-chan Eventable
+	Ctx        context.Context
+	Data       <-chan Eventable
+	Resume     Provider
+	Heartbeat  time.Duration
+	Buffer     BufferConfig
+	Serializer Serializer
+	Compress   bool
+	Retry      RetryPolicy
+	Batch      BatchConfig
 }
 
 func (s *Streamer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
-	if !WritePreamble(s.Ctx, w) {
+	if s.Data == nil && s.Resume != nil {
+		s.Data = s.Resume(LastEventID(r))
+	}
+
+	if s.Compress {
+		var done func()
+		w, done = Compress(w, r)
+		defer done()
+	}
+
+	if !writePreamble(s.Ctx, w, s.Retry.hello()) {
 		return
 	}
 
+	// dropped remains nil, blocking forever, when unbuffered
+	var dropped <-chan struct{}
+	if s.Buffer.Size > 0 {
+		bw := NewBufferedWriter(w, s.Buffer)
+		defer bw.Close()
+		dropped = bw.Dropped()
+		w = bw
+	}
+
+	serializer := s.Serializer
+	if serializer == nil {
+		serializer = JSON
+	}
+
+	heartbeat := NewHeartbeat(s.Heartbeat)
+	defer heartbeat.Stop()
+
+	pending := batch{config: s.Batch}
+	flush := func() {
+		if e, ok := pending.take(); ok {
+			WriteEventWith(s.Ctx, w, e, serializer)
+			heartbeat.Reset()
+		}
+	}
+
 	// wait for data and stream it as it becomes available
 	// finish when either the client closes the connection
 	// or the data provider closes the channel
@@ -71,48 +160,119 @@ func (s *Streamer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		select {
 		case eventable, more := <-s.Data:
 			if !more {
-				WriteEvent(s.Ctx, w, goodbyeEvent)
+				flush()
+				WriteEvent(s.Ctx, w, s.Retry.goodbye())
 				return
 			}
-			WriteEvent(s.Ctx, w, eventable.SseEvent())
+
+			e := eventable.SseEvent()
+			if !s.Batch.Enabled() || e.Error != nil {
+				flush()
+				WriteEventWith(s.Ctx, w, e, serializer)
+				heartbeat.Reset()
+				continue
+			}
+
+			if !pending.fits(e) {
+				flush()
+			}
+			if pending.add(e) {
+				flush()
+			}
+		case <-pending.due():
+			flush()
+		case <-heartbeat.C():
+			WriteHeartbeat(w)
+			heartbeat.Reset()
+		case <-dropped:
+			log.Warn(s.Ctx, "dropping slow sse client")
+			return
 		case <-s.Ctx.Done():
 			return
 		}
 	}
 }
 
-func WritePreamble(ctx context.Context, w http.ResponseWriter) bool {
+// LastEventID returns the id of the last event a reconnecting client received,
+// read from the Last-Event-ID header or, for clients that cannot set headers,
+// the last_event_id query parameter.  Returns "" if neither is present.
+func LastEventID(r *http.Request) string {
+	if lei := r.Header.Get("Last-Event-ID"); lei != "" {
+		return lei
+	}
+
+	return r.URL.Query().Get("last_event_id")
+}
 
-	_, flushable := w.(http.Flusher)
+// WritePreamble writes the headers of an event stream to w, followed by the
+// hello event.  Returns false, having rendered an error, if w cannot stream.
+func WritePreamble(ctx context.Context, w http.ResponseWriter) bool {
+	return writePreamble(ctx, w, helloEvent)
+}
 
-	if !flushable {
-		//TODO: render a problem struct instead of simple string
-		http.Error(w, "Streaming Not Supported", http.StatusBadRequest)
+func writePreamble(ctx context.Context, w http.ResponseWriter, hello Event) bool {
+	if !render.Streamable(w) {
+		problem.Render(ctx, w, problem.StreamingNotSupported)
 		return false
 	}
 
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.WriteHeader(200)
 
-	WriteEvent(ctx, w, helloEvent)
+	WriteEvent(ctx, w, hello)
 
 	return true
 }
 
+// WriteHeartbeat writes a keepalive comment to the provided ResponseWriter and
+// flushes it.
+func WriteHeartbeat(w http.ResponseWriter) {
+	fmt.Fprint(w, heartbeatComment)
+	w.(http.Flusher).Flush()
+}
+
 // WriteEvent does the actual work of formatting an SSE compliant message
-// sending it over the provided ResponseWriter and flushing.
+// sending it over the provided ResponseWriter and flushing.  Event data is
+// serialized as JSON.
 func WriteEvent(ctx context.Context, w http.ResponseWriter, e Event) {
-	if e.Error != nil {
-		fmt.Fprint(w, "event: err\n")
-		fmt.Fprintf(w, "data: %s\n\n", e.Error.Error())
-		w.(http.Flusher).Flush()
-		log.Error(ctx, e.Error)
-		return
+	WriteEventWith(ctx, w, e, JSON)
+}
+
+// WriteEventWith is like WriteEvent, but serializes the event's data using the
+// provided serializer.  If the data cannot be serialized, an error event is sent
+// in its place.
+//
+// Error events are sent as an "err" event whose data is the problem document
+// that would be rendered for the error by a non-streaming request.
+func WriteEventWith(ctx context.Context, w http.ResponseWriter, e Event, s Serializer) {
+	if e.Error == nil {
+		data, err := s.Serialize(e.Data)
+		if err == nil {
+			writeEvent(w, e, data)
+			return
+		}
+
+		e = Event{Error: errors.Wrap(err, 1)}
 	}
 
+	log.Error(ctx, e.Error)
+
+	// a problem document always serializes, barring a bug in this package
+	js, err := json.Marshal(problem.Resolve(ctx, e.Error))
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Fprint(w, "event: err\n")
+	fmt.Fprintf(w, "data: %s\n\n", js)
+	w.(http.Flusher).Flush()
+}
+
+// writeEvent writes e, whose data has been serialized to data, to w.  Each line
+// of data is sent as a separate data field.
+func writeEvent(w http.ResponseWriter, e Event, data string) {
 	// TODO: add tests to ensure retry get's properly rendered
 	if e.Retry != 0 {
 		fmt.Fprintf(w, "retry: %d\n", e.Retry)
@@ -126,16 +286,10 @@ func WriteEvent(ctx context.Context, w http.ResponseWriter, e Event) {
 		fmt.Fprintf(w, "event: %s\n", e.Event)
 	}
 
-	fmt.Fprintf(w, "data: %s\n\n", getJSON(e.Data))
-	w.(http.Flusher).Flush()
-}
-
-func getJSON(val interface{}) string {
-	js, err := json.Marshal(val)
-
-	if err != nil {
-		panic(err)
+	for _, line := range strings.Split(data, "\n") {
+		fmt.Fprintf(w, "data: %s\n", line)
 	}
 
-	return string(js)
+	fmt.Fprint(w, "\n")
+	w.(http.Flusher).Flush()
 }
@@ -1,12 +1,20 @@
 package sse
 
 import (
+	"bytes"
+	"compress/gzip"
 	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/render/problem"
 	"github.com/stellar/horizon/test"
+	"golang.org/x/net/context"
 )
 
 func TestSsePackage(t *testing.T) {
@@ -20,7 +28,8 @@ func TestSsePackage(t *testing.T) {
 			{Event{Data: "test"}, "data: \"test\"\n\n"},
 			{Event{ID: "1", Data: "test"}, "id: 1\n"},
 			{Event{Retry: 1000, Data: "test"}, "retry: 1000\n"},
-			{Event{Error: errors.New("busted")}, "event: err\ndata: busted\n\n"},
+			{Event{Error: errors.New("busted")}, "event: err\ndata: {\"type\":\"https://stellar.org/horizon-errors/server_error\""},
+			{Event{Error: &problem.NotFound}, "event: err\ndata: {\"type\":\"https://stellar.org/horizon-errors/not_found\",\"title\":\"Resource Missing\",\"status\":404"},
 			{Event{Event: "test", Data: "test"}, "event: test\ndata: \"test\"\n\n"},
 		}
 
@@ -37,4 +46,678 @@ func TestSsePackage(t *testing.T) {
 		So(log.String(), ShouldContainSubstring, "level=error")
 		So(log.String(), ShouldContainSubstring, "busted")
 	})
+
+	Convey("sse.WriteEvent sends an error event for unserializable data", t, func() {
+		w := httptest.NewRecorder()
+		So(func() { WriteEvent(ctx, w, Event{ID: "1", Data: make(chan int)}) }, ShouldNotPanic)
+		So(w.Body.String(), ShouldStartWith, "event: err\ndata: {\"type\":\"https://stellar.org/horizon-errors/server_error\"")
+	})
+
+	Convey("sse.WriteEventWith", t, func() {
+		expectations := []struct {
+			Serializer Serializer
+			Data       interface{}
+			Expected   string
+		}{
+			{JSON, "test", "data: \"test\"\n\n"},
+			{Text, "pre-rendered", "data: pre-rendered\n\n"},
+			{Text, "two\nlines", "data: two\ndata: lines\n\n"},
+			{XDR, int32(1), "data: AAAAAQ==\n\n"},
+			{CSV, []string{"a", "b,c"}, "data: a,\"b,c\"\n\n"},
+			{CSV, 3, "event: err\ndata: {\"type\":\"https://stellar.org/horizon-errors/server_error\""},
+		}
+
+		for _, e := range expectations {
+			w := httptest.NewRecorder()
+			WriteEventWith(ctx, w, Event{Data: e.Data}, e.Serializer)
+			So(w.Body.String(), ShouldStartWith, e.Expected)
+		}
+	})
+
+	Convey("sse.LastEventID", t, func() {
+		r, _ := http.NewRequest("GET", "/ledgers", nil)
+		So(LastEventID(r), ShouldEqual, "")
+
+		r, _ = http.NewRequest("GET", "/ledgers?last_event_id=1234", nil)
+		So(LastEventID(r), ShouldEqual, "1234")
+
+		r.Header.Set("Last-Event-ID", "5678")
+		So(LastEventID(r), ShouldEqual, "5678")
+	})
+
+	Convey("sse.Streamer resumes from the client's last event id", t, func() {
+		var resumedFrom string
+		streamer := &Streamer{
+			Ctx: ctx,
+			Resume: func(lastEventID string) <-chan Eventable {
+				resumedFrom = lastEventID
+				data := make(chan Eventable, 1)
+				data <- Event{ID: "1235", Data: "test"}
+				close(data)
+				return data
+			},
+		}
+
+		r, _ := http.NewRequest("GET", "/ledgers", nil)
+		r.Header.Set("Last-Event-ID", "1234")
+		w := httptest.NewRecorder()
+		streamer.ServeHTTP(w, r)
+
+		So(resumedFrom, ShouldEqual, "1234")
+		So(w.Body.String(), ShouldContainSubstring, "id: 1235\n")
+	})
+
+	Convey("sse.Stream tracks the last sent event id", t, func() {
+		r, _ := http.NewRequest("GET", "/ledgers", nil)
+		r.Header.Set("Last-Event-ID", "1234")
+		stream, _ := NewStream(ctx, httptest.NewRecorder(), r)
+		So(stream.LastEventID(), ShouldEqual, "1234")
+
+		stream.Send(Event{ID: "1235", Data: "test"})
+		So(stream.LastEventID(), ShouldEqual, "1235")
+
+		stream.Send(Event{Data: "no id"})
+		So(stream.LastEventID(), ShouldEqual, "1235")
+	})
+
+	Convey("sse.Stream.Meta sends events outside the stream's sequence", t, func() {
+		r, _ := http.NewRequest("GET", "/ledgers", nil)
+		w := httptest.NewRecorder()
+		stream, _ := NewStream(ctx, w, r)
+		stream.Meta(Event{ID: "1234", Event: "status", Data: "ok"})
+
+		So(stream.SentCount(), ShouldEqual, 0)
+		So(stream.LastEventID(), ShouldEqual, "")
+		So(w.Body.String(), ShouldEndWith, "event: status\ndata: \"ok\"\n\n")
+		So(w.Body.String(), ShouldNotContainSubstring, "id: 1234")
+	})
+
+	Convey("sse.NewSerializedStream serializes event data with its serializer", t, func() {
+		r, _ := http.NewRequest("GET", "/ledgers", nil)
+		w := httptest.NewRecorder()
+		stream, _ := NewSerializedStream(ctx, w, r, RetryPolicy{}, PrettyJSON)
+		stream.Send(Event{ID: "1234", Data: map[string]int{"sequence": 1}})
+
+		So(w.Body.String(), ShouldEndWith, "id: 1234\ndata: {\ndata:   \"sequence\": 1\ndata: }\n\n")
+	})
+
+	Convey("sse.Stream.Shutdown sends a close event with a retry hint", t, func() {
+		r, _ := http.NewRequest("GET", "/ledgers", nil)
+		w := httptest.NewRecorder()
+		stream, _ := NewStream(ctx, w, r)
+		stream.Shutdown()
+
+		So(stream.IsDone(), ShouldBeTrue)
+		So(w.Body.String(), ShouldEndWith, "retry: 1000\nevent: close\ndata: \"shutdown\"\n\n")
+	})
+
+	Convey("sse.RetryPolicy", t, func() {
+		Convey("defaults to the standard retry hints", func() {
+			var policy RetryPolicy
+			So(policy.HelloRetry(), ShouldEqual, 1000)
+			So(policy.GoodbyeRetry(), ShouldEqual, 10)
+		})
+
+		Convey("raises the goodbye hint in proportion to pressure", func() {
+			pressure := 0.0
+			policy := RetryPolicy{
+				Goodbye:  100 * time.Millisecond,
+				Pressure: func() float64 { return pressure },
+				Max:      1100 * time.Millisecond,
+			}
+			So(policy.GoodbyeRetry(), ShouldEqual, 100)
+
+			pressure = 0.5
+			So(policy.GoodbyeRetry(), ShouldEqual, 600)
+
+			pressure = 2
+			So(policy.GoodbyeRetry(), ShouldEqual, 1100)
+		})
+
+		Convey("is used by streams", func() {
+			r, _ := http.NewRequest("GET", "/ledgers", nil)
+			w := httptest.NewRecorder()
+			policy := RetryPolicy{Hello: 2 * time.Second, Goodbye: 5 * time.Second}
+			stream, _ := NewStreamWith(ctx, w, r, policy)
+			stream.Done()
+
+			So(w.Body.String(), ShouldStartWith, "retry: 2000\n")
+			So(w.Body.String(), ShouldContainSubstring, "retry: 5000\nevent: close\n")
+		})
+	})
+
+	Convey("sse.ReplayBuffer", t, func() {
+		buffer := NewReplayBuffer(3, time.Minute)
+		buffer.Record("ledgers", "", Event{ID: "1", Data: "one"})
+		buffer.Record("ledgers", "1", Event{ID: "2", Data: "two"})
+		buffer.Record("ledgers", "2", Event{ID: "3", Data: "three"})
+
+		Convey("replays the events after the last event id", func() {
+			missed, ok := buffer.Since("ledgers", "1")
+			So(ok, ShouldBeTrue)
+			So(len(missed), ShouldEqual, 2)
+			So(missed[0].ID, ShouldEqual, "2")
+			So(missed[1].ID, ShouldEqual, "3")
+
+			missed, ok = buffer.Since("ledgers", "3")
+			So(ok, ShouldBeTrue)
+			So(missed, ShouldBeEmpty)
+
+			_, ok = buffer.Since("ledgers", "9")
+			So(ok, ShouldBeFalse)
+			_, ok = buffer.Since("transactions", "1")
+			So(ok, ShouldBeFalse)
+		})
+
+		Convey("only records events that follow the newest recorded", func() {
+			buffer.Record("ledgers", "1", Event{ID: "2b"})
+			buffer.Record("ledgers", "", Event{Data: "no id"})
+			buffer.Record("ledgers", "3", Event{ID: "4"})
+
+			missed, ok := buffer.Since("ledgers", "2")
+			So(ok, ShouldBeTrue)
+			So(len(missed), ShouldEqual, 2)
+			So(missed[1].ID, ShouldEqual, "4")
+
+			_, ok = buffer.Since("ledgers", "1")
+			So(ok, ShouldBeFalse)
+		})
+
+		Convey("does not replay expired events", func() {
+			buffer.MaxAge = time.Nanosecond
+			time.Sleep(time.Millisecond)
+			_, ok := buffer.Since("ledgers", "1")
+			So(ok, ShouldBeFalse)
+		})
+
+		Convey("sse.NewReplayStream", func() {
+			r, _ := http.NewRequest("GET", "/ledgers", nil)
+			r.Header.Set("Last-Event-ID", "1")
+			w := httptest.NewRecorder()
+			stream, _ := NewStream(ctx, w, r)
+
+			replay, lastID := NewReplayStream(stream, buffer, "ledgers")
+			So(lastID, ShouldEqual, "3")
+			So(replay.SentCount(), ShouldEqual, 0)
+			So(w.Body.String(), ShouldContainSubstring, "id: 2\n")
+			So(w.Body.String(), ShouldContainSubstring, "id: 3\n")
+
+			replay.Send(Event{ID: "4", Data: "four"})
+			So(replay.SentCount(), ShouldEqual, 1)
+
+			missed, _ := buffer.Since("ledgers", "3")
+			So(len(missed), ShouldEqual, 1)
+		})
+	})
+
+	Convey("sse.NewBatchedStream", t, func() {
+		r, _ := http.NewRequest("GET", "/trades", nil)
+		w := httptest.NewRecorder()
+		inner, _ := NewStream(ctx, w, r)
+
+		So(NewBatchedStream(inner, BatchConfig{}), ShouldEqual, inner)
+
+		stream := NewBatchedStream(inner, BatchConfig{Size: 2}).(*BatchedStream)
+
+		Convey("sends a batch once it fills", func() {
+			stream.Send(Event{ID: "1", Data: "one"})
+			So(inner.SentCount(), ShouldEqual, 0)
+			So(stream.SentCount(), ShouldEqual, 1)
+
+			stream.Send(Event{ID: "2", Data: "two"})
+			So(inner.SentCount(), ShouldEqual, 1)
+			So(w.Body.String(), ShouldContainSubstring, "id: 2\ndata: [\"one\",\"two\"]\n\n")
+		})
+
+		Convey("starts a new batch for events with another name", func() {
+			stream.Send(Event{ID: "1", Event: "ledgers", Data: "one"})
+			stream.Send(Event{ID: "2", Event: "payments", Data: "two"})
+			So(inner.SentCount(), ShouldEqual, 1)
+			So(w.Body.String(), ShouldContainSubstring, "event: ledgers\ndata: [\"one\"]\n\n")
+		})
+
+		Convey("flushes pending events before the stream ends", func() {
+			stream.Send(Event{ID: "1", Data: "one"})
+			stream.Done()
+			So(w.Body.String(), ShouldContainSubstring, "data: [\"one\"]\n\n")
+			So(stream.IsDone(), ShouldBeTrue)
+		})
+
+		Convey("becomes due once its window passes", func() {
+			var none *BatchedStream
+			So(none.Due(), ShouldBeNil)
+
+			stream := NewBatchedStream(inner, BatchConfig{Window: time.Millisecond}).(*BatchedStream)
+			So(stream.Due(), ShouldBeNil)
+
+			stream.Send(Event{ID: "1", Data: "one"})
+			<-stream.Due()
+			stream.Flush()
+			So(inner.SentCount(), ShouldEqual, 1)
+		})
+	})
+
+	Convey("sse.Streamer batches events", t, func() {
+		data := make(chan Eventable, 3)
+		data <- Event{ID: "1", Data: "one"}
+		data <- Event{ID: "2", Data: "two"}
+		data <- Event{ID: "3", Data: "three"}
+		close(data)
+
+		r, _ := http.NewRequest("GET", "/trades", nil)
+		w := httptest.NewRecorder()
+		streamer := &Streamer{Ctx: ctx, Data: data, Batch: BatchConfig{Size: 2}}
+		streamer.ServeHTTP(w, r)
+
+		So(w.Body.String(), ShouldContainSubstring, "id: 2\ndata: [\"one\",\"two\"]\n\n")
+		So(w.Body.String(), ShouldContainSubstring, "id: 3\ndata: [\"three\"]\n\n")
+	})
+
+	Convey("sse.ParseFilter", t, func() {
+		f, err := ParseFilter("")
+		So(err, ShouldBeNil)
+		So(f, ShouldBeNil)
+
+		_, err = ParseFilter("type")
+		So(err, ShouldNotBeNil)
+
+		_, err = ParseFilter("=payment")
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("sse.Filter.Match", t, func() {
+		payment := Event{Data: map[string]interface{}{
+			"type":       "payment",
+			"asset_code": "USD",
+			"amount":     10,
+			"asset":      map[string]interface{}{"issuer": "GABC"},
+		}}
+
+		expectations := []struct {
+			Expr    string
+			Matches bool
+		}{
+			{"type=payment", true},
+			{"type=create_account", false},
+			{"type=payment&asset_code=USD", true},
+			{"type=payment&asset_code=EUR", false},
+			{"asset_code=EUR|USD", true},
+			{"type!=payment", false},
+			{"memo!=hello", true},
+			{"memo=hello", false},
+			{"amount=10", true},
+			{"asset.issuer=GABC", true},
+			{"asset=GABC", false},
+		}
+
+		for _, e := range expectations {
+			f, err := ParseFilter(e.Expr)
+			So(err, ShouldBeNil)
+			So(f.Match(payment), ShouldEqual, e.Matches)
+		}
+
+		var nilFilter *Filter
+		So(nilFilter.Match(payment), ShouldBeTrue)
+
+		f, _ := ParseFilter("type=create_account")
+		So(f.Match(Event{Error: errors.New("busted")}), ShouldBeTrue)
+	})
+
+	Convey("sse.NewFilteredStream", t, func() {
+		r, _ := http.NewRequest("GET", "/ledgers", nil)
+		w := httptest.NewRecorder()
+		inner, _ := NewStream(ctx, w, r)
+		f, _ := ParseFilter("type=payment")
+		stream := NewFilteredStream(inner, f)
+
+		stream.Send(Event{ID: "1", Data: map[string]string{"type": "create_account"}})
+		stream.Send(Event{ID: "2", Data: map[string]string{"type": "payment"}})
+
+		So(stream.SentCount(), ShouldEqual, 2)
+		So(inner.SentCount(), ShouldEqual, 1)
+		So(w.Body.String(), ShouldNotContainSubstring, "id: 1\n")
+		So(w.Body.String(), ShouldContainSubstring, "id: 2\n")
+
+		So(NewFilteredStream(inner, nil), ShouldEqual, inner)
+	})
+
+	Convey("sse.NewLinkedStream", t, func() {
+		r, _ := http.NewRequest("GET", "/ledgers", nil)
+		w := httptest.NewRecorder()
+		inner, _ := NewStream(ctx, w, r)
+		stream := NewLinkedStream(inner, "/testnet")
+
+		stream.Send(Event{ID: "1", Data: map[string]interface{}{
+			"_links": map[string]interface{}{"self": map[string]string{"href": "/ledgers/1"}},
+		}})
+
+		So(inner.SentCount(), ShouldEqual, 1)
+		So(w.Body.String(), ShouldContainSubstring, `"href":"/testnet/ledgers/1"`)
+		So(NewLinkedStream(inner, ""), ShouldEqual, inner)
+	})
+
+	Convey("sse.NewCursorStream", t, func() {
+		r, _ := http.NewRequest("GET", "/ledgers", nil)
+		w := httptest.NewRecorder()
+		inner, _ := NewStream(ctx, w, r)
+		stream := NewCursorStream(inner, testCursors{}, "/ledgers")
+
+		stream.Send(Event{ID: "1", Data: map[string]interface{}{"paging_token": "1"}})
+
+		So(inner.SentCount(), ShouldEqual, 1)
+		So(w.Body.String(), ShouldContainSubstring, "id: /ledgers:1\n")
+		So(w.Body.String(), ShouldContainSubstring, `"paging_token":"/ledgers:1"`)
+		So(NewCursorStream(inner, nil, "/ledgers"), ShouldEqual, inner)
+	})
+
+	Convey("sse.NegotiateEncoding", t, func() {
+		expectations := map[string]string{
+			"":                         "",
+			"identity":                 "",
+			"gzip":                     EncodingGzip,
+			"deflate, gzip;q=0.8":      EncodingGzip,
+			"deflate":                  EncodingDeflate,
+			"gzip;q=0, deflate":        EncodingDeflate,
+			"GZIP":                     EncodingGzip,
+			"br, gzip;q=0,deflate;q=0": "",
+		}
+
+		for header, expected := range expectations {
+			r, _ := http.NewRequest("GET", "/ledgers", nil)
+			r.Header.Set("Accept-Encoding", header)
+			So(NegotiateEncoding(r), ShouldEqual, expected)
+		}
+	})
+
+	Convey("sse.Compress", t, func() {
+		Convey("leaves the response alone when the client doesn't accept compression", func() {
+			r, _ := http.NewRequest("GET", "/ledgers", nil)
+			w := httptest.NewRecorder()
+			cw, done := Compress(w, r)
+			done()
+			So(cw, ShouldEqual, w)
+			So(w.Header().Get("Content-Encoding"), ShouldEqual, "")
+		})
+
+		Convey("makes every event readable as soon as it is flushed", func() {
+			r, _ := http.NewRequest("GET", "/ledgers", nil)
+			r.Header.Set("Accept-Encoding", "gzip")
+			w := httptest.NewRecorder()
+			cw, done := Compress(w, r)
+			So(w.Header().Get("Content-Encoding"), ShouldEqual, "gzip")
+
+			WriteEvent(ctx, cw, Event{ID: "1", Data: "test"})
+
+			gz, err := gzip.NewReader(bytes.NewReader(w.Body.Bytes()))
+			So(err, ShouldBeNil)
+			buf := make([]byte, 64)
+			n, _ := io.ReadAtLeast(gz, buf, len("id: 1\ndata: \"test\"\n\n"))
+			So(string(buf[:n]), ShouldEqual, "id: 1\ndata: \"test\"\n\n")
+
+			done()
+			gz, _ = gzip.NewReader(bytes.NewReader(w.Body.Bytes()))
+			all, err := ioutil.ReadAll(gz)
+			So(err, ShouldBeNil)
+			So(string(all), ShouldEqual, "id: 1\ndata: \"test\"\n\n")
+		})
+	})
+
+	Convey("sse.WriteHeartbeat writes a comment", t, func() {
+		w := httptest.NewRecorder()
+		WriteHeartbeat(w)
+		So(w.Body.String(), ShouldEqual, ": keepalive\n\n")
+	})
+
+	Convey("sse.Heartbeat with a zero interval never fires", t, func() {
+		heartbeat := NewHeartbeat(0)
+		So(heartbeat.C(), ShouldBeNil)
+		heartbeat.Reset()
+		heartbeat.Stop()
+	})
+
+	Convey("sse.Streamer sends keepalives while idle", t, func() {
+		data := make(chan Eventable)
+		streamer := &Streamer{
+			Ctx:       ctx,
+			Data:      data,
+			Heartbeat: 5 * time.Millisecond,
+		}
+
+		go func() {
+			time.Sleep(30 * time.Millisecond)
+			close(data)
+		}()
+
+		r, _ := http.NewRequest("GET", "/ledgers", nil)
+		w := httptest.NewRecorder()
+		streamer.ServeHTTP(w, r)
+
+		So(w.Body.String(), ShouldContainSubstring, ": keepalive\n\n")
+		So(w.Body.String(), ShouldContainSubstring, "event: close\n")
+	})
+
+	Convey("sse.BufferedWriter", t, func() {
+		Convey("sends queued events on Close", func() {
+			w := httptest.NewRecorder()
+			bw := NewBufferedWriter(w, BufferConfig{Size: 10, FlushBytes: 1024})
+			WriteEvent(ctx, bw, Event{ID: "1", Data: "one"})
+			WriteEvent(ctx, bw, Event{ID: "2", Data: "two"})
+			bw.Close()
+
+			So(w.Body.String(), ShouldEqual, "id: 1\ndata: \"one\"\n\nid: 2\ndata: \"two\"\n\n")
+			So(w.Flushed, ShouldBeTrue)
+		})
+
+		Convey("drops clients that fall behind", func() {
+			w := &blockingWriter{ResponseRecorder: httptest.NewRecorder(), unblock: make(chan struct{})}
+			bw := NewBufferedWriter(w, BufferConfig{Size: 1})
+
+			for i := 0; i < 3; i++ {
+				WriteEvent(ctx, bw, Event{Data: i})
+			}
+
+			select {
+			case <-bw.Dropped():
+			case <-time.After(time.Second):
+				t.Fatal("slow client was not dropped")
+			}
+
+			close(w.unblock)
+			bw.Close()
+		})
+	})
+
+	Convey("sse.ConnectionRegistry", t, func() {
+		registry := NewConnectionRegistry(3, 2)
+		request := func(addr string) *http.Request {
+			r, _ := http.NewRequest("GET", "/ledgers", nil)
+			r.RemoteAddr = addr
+			return r
+		}
+
+		release1, err := registry.Acquire(request("127.0.0.1:1000"))
+		So(err, ShouldBeNil)
+		_, err = registry.Acquire(request("127.0.0.1:1001"))
+		So(err, ShouldBeNil)
+
+		_, err = registry.Acquire(request("127.0.0.1:1002"))
+		So(err, ShouldEqual, ErrTooManyClientConnections)
+
+		_, err = registry.Acquire(request("127.0.0.2:1000"))
+		So(err, ShouldBeNil)
+
+		_, err = registry.Acquire(request("127.0.0.3:1000"))
+		So(err, ShouldEqual, ErrTooManyConnections)
+
+		So(registry.Count(), ShouldEqual, 3)
+		So(registry.ClientCount(), ShouldEqual, 2)
+		So(registry.CountForIP("127.0.0.1"), ShouldEqual, 2)
+
+		release1()
+		release1()
+		So(registry.Count(), ShouldEqual, 2)
+		So(registry.CountForIP("127.0.0.1"), ShouldEqual, 1)
+
+		_, err = registry.Acquire(request("127.0.0.3:1000"))
+		So(err, ShouldBeNil)
+
+		Convey("Shutdown waits for open streams to be released", func() {
+			registry := NewConnectionRegistry(0, 0)
+			release, err := registry.Acquire(request("127.0.0.1:1000"))
+			So(err, ShouldBeNil)
+
+			done := make(chan error)
+			go func() {
+				ctx, cancel := context.WithTimeout(ctx, time.Second)
+				defer cancel()
+				done <- registry.Shutdown(ctx)
+			}()
+
+			<-registry.Closing()
+			_, err = registry.Acquire(request("127.0.0.2:1000"))
+			So(err, ShouldEqual, ErrShuttingDown)
+
+			release()
+			So(<-done, ShouldBeNil)
+		})
+
+		Convey("Shutdown gives up once its context is done", func() {
+			ctx, cancel := context.WithTimeout(ctx, 5*time.Millisecond)
+			defer cancel()
+			So(registry.Shutdown(ctx), ShouldEqual, context.DeadlineExceeded)
+		})
+
+		Convey("reports pressure relative to its maximum", func() {
+			So(registry.Pressure(), ShouldEqual, 1)
+			So(NewConnectionRegistry(0, 0).Pressure(), ShouldEqual, 0)
+		})
+
+		Convey("a nil registry allows everything", func() {
+			var registry *ConnectionRegistry
+			release, err := registry.Acquire(request("127.0.0.1:1000"))
+			So(err, ShouldBeNil)
+			release()
+			So(registry.Count(), ShouldEqual, 0)
+		})
+	})
+}
+
+// blockingWriter is a ResponseWriter whose writes block until unblock is
+// closed, simulating a slow client.
+type blockingWriter struct {
+	*httptest.ResponseRecorder
+	unblock chan struct{}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	<-w.unblock
+	return w.ResponseRecorder.Write(p)
+}
+
+func TestHub(t *testing.T) {
+	pump := make(chan struct{})
+	SetPump(test.Context(), pump)
+
+	Convey("sse.Hub", t, func() {
+		hub := NewHub(nil)
+		calls := 0
+		fetch := func() (interface{}, error) {
+			calls++
+			return calls, nil
+		}
+
+		v, err := hub.Fetch("ledgers", fetch)
+		So(err, ShouldBeNil)
+		So(v, ShouldEqual, 1)
+
+		Convey("shares results between pumps", func() {
+			v, _ := hub.Fetch("ledgers", fetch)
+			So(v, ShouldEqual, 1)
+			So(calls, ShouldEqual, 1)
+
+			v, _ = hub.Fetch("transactions", fetch)
+			So(v, ShouldEqual, 2)
+			So(hub.Len(), ShouldEqual, 2)
+		})
+
+		Convey("fetches again after a pump", func() {
+			next := Pumped()
+			pump <- struct{}{}
+			<-next
+
+			v, _ := hub.Fetch("ledgers", fetch)
+			So(v, ShouldEqual, 2)
+			So(hub.Len(), ShouldEqual, 1)
+		})
+
+		Convey("does not share failures", func() {
+			_, err := hub.Fetch("effects", func() (interface{}, error) {
+				return nil, errors.New("busted")
+			})
+			So(err, ShouldNotBeNil)
+
+			v, err := hub.Fetch("effects", fetch)
+			So(err, ShouldBeNil)
+			So(v, ShouldEqual, 2)
+		})
+
+		Convey("is invalidated by the pump it is given", func() {
+			input := make(chan struct{})
+			p := NewPump(test.Context(), input)
+			hub := NewHub(p.Pumped)
+
+			v, _ := hub.Fetch("ledgers", fetch)
+			So(v, ShouldEqual, 2)
+
+			// the default pump leaves the results of the hub shared
+			next := Pumped()
+			pump <- struct{}{}
+			<-next
+			v, _ = hub.Fetch("ledgers", fetch)
+			So(v, ShouldEqual, 2)
+
+			next = p.Pumped()
+			input <- struct{}{}
+			<-next
+			v, _ = hub.Fetch("ledgers", fetch)
+			So(v, ShouldEqual, 3)
+		})
+
+		Convey("a nil hub always fetches", func() {
+			var hub *Hub
+			hub.Fetch("ledgers", fetch)
+			So(calls, ShouldEqual, 2)
+		})
+	})
+}
+
+func TestPump(t *testing.T) {
+	Convey("sse.Pump", t, func() {
+		input := make(chan struct{})
+		other := make(chan struct{})
+		p := NewPump(test.Context(), input)
+		o := NewPump(test.Context(), other)
+
+		next, otherNext := p.Pumped(), o.Pumped()
+		input <- struct{}{}
+		<-next
+
+		So(p.Pumped(), ShouldNotEqual, next)
+		select {
+		case <-otherNext:
+			t.Error("pumped by the input of another pump")
+		default:
+		}
+	})
+}
+
+// testCursors encodes paging tokens as the path they were issued for and the
+// token, separated by a colon.
+type testCursors struct{}
+
+func (testCursors) Encode(path, token string) string {
+	if token == "" {
+		return ""
+	}
+	return path + ":" + token
 }
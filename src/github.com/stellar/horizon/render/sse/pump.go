@@ -7,10 +7,45 @@ import (
 	"golang.org/x/net/context"
 )
 
-var pump <-chan struct{}
+// Pump drives the streaming responses of a source of pumps, such as the
+// ledgers ingested into a history database.  Everytime its input sends, the
+// open connections waiting on it are triggered to run their queries again and
+// deliver any new results to clients.
+type Pump struct {
+	ctx      context.Context
+	input    <-chan struct{}
+	lock     sync.Mutex
+	nextTick chan struct{}
+}
+
+// defaultPump drives the streams waiting on Pumped, while running is true
+var defaultPump *Pump
+var running bool
 var lock sync.Mutex
-var ctx context.Context
-var nextTick chan struct{}
+
+// NewPump starts a pump driven by p, until p closes or ctx is cancelled.
+func NewPump(ctx context.Context, p <-chan struct{}) *Pump {
+	if p == nil {
+		panic("cannot set a null pump")
+	}
+
+	result := &Pump{
+		ctx:      ctx,
+		input:    p,
+		nextTick: make(chan struct{}),
+	}
+
+	go result.run()
+	return result
+}
+
+// Pumped returns a channel that will be closed the next time the input of the
+// pump sends.
+func (p *Pump) Pumped() <-chan struct{} {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.nextTick
+}
 
 // SetPump established the pump that will be used to drive streaming responses.
 // Everytime the provided channel sends any open connections will be triggered
@@ -23,42 +58,58 @@ func SetPump(c context.Context, p <-chan struct{}) {
 	lock.Lock()
 	defer lock.Unlock()
 
-	ctx = c
-	nextTick = make(chan struct{})
-
-	if pump != nil {
+	if running {
 		panic("cannot set sse pump twice")
 	}
 
-	pump = p
+	defaultPump = NewPump(c, p)
+	running = true
 
-	go run()
+	// the pump may be set again once its context is cancelled
+	go func(pump *Pump) {
+		<-c.Done()
+		lock.Lock()
+		if defaultPump == pump {
+			running = false
+		}
+		lock.Unlock()
+	}(defaultPump)
 }
 
 // Pumped returns a channel that will be closed the next time the input pump
 // sends.  It can be used similar to `ctx.Done()`, like so:  `<-sse.Pumped()`
 func Pumped() <-chan struct{} {
-	return nextTick
+	lock.Lock()
+	p := defaultPump
+	lock.Unlock()
+
+	if p == nil {
+		return nil
+	}
+
+	return p.Pumped()
 }
 
 // run is the workhorse of the stream pump system.  It facilitates the triggering
 // of open streams by closing a new channel every time the input pump sends.
-func run() {
+func (p *Pump) run() {
 	for {
 		select {
-		case _, more := <-pump:
-			log.Debug(ctx, "sse pump")
+		case _, more := <-p.input:
+			log.Debug(p.ctx, "sse pump")
+
+			p.lock.Lock()
+			prev := p.nextTick
+			p.nextTick = make(chan struct{})
+			p.lock.Unlock()
 
-			prev := nextTick
-			nextTick = make(chan struct{})
 			// trigger all listeners by closing the nextTick channel
 			close(prev)
 
 			if !more {
 				return
 			}
-		case <-ctx.Done():
-			pump = nil
+		case <-p.ctx.Done():
 			return
 		}
 	}
@@ -0,0 +1,200 @@
+package sse
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+
+	"golang.org/x/net/context"
+)
+
+var (
+	// ErrTooManyConnections is returned by ConnectionRegistry.Acquire when the
+	// server is already serving its maximum number of streams.
+	ErrTooManyConnections = errors.New("too many streaming connections")
+	// ErrTooManyClientConnections is returned by ConnectionRegistry.Acquire when
+	// the requesting client already has its maximum number of streams open.
+	ErrTooManyClientConnections = errors.New("too many streaming connections from client")
+	// ErrShuttingDown is returned by ConnectionRegistry.Acquire once Shutdown
+	// has been called.
+	ErrShuttingDown = errors.New("streaming is shutting down")
+)
+
+// ConnectionRegistry tracks the streams open across the server, keyed by the IP
+// of the client that opened them, and enforces limits upon their number.  A
+// limit of zero means no limit.  A nil *ConnectionRegistry tracks nothing and
+// allows every connection.
+type ConnectionRegistry struct {
+	MaxConnections      int
+	MaxConnectionsPerIP int
+
+	lock     sync.Mutex
+	total    int
+	byIP     map[string]int
+	closing  chan struct{}
+	drained  chan struct{}
+	shutdown sync.Once
+}
+
+// NewConnectionRegistry returns a registry that allows at most max streams in
+// total and at most maxPerIP streams from any single client.
+func NewConnectionRegistry(max, maxPerIP int) *ConnectionRegistry {
+	return &ConnectionRegistry{
+		MaxConnections:      max,
+		MaxConnectionsPerIP: maxPerIP,
+		byIP:                map[string]int{},
+		closing:             make(chan struct{}),
+		drained:             make(chan struct{}),
+	}
+}
+
+// Acquire registers a new stream for the client making the provided request,
+// returning an error if doing so would exceed one of the registry's limits.  On
+// success, the returned func must be called once the stream is closed.
+func (cr *ConnectionRegistry) Acquire(r *http.Request) (func(), error) {
+	if cr == nil {
+		return func() {}, nil
+	}
+
+	ip := remoteIP(r)
+
+	cr.lock.Lock()
+	defer cr.lock.Unlock()
+
+	if cr.isClosing() {
+		return nil, ErrShuttingDown
+	}
+
+	if cr.MaxConnections > 0 && cr.total >= cr.MaxConnections {
+		return nil, ErrTooManyConnections
+	}
+
+	if cr.MaxConnectionsPerIP > 0 && cr.byIP[ip] >= cr.MaxConnectionsPerIP {
+		return nil, ErrTooManyClientConnections
+	}
+
+	cr.total++
+	cr.byIP[ip]++
+
+	var once sync.Once
+	return func() { once.Do(func() { cr.release(ip) }) }, nil
+}
+
+// Closing returns a channel that is closed once Shutdown has been called.
+// Streams should watch it, calling their Shutdown method when it closes.  The
+// channel is nil, blocking forever, for a nil registry.
+func (cr *ConnectionRegistry) Closing() <-chan struct{} {
+	if cr == nil {
+		return nil
+	}
+
+	return cr.closing
+}
+
+// Shutdown asks every open stream to close, informing clients that they should
+// reconnect later, and waits for them to do so or for ctx to be done.  Once
+// Shutdown is called, no new streams may be acquired.
+func (cr *ConnectionRegistry) Shutdown(ctx context.Context) error {
+	if cr == nil {
+		return nil
+	}
+
+	cr.shutdown.Do(func() {
+		cr.lock.Lock()
+		defer cr.lock.Unlock()
+
+		close(cr.closing)
+		if cr.total == 0 {
+			close(cr.drained)
+		}
+	})
+
+	select {
+	case <-cr.drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Count returns the number of streams currently open.
+func (cr *ConnectionRegistry) Count() int {
+	if cr == nil {
+		return 0
+	}
+
+	cr.lock.Lock()
+	defer cr.lock.Unlock()
+	return cr.total
+}
+
+// Pressure returns the proportion of the server's maximum number of streams
+// that are open, or 0 if the number of streams is unlimited.  It is suitable
+// for use as RetryPolicy.Pressure.
+func (cr *ConnectionRegistry) Pressure() float64 {
+	if cr == nil || cr.MaxConnections <= 0 {
+		return 0
+	}
+
+	return float64(cr.Count()) / float64(cr.MaxConnections)
+}
+
+// ClientCount returns the number of distinct clients with open streams.
+func (cr *ConnectionRegistry) ClientCount() int {
+	if cr == nil {
+		return 0
+	}
+
+	cr.lock.Lock()
+	defer cr.lock.Unlock()
+	return len(cr.byIP)
+}
+
+// CountForIP returns the number of streams currently open by the client at ip.
+func (cr *ConnectionRegistry) CountForIP(ip string) int {
+	if cr == nil {
+		return 0
+	}
+
+	cr.lock.Lock()
+	defer cr.lock.Unlock()
+	return cr.byIP[ip]
+}
+
+func (cr *ConnectionRegistry) release(ip string) {
+	cr.lock.Lock()
+	defer cr.lock.Unlock()
+
+	cr.total--
+	cr.byIP[ip]--
+
+	if cr.byIP[ip] <= 0 {
+		delete(cr.byIP, ip)
+	}
+
+	if cr.total == 0 && cr.isClosing() {
+		close(cr.drained)
+	}
+}
+
+func (cr *ConnectionRegistry) isClosing() bool {
+	select {
+	case <-cr.closing:
+		return true
+	default:
+		return false
+	}
+}
+
+// remoteIP returns the ip portion of the request's remote address.  Any
+// X-Forwarded-For header will already have been applied to RemoteAddr by the
+// xff middleware.
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
@@ -0,0 +1,175 @@
+package sse
+
+import (
+	"sync"
+	"time"
+)
+
+// ReplayBuffer keeps the most recent events sent on each topic in memory, so
+// that clients reconnecting shortly after being disconnected can be sent the
+// events they missed without a trip to the database.  A topic identifies a
+// sequence of events that every stream subscribed to it receives in the same
+// order, e.g. the path and params of the request that opened the stream.
+//
+// The events kept for a topic are always contiguous: an event is only recorded
+// when it follows the newest event already recorded, so streams that are
+// catching up from an older cursor do not disturb the buffer.
+//
+// A nil *ReplayBuffer records nothing.
+type ReplayBuffer struct {
+	// Size is the maximum number of events kept for each topic.
+	Size int
+
+	// MaxAge is how long after an event is recorded that a client whose last
+	// event it was may be replayed from the buffer.
+	MaxAge time.Duration
+
+	lock   sync.Mutex
+	topics map[string][]replayEntry
+}
+
+type replayEntry struct {
+	event Event
+	at    time.Time
+}
+
+// NewReplayBuffer returns a new, empty buffer keeping up to size events per
+// topic for maxAge.
+func NewReplayBuffer(size int, maxAge time.Duration) *ReplayBuffer {
+	return &ReplayBuffer{
+		Size:   size,
+		MaxAge: maxAge,
+		topics: map[string][]replayEntry{},
+	}
+}
+
+// Record adds e to the events kept for topic, provided that prevID, the id of
+// the event sent before e on the recording stream, is the newest recorded id.
+// Events without an id, and error events, are never recorded.
+func (b *ReplayBuffer) Record(topic, prevID string, e Event) {
+	if b == nil || b.Size <= 0 || e.ID == "" || e.Error != nil {
+		return
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := time.Now()
+	entries := b.topics[topic]
+
+	switch {
+	case len(entries) == 0, b.expired(entries[len(entries)-1], now):
+		// start afresh, anything kept is too old to be replayed
+		entries = nil
+	case entries[len(entries)-1].event.ID != prevID:
+		return
+	}
+
+	entries = append(entries, replayEntry{event: e, at: now})
+	if len(entries) > b.Size {
+		entries = append([]replayEntry(nil), entries[len(entries)-b.Size:]...)
+	}
+
+	b.topics[topic] = entries
+}
+
+// Since returns the events recorded for topic after the event identified by
+// lastID.  Returns false if that event is not in the buffer, or was recorded
+// too long ago, in which case the missed events cannot be replayed.
+func (b *ReplayBuffer) Since(topic, lastID string) ([]Event, bool) {
+	if b == nil || lastID == "" {
+		return nil, false
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	entries := b.topics[topic]
+	now := time.Now()
+
+	for i, entry := range entries {
+		if entry.event.ID != lastID {
+			continue
+		}
+
+		if b.expired(entry, now) {
+			return nil, false
+		}
+
+		result := make([]Event, 0, len(entries)-i-1)
+		for _, missed := range entries[i+1:] {
+			result = append(result, missed.event)
+		}
+		return result, true
+	}
+
+	return nil, false
+}
+
+// Len returns the number of topics with recorded events.
+func (b *ReplayBuffer) Len() int {
+	if b == nil {
+		return 0
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return len(b.topics)
+}
+
+func (b *ReplayBuffer) expired(entry replayEntry, now time.Time) bool {
+	return b.MaxAge > 0 && now.Sub(entry.at) > b.MaxAge
+}
+
+// NewReplayStream sends s the events recorded in b for topic since the last
+// event id of s, then returns a Stream that records every event sent on it in
+// b.  The returned id is that of the last event replayed, or "" if the missed
+// events could not be replayed; when non-empty, the caller should continue the
+// stream from that id rather than from s.LastEventID().
+//
+// Replayed events are not included in the returned stream's SentCount, which
+// counts only the events sent after the replay.
+func NewReplayStream(s Stream, b *ReplayBuffer, topic string) (Stream, string) {
+	if b == nil {
+		return s, ""
+	}
+
+	lastID := s.LastEventID()
+	result := &replayStream{Stream: s, buffer: b, topic: topic, prev: lastID}
+
+	missed, ok := b.Since(topic, lastID)
+	if !ok {
+		return result, ""
+	}
+
+	before := s.SentCount()
+	for _, e := range missed {
+		s.Send(e)
+		lastID = e.ID
+	}
+
+	result.prev = lastID
+	result.offset = s.SentCount() - before
+	return result, lastID
+}
+
+type replayStream struct {
+	Stream
+	buffer *ReplayBuffer
+	topic  string
+	prev   string
+	offset int
+}
+
+func (s *replayStream) Send(e Event) {
+	s.buffer.Record(s.topic, s.prev, e)
+	if e.ID != "" {
+		s.prev = e.ID
+	}
+
+	s.Stream.Send(e)
+}
+
+func (s *replayStream) SentCount() int {
+	return s.Stream.SentCount() - s.offset
+}
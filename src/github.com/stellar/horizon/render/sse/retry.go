@@ -0,0 +1,77 @@
+package sse
+
+import (
+	"time"
+)
+
+// RetryPolicy determines the retry hints sent to clients with the "open"
+// (hello) and "close" (goodbye) events of a stream.  A zero value for either
+// hint uses the default: one second for hello, 10ms for goodbye.
+//
+// The goodbye hint is deliberately short, so that clients quickly reconnect for
+// the next page of the stream.  Under load, that causes reconnection storms.
+// When Pressure and Max are set, the policy is adaptive: the goodbye hint is
+// raised towards Max in proportion to the pressure the server reports.
+type RetryPolicy struct {
+	Hello   time.Duration
+	Goodbye time.Duration
+
+	// Pressure reports how loaded the server is, from 0 (idle) to 1 (fully
+	// loaded).
+	Pressure func() float64
+	Max      time.Duration
+}
+
+const (
+	defaultHelloRetry   = 1 * time.Second
+	defaultGoodbyeRetry = 10 * time.Millisecond
+)
+
+// HelloRetry returns the retry hint, in milliseconds, for the hello event.
+func (p RetryPolicy) HelloRetry() int {
+	if p.Hello == 0 {
+		return millis(defaultHelloRetry)
+	}
+
+	return millis(p.Hello)
+}
+
+// GoodbyeRetry returns the retry hint, in milliseconds, for the goodbye event,
+// adjusted for the current pressure when the policy is adaptive.
+func (p RetryPolicy) GoodbyeRetry() int {
+	retry := p.Goodbye
+	if retry == 0 {
+		retry = defaultGoodbyeRetry
+	}
+
+	if p.Pressure == nil || p.Max <= retry {
+		return millis(retry)
+	}
+
+	pressure := p.Pressure()
+	switch {
+	case pressure < 0:
+		pressure = 0
+	case pressure > 1:
+		pressure = 1
+	}
+
+	retry += time.Duration(float64(p.Max-retry) * pressure)
+	return millis(retry)
+}
+
+func (p RetryPolicy) hello() Event {
+	e := helloEvent
+	e.Retry = p.HelloRetry()
+	return e
+}
+
+func (p RetryPolicy) goodbye() Event {
+	e := goodbyeEvent
+	e.Retry = p.GoodbyeRetry()
+	return e
+}
+
+func millis(d time.Duration) int {
+	return int(d / time.Millisecond)
+}
@@ -0,0 +1,101 @@
+package sse
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/go-errors/errors"
+	"github.com/stellar/go-stellar-base/xdr"
+)
+
+// Serializer converts the Data of an Event into the text sent to the client as
+// the event's data field.  The returned string may contain newlines, in which
+// case it is sent as multiple data lines.
+type Serializer interface {
+	Serialize(data interface{}) (string, error)
+}
+
+// SerializerFunc is an adapter to allow the use of ordinary functions as
+// serializers.
+type SerializerFunc func(data interface{}) (string, error)
+
+// Serialize calls fn(data).
+func (fn SerializerFunc) Serialize(data interface{}) (string, error) {
+	return fn(data)
+}
+
+var (
+	// JSON serializes event data using encoding/json.  It is the default
+	// serializer.
+	JSON Serializer = SerializerFunc(serializeJSON)
+
+	// PrettyJSON serializes event data as indented json, each line of which is
+	// sent as a separate data line.
+	PrettyJSON Serializer = SerializerFunc(serializePrettyJSON)
+
+	// Text serializes event data that has already been rendered: strings,
+	// []byte and fmt.Stringer values.
+	Text Serializer = SerializerFunc(serializeText)
+
+	// XDR serializes xdr structures into base64 encoded xdr.
+	XDR Serializer = SerializerFunc(xdr.MarshalBase64)
+
+	// CSV serializes a []string as a single csv row.
+	CSV Serializer = SerializerFunc(serializeCSV)
+)
+
+func serializeJSON(data interface{}) (string, error) {
+	js, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+
+	return string(js), nil
+}
+
+func serializePrettyJSON(data interface{}) (string, error) {
+	js, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return string(js), nil
+}
+
+func serializeText(data interface{}) (string, error) {
+	switch data := data.(type) {
+	case string:
+		return data, nil
+	case []byte:
+		return string(data), nil
+	case fmt.Stringer:
+		return data.String(), nil
+	default:
+		return "", errors.Errorf("cannot serialize %T as text", data)
+	}
+}
+
+func serializeCSV(data interface{}) (string, error) {
+	row, ok := data.([]string)
+	if !ok {
+		return "", errors.Errorf("cannot serialize %T as csv", data)
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	err := w.Write(row)
+	if err != nil {
+		return "", err
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(buf.String(), "\n"), nil
+}
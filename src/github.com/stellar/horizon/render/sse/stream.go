@@ -3,6 +3,7 @@ package sse
 import (
 	"net/http"
 
+	"github.com/stellar/horizon/log"
 	"golang.org/x/net/context"
 )
 
@@ -12,25 +13,98 @@ type Stream interface {
 	Done()
 	IsDone() bool
 	Err(error)
+
+	// LastEventID returns the id of the most recent event sent on this stream,
+	// falling back to the id the client reported when it (re)connected.
+	LastEventID() string
+
+	// Keepalive sends a heartbeat to the client, without sending an event.
+	Keepalive()
+
+	// Shutdown informs the client that the server is shutting down, asking it
+	// to reconnect later, and ends the stream.
+	Shutdown()
+
+	// Meta sends an event that is outside the stream's sequence of events,
+	// such as a status update.  Meta events are not counted by SentCount, and
+	// their ids are ignored.
+	Meta(Event)
 }
 
 func NewStream(ctx context.Context, w http.ResponseWriter, r *http.Request) (Stream, bool) {
-	result := &stream{ctx, w, r, false, 0}
-	ok := WritePreamble(ctx, w)
+	return NewStreamWith(ctx, w, r, RetryPolicy{})
+}
+
+// NewStreamWith is like NewStream, but uses the provided policy to decide the
+// retry hints sent to the client.
+func NewStreamWith(ctx context.Context, w http.ResponseWriter, r *http.Request, retry RetryPolicy) (Stream, bool) {
+	return NewSerializedStream(ctx, w, r, retry, JSON)
+}
+
+// NewSerializedStream is like NewStreamWith, but serializes the data of the
+// events sent on the stream using s.
+func NewSerializedStream(ctx context.Context, w http.ResponseWriter, r *http.Request, retry RetryPolicy, s Serializer) (Stream, bool) {
+	result := &stream{ctx: ctx, w: w, r: r, lastID: LastEventID(r), retry: retry, serializer: s}
+	ok := writePreamble(ctx, w, retry.hello())
 	return result, ok
 }
 
+// NewBufferedStream is like NewSerializedStream but, when buffer.Size is not
+// zero, sends the events of the stream through a BufferedWriter once the
+// preamble is written.  The stream is done once the client is dropped.  done
+// must be called once the stream ends, to send the events still queued.
+func NewBufferedStream(ctx context.Context, w http.ResponseWriter, r *http.Request, retry RetryPolicy, s Serializer, buffer BufferConfig) (result Stream, done func(), ok bool) {
+	done = func() {}
+	if buffer.Size == 0 {
+		result, ok = NewSerializedStream(ctx, w, r, retry, s)
+		return
+	}
+
+	if !writePreamble(ctx, w, retry.hello()) {
+		return
+	}
+
+	bw := NewBufferedWriter(w, buffer)
+	result = &stream{ctx: ctx, w: bw, r: r, lastID: LastEventID(r), retry: retry, serializer: s, dropped: bw.Dropped()}
+	return result, bw.Close, true
+}
+
 type stream struct {
-	ctx  context.Context
-	w    http.ResponseWriter
-	r    *http.Request
-	done bool
-	sent int
+	ctx    context.Context
+	w      http.ResponseWriter
+	r      *http.Request
+	done   bool
+	sent   int
+	lastID string
+	retry  RetryPolicy
+
+	serializer Serializer
+
+	// dropped, when not nil, is closed once the client is dropped by the
+	// BufferedWriter the stream sends through
+	dropped <-chan struct{}
 }
 
 func (s *stream) Send(e Event) {
-	WriteEvent(s.ctx, s.w, e)
+	WriteEventWith(s.ctx, s.w, e, s.serializer)
 	s.sent++
+
+	if e.ID != "" {
+		s.lastID = e.ID
+	}
+}
+
+func (s *stream) LastEventID() string {
+	return s.lastID
+}
+
+func (s *stream) Meta(e Event) {
+	e.ID = ""
+	WriteEventWith(s.ctx, s.w, e, s.serializer)
+}
+
+func (s *stream) Keepalive() {
+	WriteHeartbeat(s.w)
 }
 
 func (s *stream) SentCount() int {
@@ -38,11 +112,27 @@ func (s *stream) SentCount() int {
 }
 
 func (s *stream) Done() {
-	WriteEvent(s.ctx, s.w, goodbyeEvent)
+	WriteEvent(s.ctx, s.w, s.retry.goodbye())
+	s.done = true
+}
+
+func (s *stream) Shutdown() {
+	WriteEvent(s.ctx, s.w, shutdownEvent)
 	s.done = true
 }
 
 func (s *stream) IsDone() bool {
+	if s.done {
+		return true
+	}
+
+	select {
+	case <-s.dropped:
+		log.Warn(s.ctx, "dropping slow sse client")
+		s.done = true
+	default:
+	}
+
 	return s.done
 }
 
@@ -0,0 +1,186 @@
+package ws
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"sync"
+)
+
+// frame opcodes, as defined by RFC 6455 section 5.2
+const (
+	opText  = 0x1
+	opClose = 0x8
+	opPing  = 0x9
+	opPong  = 0xA
+)
+
+// Conn is the server side of an upgraded websocket connection.  Writes to a
+// Conn are safe to perform from multiple goroutines.
+type Conn struct {
+	lock   sync.Mutex
+	conn   net.Conn
+	rw     *bufio.ReadWriter
+	closed chan struct{}
+	once   sync.Once
+}
+
+func newConn(conn net.Conn, rw *bufio.ReadWriter) *Conn {
+	result := &Conn{
+		conn:   conn,
+		rw:     rw,
+		closed: make(chan struct{}),
+	}
+
+	go result.readLoop()
+	return result
+}
+
+// WriteText sends payload to the client as a single text frame.
+func (c *Conn) WriteText(payload []byte) error {
+	return c.writeFrame(opText, payload)
+}
+
+// Ping sends a ping frame to the client, which keeps intermediaries from
+// closing an idle connection.
+func (c *Conn) Ping() error {
+	return c.writeFrame(opPing, nil)
+}
+
+// Close sends a close frame to the client and closes the underlying network
+// connection.
+func (c *Conn) Close() error {
+	err := c.writeFrame(opClose, nil)
+	c.shutdown()
+	return err
+}
+
+// Closed returns a channel that is closed once the connection has been closed,
+// either by the client or the server.
+func (c *Conn) Closed() <-chan struct{} {
+	return c.closed
+}
+
+func (c *Conn) shutdown() {
+	c.once.Do(func() {
+		close(c.closed)
+		c.conn.Close()
+	})
+}
+
+func (c *Conn) writeFrame(opcode byte, payload []byte) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	select {
+	case <-c.closed:
+		return io.ErrClosedPipe
+	default:
+	}
+
+	_, err := c.rw.Write(encodeFrameHeader(opcode, len(payload)))
+	if err != nil {
+		return err
+	}
+
+	_, err = c.rw.Write(payload)
+	if err != nil {
+		return err
+	}
+
+	return c.rw.Flush()
+}
+
+// readLoop consumes frames sent by the client, responding to pings and closing
+// the connection when the client asks to (or when the connection breaks).
+// Data frames sent by the client are discarded.
+func (c *Conn) readLoop() {
+	defer c.shutdown()
+
+	for {
+		opcode, payload, err := readFrame(c.rw.Reader)
+		if err != nil {
+			return
+		}
+
+		switch opcode {
+		case opClose:
+			c.writeFrame(opClose, nil)
+			return
+		case opPing:
+			c.writeFrame(opPong, payload)
+		}
+	}
+}
+
+// encodeFrameHeader returns the header for an unmasked, final frame of the
+// provided opcode and payload length.
+func encodeFrameHeader(opcode byte, length int) []byte {
+	header := []byte{0x80 | opcode}
+
+	switch {
+	case length < 126:
+		header = append(header, byte(length))
+	case length <= 0xFFFF:
+		header = append(header, 126, 0, 0)
+		binary.BigEndian.PutUint16(header[2:], uint16(length))
+	default:
+		header = append(header, 127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(header[2:], uint64(length))
+	}
+
+	return header
+}
+
+// readFrame reads a single frame from r, unmasking its payload if necessary.
+func readFrame(r io.Reader) (opcode byte, payload []byte, err error) {
+	var header [2]byte
+	if _, err = io.ReadFull(r, header[:]); err != nil {
+		return
+	}
+
+	opcode = header[0] & 0x0F
+	masked := header[1]&0x80 != 0
+	length := uint64(header[1] & 0x7F)
+
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(r, ext[:]); err != nil {
+			return
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(r, ext[:]); err != nil {
+			return
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+
+	if length > maxClientFrameSize {
+		err = ErrFrameTooLarge
+		return
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err = io.ReadFull(r, mask[:]); err != nil {
+			return
+		}
+	}
+
+	payload = make([]byte, length)
+	if _, err = io.ReadFull(r, payload); err != nil {
+		return
+	}
+
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+
+	return
+}
@@ -0,0 +1,4 @@
+// Package ws contains the WebSocket implementation used by horizon.  It
+// delivers the same stream of events that package sse does, allowing clients
+// that cannot use EventSource to stream from horizon over a WebSocket.
+package ws
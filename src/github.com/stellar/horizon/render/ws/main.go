@@ -0,0 +1,197 @@
+package ws
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/stellar/horizon/log"
+	"github.com/stellar/horizon/render/problem"
+	"github.com/stellar/horizon/render/sse"
+	"golang.org/x/net/context"
+)
+
+// acceptGUID is the magic value from RFC 6455 used to compute the
+// Sec-WebSocket-Accept response header.
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// maxClientFrameSize is the largest frame we are willing to read from a
+// client.  Clients have no reason to send us anything but control frames.
+const maxClientFrameSize = 4096
+
+var (
+	// ErrNotWebSocket is returned when attempting to upgrade a request that did
+	// not ask to be upgraded to the websocket protocol.
+	ErrNotWebSocket = errors.New("request is not a websocket upgrade")
+	// ErrUnsupportedVersion is returned when the client requests a version of
+	// the websocket protocol other than 13.
+	ErrUnsupportedVersion = errors.New("unsupported websocket version")
+	// ErrNotHijackable is returned when the provided http.ResponseWriter cannot
+	// release its underlying connection.
+	ErrNotHijackable = errors.New("response writer cannot be hijacked")
+	// ErrFrameTooLarge is returned when a client sends a frame larger than we
+	// are willing to read.
+	ErrFrameTooLarge = errors.New("websocket frame too large")
+)
+
+// Upon initial stream creation we send this event, mirroring the sse
+// package's "open" event.
+var helloEvent = sse.Event{
+	Data:  "hello",
+	Event: "open",
+}
+
+// Upon successful completion of a query we send this event prior to closing
+// the connection, mirroring the sse package's "close" event.  Clients should
+// reconnect using the id of the last message they received as their cursor.
+var goodbyeEvent = sse.Event{
+	Data:  "byebye",
+	Event: "close",
+}
+
+// When the server is shutting down we send this event in place of
+// goodbyeEvent, mirroring the sse package's shutdown event.
+var shutdownEvent = sse.Event{
+	Data:  "shutdown",
+	Event: "close",
+}
+
+// message is the json form of an sse.Event that is sent to websocket clients as
+// a single text frame.
+type message struct {
+	ID    string      `json:"id,omitempty"`
+	Event string      `json:"event,omitempty"`
+	Data  interface{} `json:"data"`
+}
+
+// IsUpgrade returns true if the provided request is asking to be upgraded to
+// the websocket protocol.
+func IsUpgrade(r *http.Request) bool {
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		return false
+	}
+
+	for _, v := range strings.Split(r.Header.Get("Connection"), ",") {
+		if strings.EqualFold(strings.TrimSpace(v), "upgrade") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Upgrade performs the websocket opening handshake for the provided request,
+// taking over the connection underlying w.  Nothing is written to w when an
+// error is returned.
+func Upgrade(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	if !IsUpgrade(r) {
+		return nil, ErrNotWebSocket
+	}
+
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		return nil, ErrUnsupportedVersion
+	}
+
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		return nil, ErrNotWebSocket
+	}
+
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, ErrNotHijackable
+	}
+
+	conn, rw, err := hj.Hijack()
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Fprint(rw, "HTTP/1.1 101 Switching Protocols\r\n")
+	fmt.Fprint(rw, "Upgrade: websocket\r\n")
+	fmt.Fprint(rw, "Connection: Upgrade\r\n")
+	fmt.Fprintf(rw, "Sec-WebSocket-Accept: %s\r\n\r\n", acceptKey(key))
+
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return newConn(conn, rw), nil
+}
+
+// WriteEvent formats the provided event as a json message and sends it over
+// the provided connection.  Errors are sent as an "err" event whose data is the
+// problem document for the error, as in the sse package.
+func WriteEvent(ctx context.Context, c *Conn, e sse.Event) error {
+	m := message{ID: e.ID, Event: e.Event, Data: e.Data}
+
+	if e.Error != nil {
+		log.Error(ctx, e.Error)
+		m = message{Event: "err", Data: problem.Resolve(ctx, e.Error)}
+	}
+
+	js, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	return c.WriteText(js)
+}
+
+// Streamer handles the work of turning a channel of Eventable objects into
+// websocket messages sent to a client.  It is the websocket counterpart of
+// sse.Streamer.  Construct one and call `ServeHTTP` to do so.
+//
+// If Heartbeat is non-zero, a ping is sent to the client whenever that much
+// time passes without any data being sent.
+type Streamer struct {
+	Ctx       context.Context
+	Data      <-chan sse.Eventable
+	Heartbeat time.Duration
+}
+
+func (s *Streamer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	stream, ok := newStream(s.Ctx, w, r)
+	if !ok {
+		return
+	}
+
+	heartbeat := sse.NewHeartbeat(s.Heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case eventable, more := <-s.Data:
+			if !more {
+				stream.Done()
+				return
+			}
+			stream.Send(eventable.SseEvent())
+			if stream.IsDone() {
+				return
+			}
+			heartbeat.Reset()
+		case <-heartbeat.C():
+			stream.Keepalive()
+			heartbeat.Reset()
+		case <-stream.conn.Closed():
+			return
+		case <-s.Ctx.Done():
+			stream.Done()
+			return
+		}
+	}
+}
+
+func acceptKey(key string) string {
+	h := sha1.New()
+	io.WriteString(h, key+acceptGUID)
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
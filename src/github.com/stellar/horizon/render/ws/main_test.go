@@ -0,0 +1,125 @@
+package ws
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/render/sse"
+	"github.com/stellar/horizon/test"
+)
+
+func TestWsPackage(t *testing.T) {
+	ctx := test.Context()
+
+	Convey("ws.IsUpgrade", t, func() {
+		r, _ := http.NewRequest("GET", "/ledgers", nil)
+		So(IsUpgrade(r), ShouldBeFalse)
+
+		r.Header.Set("Upgrade", "websocket")
+		So(IsUpgrade(r), ShouldBeFalse)
+
+		r.Header.Set("Connection", "keep-alive, Upgrade")
+		So(IsUpgrade(r), ShouldBeTrue)
+	})
+
+	Convey("ws.acceptKey matches the RFC 6455 example", t, func() {
+		So(acceptKey("dGhlIHNhbXBsZSBub25jZQ=="), ShouldEqual, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=")
+	})
+
+	Convey("encodeFrameHeader", t, func() {
+		So(encodeFrameHeader(opText, 5), ShouldResemble, []byte{0x81, 5})
+		So(encodeFrameHeader(opText, 256), ShouldResemble, []byte{0x81, 126, 1, 0})
+		So(encodeFrameHeader(opClose, 0), ShouldResemble, []byte{0x88, 0})
+		So(len(encodeFrameHeader(opText, 70000)), ShouldEqual, 10)
+	})
+
+	Convey("readFrame unmasks client frames", t, func() {
+		mask := []byte{1, 2, 3, 4}
+		payload := []byte("ping")
+		frame := []byte{0x80 | opPing, 0x80 | byte(len(payload))}
+		frame = append(frame, mask...)
+		for i, b := range payload {
+			frame = append(frame, b^mask[i%4])
+		}
+
+		opcode, got, err := readFrame(bytes.NewReader(frame))
+		So(err, ShouldBeNil)
+		So(opcode, ShouldEqual, opPing)
+		So(string(got), ShouldEqual, "ping")
+	})
+
+	Convey("ws.Upgrade fails for non-hijackable writers", t, func() {
+		r, _ := http.NewRequest("GET", "/ledgers", nil)
+		r.Header.Set("Upgrade", "websocket")
+		r.Header.Set("Connection", "Upgrade")
+		r.Header.Set("Sec-WebSocket-Version", "13")
+		r.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+
+		_, err := Upgrade(httptest.NewRecorder(), r)
+		So(err, ShouldEqual, ErrNotHijackable)
+	})
+
+	Convey("ws.NewStream delivers events over an upgraded connection", t, func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			stream, ok := NewStream(ctx, w, r)
+			if !ok {
+				return
+			}
+			stream.Send(sse.Event{ID: "1", Data: "test"})
+			stream.Err(errors.New("busted"))
+		}))
+		defer server.Close()
+
+		conn, err := net.Dial("tcp", server.Listener.Addr().String())
+		So(err, ShouldBeNil)
+		defer conn.Close()
+
+		conn.Write([]byte("GET / HTTP/1.1\r\n" +
+			"Host: localhost\r\n" +
+			"Upgrade: websocket\r\n" +
+			"Connection: Upgrade\r\n" +
+			"Sec-WebSocket-Version: 13\r\n" +
+			"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n\r\n"))
+
+		br := bufio.NewReader(conn)
+		resp, err := http.ReadResponse(br, nil)
+		So(err, ShouldBeNil)
+		So(resp.StatusCode, ShouldEqual, http.StatusSwitchingProtocols)
+		So(resp.Header.Get("Sec-WebSocket-Accept"), ShouldEqual, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=")
+
+		expectations := []message{
+			{Event: "open", Data: "hello"},
+			{ID: "1", Data: "test"},
+		}
+
+		for _, expected := range expectations {
+			opcode, payload, err := readFrame(br)
+			So(err, ShouldBeNil)
+			So(opcode, ShouldEqual, opText)
+
+			var m message
+			So(json.Unmarshal(payload, &m), ShouldBeNil)
+			So(m, ShouldResemble, expected)
+		}
+
+		// errors are delivered as problem documents
+		_, payload, err := readFrame(br)
+		So(err, ShouldBeNil)
+
+		var m message
+		So(json.Unmarshal(payload, &m), ShouldBeNil)
+		So(m.Event, ShouldEqual, "err")
+		So(m.Data.(map[string]interface{})["type"], ShouldEqual, "https://stellar.org/horizon-errors/server_error")
+
+		opcode, _, err := readFrame(br)
+		So(err, ShouldBeNil)
+		So(opcode, ShouldEqual, opClose)
+	})
+}
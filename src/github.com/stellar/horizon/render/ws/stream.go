@@ -0,0 +1,98 @@
+package ws
+
+import (
+	"net/http"
+
+	"github.com/stellar/horizon/log"
+	"github.com/stellar/horizon/render/sse"
+	"golang.org/x/net/context"
+)
+
+// NewStream upgrades the provided request to a websocket connection and
+// returns an sse.Stream that writes to it, allowing actions written against
+// the sse package to serve websocket clients unchanged.
+func NewStream(ctx context.Context, w http.ResponseWriter, r *http.Request) (sse.Stream, bool) {
+	return newStream(ctx, w, r)
+}
+
+func newStream(ctx context.Context, w http.ResponseWriter, r *http.Request) (*stream, bool) {
+	conn, err := Upgrade(w, r)
+	if err != nil {
+		//TODO: render a problem struct instead of simple string
+		http.Error(w, "WebSocket Upgrade Failed", http.StatusBadRequest)
+		return nil, false
+	}
+
+	result := &stream{ctx: ctx, conn: conn, lastID: sse.LastEventID(r)}
+	result.write(helloEvent)
+	return result, !result.done
+}
+
+type stream struct {
+	ctx    context.Context
+	conn   *Conn
+	done   bool
+	sent   int
+	lastID string
+}
+
+func (s *stream) Send(e sse.Event) {
+	s.write(e)
+	s.sent++
+
+	if e.ID != "" {
+		s.lastID = e.ID
+	}
+}
+
+func (s *stream) LastEventID() string {
+	return s.lastID
+}
+
+func (s *stream) Meta(e sse.Event) {
+	e.ID = ""
+	s.write(e)
+}
+
+func (s *stream) Keepalive() {
+	err := s.conn.Ping()
+	if err != nil {
+		s.done = true
+	}
+}
+
+func (s *stream) SentCount() int {
+	return s.sent
+}
+
+func (s *stream) Done() {
+	s.write(goodbyeEvent)
+	s.conn.Close()
+	s.done = true
+}
+
+func (s *stream) Shutdown() {
+	s.write(shutdownEvent)
+	s.conn.Close()
+	s.done = true
+}
+
+func (s *stream) IsDone() bool {
+	return s.done
+}
+
+func (s *stream) Err(err error) {
+	s.write(sse.Event{Error: err})
+	s.conn.Close()
+	s.done = true
+}
+
+// write sends e to the client, marking the stream as done if the connection
+// has gone away.
+func (s *stream) write(e sse.Event) {
+	err := WriteEvent(s.ctx, s.conn, e)
+	if err != nil {
+		log.Debug(s.ctx, "websocket write failed: ", err)
+		s.done = true
+	}
+}
@@ -0,0 +1,193 @@
+package xdr
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/go-errors/errors"
+	"github.com/stellar/go-stellar-base/xdr"
+	"github.com/stellar/horizon/strkeys"
+)
+
+// DecodedField is the name of the object DecodeFields adds the decoded xdr of
+// a document to.
+const DecodedField = "decoded_xdr"
+
+// Fields maps the names of the json fields that horizon renders base64 encoded
+// xdr in to a constructor of the type that xdr decodes into.
+var Fields = map[string]func() interface{}{
+	"envelope_xdr":    func() interface{} { return &xdr.TransactionEnvelope{} },
+	"result_xdr":      func() interface{} { return &xdr.TransactionResult{} },
+	"result_meta_xdr": func() interface{} { return &xdr.TransactionMeta{} },
+	"header_xdr":      func() interface{} { return &xdr.LedgerHeader{} },
+}
+
+var (
+	accountIDType = reflect.TypeOf(xdr.AccountId{})
+	publicKeyType = reflect.TypeOf(xdr.PublicKey{})
+	stringerType  = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+)
+
+// Decode decodes data, the base64 encoded xdr of the json field named field,
+// into the generic json value of the structure it encodes.  The fields of its
+// structs are named in snake case, the arms of its unions not switched to are
+// left out, its enums are named, its keys are rendered as strkeys, its asset
+// codes as strings, and any other opaque data as hex when of a fixed length
+// and as base64 otherwise.
+func Decode(field string, data string) (interface{}, error) {
+	newValue, ok := Fields[field]
+	if !ok {
+		return nil, errors.Errorf("%s is not a field of xdr", field)
+	}
+
+	v := newValue()
+	err := xdr.SafeUnmarshalBase64(data, v)
+	if err != nil {
+		return nil, errors.Wrap(err, 1)
+	}
+
+	return value(reflect.ValueOf(v).Elem()), nil
+}
+
+// DecodeFields adds to each object of doc, a generic json value such as those
+// json.Unmarshal returns, with any of Fields a DecodedField object holding the
+// decoding of each.  The fields that fail to decode are left out of it.
+func DecodeFields(doc interface{}) {
+	switch doc := doc.(type) {
+	case map[string]interface{}:
+		decoded := map[string]interface{}{}
+		for key, v := range doc {
+			data, ok := v.(string)
+			if _, known := Fields[key]; !known || !ok || data == "" {
+				DecodeFields(v)
+				continue
+			}
+
+			if d, err := Decode(key, data); err == nil {
+				decoded[key] = d
+			}
+		}
+
+		if len(decoded) > 0 {
+			doc[DecodedField] = decoded
+		}
+	case []interface{}:
+		for _, v := range doc {
+			DecodeFields(v)
+		}
+	}
+}
+
+// value returns the generic json value of v, as described by Decode
+func value(v reflect.Value) interface{} {
+	switch v.Type() {
+	case accountIDType:
+		return address(v.Interface().(xdr.AccountId))
+	case publicKeyType:
+		return address(xdr.AccountId(v.Interface().(xdr.PublicKey)))
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		return value(v.Elem())
+	case reflect.Struct:
+		return structValue(v)
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return opaque(v)
+		}
+
+		result := make([]interface{}, v.Len())
+		for i := range result {
+			result[i] = value(v.Index(i))
+		}
+		return result
+	case reflect.Int32:
+		if v.Type().Implements(stringerType) {
+			return v.Interface().(fmt.Stringer).String()
+		}
+	}
+
+	return v.Interface()
+}
+
+// structValue returns the generic json value of the struct v, less the nil
+// arms of a union
+func structValue(v reflect.Value) map[string]interface{} {
+	result := map[string]interface{}{}
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		fv := v.Field(i)
+		if f.PkgPath != "" || (fv.Kind() == reflect.Ptr && fv.IsNil()) {
+			continue
+		}
+
+		if strings.HasPrefix(f.Name, "AssetCode") {
+			result[snakeCase(f.Name)] = assetCode(reflect.Indirect(fv))
+			continue
+		}
+
+		result[snakeCase(f.Name)] = value(fv)
+	}
+
+	return result
+}
+
+// opaque renders v, a byte array or slice, as hex if of a fixed length and as
+// base64 otherwise
+func opaque(v reflect.Value) string {
+	raw := make([]byte, v.Len())
+	reflect.Copy(reflect.ValueOf(raw), v)
+
+	if v.Kind() == reflect.Array {
+		return hex.EncodeToString(raw)
+	}
+	return base64.StdEncoding.EncodeToString(raw)
+}
+
+// assetCode renders v, the byte array of an asset code, as a string less its
+// padding
+func assetCode(v reflect.Value) string {
+	raw := make([]byte, v.Len())
+	reflect.Copy(reflect.ValueOf(raw), v)
+	return strings.TrimRight(string(raw), "\x00")
+}
+
+// address renders aid as a strkey, or as nothing if it is not of a key type
+// strkeys knows
+func address(aid xdr.AccountId) interface{} {
+	address, err := strkeys.Address(aid)
+	if err != nil {
+		return nil
+	}
+	return address
+}
+
+// snakeCase converts the name of a field, such as SourceAccount or TxSetHash,
+// to snake case
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var result []rune
+
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prevLower := !unicode.IsUpper(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || nextLower {
+				result = append(result, '_')
+			}
+		}
+		result = append(result, unicode.ToLower(r))
+	}
+
+	return string(result)
+}
@@ -0,0 +1,68 @@
+package xdr
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// envelope is that of a transaction creating an account
+const envelope = "AAAAAGL8HQvQkbK2HA3WVjRrKmjX00fG8sLI7m0ERwJW/AX3AAAACgAAAAAAAAABAAAAAAAAAAAAAAABAAAAAAAAAAAAAAAArqN6LeOagjxMaUP96Bzfs9e0corNZXzBWJkFoK7kvkwAAAAAO5rKAAAAAAAAAAABVvwF9wAAAEAKZ7IPj/46PuWU6ZOtyMosctNAkXRNX9WCAI5RnfRk+AyxDLoDZP/9l3NvsxQtWj9juQOuoBlFLnWu8intgxQA"
+
+func TestDecode(t *testing.T) {
+	Convey("Decode", t, func() {
+		Convey("decodes envelopes legibly", func() {
+			decoded, err := Decode("envelope_xdr", envelope)
+			So(err, ShouldBeNil)
+
+			js, err := json.Marshal(decoded)
+			So(err, ShouldBeNil)
+			t.Log(string(js))
+			So(string(js), ShouldContainSubstring, `"source_account":"GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H"`)
+			So(string(js), ShouldContainSubstring, `"type":"OperationTypeCreateAccount"`)
+			So(string(js), ShouldContainSubstring, `"starting_balance":1000000000`)
+			So(string(js), ShouldContainSubstring, `"hint":"56fc05f7"`)
+			So(string(js), ShouldNotContainSubstring, "payment_op")
+		})
+
+		Convey("fails on malformed xdr, and on fields it does not know", func() {
+			_, err := Decode("envelope_xdr", "AAAA")
+			So(err, ShouldNotBeNil)
+
+			_, err = Decode("memo", envelope)
+			So(err, ShouldNotBeNil)
+		})
+	})
+
+	Convey("DecodeFields", t, func() {
+		doc := map[string]interface{}{
+			"hash": "c492d87c",
+			"_embedded": map[string]interface{}{
+				"records": []interface{}{
+					map[string]interface{}{"envelope_xdr": envelope},
+					map[string]interface{}{"envelope_xdr": "broken"},
+				},
+			},
+		}
+
+		DecodeFields(doc)
+		_, ok := doc[DecodedField]
+		So(ok, ShouldBeFalse)
+
+		records := doc["_embedded"].(map[string]interface{})["records"].([]interface{})
+		decoded, ok := records[0].(map[string]interface{})[DecodedField].(map[string]interface{})
+		So(ok, ShouldBeTrue)
+		So(decoded["envelope_xdr"], ShouldNotBeNil)
+
+		_, ok = records[1].(map[string]interface{})[DecodedField]
+		So(ok, ShouldBeFalse)
+	})
+
+	Convey("snakeCase", t, func() {
+		So(snakeCase("SourceAccount"), ShouldEqual, "source_account")
+		So(snakeCase("TxSetHash"), ShouldEqual, "tx_set_hash")
+		So(snakeCase("AssetCode12"), ShouldEqual, "asset_code12")
+		So(snakeCase("V"), ShouldEqual, "v")
+	})
+}
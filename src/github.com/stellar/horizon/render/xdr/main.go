@@ -0,0 +1,23 @@
+// Package xdr renders responses of raw, binary encoded xdr, and decodes the
+// base64 encoded xdr of json documents for people to read.
+package xdr
+
+import (
+	"encoding/base64"
+	"net/http"
+
+	"github.com/go-errors/errors"
+)
+
+// Render writes the xdr encoded by data, a base64 string as stored in the
+// database, to w in its raw binary form.
+func Render(w http.ResponseWriter, data string) error {
+	raw, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	w.Header().Set("Content-Type", "application/x-stellar-xdr")
+	_, err = w.Write(raw)
+	return err
+}
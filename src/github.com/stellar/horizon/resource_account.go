@@ -9,6 +9,8 @@ import (
 	"github.com/stellar/go-stellar-base/xdr"
 	"github.com/stellar/horizon/db"
 	"github.com/stellar/horizon/render/hal"
+	"github.com/stellar/horizon/render/problem"
+	"golang.org/x/net/context"
 )
 
 // AccountResource is the summary of an account
@@ -132,8 +134,93 @@ func NewAccountResource(ac db.AccountRecord) AccountResource {
 	}
 }
 
+// NewFilteredAccountResource creates an AccountResource from an account loaded
+// by a db.CoreAccountPageQuery, whose paging token is the account's address.
+func NewFilteredAccountResource(ac db.AccountRecord) AccountResource {
+	r := NewAccountResource(ac)
+	r.PagingToken = ac.Accountid
+	return r
+}
+
+// NewAccountResourcePage creates a page of the accounts loaded by query, whose
+// links carry the query's filters.
+func NewAccountResourcePage(records []db.AccountRecord, query db.CoreAccountPageQuery) hal.Page {
+	fmts := "/accounts?signer=%s&asset=%s&home_domain=%s&order=%s&limit=%d&cursor=%s"
+	next := query.PageQuery
+	prev := query.Invert()
+
+	resources := make([]interface{}, len(records))
+	for i, record := range records {
+		r := NewFilteredAccountResource(record)
+		if i == 0 {
+			prev.Cursor = r.PagingToken
+		}
+		if i == len(records)-1 {
+			next.Cursor = r.PagingToken
+		}
+		resources[i] = r
+	}
+
+	var asset string
+	if query.AssetCode != "" {
+		asset = query.AssetCode + ":" + query.AssetIssuer
+	}
+
+	return hal.Page{
+		Links: halgo.Links{}.
+			Self(fmts, query.Signer, asset, query.HomeDomain, query.Order, query.Limit, query.Cursor).
+			Link("next", fmts, query.Signer, asset, query.HomeDomain, next.Order, next.Limit, next.Cursor).
+			Link("prev", fmts, query.Signer, asset, query.HomeDomain, prev.Order, prev.Limit, prev.Cursor),
+		Records: resources,
+	}
+}
+
 func AmountToString(amount int64) string {
 	whole := amount / stellarbase.One
 	frac := amount % stellarbase.One
 	return fmt.Sprintf("%d.%07d", whole, frac)
 }
+
+// AccountBatchResource is the response to a batch account lookup: a record
+// for each address asked for, in the order asked, holding either the account's
+// summary or the problem that kept it from being loaded.
+type AccountBatchResource struct {
+	Records []AccountBatchRecordResource `json:"records"`
+}
+
+// AccountBatchRecordResource is the outcome of looking up a single address of
+// a batch.  Exactly one of Account and Error is set.
+type AccountBatchRecordResource struct {
+	ID      string           `json:"id"`
+	Account *AccountResource `json:"account,omitempty"`
+	Error   *problem.P       `json:"error,omitempty"`
+}
+
+// NewAccountBatchResource creates a new resource from the records found for
+// addresses.  The addresses no record was found for are reported as not found.
+func NewAccountBatchResource(ctx context.Context, addresses []string, records []db.AccountRecord) AccountBatchResource {
+	found := make(map[string]db.AccountRecord, len(records))
+	for _, record := range records {
+		found[record.Address] = record
+	}
+
+	result := AccountBatchResource{
+		Records: make([]AccountBatchRecordResource, len(addresses)),
+	}
+
+	for i, address := range addresses {
+		result.Records[i].ID = address
+
+		record, ok := found[address]
+		if !ok {
+			p := problem.Resolve(ctx, problem.NotFound)
+			result.Records[i].Error = &p
+			continue
+		}
+
+		r := NewAccountResource(record)
+		result.Records[i].Account = &r
+	}
+
+	return result
+}
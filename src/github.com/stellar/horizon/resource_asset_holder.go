@@ -0,0 +1,56 @@
+package horizon
+
+import (
+	"github.com/jagregory/halgo"
+
+	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/render/hal"
+)
+
+// AssetHolderResource is the display form of an account holding an asset, by
+// way of its trustline to the asset.
+type AssetHolderResource struct {
+	halgo.Links
+	Account     string `json:"account_id"`
+	PagingToken string `json:"paging_token"`
+	Balance     string `json:"balance"`
+	Limit       string `json:"limit"`
+	Authorized  bool   `json:"is_authorized"`
+}
+
+// NewAssetHolderResource converts a TrustlineRecord into an
+// AssetHolderResource
+func NewAssetHolderResource(record db.TrustlineRecord) AssetHolderResource {
+	return AssetHolderResource{
+		Links: halgo.Links{}.
+			Link("account", "/accounts/%s", record.Accountid),
+		Account:     record.Accountid,
+		PagingToken: record.PagingToken(),
+		Balance:     AmountToString(record.Balance),
+		Limit:       AmountToString(record.Tlimit),
+		Authorized:  record.IsAuthorized(),
+	}
+}
+
+// NewAssetHolderResourcePage creates a page of AssetHolderResources for the
+// asset of the query.
+func NewAssetHolderResourcePage(records []db.TrustlineRecord, query db.AssetHoldersPageQuery) (hal.Page, error) {
+	fmts := "/assets/%s:%s/holders?order=%s&limit=%d&cursor=%s"
+	next, prev, err := query.GetContinuations(records)
+	if err != nil {
+		return hal.Page{}, err
+	}
+
+	resources := make([]interface{}, len(records))
+	for i, record := range records {
+		resources[i] = NewAssetHolderResource(record)
+	}
+
+	return hal.Page{
+		Links: halgo.Links{}.
+			Self(fmts, query.Code, query.Issuer, query.Order, query.Limit, query.Cursor).
+			Link("next", fmts, query.Code, query.Issuer, next.Order, next.Limit, next.Cursor).
+			Link("prev", fmts, query.Code, query.Issuer, prev.Order, prev.Limit, prev.Cursor),
+		Records: resources,
+	}, nil
+}
@@ -0,0 +1,97 @@
+package horizon
+
+import (
+	"github.com/jagregory/halgo"
+	"github.com/stellar/go-stellar-base/xdr"
+
+	"github.com/stellar/horizon/anchors"
+	"github.com/stellar/horizon/assets"
+	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/render/hal"
+)
+
+// AssetStatResource is the display form of an asset issued on the network,
+// summarizing the accounts that hold it.  Amount is the total held by accounts
+// other than the issuer.  Anchor is present once the anchor of the asset has
+// been fetched, when horizon annotates assets with their anchors.
+type AssetStatResource struct {
+	halgo.Links
+	Type        string        `json:"asset_type"`
+	Code        string        `json:"asset_code"`
+	Issuer      string        `json:"asset_issuer"`
+	PagingToken string        `json:"paging_token"`
+	Amount      string        `json:"amount"`
+	NumAccounts int32         `json:"num_accounts"`
+	Flags       FlagsResource `json:"flags"`
+
+	Anchor *AnchorResource `json:"anchor,omitempty"`
+}
+
+// AnchorResource is what the issuer of an asset publishes about it in the
+// stellar.toml of its home domain.  Verified is true when the stellar.toml
+// lists the asset.
+type AnchorResource struct {
+	Domain   string `json:"domain,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Image    string `json:"image,omitempty"`
+	Verified bool   `json:"verified"`
+}
+
+// NewAnchorResource converts an anchors.Anchor into an AnchorResource
+func NewAnchorResource(a anchors.Anchor) *AnchorResource {
+	return &AnchorResource{
+		Domain:   a.Domain,
+		Name:     a.Name,
+		Image:    a.Image,
+		Verified: a.Verified,
+	}
+}
+
+// NewAssetStatResource converts a CoreAssetStatRecord into an AssetStatResource
+func NewAssetStatResource(record db.CoreAssetStatRecord) (AssetStatResource, error) {
+	t, err := assets.String(xdr.AssetType(record.Assettype))
+	if err != nil {
+		return AssetStatResource{}, err
+	}
+
+	return AssetStatResource{
+		Links: halgo.Links{}.
+			Link("issuer", "/accounts/%s", record.Issuer),
+		Type:        t,
+		Code:        record.Assetcode,
+		Issuer:      record.Issuer,
+		PagingToken: record.PagingToken(),
+		Amount:      AmountToString(record.Amount),
+		NumAccounts: record.Numaccounts,
+		Flags: FlagsResource{
+			AuthRequired:  record.IsAuthRequired(),
+			AuthRevocable: record.IsAuthRevocable(),
+		},
+	}, nil
+}
+
+// NewAssetStatResourcePage creates a page of AssetStatResources, whose links
+// carry the code and issuer filters of the query.
+func NewAssetStatResourcePage(records []db.CoreAssetStatRecord, query db.CoreAssetStatPageQuery) (hal.Page, error) {
+	fmts := "/assets?asset_code=%s&asset_issuer=%s&order=%s&limit=%d&cursor=%s"
+	next, prev, err := query.GetContinuations(records)
+	if err != nil {
+		return hal.Page{}, err
+	}
+
+	resources := make([]interface{}, len(records))
+	for i, record := range records {
+		resources[i], err = NewAssetStatResource(record)
+		if err != nil {
+			return hal.Page{}, err
+		}
+	}
+
+	return hal.Page{
+		Links: halgo.Links{}.
+			Self(fmts, query.Code, query.Issuer, query.Order, query.Limit, query.Cursor).
+			Link("next", fmts, query.Code, query.Issuer, next.Order, next.Limit, next.Cursor).
+			Link("prev", fmts, query.Code, query.Issuer, prev.Order, prev.Limit, prev.Cursor),
+		Records: resources,
+	}, nil
+}
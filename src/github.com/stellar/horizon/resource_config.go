@@ -0,0 +1,127 @@
+package horizon
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/throttled"
+	"github.com/Sirupsen/logrus"
+)
+
+// redacted stands in for the secrets of a ConfigResource
+const redacted = "REDACTED"
+
+// secretConfigFields are the fields of Config whose values are never shown
+var secretConfigFields = map[string]bool{
+	"SentryDSN":              true,
+	"LogglyToken":            true,
+	"CursorSecret":           true,
+	"CallbackSecret":         true,
+	"FriendbotSecret":        true,
+	"FriendbotCaptchaSecret": true,
+}
+
+// ConfigResource is the display form of the config horizon runs with, by
+// field of Config.  Secrets are redacted, as are the passwords of urls, those
+// of the structs Config nests, such as its Networks, included.
+type ConfigResource map[string]interface{}
+
+// NewConfigResource creates a new resource from c
+func NewConfigResource(c Config) ConfigResource {
+	return ConfigResource(configStruct(reflect.ValueOf(c)))
+}
+
+// configStruct returns the display form of the struct v, by field
+func configStruct(v reflect.Value) map[string]interface{} {
+	result := map[string]interface{}{}
+
+	for i := 0; i < v.NumField(); i++ {
+		name := v.Type().Field(i).Name
+		result[name] = configValue(name, v.Field(i).Interface())
+	}
+
+	return result
+}
+
+// configValue returns the display form of the value of the field name
+func configValue(name string, value interface{}) interface{} {
+	if secretConfigFields[name] {
+		if value == "" {
+			return ""
+		}
+		return redacted
+	}
+
+	switch value := value.(type) {
+	case string:
+		if strings.HasSuffix(name, "Url") || strings.HasSuffix(name, "URL") {
+			return redactURL(value)
+		}
+	case []string:
+		if strings.HasSuffix(name, "Urls") {
+			urls := make([]string, len(value))
+			for i, u := range value {
+				urls[i] = redactURL(u)
+			}
+			return urls
+		}
+	case time.Duration:
+		return value.String()
+	case logrus.Level:
+		return value.String()
+	case throttled.Quota:
+		n, window := value.Quota()
+		return fmt.Sprintf("%d per %s", n, window)
+	}
+
+	// the secrets of nested structs, such as the NetworkConfigs of
+	// Config.Networks, are redacted by the names of their own fields
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Struct:
+		return configStruct(v)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.Struct {
+			break
+		}
+
+		if v.IsNil() {
+			return nil
+		}
+
+		result := make([]interface{}, v.Len())
+		for i := range result {
+			result[i] = configStruct(v.Index(i))
+		}
+		return result
+	}
+
+	return value
+}
+
+// redactURL returns raw with the password of its user info redacted.  Those
+// that are not urls, such as postgres connection strings of key=value pairs,
+// are redacted in full, lest they hold a secret.
+func redactURL(raw string) string {
+	if raw == "" {
+		return ""
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" {
+		return redacted
+	}
+
+	if u.User == nil {
+		return raw
+	}
+
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), redacted)
+	}
+
+	return u.String()
+}
@@ -7,25 +7,26 @@ import (
 )
 
 var effectResourceTypeNames = map[int32]string{
-	db.EffectAccountCreated:           "account_created",
-	db.EffectAccountRemoved:           "account_removed",
-	db.EffectAccountCredited:          "account_credited",
-	db.EffectAccountDebited:           "account_debited",
-	db.EffectAccountThresholdsUpdated: "account_thresholds_updated",
-	db.EffectAccountHomeDomainUpdated: "account_home_domain_updated",
-	db.EffectAccountFlagsUpdated:      "account_flags_updated",
-	db.EffectSignerCreated:            "signer_created",
-	db.EffectSignerRemoved:            "signer_removed",
-	db.EffectSignerUpdated:            "signer_updated",
-	db.EffectTrustlineCreated:         "trustline_created",
-	db.EffectTrustlineRemoved:         "trustline_removed",
-	db.EffectTrustlineUpdated:         "trustline_updated",
-	db.EffectTrustlineAuthorized:      "trustline_authorized",
-	db.EffectTrustlineDeauthorized:    "trustline_deauthorized",
-	db.EffectOfferCreated:             "offer_created",
-	db.EffectOfferRemoved:             "offer_removed",
-	db.EffectOfferUpdated:             "offer_updated",
-	db.EffectTrade:                    "trade",
+	db.EffectAccountCreated:                     "account_created",
+	db.EffectAccountRemoved:                     "account_removed",
+	db.EffectAccountCredited:                    "account_credited",
+	db.EffectAccountDebited:                     "account_debited",
+	db.EffectAccountThresholdsUpdated:           "account_thresholds_updated",
+	db.EffectAccountHomeDomainUpdated:           "account_home_domain_updated",
+	db.EffectAccountFlagsUpdated:                "account_flags_updated",
+	db.EffectAccountInflationDestinationUpdated: "account_inflation_destination_updated",
+	db.EffectSignerCreated:                      "signer_created",
+	db.EffectSignerRemoved:                      "signer_removed",
+	db.EffectSignerUpdated:                      "signer_updated",
+	db.EffectTrustlineCreated:                   "trustline_created",
+	db.EffectTrustlineRemoved:                   "trustline_removed",
+	db.EffectTrustlineUpdated:                   "trustline_updated",
+	db.EffectTrustlineAuthorized:                "trustline_authorized",
+	db.EffectTrustlineDeauthorized:              "trustline_deauthorized",
+	db.EffectOfferCreated:                       "offer_created",
+	db.EffectOfferRemoved:                       "offer_removed",
+	db.EffectOfferUpdated:                       "offer_updated",
+	db.EffectTrade:                              "trade",
 }
 
 // EffectResource is the json form of a row from the history_effects
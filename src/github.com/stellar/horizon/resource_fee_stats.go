@@ -0,0 +1,56 @@
+package horizon
+
+import (
+	"fmt"
+
+	"github.com/stellar/horizon/db"
+)
+
+// FeeStatsResource is the display form of db.FeeStats: the fees, per
+// operation in stroops, accepted into the network's latest ledgers.  Wallets
+// may choose a fee from its percentiles, trading cost against the chance of
+// being included when ledgers fill up.
+type FeeStatsResource struct {
+	LastLedger          int32  `json:"last_ledger"`
+	LastLedgerBaseFee   int32  `json:"last_ledger_base_fee"`
+	Ledgers             int32  `json:"ledgers"`
+	LedgerCapacityUsage string `json:"ledger_capacity_usage"`
+	MinAcceptedFee      int64  `json:"min_accepted_fee"`
+	ModeAcceptedFee     int64  `json:"mode_accepted_fee"`
+	P10AcceptedFee      int64  `json:"p10_accepted_fee"`
+	P20AcceptedFee      int64  `json:"p20_accepted_fee"`
+	P30AcceptedFee      int64  `json:"p30_accepted_fee"`
+	P40AcceptedFee      int64  `json:"p40_accepted_fee"`
+	P50AcceptedFee      int64  `json:"p50_accepted_fee"`
+	P60AcceptedFee      int64  `json:"p60_accepted_fee"`
+	P70AcceptedFee      int64  `json:"p70_accepted_fee"`
+	P80AcceptedFee      int64  `json:"p80_accepted_fee"`
+	P90AcceptedFee      int64  `json:"p90_accepted_fee"`
+	P95AcceptedFee      int64  `json:"p95_accepted_fee"`
+	P99AcceptedFee      int64  `json:"p99_accepted_fee"`
+}
+
+// NewFeeStatsResource creates a new resource from a db.FeeStats
+func NewFeeStatsResource(in db.FeeStats) FeeStatsResource {
+	p := in.Percentiles
+
+	return FeeStatsResource{
+		LastLedger:          in.LastLedger,
+		LastLedgerBaseFee:   in.LastLedgerBaseFee,
+		Ledgers:             in.Ledgers,
+		LedgerCapacityUsage: fmt.Sprintf("%.2f", in.LedgerCapacityUsage),
+		MinAcceptedFee:      in.Min,
+		ModeAcceptedFee:     in.Mode,
+		P10AcceptedFee:      p[0],
+		P20AcceptedFee:      p[1],
+		P30AcceptedFee:      p[2],
+		P40AcceptedFee:      p[3],
+		P50AcceptedFee:      p[4],
+		P60AcceptedFee:      p[5],
+		P70AcceptedFee:      p[6],
+		P80AcceptedFee:      p[7],
+		P90AcceptedFee:      p[8],
+		P95AcceptedFee:      p[9],
+		P99AcceptedFee:      p[10],
+	}
+}
@@ -0,0 +1,54 @@
+package horizon
+
+import (
+	"time"
+
+	"github.com/jagregory/halgo"
+	"github.com/stellar/go-stellar-base/xdr"
+
+	"github.com/stellar/horizon/assets"
+	"github.com/stellar/horizon/db"
+)
+
+// HistoricalBalancesResource is the display form of the balances an account
+// held as of the close of a past ledger.
+type HistoricalBalancesResource struct {
+	halgo.Links
+	Address  string            `json:"account_id"`
+	Ledger   int32             `json:"ledger"`
+	ClosedAt time.Time         `json:"closed_at"`
+	Balances []BalanceResource `json:"balances"`
+}
+
+// NewHistoricalBalancesResource converts a HistoricalBalancesRecord into a
+// HistoricalBalancesResource, whose balances are ordered as those of an
+// AccountResource: trustlines first, the native balance last.
+func NewHistoricalBalancesResource(record db.HistoricalBalancesRecord) (HistoricalBalancesResource, error) {
+	balances := make([]BalanceResource, 0, len(record.Trustlines)+1)
+	for _, tl := range record.Trustlines {
+		t, err := assets.String(xdr.AssetType(tl.Assettype))
+		if err != nil {
+			return HistoricalBalancesResource{}, err
+		}
+
+		balances = append(balances, BalanceResource{
+			Type:    t,
+			Balance: AmountToString(tl.Balance),
+			Code:    tl.Assetcode,
+			Issuer:  tl.Issuer,
+			Limit:   AmountToString(tl.Tlimit),
+		})
+	}
+	balances = append(balances, BalanceResource{Type: "native", Balance: AmountToString(record.Balance)})
+
+	return HistoricalBalancesResource{
+		Links: halgo.Links{}.
+			Self("/accounts/%s/balances?at_ledger=%d", record.Address, record.Ledger).
+			Link("account", "/accounts/%s", record.Address).
+			Link("ledger", "/ledgers/%d", record.Ledger),
+		Address:  record.Address,
+		Ledger:   record.Ledger,
+		ClosedAt: record.ClosedAt,
+		Balances: balances,
+	}, nil
+}
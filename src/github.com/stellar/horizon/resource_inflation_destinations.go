@@ -0,0 +1,46 @@
+package horizon
+
+import (
+	"github.com/jagregory/halgo"
+	"github.com/stellar/horizon/db"
+)
+
+// InflationDestinationsResource is the display form of the tally of the
+// votes for each inflation destination, as of the stellar-core ledger Ledger.
+// The votes of a destination are the balances, in lumens, of the accounts
+// voting for it.
+type InflationDestinationsResource struct {
+	halgo.Links
+	Ledger       int32                          `json:"ledger"`
+	Destinations []InflationDestinationResource `json:"destinations"`
+}
+
+// InflationDestinationResource totals the votes for a single inflation
+// destination
+type InflationDestinationResource struct {
+	halgo.Links
+	Destination string `json:"destination"`
+	NumAccounts int32  `json:"num_accounts"`
+	Votes       string `json:"votes"`
+}
+
+// NewInflationDestinationsResource creates a new resource of the records
+// tallied as of ledger, rendered at the url self.
+func NewInflationDestinationsResource(self string, ledger int32, records []db.CoreInflationDestinationRecord) InflationDestinationsResource {
+	result := InflationDestinationsResource{
+		Links:        halgo.Links{}.Self("%s", self),
+		Ledger:       ledger,
+		Destinations: make([]InflationDestinationResource, len(records)),
+	}
+
+	for i, record := range records {
+		result.Destinations[i] = InflationDestinationResource{
+			Links:       halgo.Links{}.Link("account", "/accounts/%s", record.Destination),
+			Destination: record.Destination,
+			NumAccounts: record.NumAccounts,
+			Votes:       AmountToString(record.Votes),
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,51 @@
+package horizon
+
+import (
+	"time"
+
+	"github.com/stellar/horizon/ingest"
+)
+
+// IngestionStatusResource is the display form of the progress of ingestion:
+// the latest ledger ingested, how far it trails stellar-core, the gaps in
+// history being backfilled and whether it is paused.
+type IngestionStatusResource struct {
+	LatestLedger      int32        `json:"latest_ledger"`
+	CoreLatestLedger  int32        `json:"core_latest_ledger"`
+	LagLedgers        int32        `json:"lag_ledgers"`
+	LagSeconds        int64        `json:"lag_seconds"`
+	RowsPerSecond     float64      `json:"rows_per_second"`
+	Gaps              []ingest.Gap `json:"gaps"`
+	MissingLedgers    int32        `json:"missing_ledgers"`
+	BackfilledLedgers int          `json:"backfilled_ledgers"`
+	GapsDetectedAt    *time.Time   `json:"gaps_detected_at"`
+	BackfillError     string       `json:"backfill_error,omitempty"`
+	Paused            bool         `json:"paused"`
+}
+
+// NewIngestionStatusResource creates a new resource from the status of
+// ingestion and the progress of its backfilling
+func NewIngestionStatusResource(s ingest.Status, p ingest.Progress) IngestionStatusResource {
+	result := IngestionStatusResource{
+		LatestLedger:      s.Cursor,
+		CoreLatestLedger:  s.Latest,
+		LagLedgers:        s.LagLedgers,
+		LagSeconds:        s.LagSeconds,
+		RowsPerSecond:     s.RowsPerSecond,
+		Gaps:              p.Gaps,
+		MissingLedgers:    p.Missing,
+		BackfilledLedgers: p.Backfilled,
+		Paused:            s.Paused,
+	}
+
+	if !p.DetectedAt.IsZero() {
+		detectedAt := p.DetectedAt.UTC()
+		result.GapsDetectedAt = &detectedAt
+	}
+
+	if p.Err != nil {
+		result.BackfillError = p.Err.Error()
+	}
+
+	return result
+}
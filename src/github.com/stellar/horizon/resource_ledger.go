@@ -5,13 +5,18 @@ import (
 	"time"
 
 	"github.com/jagregory/halgo"
+	"github.com/stellar/go-stellar-base/xdr"
 	"github.com/stellar/horizon/db"
 	"github.com/stellar/horizon/render/hal"
 )
 
-// LedgerResource represents the summary of a single ledger
+// LedgerResource represents the summary of a single ledger.  The fields of its
+// LedgerHeaderResource are present while stellar-core keeps the ledger's
+// header, and its OperationTypeCounts while history keeps those of the
+// ledger.
 type LedgerResource struct {
 	halgo.Links
+	*LedgerHeaderResource
 	ID               string    `json:"id"`
 	PagingToken      string    `json:"paging_token"`
 	Hash             string    `json:"hash"`
@@ -20,6 +25,78 @@ type LedgerResource struct {
 	TransactionCount int32     `json:"transaction_count"`
 	OperationCount   int32     `json:"operation_count"`
 	ClosedAt         time.Time `json:"closed_at"`
+
+	OperationTypeCounts map[string]int32 `json:"operation_type_counts,omitempty"`
+}
+
+// LedgerStatsResource breaks the operations of a ledger down by type, naming
+// each type as operation resources do.
+type LedgerStatsResource struct {
+	halgo.Links
+	Sequence            int32            `json:"sequence"`
+	ClosedAt            time.Time        `json:"closed_at"`
+	TransactionCount    int32            `json:"transaction_count"`
+	OperationCount      int32            `json:"operation_count"`
+	OperationTypeCounts map[string]int32 `json:"operation_type_counts"`
+}
+
+// LedgerHeaderResource is the part of a ledger resource read from the header
+// stellar-core keeps of the ledger: the header itself, and the network
+// parameters in force once the ledger closed.
+type LedgerHeaderResource struct {
+	HeaderXDR       string                  `json:"header_xdr,omitempty"`
+	ProtocolVersion int32                   `json:"protocol_version"`
+	BaseFee         int32                   `json:"base_fee"`
+	BaseReserve     int32                   `json:"base_reserve"`
+	MaxTxSetSize    int32                   `json:"max_tx_set_size"`
+	Upgrades        []LedgerUpgradeResource `json:"upgrades"`
+}
+
+// LedgerUpgradeResource is a change to a network parameter applied when a
+// ledger closed.  Type names the parameter: protocol_version, base_fee or
+// max_tx_set_size.
+type LedgerUpgradeResource struct {
+	Type  string `json:"type"`
+	Value int32  `json:"value"`
+}
+
+// NewLedgerHeaderResource creates a new resource from a
+// db.CoreLedgerHeaderRecord, less the header's xdr unless withXDR is set.
+func NewLedgerHeaderResource(in db.CoreLedgerHeaderRecord, withXDR bool) (*LedgerHeaderResource, error) {
+	header, err := in.Header()
+	if err != nil {
+		return nil, err
+	}
+
+	upgrades, err := in.Upgrades()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &LedgerHeaderResource{
+		ProtocolVersion: int32(header.LedgerVersion),
+		BaseFee:         int32(header.BaseFee),
+		BaseReserve:     int32(header.BaseReserve),
+		MaxTxSetSize:    int32(header.MaxTxSetSize),
+		Upgrades:        make([]LedgerUpgradeResource, len(upgrades)),
+	}
+
+	if withXDR {
+		result.HeaderXDR = in.DataXDR
+	}
+
+	for i, u := range upgrades {
+		switch u.Type {
+		case xdr.LedgerUpgradeTypeLedgerUpgradeVersion:
+			result.Upgrades[i] = LedgerUpgradeResource{Type: "protocol_version", Value: int32(u.MustNewLedgerVersion())}
+		case xdr.LedgerUpgradeTypeLedgerUpgradeBaseFee:
+			result.Upgrades[i] = LedgerUpgradeResource{Type: "base_fee", Value: int32(u.MustNewBaseFee())}
+		case xdr.LedgerUpgradeTypeLedgerUpgradeMaxTxSetSize:
+			result.Upgrades[i] = LedgerUpgradeResource{Type: "max_tx_set_size", Value: int32(u.MustNewMaxTxSetSize())}
+		}
+	}
+
+	return result, nil
 }
 
 // NewLedgerResource creates a new resource from a db.LedgerRecord
@@ -30,7 +107,8 @@ func NewLedgerResource(in db.LedgerRecord) LedgerResource {
 			Self(self).
 			Link("transactions", "%s/transactions%s", self, hal.StandardPagingOptions).
 			Link("operations", "%s/operations%s", self, hal.StandardPagingOptions).
-			Link("effects", "%s/effects%s", self, hal.StandardPagingOptions),
+			Link("effects", "%s/effects%s", self, hal.StandardPagingOptions).
+			Link("stats", "%s/stats", self),
 		ID:               in.LedgerHash,
 		PagingToken:      in.PagingToken(),
 		Hash:             in.LedgerHash,
@@ -39,11 +117,45 @@ func NewLedgerResource(in db.LedgerRecord) LedgerResource {
 		TransactionCount: in.TransactionCount,
 		OperationCount:   in.OperationCount,
 		ClosedAt:         in.ClosedAt,
+
+		OperationTypeCounts: operationTypeCounts(in.OperationTypeCounts),
+	}
+}
+
+// NewLedgerStatsResource creates a new resource from a db.LedgerStatsRecord
+func NewLedgerStatsResource(in db.LedgerStatsRecord) LedgerStatsResource {
+	self := fmt.Sprintf("/ledgers/%d", in.Ledger.Sequence)
+	return LedgerStatsResource{
+		Links: halgo.Links{}.
+			Self("%s/stats", self).
+			Link("ledger", self),
+		Sequence:            in.Ledger.Sequence,
+		ClosedAt:            in.Ledger.ClosedAt,
+		TransactionCount:    in.Ledger.TransactionCount,
+		OperationCount:      in.Ledger.OperationCount,
+		OperationTypeCounts: operationTypeCounts(in.OperationTypeCounts),
 	}
 }
 
-func NewLedgerResourcePage(records []db.LedgerRecord, query db.PageQuery) (hal.Page, error) {
-	fmts := "/ledgers?order=%s&limit=%d&cursor=%s"
+// operationTypeCounts returns counts by the resource type name of each
+// operation type, counting the types left out of counts as zero, or nil when
+// counts is.
+func operationTypeCounts(counts db.OperationTypeCounts) map[string]int32 {
+	if counts == nil {
+		return nil
+	}
+
+	result := make(map[string]int32, len(operationResourceTypeNames))
+	for t, name := range operationResourceTypeNames {
+		result[name] = counts[t]
+	}
+
+	return result
+}
+
+func NewLedgerResourcePage(records []db.LedgerRecord, q db.LedgerPageQuery) (hal.Page, error) {
+	query := q.PageQuery
+	fmts := "/ledgers?" + timeRangeParams(q.TimeRange) + "order=%s&limit=%d&cursor=%s"
 	next, prev, err := query.GetContinuations(records)
 	if err != nil {
 		return hal.Page{}, err
@@ -62,3 +174,28 @@ func NewLedgerResourcePage(records []db.LedgerRecord, query db.PageQuery) (hal.P
 		Records: resources,
 	}, nil
 }
+
+// timeRangeParams returns the start_time and end_time params, each followed by
+// an `&`, that restrict a page to r.  Times are given in UTC, and unset ends
+// of the range are left out.
+func timeRangeParams(r db.TimeRange) string {
+	params := ""
+	if !r.Start.IsZero() {
+		params += "start_time=" + r.Start.UTC().Format(time.RFC3339) + "&"
+	}
+	if !r.End.IsZero() {
+		params += "end_time=" + r.End.UTC().Format(time.RFC3339) + "&"
+	}
+
+	return params
+}
+
+// includeFailedParam returns the query string param, followed by &, that
+// includes failed transactions in a page when include is true.
+func includeFailedParam(include bool) string {
+	if !include {
+		return ""
+	}
+
+	return "include_failed=true&"
+}
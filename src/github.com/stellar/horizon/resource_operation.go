@@ -2,6 +2,8 @@ package horizon
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/jagregory/halgo"
 
@@ -52,6 +54,7 @@ func NewOperationResource(op db.OperationRecord) (OperationResource, error) {
 	result["source_account"] = op.SourceAccount
 	result["paging_token"] = op.PagingToken()
 	result["type_i"] = op.Type
+	result["transaction_successful"] = op.TransactionSuccessful
 
 	ts, ok := operationResourceTypeNames[op.Type]
 
@@ -64,10 +67,53 @@ func NewOperationResource(op db.OperationRecord) (OperationResource, error) {
 	return result, nil
 }
 
+// operationTypeNamed returns the operation type whose resource type name is
+// name.
+func operationTypeNamed(name string) (xdr.OperationType, bool) {
+	for t, n := range operationResourceTypeNames {
+		if n == name {
+			return t, true
+		}
+	}
+
+	return 0, false
+}
+
+// operationTypeNames returns the resource type names of every operation type,
+// in the order of the types.
+func operationTypeNames(types ...xdr.OperationType) []string {
+	if len(types) == 0 {
+		for t := range operationResourceTypeNames {
+			types = append(types, t)
+		}
+		sort.Sort(operationTypes(types))
+	}
+
+	names := make([]string, len(types))
+	for i, t := range types {
+		names[i] = operationResourceTypeNames[t]
+	}
+
+	return names
+}
+
+type operationTypes []xdr.OperationType
+
+func (s operationTypes) Len() int           { return len(s) }
+func (s operationTypes) Less(i, j int) bool { return s[i] < s[j] }
+func (s operationTypes) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
 // NewOperationResourcePage initialzed a hal.Page from s a slice of
-// OperationRecords
-func NewOperationResourcePage(records []db.OperationRecord, query db.PageQuery, path string) (hal.Page, error) {
-	fmts := path + "?order=%s&limit=%d&cursor=%s"
+// OperationRecords.  The links of the page keep the query's type filter, time
+// range and failed transactions.
+func NewOperationResourcePage(records []db.OperationRecord, q db.OperationPageQuery, path string) (hal.Page, error) {
+	query := q.PageQuery
+	params := timeRangeParams(q.TimeRange) + includeFailedParam(q.IncludeFailed)
+	if len(q.Types) > 0 {
+		params = "type=" + strings.Join(operationTypeNames(q.Types...), ",") + "&" + params
+	}
+	fmts := path + "?" + params + "order=%s&limit=%d&cursor=%s"
+
 	next, prev, err := query.GetContinuations(records)
 	if err != nil {
 		return hal.Page{}, err
@@ -0,0 +1,72 @@
+package horizon
+
+import (
+	"github.com/jagregory/halgo"
+	"github.com/stellar/horizon/assets"
+	"github.com/stellar/horizon/paths"
+	"github.com/stellar/horizon/render/hal"
+)
+
+// PathResource is the display form of a path found through the order books:
+// sending SourceAmount of the source asset, converted through each asset of
+// Path in turn, delivers DestinationAmount of the destination asset.
+type PathResource struct {
+	SourceAssetType        string          `json:"source_asset_type"`
+	SourceAssetCode        string          `json:"source_asset_code,omitempty"`
+	SourceAssetIssuer      string          `json:"source_asset_issuer,omitempty"`
+	SourceAmount           string          `json:"source_amount"`
+	DestinationAssetType   string          `json:"destination_asset_type"`
+	DestinationAssetCode   string          `json:"destination_asset_code,omitempty"`
+	DestinationAssetIssuer string          `json:"destination_asset_issuer,omitempty"`
+	DestinationAmount      string          `json:"destination_amount"`
+	Path                   []AssetResource `json:"path"`
+}
+
+// NewPathResource converts a paths.Path into a PathResource
+func NewPathResource(p paths.Path) PathResource {
+	source := newPathAssetResource(p.Source)
+	destination := newPathAssetResource(p.Destination)
+
+	result := PathResource{
+		SourceAssetType:        source.AssetType,
+		SourceAssetCode:        source.AssetCode,
+		SourceAssetIssuer:      source.AssetIssuer,
+		SourceAmount:           AmountToString(p.SourceAmount),
+		DestinationAssetType:   destination.AssetType,
+		DestinationAssetCode:   destination.AssetCode,
+		DestinationAssetIssuer: destination.AssetIssuer,
+		DestinationAmount:      AmountToString(p.DestinationAmount),
+		Path:                   make([]AssetResource, len(p.Path)),
+	}
+
+	for i, a := range p.Path {
+		result.Path[i] = newPathAssetResource(a)
+	}
+
+	return result
+}
+
+// NewPathResourcePage creates a page of PathResources.  Paths are not paged, so
+// the page links only to itself.
+func NewPathResourcePage(records []paths.Path, self string) hal.Page {
+	resources := make([]interface{}, len(records))
+	for i, record := range records {
+		resources[i] = NewPathResource(record)
+	}
+
+	return hal.Page{
+		Links:   halgo.Links{}.Self("%s", self),
+		Records: resources,
+	}
+}
+
+func newPathAssetResource(a paths.Asset) AssetResource {
+	// the types of paths.Asset are always valid
+	t, _ := assets.String(a.Type)
+
+	return AssetResource{
+		AssetType:   t,
+		AssetCode:   a.Code,
+		AssetIssuer: a.Issuer,
+	}
+}
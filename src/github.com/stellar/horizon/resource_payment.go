@@ -0,0 +1,120 @@
+package horizon
+
+import (
+	"github.com/jagregory/halgo"
+	"github.com/stellar/go-stellar-base/xdr"
+	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/render/hal"
+	"golang.org/x/net/context"
+)
+
+// paymentReceivedFields are the fields, common to every type of payment, that
+// describe what the recipient of a payment received: the amount_received of
+// the asset_received_* by the account received_by.
+var paymentReceivedFields = []string{
+	"received_by",
+	"amount_received",
+	"asset_received_type",
+	"asset_received_code",
+	"asset_received_issuer",
+}
+
+// NewPaymentResources converts payment operations into OperationResources,
+// extended with the fields of paymentReceivedFields.  The amount received from
+// an account merge is not part of the operation, and is loaded from the
+// account_credited effect the merge produced.
+func NewPaymentResources(ctx context.Context, q db.SqlQuery, records []db.OperationRecord) ([]OperationResource, error) {
+	var merges []int64
+	for _, record := range records {
+		if record.Type == xdr.OperationTypeAccountMerge {
+			merges = append(merges, record.Id)
+		}
+	}
+
+	credits := map[int64]db.EffectRecord{}
+	if len(merges) > 0 {
+		var effects []db.EffectRecord
+		err := db.Select(ctx, db.EffectsByOperationsQuery{
+			SqlQuery:     q,
+			OperationIDs: merges,
+			Type:         db.EffectAccountCredited,
+		}, &effects)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, effect := range effects {
+			credits[effect.HistoryOperationID] = effect
+		}
+	}
+
+	result := make([]OperationResource, len(records))
+	for i, record := range records {
+		r, err := NewOperationResource(record)
+		if err != nil {
+			return nil, err
+		}
+
+		switch record.Type {
+		case xdr.OperationTypeCreateAccount:
+			r["received_by"] = r["account"]
+			r["amount_received"] = r["starting_balance"]
+			r["asset_received_type"] = "native"
+		case xdr.OperationTypePayment, xdr.OperationTypePathPayment:
+			r["received_by"] = r["to"]
+			r["amount_received"] = r["amount"]
+			r["asset_received_type"] = r["asset_type"]
+			r["asset_received_code"] = r["asset_code"]
+			r["asset_received_issuer"] = r["asset_issuer"]
+		case xdr.OperationTypeAccountMerge:
+			r["received_by"] = r["into"]
+			r["asset_received_type"] = "native"
+
+			if credit, ok := credits[record.Id]; ok {
+				details, err := credit.Details()
+				if err != nil {
+					return nil, err
+				}
+				r["amount_received"] = details["amount"]
+			}
+		}
+
+		for _, field := range paymentReceivedFields {
+			if v, ok := r[field]; ok && v == nil {
+				delete(r, field)
+			}
+		}
+
+		result[i] = r
+	}
+
+	return result, nil
+}
+
+// NewPaymentResourcePage initializes a hal.Page from a slice of payment
+// OperationRecords
+func NewPaymentResourcePage(ctx context.Context, q db.SqlQuery, records []db.OperationRecord, query db.PageQuery, path string) (hal.Page, error) {
+	fmts := path + "?order=%s&limit=%d&cursor=%s"
+	next, prev, err := query.GetContinuations(records)
+	if err != nil {
+		return hal.Page{}, err
+	}
+
+	payments, err := NewPaymentResources(ctx, q, records)
+	if err != nil {
+		return hal.Page{}, err
+	}
+
+	resources := make([]interface{}, len(payments))
+	for i, r := range payments {
+		resources[i] = r
+	}
+
+	return hal.Page{
+		Links: halgo.Links{}.
+			Self(fmts, query.Order, query.Limit, query.Cursor).
+			Link("next", fmts, next.Order, next.Limit, next.Cursor).
+			Link("prev", fmts, prev.Order, prev.Limit, prev.Cursor),
+		Records: resources,
+	}, nil
+}
@@ -0,0 +1,78 @@
+package horizon
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/jagregory/halgo"
+	"github.com/stellar/horizon/db"
+)
+
+// PaymentSummaryResource is the display form of the totals of the payments an
+// account received and sent per asset, within the close times it was asked
+// for.  An open end of the range is left out.
+type PaymentSummaryResource struct {
+	halgo.Links
+	Address   string                        `json:"account_id"`
+	StartTime *time.Time                    `json:"start_time,omitempty"`
+	EndTime   *time.Time                    `json:"end_time,omitempty"`
+	Assets    []PaymentSummaryAssetResource `json:"assets"`
+}
+
+// PaymentSummaryAssetResource totals the payments of a single asset
+type PaymentSummaryAssetResource struct {
+	Type          string `json:"asset_type"`
+	Code          string `json:"asset_code,omitempty"`
+	Issuer        string `json:"asset_issuer,omitempty"`
+	Received      string `json:"received"`
+	ReceivedCount int32  `json:"received_count"`
+	Sent          string `json:"sent"`
+	SentCount     int32  `json:"sent_count"`
+}
+
+// NewPaymentSummaryResource converts the PaymentSummaryRecords of the account
+// at address, totalled within r, into a PaymentSummaryResource.
+func NewPaymentSummaryResource(address string, r db.TimeRange, records []db.PaymentSummaryRecord) PaymentSummaryResource {
+	params := url.Values{}
+	if !r.Start.IsZero() {
+		params.Set("start_time", r.Start.Format(time.RFC3339))
+	}
+	if !r.End.IsZero() {
+		params.Set("end_time", r.End.Format(time.RFC3339))
+	}
+
+	self := "/accounts/" + address + "/payments/summary"
+	if len(params) > 0 {
+		self += "?" + params.Encode()
+	}
+
+	result := PaymentSummaryResource{
+		Links: halgo.Links{}.
+			Self("%s", self).
+			Link("account", "/accounts/%s", address).
+			Link("payments", "/accounts/%s/payments", address),
+		Address: address,
+		Assets:  make([]PaymentSummaryAssetResource, len(records)),
+	}
+
+	if !r.Start.IsZero() {
+		result.StartTime = &r.Start
+	}
+	if !r.End.IsZero() {
+		result.EndTime = &r.End
+	}
+
+	for i, record := range records {
+		result.Assets[i] = PaymentSummaryAssetResource{
+			Type:          record.AssetType,
+			Code:          record.AssetCode,
+			Issuer:        record.AssetIssuer,
+			Received:      record.Received,
+			ReceivedCount: record.ReceivedCount,
+			Sent:          record.Sent,
+			SentCount:     record.SentCount,
+		}
+	}
+
+	return result
+}
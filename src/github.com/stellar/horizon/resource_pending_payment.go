@@ -0,0 +1,82 @@
+package horizon
+
+import (
+	"fmt"
+
+	"github.com/jagregory/halgo"
+	"github.com/stellar/horizon/assets"
+	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/render/hal"
+)
+
+// PendingPaymentResource is the display form of a payment that failed because
+// its recipient did not trust the asset delivered.  A wallet can offer to add
+// the missing trustline, after which the sender may retry.
+type PendingPaymentResource struct {
+	halgo.Links
+	ID              string `json:"id"`
+	PagingToken     string `json:"paging_token"`
+	TransactionHash string `json:"transaction_hash"`
+	Ledger          int32  `json:"ledger"`
+	Type            string `json:"type"`
+	From            string `json:"from"`
+	To              string `json:"to"`
+	AssetType       string `json:"asset_type"`
+	AssetCode       string `json:"asset_code,omitempty"`
+	AssetIssuer     string `json:"asset_issuer,omitempty"`
+	Amount          string `json:"amount"`
+	ResultCode      string `json:"result_code"`
+}
+
+// NewPendingPaymentResource converts a PendingPaymentRecord into a
+// PendingPaymentResource
+func NewPendingPaymentResource(record db.PendingPaymentRecord) (PendingPaymentResource, error) {
+	t, err := assets.String(record.AssetType)
+	if err != nil {
+		return PendingPaymentResource{}, err
+	}
+
+	return PendingPaymentResource{
+		Links: halgo.Links{}.
+			Link("transaction", "/transactions/%s", record.TransactionHash).
+			Link("sender", "/accounts/%s", record.From).
+			Link("receiver", "/accounts/%s", record.To),
+		ID:              fmt.Sprintf("%d", record.Id),
+		PagingToken:     record.PagingToken(),
+		TransactionHash: record.TransactionHash,
+		Ledger:          record.LedgerSequence,
+		Type:            operationResourceTypeNames[record.Type],
+		From:            record.From,
+		To:              record.To,
+		AssetType:       t,
+		AssetCode:       record.AssetCode,
+		AssetIssuer:     record.AssetIssuer,
+		Amount:          AmountToString(record.Amount),
+		ResultCode:      record.ResultCode,
+	}, nil
+}
+
+// NewPendingPaymentResourcePage creates a page of PendingPaymentResources
+func NewPendingPaymentResourcePage(records []db.PendingPaymentRecord, query db.PendingPaymentPageQuery) (hal.Page, error) {
+	fmts := "/accounts/%s/pending_payments?order=%s&limit=%d&cursor=%s"
+	next, prev, err := query.GetContinuations(records)
+	if err != nil {
+		return hal.Page{}, err
+	}
+
+	resources := make([]interface{}, len(records))
+	for i, record := range records {
+		resources[i], err = NewPendingPaymentResource(record)
+		if err != nil {
+			return hal.Page{}, err
+		}
+	}
+
+	return hal.Page{
+		Links: halgo.Links{}.
+			Self(fmts, query.Address, query.Order, query.Limit, query.Cursor).
+			Link("next", fmts, query.Address, next.Order, next.Limit, next.Cursor).
+			Link("prev", fmts, query.Address, prev.Order, prev.Limit, prev.Cursor),
+		Records: resources,
+	}, nil
+}
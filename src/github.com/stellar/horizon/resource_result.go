@@ -1,61 +1,84 @@
 package horizon
 
 import (
+	"time"
+
 	"github.com/jagregory/halgo"
+	"github.com/stellar/horizon/codes"
 	"github.com/stellar/horizon/render/problem"
+	"github.com/stellar/horizon/simulate"
 	"github.com/stellar/horizon/txsub"
-	"net/http"
 )
 
 type ResultResource struct {
 	txsub.Result
 }
 
+// invalidTransactionProblems maps the reasons txsub.Validator refuses a
+// transaction for to the problems rendered for them.
+var invalidTransactionProblems = map[string]problem.P{
+	txsub.InvalidWrongNetwork:       problem.TransactionWrongNetwork,
+	txsub.InvalidTimeBounds:         problem.TransactionInvalidTimeBounds,
+	txsub.InvalidTooLate:            problem.TransactionTooLate,
+	txsub.InvalidTooEarly:           problem.TransactionTooEarly,
+	txsub.InvalidNoOperations:       problem.TransactionNoOperations,
+	txsub.InvalidTooManyOperations:  problem.TransactionTooManyOperations,
+	txsub.InvalidFeeTooHigh:         problem.TransactionFeeTooHigh,
+	txsub.InvalidUnsigned:           problem.TransactionUnsigned,
+	txsub.InvalidTooManySignatures:  problem.TransactionTooManySignatures,
+	txsub.InvalidDuplicateSignature: problem.TransactionDuplicateSignature,
+}
+
 type ResultCodesResource struct {
 	TransactionCode string   `json:"transaction"`
 	OperationCodes  []string `json:"operations,omitempty"`
 }
 
-func (res *ResultResource) Error() error {
+// NewResultCodesResource returns the result codes of a transaction stellar-core
+// rejected.
+func NewResultCodesResource(err *txsub.FailedTransactionError) (ResultCodesResource, error) {
+	var rcr ResultCodesResource
 	var ierr error
 
-	switch err := res.Err.(type) {
-	case *txsub.FailedTransactionError:
-		rcr := ResultCodesResource{}
+	rcr.TransactionCode, ierr = err.TransactionResultCode()
+	if ierr != nil {
+		return ResultCodesResource{}, ierr
+	}
 
-		rcr.TransactionCode, ierr = err.TransactionResultCode()
-		if ierr != nil {
-			return ierr
-		}
+	rcr.OperationCodes, ierr = err.OperationResultCodes()
+	if ierr != nil {
+		return ResultCodesResource{}, ierr
+	}
+
+	return rcr, nil
+}
 
-		rcr.OperationCodes, ierr = err.OperationResultCodes()
+func (res *ResultResource) Error() error {
+	switch err := res.Err.(type) {
+	case *txsub.FailedTransactionError:
+		rcr, ierr := NewResultCodesResource(err)
 		if ierr != nil {
 			return ierr
 		}
 
-		// TODO: Fill detail
-		return &problem.P{
-			Type:   "transaction_failed",
-			Title:  "Transaction Failed",
-			Status: http.StatusBadRequest,
-			Detail: "TODO",
-			Extras: map[string]interface{}{
-				"envelope_xdr": res.EnvelopeXDR,
-				"result_xdr":   err.ResultXDR,
-				"result_codes": rcr,
-			},
-		}
+		return problem.TransactionFailed.With(map[string]interface{}{
+			"envelope_xdr": res.EnvelopeXDR,
+			"result_xdr":   err.ResultXDR,
+			"result_codes": rcr,
+		})
 	case *txsub.MalformedTransactionError:
-		// TODO: Fill detail
-		return &problem.P{
-			Type:   "transaction_malformed",
-			Title:  "Transaction Malformed",
-			Status: http.StatusBadRequest,
-			Detail: "TODO",
-			Extras: map[string]interface{}{
-				"envelope_xdr": err.EnvelopeXDR,
-			},
+		return problem.TransactionMalformed.With(map[string]interface{}{
+			"envelope_xdr": err.EnvelopeXDR,
+		})
+	case *txsub.InvalidTransactionError:
+		p, ok := invalidTransactionProblems[err.Reason]
+		if !ok {
+			return err
 		}
+
+		return p.With(map[string]interface{}{
+			"envelope_xdr": err.EnvelopeXDR,
+		})
 	default:
 		return err
 	}
@@ -84,3 +107,124 @@ func (res *ResultResource) Success() interface{} {
 	}
 
 }
+
+// SimulationResource is the predicted outcome of submitting a transaction, as
+// simulated against the ledger whose sequence is Ledger.  FeeCharged is zero
+// for transactions stellar-core would reject outright.  UncheckedOperations
+// holds the indexes of the operations whose success was assumed rather than
+// simulated.
+type SimulationResource struct {
+	halgo.Links
+	Hash                string              `json:"hash"`
+	Ledger              int32               `json:"ledger"`
+	Successful          bool                `json:"successful"`
+	FeeCharged          int64               `json:"fee_charged"`
+	ResultCodes         ResultCodesResource `json:"result_codes"`
+	UncheckedOperations []int               `json:"unchecked_operations"`
+}
+
+// NewSimulationResource returns the resource of r, simulated against ledger.
+func NewSimulationResource(ledger int32, r simulate.Result) (SimulationResource, error) {
+	resource := SimulationResource{
+		Links:               halgo.Links{}.Link("transaction", "/transactions/%s", r.Hash),
+		Hash:                r.Hash,
+		Ledger:              ledger,
+		Successful:          r.Successful(),
+		FeeCharged:          r.Fee,
+		UncheckedOperations: []int{},
+	}
+
+	var err error
+	resource.ResultCodes.TransactionCode, err = codes.String(r.Code)
+	if err != nil {
+		return SimulationResource{}, err
+	}
+
+	for i, op := range r.Operations {
+		code, err := codes.String(op.Code)
+		if err != nil {
+			return SimulationResource{}, err
+		}
+
+		resource.ResultCodes.OperationCodes = append(resource.ResultCodes.OperationCodes, code)
+		if !op.Checked {
+			resource.UncheckedOperations = append(resource.UncheckedOperations, i)
+		}
+	}
+
+	return resource, nil
+}
+
+// The statuses of a TransactionStatusResource
+const (
+	TransactionStatusPending = "pending"
+	TransactionStatusSuccess = "success"
+	TransactionStatusFailed  = "failed"
+)
+
+// TransactionStatusResource is the status of a transaction submitted without
+// waiting for its result: pending until it is included in a ledger, and then
+// either success or failed.
+type TransactionStatusResource struct {
+	halgo.Links
+	Hash        string               `json:"hash"`
+	Status      string               `json:"status"`
+	Ledger      int32                `json:"ledger,omitempty"`
+	EnvelopeXDR string               `json:"envelope_xdr,omitempty"`
+	ResultXDR   string               `json:"result_xdr,omitempty"`
+	ResultCodes *ResultCodesResource `json:"result_codes,omitempty"`
+	Attempts    []AttemptResource    `json:"attempts,omitempty"`
+}
+
+// AttemptResource is an attempt made to submit a transaction to stellar-core,
+// along with the error it failed with, if any.
+type AttemptResource struct {
+	SubmittedAt time.Time `json:"submitted_at"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// NewTransactionStatusResource returns the status of the transaction with the
+// provided hash, given its result as looked up by txsub.System.Status and the
+// attempts made to submit it, if resubmission is enabled.  Results other than
+// those of pending, successful or failed transactions are returned as errors.
+func NewTransactionStatusResource(hash string, r txsub.Result, pending bool, attempts []txsub.Attempt) (TransactionStatusResource, error) {
+	resource := TransactionStatusResource{
+		Links: halgo.Links{}.
+			Self("/transactions/%s/status", hash).
+			Link("transaction", "/transactions/%s", hash),
+		Hash:        hash,
+		Ledger:      r.LedgerSequence,
+		EnvelopeXDR: r.EnvelopeXDR,
+		ResultXDR:   r.ResultXDR,
+	}
+
+	for _, a := range attempts {
+		ar := AttemptResource{SubmittedAt: a.SubmittedAt}
+		if a.Err != nil {
+			ar.Error = a.Err.Error()
+		}
+		resource.Attempts = append(resource.Attempts, ar)
+	}
+
+	failed, isFailed := r.Err.(*txsub.FailedTransactionError)
+
+	switch {
+	case r.Err == nil:
+		resource.Status = TransactionStatusSuccess
+	case isFailed:
+		rcr, err := NewResultCodesResource(failed)
+		if err != nil {
+			return TransactionStatusResource{}, err
+		}
+
+		resource.Status = TransactionStatusFailed
+		resource.ResultXDR = failed.ResultXDR
+		resource.ResultCodes = &rcr
+	case r.Err == txsub.ErrNoResults && pending:
+		resource.Status = TransactionStatusPending
+	default:
+		return TransactionStatusResource{}, r.Err
+	}
+
+	return resource, nil
+}
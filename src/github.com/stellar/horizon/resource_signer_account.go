@@ -0,0 +1,54 @@
+package horizon
+
+import (
+	"github.com/jagregory/halgo"
+
+	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/render/hal"
+)
+
+// SignerAccountResource is the display form of an account a key signs for,
+// by way of being one of its signers.
+type SignerAccountResource struct {
+	halgo.Links
+	Account     string `json:"account_id"`
+	PagingToken string `json:"paging_token"`
+	Signer      string `json:"signer"`
+	Weight      int32  `json:"weight"`
+}
+
+// NewSignerAccountResource converts a SignerRecord into a
+// SignerAccountResource
+func NewSignerAccountResource(record db.SignerRecord) SignerAccountResource {
+	return SignerAccountResource{
+		Links: halgo.Links{}.
+			Link("account", "/accounts/%s", record.Accountid),
+		Account:     record.Accountid,
+		PagingToken: record.PagingToken(),
+		Signer:      record.Publickey,
+		Weight:      record.Weight,
+	}
+}
+
+// NewSignerAccountResourcePage creates a page of SignerAccountResources for
+// the signer of the query.
+func NewSignerAccountResourcePage(records []db.SignerRecord, query db.SignerAccountsPageQuery) (hal.Page, error) {
+	fmts := "/signers/%s/accounts?order=%s&limit=%d&cursor=%s"
+	next, prev, err := query.GetContinuations(records)
+	if err != nil {
+		return hal.Page{}, err
+	}
+
+	resources := make([]interface{}, len(records))
+	for i, record := range records {
+		resources[i] = NewSignerAccountResource(record)
+	}
+
+	return hal.Page{
+		Links: halgo.Links{}.
+			Self(fmts, query.Signer, query.Order, query.Limit, query.Cursor).
+			Link("next", fmts, query.Signer, next.Order, next.Limit, next.Cursor).
+			Link("prev", fmts, query.Signer, prev.Order, prev.Limit, prev.Cursor),
+		Records: resources,
+	}, nil
+}
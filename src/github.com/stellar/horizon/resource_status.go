@@ -0,0 +1,33 @@
+package horizon
+
+import (
+	"time"
+
+	"github.com/stellar/horizon/db"
+)
+
+// StatusResource is the data of the status events periodically sent to
+// streaming clients, allowing them to detect when horizon has fallen behind the
+// network.  IngestionLag is the number of ledgers stellar-core has closed that
+// horizon has yet to ingest.
+type StatusResource struct {
+	HorizonSequence     int32     `json:"horizon_latest_ledger"`
+	StellarCoreSequence int32     `json:"core_latest_ledger"`
+	IngestionLag        int32     `json:"ingestion_lag"`
+	ServerTime          time.Time `json:"server_time"`
+}
+
+// NewStatusResource creates a new resource from a db.LedgerState, as of now.
+func NewStatusResource(in db.LedgerState, now time.Time) StatusResource {
+	lag := in.StellarCoreSequence - in.HorizonSequence
+	if lag < 0 {
+		lag = 0
+	}
+
+	return StatusResource{
+		HorizonSequence:     in.HorizonSequence,
+		StellarCoreSequence: in.StellarCoreSequence,
+		IngestionLag:        lag,
+		ServerTime:          now.UTC(),
+	}
+}
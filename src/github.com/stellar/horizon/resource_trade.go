@@ -2,6 +2,8 @@ package horizon
 
 import (
 	"errors"
+	"math/big"
+
 	"github.com/jagregory/halgo"
 	"github.com/stellar/horizon/db"
 	"github.com/stellar/horizon/render/hal"
@@ -25,6 +27,11 @@ type TradeResource struct {
 	BoughtAssetType   interface{} `json:"bought_asset_type"`
 	BoughtAssetCode   interface{} `json:"bought_asset_code,omitempty"`
 	BoughtAssetIssuer interface{} `json:"bought_asset_issuer,omitempty"`
+	OfferID           interface{} `json:"offer_id"`
+	SoldAmount        interface{} `json:"sold_amount"`
+	BoughtAmount      interface{} `json:"bought_amount"`
+	Price             string      `json:"price,omitempty"`
+	Counterparty      string      `json:"counterparty"`
 }
 
 // NewTradeResource initializes a new resource from an EffectRecord
@@ -52,6 +59,7 @@ func NewTradeResource(r db.EffectRecord) (result TradeResource, err error) {
 		Links: halgo.Links{}.
 			Link("seller", "/accounts/%s", seller).
 			Link("buyer", "/accounts/%s", r.Account).
+			Link("counterparty", "/accounts/%s", seller).
 			Link("order_book", "/order_book?TODO"),
 		ID:                r.PagingToken(),
 		PagingToken:       r.PagingToken(),
@@ -63,11 +71,41 @@ func NewTradeResource(r db.EffectRecord) (result TradeResource, err error) {
 		BoughtAssetType:   details["bought_asset_type"],
 		BoughtAssetCode:   details["bought_asset_code"],
 		BoughtAssetIssuer: details["bought_asset_issuer"],
+		OfferID:           details["offer_id"],
+		SoldAmount:        details["sold_amount"],
+		BoughtAmount:      details["bought_amount"],
+		Price:             tradePrice(details["sold_amount"], details["bought_amount"]),
+		Counterparty:      seller,
 	}
 
 	return
 }
 
+// tradePrice returns the price the sold asset traded at, as the amount of the
+// bought asset paid for each unit sold.  Returns an empty string when either
+// amount is missing or the sold amount is zero.
+func tradePrice(sold, bought interface{}) string {
+	s, ok := sold.(string)
+	if !ok {
+		return ""
+	}
+
+	b, ok := bought.(string)
+	if !ok {
+		return ""
+	}
+
+	var sr, br big.Rat
+	if _, ok := sr.SetString(s); !ok || sr.Sign() == 0 {
+		return ""
+	}
+	if _, ok := br.SetString(b); !ok {
+		return ""
+	}
+
+	return br.Quo(&br, &sr).FloatString(7)
+}
+
 // NewTradeResourcePage initialzed a hal.Page from s a slice of
 // EffectRecords
 func NewTradeResourcePage(records []db.EffectRecord, query db.PageQuery, path string) (hal.Page, error) {
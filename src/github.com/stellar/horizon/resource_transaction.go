@@ -18,6 +18,7 @@ type TransactionResource struct {
 	ID              string    `json:"id"`
 	PagingToken     string    `json:"paging_token"`
 	Hash            string    `json:"hash"`
+	Successful      bool      `json:"successful"`
 	Ledger          int32     `json:"ledger"`
 	LedgerCloseTime time.Time `json:"created_at"`
 	Account         string    `json:"source_account"`
@@ -25,9 +26,9 @@ type TransactionResource struct {
 	MaxFee          int32     `json:"max_fee"`
 	FeePaid         int32     `json:"fee_paid"`
 	OperationCount  int32     `json:"operation_count"`
-	EnvelopeXdr     string    `json:"envelope_xdr"`
-	ResultXdr       string    `json:"result_xdr"`
-	ResultMetaXdr   string    `json:"result_meta_xdr"`
+	EnvelopeXdr     string    `json:"envelope_xdr,omitempty"`
+	ResultXdr       string    `json:"result_xdr,omitempty"`
+	ResultMetaXdr   string    `json:"result_meta_xdr,omitempty"`
 	MemoType        string    `json:"memo_type"`
 	Memo            string    `json:"memo,omitempty"`
 	Signatures      []string  `json:"signatures"`
@@ -58,6 +59,7 @@ func NewTransactionResource(tx db.TransactionRecord) TransactionResource {
 		ID:              tx.TransactionHash,
 		PagingToken:     tx.PagingToken(),
 		Hash:            tx.TransactionHash,
+		Successful:      tx.Successful,
 		Ledger:          tx.LedgerSequence,
 		LedgerCloseTime: tx.LedgerCloseTime,
 		Account:         tx.Account,
@@ -76,10 +78,25 @@ func NewTransactionResource(tx db.TransactionRecord) TransactionResource {
 	}
 }
 
+// WithoutXDR returns the resource less its xdr fields, which then are omitted
+// from its json form.
+func (r TransactionResource) WithoutXDR() TransactionResource {
+	r.EnvelopeXdr = ""
+	r.ResultXdr = ""
+	r.ResultMetaXdr = ""
+	return r
+}
+
 // NewTransactionResourcePage initialzed a hal.Page from s a slice of
-// OperationRecords
-func NewTransactionResourcePage(records []db.TransactionRecord, query db.PageQuery, path string) (hal.Page, error) {
-	fmts := path + "?order=%s&limit=%d&cursor=%s"
+// TransactionRecords.  The links of the page keep the query's time range and
+// failed transactions, and leave out xdr fields unless withXDR.
+func NewTransactionResourcePage(records []db.TransactionRecord, q db.TransactionPageQuery, withXDR bool, path string) (hal.Page, error) {
+	query := q.PageQuery
+	params := timeRangeParams(q.TimeRange) + includeFailedParam(q.IncludeFailed)
+	if !withXDR {
+		params += "include_xdr=false&"
+	}
+	fmts := path + "?" + params + "order=%s&limit=%d&cursor=%s"
 	next, prev, err := query.GetContinuations(records)
 	if err != nil {
 		return hal.Page{}, err
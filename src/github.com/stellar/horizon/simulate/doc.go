@@ -0,0 +1,16 @@
+// Package simulate predicts the outcome of a transaction without submitting
+// it: the result codes stellar-core would give it, were it applied to the
+// current ledger, and the fee it would be charged.
+//
+// Transactions are simulated against a Ledger: a snapshot of the network
+// parameters in force and of the accounts the transaction touches.  As
+// stellar-core does, simulation first checks that the transaction is valid,
+// verifying its time bounds, fee, sequence number, signatures and the balance
+// of its source account, then applies each of its operations in turn.
+//
+// Create account, payment and change trust operations are simulated in full.
+// The outcome of the others depends on state a snapshot does not hold, such as
+// the order books, so only their source account and signatures are checked,
+// and they are otherwise predicted to succeed.  Such results are marked as
+// unchecked.
+package simulate
@@ -0,0 +1,366 @@
+package simulate
+
+import (
+	"bytes"
+	"encoding/hex"
+	"sort"
+	"strings"
+
+	"github.com/go-errors/errors"
+	"github.com/stellar/go-stellar-base"
+	"github.com/stellar/go-stellar-base/xdr"
+	"github.com/stellar/horizon/strkeys"
+)
+
+// Asset identifies the asset of a trustline.
+type Asset struct {
+	Type   xdr.AssetType
+	Code   string
+	Issuer string
+}
+
+// Account is the state of an account that simulation depends upon.  Signers
+// holds the weights of the account's signers other than its master key, whose
+// weight is amongst its Thresholds.
+type Account struct {
+	Address      string
+	Balance      int64
+	Sequence     int64
+	SubEntries   int32
+	Thresholds   xdr.Thresholds
+	AuthRequired bool
+	Signers      map[string]int32
+	Trustlines   map[Asset]*Trustline
+}
+
+// Trustline is an account's holding of a credit asset.
+type Trustline struct {
+	Balance    int64
+	Limit      int64
+	Authorized bool
+}
+
+// Ledger is the snapshot a transaction is simulated against: the latest
+// ledger closed, whose successor the transaction would be applied in.
+// Accounts holds by address those accounts the transaction touches that exist,
+// as found with Addresses.
+//
+// Simulation modifies the accounts of the ledger as it applies operations, so
+// a Ledger must not be used for more than one simulation.
+type Ledger struct {
+	Sequence    int32
+	CloseTime   uint64
+	BaseFee     int32
+	BaseReserve int32
+	Accounts    map[string]*Account
+}
+
+// MinimumBalance returns the balance below which account cannot go, given the
+// reserve each of its subentries and the account itself holds.
+func (l *Ledger) MinimumBalance(account *Account) int64 {
+	return int64(2+account.SubEntries) * int64(l.BaseReserve)
+}
+
+// Result is the predicted outcome of a transaction.  Fee is zero when the
+// transaction is invalid, as stellar-core would reject it without charging it.
+// Operations is empty when the transaction fails before any of its operations
+// are checked.
+type Result struct {
+	Hash       string
+	Fee        int64
+	Code       xdr.TransactionResultCode
+	Operations []OperationResult
+}
+
+// Successful returns true when the transaction is predicted to succeed.
+func (r Result) Successful() bool {
+	return r.Code == xdr.TransactionResultCodeTxSuccess
+}
+
+// OperationResult is the predicted outcome of an operation.  Code is an
+// xdr.OperationResultCode when the operation is not applied for want of a
+// source account or signatures, and otherwise the result code of the
+// operation's type, such as an xdr.PaymentResultCode.  Either can be rendered
+// with codes.String.
+//
+// Checked is false when the outcome of applying the operation was assumed
+// rather than simulated.
+type OperationResult struct {
+	Code    interface{}
+	Checked bool
+}
+
+// Simulate predicts the outcome of env, signed for the network with
+// passphrase, were it applied to l.
+func Simulate(l *Ledger, passphrase string, env xdr.TransactionEnvelope) (Result, error) {
+	tx := env.Tx
+
+	hash, err := Hash(tx, passphrase)
+	if err != nil {
+		return Result{}, err
+	}
+
+	s := &simulation{
+		ledger:     l,
+		signatures: newSignatures(hash, env.Signatures),
+	}
+
+	result := Result{Hash: hex.EncodeToString(hash[:])}
+
+	source, err := address(tx.SourceAccount)
+	if err != nil {
+		return Result{}, err
+	}
+
+	result.Code = s.checkTransaction(tx, source)
+	if result.Code != xdr.TransactionResultCodeTxSuccess {
+		return result, nil
+	}
+
+	sources := make([]string, len(tx.Operations))
+	result.Operations = make([]OperationResult, len(tx.Operations))
+	for i, op := range tx.Operations {
+		sources[i] = source
+		if op.SourceAccount != nil {
+			sources[i], err = address(*op.SourceAccount)
+			if err != nil {
+				return Result{}, err
+			}
+		}
+
+		result.Operations[i], err = s.checkOperation(sources[i], op)
+		if err != nil {
+			return Result{}, err
+		}
+
+		if !succeeded(result.Operations[i].Code) {
+			result.Code = xdr.TransactionResultCodeTxFailed
+		}
+	}
+
+	if result.Code != xdr.TransactionResultCodeTxSuccess {
+		return result, nil
+	}
+
+	if !s.signatures.allUsed() {
+		result.Code = xdr.TransactionResultCodeTxBadAuthExtra
+		result.Operations = nil
+		return result, nil
+	}
+
+	// the transaction is valid: from here on, it is charged its fee and
+	// consumes its sequence number whatever becomes of its operations.
+	account := l.Accounts[source]
+	result.Fee = int64(tx.Fee)
+	account.Balance -= result.Fee
+	account.Sequence = int64(tx.SeqNum)
+
+	for i, op := range tx.Operations {
+		result.Operations[i], err = s.apply(sources[i], op)
+		if err != nil {
+			return Result{}, err
+		}
+
+		if !succeeded(result.Operations[i].Code) {
+			result.Code = xdr.TransactionResultCodeTxFailed
+		}
+	}
+
+	return result, nil
+}
+
+// Addresses returns the addresses of the accounts simulating env depends upon:
+// the sources of the transaction and of its operations, along with the
+// accounts its operations create, pay, trust or merge into.
+func Addresses(env xdr.TransactionEnvelope) ([]string, error) {
+	seen := map[string]bool{}
+	var result []string
+
+	add := func(aid xdr.AccountId) error {
+		a, err := address(aid)
+		if err != nil {
+			return err
+		}
+
+		if !seen[a] {
+			seen[a] = true
+			result = append(result, a)
+		}
+		return nil
+	}
+
+	ids := []xdr.AccountId{env.Tx.SourceAccount}
+	for _, op := range env.Tx.Operations {
+		if op.SourceAccount != nil {
+			ids = append(ids, *op.SourceAccount)
+		}
+
+		switch op.Body.Type {
+		case xdr.OperationTypeCreateAccount:
+			ids = append(ids, op.Body.MustCreateAccountOp().Destination)
+		case xdr.OperationTypePayment:
+			p := op.Body.MustPaymentOp()
+			ids = append(ids, p.Destination)
+			ids = appendIssuer(ids, p.Asset)
+		case xdr.OperationTypePathPayment:
+			ids = append(ids, op.Body.MustPathPaymentOp().Destination)
+		case xdr.OperationTypeChangeTrust:
+			ids = appendIssuer(ids, op.Body.MustChangeTrustOp().Line)
+		case xdr.OperationTypeAllowTrust:
+			ids = append(ids, op.Body.MustAllowTrustOp().Trustor)
+		case xdr.OperationTypeAccountMerge:
+			ids = append(ids, op.Body.MustDestination())
+		}
+	}
+
+	for _, aid := range ids {
+		err := add(aid)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// Hash returns the hash of tx on the network with passphrase: the message its
+// signatures sign.
+func Hash(tx xdr.Transaction, passphrase string) ([32]byte, error) {
+	var buf bytes.Buffer
+
+	network := stellarbase.Hash([]byte(passphrase))
+	buf.Write(network[:])
+
+	_, err := xdr.Marshal(&buf, xdr.EnvelopeTypeEnvelopeTypeTx)
+	if err != nil {
+		return [32]byte{}, errors.Wrap(err, 1)
+	}
+
+	_, err = xdr.Marshal(&buf, tx)
+	if err != nil {
+		return [32]byte{}, errors.Wrap(err, 1)
+	}
+
+	return stellarbase.Hash(buf.Bytes()), nil
+}
+
+// simulation is the state of a single call to Simulate.
+type simulation struct {
+	ledger     *Ledger
+	signatures *signatures
+}
+
+// checkTransaction returns the code of the first check tx fails of those
+// stellar-core makes of the transaction as a whole, or tx_success.
+func (s *simulation) checkTransaction(tx xdr.Transaction, source string) xdr.TransactionResultCode {
+	if len(tx.Operations) == 0 {
+		return xdr.TransactionResultCodeTxMissingOperation
+	}
+
+	if tb := tx.TimeBounds; tb != nil {
+		if uint64(tb.MinTime) > s.ledger.CloseTime {
+			return xdr.TransactionResultCodeTxTooEarly
+		}
+		if tb.MaxTime != 0 && uint64(tb.MaxTime) < s.ledger.CloseTime {
+			return xdr.TransactionResultCodeTxTooLate
+		}
+	}
+
+	if int64(tx.Fee) < int64(s.ledger.BaseFee)*int64(len(tx.Operations)) {
+		return xdr.TransactionResultCodeTxInsufficientFee
+	}
+
+	account, ok := s.ledger.Accounts[source]
+	if !ok {
+		return xdr.TransactionResultCodeTxNoAccount
+	}
+
+	if int64(tx.SeqNum) != account.Sequence+1 {
+		return xdr.TransactionResultCodeTxBadSeq
+	}
+
+	if !s.signatures.check(account, xdr.ThresholdIndexesThresholdLow) {
+		return xdr.TransactionResultCodeTxBadAuth
+	}
+
+	if account.Balance-int64(tx.Fee) < s.ledger.MinimumBalance(account) {
+		return xdr.TransactionResultCodeTxInsufficientBalance
+	}
+
+	return xdr.TransactionResultCodeTxSuccess
+}
+
+// succeeded returns true when code, as found in an OperationResult, is that of
+// a successful operation.
+func succeeded(code interface{}) bool {
+	switch code := code.(type) {
+	case xdr.OperationResultCode:
+		return false
+	case xdr.CreateAccountResultCode:
+		return code == xdr.CreateAccountResultCodeCreateAccountSuccess
+	case xdr.PaymentResultCode:
+		return code == xdr.PaymentResultCodePaymentSuccess
+	case xdr.ChangeTrustResultCode:
+		return code == xdr.ChangeTrustResultCodeChangeTrustSuccess
+	default:
+		// operations that are not simulated are assumed to succeed
+		return true
+	}
+}
+
+// address returns the strkey address of aid
+func address(aid xdr.AccountId) (string, error) {
+	result, err := strkeys.Address(aid)
+	if err != nil {
+		return "", errors.Wrap(err, 1)
+	}
+
+	return result, nil
+}
+
+// assetOf returns the Asset identifying a, which must not be native.
+func assetOf(a xdr.Asset) (Asset, error) {
+	var raw []byte
+	var issuer xdr.AccountId
+
+	switch a.Type {
+	case xdr.AssetTypeAssetTypeCreditAlphanum4:
+		an := a.MustAlphaNum4()
+		raw, issuer = an.AssetCode[:], an.Issuer
+	case xdr.AssetTypeAssetTypeCreditAlphanum12:
+		an := a.MustAlphaNum12()
+		raw, issuer = an.AssetCode[:], an.Issuer
+	default:
+		return Asset{}, errors.New("native asset has no trustline")
+	}
+
+	result := Asset{Type: a.Type, Code: strings.TrimRight(string(raw), "\x00")}
+
+	var err error
+	result.Issuer, err = address(issuer)
+	return result, err
+}
+
+// appendIssuer appends the issuer of a to ids, unless a is native.
+func appendIssuer(ids []xdr.AccountId, a xdr.Asset) []xdr.AccountId {
+	switch a.Type {
+	case xdr.AssetTypeAssetTypeCreditAlphanum4:
+		return append(ids, a.MustAlphaNum4().Issuer)
+	case xdr.AssetTypeAssetTypeCreditAlphanum12:
+		return append(ids, a.MustAlphaNum12().Issuer)
+	}
+
+	return ids
+}
+
+// signerAddresses returns the addresses of account's signers other than its
+// master key, in order.
+func signerAddresses(account *Account) []string {
+	result := make([]string, 0, len(account.Signers))
+	for a := range account.Signers {
+		result = append(result, a)
+	}
+
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,332 @@
+package simulate
+
+import (
+	"encoding/hex"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/go-stellar-base"
+	"github.com/stellar/go-stellar-base/xdr"
+)
+
+const passphrase = "Test SDF Network ; September 2015"
+
+type testKey struct {
+	stellarbase.PrivateKey
+}
+
+func newTestKey(b byte) testKey {
+	var seed stellarbase.RawSeed
+	seed[0] = b
+
+	_, priv, err := stellarbase.GenerateKeyFromRawSeed(seed)
+	if err != nil {
+		panic(err)
+	}
+	return testKey{priv}
+}
+
+func (k testKey) aid() xdr.AccountId {
+	aid, err := stellarbase.AddressToAccountId(k.Address())
+	if err != nil {
+		panic(err)
+	}
+	return aid
+}
+
+func (k testKey) credit(code string) xdr.Asset {
+	var an xdr.AssetAlphaNum4
+	copy(an.AssetCode[:], code)
+	an.Issuer = k.aid()
+
+	asset, err := xdr.NewAsset(xdr.AssetTypeAssetTypeCreditAlphanum4, an)
+	if err != nil {
+		panic(err)
+	}
+	return asset
+}
+
+func operation(source *testKey, t xdr.OperationType, body interface{}) xdr.Operation {
+	b, err := xdr.NewOperationBody(t, body)
+	if err != nil {
+		panic(err)
+	}
+
+	op := xdr.Operation{Body: b}
+	if source != nil {
+		aid := source.aid()
+		op.SourceAccount = &aid
+	}
+	return op
+}
+
+func envelope(source testKey, seq int64, fee uint32, ops ...xdr.Operation) xdr.TransactionEnvelope {
+	memo, err := xdr.NewMemo(xdr.MemoTypeMemoNone, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	return xdr.TransactionEnvelope{Tx: xdr.Transaction{
+		SourceAccount: source.aid(),
+		Fee:           xdr.Uint32(fee),
+		SeqNum:        xdr.SequenceNumber(seq),
+		Memo:          memo,
+		Operations:    ops,
+	}}
+}
+
+func sign(env *xdr.TransactionEnvelope, keys ...testKey) {
+	hash, err := Hash(env.Tx, passphrase)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, k := range keys {
+		sig := k.Sign(hash[:])
+		env.Signatures = append(env.Signatures, xdr.DecoratedSignature{
+			Hint:      xdr.SignatureHint(k.Hint()),
+			Signature: xdr.Signature(sig[:]),
+		})
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	alice, bob, carol, issuer := newTestKey(1), newTestKey(2), newTestKey(3), newTestKey(4)
+	usd := issuer.credit("USD")
+
+	newLedger := func() *Ledger {
+		account := func(k testKey, balance int64) *Account {
+			return &Account{
+				Address:    k.Address(),
+				Balance:    balance,
+				Sequence:   100,
+				Thresholds: xdr.Thresholds{1, 0, 0, 0},
+				Signers:    map[string]int32{},
+				Trustlines: map[Asset]*Trustline{},
+			}
+		}
+
+		l := &Ledger{
+			Sequence:    10,
+			CloseTime:   1000,
+			BaseFee:     100,
+			BaseReserve: 10000000,
+			Accounts:    map[string]*Account{},
+		}
+		for _, a := range []*Account{
+			account(alice, 1000000000),
+			account(bob, 1000000000),
+			account(issuer, 1000000000),
+		} {
+			l.Accounts[a.Address] = a
+		}
+		return l
+	}
+
+	pay := func(source *testKey, to testKey, asset xdr.Asset, amount int64) xdr.Operation {
+		return operation(source, xdr.OperationTypePayment, xdr.PaymentOp{
+			Destination: to.aid(),
+			Asset:       asset,
+			Amount:      xdr.Int64(amount),
+		})
+	}
+
+	native, err := xdr.NewAsset(xdr.AssetTypeAssetTypeNative, nil)
+	if err != nil {
+		panic(err)
+	}
+
+	Convey("simulate.Simulate", t, func() {
+		l := newLedger()
+
+		Convey("predicts a valid payment succeeds, charging its fee", func() {
+			env := envelope(alice, 101, 100, pay(nil, bob, native, 50000000))
+			sign(&env, alice)
+
+			r, err := Simulate(l, passphrase, env)
+			So(err, ShouldBeNil)
+			So(r.Successful(), ShouldBeTrue)
+			So(r.Fee, ShouldEqual, 100)
+			So(r.Operations, ShouldResemble, []OperationResult{{xdr.PaymentResultCodePaymentSuccess, true}})
+
+			hash, _ := Hash(env.Tx, passphrase)
+			So(r.Hash, ShouldEqual, hex.EncodeToString(hash[:]))
+			So(l.Accounts[bob.Address()].Balance, ShouldEqual, 1050000000)
+			So(l.Accounts[alice.Address()].Balance, ShouldEqual, 1000000000-50000000-100)
+		})
+
+		Convey("checks the transaction before its operations", func() {
+			cases := []struct {
+				env  xdr.TransactionEnvelope
+				code xdr.TransactionResultCode
+			}{
+				{envelope(alice, 101, 100), xdr.TransactionResultCodeTxMissingOperation},
+				{envelope(alice, 101, 100, pay(nil, bob, native, 1), pay(nil, bob, native, 1)), xdr.TransactionResultCodeTxInsufficientFee},
+				{envelope(alice, 102, 100, pay(nil, bob, native, 1)), xdr.TransactionResultCodeTxBadSeq},
+				{envelope(carol, 101, 100, pay(nil, bob, native, 1)), xdr.TransactionResultCodeTxNoAccount},
+			}
+
+			for _, c := range cases {
+				sign(&c.env, alice)
+				r, err := Simulate(newLedger(), passphrase, c.env)
+				So(err, ShouldBeNil)
+				So(r.Code, ShouldEqual, c.code)
+				So(r.Fee, ShouldEqual, 0)
+				So(r.Operations, ShouldBeEmpty)
+			}
+
+			env := envelope(alice, 101, 100, pay(nil, bob, native, 1))
+			env.Tx.TimeBounds = &xdr.TimeBounds{MinTime: 2000}
+			sign(&env, alice)
+			r, _ := Simulate(l, passphrase, env)
+			So(r.Code, ShouldEqual, xdr.TransactionResultCodeTxTooEarly)
+
+			env.Tx.TimeBounds = &xdr.TimeBounds{MaxTime: 500}
+			r, _ = Simulate(l, passphrase, env)
+			So(r.Code, ShouldEqual, xdr.TransactionResultCodeTxTooLate)
+		})
+
+		Convey("verifies signatures", func() {
+			env := envelope(alice, 101, 100, pay(nil, bob, native, 1))
+			r, _ := Simulate(l, passphrase, env)
+			So(r.Code, ShouldEqual, xdr.TransactionResultCodeTxBadAuth)
+
+			sign(&env, bob)
+			r, _ = Simulate(l, passphrase, env)
+			So(r.Code, ShouldEqual, xdr.TransactionResultCodeTxBadAuth)
+
+			env.Signatures = nil
+			sign(&env, alice, bob)
+			r, _ = Simulate(l, passphrase, env)
+			So(r.Code, ShouldEqual, xdr.TransactionResultCodeTxBadAuthExtra)
+
+			env.Signatures = nil
+			sign(&env, alice)
+			r, _ = Simulate(l, "another network", env)
+			So(r.Code, ShouldEqual, xdr.TransactionResultCodeTxBadAuth)
+
+			Convey("of the sources of operations", func() {
+				env := envelope(alice, 101, 100, pay(&bob, alice, native, 1))
+				sign(&env, alice)
+				r, _ := Simulate(l, passphrase, env)
+				So(r.Code, ShouldEqual, xdr.TransactionResultCodeTxFailed)
+				So(r.Fee, ShouldEqual, 0)
+				So(r.Operations, ShouldResemble, []OperationResult{{xdr.OperationResultCodeOpBadAuth, true}})
+
+				env.Signatures = nil
+				sign(&env, alice, bob)
+				r, _ = Simulate(l, passphrase, env)
+				So(r.Successful(), ShouldBeTrue)
+			})
+
+			Convey("of other signers, by weight", func() {
+				a := l.Accounts[alice.Address()]
+				a.Thresholds = xdr.Thresholds{1, 0, 2, 0}
+				a.Signers[carol.Address()] = 1
+
+				env := envelope(alice, 101, 100, pay(nil, bob, native, 1))
+				sign(&env, alice)
+				r, _ := Simulate(newLedger(), passphrase, env)
+				So(r.Successful(), ShouldBeTrue)
+
+				r, _ = Simulate(l, passphrase, env)
+				So(r.Operations[0].Code, ShouldEqual, xdr.OperationResultCodeOpBadAuth)
+
+				sign(&env, carol)
+				r, _ = Simulate(l, passphrase, env)
+				So(r.Successful(), ShouldBeTrue)
+			})
+		})
+
+		Convey("applies operations in turn", func() {
+			var credit xdr.Int64 = 20000000
+			env := envelope(alice, 101, 300,
+				operation(nil, xdr.OperationTypeCreateAccount, xdr.CreateAccountOp{
+					Destination:     carol.aid(),
+					StartingBalance: credit,
+				}),
+				pay(nil, carol, native, 10000000),
+				pay(nil, carol, usd, 10),
+			)
+			sign(&env, alice)
+
+			r, err := Simulate(l, passphrase, env)
+			So(err, ShouldBeNil)
+			So(r.Code, ShouldEqual, xdr.TransactionResultCodeTxFailed)
+			So(r.Fee, ShouldEqual, 300)
+			So(r.Operations, ShouldResemble, []OperationResult{
+				{xdr.CreateAccountResultCodeCreateAccountSuccess, true},
+				{xdr.PaymentResultCodePaymentSuccess, true},
+				{xdr.PaymentResultCodePaymentNoTrust, true},
+			})
+			So(l.Accounts[carol.Address()].Balance, ShouldEqual, 30000000)
+			So(l.Accounts[carol.Address()].Sequence, ShouldEqual, 11<<32)
+		})
+
+		Convey("predicts the failures of payments", func() {
+			cases := []struct {
+				op   xdr.Operation
+				code xdr.PaymentResultCode
+			}{
+				{pay(nil, carol, native, 1), xdr.PaymentResultCodePaymentNoDestination},
+				{pay(nil, bob, native, 1000000000), xdr.PaymentResultCodePaymentUnderfunded},
+				{pay(nil, bob, usd, 1), xdr.PaymentResultCodePaymentNoTrust},
+				{pay(nil, issuer, usd, 1), xdr.PaymentResultCodePaymentSrcNoTrust},
+				{pay(nil, bob, carol.credit("EUR"), 1), xdr.PaymentResultCodePaymentNoIssuer},
+			}
+
+			for _, c := range cases {
+				env := envelope(alice, 101, 100, c.op)
+				sign(&env, alice)
+				r, err := Simulate(newLedger(), passphrase, env)
+				So(err, ShouldBeNil)
+				So(r.Code, ShouldEqual, xdr.TransactionResultCodeTxFailed)
+				So(r.Operations[0].Code, ShouldEqual, c.code)
+			}
+		})
+
+		Convey("pays credit along trustlines", func() {
+			line := Asset{Type: xdr.AssetTypeAssetTypeCreditAlphanum4, Code: "USD", Issuer: issuer.Address()}
+
+			env := envelope(alice, 101, 200,
+				operation(nil, xdr.OperationTypeChangeTrust, xdr.ChangeTrustOp{Line: usd, Limit: 100}),
+				pay(&issuer, alice, usd, 60),
+			)
+			sign(&env, alice, issuer)
+
+			r, err := Simulate(l, passphrase, env)
+			So(err, ShouldBeNil)
+			So(r.Successful(), ShouldBeTrue)
+			So(l.Accounts[alice.Address()].SubEntries, ShouldEqual, 1)
+			So(l.Accounts[alice.Address()].Trustlines[line], ShouldResemble, &Trustline{Balance: 60, Limit: 100, Authorized: true})
+
+			env = envelope(alice, 102, 100, pay(&issuer, alice, usd, 50))
+			sign(&env, alice, issuer)
+			r, _ = Simulate(l, passphrase, env)
+			So(r.Operations[0].Code, ShouldEqual, xdr.PaymentResultCodePaymentLineFull)
+
+			env = envelope(alice, 103, 100, operation(nil, xdr.OperationTypeChangeTrust, xdr.ChangeTrustOp{Line: usd, Limit: 50}))
+			sign(&env, alice)
+			r, _ = Simulate(l, passphrase, env)
+			So(r.Operations[0].Code, ShouldEqual, xdr.ChangeTrustResultCodeChangeTrustInvalidLimit)
+		})
+
+		Convey("assumes operations it does not simulate succeed", func() {
+			env := envelope(alice, 101, 100, operation(nil, xdr.OperationTypeInflation, nil))
+			sign(&env, alice)
+
+			r, err := Simulate(l, passphrase, env)
+			So(err, ShouldBeNil)
+			So(r.Successful(), ShouldBeTrue)
+			So(r.Operations, ShouldResemble, []OperationResult{{xdr.InflationResultCodeInflationSuccess, false}})
+		})
+	})
+
+	Convey("simulate.Addresses", t, func() {
+		env := envelope(alice, 1, 100, pay(&bob, carol, usd, 1), pay(nil, bob, native, 1))
+
+		addresses, err := Addresses(env)
+		So(err, ShouldBeNil)
+		So(addresses, ShouldResemble, []string{alice.Address(), bob.Address(), carol.Address(), issuer.Address()})
+	})
+}
@@ -0,0 +1,246 @@
+package simulate
+
+import (
+	"github.com/stellar/go-stellar-base/xdr"
+)
+
+// threshold returns the threshold of its source account op must be authorized
+// at.
+func threshold(op xdr.Operation) xdr.ThresholdIndexes {
+	switch op.Body.Type {
+	case xdr.OperationTypeAllowTrust, xdr.OperationTypeInflation:
+		return xdr.ThresholdIndexesThresholdLow
+	case xdr.OperationTypeAccountMerge:
+		return xdr.ThresholdIndexesThresholdHigh
+	case xdr.OperationTypeSetOptions:
+		so := op.Body.MustSetOptionsOp()
+		if so.MasterWeight != nil || so.LowThreshold != nil || so.MedThreshold != nil ||
+			so.HighThreshold != nil || so.Signer != nil {
+			return xdr.ThresholdIndexesThresholdHigh
+		}
+	}
+
+	return xdr.ThresholdIndexesThresholdMed
+}
+
+// checkOperation makes the checks stellar-core makes of op before the
+// transaction carrying it is applied: that its source account exists and has
+// signed it, and that it is well formed.
+func (s *simulation) checkOperation(source string, op xdr.Operation) (OperationResult, error) {
+	account, ok := s.ledger.Accounts[source]
+	if !ok {
+		return OperationResult{xdr.OperationResultCodeOpNoAccount, true}, nil
+	}
+
+	if !s.signatures.check(account, threshold(op)) {
+		return OperationResult{xdr.OperationResultCodeOpBadAuth, true}, nil
+	}
+
+	switch op.Body.Type {
+	case xdr.OperationTypeCreateAccount:
+		if op.Body.MustCreateAccountOp().StartingBalance <= 0 {
+			return OperationResult{xdr.CreateAccountResultCodeCreateAccountMalformed, true}, nil
+		}
+	case xdr.OperationTypePayment:
+		if op.Body.MustPaymentOp().Amount <= 0 {
+			return OperationResult{xdr.PaymentResultCodePaymentMalformed, true}, nil
+		}
+	case xdr.OperationTypeChangeTrust:
+		if op.Body.MustChangeTrustOp().Limit < 0 {
+			return OperationResult{xdr.ChangeTrustResultCodeChangeTrustMalformed, true}, nil
+		}
+	}
+
+	// the outcome of applying op is yet to be simulated
+	return OperationResult{success(op).Code, false}, nil
+}
+
+// apply simulates applying op, whose source account exists, to the ledger.
+func (s *simulation) apply(source string, op xdr.Operation) (OperationResult, error) {
+	account := s.ledger.Accounts[source]
+
+	switch op.Body.Type {
+	case xdr.OperationTypeCreateAccount:
+		return s.createAccount(account, op.Body.MustCreateAccountOp())
+	case xdr.OperationTypePayment:
+		return s.payment(account, op.Body.MustPaymentOp())
+	case xdr.OperationTypeChangeTrust:
+		return s.changeTrust(account, op.Body.MustChangeTrustOp())
+	default:
+		return success(op), nil
+	}
+}
+
+func (s *simulation) createAccount(source *Account, op xdr.CreateAccountOp) (OperationResult, error) {
+	dest, err := address(op.Destination)
+	if err != nil {
+		return OperationResult{}, err
+	}
+
+	if _, ok := s.ledger.Accounts[dest]; ok {
+		return OperationResult{xdr.CreateAccountResultCodeCreateAccountAlreadyExist, true}, nil
+	}
+
+	account := &Account{
+		Address:    dest,
+		Balance:    int64(op.StartingBalance),
+		Sequence:   int64(s.ledger.Sequence+1) << 32,
+		Thresholds: xdr.Thresholds{1, 0, 0, 0},
+		Signers:    map[string]int32{},
+		Trustlines: map[Asset]*Trustline{},
+	}
+
+	if account.Balance < s.ledger.MinimumBalance(account) {
+		return OperationResult{xdr.CreateAccountResultCodeCreateAccountLowReserve, true}, nil
+	}
+
+	if source.Balance-account.Balance < s.ledger.MinimumBalance(source) {
+		return OperationResult{xdr.CreateAccountResultCodeCreateAccountUnderfunded, true}, nil
+	}
+
+	source.Balance -= account.Balance
+	s.ledger.Accounts[dest] = account
+	return OperationResult{xdr.CreateAccountResultCodeCreateAccountSuccess, true}, nil
+}
+
+func (s *simulation) payment(source *Account, op xdr.PaymentOp) (OperationResult, error) {
+	to, err := address(op.Destination)
+	if err != nil {
+		return OperationResult{}, err
+	}
+
+	dest, ok := s.ledger.Accounts[to]
+	if !ok {
+		return OperationResult{xdr.PaymentResultCodePaymentNoDestination, true}, nil
+	}
+
+	amount := int64(op.Amount)
+
+	if op.Asset.Type == xdr.AssetTypeAssetTypeNative {
+		if source.Balance-amount < s.ledger.MinimumBalance(source) {
+			return OperationResult{xdr.PaymentResultCodePaymentUnderfunded, true}, nil
+		}
+
+		source.Balance -= amount
+		dest.Balance += amount
+		return OperationResult{xdr.PaymentResultCodePaymentSuccess, true}, nil
+	}
+
+	asset, err := assetOf(op.Asset)
+	if err != nil {
+		return OperationResult{}, err
+	}
+
+	if _, ok := s.ledger.Accounts[asset.Issuer]; !ok {
+		return OperationResult{xdr.PaymentResultCodePaymentNoIssuer, true}, nil
+	}
+
+	// issuers neither need trustlines for the assets they issue, nor are
+	// limited in how much of them they can send or receive.
+	var destLine, sourceLine *Trustline
+
+	if dest.Address != asset.Issuer {
+		destLine = dest.Trustlines[asset]
+		switch {
+		case destLine == nil:
+			return OperationResult{xdr.PaymentResultCodePaymentNoTrust, true}, nil
+		case !destLine.Authorized:
+			return OperationResult{xdr.PaymentResultCodePaymentNotAuthorized, true}, nil
+		case destLine.Limit-destLine.Balance < amount:
+			return OperationResult{xdr.PaymentResultCodePaymentLineFull, true}, nil
+		}
+	}
+
+	if source.Address != asset.Issuer {
+		sourceLine = source.Trustlines[asset]
+		switch {
+		case sourceLine == nil:
+			return OperationResult{xdr.PaymentResultCodePaymentSrcNoTrust, true}, nil
+		case !sourceLine.Authorized:
+			return OperationResult{xdr.PaymentResultCodePaymentSrcNotAuthorized, true}, nil
+		case sourceLine.Balance < amount:
+			return OperationResult{xdr.PaymentResultCodePaymentUnderfunded, true}, nil
+		}
+	}
+
+	if sourceLine != nil {
+		sourceLine.Balance -= amount
+	}
+	if destLine != nil {
+		destLine.Balance += amount
+	}
+
+	return OperationResult{xdr.PaymentResultCodePaymentSuccess, true}, nil
+}
+
+func (s *simulation) changeTrust(source *Account, op xdr.ChangeTrustOp) (OperationResult, error) {
+	asset, err := assetOf(op.Line)
+	if err != nil {
+		return OperationResult{}, err
+	}
+
+	issuer, ok := s.ledger.Accounts[asset.Issuer]
+	if !ok {
+		return OperationResult{xdr.ChangeTrustResultCodeChangeTrustNoIssuer, true}, nil
+	}
+
+	limit := int64(op.Limit)
+	line, ok := source.Trustlines[asset]
+
+	if ok {
+		if limit < line.Balance {
+			return OperationResult{xdr.ChangeTrustResultCodeChangeTrustInvalidLimit, true}, nil
+		}
+
+		if limit == 0 {
+			delete(source.Trustlines, asset)
+			source.SubEntries--
+		} else {
+			line.Limit = limit
+		}
+
+		return OperationResult{xdr.ChangeTrustResultCodeChangeTrustSuccess, true}, nil
+	}
+
+	if limit == 0 {
+		return OperationResult{xdr.ChangeTrustResultCodeChangeTrustInvalidLimit, true}, nil
+	}
+
+	source.SubEntries++
+	if source.Balance < s.ledger.MinimumBalance(source) {
+		source.SubEntries--
+		return OperationResult{xdr.ChangeTrustResultCodeChangeTrustLowReserve, true}, nil
+	}
+
+	if source.Trustlines == nil {
+		source.Trustlines = map[Asset]*Trustline{}
+	}
+	source.Trustlines[asset] = &Trustline{Limit: limit, Authorized: !issuer.AuthRequired}
+
+	return OperationResult{xdr.ChangeTrustResultCodeChangeTrustSuccess, true}, nil
+}
+
+// success returns the result of op succeeding, checked only when op is of a
+// type that is simulated.
+func success(op xdr.Operation) OperationResult {
+	switch op.Body.Type {
+	case xdr.OperationTypeCreateAccount:
+		return OperationResult{xdr.CreateAccountResultCodeCreateAccountSuccess, true}
+	case xdr.OperationTypePayment:
+		return OperationResult{xdr.PaymentResultCodePaymentSuccess, true}
+	case xdr.OperationTypeChangeTrust:
+		return OperationResult{xdr.ChangeTrustResultCodeChangeTrustSuccess, true}
+	case xdr.OperationTypePathPayment:
+		return OperationResult{xdr.PathPaymentResultCodePathPaymentSuccess, false}
+	case xdr.OperationTypeManageOffer, xdr.OperationTypeCreatePassiveOffer:
+		return OperationResult{xdr.ManageOfferResultCodeManageOfferSuccess, false}
+	case xdr.OperationTypeSetOptions:
+		return OperationResult{xdr.SetOptionsResultCodeSetOptionsSuccess, false}
+	case xdr.OperationTypeAllowTrust:
+		return OperationResult{xdr.AllowTrustResultCodeAllowTrustSuccess, false}
+	case xdr.OperationTypeAccountMerge:
+		return OperationResult{xdr.AccountMergeResultCodeAccountMergeSuccess, false}
+	default:
+		return OperationResult{xdr.InflationResultCodeInflationSuccess, false}
+	}
+}
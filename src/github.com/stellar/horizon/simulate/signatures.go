@@ -0,0 +1,97 @@
+package simulate
+
+import (
+	"github.com/agl/ed25519"
+	"github.com/stellar/go-stellar-base/xdr"
+	"github.com/stellar/horizon/strkeys"
+)
+
+// signatures are the signatures of a transaction, along with which of them
+// have been used to authorize it or its operations.
+type signatures struct {
+	hash [32]byte
+	all  []xdr.DecoratedSignature
+	used []bool
+}
+
+func newSignatures(hash [32]byte, all []xdr.DecoratedSignature) *signatures {
+	return &signatures{
+		hash: hash,
+		all:  all,
+		used: make([]bool, len(all)),
+	}
+}
+
+// check returns true when the signatures carry the weight account requires at
+// threshold, marking those counted as used.  As stellar-core does, signatures
+// are counted in order, each against the first of the account's keys it is
+// valid for, until the weight required is reached; each key counts once.
+func (s *signatures) check(account *Account, threshold xdr.ThresholdIndexes) bool {
+	type key struct {
+		address string
+		weight  int32
+	}
+
+	var keys []key
+	if w := int32(account.Thresholds[xdr.ThresholdIndexesThresholdMasterWeight]); w > 0 {
+		keys = append(keys, key{account.Address, w})
+	}
+	for _, a := range signerAddresses(account) {
+		keys = append(keys, key{a, account.Signers[a]})
+	}
+
+	needed := int32(account.Thresholds[threshold])
+	total := int32(0)
+
+	for i, sig := range s.all {
+		for j, k := range keys {
+			if !s.verify(k.address, sig) {
+				continue
+			}
+
+			s.used[i] = true
+			total += k.weight
+			if total >= needed {
+				return true
+			}
+
+			keys = append(keys[:j], keys[j+1:]...)
+			break
+		}
+	}
+
+	return false
+}
+
+// allUsed returns true once every signature has been used.
+func (s *signatures) allUsed() bool {
+	for _, used := range s.used {
+		if !used {
+			return false
+		}
+	}
+
+	return true
+}
+
+// verify returns true when sig is a valid signature of the hash by the key of
+// address.
+func (s *signatures) verify(address string, sig xdr.DecoratedSignature) bool {
+	raw, err := strkeys.Decode(strkeys.AccountID, address)
+	if err != nil || len(raw) != ed25519.PublicKeySize || len(sig.Signature) != ed25519.SignatureSize {
+		return false
+	}
+
+	var hint xdr.SignatureHint
+	copy(hint[:], raw[len(raw)-len(hint):])
+	if hint != sig.Hint {
+		return false
+	}
+
+	var pub [ed25519.PublicKeySize]byte
+	var signature [ed25519.SignatureSize]byte
+	copy(pub[:], raw)
+	copy(signature[:], sig.Signature)
+
+	return ed25519.Verify(&pub, s.hash[:], &signature)
+}
@@ -0,0 +1,126 @@
+package horizon
+
+import (
+	"github.com/stellar/go-stellar-base/xdr"
+	"github.com/stellar/horizon/db"
+	"github.com/stellar/horizon/simulate"
+	"github.com/stellar/horizon/txsub"
+	"golang.org/x/net/context"
+)
+
+// Simulate predicts the outcome of submitting env, a base64 encoded
+// transaction envelope, by simulating it against the latest ledger stellar-core
+// has closed, whose sequence is returned along with the result.  An envelope
+// that cannot be decoded returns a *txsub.MalformedTransactionError.
+func (a *App) Simulate(ctx context.Context, env string) (simulate.Result, int32, error) {
+	var envelope xdr.TransactionEnvelope
+	err := xdr.SafeUnmarshalBase64(env, &envelope)
+	if err != nil {
+		return simulate.Result{}, 0, &txsub.MalformedTransactionError{EnvelopeXDR: env}
+	}
+
+	addresses, err := simulate.Addresses(envelope)
+	if err != nil {
+		return simulate.Result{}, 0, err
+	}
+
+	ledger, err := a.simulationLedger(ctx, addresses)
+	if err != nil {
+		return simulate.Result{}, 0, err
+	}
+
+	result, err := simulate.Simulate(ledger, a.networkPassphrase, envelope)
+	return result, ledger.Sequence, err
+}
+
+// simulationLedger loads from stellar-core the snapshot of its latest ledger
+// and of the accounts of addresses against which a transaction is simulated.
+func (a *App) simulationLedger(ctx context.Context, addresses []string) (*simulate.Ledger, error) {
+	ls, err := a.LedgerState(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var headers []db.CoreLedgerHeaderRecord
+	err = db.Select(ctx, db.CoreLedgerHeadersBySequenceQuery{
+		SqlQuery:  a.CoreQuery(),
+		Sequences: []int32{ls.StellarCoreSequence},
+	}, &headers)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(headers) == 0 {
+		return nil, db.ErrNoResults
+	}
+
+	header, err := headers[0].Header()
+	if err != nil {
+		return nil, err
+	}
+
+	var records []db.AccountRecord
+	err = db.Select(ctx, db.CoreAccountsByAddressQuery{
+		SqlQuery:  a.CoreQuery(),
+		Addresses: addresses,
+	}, &records)
+	if err != nil {
+		return nil, err
+	}
+
+	ledger := &simulate.Ledger{
+		Sequence:    headers[0].Sequence,
+		CloseTime:   uint64(header.ScpValue.CloseTime),
+		BaseFee:     int32(header.BaseFee),
+		BaseReserve: int32(header.BaseReserve),
+		Accounts:    map[string]*simulate.Account{},
+	}
+
+	for _, r := range records {
+		account, err := simulationAccount(r)
+		if err != nil {
+			return nil, err
+		}
+		ledger.Accounts[account.Address] = account
+	}
+
+	return ledger, nil
+}
+
+func simulationAccount(r db.AccountRecord) (*simulate.Account, error) {
+	thresholds, err := r.DecodeThresholds()
+	if err != nil {
+		return nil, err
+	}
+
+	account := &simulate.Account{
+		Address:      r.Accountid,
+		Balance:      r.Balance,
+		Sequence:     r.Seqnum,
+		SubEntries:   r.Numsubentries,
+		Thresholds:   thresholds,
+		AuthRequired: r.IsAuthRequired(),
+		Signers:      map[string]int32{},
+		Trustlines:   map[simulate.Asset]*simulate.Trustline{},
+	}
+
+	for _, s := range r.Signers {
+		account.Signers[s.Publickey] = s.Weight
+	}
+
+	for _, tl := range r.Trustlines {
+		asset := simulate.Asset{
+			Type:   xdr.AssetType(tl.Assettype),
+			Code:   tl.Assetcode,
+			Issuer: tl.Issuer,
+		}
+
+		account.Trustlines[asset] = &simulate.Trustline{
+			Balance:    tl.Balance,
+			Limit:      tl.Tlimit,
+			Authorized: xdr.TrustLineFlags(tl.Flags)&xdr.TrustLineFlagsAuthorizedFlag != 0,
+		}
+	}
+
+	return account, nil
+}
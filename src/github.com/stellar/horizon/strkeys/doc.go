@@ -0,0 +1,8 @@
+// Package strkeys encodes, decodes and validates the strkeys of stellar: the
+// base32, checksummed strings that stand for account ids (G...), secret seeds
+// (S...) and the other kinds of keys stellar may come to use.
+//
+// Horizon only ever takes account ids from its clients.  A seed given where an
+// account id is expected is refused with ErrSeed, apart from the other
+// malformed strings, so that the client may be warned its secret is exposed.
+package strkeys
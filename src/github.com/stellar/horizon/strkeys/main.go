@@ -0,0 +1,100 @@
+package strkeys
+
+import (
+	stderr "errors"
+
+	"github.com/stellar/go-stellar-base/strkey"
+	"github.com/stellar/go-stellar-base/xdr"
+)
+
+// ErrInvalid is returned when decoding a string that is not a strkey of the
+// expected kind, or whose checksum does not verify.
+// NOTE: this is not a go-errors based error, as stack traces are unnecessary
+var ErrInvalid = stderr.New("invalid strkey")
+
+// ErrSeed is returned when validating a secret seed given in place of an
+// account id.
+// NOTE: this is not a go-errors based error, as stack traces are unnecessary
+var ErrSeed = stderr.New("strkey is a secret seed, not an account id")
+
+// Kind is the kind of key a strkey stands for, given by its version byte
+type Kind strkey.VersionByte
+
+const (
+	// AccountID is the kind of the strkeys of accounts, which begin with G
+	AccountID = Kind(strkey.VersionByteAccountID)
+
+	// Seed is the kind of the strkeys of secret seeds, which begin with S
+	Seed = Kind(strkey.VersionByteSeed)
+)
+
+// kinds are the kinds of strkeys KindOf recognizes
+var kinds = []Kind{AccountID, Seed}
+
+// String returns the name of k
+func (k Kind) String() string {
+	switch k {
+	case AccountID:
+		return "account id"
+	case Seed:
+		return "seed"
+	default:
+		return "unknown"
+	}
+}
+
+// Encode encodes raw as a strkey of kind k.
+func Encode(k Kind, raw []byte) (string, error) {
+	result, err := strkey.Encode(strkey.VersionByte(k), raw)
+	if err != nil {
+		return "", ErrInvalid
+	}
+
+	return result, nil
+}
+
+// Decode decodes s, a strkey of kind k, into its raw key.  Returns ErrInvalid
+// if s is of another kind or its checksum does not verify.
+func Decode(k Kind, s string) ([]byte, error) {
+	raw, err := strkey.Decode(strkey.VersionByte(k), s)
+	if err != nil {
+		return nil, ErrInvalid
+	}
+
+	return raw, nil
+}
+
+// KindOf returns the kind of the strkey s, and false if s is not a valid
+// strkey of any kind.
+func KindOf(s string) (Kind, bool) {
+	for _, k := range kinds {
+		if _, err := Decode(k, s); err == nil {
+			return k, true
+		}
+	}
+
+	return 0, false
+}
+
+// ValidateAccountID returns nil if s is an account id, ErrSeed if it is a
+// secret seed, and ErrInvalid otherwise.
+func ValidateAccountID(s string) error {
+	switch k, ok := KindOf(s); {
+	case ok && k == AccountID:
+		return nil
+	case ok && k == Seed:
+		return ErrSeed
+	default:
+		return ErrInvalid
+	}
+}
+
+// Address returns the account id of aid.
+func Address(aid xdr.AccountId) (string, error) {
+	key, ok := aid.GetEd25519()
+	if !ok {
+		return "", ErrInvalid
+	}
+
+	return Encode(AccountID, key[:])
+}
@@ -0,0 +1,65 @@
+package strkeys
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/go-stellar-base/xdr"
+)
+
+func TestStrkeys(t *testing.T) {
+	Convey("strkeys", t, func() {
+		address := "GC23QF2HUE52AMXUFUH3AYJAXXGXXV2VHXYYR6EYXETPKDXZSAW67XO4"
+		raw := bytes.Repeat([]byte{7}, 32)
+		seed, err := Encode(Seed, raw)
+		So(err, ShouldBeNil)
+
+		Convey("Decode decodes strkeys of the expected kind", func() {
+			decoded, err := Decode(Seed, seed)
+			So(err, ShouldBeNil)
+			So(decoded, ShouldResemble, raw)
+
+			_, err = Decode(AccountID, seed)
+			So(err, ShouldEqual, ErrInvalid)
+
+			_, err = Decode(AccountID, address[:55]+"A")
+			So(err, ShouldEqual, ErrInvalid)
+		})
+
+		Convey("KindOf identifies strkeys", func() {
+			k, ok := KindOf(address)
+			So(ok, ShouldBeTrue)
+			So(k, ShouldEqual, AccountID)
+
+			k, ok = KindOf(seed)
+			So(ok, ShouldBeTrue)
+			So(k, ShouldEqual, Seed)
+
+			_, ok = KindOf("not_a_strkey")
+			So(ok, ShouldBeFalse)
+		})
+
+		Convey("ValidateAccountID refuses seeds apart from malformed strings", func() {
+			So(ValidateAccountID(address), ShouldBeNil)
+			So(ValidateAccountID(seed), ShouldEqual, ErrSeed)
+			So(ValidateAccountID(""), ShouldEqual, ErrInvalid)
+			So(ValidateAccountID(address[:55]+"A"), ShouldEqual, ErrInvalid)
+		})
+
+		Convey("Address encodes account ids", func() {
+			var key xdr.Uint256
+			copy(key[:], raw)
+			aid, err := xdr.NewAccountId(xdr.CryptoKeyTypeKeyTypeEd25519, key)
+			So(err, ShouldBeNil)
+
+			encoded, err := Address(aid)
+			So(err, ShouldBeNil)
+			So(ValidateAccountID(encoded), ShouldBeNil)
+
+			decoded, err := Decode(AccountID, encoded)
+			So(err, ShouldBeNil)
+			So(decoded, ShouldResemble, raw)
+		})
+	})
+}
@@ -4,10 +4,13 @@ import (
 	"github.com/zenazn/goji/web"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 )
 
 type RequestHelper interface {
 	Get(string, func(*http.Request)) *httptest.ResponseRecorder
+	Post(string, url.Values, func(*http.Request)) *httptest.ResponseRecorder
 }
 
 type requestHelper struct {
@@ -44,6 +47,26 @@ func (r *requestHelper) Get(
 ) *httptest.ResponseRecorder {
 
 	req, _ := http.NewRequest("GET", path, nil)
+	return r.serve(req, requestModFn)
+}
+
+// Post makes a POST request of the url encoded form to path
+func (r *requestHelper) Post(
+	path string,
+	form url.Values,
+	requestModFn func(*http.Request),
+) *httptest.ResponseRecorder {
+
+	req, _ := http.NewRequest("POST", path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return r.serve(req, requestModFn)
+}
+
+func (r *requestHelper) serve(
+	req *http.Request,
+	requestModFn func(*http.Request),
+) *httptest.ResponseRecorder {
+
 	req.RemoteAddr = "127.0.0.1"
 	requestModFn(req)
 
@@ -14,6 +14,7 @@ import (
 
 	"github.com/Sirupsen/logrus"
 	"github.com/jmoiron/sqlx"
+	"github.com/stellar/horizon/db/schema"
 	glog "github.com/stellar/horizon/log"
 	"golang.org/x/net/context"
 )
@@ -63,7 +64,9 @@ func OpenDatabase(dsn string) *sqlx.DB {
 
 // LoadScenario populates the test databases with pre-created scenarios.  Each
 // scenario is in the scenarios subfolder of this package and are a pair of
-// sql files, one per database.
+// sql files, one per database.  The history database is then migrated, from
+// scratch, to the latest version of the schema of horizon, as horizon serves
+// from no other.
 func LoadScenario(scenarioName string) {
 	scenarioBasePath := "scenarios/" + scenarioName
 	horizonPath := scenarioBasePath + "-horizon.sql"
@@ -71,6 +74,22 @@ func LoadScenario(scenarioName string) {
 
 	loadSqlFile(DatabaseUrl(), horizonPath)
 	loadSqlFile(StellarCoreDatabaseUrl(), stellarCorePath)
+	migrateSchema(DatabaseUrl())
+}
+
+// migrateSchema reverts every migration applied to the database at url, so
+// that the tables of the schema hold nothing of earlier scenarios, and then
+// applies them all anew.
+func migrateSchema(url string) {
+	db := OpenDatabase(url)
+	defer db.Close()
+
+	for _, dir := range []schema.Direction{schema.Down, schema.Up} {
+		_, err := schema.Migrate(db, dir, 0)
+		if err != nil {
+			log.Panic(err)
+		}
+	}
 }
 
 func loadSqlFile(url string, path string) {
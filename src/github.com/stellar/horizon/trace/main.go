@@ -0,0 +1,219 @@
+// Package trace records spans of the work horizon does, such as the requests
+// it serves, the queries it makes of its databases, its calls to stellar-core
+// and the steps of ingestion, for operators to see where slow requests spend
+// their time.  Spans follow the model of OpenTelemetry: they are exported over
+// OTLP to a collector, or to Jaeger, which accepts OTLP as well, and their
+// context is propagated between services in the W3C traceparent header.
+//
+// Spans are only recorded within a context bound to a Tracer with Context.
+// Elsewhere, Start returns a nil *Span, whose methods do nothing, so that
+// callers need not check whether tracing is enabled.
+package trace
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// Kind is the kind of a span, as in OpenTelemetry
+type Kind int
+
+// The kinds of spans
+const (
+	KindInternal Kind = 1
+	KindServer   Kind = 2
+	KindClient   Kind = 3
+)
+
+// TraceID identifies a trace: the spans of a request, across services
+type TraceID [16]byte
+
+// String returns the hex encoding of id
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// SpanID identifies a span within its trace
+type SpanID [8]byte
+
+// String returns the hex encoding of id
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// Span is a timed unit of work, a part of the trace it belongs to, the child
+// of the span it was started within.
+type Span struct {
+	TraceID  TraceID
+	SpanID   SpanID
+	ParentID SpanID
+	Kind     Kind
+	Start    time.Time
+	End      time.Time
+
+	tracer *Tracer
+
+	lock       sync.Mutex
+	name       string
+	attributes map[string]interface{}
+	err        string
+}
+
+// Name returns the name of s
+func (s *Span) Name() string {
+	if s == nil {
+		return ""
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.name
+}
+
+// SetName renames s, as when what it did is only known once done
+func (s *Span) SetName(name string) {
+	if s == nil {
+		return
+	}
+
+	s.lock.Lock()
+	s.name = name
+	s.lock.Unlock()
+}
+
+// SetKind sets the kind of s, which is KindInternal unless set otherwise
+func (s *Span) SetKind(kind Kind) {
+	if s == nil {
+		return
+	}
+
+	s.Kind = kind
+}
+
+// SetAttribute records the attribute key of s.  Values are strings, bools,
+// integers or floats; others are recorded as strings.
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+
+	s.lock.Lock()
+	if s.attributes == nil {
+		s.attributes = map[string]interface{}{}
+	}
+	s.attributes[key] = value
+	s.lock.Unlock()
+}
+
+// Attributes returns a copy of the attributes of s
+func (s *Span) Attributes() map[string]interface{} {
+	if s == nil {
+		return nil
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	result := make(map[string]interface{}, len(s.attributes))
+	for k, v := range s.attributes {
+		result[k] = v
+	}
+	return result
+}
+
+// Err returns the error s finished with, empty when it succeeded
+func (s *Span) Err() string {
+	if s == nil {
+		return ""
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.err
+}
+
+// Finish ends s, the work it timed having failed with err when not nil, and
+// queues it to be exported.  Spans should be finished once.
+func (s *Span) Finish(err error) {
+	if s == nil {
+		return
+	}
+
+	s.lock.Lock()
+	s.End = time.Now()
+	if err != nil {
+		s.err = err.Error()
+	}
+	s.lock.Unlock()
+
+	s.tracer.record(s)
+}
+
+var tracerKey = 0
+var spanKey = 0
+var remoteKey = 0
+
+// Context returns a context derived from parent, within which spans are
+// recorded by t.
+func Context(parent context.Context, t *Tracer) context.Context {
+	return context.WithValue(parent, &tracerKey, t)
+}
+
+// FromContext returns the span ctx is within, nil when there is none
+func FromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(&spanKey).(*Span)
+	return span
+}
+
+// Start starts a span, named name, within ctx: a child of the span ctx is
+// within, or of the remote parent extracted into ctx, or the root of a new
+// trace, sampled at the sample rate of the tracer.  It returns the span,
+// along with a context within it.  The span is nil when ctx is bound to no
+// tracer, or when its trace is not sampled.
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+	t, _ := ctx.Value(&tracerKey).(*Tracer)
+	if t == nil {
+		return ctx, nil
+	}
+
+	span := &Span{
+		Kind:   KindInternal,
+		Start:  time.Now(),
+		tracer: t,
+		name:   name,
+	}
+
+	if parent := FromContext(ctx); parent != nil {
+		span.TraceID = parent.TraceID
+		span.ParentID = parent.SpanID
+	} else if remote, ok := ctx.Value(&remoteKey).(remoteParent); ok {
+		if !remote.sampled {
+			return ctx, nil
+		}
+		span.TraceID = remote.traceID
+		span.ParentID = remote.spanID
+	} else {
+		if !t.sample() {
+			return ctx, nil
+		}
+		rand.Read(span.TraceID[:])
+	}
+
+	rand.Read(span.SpanID[:])
+	return context.WithValue(ctx, &spanKey, span), span
+}
+
+// StartChild starts a span as Start does, but only as the child of the span
+// ctx is within, for work too small to trace on its own, such as the queries
+// polled in the background.  It returns a nil span when ctx is within none.
+func StartChild(ctx context.Context, name string) (context.Context, *Span) {
+	if FromContext(ctx) == nil {
+		return ctx, nil
+	}
+
+	return Start(ctx, name)
+}
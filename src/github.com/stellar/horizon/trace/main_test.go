@@ -0,0 +1,193 @@
+package trace
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"golang.org/x/net/context"
+)
+
+type memoryExporter struct {
+	lock  sync.Mutex
+	spans []*Span
+}
+
+func (e *memoryExporter) Export(spans []*Span) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+func TestTrace(t *testing.T) {
+	Convey("trace.Start", t, func() {
+		exporter := &memoryExporter{}
+		tracer := NewTracer(exporter, 1)
+		ctx := Context(context.Background(), tracer)
+
+		Convey("records nothing outside a traced context", func() {
+			ctx, span := Start(context.Background(), "untraced")
+			So(span, ShouldBeNil)
+			So(FromContext(ctx), ShouldBeNil)
+
+			// the methods of a nil span do nothing
+			span.SetAttribute("key", "value")
+			span.SetName("renamed")
+			span.Finish(errors.New("broken"))
+			So(span.Name(), ShouldEqual, "")
+		})
+
+		Convey("starts spans within their parents", func() {
+			ctx, parent := Start(ctx, "parent")
+			_, child := Start(ctx, "child")
+
+			So(parent, ShouldNotBeNil)
+			So(FromContext(ctx), ShouldEqual, parent)
+			So(child.TraceID, ShouldEqual, parent.TraceID)
+			So(child.ParentID, ShouldEqual, parent.SpanID)
+			So(child.SpanID, ShouldNotEqual, parent.SpanID)
+			So(parent.ParentID, ShouldEqual, SpanID{})
+
+			child.SetAttribute("db.statement", "SELECT 1")
+			child.Finish(errors.New("broken"))
+			parent.Finish(nil)
+			tracer.Close()
+
+			So(len(exporter.spans), ShouldEqual, 2)
+			So(exporter.spans[0].Name(), ShouldEqual, "child")
+			So(exporter.spans[0].Attributes()["db.statement"], ShouldEqual, "SELECT 1")
+			So(exporter.spans[0].Err(), ShouldEqual, "broken")
+			So(exporter.spans[1].Err(), ShouldEqual, "")
+		})
+
+		Convey("starts children only within a span", func() {
+			_, span := StartChild(ctx, "db.query")
+			So(span, ShouldBeNil)
+
+			ctx, parent := Start(ctx, "parent")
+			_, child := StartChild(ctx, "db.query")
+			So(child, ShouldNotBeNil)
+			So(child.ParentID, ShouldEqual, parent.SpanID)
+		})
+
+		Convey("samples new traces at the sample rate", func() {
+			tracer.SampleRate = 0
+			_, span := Start(ctx, "unsampled")
+			So(span, ShouldBeNil)
+		})
+
+		Convey("drops spans finished after the tracer is closed", func() {
+			_, span := Start(ctx, "late")
+			tracer.Close()
+			span.Finish(nil)
+
+			So(exporter.spans, ShouldBeEmpty)
+			So(tracer.Dropped(), ShouldEqual, 1)
+		})
+	})
+
+	Convey("trace.Extract and trace.Inject", t, func() {
+		tracer := NewTracer(&memoryExporter{}, 0)
+		defer tracer.Close()
+		ctx := Context(context.Background(), tracer)
+
+		r, _ := http.NewRequest("GET", "/ledgers", nil)
+
+		Convey("continues sampled remote traces", func() {
+			r.Header.Set(Header, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+			_, span := Start(Extract(ctx, r), "request")
+
+			So(span, ShouldNotBeNil)
+			So(span.TraceID.String(), ShouldEqual, "4bf92f3577b34da6a3ce929d0e0e4736")
+			So(span.ParentID.String(), ShouldEqual, "00f067aa0ba902b7")
+
+			req, _ := http.NewRequest("POST", "http://localhost:11626/tx", nil)
+			Inject(context.WithValue(ctx, &spanKey, span), req)
+			So(req.Header.Get(Header), ShouldEqual, "00-4bf92f3577b34da6a3ce929d0e0e4736-"+span.SpanID.String()+"-01")
+		})
+
+		Convey("does not record unsampled remote traces", func() {
+			r.Header.Set(Header, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+			_, span := Start(Extract(ctx, r), "request")
+			So(span, ShouldBeNil)
+		})
+
+		Convey("ignores invalid traceparents", func() {
+			headers := []string{
+				"",
+				"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+				"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+				"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+				"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902-01",
+				"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-1",
+			}
+
+			for _, header := range headers {
+				r.Header.Set(Header, header)
+				So(Extract(ctx, r), ShouldEqual, ctx)
+			}
+		})
+
+		Convey("does not inject outside a span", func() {
+			req, _ := http.NewRequest("POST", "http://localhost:11626/tx", nil)
+			Inject(ctx, req)
+			So(req.Header.Get(Header), ShouldEqual, "")
+		})
+	})
+
+	Convey("OTLPExporter", t, func() {
+		var body map[string]interface{}
+		status := http.StatusOK
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			raw, _ := ioutil.ReadAll(r.Body)
+			json.Unmarshal(raw, &body)
+			w.WriteHeader(status)
+		}))
+		defer server.Close()
+
+		exporter := &OTLPExporter{URL: server.URL, ServiceName: "horizon-test"}
+		tracer := NewTracer(&memoryExporter{}, 1)
+		defer tracer.Close()
+
+		_, span := Start(Context(context.Background(), tracer), "GET /ledgers")
+		span.Kind = KindServer
+		span.SetAttribute("http.status_code", 500)
+		span.Finish(errors.New("broken"))
+
+		Convey("posts spans as OTLP json", func() {
+			So(exporter.Export([]*Span{span}), ShouldBeNil)
+
+			resource := body["resourceSpans"].([]interface{})[0].(map[string]interface{})
+			attr := resource["resource"].(map[string]interface{})["attributes"].([]interface{})[0]
+			So(attr, ShouldResemble, map[string]interface{}{
+				"key":   "service.name",
+				"value": map[string]interface{}{"stringValue": "horizon-test"},
+			})
+
+			scope := resource["scopeSpans"].([]interface{})[0].(map[string]interface{})
+			got := scope["spans"].([]interface{})[0].(map[string]interface{})
+			So(got["traceId"], ShouldEqual, span.TraceID.String())
+			So(got["spanId"], ShouldEqual, span.SpanID.String())
+			So(got["parentSpanId"], ShouldBeNil)
+			So(got["name"], ShouldEqual, "GET /ledgers")
+			So(got["kind"], ShouldEqual, 2.0)
+			So(got["status"], ShouldResemble, map[string]interface{}{"code": 2.0, "message": "broken"})
+			So(got["attributes"], ShouldResemble, []interface{}{map[string]interface{}{
+				"key":   "http.status_code",
+				"value": map[string]interface{}{"intValue": "500"},
+			}})
+		})
+
+		Convey("fails when the collector does", func() {
+			status = http.StatusServiceUnavailable
+			So(exporter.Export([]*Span{span}), ShouldNotBeNil)
+		})
+	})
+}
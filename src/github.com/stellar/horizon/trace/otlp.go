@@ -0,0 +1,174 @@
+package trace
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-errors/errors"
+)
+
+// DefaultServiceName is the service.name of the spans an OTLPExporter exports
+// when its ServiceName is empty
+const DefaultServiceName = "horizon"
+
+// OTLPExporter exports spans to URL, the traces endpoint of an OpenTelemetry
+// collector or of Jaeger, such as http://localhost:4318/v1/traces, over
+// OTLP/HTTP in its json encoding.
+type OTLPExporter struct {
+	URL         string
+	ServiceName string
+
+	// Client makes the requests of the exporter, a client with a timeout of 10
+	// seconds when nil
+	Client *http.Client
+}
+
+var defaultOTLPClient = &http.Client{Timeout: 10 * time.Second}
+
+// Export is a method for Exporter
+func (e *OTLPExporter) Export(spans []*Span) error {
+	body, err := json.Marshal(e.request(spans))
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	client := e.Client
+	if client == nil {
+		client = defaultOTLPClient
+	}
+
+	resp, err := client.Post(e.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.Errorf("trace collector responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// The types below are those of the json encoding of an
+// ExportTraceServiceRequest of OTLP
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              Kind            `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpAttribute struct {
+	Key   string                 `json:"key"`
+	Value map[string]interface{} `json:"value"`
+}
+
+// the codes of the statuses of spans
+const (
+	otlpStatusOK    = 1
+	otlpStatusError = 2
+)
+
+func (e *OTLPExporter) request(spans []*Span) otlpRequest {
+	service := e.ServiceName
+	if service == "" {
+		service = DefaultServiceName
+	}
+
+	scope := otlpScopeSpans{Scope: otlpScope{Name: "github.com/stellar/horizon"}}
+	for _, s := range spans {
+		span := otlpSpan{
+			TraceID:           s.TraceID.String(),
+			SpanID:            s.SpanID.String(),
+			Name:              s.Name(),
+			Kind:              s.Kind,
+			StartTimeUnixNano: strconv.FormatInt(s.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.End.UnixNano(), 10),
+			Status:            otlpStatus{Code: otlpStatusOK},
+		}
+
+		if s.ParentID != (SpanID{}) {
+			span.ParentSpanID = s.ParentID.String()
+		}
+
+		for k, v := range s.Attributes() {
+			span.Attributes = append(span.Attributes, attribute(k, v))
+		}
+
+		if err := s.Err(); err != "" {
+			span.Status = otlpStatus{Code: otlpStatusError, Message: err}
+		}
+
+		scope.Spans = append(scope.Spans, span)
+	}
+
+	return otlpRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource:   otlpResource{Attributes: []otlpAttribute{attribute("service.name", service)}},
+			ScopeSpans: []otlpScopeSpans{scope},
+		}},
+	}
+}
+
+// attribute returns the attribute key of value v, encoded as OTLP encodes its
+// type
+func attribute(key string, v interface{}) otlpAttribute {
+	var value map[string]interface{}
+
+	switch v := v.(type) {
+	case string:
+		value = map[string]interface{}{"stringValue": v}
+	case bool:
+		value = map[string]interface{}{"boolValue": v}
+	case int:
+		value = map[string]interface{}{"intValue": strconv.FormatInt(int64(v), 10)}
+	case int32:
+		value = map[string]interface{}{"intValue": strconv.FormatInt(int64(v), 10)}
+	case int64:
+		value = map[string]interface{}{"intValue": strconv.FormatInt(v, 10)}
+	case float64:
+		value = map[string]interface{}{"doubleValue": v}
+	default:
+		value = map[string]interface{}{"stringValue": fmt.Sprint(v)}
+	}
+
+	return otlpAttribute{Key: key, Value: value}
+}
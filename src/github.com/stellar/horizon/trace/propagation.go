@@ -0,0 +1,89 @@
+package trace
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/net/context"
+)
+
+// Header is the W3C trace context header spans are propagated in
+const Header = "traceparent"
+
+// remoteParent is the span of another service a span of horizon is the child
+// of, as extracted from a traceparent header
+type remoteParent struct {
+	traceID TraceID
+	spanID  SpanID
+	sampled bool
+}
+
+// Extract returns a context derived from ctx, within which spans started are
+// the children of the span given in the traceparent header of r, a request
+// made of horizon.  ctx is returned as is when r has no valid traceparent.
+func Extract(ctx context.Context, r *http.Request) context.Context {
+	remote, ok := parseTraceparent(r.Header.Get(Header))
+	if !ok {
+		return ctx
+	}
+
+	return context.WithValue(ctx, &remoteKey, remote)
+}
+
+// Inject sets the traceparent header of req, a request horizon makes of
+// another service, to the span ctx is within, so that the spans of the
+// service are its children.  req is left as is when ctx is within no span.
+func Inject(ctx context.Context, req *http.Request) {
+	span := FromContext(ctx)
+	if span == nil {
+		return
+	}
+
+	req.Header.Set(Header, fmt.Sprintf("00-%s-%s-01", span.TraceID, span.SpanID))
+}
+
+// parseTraceparent parses a traceparent of version 00:
+// "00-<trace id>-<parent id>-<flags>", ids and flags in lowercase hex.
+func parseTraceparent(header string) (remoteParent, bool) {
+	var result remoteParent
+
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) != 4 || parts[0] != "00" {
+		return result, false
+	}
+
+	if !decodeID(result.traceID[:], parts[1]) || !decodeID(result.spanID[:], parts[2]) {
+		return result, false
+	}
+
+	flags, err := hex.DecodeString(parts[3])
+	if err != nil || len(flags) != 1 {
+		return result, false
+	}
+
+	result.sampled = flags[0]&1 == 1
+	return result, true
+}
+
+// decodeID decodes the lowercase hex encoded id s into dest, returning false
+// when s is not of the length of dest or is all zeroes, as invalid ids are.
+func decodeID(dest []byte, s string) bool {
+	if len(s) != hex.EncodedLen(len(dest)) || strings.ToLower(s) != s {
+		return false
+	}
+
+	_, err := hex.Decode(dest, []byte(s))
+	if err != nil {
+		return false
+	}
+
+	for _, b := range dest {
+		if b != 0 {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,158 @@
+package trace
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultFlushInterval is how often a Tracer exports the spans finished
+	// since it last did
+	DefaultFlushInterval = 5 * time.Second
+
+	// MaxBatch is the count of spans a Tracer exports at once.  A Tracer holds
+	// on to no more than MaxQueued spans waiting to be exported, dropping
+	// those finished beyond, so that an exporter that cannot keep up does not
+	// exhaust memory.
+	MaxBatch  = 512
+	MaxQueued = 8 * MaxBatch
+)
+
+// Exporter exports finished spans
+type Exporter interface {
+	Export(spans []*Span) error
+}
+
+// Tracer records the spans started within the contexts bound to it, exporting
+// them in batches as they finish: every DefaultFlushInterval, and as soon as
+// MaxBatch of them are waiting.
+//
+// A Tracer is safe for concurrent access.
+type Tracer struct {
+	Exporter Exporter
+
+	// SampleRate is the fraction, from 0 to 1, of the traces begun by horizon
+	// that are recorded.  Traces continued from other services are recorded as
+	// they were sampled there.
+	SampleRate float64
+
+	// OnError is called with the errors of exporting spans, when set
+	OnError func(err error)
+
+	lock    sync.Mutex
+	queued  []*Span
+	dropped int
+	flush   chan struct{}
+	done    chan struct{}
+	closing sync.Once
+	stopped sync.WaitGroup
+}
+
+// NewTracer returns a tracer exporting spans through exporter, having started
+// its goroutine of exports.  Call Close to stop it.
+func NewTracer(exporter Exporter, sampleRate float64) *Tracer {
+	t := &Tracer{
+		Exporter:   exporter,
+		SampleRate: sampleRate,
+		flush:      make(chan struct{}, 1),
+		done:       make(chan struct{}),
+	}
+
+	t.stopped.Add(1)
+	go t.run()
+	return t
+}
+
+// Close exports the spans waiting to be, then stops the tracer.  Spans
+// finished after Close are dropped.  Close may be called more than once.
+func (t *Tracer) Close() {
+	t.closing.Do(func() { close(t.done) })
+	t.stopped.Wait()
+}
+
+// Dropped returns the count of spans dropped because too many were waiting
+// to be exported
+func (t *Tracer) Dropped() int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.dropped
+}
+
+func (t *Tracer) run() {
+	defer t.stopped.Done()
+
+	ticker := time.NewTicker(DefaultFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.done:
+			t.export()
+			return
+		case <-ticker.C:
+		case <-t.flush:
+		}
+
+		t.export()
+	}
+}
+
+// export exports the spans waiting to be, MaxBatch at a time
+func (t *Tracer) export() {
+	t.lock.Lock()
+	spans := t.queued
+	t.queued = nil
+	t.lock.Unlock()
+
+	for len(spans) > 0 {
+		n := len(spans)
+		if n > MaxBatch {
+			n = MaxBatch
+		}
+
+		err := t.Exporter.Export(spans[:n])
+		if err != nil && t.OnError != nil {
+			t.OnError(err)
+		}
+		spans = spans[n:]
+	}
+}
+
+// record queues the finished span s to be exported
+func (t *Tracer) record(s *Span) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	select {
+	case <-t.done:
+		t.dropped++
+		return
+	default:
+	}
+
+	if len(t.queued) >= MaxQueued {
+		t.dropped++
+		return
+	}
+
+	t.queued = append(t.queued, s)
+	if len(t.queued) >= MaxBatch {
+		select {
+		case t.flush <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// sample returns true if a new trace is to be recorded
+func (t *Tracer) sample() bool {
+	switch {
+	case t.SampleRate >= 1:
+		return true
+	case t.SampleRate <= 0:
+		return false
+	default:
+		return rand.Float64() < t.SampleRate
+	}
+}
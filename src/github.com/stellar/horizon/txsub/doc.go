@@ -10,4 +10,8 @@ package txsub
 // - system.go: txsub.System, the struct that ties all the interfaces together
 // - internal.go: helper functions
 // - open_submission_list.go: A default implementation of the OpenSubmissionList interface
+// - submission_queue.go: A default implementation of the SubmissionQueue interface
+// - resubmitter.go: txsub.Resubmitter, which resends open submissions
+// - validator.go: txsub.Validator, which checks envelopes before submission
 // - submitter.go: A default implementation of the Submitter interface
+// - multi_submitter.go: txsub.MultiSubmitter, which submits to several stellar-core instances
@@ -9,6 +9,18 @@ import (
 
 var (
 	ErrNoResults = errors.New("No result found")
+
+	// ErrQueueFull is returned when a submission would wait in the queue of a
+	// source account that already holds as many submissions as it may.
+	ErrQueueFull = errors.New("Too many submissions queued for source account")
+
+	// ErrTooManyOpenSubmissions is returned when a new submission would exceed
+	// the system's cap on open submissions.
+	ErrTooManyOpenSubmissions = errors.New("Too many open submissions")
+
+	// ErrShuttingDown is the result of submissions left open or queued as the
+	// system shuts down, and of those made after it has.
+	ErrShuttingDown = errors.New("Submission system shutting down")
 )
 
 // FailedTransactionError represent an error that occurred because
@@ -61,6 +73,18 @@ func (fte *FailedTransactionError) OperationResultCodes() (result []string, err
 	return
 }
 
+// InvalidTransactionError represents an error that occurred because the
+// submission system's Validator refused to submit the transaction.  Reason is
+// one of the Invalid* constants.
+type InvalidTransactionError struct {
+	Reason      string
+	EnvelopeXDR string
+}
+
+func (err *InvalidTransactionError) Error() string {
+	return fmt.Sprintf("tx invalid: %s", err.Reason)
+}
+
 // MalformedTransactionError represent an error that occurred because
 // a TransactionEnvelope could not be decoded from the provided data.
 type MalformedTransactionError struct {
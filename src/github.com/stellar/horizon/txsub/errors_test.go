@@ -0,0 +1,57 @@
+package txsub
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/go-stellar-base/xdr"
+)
+
+func TestFailedTransactionError(t *testing.T) {
+	Convey("txsub.FailedTransactionError", t, func() {
+		Convey("a transaction rejected outright has no operation codes", func() {
+			err := &FailedTransactionError{"AAAAAAAAAAD////7AAAAAA=="}
+
+			code, ierr := err.TransactionResultCode()
+			So(ierr, ShouldBeNil)
+			So(code, ShouldEqual, "tx_bad_seq")
+
+			opCodes, ierr := err.OperationResultCodes()
+			So(ierr, ShouldBeNil)
+			So(opCodes, ShouldBeEmpty)
+		})
+
+		Convey("a failed transaction has a code per operation", func() {
+			pr, ierr := xdr.NewPaymentResult(xdr.PaymentResultCodePaymentUnderfunded, nil)
+			So(ierr, ShouldBeNil)
+			tr, ierr := xdr.NewOperationResultTr(xdr.OperationTypePayment, pr)
+			So(ierr, ShouldBeNil)
+			opr, ierr := xdr.NewOperationResult(xdr.OperationResultCodeOpInner, tr)
+			So(ierr, ShouldBeNil)
+			noop, ierr := xdr.NewOperationResult(xdr.OperationResultCodeOpNoAccount, nil)
+			So(ierr, ShouldBeNil)
+			trr, ierr := xdr.NewTransactionResultResult(
+				xdr.TransactionResultCodeTxFailed,
+				[]xdr.OperationResult{opr, noop},
+			)
+			So(ierr, ShouldBeNil)
+
+			resultXDR, ierr := xdr.MarshalBase64(xdr.TransactionResult{FeeCharged: 200, Result: trr})
+			So(ierr, ShouldBeNil)
+			err := &FailedTransactionError{resultXDR}
+
+			code, ierr := err.TransactionResultCode()
+			So(ierr, ShouldBeNil)
+			So(code, ShouldEqual, "tx_failed")
+
+			opCodes, ierr := err.OperationResultCodes()
+			So(ierr, ShouldBeNil)
+			So(opCodes, ShouldResemble, []string{"op_underfunded", "op_no_source_account"})
+		})
+
+		Convey("errors on undecodable results", func() {
+			_, ierr := (&FailedTransactionError{"not xdr"}).TransactionResultCode()
+			So(ierr, ShouldNotBeNil)
+		})
+	})
+}
@@ -2,15 +2,17 @@ package txsub
 
 import (
 	"github.com/stellar/go-stellar-base/build"
-	"github.com/stellar/go-stellar-base/strkey"
 	"github.com/stellar/go-stellar-base/xdr"
+	"github.com/stellar/horizon/strkeys"
 	"golang.org/x/net/context"
 )
 
 type envelopeInfo struct {
 	Hash          string
+	HashBytes     [32]byte
 	Sequence      uint64
 	SourceAddress string
+	Envelope      xdr.TransactionEnvelope
 }
 
 func extractEnvelopeInfo(ctx context.Context, env string, passphrase string) (result envelopeInfo, err error) {
@@ -31,10 +33,15 @@ func extractEnvelopeInfo(ctx context.Context, env string, passphrase string) (re
 		return
 	}
 
+	result.HashBytes, err = txb.Hash()
+	if err != nil {
+		return
+	}
+
+	result.Envelope = tx
 	result.Sequence = uint64(tx.Tx.SeqNum)
 
-	aid := tx.Tx.SourceAccount.MustEd25519()
-	result.SourceAddress, err = strkey.Encode(strkey.VersionByteAccountID, aid[:])
+	result.SourceAddress, err = strkeys.Address(tx.Tx.SourceAccount)
 
 	return
 }
@@ -52,6 +52,61 @@ type Submitter interface {
 	Submit(context.Context, string) SubmissionResult
 }
 
+// SequenceProvider represents an abstract store that can lookup the current
+// sequence numbers of accounts.  A SequenceProvider is used to decide whether a
+// submission must wait in the SubmissionQueue for the transactions preceding
+// it to be included in the ledger.
+type SequenceProvider interface {
+	// Look up the sequence numbers of the accounts with the provided addresses.
+	// Accounts that do not exist are left out of the result.
+	Get(context.Context, []string) (map[string]uint64, error)
+}
+
+// SubmissionQueue represents the structure that holds submissions whose
+// sequence numbers are ahead of their source accounts', so that a client can
+// submit several consecutive transactions from one account at once.  Each is
+// released for submission once the transaction preceding it is included in the
+// ledger.
+//
+// NOTE:  An implementation of this interface will be called from multiple go-routines
+// concurrently.
+type SubmissionQueue interface {
+	// Push adds the provided submission to the queue of its source account.
+	Push(context.Context, QueuedSubmission) error
+
+	// Addresses returns the addresses of the source accounts that have
+	// submissions queued.
+	Addresses(context.Context) []string
+
+	// Update removes and returns the submissions that are ready to submit,
+	// given the current sequence numbers of their source accounts: the
+	// submission that follows each account's sequence number, along with any
+	// the account's sequence number has overtaken.
+	Update(context.Context, map[string]uint64) []QueuedSubmission
+
+	// Clean removes any queued submissions over the provided age, returning how
+	// many remain.
+	Clean(context.Context, time.Duration) (int, error)
+}
+
+// QueuedSubmission is a submission held in a SubmissionQueue.
+type QueuedSubmission struct {
+	// The address of the transaction's source account
+	SourceAddress string
+
+	// The transaction's sequence number
+	Sequence uint64
+
+	// The transaction hash
+	Hash string
+
+	// The base64-encoded TransactionEnvelope to submit
+	EnvelopeXDR string
+
+	// When the submission was queued
+	QueuedAt time.Time
+}
+
 // Result represents the response from a ResultProvider.  Given no
 // Err is set, the rest of the struct should be populated appropriately.
 type Result struct {
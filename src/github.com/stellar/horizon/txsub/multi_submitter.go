@@ -0,0 +1,197 @@
+package txsub
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-errors/errors"
+	"golang.org/x/net/context"
+)
+
+const (
+	// StateSynced is the state stellar-core reports on its /info endpoint
+	// while it is in sync with the network.
+	StateSynced = "Synced!"
+
+	// HealthCheckTimeout is how long a MultiSubmitter waits for an instance
+	// to answer its health check.
+	HealthCheckTimeout = 5 * time.Second
+)
+
+// MultiSubmitter is a Submitter that submits to the healthiest of several
+// stellar-core instances, failing over to the next healthiest when a
+// submission errors, so that submissions carry on while an instance restarts.
+//
+// Instances are ranked by their health, as last checked by CheckHealth: those
+// in sync with the network first, then those with the fewest submissions
+// failed in a row, then those that answered their health check fastest.
+// Instances yet to be checked are assumed to be in sync.
+type MultiSubmitter struct {
+	http      *http.Client
+	checker   *http.Client
+	lock      sync.Mutex
+	instances []*coreInstance
+}
+
+// coreInstance is a stellar-core instance submitted to by a MultiSubmitter,
+// along with its health.
+type coreInstance struct {
+	submitter *submitter
+	unsynced  bool
+	failures  int
+	latency   time.Duration
+}
+
+// NewMultiSubmitter returns a MultiSubmitter that submits to the stellar-core
+// instances at urls using the http client h.
+func NewMultiSubmitter(h *http.Client, urls []string) *MultiSubmitter {
+	result := &MultiSubmitter{
+		http:    h,
+		checker: &http.Client{Transport: h.Transport, Timeout: HealthCheckTimeout},
+	}
+
+	for _, u := range urls {
+		result.instances = append(result.instances, &coreInstance{
+			submitter: &submitter{http: h, coreURL: u},
+		})
+	}
+
+	return result
+}
+
+// Submit sends the provided envelope to the healthiest stellar-core instance,
+// trying the others in turn, by health, for as long as submissions error.
+// When every instance errors, the first transaction failure reported is
+// returned, as it is the most telling, or otherwise the last error.
+func (sub *MultiSubmitter) Submit(ctx context.Context, env string) (result SubmissionResult) {
+	start := time.Now()
+	defer func() { result.Duration = time.Since(start) }()
+
+	var failed *SubmissionResult
+	for _, instance := range sub.ranked() {
+		result = instance.submitter.Submit(ctx, env)
+
+		_, isFailed := result.Err.(*FailedTransactionError)
+		sub.record(instance, result.Err == nil || isFailed)
+
+		if result.Err == nil {
+			return
+		}
+
+		if isFailed && failed == nil {
+			r := result
+			failed = &r
+		}
+	}
+
+	if failed != nil {
+		result = *failed
+	}
+
+	if len(sub.instances) == 0 {
+		result.Err = errors.New("no stellar-core instances to submit to")
+	}
+
+	return
+}
+
+// CheckHealth asks each stellar-core instance for its state, recording whether
+// it is in sync with the network and how long it took to answer.  Instances
+// that cannot be reached are out of sync.
+func (sub *MultiSubmitter) CheckHealth(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	for _, instance := range sub.instances {
+		wg.Add(1)
+		go func(instance *coreInstance) {
+			defer wg.Done()
+
+			start := time.Now()
+			state, err := sub.state(instance.submitter.coreURL)
+			latency := time.Since(start)
+
+			sub.lock.Lock()
+			defer sub.lock.Unlock()
+			instance.unsynced = err != nil || state != StateSynced
+			instance.latency = latency
+		}(instance)
+	}
+
+	wg.Wait()
+}
+
+// state loads the state of the stellar-core instance at coreURL from its
+// /info endpoint.
+func (sub *MultiSubmitter) state(coreURL string) (string, error) {
+	u, err := url.Parse(coreURL)
+	if err != nil {
+		return "", errors.Wrap(err, 1)
+	}
+	u.Path = "/info"
+
+	resp, err := sub.checker.Get(u.String())
+	if err != nil {
+		return "", errors.Wrap(err, 1)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("stellar-core responded with status %d", resp.StatusCode)
+	}
+
+	var info struct {
+		Info struct {
+			State string `json:"state"`
+		} `json:"info"`
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&info)
+	if err != nil {
+		return "", errors.Wrap(err, 1)
+	}
+
+	return info.Info.State, nil
+}
+
+// ranked returns the instances, healthiest first.
+func (sub *MultiSubmitter) ranked() []*coreInstance {
+	sub.lock.Lock()
+	defer sub.lock.Unlock()
+
+	result := append([]*coreInstance{}, sub.instances...)
+	sort.Stable(byHealth(result))
+	return result
+}
+
+// record records the outcome of a submission to instance: ok when stellar-core
+// answered, whether or not it accepted the transaction.
+func (sub *MultiSubmitter) record(instance *coreInstance, ok bool) {
+	sub.lock.Lock()
+	defer sub.lock.Unlock()
+
+	if ok {
+		instance.failures = 0
+	} else {
+		instance.failures++
+	}
+}
+
+type byHealth []*coreInstance
+
+func (s byHealth) Len() int      { return len(s) }
+func (s byHealth) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byHealth) Less(i, j int) bool {
+	a, b := s[i], s[j]
+	switch {
+	case a.unsynced != b.unsynced:
+		return !a.unsynced
+	case a.failures != b.failures:
+		return a.failures < b.failures
+	default:
+		return a.latency < b.latency
+	}
+}
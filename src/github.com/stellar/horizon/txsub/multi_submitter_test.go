@@ -0,0 +1,90 @@
+package txsub
+
+import (
+	"net/http"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/test"
+)
+
+func TestMultiSubmitter(t *testing.T) {
+	ctx := test.Context()
+
+	Convey("MultiSubmitter", t, func() {
+		pending := test.NewStaticMockServer(`{
+			"info": {"state": "Synced!"},
+			"status": "PENDING"
+			}`)
+		defer pending.Close()
+
+		Convey("submits to the first instance when all are healthy", func() {
+			other := test.NewStaticMockServer(`{"status": "PENDING"}`)
+			defer other.Close()
+
+			s := NewMultiSubmitter(http.DefaultClient, []string{pending.URL, other.URL})
+			sr := s.Submit(ctx, "hello")
+			So(sr.Err, ShouldBeNil)
+			So(pending.LastRequest, ShouldNotBeNil)
+			So(other.LastRequest, ShouldBeNil)
+		})
+
+		Convey("fails over when an instance is not reachable", func() {
+			s := NewMultiSubmitter(http.DefaultClient, []string{"http://127.0.0.1:65535", pending.URL})
+			sr := s.Submit(ctx, "hello")
+			So(sr.Err, ShouldBeNil)
+			So(pending.LastRequest.URL.Query().Get("blob"), ShouldEqual, "hello")
+
+			Convey("and ranks the unreachable instance last afterwards", func() {
+				ranked := s.ranked()
+				So(ranked[0].submitter.coreURL, ShouldEqual, pending.URL)
+			})
+		})
+
+		Convey("fails over when an instance responds with an ERROR status", func() {
+			failing := test.NewStaticMockServer(`{"status": "ERROR", "error": "1234"}`)
+			defer failing.Close()
+
+			s := NewMultiSubmitter(http.DefaultClient, []string{failing.URL, pending.URL})
+			sr := s.Submit(ctx, "hello")
+			So(sr.Err, ShouldBeNil)
+			So(failing.LastRequest, ShouldNotBeNil)
+			So(pending.LastRequest, ShouldNotBeNil)
+		})
+
+		Convey("returns the transaction failure when every instance errors", func() {
+			failing := test.NewStaticMockServer(`{"status": "ERROR", "error": "1234"}`)
+			defer failing.Close()
+
+			s := NewMultiSubmitter(http.DefaultClient, []string{failing.URL, "http://127.0.0.1:65535"})
+			sr := s.Submit(ctx, "hello")
+			So(sr.Err, ShouldHaveSameTypeAs, &FailedTransactionError{})
+			So(sr.Err.(*FailedTransactionError).ResultXDR, ShouldEqual, "1234")
+		})
+
+		Convey("errors when there are no instances", func() {
+			s := NewMultiSubmitter(http.DefaultClient, nil)
+			sr := s.Submit(ctx, "hello")
+			So(sr.Err, ShouldNotBeNil)
+		})
+
+		Convey("prefers instances in sync with the network once checked", func() {
+			catchingUp := test.NewStaticMockServer(`{
+				"info": {"state": "Catching up"},
+				"status": "PENDING"
+				}`)
+			defer catchingUp.Close()
+
+			s := NewMultiSubmitter(http.DefaultClient, []string{catchingUp.URL, pending.URL})
+			s.CheckHealth(ctx)
+			So(s.instances[0].unsynced, ShouldBeTrue)
+			So(s.instances[1].unsynced, ShouldBeFalse)
+
+			catchingUp.LastRequest = nil
+			sr := s.Submit(ctx, "hello")
+			So(sr.Err, ShouldBeNil)
+			So(catchingUp.LastRequest, ShouldBeNil)
+			So(pending.LastRequest.URL.Path, ShouldEqual, "/tx")
+		})
+	})
+}
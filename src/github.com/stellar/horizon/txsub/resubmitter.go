@@ -0,0 +1,138 @@
+package txsub
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Resubmitter re-sends to stellar-core the envelopes of open submissions that
+// have yet to be included in a ledger, in case stellar-core dropped them, as it
+// may when it restarts or its queue of pending transactions overflows.
+//
+// A submission is first resent once Ledgers ledgers have closed without it,
+// and after each attempt the wait doubles.  Each wait is lengthened at random
+// by up to half again, so that submissions made together are not all resent
+// together.  A submission is resent at most MaxAttempts times.
+//
+// The attempts made for each submission are kept for as long as open
+// submissions are, whether or not the transaction has since been included.
+type Resubmitter struct {
+	Ledgers     int
+	MaxAttempts int
+
+	lock        sync.Mutex
+	ledger      int
+	submissions map[string]*resubmission
+	rand        *rand.Rand
+}
+
+// Attempt records one submission of an envelope to stellar-core.  Err is the
+// error of the submission, if it failed.
+type Attempt struct {
+	SubmittedAt time.Time
+	Err         error
+}
+
+// resubmission tracks an open submission, and the ledger it is next due to be
+// resent at.
+type resubmission struct {
+	Envelope string
+	Attempts []Attempt
+	DueAt    int
+}
+
+// Watch starts tracking the submission of env, whose transaction has the
+// provided hash, recording the submission as its first attempt.  Submissions
+// already tracked are left as they are.
+func (r *Resubmitter) Watch(hash string, env string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.init()
+
+	if _, ok := r.submissions[hash]; ok {
+		return
+	}
+
+	s := &resubmission{Envelope: env}
+	r.submissions[hash] = s
+	r.record(s, nil)
+}
+
+// Due is called once per ledger.  It returns, by hash, the envelopes amongst
+// the pending submissions that are due to be resent.  Each should be resent
+// and its outcome passed to Record.
+func (r *Resubmitter) Due(pending []string) map[string]string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.init()
+
+	r.ledger++
+
+	due := map[string]string{}
+	for _, hash := range pending {
+		s, ok := r.submissions[hash]
+		if !ok || len(s.Attempts) > r.MaxAttempts || r.ledger < s.DueAt {
+			continue
+		}
+
+		due[hash] = s.Envelope
+	}
+
+	return due
+}
+
+// Record records an attempt to resend the submission with the provided hash,
+// which failed with err unless it is nil.
+func (r *Resubmitter) Record(hash string, err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.init()
+
+	if s, ok := r.submissions[hash]; ok {
+		r.record(s, err)
+	}
+}
+
+// Attempts returns the attempts made to submit the transaction with the
+// provided hash, the first of which is its original submission.
+func (r *Resubmitter) Attempts(hash string) []Attempt {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.init()
+
+	s, ok := r.submissions[hash]
+	if !ok {
+		return nil
+	}
+
+	return append([]Attempt{}, s.Attempts...)
+}
+
+// Clean stops tracking submissions first made over the provided age ago.
+func (r *Resubmitter) Clean(maxAge time.Duration) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.init()
+
+	for hash, s := range r.submissions {
+		if time.Since(s.Attempts[0].SubmittedAt) > maxAge {
+			delete(r.submissions, hash)
+		}
+	}
+}
+
+// record appends an attempt to s, scheduling the next.
+func (r *Resubmitter) record(s *resubmission, err error) {
+	s.Attempts = append(s.Attempts, Attempt{SubmittedAt: time.Now(), Err: err})
+
+	wait := r.Ledgers << uint(len(s.Attempts)-1)
+	s.DueAt = r.ledger + wait + r.rand.Intn(wait/2+1)
+}
+
+func (r *Resubmitter) init() {
+	if r.submissions == nil {
+		r.submissions = map[string]*resubmission{}
+		r.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+}
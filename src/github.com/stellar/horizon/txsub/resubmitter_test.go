@@ -0,0 +1,83 @@
+package txsub
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestResubmitter(t *testing.T) {
+	Convey("txsub.Resubmitter", t, func() {
+		r := &Resubmitter{Ledgers: 2, MaxAttempts: 2}
+		r.Watch("a", "env-a")
+
+		// ticks calls Due n times, returning the calls that found "a" due
+		ticks := func(n int) (due []int) {
+			for i := 1; i <= n; i++ {
+				if _, ok := r.Due([]string{"a"})["a"]; ok {
+					due = append(due, i)
+				}
+			}
+			return
+		}
+
+		Convey("Watch() records the original submission", func() {
+			attempts := r.Attempts("a")
+			So(len(attempts), ShouldEqual, 1)
+			So(attempts[0].SubmittedAt, ShouldHappenWithin, 1*time.Second, time.Now())
+			So(attempts[0].Err, ShouldBeNil)
+
+			r.Watch("a", "env-a")
+			So(len(r.Attempts("a")), ShouldEqual, 1)
+		})
+
+		Convey("Due()", func() {
+			Convey("waits at least Ledgers ledgers, and up to half again", func() {
+				due := ticks(3)
+				So(len(due), ShouldBeGreaterThan, 0)
+				So(due[0], ShouldBeBetweenOrEqual, 2, 3)
+				So(r.Due([]string{"a"}), ShouldResemble, map[string]string{"a": "env-a"})
+			})
+
+			Convey("doubles the wait after each attempt", func() {
+				ticks(3)
+				r.Record("a", nil)
+
+				due := ticks(6)
+				So(len(due), ShouldBeGreaterThan, 0)
+				So(due[0], ShouldBeBetweenOrEqual, 4, 6)
+			})
+
+			Convey("stops after MaxAttempts attempts", func() {
+				ticks(3)
+				r.Record("a", nil)
+				ticks(6)
+				r.Record("a", errors.New("busted"))
+
+				So(ticks(20), ShouldBeEmpty)
+
+				attempts := r.Attempts("a")
+				So(len(attempts), ShouldEqual, 3)
+				So(attempts[1].Err, ShouldBeNil)
+				So(attempts[2].Err, ShouldNotBeNil)
+			})
+
+			Convey("ignores submissions no longer pending", func() {
+				for i := 0; i < 3; i++ {
+					So(r.Due([]string{}), ShouldBeEmpty)
+				}
+			})
+		})
+
+		Convey("Clean() forgets old submissions", func() {
+			r.Clean(1 * time.Minute)
+			So(len(r.Attempts("a")), ShouldEqual, 1)
+
+			<-time.After(10 * time.Millisecond)
+			r.Clean(5 * time.Millisecond)
+			So(r.Attempts("a"), ShouldBeNil)
+		})
+	})
+}
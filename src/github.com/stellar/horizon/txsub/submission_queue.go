@@ -0,0 +1,125 @@
+package txsub
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// DefaultMaxQueueSize is the most submissions the default SubmissionQueue
+// holds for any one account.
+const DefaultMaxQueueSize = 100
+
+// NewDefaultSubmissionQueue returns a queue that holds submissions purely in
+// memory, up to maxSize for each account.  A maxSize of 0 uses
+// DefaultMaxQueueSize.
+func NewDefaultSubmissionQueue(maxSize int) SubmissionQueue {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxQueueSize
+	}
+
+	return &submissionQueue{
+		maxSize: maxSize,
+		queues:  map[string][]QueuedSubmission{},
+	}
+}
+
+// submissionQueue keeps the submissions of each account ordered by sequence
+// number.
+type submissionQueue struct {
+	sync.Mutex
+	maxSize int
+	queues  map[string][]QueuedSubmission
+}
+
+func (q *submissionQueue) Push(ctx context.Context, s QueuedSubmission) error {
+	q.Lock()
+	defer q.Unlock()
+
+	queue := q.queues[s.SourceAddress]
+	if len(queue) >= q.maxSize {
+		return ErrQueueFull
+	}
+
+	if s.QueuedAt.IsZero() {
+		s.QueuedAt = time.Now()
+	}
+
+	i := sort.Search(len(queue), func(i int) bool {
+		return queue[i].Sequence > s.Sequence
+	})
+
+	queue = append(queue, QueuedSubmission{})
+	copy(queue[i+1:], queue[i:])
+	queue[i] = s
+
+	q.queues[s.SourceAddress] = queue
+	return nil
+}
+
+func (q *submissionQueue) Addresses(ctx context.Context) []string {
+	q.Lock()
+	defer q.Unlock()
+	results := make([]string, 0, len(q.queues))
+
+	for address := range q.queues {
+		results = append(results, address)
+	}
+
+	return results
+}
+
+func (q *submissionQueue) Update(ctx context.Context, sequences map[string]uint64) []QueuedSubmission {
+	q.Lock()
+	defer q.Unlock()
+
+	var ready []QueuedSubmission
+	for address, queue := range q.queues {
+		current, ok := sequences[address]
+		if !ok {
+			continue
+		}
+
+		n := 0
+		for n < len(queue) && queue[n].Sequence <= current+1 {
+			n++
+		}
+
+		ready = append(ready, queue[:n]...)
+		q.set(address, queue[n:])
+	}
+
+	return ready
+}
+
+func (q *submissionQueue) Clean(ctx context.Context, maxAge time.Duration) (int, error) {
+	q.Lock()
+	defer q.Unlock()
+
+	remaining := 0
+	for address, queue := range q.queues {
+		kept := queue[:0]
+		for _, s := range queue {
+			if time.Since(s.QueuedAt) <= maxAge {
+				kept = append(kept, s)
+			}
+		}
+
+		q.set(address, kept)
+		remaining += len(kept)
+	}
+
+	return remaining, nil
+}
+
+// set replaces the queue of address, forgetting the account once it is empty.
+func (q *submissionQueue) set(address string, queue []QueuedSubmission) {
+	if len(queue) == 0 {
+		delete(q.queues, address)
+		return
+	}
+
+	q.queues[address] = queue
+}
@@ -0,0 +1,87 @@
+package txsub
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/test"
+)
+
+func TestDefaultSubmissionQueue(t *testing.T) {
+	ctx := test.Context()
+
+	Convey("submissionQueue (The default SubmissionQueue implementation)", t, func() {
+		queue := NewDefaultSubmissionQueue(3)
+		sub := func(address string, seq uint64) QueuedSubmission {
+			return QueuedSubmission{SourceAddress: address, Sequence: seq, Hash: address}
+		}
+
+		Convey("Push()", func() {
+			for _, seq := range []uint64{5, 3, 4} {
+				So(queue.Push(ctx, sub("a", seq)), ShouldBeNil)
+			}
+
+			Convey("keeps each account's submissions in sequence order", func() {
+				q := queue.(*submissionQueue).queues["a"]
+				So(len(q), ShouldEqual, 3)
+				So(q[0].Sequence, ShouldEqual, 3)
+				So(q[1].Sequence, ShouldEqual, 4)
+				So(q[2].Sequence, ShouldEqual, 5)
+				So(q[0].QueuedAt, ShouldHappenWithin, 1*time.Second, time.Now())
+			})
+
+			Convey("errors when an account's queue is full", func() {
+				So(queue.Push(ctx, sub("a", 6)), ShouldEqual, ErrQueueFull)
+				So(queue.Push(ctx, sub("b", 6)), ShouldBeNil)
+				So(len(queue.Addresses(ctx)), ShouldEqual, 2)
+			})
+		})
+
+		Convey("Update()", func() {
+			queue.Push(ctx, sub("a", 3))
+			queue.Push(ctx, sub("a", 4))
+			queue.Push(ctx, sub("b", 10))
+
+			Convey("releases only the submission that follows the account's sequence", func() {
+				ready := queue.Update(ctx, map[string]uint64{"a": 1, "b": 1})
+				So(ready, ShouldBeEmpty)
+
+				ready = queue.Update(ctx, map[string]uint64{"a": 2})
+				So(len(ready), ShouldEqual, 1)
+				So(ready[0].Sequence, ShouldEqual, 3)
+
+				ready = queue.Update(ctx, map[string]uint64{"a": 3})
+				So(len(ready), ShouldEqual, 1)
+				So(ready[0].Sequence, ShouldEqual, 4)
+				So(queue.Addresses(ctx), ShouldResemble, []string{"b"})
+			})
+
+			Convey("releases submissions the account's sequence has overtaken", func() {
+				ready := queue.Update(ctx, map[string]uint64{"a": 10})
+				So(len(ready), ShouldEqual, 2)
+			})
+
+			Convey("keeps the submissions of accounts without a sequence", func() {
+				ready := queue.Update(ctx, map[string]uint64{})
+				So(ready, ShouldBeEmpty)
+				So(len(queue.Addresses(ctx)), ShouldEqual, 2)
+			})
+		})
+
+		Convey("Clean()", func() {
+			old := sub("a", 3)
+			old.QueuedAt = time.Now().Add(-1 * time.Hour)
+			queue.Push(ctx, old)
+			queue.Push(ctx, sub("a", 4))
+
+			remaining, err := queue.Clean(ctx, 1*time.Minute)
+			So(err, ShouldBeNil)
+			So(remaining, ShouldEqual, 1)
+
+			remaining, _ = queue.Clean(ctx, 0)
+			So(remaining, ShouldEqual, 0)
+			So(queue.Addresses(ctx), ShouldBeEmpty)
+		})
+	})
+}
@@ -3,6 +3,8 @@ package txsub
 import (
 	"encoding/json"
 	"github.com/go-errors/errors"
+	"github.com/stellar/horizon/context/requestid"
+	"github.com/stellar/horizon/trace"
 	"golang.org/x/net/context"
 	"net/http"
 	"net/url"
@@ -41,10 +43,23 @@ type submitter struct {
 }
 
 // Submit sends the provided envelope to stellar-core and parses the response into
-// a SubmissionResult
+// a SubmissionResult.  The submission is traced as a "core.submit" span, which
+// fails only when the submission does: transactions that stellar-core rejects
+// are a response like any other.
 func (sub *submitter) Submit(ctx context.Context, env string) (result SubmissionResult) {
 	start := time.Now()
-	defer func() { result.Duration = time.Since(start) }()
+	ctx, span := trace.Start(ctx, "core.submit")
+	span.SetKind(trace.KindClient)
+	span.SetAttribute("core.url", sub.coreURL)
+	defer func() {
+		result.Duration = time.Since(start)
+
+		err := result.Err
+		if _, ok := err.(*FailedTransactionError); ok {
+			err = nil
+		}
+		span.Finish(err)
+	}()
 
 	// construct the request
 	u, err := url.Parse(sub.coreURL)
@@ -63,6 +78,8 @@ func (sub *submitter) Submit(ctx context.Context, env string) (result Submission
 		result.Err = errors.Wrap(err, 1)
 		return
 	}
+	requestid.Propagate(ctx, req)
+	trace.Inject(ctx, req)
 
 	// perform the submission
 	resp, err := sub.http.Do(req)
@@ -5,6 +5,7 @@ import (
 	"github.com/stellar/horizon/log"
 	"golang.org/x/net/context"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -13,6 +14,7 @@ import (
 // to a stellar-core instance.
 type System struct {
 	initializer sync.Once
+	stopped     int32
 
 	Pending           OpenSubmissionList
 	Results           ResultProvider
@@ -20,6 +22,31 @@ type System struct {
 	NetworkPassphrase string
 	SubmissionTimeout time.Duration
 
+	// Validator, when set, refuses envelopes that fail its checks with an
+	// *InvalidTransactionError rather than submitting them.
+	Validator *Validator
+
+	// MaxOpenSubmissions, when non-zero, caps the count of open submissions:
+	// new submissions beyond it fail with ErrTooManyOpenSubmissions.
+	MaxOpenSubmissions int
+
+	// Queue, when set along with Sequences, holds submissions whose sequence
+	// numbers are ahead of their source accounts' until the transactions
+	// preceding them are included in the ledger, rather than submitting them
+	// to fail with txBAD_SEQ.
+	Queue     SubmissionQueue
+	Sequences SequenceProvider
+
+	// Resubmitter, when set, resends the envelopes of open submissions that
+	// stellar-core is slow to include in a ledger.
+	Resubmitter *Resubmitter
+
+	// Guard, when set, is asked before each transaction is sent to
+	// stellar-core whether it may be: submissions fail with the error it
+	// returns, and none are resent, as when stellar-core is found to be on
+	// another network.
+	Guard func() error
+
 	Metrics struct {
 		// SubmissionTimer exposes timing metrics about the rate and latency of
 		// submissions to stellar-core
@@ -36,16 +63,31 @@ type System struct {
 		// SuccessfulSubmissionsMeter tracks the rate of successful transactions that
 		// have been submitted to this process
 		SuccessfulSubmissionsMeter metrics.Meter
+
+		// QueuedSubmissionsGauge tracks the count of submissions waiting in the
+		// queue for the transactions preceding them
+		QueuedSubmissionsGauge metrics.Gauge
+
+		// ResubmissionsMeter tracks the rate at which open submissions are
+		// resent to stellar-core
+		ResubmissionsMeter metrics.Meter
 	}
 }
 
 // Submit submits the provided base64 encoded transaction envelope to the
-// network using this submission system.
+// network using this submission system.  A transaction already amongst the open
+// submissions is not submitted again: the returned channel instead receives
+// the result of the open submission, making it safe for clients to retry.
 func (sys *System) Submit(ctx context.Context, env string) (result <-chan Result) {
 	sys.Init(ctx)
 	response := make(chan Result, 1)
 	result = response
 
+	if atomic.LoadInt32(&sys.stopped) == 1 {
+		response <- Result{Err: ErrShuttingDown, EnvelopeXDR: env}
+		return
+	}
+
 	// calculate hash of transaction
 	info, err := extractEnvelopeInfo(ctx, env, sys.NetworkPassphrase)
 	if err != nil {
@@ -61,15 +103,65 @@ func (sys *System) Submit(ctx context.Context, env string) (result <-chan Result
 		return
 	}
 
+	// attach to the open submission of the same transaction, if any
+	if sys.isPending(ctx, info.Hash) {
+		err = sys.Pending.Add(ctx, info.Hash, response)
+		if err != nil {
+			response <- Result{Err: err, EnvelopeXDR: env}
+		}
+		return
+	}
+
+	if sys.Validator != nil {
+		if reason := sys.Validator.validate(info, time.Now()); reason != "" {
+			response <- Result{Err: &InvalidTransactionError{reason, env}, EnvelopeXDR: env}
+			return
+		}
+	}
+
+	if sys.MaxOpenSubmissions > 0 && len(sys.Pending.Pending(ctx)) >= sys.MaxOpenSubmissions {
+		response <- Result{Err: ErrTooManyOpenSubmissions, EnvelopeXDR: env}
+		return
+	}
+
+	// hold the submission back if it must wait for its predecessors
+	queued, err := sys.enqueue(ctx, info, env, response)
+	if err != nil {
+		response <- Result{Err: err, EnvelopeXDR: env}
+		return
+	}
+
+	if queued {
+		return
+	}
+
+	// if received or duplicate, add to the open submissions list
+	r, pending := sys.submit(ctx, info.Hash, env)
+	if pending {
+		sys.Pending.Add(ctx, info.Hash, response)
+		sys.watch(info.Hash, env)
+		return
+	}
+
+	response <- r
+	return
+}
+
+// submit sends env to stellar-core, returning its result unless the
+// submission is pending, awaiting inclusion in a ledger.  Nothing is sent while
+// Guard refuses it.
+func (sys *System) submit(ctx context.Context, hash string, env string) (r Result, pending bool) {
+	if err := sys.guard(); err != nil {
+		return Result{Err: err, EnvelopeXDR: env}, false
+	}
+
 	// submit to stellar-core
 	sr := sys.Submitter.Submit(ctx, env)
 	sys.Metrics.SubmissionTimer.Update(sr.Duration)
 
-	// if received or duplicate, add to the open submissions list
 	if sr.Err == nil {
 		sys.Metrics.SuccessfulSubmissionsMeter.Mark(1)
-		sys.Pending.Add(ctx, info.Hash, response)
-		return
+		return Result{}, true
 	}
 
 	sys.Metrics.FailedSubmissionsMeter.Mark(1)
@@ -77,27 +169,172 @@ func (sys *System) Submit(ctx context.Context, env string) (result <-chan Result
 	// any error other than "txBAD_SEQ" is a failure
 	isBad, err := sr.IsBadSeq()
 	if err != nil {
-		response <- Result{Err: err, EnvelopeXDR: env}
-		return
+		return Result{Err: err, EnvelopeXDR: env}, false
 	}
 
 	if !isBad {
-		response <- Result{Err: sr.Err, EnvelopeXDR: env}
-		return
+		return Result{Err: sr.Err, EnvelopeXDR: env}, false
 	}
 
 	// If error is txBAD_SEQ, check for the result again
-	r = sys.Results.ResultByHash(ctx, info.Hash)
+	r = sys.Results.ResultByHash(ctx, hash)
 
 	if r.Err == nil {
 		// If the found use it as the result
-		response <- r
-	} else {
-		// finally, return the bad_seq error if no result was found on 2nd attempt
-		response <- Result{Err: sr.Err, EnvelopeXDR: env}
+		return r, false
 	}
 
-	return
+	// finally, return the bad_seq error if no result was found on 2nd attempt
+	return Result{Err: sr.Err, EnvelopeXDR: env}, false
+}
+
+// enqueue queues the submission of env when its sequence number is ahead of
+// that of its source account, adding listener to the open submissions that
+// await a result.  It returns false when the submission need not wait, or
+// when the system has no queue.
+func (sys *System) enqueue(ctx context.Context, info envelopeInfo, env string, listener Listener) (bool, error) {
+	if sys.Queue == nil || sys.Sequences == nil {
+		return false, nil
+	}
+
+	sequences, err := sys.Sequences.Get(ctx, []string{info.SourceAddress})
+	if err != nil {
+		return false, err
+	}
+
+	// unknown accounts are left for stellar-core to reject
+	current, ok := sequences[info.SourceAddress]
+	if !ok || info.Sequence <= current+1 {
+		return false, nil
+	}
+
+	err = sys.Queue.Push(ctx, QueuedSubmission{
+		SourceAddress: info.SourceAddress,
+		Sequence:      info.Sequence,
+		Hash:          info.Hash,
+		EnvelopeXDR:   env,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return true, sys.Pending.Add(ctx, info.Hash, listener)
+}
+
+// release submits the queued submissions whose predecessors have been
+// included in the ledger, finishing those that fail at once.
+func (sys *System) release(ctx context.Context) {
+	addresses := sys.Queue.Addresses(ctx)
+	if len(addresses) == 0 {
+		return
+	}
+
+	sequences, err := sys.Sequences.Get(ctx, addresses)
+	if err != nil {
+		log.WithStack(ctx, err).Error(err)
+		return
+	}
+
+	for _, s := range sys.Queue.Update(ctx, sequences) {
+		log.WithField(ctx, "hash", s.Hash).Debug("releasing queued submission")
+
+		r, pending := sys.submit(ctx, s.Hash, s.EnvelopeXDR)
+		if pending {
+			sys.watch(s.Hash, s.EnvelopeXDR)
+			continue
+		}
+
+		r.Hash = s.Hash
+		sys.Pending.Finish(ctx, r)
+	}
+}
+
+// Hash returns the hash of the provided base64 encoded transaction envelope
+// on the system's network.  An envelope that cannot be decoded returns a
+// *MalformedTransactionError.
+func (sys *System) Hash(ctx context.Context, env string) (string, error) {
+	info, err := extractEnvelopeInfo(ctx, env, sys.NetworkPassphrase)
+	return info.Hash, err
+}
+
+// SourceAddress returns the address of the source account of the provided
+// base64 encoded transaction envelope.  An envelope that cannot be decoded
+// returns a *MalformedTransactionError.
+func (sys *System) SourceAddress(ctx context.Context, env string) (string, error) {
+	info, err := extractEnvelopeInfo(ctx, env, sys.NetworkPassphrase)
+	return info.SourceAddress, err
+}
+
+// Status looks up the result of the transaction with the provided hash, for
+// clients that submitted it without waiting on Submit's result.  Until a
+// result is known its Err is ErrNoResults, and pending reports whether the
+// transaction is amongst the open submissions still awaiting one.
+func (sys *System) Status(ctx context.Context, hash string) (r Result, pending bool) {
+	sys.Init(ctx)
+
+	r = sys.Results.ResultByHash(ctx, hash)
+	if r.Err != ErrNoResults {
+		return r, false
+	}
+
+	return r, sys.isPending(ctx, hash)
+}
+
+// isPending reports whether the transaction with the provided hash is amongst
+// the open submissions.
+func (sys *System) isPending(ctx context.Context, hash string) bool {
+	for _, h := range sys.Pending.Pending(ctx) {
+		if h == hash {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Attempts returns the attempts made to submit the transaction with the
+// provided hash, when the system resubmits transactions.
+func (sys *System) Attempts(ctx context.Context, hash string) []Attempt {
+	if sys.Resubmitter == nil {
+		return nil
+	}
+
+	return sys.Resubmitter.Attempts(hash)
+}
+
+// watch hands a pending submission to the resubmitter, if any.
+func (sys *System) watch(hash string, env string) {
+	if sys.Resubmitter != nil {
+		sys.Resubmitter.Watch(hash, env)
+	}
+}
+
+// guard returns the error of Guard, if any
+func (sys *System) guard() error {
+	if sys.Guard == nil {
+		return nil
+	}
+
+	return sys.Guard()
+}
+
+// resubmit resends the open submissions the resubmitter finds are due, unless
+// Guard refuses them.
+func (sys *System) resubmit(ctx context.Context) {
+	if sys.guard() != nil {
+		return
+	}
+
+	for hash, env := range sys.Resubmitter.Due(sys.Pending.Pending(ctx)) {
+		log.WithField(ctx, "hash", hash).Debug("resubmitting open submission")
+
+		sr := sys.Submitter.Submit(ctx, env)
+		sys.Metrics.SubmissionTimer.Update(sr.Duration)
+		sys.Metrics.ResubmissionsMeter.Mark(1)
+		sys.Resubmitter.Record(hash, sr.Err)
+	}
+
+	sys.Resubmitter.Clean(sys.SubmissionTimeout)
 }
 
 // Ticker triggers the system to update itself with any new data available.
@@ -105,6 +342,63 @@ func (sys *System) Tick(ctx context.Context) {
 	sys.Init(ctx)
 
 	log.Debugln(ctx, "ticking txsub system")
+	sys.finishIncluded(ctx)
+
+	if sys.Resubmitter != nil {
+		sys.resubmit(ctx)
+	}
+
+	if sys.Queue != nil && sys.Sequences != nil {
+		sys.release(ctx)
+
+		stillQueued, err := sys.Queue.Clean(ctx, sys.SubmissionTimeout)
+		if err != nil {
+			log.WithStack(ctx, err).Error(err)
+		}
+
+		sys.Metrics.QueuedSubmissionsGauge.Update(int64(stillQueued))
+	}
+
+	stillOpen, err := sys.Pending.Clean(ctx, sys.SubmissionTimeout)
+	if err != nil {
+		log.WithStack(ctx, err).Error(err)
+	}
+
+	sys.Metrics.OpenSubmissionsGauge.Update(int64(stillOpen))
+}
+
+// Shutdown stops the system, as when horizon shuts down.  Open and queued
+// submissions whose results are not yet known are finished with
+// ErrShuttingDown rather than left waiting for ledgers that nothing will tick
+// the system for, and so are submissions made from then on.  It returns the
+// count of submissions so finished.
+func (sys *System) Shutdown(ctx context.Context) int {
+	sys.Init(ctx)
+	atomic.StoreInt32(&sys.stopped, 1)
+
+	sys.finishIncluded(ctx)
+
+	if sys.Queue != nil {
+		_, err := sys.Queue.Clean(ctx, 0)
+		if err != nil {
+			log.WithStack(ctx, err).Error(err)
+		}
+		sys.Metrics.QueuedSubmissionsGauge.Update(0)
+	}
+
+	// the listeners of queued submissions are amongst the open ones
+	open := sys.Pending.Pending(ctx)
+	for _, hash := range open {
+		sys.Pending.Finish(ctx, Result{Hash: hash, Err: ErrShuttingDown})
+	}
+	sys.Metrics.OpenSubmissionsGauge.Update(0)
+
+	return len(open)
+}
+
+// finishIncluded finishes the open submissions whose transactions have
+// been included in a ledger.
+func (sys *System) finishIncluded(ctx context.Context) {
 	for _, hash := range sys.Pending.Pending(ctx) {
 		r := sys.Results.ResultByHash(ctx, hash)
 
@@ -126,13 +420,6 @@ func (sys *System) Tick(ctx context.Context) {
 			log.WithStack(ctx, r.Err).Error(r.Err)
 		}
 	}
-
-	stillOpen, err := sys.Pending.Clean(ctx, sys.SubmissionTimeout)
-	if err != nil {
-		log.WithStack(ctx, err).Error(err)
-	}
-
-	sys.Metrics.OpenSubmissionsGauge.Update(int64(stillOpen))
 }
 
 func (sys *System) Init(ctx context.Context) {
@@ -141,6 +428,8 @@ func (sys *System) Init(ctx context.Context) {
 		sys.Metrics.SuccessfulSubmissionsMeter = metrics.NewMeter()
 		sys.Metrics.SubmissionTimer = metrics.NewTimer()
 		sys.Metrics.OpenSubmissionsGauge = metrics.NewGauge()
+		sys.Metrics.QueuedSubmissionsGauge = metrics.NewGauge()
+		sys.Metrics.ResubmissionsMeter = metrics.NewMeter()
 
 		if sys.SubmissionTimeout == 0 {
 			sys.SubmissionTimeout = 1 * time.Minute
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/go-stellar-base"
 	"github.com/stellar/go-stellar-base/build"
 	"github.com/stellar/horizon/test"
 )
@@ -84,6 +85,182 @@ func TestTxsub(t *testing.T) {
 				So(system.Metrics.FailedSubmissionsMeter.Count(), ShouldEqual, 0)
 				So(system.Metrics.SubmissionTimer.Count(), ShouldEqual, 1)
 			})
+
+			Convey("refuses envelopes its Validator finds invalid", func() {
+				system.Validator = &Validator{MaxFee: 1}
+				r := <-system.Submit(ctx, successTx.EnvelopeXDR)
+				So(r.Err, ShouldResemble, &InvalidTransactionError{InvalidFeeTooHigh, successTx.EnvelopeXDR})
+				So(submitter.WasSubmittedTo, ShouldBeFalse)
+			})
+
+			Convey("refuses submissions while its Guard does", func() {
+				refused := errors.New("refused")
+				system.Guard = func() error { return refused }
+
+				r := <-system.Submit(ctx, successTx.EnvelopeXDR)
+				So(r.Err, ShouldEqual, refused)
+				So(submitter.WasSubmittedTo, ShouldBeFalse)
+				So(len(system.Pending.Pending(ctx)), ShouldEqual, 0)
+			})
+
+			Convey("refuses submissions beyond MaxOpenSubmissions", func() {
+				system.MaxOpenSubmissions = 1
+				other := "2374e99349b9ef7dba9a5db3339b78fda8f34777b1af33ba468ad5c0df946d4d"
+				system.Pending.Add(ctx, other, make(chan Result, 1))
+
+				r := <-system.Submit(ctx, successTx.EnvelopeXDR)
+				So(r.Err, ShouldEqual, ErrTooManyOpenSubmissions)
+				So(submitter.WasSubmittedTo, ShouldBeFalse)
+			})
+
+			Convey("attaches resubmissions to the open submission of the same transaction", func() {
+				first := system.Submit(ctx, successTx.EnvelopeXDR)
+				submitter.WasSubmittedTo = false
+
+				second := system.Submit(ctx, successTx.EnvelopeXDR)
+				So(submitter.WasSubmittedTo, ShouldBeFalse)
+				So(system.Metrics.SubmissionTimer.Count(), ShouldEqual, 1)
+				So(len(system.Pending.Pending(ctx)), ShouldEqual, 1)
+
+				results.Results = []Result{successTx}
+				system.Tick(ctx)
+				So((<-first).Hash, ShouldEqual, successTx.Hash)
+				So((<-second).Hash, ShouldEqual, successTx.Hash)
+			})
+		})
+
+		Convey("Submit with a Queue", func() {
+			_, root, err := stellarbase.GenerateKeyFromSeed("SDHOAMBNLGCE2MV5ZKIVZAQD3VCLGP53P3OBSBI6UN5L5XZI5TKHFQL4")
+			So(err, ShouldBeNil)
+
+			tx := build.Transaction(
+				build.SourceAccount{Address: root.Address()},
+				build.Sequence{Sequence: 3},
+				build.TestNetwork,
+				build.Payment(
+					build.Destination{Address: "GA5WBPYA5Y4WAEHXWR2UKO2UO4BUGHUQ74EUPKON2QHV4WRHOIRNKKH2"},
+					build.NativeAmount{Amount: "10"},
+				),
+			)
+			txe := tx.Sign(&root)
+			env, err := txe.Base64()
+			So(err, ShouldBeNil)
+			hash, err := tx.HashHex()
+			So(err, ShouldBeNil)
+
+			sequences := &MockSequenceProvider{Results: map[string]uint64{root.Address(): 1}}
+			system.Queue = NewDefaultSubmissionQueue(0)
+			system.Sequences = sequences
+
+			Convey("queues transactions ahead of their source account's sequence", func() {
+				l := system.Submit(ctx, env)
+				So(len(l), ShouldEqual, 0)
+				So(submitter.WasSubmittedTo, ShouldBeFalse)
+				So(system.Queue.Addresses(ctx), ShouldResemble, []string{root.Address()})
+
+				_, pending := system.Status(ctx, hash)
+				So(pending, ShouldBeTrue)
+
+				Convey("and submits them once their predecessor is included", func() {
+					system.Tick(ctx)
+					So(submitter.WasSubmittedTo, ShouldBeFalse)
+
+					sequences.Results[root.Address()] = 2
+					system.Tick(ctx)
+					So(submitter.WasSubmittedTo, ShouldBeTrue)
+					So(system.Queue.Addresses(ctx), ShouldBeEmpty)
+					So(system.Pending.Pending(ctx), ShouldResemble, []string{hash})
+				})
+
+				Convey("and finishes them if their submission fails", func() {
+					submitter.R.Err = errors.New("busted for some reason")
+					sequences.Results[root.Address()] = 2
+					system.Tick(ctx)
+
+					r := <-l
+					So(r.Err, ShouldNotBeNil)
+					So(system.Pending.Pending(ctx), ShouldBeEmpty)
+				})
+
+				Convey("and finishes them unsubmitted on shutdown", func() {
+					So(system.Shutdown(ctx), ShouldEqual, 1)
+
+					r := <-l
+					So(r.Err, ShouldEqual, ErrShuttingDown)
+					So(submitter.WasSubmittedTo, ShouldBeFalse)
+					So(system.Queue.Addresses(ctx), ShouldBeEmpty)
+				})
+			})
+
+			Convey("submits transactions that follow their source account's sequence", func() {
+				sequences.Results[root.Address()] = 2
+				_ = system.Submit(ctx, env)
+				So(submitter.WasSubmittedTo, ShouldBeTrue)
+				So(system.Queue.Addresses(ctx), ShouldBeEmpty)
+			})
+
+			Convey("submits transactions of unknown accounts", func() {
+				sequences.Results = map[string]uint64{}
+				_ = system.Submit(ctx, env)
+				So(submitter.WasSubmittedTo, ShouldBeTrue)
+			})
+		})
+
+		Convey("Submit with a Resubmitter", func() {
+			system.Resubmitter = &Resubmitter{Ledgers: 1, MaxAttempts: 1}
+			_ = system.Submit(ctx, successTx.EnvelopeXDR)
+			So(len(system.Attempts(ctx, successTx.Hash)), ShouldEqual, 1)
+
+			Convey("resends open submissions when due", func() {
+				submitter.WasSubmittedTo = false
+				system.Tick(ctx)
+				So(submitter.WasSubmittedTo, ShouldBeTrue)
+				So(len(system.Attempts(ctx, successTx.Hash)), ShouldEqual, 2)
+				So(system.Metrics.ResubmissionsMeter.Count(), ShouldEqual, 1)
+
+				submitter.WasSubmittedTo = false
+				system.Tick(ctx)
+				system.Tick(ctx)
+				So(submitter.WasSubmittedTo, ShouldBeFalse)
+			})
+
+			Convey("does not resend included submissions", func() {
+				submitter.WasSubmittedTo = false
+				results.Results = []Result{successTx}
+				system.Tick(ctx)
+				So(submitter.WasSubmittedTo, ShouldBeFalse)
+			})
+		})
+
+		Convey("Hash", func() {
+			hash, err := system.Hash(ctx, successTx.EnvelopeXDR)
+			So(err, ShouldBeNil)
+			So(hash, ShouldEqual, successTx.Hash)
+
+			_, err = system.Hash(ctx, "not_xdr")
+			So(err, ShouldHaveSameTypeAs, &MalformedTransactionError{})
+		})
+
+		Convey("Status", func() {
+			Convey("returns the result provided by the ResultProvider", func() {
+				results.Results = []Result{successTx}
+				r, pending := system.Status(ctx, successTx.Hash)
+
+				So(r.Err, ShouldBeNil)
+				So(r.LedgerSequence, ShouldEqual, 2)
+				So(pending, ShouldBeFalse)
+			})
+
+			Convey("reports open submissions without results as pending", func() {
+				r, pending := system.Status(ctx, successTx.Hash)
+				So(r.Err, ShouldEqual, ErrNoResults)
+				So(pending, ShouldBeFalse)
+
+				_ = system.Submit(ctx, successTx.EnvelopeXDR)
+				r, pending = system.Status(ctx, successTx.Hash)
+				So(r.Err, ShouldEqual, ErrNoResults)
+				So(pending, ShouldBeTrue)
+			})
 		})
 
 		Convey("Tick", func() {
@@ -125,5 +302,37 @@ func TestTxsub(t *testing.T) {
 			})
 		})
 
+		Convey("Shutdown", func() {
+			Convey("finishes open submissions with ErrShuttingDown", func() {
+				l := make(chan Result, 1)
+				system.Pending.Add(ctx, successTx.Hash, l)
+
+				So(system.Shutdown(ctx), ShouldEqual, 1)
+				So(len(system.Pending.Pending(ctx)), ShouldEqual, 0)
+
+				r := <-l
+				So(r.Hash, ShouldEqual, successTx.Hash)
+				So(r.Err, ShouldEqual, ErrShuttingDown)
+			})
+
+			Convey("finishes included submissions with their results", func() {
+				l := make(chan Result, 1)
+				system.Pending.Add(ctx, successTx.Hash, l)
+				results.Results = []Result{successTx}
+
+				So(system.Shutdown(ctx), ShouldEqual, 0)
+				r := <-l
+				So(r.Err, ShouldBeNil)
+			})
+
+			Convey("refuses submissions made after it", func() {
+				system.Shutdown(ctx)
+				r := <-system.Submit(ctx, successTx.EnvelopeXDR)
+
+				So(r.Err, ShouldEqual, ErrShuttingDown)
+				So(submitter.WasSubmittedTo, ShouldBeFalse)
+			})
+		})
+
 	})
 }
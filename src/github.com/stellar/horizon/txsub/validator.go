@@ -0,0 +1,151 @@
+package txsub
+
+import (
+	"time"
+
+	"github.com/agl/ed25519"
+	"github.com/stellar/go-stellar-base/xdr"
+)
+
+// The limits stellar-core places on a transaction, which a Validator applies
+// when its own limits are not set.
+const (
+	DefaultMaxOperations = 100
+	DefaultMaxSignatures = 20
+)
+
+// The reasons an InvalidTransactionError gives for refusing an envelope.
+const (
+	InvalidWrongNetwork       = "wrong_network"
+	InvalidTimeBounds         = "invalid_time_bounds"
+	InvalidTooLate            = "too_late"
+	InvalidTooEarly           = "too_early"
+	InvalidNoOperations       = "no_operations"
+	InvalidTooManyOperations  = "too_many_operations"
+	InvalidFeeTooHigh         = "fee_too_high"
+	InvalidUnsigned           = "unsigned"
+	InvalidTooManySignatures  = "too_many_signatures"
+	InvalidDuplicateSignature = "duplicate_signature"
+)
+
+// Validator checks envelopes before they are submitted, refusing those
+// stellar-core is bound to reject and those beyond the limits of the
+// submission system.  MaxOperations and MaxSignatures default to the limits of
+// stellar-core.  MaxFee, the highest fee a transaction may offer, and MaxDelay,
+// how far in the future the lower time bound of a transaction may be, are
+// unlimited when zero.
+type Validator struct {
+	MaxOperations int
+	MaxSignatures int
+	MaxFee        int
+	MaxDelay      time.Duration
+}
+
+// validate returns the reason the envelope described by info, hashed on the
+// submission system's network, is invalid at the provided time, or an empty
+// string when it is valid.
+func (v *Validator) validate(info envelopeInfo, now time.Time) string {
+	env := info.Envelope
+
+	if reason := v.validateTimeBounds(env.Tx.TimeBounds, now); reason != "" {
+		return reason
+	}
+
+	maxOps := v.MaxOperations
+	if maxOps == 0 {
+		maxOps = DefaultMaxOperations
+	}
+
+	switch {
+	case len(env.Tx.Operations) == 0:
+		return InvalidNoOperations
+	case len(env.Tx.Operations) > maxOps:
+		return InvalidTooManyOperations
+	case v.MaxFee > 0 && int64(env.Tx.Fee) > int64(v.MaxFee):
+		return InvalidFeeTooHigh
+	}
+
+	return v.validateSignatures(info)
+}
+
+func (v *Validator) validateTimeBounds(tb *xdr.TimeBounds, now time.Time) string {
+	if tb == nil {
+		return ""
+	}
+
+	min, max := int64(tb.MinTime), int64(tb.MaxTime)
+
+	switch {
+	case max != 0 && min > max:
+		return InvalidTimeBounds
+	case max != 0 && max < now.Unix():
+		return InvalidTooLate
+	case v.MaxDelay > 0 && min > now.Add(v.MaxDelay).Unix():
+		return InvalidTooEarly
+	}
+
+	return ""
+}
+
+// validateSignatures checks the count and uniqueness of the signatures of the
+// envelope described by info.  Signatures hinting at the key of the source
+// account of the transaction or of one of its operations must verify against
+// the transaction's hash on the submission system's network: when they do not,
+// they were most likely made for another network.
+func (v *Validator) validateSignatures(info envelopeInfo) string {
+	env := info.Envelope
+
+	maxSigs := v.MaxSignatures
+	if maxSigs == 0 {
+		maxSigs = DefaultMaxSignatures
+	}
+
+	switch {
+	case len(env.Signatures) == 0:
+		return InvalidUnsigned
+	case len(env.Signatures) > maxSigs:
+		return InvalidTooManySignatures
+	}
+
+	seen := map[string]bool{}
+	for _, sig := range env.Signatures {
+		s := string(sig.Signature)
+		if seen[s] {
+			return InvalidDuplicateSignature
+		}
+		seen[s] = true
+	}
+
+	sources := []xdr.AccountId{env.Tx.SourceAccount}
+	for _, op := range env.Tx.Operations {
+		if op.SourceAccount != nil {
+			sources = append(sources, *op.SourceAccount)
+		}
+	}
+
+	for _, sig := range env.Signatures {
+		if len(sig.Signature) != ed25519.SignatureSize {
+			continue
+		}
+
+		var signature [ed25519.SignatureSize]byte
+		copy(signature[:], sig.Signature)
+
+		for _, source := range sources {
+			key := source.MustEd25519()
+
+			var hint xdr.SignatureHint
+			copy(hint[:], key[len(key)-len(hint):])
+			if hint != sig.Hint {
+				continue
+			}
+
+			pub := [ed25519.PublicKeySize]byte(key)
+			if !ed25519.Verify(&pub, info.HashBytes[:], &signature) {
+				return InvalidWrongNetwork
+			}
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,109 @@
+package txsub
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/go-stellar-base"
+	"github.com/stellar/go-stellar-base/build"
+	"github.com/stellar/go-stellar-base/xdr"
+	"github.com/stellar/horizon/test"
+)
+
+func TestValidator(t *testing.T) {
+	Convey("txsub.Validator", t, func() {
+		ctx := test.Context()
+		now := time.Now()
+		v := &Validator{}
+
+		_, root, err := stellarbase.GenerateKeyFromSeed("SDHOAMBNLGCE2MV5ZKIVZAQD3VCLGP53P3OBSBI6UN5L5XZI5TKHFQL4")
+		So(err, ShouldBeNil)
+
+		tx := build.Transaction(
+			build.SourceAccount{Address: root.Address()},
+			build.Sequence{Sequence: 4},
+			build.TestNetwork,
+			build.Payment(
+				build.Destination{Address: "GA5WBPYA5Y4WAEHXWR2UKO2UO4BUGHUQ74EUPKON2QHV4WRHOIRNKKH2"},
+				build.NativeAmount{Amount: "10"},
+			),
+		)
+		txe := tx.Sign(&root)
+		env, err := txe.Base64()
+		So(err, ShouldBeNil)
+
+		info, err := extractEnvelopeInfo(ctx, env, build.TestNetwork.Passphrase)
+		So(err, ShouldBeNil)
+
+		Convey("accepts valid envelopes", func() {
+			So(v.validate(info, now), ShouldEqual, "")
+		})
+
+		Convey("refuses envelopes signed for another network", func() {
+			info, err := extractEnvelopeInfo(ctx, env, build.PublicNetwork.Passphrase)
+			So(err, ShouldBeNil)
+			So(v.validate(info, now), ShouldEqual, InvalidWrongNetwork)
+		})
+
+		Convey("checks time bounds", func() {
+			tb := &xdr.TimeBounds{}
+			info.Envelope.Tx.TimeBounds = tb
+
+			tb.MinTime = xdr.Uint64(now.Unix() + 10)
+			tb.MaxTime = xdr.Uint64(now.Unix())
+			So(v.validate(info, now), ShouldEqual, InvalidTimeBounds)
+
+			tb.MinTime = 0
+			tb.MaxTime = xdr.Uint64(now.Unix() - 1)
+			So(v.validate(info, now), ShouldEqual, InvalidTooLate)
+
+			tb.MinTime = xdr.Uint64(now.Add(1 * time.Hour).Unix())
+			tb.MaxTime = 0
+			So(v.validate(info, now), ShouldEqual, "")
+
+			v.MaxDelay = 1 * time.Minute
+			So(v.validate(info, now), ShouldEqual, InvalidTooEarly)
+		})
+
+		Convey("checks the count of operations", func() {
+			ops := info.Envelope.Tx.Operations
+
+			info.Envelope.Tx.Operations = nil
+			So(v.validate(info, now), ShouldEqual, InvalidNoOperations)
+
+			for i := 0; i < DefaultMaxOperations; i++ {
+				info.Envelope.Tx.Operations = append(info.Envelope.Tx.Operations, ops[0])
+			}
+			So(v.validate(info, now), ShouldEqual, "")
+
+			info.Envelope.Tx.Operations = append(info.Envelope.Tx.Operations, ops[0])
+			So(v.validate(info, now), ShouldEqual, InvalidTooManyOperations)
+
+			v.MaxOperations = 1
+			info.Envelope.Tx.Operations = ops[:1]
+			So(v.validate(info, now), ShouldEqual, "")
+		})
+
+		Convey("checks the fee against MaxFee", func() {
+			v.MaxFee = 100
+			So(v.validate(info, now), ShouldEqual, "")
+
+			info.Envelope.Tx.Fee = 101
+			So(v.validate(info, now), ShouldEqual, InvalidFeeTooHigh)
+		})
+
+		Convey("checks signatures", func() {
+			sigs := info.Envelope.Signatures
+
+			info.Envelope.Signatures = nil
+			So(v.validate(info, now), ShouldEqual, InvalidUnsigned)
+
+			info.Envelope.Signatures = append(sigs, sigs[0])
+			So(v.validate(info, now), ShouldEqual, InvalidDuplicateSignature)
+
+			v.MaxSignatures = 1
+			So(v.validate(info, now), ShouldEqual, InvalidTooManySignatures)
+		})
+	})
+}
@@ -0,0 +1,16 @@
+// Package webhook delivers notifications to urls given by clients, such as
+// the callback_url of a transaction submission.
+//
+// A notification is a JSON document POSTed to its url.  Each carries, in its
+// X-Horizon-Signature header, the hex encoded HMAC-SHA256 of its body keyed by
+// a secret shared with the receiver, so that the receiver can tell the
+// notification came from this server.  Deliveries that fail are retried, with
+// a doubling wait between attempts, and those that still fail are logged as
+// dead letters, carrying the url and body so that they can be replayed.  A
+// Sender makes a bounded number of deliveries at once, each of which times
+// out, so that slow receivers cannot pile up requests.
+//
+// Notifications are only delivered over https to public hosts, without
+// following redirects, so that clients cannot have the server post to hosts
+// within its own network.
+package webhook
@@ -0,0 +1,229 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	stderr "errors"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/go-errors/errors"
+	"github.com/stellar/horizon/context/requestid"
+	"github.com/stellar/horizon/httpx"
+	"github.com/stellar/horizon/log"
+	"github.com/stellar/horizon/trace"
+	"golang.org/x/net/context"
+)
+
+const (
+	// SignatureHeader is the header a notification's signature is sent in.
+	SignatureHeader = "X-Horizon-Signature"
+
+	// DefaultMaxAttempts is the number of times a Sender tries to deliver a
+	// notification when MaxAttempts is zero.
+	DefaultMaxAttempts = 5
+
+	// DefaultBackoff is how long a Sender waits before its first retry when
+	// Backoff is zero.
+	DefaultBackoff = 1 * time.Second
+
+	// DefaultTimeout is how long a Sender waits on each delivery when its
+	// client has no timeout of its own.
+	DefaultTimeout = 10 * time.Second
+
+	// DefaultMaxDeliveries is the most deliveries a Sender makes at once when
+	// MaxDeliveries is zero.
+	DefaultMaxDeliveries = 20
+)
+
+// ErrInvalidURL is returned for the urls a Sender will not deliver to.
+// NOTE: this is not a go-errors based error, as stack traces are unnecessary
+var ErrInvalidURL = stderr.New("not an https url of a public host")
+
+// Sender delivers signed notifications.  A Sender is safe for concurrent
+// access.
+type Sender struct {
+	// Client performs the sender's requests.  When nil, the client bound to
+	// the context of each delivery by httpx.ClientContext is used.  Either is
+	// given DefaultTimeout when it has no timeout.
+	Client *http.Client
+
+	// Secret is the key notifications are signed with.
+	Secret string
+
+	// MaxAttempts is the most times a notification is tried.
+	MaxAttempts int
+
+	// Backoff is how long the sender waits before retrying a notification the
+	// first time, doubling after each retry.
+	Backoff time.Duration
+
+	// MaxDeliveries is the most deliveries made at once.  Others wait for one
+	// to end before they are attempted.
+	MaxDeliveries int
+
+	// Insecure delivers to any host, over plain http as well as https, rather
+	// than only to public hosts over https.  It is meant for testing against
+	// local servers.
+	Insecure bool
+
+	initializer sync.Once
+	slots       chan struct{}
+}
+
+// Send POSTs payload, encoded as JSON, to url, retrying until it is accepted
+// with a 2xx response, the sender's attempts run out or ctx is done.  A
+// notification that cannot be delivered is logged as a dead letter and its
+// last error returned.
+func (s *Sender) Send(ctx context.Context, url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	attempts := s.MaxAttempts
+	if attempts <= 0 {
+		attempts = DefaultMaxAttempts
+	}
+
+	wait := s.Backoff
+	if wait == 0 {
+		wait = DefaultBackoff
+	}
+
+	for i := 1; i <= attempts; i++ {
+		err = s.post(ctx, url, body)
+		if err == nil {
+			return nil
+		}
+
+		if i == attempts || !sleep(ctx, wait) {
+			break
+		}
+		wait *= 2
+	}
+
+	log.WithField(ctx, "url", url).
+		WithField("body", string(body)).
+		WithField("err", err.Error()).
+		Error("webhook undeliverable")
+
+	return err
+}
+
+// ValidateURL returns ErrInvalidURL unless s delivers to u: an absolute https
+// url of a public host, or, when s is Insecure, any absolute http or https url.
+// Clients name the urls notifications are sent to, so that they must not name
+// hosts within the server's own network.
+func (s *Sender) ValidateURL(u *url.URL) error {
+	if !u.IsAbs() || u.Host == "" {
+		return ErrInvalidURL
+	}
+
+	if s.Insecure {
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return ErrInvalidURL
+		}
+		return nil
+	}
+
+	if u.Scheme != "https" || httpx.ValidatePublicHost(u.Hostname()) != nil {
+		return ErrInvalidURL
+	}
+
+	return nil
+}
+
+// Sign returns the signature of body under secret, as sent in SignatureHeader.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *Sender) post(ctx context.Context, url string, body []byte) (err error) {
+	s.initializer.Do(s.init)
+	select {
+	case s.slots <- struct{}{}:
+		defer func() { <-s.slots }()
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), 1)
+	}
+
+	ctx, span := trace.Start(ctx, "webhook.post")
+	span.SetKind(trace.KindClient)
+	span.SetAttribute("http.url", url)
+	defer func() { span.Finish(err) }()
+
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	// checked again as delivered, as the addresses of the host may have
+	// changed since its url was given
+	err = s.ValidateURL(req.URL)
+	if err == nil && !s.Insecure {
+		err = httpx.ResolvePublicHost(ctx, req.URL.Hostname())
+	}
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(s.Secret, body))
+	requestid.Propagate(ctx, req)
+	trace.Inject(ctx, req)
+
+	resp, err := s.client(ctx).Do(req)
+	if err != nil {
+		return errors.Wrap(err, 1)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.Errorf("webhook receiver responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// sleep waits for d, returning false if ctx is done first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-time.After(d):
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// client returns the client of deliveries made within ctx, which does not
+// follow redirects: they could lead a delivery on to a host it may not reach.
+func (s *Sender) client(ctx context.Context) *http.Client {
+	client := s.Client
+	if client == nil {
+		client = httpx.ClientFromContext(ctx)
+	}
+
+	unredirected := *httpx.ClientWithTimeout(client, DefaultTimeout)
+	unredirected.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return &unredirected
+}
+
+// init creates the slots of the deliveries s makes at once
+func (s *Sender) init() {
+	n := s.MaxDeliveries
+	if n <= 0 {
+		n = DefaultMaxDeliveries
+	}
+
+	s.slots = make(chan struct{}, n)
+}
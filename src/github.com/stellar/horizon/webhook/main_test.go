@@ -0,0 +1,138 @@
+package webhook
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stellar/horizon/test"
+)
+
+func TestSender(t *testing.T) {
+	ctx := test.Context()
+
+	Convey("Sender", t, func() {
+		var (
+			bodies     []string
+			signatures []string
+			failures   int
+		)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			if failures > 0 {
+				failures--
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			bodies = append(bodies, string(body))
+			signatures = append(signatures, r.Header.Get(SignatureHeader))
+		}))
+		defer server.Close()
+
+		s := &Sender{Secret: "shh", MaxAttempts: 3, Backoff: time.Millisecond, Insecure: true}
+		payload := map[string]string{"hash": "abc"}
+
+		Convey("delivers signed payloads", func() {
+			So(s.Send(ctx, server.URL, payload), ShouldBeNil)
+			So(bodies, ShouldResemble, []string{`{"hash":"abc"}`})
+			So(signatures[0], ShouldEqual, Sign("shh", []byte(`{"hash":"abc"}`)))
+			So(signatures[0], ShouldNotEqual, Sign("other", []byte(`{"hash":"abc"}`)))
+		})
+
+		Convey("retries failed deliveries", func() {
+			failures = 2
+			So(s.Send(ctx, server.URL, payload), ShouldBeNil)
+			So(len(bodies), ShouldEqual, 1)
+		})
+
+		Convey("makes at most MaxDeliveries at once", func() {
+			var (
+				lock      sync.Mutex
+				open, max int
+			)
+			slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				lock.Lock()
+				open++
+				if open > max {
+					max = open
+				}
+				lock.Unlock()
+
+				time.Sleep(10 * time.Millisecond)
+
+				lock.Lock()
+				open--
+				lock.Unlock()
+			}))
+			defer slow.Close()
+
+			s.MaxDeliveries = 2
+			var wg sync.WaitGroup
+			for i := 0; i < 6; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					s.Send(ctx, slow.URL, payload)
+				}()
+			}
+			wg.Wait()
+
+			So(max, ShouldBeLessThanOrEqualTo, 2)
+		})
+
+		Convey("gives up on deliveries once they time out", func() {
+			hung := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(100 * time.Millisecond)
+			}))
+			defer hung.Close()
+
+			s.Client = &http.Client{Timeout: 10 * time.Millisecond}
+			s.MaxAttempts = 1
+			So(s.Send(ctx, hung.URL, payload), ShouldNotBeNil)
+		})
+
+		Convey("gives up after MaxAttempts", func() {
+			failures = 3
+			So(s.Send(ctx, server.URL, payload), ShouldNotBeNil)
+			So(bodies, ShouldBeEmpty)
+		})
+
+		Convey("does not follow redirects", func() {
+			redirecting := httptest.NewServer(http.RedirectHandler(server.URL, http.StatusTemporaryRedirect))
+			defer redirecting.Close()
+
+			s.MaxAttempts = 1
+			So(s.Send(ctx, redirecting.URL, payload), ShouldNotBeNil)
+			So(bodies, ShouldBeEmpty)
+		})
+
+		Convey("only delivers to public hosts over https unless Insecure", func() {
+			s.Insecure = false
+			s.MaxAttempts = 1
+			So(s.Send(ctx, server.URL, payload), ShouldNotBeNil)
+			So(bodies, ShouldBeEmpty)
+
+			for _, raw := range []string{
+				"http://stellar.org/callbacks",
+				"https://127.0.0.1/callbacks",
+				"https://169.254.169.254/latest/meta-data",
+				"https://10.0.0.1/callbacks",
+				"https://localhost:8001/config/reload",
+				"/callbacks",
+			} {
+				u, err := url.Parse(raw)
+				So(err, ShouldBeNil)
+				So(s.ValidateURL(u), ShouldEqual, ErrInvalidURL)
+			}
+
+			u, _ := url.Parse("https://stellar.org/callbacks")
+			So(s.ValidateURL(u), ShouldBeNil)
+		})
+	})
+}